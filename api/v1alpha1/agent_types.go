@@ -3,6 +3,7 @@
 package v1alpha1
 
 import (
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -118,6 +119,105 @@ type ProxyConfig struct {
 	NoProxy string `json:"noProxy,omitempty"`
 }
 
+// PreemptionPolicy controls whether Tasks preempt running Tasks of an Agent
+// +kubebuilder:validation:Enum=Never;LowerPriority
+type PreemptionPolicy string
+
+const (
+	// PreemptionPolicyNever never preempts running Tasks
+	PreemptionPolicyNever PreemptionPolicy = "Never"
+	// PreemptionPolicyLowerPriority lets Tasks preempt running Tasks with a lower priority
+	PreemptionPolicyLowerPriority PreemptionPolicy = "LowerPriority"
+)
+
+// ExecutionBackend selects the workload that runs a Task
+// +kubebuilder:validation:Enum=Pod;Job
+type ExecutionBackend string
+
+const (
+	// ExecutionBackendPod runs each Task in a Pod created by the controller
+	ExecutionBackendPod ExecutionBackend = "Pod"
+	// ExecutionBackendJob runs each Task in a batch/v1 Job
+	ExecutionBackendJob ExecutionBackend = "Job"
+)
+
+// ExecutionConfig configures the workload running Tasks.
+type ExecutionConfig struct {
+	// Backend selects the workload that runs a Task:
+	//   - Pod (default): the controller creates the Task Pod directly and a
+	//     failed or deleted Pod fails the Task
+	//   - Job: the controller creates a Job from the same Pod template, so
+	//     Kubernetes recreates the Pod per job settings, e.g. after a node failure
+	// +kubebuilder:validation:Enum=Pod;Job
+	// +optional
+	Backend ExecutionBackend `json:"backend,omitempty"`
+
+	// Job configures the Job when backend is Job.
+	// +optional
+	Job *JobExecutionConfig `json:"job,omitempty"`
+
+	// OnDisruption decides what happens to a Task whose Pod is lost to its node
+	// rather than to the agent exiting, e.g. evicted by a node drain or deleted
+	// after a node failure. Only used by the Pod backend; with the Job backend,
+	// job.podFailurePolicy decides. Defaults to failing the Task.
+	// +optional
+	OnDisruption *DisruptionPolicy `json:"onDisruption,omitempty"`
+}
+
+// DisruptionAction is the action taken when a Task Pod is disrupted
+// +kubebuilder:validation:Enum=Fail;Reschedule
+type DisruptionAction string
+
+const (
+	// DisruptionActionFail fails the Task with reason InfrastructureError
+	DisruptionActionFail DisruptionAction = "Fail"
+	// DisruptionActionReschedule creates a new Pod for the Task
+	DisruptionActionReschedule DisruptionAction = "Reschedule"
+)
+
+// DisruptionPolicy configures how Tasks survive the loss of their Pod.
+type DisruptionPolicy struct {
+	// Action taken when the Task Pod is disrupted:
+	//   - Fail (default): the Task fails with reason InfrastructureError
+	//   - Reschedule: the controller creates a new Pod for the Task. agentRef
+	//     Tasks continue their OpenCode session on the Agent's server if it
+	//     can be found; otherwise, and for templateRef Tasks, the Task restarts
+	//     from scratch.
+	// +kubebuilder:validation:Enum=Fail;Reschedule
+	// +kubebuilder:default=Fail
+	// +optional
+	Action DisruptionAction `json:"action,omitempty"`
+
+	// MaxReschedules is the number of times a Task is rescheduled before a
+	// further disruption fails it. Defaults to 3.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=3
+	// +optional
+	MaxReschedules *int32 `json:"maxReschedules,omitempty"`
+}
+
+// JobExecutionConfig holds the settings passed through to the Job of a Task.
+// Unset fields use the Kubernetes defaults.
+type JobExecutionConfig struct {
+	// BackoffLimit is the number of retries before the Job, and the Task, fails.
+	// Each retry runs the Task from scratch in a new Pod.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	BackoffLimit *int32 `json:"backoffLimit,omitempty"`
+
+	// PodFailurePolicy decides which Pod failures are retried, ignored or
+	// fail the Job, e.g. ignore disruptions (DisruptionTarget) but fail on
+	// non-zero exit codes of the agent container.
+	// +optional
+	PodFailurePolicy *batchv1.PodFailurePolicy `json:"podFailurePolicy,omitempty"`
+
+	// ActiveDeadlineSeconds bounds the Job's run time across all retries.
+	// Task spec.timeout is enforced by the controller independently.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ActiveDeadlineSeconds *int64 `json:"activeDeadlineSeconds,omitempty"`
+}
+
 // PluginTarget specifies which OpenCode plugin runtime to load the plugin into.
 // +kubebuilder:validation:Enum=server;tui
 type PluginTarget string
@@ -373,6 +473,26 @@ type AgentSpec struct {
 	// +optional
 	Quota *QuotaConfig `json:"quota,omitempty"`
 
+	// PreemptionPolicy controls whether Tasks may preempt running Tasks of this
+	// Agent when it is at capacity (maxConcurrentTasks):
+	//   - Never (default): Tasks wait for capacity in the queue
+	//   - LowerPriority: a Task preempts the running Task with the lowest
+	//     spec.priority below its own; the preempted Task's Pod is deleted and
+	//     the Task is queued again, restarting from scratch when capacity frees up
+	// +kubebuilder:validation:Enum=Never;LowerPriority
+	// +optional
+	PreemptionPolicy PreemptionPolicy `json:"preemptionPolicy,omitempty"`
+
+	// Execution selects whether Tasks of this Agent run in Pods (default) or Jobs.
+	// +optional
+	Execution *ExecutionConfig `json:"execution,omitempty"`
+
+	// NetworkPolicy overrides the cluster-wide networkPolicy of KubeOpenCodeConfig
+	// for the Task Pods of this Agent, e.g. to declare the egress it needs or to
+	// disable isolation with enabled: false.
+	// +optional
+	NetworkPolicy *TaskNetworkPolicyConfig `json:"networkPolicy,omitempty"`
+
 	// CABundle configures custom CA certificates for TLS verification.
 	// The CA bundle is mounted into all init containers (git-init, url-fetch, context-init)
 	// and the worker container, enabling HTTPS access to servers using private/self-signed CAs.
@@ -821,6 +941,15 @@ type AgentPodSpec struct {
 	// +optional
 	PodSecurityContext *corev1.PodSecurityContext `json:"podSecurityContext,omitempty"`
 
+	// PodSecurityDefaultsOptOut lists the KubeOpenCodeConfig podSecurityDefaults
+	// not applied to this Agent's Pods, e.g. ReadOnlyRootFilesystem for an image that
+	// writes outside its volumes. "All" opts out of every default.
+	//
+	// Example:
+	//   podSecurityDefaultsOptOut: ["ReadOnlyRootFilesystem"]
+	// +optional
+	PodSecurityDefaultsOptOut []PodSecurityDefault `json:"podSecurityDefaultsOptOut,omitempty"`
+
 	// Lifecycle describes actions that the management system should take in response
 	// to container lifecycle events. This is applied to the executor (worker) container.
 	//
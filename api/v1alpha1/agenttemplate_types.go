@@ -131,6 +131,17 @@ type AgentTemplateSpec struct {
 	// Agents can override this value in their own spec.
 	// +optional
 	Quota *QuotaConfig `json:"quota,omitempty"`
+
+	// Execution provides the default execution backend for Agents derived from
+	// this template and for templateRef Tasks. Agents can override this value.
+	// +optional
+	Execution *ExecutionConfig `json:"execution,omitempty"`
+
+	// FailureSnapshot archives the workspace to a PVC when a templateRef Task fails.
+	// Tasks can override this in their own spec. Not used by Agents, whose
+	// workspace outlives individual Tasks.
+	// +optional
+	FailureSnapshot *WorkspaceSnapshotConfig `json:"failureSnapshot,omitempty"`
 }
 
 // AgentTemplateStatus defines the observed state of AgentTemplate
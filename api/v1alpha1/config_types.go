@@ -4,6 +4,7 @@ package v1alpha1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -41,6 +42,20 @@ type KubeOpenCodeConfigSpec struct {
 	// +optional
 	SystemImage *SystemImageConfig `json:"systemImage,omitempty"`
 
+	// ImageRewrites rewrite the image references of all containers of generated
+	// Pods (agent, executor, attach and system images) at build time, for
+	// air-gapped clusters that mirror all images to an internal registry.
+	// The rewrite with the longest matching prefix applies.
+	//
+	// Example:
+	//   imageRewrites:
+	//     - prefix: "quay.io/"
+	//       replacement: "internal-mirror.example.com/quay/"
+	//     - prefix: "docker.io/"
+	//       replacement: "internal-mirror.example.com/dockerhub/"
+	// +optional
+	ImageRewrites []ImageRewrite `json:"imageRewrites,omitempty"`
+
 	// Cleanup configures automatic cleanup of completed Tasks.
 	// When configured, completed/failed Tasks are automatically deleted based on
 	// TTL (time-to-live) and/or retention count policies.
@@ -48,18 +63,195 @@ type KubeOpenCodeConfigSpec struct {
 	// +optional
 	Cleanup *CleanupConfig `json:"cleanup,omitempty"`
 
+	// Defaults configures defaults applied to resources that leave a field unset.
+	// +optional
+	Defaults *DefaultsConfig `json:"defaults,omitempty"`
+
 	// Proxy configures cluster-wide HTTP/HTTPS proxy settings for all generated Pods.
 	// Agent-level proxy settings take precedence over cluster-level settings.
 	// If not specified, no proxy environment variables are injected.
 	// +optional
 	Proxy *ProxyConfig `json:"proxy,omitempty"`
 
+	// PodSecurityDefaults hardens the security context of every container of Task
+	// Pods and Agent server Pods. Settings already specified by the Agent's
+	// securityContext or podSecurityContext take precedence, and Agents can opt out
+	// of individual defaults with podSpec.podSecurityDefaultsOptOut.
+	// If not specified, only the built-in restricted default security context applies.
+	// +optional
+	PodSecurityDefaults *PodSecurityDefaults `json:"podSecurityDefaults,omitempty"`
+
+	// NetworkPolicy makes the controller isolate every Task Pod with its own
+	// NetworkPolicy, created before the Pod. Agents can override it with their
+	// own networkPolicy. If not specified, no NetworkPolicy is created.
+	// +optional
+	NetworkPolicy *TaskNetworkPolicyConfig `json:"networkPolicy,omitempty"`
+
+	// Outputs configures how Task Pods report the outputs captured for Tasks with
+	// spec.captureOutputs. If not specified, outputs are reported through the
+	// agent container's termination message.
+	// +optional
+	Outputs *TaskOutputsConfig `json:"outputs,omitempty"`
+
 	// Observability configures OpenTelemetry telemetry for OpenCode agent Pods.
 	// When enabled, the controller injects OTLP environment variables into Pod specs
 	// so that OpenCode's built-in OTel support is activated automatically.
 	// If not specified, no telemetry is produced.
 	// +optional
 	Observability *ObservabilitySpec `json:"observability,omitempty"`
+
+	// ContextCache configures a shared cache for Git contexts.
+	// When configured, git-init reuses a previously cloned repository for the same
+	// repository, ref, and resolved commit instead of cloning it again.
+	// If not specified, every Pod clones its Git contexts from the remote.
+	// +optional
+	ContextCache *ContextCacheConfig `json:"contextCache,omitempty"`
+
+	// ShareContextConfigMaps makes Tasks with identical resolved contexts share one
+	// content-addressed context ConfigMap instead of creating one ConfigMap per Task.
+	// The shared ConfigMap is owned by every Task that uses it and is garbage
+	// collected when the last of them is deleted.
+	// Useful when many Tasks with the same contexts are created in bursts
+	// (e.g., by CronTasks). Defaults to false.
+	// +optional
+	ShareContextConfigMaps bool `json:"shareContextConfigMaps,omitempty"`
+}
+
+// ContextCacheConfig configures the content-addressed context cache.
+// Cache entries are keyed by repository, ref, and clone options, and are
+// only reused when the remote ref still resolves to the cached commit.
+type ContextCacheConfig struct {
+	// ClaimName is the name of the PersistentVolumeClaim used as cache storage.
+	// PVCs are namespaced, so the claim is looked up in the namespace of each
+	// Task/Agent Pod; namespaces without this PVC will fail to start Pods.
+	// Use ReadWriteMany storage so that concurrent Pods can share the cache.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	ClaimName string `json:"claimName"`
+}
+
+// PodSecurityDefaults defines security context settings applied to all containers
+// of generated Pods, e.g. to meet the restricted Pod Security Standard.
+type PodSecurityDefaults struct {
+	// RunAsNonRoot requires containers to run as a non-root user.
+	// Not applied when the Agent sets runAsNonRoot on the Pod or container.
+	// +optional
+	RunAsNonRoot *bool `json:"runAsNonRoot,omitempty"`
+
+	// SeccompProfile is the seccomp profile of containers.
+	// Not applied when the Agent sets a seccomp profile on the Pod or container.
+	// +optional
+	SeccompProfile *corev1.SeccompProfile `json:"seccompProfile,omitempty"`
+
+	// ReadOnlyRootFilesystem mounts the root filesystem of containers read-only.
+	// Writable paths must then be provided as volumes (e.g., via podSpec.extraVolumes).
+	// Not applied when the Agent sets readOnlyRootFilesystem on the container.
+	// +optional
+	ReadOnlyRootFilesystem *bool `json:"readOnlyRootFilesystem,omitempty"`
+
+	// DropCapabilities are Linux capabilities dropped from every container,
+	// in addition to those the Agent drops itself.
+	// +optional
+	DropCapabilities []corev1.Capability `json:"dropCapabilities,omitempty"`
+}
+
+// PodSecurityDefault names a setting of PodSecurityDefaults an Agent can opt out of
+// +kubebuilder:validation:Enum=All;RunAsNonRoot;SeccompProfile;ReadOnlyRootFilesystem;DropCapabilities
+type PodSecurityDefault string
+
+const (
+	// PodSecurityDefaultAll opts out of all pod security defaults
+	PodSecurityDefaultAll PodSecurityDefault = "All"
+	// PodSecurityDefaultRunAsNonRoot opts out of runAsNonRoot
+	PodSecurityDefaultRunAsNonRoot PodSecurityDefault = "RunAsNonRoot"
+	// PodSecurityDefaultSeccompProfile opts out of seccompProfile
+	PodSecurityDefaultSeccompProfile PodSecurityDefault = "SeccompProfile"
+	// PodSecurityDefaultReadOnlyRootFilesystem opts out of readOnlyRootFilesystem
+	PodSecurityDefaultReadOnlyRootFilesystem PodSecurityDefault = "ReadOnlyRootFilesystem"
+	// PodSecurityDefaultDropCapabilities opts out of dropCapabilities
+	PodSecurityDefaultDropCapabilities PodSecurityDefault = "DropCapabilities"
+)
+
+// TaskNetworkPolicyConfig configures the NetworkPolicy isolating Task Pods
+type TaskNetworkPolicyConfig struct {
+	// Enabled creates a NetworkPolicy for each Task that denies all ingress to
+	// the Task Pod and allows egress only to DNS, the Agent server (agentRef
+	// Tasks) and the destinations listed in egress.
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// Egress lists the additional destinations Task Pods may connect to, such as
+	// the model provider API, Git hosts or a proxy.
+	//
+	// Example (HTTPS to anywhere):
+	//   egress:
+	//     - ports:
+	//         - protocol: TCP
+	//           port: 443
+	// +listType=atomic
+	// +optional
+	Egress []networkingv1.NetworkPolicyEgressRule `json:"egress,omitempty"`
+}
+
+// TaskOutputsDelivery selects how Task Pods report captured outputs
+// +kubebuilder:validation:Enum=TerminationMessage;Server
+type TaskOutputsDelivery string
+
+const (
+	// TaskOutputsDeliveryTerminationMessage writes the outputs to the agent
+	// container's termination message, limited to about 3KiB compressed
+	TaskOutputsDeliveryTerminationMessage TaskOutputsDelivery = "TerminationMessage"
+	// TaskOutputsDeliveryServer uploads the outputs to the KubeOpenCode server
+	TaskOutputsDeliveryServer TaskOutputsDelivery = "Server"
+)
+
+// TaskOutputsConfig configures how Task Pods report captured outputs
+// +kubebuilder:validation:XValidation:rule="!has(self.delivery) || self.delivery != 'Server' || (has(self.serverURL) && size(self.serverURL) > 0)",message="serverURL is required when delivery is Server"
+type TaskOutputsConfig struct {
+	// Delivery selects how Task Pods report captured outputs:
+	//   - TerminationMessage (default): through the agent container's termination
+	//     message, limited to about 3KiB compressed
+	//   - Server: uploaded to the KubeOpenCode server at serverURL, authenticated
+	//     with the Task Pod's ServiceAccount token, up to 512KiB. The termination
+	//     message is used if the upload fails. Requires curl or wget in the
+	//     executor image and server authentication to be enabled.
+	// +kubebuilder:validation:Enum=TerminationMessage;Server
+	// +optional
+	Delivery TaskOutputsDelivery `json:"delivery,omitempty"`
+
+	// ServerURL is the URL of the KubeOpenCode server reachable from Task Pods,
+	// e.g. "http://kubeopencode-server.kubeopencode-system.svc:2746".
+	// Required when delivery is Server.
+	// +optional
+	ServerURL string `json:"serverURL,omitempty"`
+}
+
+// ImageRewrite replaces the prefix of image references
+type ImageRewrite struct {
+	// Prefix is matched against the start of image references, e.g. "quay.io/".
+	// Images without a registry (e.g. "alpine:3") are matched in their fully
+	// qualified form ("docker.io/library/alpine:3").
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	Prefix string `json:"prefix"`
+
+	// Replacement replaces the matched prefix,
+	// e.g. "internal-mirror.example.com/quay/".
+	// +required
+	Replacement string `json:"replacement"`
+}
+
+// DefaultsConfig defines cluster-wide defaults for new resources
+type DefaultsConfig struct {
+	// AgentRef is the Agent used by Tasks that reference neither an Agent nor an
+	// AgentTemplate. The Agent is looked up in the Task's namespace; Tasks in
+	// namespaces without it are left unchanged.
+	// A namespace can override it with the "kubeopencode.io/default-agent" annotation.
+	// If not specified, the Agent named "default" is used.
+	// The default is applied by the mutating admission webhook, which is disabled
+	// by default; without it, Tasks must set agentRef or templateRef.
+	// +optional
+	AgentRef *AgentReference `json:"agentRef,omitempty"`
 }
 
 // CleanupConfig defines cleanup policies for completed/failed Tasks.
@@ -89,6 +281,51 @@ type CleanupConfig struct {
 	// +optional
 	// +kubebuilder:validation:Minimum=0
 	MaxRetainedTasks *int32 `json:"maxRetainedTasks,omitempty"`
+
+	// Archive sends each Task to an archive sink before cleanup deletes it,
+	// so that nothing deleted by TTL or retention is lost.
+	// If unset, Tasks are deleted without archiving.
+	// +optional
+	Archive *CleanupArchiveConfig `json:"archive,omitempty"`
+}
+
+// ArchiveFailurePolicy defines what cleanup does when archiving a Task fails.
+// +kubebuilder:validation:Enum=Retry;Ignore
+type ArchiveFailurePolicy string
+
+const (
+	// ArchiveFailurePolicyRetry keeps the Task and retries archiving with backoff.
+	ArchiveFailurePolicyRetry ArchiveFailurePolicy = "Retry"
+	// ArchiveFailurePolicyIgnore deletes the Task even though archiving failed.
+	ArchiveFailurePolicyIgnore ArchiveFailurePolicy = "Ignore"
+)
+
+// CleanupArchiveConfig configures the archive sink Tasks are sent to before cleanup.
+// The controller POSTs one JSON record per Task to the URL, containing the Task,
+// the cleanup policy that deleted it, and optionally the final logs of its Pod.
+// Any 2xx response counts as archived.
+type CleanupArchiveConfig struct {
+	// URL is the HTTP(S) endpoint the archive records are POSTed to.
+	// Example: https://archive.example.com/kubeopencode/tasks
+	// +required
+	// +kubebuilder:validation:Pattern=`^https?://.+$`
+	URL string `json:"url"`
+
+	// HeadersSecretRef references a Secret whose keys and values are sent as HTTP
+	// headers with each record (e.g., Authorization: Bearer <token>).
+	// +optional
+	HeadersSecretRef *corev1.SecretReference `json:"headersSecretRef,omitempty"`
+
+	// IncludeLogs adds the final logs of the Task Pod's containers to the record,
+	// if the Pod still exists.
+	// +optional
+	IncludeLogs bool `json:"includeLogs,omitempty"`
+
+	// FailurePolicy defines what happens when archiving fails.
+	// Retry (default) keeps the Task until it is archived; Ignore deletes it anyway.
+	// +optional
+	// +kubebuilder:default=Retry
+	FailurePolicy ArchiveFailurePolicy `json:"failurePolicy,omitempty"`
 }
 
 // SystemImageConfig configures the KubeOpenCode system image used for internal components
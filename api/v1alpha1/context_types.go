@@ -7,7 +7,7 @@ import (
 )
 
 // ContextType defines the type of context source
-// +kubebuilder:validation:Enum=Text;ConfigMap;Git;Runtime;URL
+// +kubebuilder:validation:Enum=Text;ConfigMap;Git;Runtime;URL;TaskOutput;Vault;Archive
 type ContextType string
 
 const (
@@ -33,9 +33,47 @@ const (
 	//   - External documentation or guidelines
 	//   - Dynamic configuration from external services
 	ContextTypeURL ContextType = "URL"
+
+	// ContextTypeTaskOutput represents the captured outputs of another Task.
+	// The referenced Task must have completed with spec.captureOutputs enabled.
+	//
+	// Use cases:
+	//   - Simple pipelines ("analyze the report produced by task X")
+	//   - Handing findings from an investigation Task to a fix Task
+	ContextTypeTaskOutput ContextType = "TaskOutput"
+
+	// ContextTypeVault represents secret values read from a HashiCorp Vault KV path.
+	// The values are fetched at task execution time by an init container that
+	// authenticates with the Task Pod's ServiceAccount (Vault Kubernetes auth),
+	// so secrets never have to be mirrored into Kubernetes Secrets.
+	ContextTypeVault ContextType = "Vault"
+
+	// ContextTypeArchive represents a tar or zip archive fetched from a remote
+	// HTTP/HTTPS URL and extracted into a directory at task execution time.
+	//
+	// Use cases:
+	//   - Release bundles or build artifacts for the agent to inspect
+	//   - Documentation snapshots published as archives
+	ContextTypeArchive ContextType = "Archive"
+)
+
+// ArchiveFormat is the format of an Archive context
+// +kubebuilder:validation:Enum=TarGz;Tar;Zip
+type ArchiveFormat string
+
+const (
+	// ArchiveFormatTarGz is a gzip-compressed tar archive (.tar.gz, .tgz)
+	ArchiveFormatTarGz ArchiveFormat = "TarGz"
+
+	// ArchiveFormatTar is an uncompressed tar archive (.tar)
+	ArchiveFormatTar ArchiveFormat = "Tar"
+
+	// ArchiveFormatZip is a zip archive (.zip)
+	ArchiveFormatZip ArchiveFormat = "Zip"
 )
 
 // ConfigMapContext references a ConfigMap for context content.
+// +kubebuilder:validation:XValidation:rule="!(has(self.key) && has(self.items))",message="only one of key or items can be specified"
 type ConfigMapContext struct {
 	// Name of the ConfigMap
 	// +required
@@ -43,14 +81,52 @@ type ConfigMapContext struct {
 
 	// Key specifies a single key to mount as a file.
 	// If not specified, all keys are mounted as files in the directory.
+	// Mutually exclusive with Items.
 	// +optional
 	Key string `json:"key,omitempty"`
 
+	// Items selects specific keys of the ConfigMap and maps them to files.
+	// When MountPath is specified, only the listed keys are written under the
+	// mount directory, each at its own relative path and with its own file mode.
+	// When MountPath is empty, only the listed keys are appended to the context file.
+	// Mutually exclusive with Key.
+	//
+	// Example:
+	//   items:
+	//     - key: run-tests.sh
+	//       path: bin/run-tests
+	//       mode: 493  # 0755
+	//     - key: README.md
+	// +optional
+	// +listType=atomic
+	Items []ConfigMapKeyToPath `json:"items,omitempty"`
+
 	// Optional specifies whether the ConfigMap must exist.
 	// +optional
 	Optional *bool `json:"optional,omitempty"`
 }
 
+// ConfigMapKeyToPath maps a ConfigMap key to a file within the context mount directory.
+type ConfigMapKeyToPath struct {
+	// Key is the ConfigMap key to select.
+	// +required
+	Key string `json:"key"`
+
+	// Path is the relative file path (within mountPath) the key is written to.
+	// May contain subdirectories (e.g., "bin/run-tests") but must not be absolute
+	// or contain "..". Defaults to the key name.
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// Mode is the file permission mode for this file (e.g., 0755 for executable scripts).
+	// Overrides the context-level fileMode for this file.
+	// If neither is specified, defaults to 0644.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=511
+	Mode *int32 `json:"mode,omitempty"`
+}
+
 // GitContext references content from a Git repository.
 type GitContext struct {
 	// Repository is the Git repository URL.
@@ -65,6 +141,9 @@ type GitContext struct {
 	//   - If Path is empty (entire repo): The mounted directory WILL contain .git/
 	//   - If Path is specified (subdirectory): The mounted directory will NOT contain .git/
 	//
+	// When Path is specified, git-init uses a sparse checkout so that only Path
+	// is fetched and checked out (unless RecurseSubmodules is enabled).
+	//
 	// Example: ".claude/", "docs/guide.md"
 	// +optional
 	Path string `json:"path,omitempty"`
@@ -152,10 +231,55 @@ type GitSecretReference struct {
 //   - How to query Task/Workflow information via kubectl
 //   - Understanding of task.md structure and context mounting
 //
-// This context type has no configurable fields - the content is generated
-// by the controller at runtime.
+// Optionally, it can also snapshot live cluster resources into the workspace
+// so that ops-oriented agents see actual cluster state.
 type RuntimeContext struct {
-	// No fields - content is generated by the controller
+	// Resources declares cluster resources to snapshot when the Task starts.
+	// Each entry is written as <name>.yaml under the context's mountPath
+	// (default: .kubeopencode/cluster-state). Resources are read with the Task
+	// Pod's ServiceAccount, which needs get/list permissions on them.
+	// Only effective for templateRef Tasks, whose workspace lives in the Task Pod.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Resources []ResourceSnapshot `json:"resources,omitempty"`
+}
+
+// ResourceSnapshot selects cluster resources to write into the workspace.
+// Either a single object (ResourceName) or a filtered list (selectors) is collected.
+// +kubebuilder:validation:XValidation:rule="self.kind != 'Secret'",message="Secrets cannot be snapshotted into the workspace"
+// +kubebuilder:validation:XValidation:rule="!has(self.resourceName) || (!has(self.labelSelector) && !has(self.fieldSelector))",message="resourceName cannot be combined with labelSelector or fieldSelector"
+type ResourceSnapshot struct {
+	// Name identifies this snapshot and is used as the file name (<name>.yaml).
+	// +required
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=63
+	Name string `json:"name"`
+
+	// APIVersion of the resource (e.g., "v1", "apps/v1").
+	// +required
+	APIVersion string `json:"apiVersion"`
+
+	// Kind of the resource (e.g., "Deployment", "Event").
+	// +required
+	Kind string `json:"kind"`
+
+	// Namespace to read from. Defaults to the Task's namespace.
+	// Ignored for cluster-scoped resources.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// ResourceName selects a single object by name. If empty, all matching objects are listed.
+	// +optional
+	ResourceName string `json:"resourceName,omitempty"`
+
+	// LabelSelector filters listed objects (e.g., "app=web").
+	// +optional
+	LabelSelector string `json:"labelSelector,omitempty"`
+
+	// FieldSelector filters listed objects (e.g., "involvedObject.name=web-0" for Events).
+	// +optional
+	FieldSelector string `json:"fieldSelector,omitempty"`
 }
 
 // URLContext references content from a remote HTTP/HTTPS URL.
@@ -192,6 +316,13 @@ type URLContext struct {
 	// +optional
 	// +kubebuilder:default=30
 	Timeout *int32 `json:"timeout,omitempty"`
+
+	// SHA256 is the expected hex-encoded SHA-256 digest of the fetched content.
+	// When set, the content is verified before the agent starts, and a mismatch
+	// fails the Task with a ContextError condition.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[a-fA-F0-9]{64}$`
+	SHA256 string `json:"sha256,omitempty"`
 }
 
 // URLSecretReference references a Secret for URL authentication.
@@ -201,13 +332,126 @@ type URLSecretReference struct {
 	Name string `json:"name"`
 }
 
+// TaskOutputContext references the captured outputs of another Task.
+type TaskOutputContext struct {
+	// Name of the Task in the same namespace whose outputs are used.
+	// The Task must be in the Completed phase.
+	// +required
+	Name string `json:"name"`
+
+	// Files selects specific output files. If not specified, all captured
+	// output files are used.
+	// +optional
+	// +listType=atomic
+	Files []string `json:"files,omitempty"`
+
+	// Optional specifies whether the Task and its outputs must exist.
+	// When true, a missing Task, an unfinished Task, or a Task without
+	// captured outputs results in an empty context instead of an error.
+	// +optional
+	Optional *bool `json:"optional,omitempty"`
+}
+
+// VaultContext reads secret values from a HashiCorp Vault KV secrets engine.
+type VaultContext struct {
+	// Address is the Vault server address.
+	// Example: "https://vault.example.com:8200"
+	// +required
+	// +kubebuilder:validation:Pattern=`^https?://`
+	Address string `json:"address"`
+
+	// Role is the Vault role used to log in with the Kubernetes auth method.
+	// The role must be bound to the Task Pod's ServiceAccount.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Role string `json:"role"`
+
+	// AuthMount is the mount path of the Kubernetes auth method.
+	// Defaults to "kubernetes".
+	// +optional
+	AuthMount string `json:"authMount,omitempty"`
+
+	// Namespace is the Vault Enterprise namespace, sent as X-Vault-Namespace.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Engine is the mount path of the KV secrets engine.
+	// Defaults to "secret".
+	// +optional
+	Engine string `json:"engine,omitempty"`
+
+	// Path is the secret path within the KV secrets engine.
+	// Example: "teams/payments/ci"
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Path string `json:"path"`
+
+	// KVVersion is the version of the KV secrets engine (1 or 2).
+	// Defaults to 2.
+	// +optional
+	// +kubebuilder:validation:Enum=1;2
+	KVVersion *int32 `json:"kvVersion,omitempty"`
+
+	// Keys selects specific keys of the secret and maps them to files.
+	// If not specified, every key is written to a file named after the key.
+	// +optional
+	// +listType=atomic
+	Keys []VaultKeyToPath `json:"keys,omitempty"`
+}
+
+// ArchiveContext references an archive fetched from a remote HTTP/HTTPS URL
+// and extracted into the context's mountPath.
+// Object storage is supported through HTTP(S) endpoints such as pre-signed URLs.
+type ArchiveContext struct {
+	// URLContext configures how the archive is downloaded (source, auth, TLS, timeout, sha256).
+	// SHA256 is verified against the archive itself, before extraction.
+	URLContext `json:",inline"`
+
+	// Format of the archive. If not specified, it is detected from the content.
+	// +optional
+	Format ArchiveFormat `json:"format,omitempty"`
+
+	// StripComponents removes the given number of leading path components from
+	// each entry when extracting, like tar --strip-components. Entries with fewer
+	// components are skipped.
+	// Example: 1 extracts "release-1.2.0/docs/a.md" as "docs/a.md".
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	StripComponents int32 `json:"stripComponents,omitempty"`
+}
+
+// VaultKeyToPath maps a Vault secret key to a file within the context mount directory.
+type VaultKeyToPath struct {
+	// Key is the secret key to select.
+	// +required
+	Key string `json:"key"`
+
+	// Path is the relative file path (within mountPath) the value is written to.
+	// Must not be absolute or contain "..". Defaults to the key name.
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// Mode is the file permission mode for this file.
+	// Overrides the context-level fileMode for this file.
+	// If neither is specified, defaults to 0600.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=511
+	Mode *int32 `json:"mode,omitempty"`
+}
+
 // ContextItem defines context with content and mount path.
 // Used directly in Task/Agent specs to provide additional context for task execution.
 // +kubebuilder:validation:XValidation:rule="self.type != 'Text' || has(self.text)",message="text is required when type is Text"
 // +kubebuilder:validation:XValidation:rule="self.type != 'ConfigMap' || has(self.configMap)",message="configMap is required when type is ConfigMap"
 // +kubebuilder:validation:XValidation:rule="self.type != 'Git' || has(self.git)",message="git is required when type is Git"
 // +kubebuilder:validation:XValidation:rule="self.type != 'URL' || has(self.url)",message="url is required when type is URL"
+// +kubebuilder:validation:XValidation:rule="self.type != 'TaskOutput' || has(self.taskOutput)",message="taskOutput is required when type is TaskOutput"
+// +kubebuilder:validation:XValidation:rule="self.type != 'Vault' || has(self.vault)",message="vault is required when type is Vault"
+// +kubebuilder:validation:XValidation:rule="self.type != 'Vault' || has(self.mountPath)",message="mountPath is required for Vault context type"
 // +kubebuilder:validation:XValidation:rule="self.type != 'Git' || has(self.mountPath)",message="mountPath is required for Git context type"
+// +kubebuilder:validation:XValidation:rule="self.type != 'Archive' || has(self.archive)",message="archive is required when type is Archive"
+// +kubebuilder:validation:XValidation:rule="self.type != 'Archive' || has(self.mountPath)",message="mountPath is required for Archive context type"
 type ContextItem struct {
 	// === Common Fields ===
 
@@ -228,7 +472,7 @@ type ContextItem struct {
 
 	// === Type and Mount Configuration ===
 
-	// Type of context source: Text, ConfigMap, Git, Runtime, Secret, or URL
+	// Type of context source: Text, ConfigMap, Git, Runtime, URL, TaskOutput, Vault, or Archive
 	// +required
 	Type ContextType `json:"type"`
 
@@ -240,13 +484,16 @@ type ContextItem struct {
 	//
 	// If not specified, the content is appended to task.md with XML tags.
 	//
-	// Note: For Runtime context type, MountPath is ignored - content is always
-	// appended to task.md.
+	// Note: For Runtime context type, the platform prompt is always appended to
+	// task.md; MountPath only sets the directory for resource snapshots.
+	// For Vault context type, MountPath is the directory secret files are written to.
+	// For Archive context type, MountPath is the directory the archive is extracted into.
 	// +optional
 	MountPath string `json:"mountPath,omitempty"`
 
 	// FileMode is the file permission mode for the mounted file (e.g., 0755 for executable scripts).
-	// Only applicable when MountPath is specified.
+	// Only applicable when MountPath is specified. For ConfigMap contexts mounted
+	// as a directory, it applies to every file copied into the directory.
 	// If not specified, defaults to 0644.
 	// +optional
 	FileMode *int32 `json:"fileMode,omitempty"`
@@ -276,4 +523,20 @@ type ContextItem struct {
 	// Fetches content from a remote HTTP/HTTPS URL at task execution time.
 	// +optional
 	URL *URLContext `json:"url,omitempty"`
+
+	// TaskOutput context (required when Type == "TaskOutput")
+	// Uses the files captured from another Task's outputs directory.
+	// +optional
+	TaskOutput *TaskOutputContext `json:"taskOutput,omitempty"`
+
+	// Vault context (required when Type == "Vault")
+	// Writes secret values from a Vault KV path as files under MountPath.
+	// Secret values are never stored in the context ConfigMap.
+	// +optional
+	Vault *VaultContext `json:"vault,omitempty"`
+
+	// Archive context (required when Type == "Archive")
+	// Downloads a tar or zip archive and extracts it under MountPath at task execution time.
+	// +optional
+	Archive *ArchiveContext `json:"archive,omitempty"`
 }
@@ -4,6 +4,7 @@ package v1alpha1
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // TaskPhase represents the current phase of a task
@@ -28,6 +29,12 @@ const (
 	TaskPhaseFailed TaskPhase = "Failed"
 )
 
+const (
+	// TaskRetryOfLabelKey is the label key added to Tasks created by retrying another Task.
+	// The value is the name of the retried Task.
+	TaskRetryOfLabelKey = "kubeopencode.io/retry-of"
+)
+
 const (
 	// ConditionTypeReady is the condition type for Task readiness
 	ConditionTypeReady = "Ready"
@@ -51,6 +58,8 @@ const (
 	ReasonCapacityAvailable = "CapacityAvailable"
 	// ReasonPodCreationError is the reason for Pod creation failures
 	ReasonPodCreationError = "PodCreationError"
+	// ReasonNetworkPolicyError is the reason for NetworkPolicy creation failures
+	ReasonNetworkPolicyError = "NetworkPolicyError"
 	// ReasonConfigMapCreationError is the reason for ConfigMap creation failures
 	ReasonConfigMapCreationError = "ConfigMapCreationError"
 	// ReasonPermissionRequired is the reason when agent needs permission approval
@@ -63,6 +72,11 @@ const (
 	ReasonAgentServerNotReady = "AgentServerNotReady"
 	// ReasonTimeout is the reason when a task is stopped due to exceeding its timeout
 	ReasonTimeout = "Timeout"
+	// ReasonPreempted is the reason when a running task is requeued for a higher-priority task
+	ReasonPreempted = "Preempted"
+	// ReasonInfrastructureError is the reason when the Task Pod was lost to its node
+	// (eviction, node drain or node failure) rather than to the agent exiting
+	ReasonInfrastructureError = "InfrastructureError"
 )
 
 // +genclient
@@ -133,6 +147,18 @@ type TaskSpec struct {
 	// +optional
 	Contexts []ContextItem `json:"contexts,omitempty"`
 
+	// Params are per-Task values that can be referenced from the description
+	// and from Text contexts (including Agent/Template contexts) as
+	// {{ .params.<key> }} or ${params.<key>}.
+	// The controller renders these references before creating the context ConfigMap.
+	//
+	// Example:
+	//   params:
+	//     service: checkout
+	//     environment: staging
+	// +optional
+	Params map[string]string `json:"params,omitempty"`
+
 	// AgentRef references a running Agent in the same namespace.
 	// The Task creates a lightweight Pod that connects to the Agent's server
 	// via `opencode run --attach`.
@@ -160,6 +186,113 @@ type TaskSpec struct {
 	// Example: "30m", "1h", "2h30m"
 	// +optional
 	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// Priority orders the Tasks of an Agent that is at capacity: a queued Task
+	// does not start while a queued Task with a higher priority waits for the
+	// same Agent. If the Agent's preemptionPolicy is LowerPriority, a Task also
+	// preempts the running Task with the lowest priority below its own, which
+	// is stopped and queued again.
+	// Has no effect on templateRef Tasks, which are never queued.
+	// Defaults to 0.
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+
+	// CaptureOutputs captures the files the agent writes to
+	// ${WORKSPACE_DIR}/.kubeopencode/outputs/ when the Task finishes, so other
+	// Tasks can consume them through a TaskOutput context.
+	// Captured files are stored in the "<task-name>-outputs" ConfigMap, owned by the Task.
+	// A result.json file among them is also parsed into status.result.
+	// Only top-level regular files are captured, and the compressed outputs must
+	// fit in the container termination message (about 3KiB).
+	// Only effective for templateRef Tasks, whose workspace lives in the Task Pod.
+	// +optional
+	CaptureOutputs bool `json:"captureOutputs,omitempty"`
+
+	// FailureSnapshot archives the workspace to a PVC when the Task fails,
+	// so engineers can inspect exactly what the agent left behind.
+	// Overrides AgentTemplate.spec.failureSnapshot.
+	// Only effective for templateRef Tasks, whose workspace lives in the Task Pod.
+	// +optional
+	FailureSnapshot *WorkspaceSnapshotConfig `json:"failureSnapshot,omitempty"`
+}
+
+// WorkspaceSnapshotConfig configures a workspace snapshot taken when a Task fails.
+// When the agent command exits with a non-zero code, the workspace directory is
+// archived as a tar.gz file into the referenced PVC under "<namespace>/".
+// Snapshots are never taken for successful Tasks.
+type WorkspaceSnapshotConfig struct {
+	// ClaimName is the name of a PersistentVolumeClaim in the Task's namespace
+	// where snapshot archives are written. The PVC should be ReadWriteMany if
+	// several Tasks may fail concurrently on different nodes.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	ClaimName string `json:"claimName"`
+
+	// MaxSize is the maximum workspace size to archive (e.g., "500Mi", "2Gi").
+	// Workspaces larger than this are skipped to protect the storage backend.
+	// Defaults to "1Gi".
+	// +optional
+	MaxSize string `json:"maxSize,omitempty"`
+
+	// Retention is the maximum number of snapshots kept per namespace in the PVC.
+	// Older snapshots are removed after a new one is written.
+	// Defaults to 10.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	Retention *int32 `json:"retention,omitempty"`
+}
+
+// WorkspaceSnapshotStatus records where a failure snapshot was written.
+type WorkspaceSnapshotStatus struct {
+	// ClaimName is the PVC containing the snapshot.
+	ClaimName string `json:"claimName"`
+
+	// Path is the archive path relative to the PVC root (e.g., "default/my-task-20260101120000.tar.gz").
+	Path string `json:"path"`
+}
+
+// TaskOutputsStatus records where a Task's captured outputs are stored.
+type TaskOutputsStatus struct {
+	// ConfigMapName is the ConfigMap holding the captured output files.
+	ConfigMapName string `json:"configMapName"`
+
+	// Files lists the captured output file names.
+	// +optional
+	Files []string `json:"files,omitempty"`
+}
+
+// TaskResultStatus is the verdict an agent reports in its result file
+// +kubebuilder:validation:Enum=Success;Failure;Neutral
+type TaskResultStatus string
+
+const (
+	// TaskResultSuccess means the agent achieved what the Task asked for
+	TaskResultSuccess TaskResultStatus = "Success"
+	// TaskResultFailure means the agent ran but reports a negative verdict,
+	// e.g. a review that requests changes
+	TaskResultFailure TaskResultStatus = "Failure"
+	// TaskResultNeutral means the agent reports no verdict, e.g. nothing to do
+	TaskResultNeutral TaskResultStatus = "Neutral"
+)
+
+// TaskResult is the machine-readable result an agent reported in
+// ${WORKSPACE_DIR}/.kubeopencode/outputs/result.json.
+type TaskResult struct {
+	// Status is the agent's verdict. It is independent of the Task phase, which
+	// reflects whether the agent ran successfully.
+	Status TaskResultStatus `json:"status"`
+
+	// Summary is a short human-readable description of the result.
+	// +kubebuilder:validation:MaxLength=1024
+	// +optional
+	Summary string `json:"summary,omitempty"`
+
+	// Fields holds task-specific structured values as a JSON object,
+	// e.g. {"approved": false, "findings": 3}.
+	// +optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	Fields *runtime.RawExtension `json:"fields,omitempty"`
 }
 
 // SessionInfo contains information about the OpenCode session associated with a Task.
@@ -245,10 +378,20 @@ type TaskExecutionStatus struct {
 	// +optional
 	TemplateRef *AgentTemplateReference `json:"templateRef,omitempty"`
 
-	// Kubernetes Pod name
+	// Kubernetes Pod name. With the Job execution backend, this is the
+	// most recent Pod of the Job.
 	// +optional
 	PodName string `json:"podName,omitempty"`
 
+	// Kubernetes Job name, set when the Task runs with the Job execution backend
+	// +optional
+	JobName string `json:"jobName,omitempty"`
+
+	// Reschedules is the number of times the Task Pod was recreated after a
+	// disruption. See the Agent's execution.onDisruption.
+	// +optional
+	Reschedules int32 `json:"reschedules,omitempty"`
+
 	// Session contains information about the OpenCode session created for this Task.
 	// Only populated for agentRef Tasks where the session can be resolved.
 	// +optional
@@ -262,6 +405,21 @@ type TaskExecutionStatus struct {
 	// +optional
 	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
 
+	// WorkspaceSnapshot is set when the Task failed and its workspace was archived.
+	// See spec.failureSnapshot.
+	// +optional
+	WorkspaceSnapshot *WorkspaceSnapshotStatus `json:"workspaceSnapshot,omitempty"`
+
+	// Outputs is set when the Task's outputs were captured.
+	// See spec.captureOutputs.
+	// +optional
+	Outputs *TaskOutputsStatus `json:"outputs,omitempty"`
+
+	// Result is set when the agent reported a valid result.json among its outputs.
+	// See spec.captureOutputs.
+	// +optional
+	Result *TaskResult `json:"result,omitempty"`
+
 	// Kubernetes standard conditions
 	// +optional
 	// +listType=map
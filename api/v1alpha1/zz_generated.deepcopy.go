@@ -7,7 +7,9 @@
 package v1alpha1
 
 import (
+	batchv1 "k8s.io/api/batch/v1"
 	"k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
@@ -106,6 +108,11 @@ func (in *AgentPodSpec) DeepCopyInto(out *AgentPodSpec) {
 		*out = new(v1.PodSecurityContext)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.PodSecurityDefaultsOptOut != nil {
+		in, out := &in.PodSecurityDefaultsOptOut, &out.PodSecurityDefaultsOptOut
+		*out = make([]PodSecurityDefault, len(*in))
+		copy(*out, *in)
+	}
 	if in.Lifecycle != nil {
 		in, out := &in.Lifecycle, &out.Lifecycle
 		*out = new(v1.Lifecycle)
@@ -225,6 +232,16 @@ func (in *AgentSpec) DeepCopyInto(out *AgentSpec) {
 		*out = new(QuotaConfig)
 		**out = **in
 	}
+	if in.Execution != nil {
+		in, out := &in.Execution, &out.Execution
+		*out = new(ExecutionConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NetworkPolicy != nil {
+		in, out := &in.NetworkPolicy, &out.NetworkPolicy
+		*out = new(TaskNetworkPolicyConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.CABundle != nil {
 		in, out := &in.CABundle, &out.CABundle
 		*out = new(CABundleConfig)
@@ -467,6 +484,16 @@ func (in *AgentTemplateSpec) DeepCopyInto(out *AgentTemplateSpec) {
 		*out = new(QuotaConfig)
 		**out = **in
 	}
+	if in.Execution != nil {
+		in, out := &in.Execution, &out.Execution
+		*out = new(ExecutionConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FailureSnapshot != nil {
+		in, out := &in.FailureSnapshot, &out.FailureSnapshot
+		*out = new(WorkspaceSnapshotConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AgentTemplateSpec.
@@ -501,6 +528,22 @@ func (in *AgentTemplateStatus) DeepCopy() *AgentTemplateStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArchiveContext) DeepCopyInto(out *ArchiveContext) {
+	*out = *in
+	in.URLContext.DeepCopyInto(&out.URLContext)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArchiveContext.
+func (in *ArchiveContext) DeepCopy() *ArchiveContext {
+	if in == nil {
+		return nil
+	}
+	out := new(ArchiveContext)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AssetMetadata) DeepCopyInto(out *AssetMetadata) {
 	*out = *in
@@ -566,6 +609,26 @@ func (in *CABundleReference) DeepCopy() *CABundleReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CleanupArchiveConfig) DeepCopyInto(out *CleanupArchiveConfig) {
+	*out = *in
+	if in.HeadersSecretRef != nil {
+		in, out := &in.HeadersSecretRef, &out.HeadersSecretRef
+		*out = new(v1.SecretReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CleanupArchiveConfig.
+func (in *CleanupArchiveConfig) DeepCopy() *CleanupArchiveConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CleanupArchiveConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CleanupConfig) DeepCopyInto(out *CleanupConfig) {
 	*out = *in
@@ -579,6 +642,11 @@ func (in *CleanupConfig) DeepCopyInto(out *CleanupConfig) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.Archive != nil {
+		in, out := &in.Archive, &out.Archive
+		*out = new(CleanupArchiveConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CleanupConfig.
@@ -594,6 +662,13 @@ func (in *CleanupConfig) DeepCopy() *CleanupConfig {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ConfigMapContext) DeepCopyInto(out *ConfigMapContext) {
 	*out = *in
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ConfigMapKeyToPath, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Optional != nil {
 		in, out := &in.Optional, &out.Optional
 		*out = new(bool)
@@ -611,6 +686,41 @@ func (in *ConfigMapContext) DeepCopy() *ConfigMapContext {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigMapKeyToPath) DeepCopyInto(out *ConfigMapKeyToPath) {
+	*out = *in
+	if in.Mode != nil {
+		in, out := &in.Mode, &out.Mode
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigMapKeyToPath.
+func (in *ConfigMapKeyToPath) DeepCopy() *ConfigMapKeyToPath {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigMapKeyToPath)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContextCacheConfig) DeepCopyInto(out *ContextCacheConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContextCacheConfig.
+func (in *ContextCacheConfig) DeepCopy() *ContextCacheConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ContextCacheConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ContextItem) DeepCopyInto(out *ContextItem) {
 	*out = *in
@@ -632,13 +742,28 @@ func (in *ContextItem) DeepCopyInto(out *ContextItem) {
 	if in.Runtime != nil {
 		in, out := &in.Runtime, &out.Runtime
 		*out = new(RuntimeContext)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.URL != nil {
 		in, out := &in.URL, &out.URL
 		*out = new(URLContext)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.TaskOutput != nil {
+		in, out := &in.TaskOutput, &out.TaskOutput
+		*out = new(TaskOutputContext)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Vault != nil {
+		in, out := &in.Vault, &out.Vault
+		*out = new(VaultContext)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Archive != nil {
+		in, out := &in.Archive, &out.Archive
+		*out = new(ArchiveContext)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContextItem.
@@ -831,6 +956,71 @@ func (in *CronTaskStatus) DeepCopy() *CronTaskStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DefaultsConfig) DeepCopyInto(out *DefaultsConfig) {
+	*out = *in
+	if in.AgentRef != nil {
+		in, out := &in.AgentRef, &out.AgentRef
+		*out = new(AgentReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DefaultsConfig.
+func (in *DefaultsConfig) DeepCopy() *DefaultsConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(DefaultsConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DisruptionPolicy) DeepCopyInto(out *DisruptionPolicy) {
+	*out = *in
+	if in.MaxReschedules != nil {
+		in, out := &in.MaxReschedules, &out.MaxReschedules
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DisruptionPolicy.
+func (in *DisruptionPolicy) DeepCopy() *DisruptionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(DisruptionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExecutionConfig) DeepCopyInto(out *ExecutionConfig) {
+	*out = *in
+	if in.Job != nil {
+		in, out := &in.Job, &out.Job
+		*out = new(JobExecutionConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OnDisruption != nil {
+		in, out := &in.OnDisruption, &out.OnDisruption
+		*out = new(DisruptionPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExecutionConfig.
+func (in *ExecutionConfig) DeepCopy() *ExecutionConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ExecutionConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExtraPort) DeepCopyInto(out *ExtraPort) {
 	*out = *in
@@ -981,6 +1171,21 @@ func (in *ImageMetadata) DeepCopy() *ImageMetadata {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageRewrite) DeepCopyInto(out *ImageRewrite) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageRewrite.
+func (in *ImageRewrite) DeepCopy() *ImageRewrite {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageRewrite)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ImageStatus) DeepCopyInto(out *ImageStatus) {
 	*out = *in
@@ -1029,6 +1234,36 @@ func (in *InitContainerOverrides) DeepCopy() *InitContainerOverrides {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobExecutionConfig) DeepCopyInto(out *JobExecutionConfig) {
+	*out = *in
+	if in.BackoffLimit != nil {
+		in, out := &in.BackoffLimit, &out.BackoffLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PodFailurePolicy != nil {
+		in, out := &in.PodFailurePolicy, &out.PodFailurePolicy
+		*out = new(batchv1.PodFailurePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ActiveDeadlineSeconds != nil {
+		in, out := &in.ActiveDeadlineSeconds, &out.ActiveDeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobExecutionConfig.
+func (in *JobExecutionConfig) DeepCopy() *JobExecutionConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(JobExecutionConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KubeOpenCodeConfig) DeepCopyInto(out *KubeOpenCodeConfig) {
 	*out = *in
@@ -1095,21 +1330,51 @@ func (in *KubeOpenCodeConfigSpec) DeepCopyInto(out *KubeOpenCodeConfigSpec) {
 		*out = new(SystemImageConfig)
 		**out = **in
 	}
+	if in.ImageRewrites != nil {
+		in, out := &in.ImageRewrites, &out.ImageRewrites
+		*out = make([]ImageRewrite, len(*in))
+		copy(*out, *in)
+	}
 	if in.Cleanup != nil {
 		in, out := &in.Cleanup, &out.Cleanup
 		*out = new(CleanupConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Defaults != nil {
+		in, out := &in.Defaults, &out.Defaults
+		*out = new(DefaultsConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Proxy != nil {
 		in, out := &in.Proxy, &out.Proxy
 		*out = new(ProxyConfig)
 		**out = **in
 	}
+	if in.PodSecurityDefaults != nil {
+		in, out := &in.PodSecurityDefaults, &out.PodSecurityDefaults
+		*out = new(PodSecurityDefaults)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NetworkPolicy != nil {
+		in, out := &in.NetworkPolicy, &out.NetworkPolicy
+		*out = new(TaskNetworkPolicyConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Outputs != nil {
+		in, out := &in.Outputs, &out.Outputs
+		*out = new(TaskOutputsConfig)
+		**out = **in
+	}
 	if in.Observability != nil {
 		in, out := &in.Observability, &out.Observability
 		*out = new(ObservabilitySpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ContextCache != nil {
+		in, out := &in.ContextCache, &out.ContextCache
+		*out = new(ContextCacheConfig)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeOpenCodeConfigSpec.
@@ -1309,6 +1574,41 @@ func (in *PodScheduling) DeepCopy() *PodScheduling {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodSecurityDefaults) DeepCopyInto(out *PodSecurityDefaults) {
+	*out = *in
+	if in.RunAsNonRoot != nil {
+		in, out := &in.RunAsNonRoot, &out.RunAsNonRoot
+		*out = new(bool)
+		**out = **in
+	}
+	if in.SeccompProfile != nil {
+		in, out := &in.SeccompProfile, &out.SeccompProfile
+		*out = new(v1.SeccompProfile)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReadOnlyRootFilesystem != nil {
+		in, out := &in.ReadOnlyRootFilesystem, &out.ReadOnlyRootFilesystem
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DropCapabilities != nil {
+		in, out := &in.DropCapabilities, &out.DropCapabilities
+		*out = make([]v1.Capability, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodSecurityDefaults.
+func (in *PodSecurityDefaults) DeepCopy() *PodSecurityDefaults {
+	if in == nil {
+		return nil
+	}
+	out := new(PodSecurityDefaults)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProxyConfig) DeepCopyInto(out *ProxyConfig) {
 	*out = *in
@@ -1557,9 +1857,29 @@ func (in *RegistryStatus) DeepCopy() *RegistryStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceSnapshot) DeepCopyInto(out *ResourceSnapshot) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceSnapshot.
+func (in *ResourceSnapshot) DeepCopy() *ResourceSnapshot {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceSnapshot)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RuntimeContext) DeepCopyInto(out *RuntimeContext) {
 	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]ResourceSnapshot, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RuntimeContext.
@@ -1849,6 +2169,21 @@ func (in *TaskExecutionStatus) DeepCopyInto(out *TaskExecutionStatus) {
 		in, out := &in.CompletionTime, &out.CompletionTime
 		*out = (*in).DeepCopy()
 	}
+	if in.WorkspaceSnapshot != nil {
+		in, out := &in.WorkspaceSnapshot, &out.WorkspaceSnapshot
+		*out = new(WorkspaceSnapshotStatus)
+		**out = **in
+	}
+	if in.Outputs != nil {
+		in, out := &in.Outputs, &out.Outputs
+		*out = new(TaskOutputsStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Result != nil {
+		in, out := &in.Result, &out.Result
+		*out = new(TaskResult)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]metav1.Condition, len(*in))
@@ -1900,6 +2235,108 @@ func (in *TaskList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TaskNetworkPolicyConfig) DeepCopyInto(out *TaskNetworkPolicyConfig) {
+	*out = *in
+	if in.Egress != nil {
+		in, out := &in.Egress, &out.Egress
+		*out = make([]networkingv1.NetworkPolicyEgressRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TaskNetworkPolicyConfig.
+func (in *TaskNetworkPolicyConfig) DeepCopy() *TaskNetworkPolicyConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(TaskNetworkPolicyConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TaskOutputContext) DeepCopyInto(out *TaskOutputContext) {
+	*out = *in
+	if in.Files != nil {
+		in, out := &in.Files, &out.Files
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Optional != nil {
+		in, out := &in.Optional, &out.Optional
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TaskOutputContext.
+func (in *TaskOutputContext) DeepCopy() *TaskOutputContext {
+	if in == nil {
+		return nil
+	}
+	out := new(TaskOutputContext)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TaskOutputsConfig) DeepCopyInto(out *TaskOutputsConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TaskOutputsConfig.
+func (in *TaskOutputsConfig) DeepCopy() *TaskOutputsConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(TaskOutputsConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TaskOutputsStatus) DeepCopyInto(out *TaskOutputsStatus) {
+	*out = *in
+	if in.Files != nil {
+		in, out := &in.Files, &out.Files
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TaskOutputsStatus.
+func (in *TaskOutputsStatus) DeepCopy() *TaskOutputsStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TaskOutputsStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TaskResult) DeepCopyInto(out *TaskResult) {
+	*out = *in
+	if in.Fields != nil {
+		in, out := &in.Fields, &out.Fields
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TaskResult.
+func (in *TaskResult) DeepCopy() *TaskResult {
+	if in == nil {
+		return nil
+	}
+	out := new(TaskResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TaskSpec) DeepCopyInto(out *TaskSpec) {
 	*out = *in
@@ -1915,6 +2352,13 @@ func (in *TaskSpec) DeepCopyInto(out *TaskSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Params != nil {
+		in, out := &in.Params, &out.Params
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	if in.AgentRef != nil {
 		in, out := &in.AgentRef, &out.AgentRef
 		*out = new(AgentReference)
@@ -1930,6 +2374,11 @@ func (in *TaskSpec) DeepCopyInto(out *TaskSpec) {
 		*out = new(metav1.Duration)
 		**out = **in
 	}
+	if in.FailureSnapshot != nil {
+		in, out := &in.FailureSnapshot, &out.FailureSnapshot
+		*out = new(WorkspaceSnapshotConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TaskSpec.
@@ -2066,6 +2515,53 @@ func (in *URLSecretReference) DeepCopy() *URLSecretReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultContext) DeepCopyInto(out *VaultContext) {
+	*out = *in
+	if in.KVVersion != nil {
+		in, out := &in.KVVersion, &out.KVVersion
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Keys != nil {
+		in, out := &in.Keys, &out.Keys
+		*out = make([]VaultKeyToPath, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultContext.
+func (in *VaultContext) DeepCopy() *VaultContext {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultContext)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultKeyToPath) DeepCopyInto(out *VaultKeyToPath) {
+	*out = *in
+	if in.Mode != nil {
+		in, out := &in.Mode, &out.Mode
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultKeyToPath.
+func (in *VaultKeyToPath) DeepCopy() *VaultKeyToPath {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultKeyToPath)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VolumePersistence) DeepCopyInto(out *VolumePersistence) {
 	*out = *in
@@ -2085,3 +2581,38 @@ func (in *VolumePersistence) DeepCopy() *VolumePersistence {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkspaceSnapshotConfig) DeepCopyInto(out *WorkspaceSnapshotConfig) {
+	*out = *in
+	if in.Retention != nil {
+		in, out := &in.Retention, &out.Retention
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkspaceSnapshotConfig.
+func (in *WorkspaceSnapshotConfig) DeepCopy() *WorkspaceSnapshotConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkspaceSnapshotConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkspaceSnapshotStatus) DeepCopyInto(out *WorkspaceSnapshotStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkspaceSnapshotStatus.
+func (in *WorkspaceSnapshotStatus) DeepCopy() *WorkspaceSnapshotStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkspaceSnapshotStatus)
+	in.DeepCopyInto(out)
+	return out
+}
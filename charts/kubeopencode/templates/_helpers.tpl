@@ -67,6 +67,17 @@ Controller selector labels
 app.kubernetes.io/component: controller
 {{- end }}
 
+{{/*
+Name of the Secret holding the webhook serving certificate
+*/}}
+{{- define "kubeopencode.webhook.secretName" -}}
+{{- if .Values.controller.webhook.certManager.enabled }}
+{{- printf "%s-webhook-tls" (include "kubeopencode.fullname" .) }}
+{{- else }}
+{{- required "controller.webhook.secretName is required when cert-manager is disabled" .Values.controller.webhook.secretName }}
+{{- end }}
+{{- end }}
+
 {{/*
 Create the name of the controller service account to use
 */}}
@@ -150,3 +161,13 @@ Server image
 {{- $tag := .Values.server.image.tag | default .Chart.AppVersion }}
 {{- printf "%s:%s" .Values.server.image.repository $tag }}
 {{- end }}
+
+{{/*
+Tracing environment for the controller and server containers
+*/}}
+{{- define "kubeopencode.tracing.env" -}}
+{{- if .Values.tracing.endpoint }}
+- name: OTEL_EXPORTER_OTLP_ENDPOINT
+  value: {{ .Values.tracing.endpoint | quote }}
+{{- end }}
+{{- end }}
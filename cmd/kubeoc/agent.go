@@ -43,6 +43,7 @@ func newAgentCmd() *cobra.Command {
 		Use:   "agent",
 		Short: "Interact with KubeOpenCode agents",
 	}
+	cmd.AddCommand(listCommand(newGetAgentsCmd(), "agent"))
 	cmd.AddCommand(newAgentAttachCmd())
 	cmd.AddCommand(newAgentSuspendCmd())
 	cmd.AddCommand(newAgentResumeCmd())
@@ -12,8 +12,34 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+	servertypes "github.com/kubeopencode/kubeopencode/internal/server/types"
 )
 
+func init() {
+	rootCmd.AddCommand(newTemplateCmd())
+}
+
+func newTemplateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "template",
+		Aliases: []string{"agenttemplate"},
+		Short:   "Manage KubeOpenCode agent templates",
+	}
+	cmd.AddCommand(listCommand(newGetAgentTemplatesCmd(), "template"))
+	return cmd
+}
+
+// templateSummary holds the fields of an agent template shown by the CLI, read
+// either from the AgentTemplate resource or from the API server.
+type templateSummary struct {
+	Namespace          string
+	Name               string
+	Agents             int
+	ExecutorImage      string
+	WorkspaceDir       string
+	ServiceAccountName string
+}
+
 // newGetAgentTemplatesCmd creates the "get agenttemplates" subcommand.
 func newGetAgentTemplatesCmd() *cobra.Command {
 	var (
@@ -37,32 +63,71 @@ Examples:
   kubeoc get agenttemplates --wide
   kubeoc get agenttemplates -o json`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cfg, err := getKubeConfig()
-			if err != nil {
-				return fmt.Errorf("cannot connect to cluster: %w", err)
-			}
+			var summaries []templateSummary
 
-			k8sClient, err := client.New(cfg, client.Options{Scheme: scheme})
+			sc, err := newServerClient()
 			if err != nil {
-				return fmt.Errorf("failed to create kubernetes client: %w", err)
+				return err
 			}
+			if sc != nil {
+				templates, err := sc.listAgentTemplates(cmd.Context(), namespace)
+				if err != nil {
+					return fmt.Errorf("failed to list agent templates: %w", err)
+				}
+				if handled, err := outputFormat(output, servertypes.AgentTemplateListResponse{Templates: templates, Total: len(templates)}); handled {
+					return err
+				}
+				for _, tmpl := range templates {
+					summaries = append(summaries, templateSummary{
+						Namespace:          tmpl.Namespace,
+						Name:               tmpl.Name,
+						Agents:             tmpl.AgentCount,
+						ExecutorImage:      tmpl.ExecutorImage,
+						WorkspaceDir:       tmpl.WorkspaceDir,
+						ServiceAccountName: tmpl.ServiceAccountName,
+					})
+				}
+			} else {
+				cfg, err := getKubeConfig()
+				if err != nil {
+					return fmt.Errorf("cannot connect to cluster: %w", err)
+				}
 
-			var templates kubeopenv1alpha1.AgentTemplateList
-			listOpts := []client.ListOption{}
-			if namespace != "" {
-				listOpts = append(listOpts, client.InNamespace(namespace))
-			}
+				k8sClient, err := client.New(cfg, client.Options{Scheme: scheme})
+				if err != nil {
+					return fmt.Errorf("failed to create kubernetes client: %w", err)
+				}
 
-			if err := k8sClient.List(cmd.Context(), &templates, listOpts...); err != nil {
-				return fmt.Errorf("failed to list agent templates: %w", err)
-			}
+				var templates kubeopenv1alpha1.AgentTemplateList
+				listOpts := []client.ListOption{}
+				if namespace != "" {
+					listOpts = append(listOpts, client.InNamespace(namespace))
+				}
 
-			// Handle structured output formats
-			if handled, err := outputFormat(output, templates); handled {
-				return err
+				if err := k8sClient.List(cmd.Context(), &templates, listOpts...); err != nil {
+					return fmt.Errorf("failed to list agent templates: %w", err)
+				}
+
+				// Handle structured output formats
+				if handled, err := outputFormat(output, templates); handled {
+					return err
+				}
+
+				// Count referencing agents per template
+				refCounts := countReferencingAgents(cmd.Context(), k8sClient, templates.Items)
+				for i, tmpl := range templates.Items {
+					summaries = append(summaries, templateSummary{
+						Namespace:          tmpl.Namespace,
+						Name:               tmpl.Name,
+						Agents:             refCounts[i],
+						ExecutorImage:      tmpl.Spec.ExecutorImage,
+						WorkspaceDir:       tmpl.Spec.WorkspaceDir,
+						ServiceAccountName: tmpl.Spec.ServiceAccountName,
+					})
+				}
 			}
 
-			if len(templates.Items) == 0 {
+			if len(summaries) == 0 {
 				if namespace != "" {
 					fmt.Printf("No agent templates found in namespace %q\n", namespace)
 				} else {
@@ -71,9 +136,6 @@ Examples:
 				return nil
 			}
 
-			// Count referencing agents per template
-			refCounts := countReferencingAgents(cmd.Context(), k8sClient, templates.Items)
-
 			w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
 			if wide {
 				_, _ = fmt.Fprintln(w, "NAMESPACE\tNAME\tAGENTS\tEXECUTOR IMAGE\tWORKSPACE\tSERVICE ACCOUNT")
@@ -81,19 +143,19 @@ Examples:
 				_, _ = fmt.Fprintln(w, "NAMESPACE\tNAME\tAGENTS")
 			}
 
-			for i, tmpl := range templates.Items {
+			for _, tmpl := range summaries {
 				if wide {
-					image := tmpl.Spec.ExecutorImage
+					image := tmpl.ExecutorImage
 					if len(image) > 50 {
 						image = "..." + image[len(image)-47:]
 					}
 
 					_, _ = fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\n",
-						tmpl.Namespace, tmpl.Name, refCounts[i],
-						image, tmpl.Spec.WorkspaceDir, tmpl.Spec.ServiceAccountName)
+						tmpl.Namespace, tmpl.Name, tmpl.Agents,
+						image, tmpl.WorkspaceDir, tmpl.ServiceAccountName)
 				} else {
 					_, _ = fmt.Fprintf(w, "%s\t%s\t%d\n",
-						tmpl.Namespace, tmpl.Name, refCounts[i])
+						tmpl.Namespace, tmpl.Name, tmpl.Agents)
 				}
 			}
 
@@ -340,6 +340,7 @@ func TestRootCmdStructure(t *testing.T) {
 		"get":        false,
 		"agent":      false,
 		"task":       false,
+		"template":   false,
 		"crontask":   false,
 	}
 
@@ -383,6 +384,7 @@ func TestAgentCmdStructure(t *testing.T) {
 	agentCmd := newAgentCmd()
 	subCmds := agentCmd.Commands()
 	wantCmds := map[string]bool{
+		"list":    false,
 		"attach":  false,
 		"suspend": false,
 		"resume":  false,
@@ -407,8 +409,13 @@ func TestTaskCmdStructure(t *testing.T) {
 	taskCmd := newTaskCmd()
 	subCmds := taskCmd.Commands()
 	wantCmds := map[string]bool{
-		"stop": false,
-		"logs": false,
+		"create": false,
+		"run":    false,
+		"get":    false,
+		"list":   false,
+		"stop":   false,
+		"logs":   false,
+		"attach": false,
 	}
 
 	for _, cmd := range subCmds {
@@ -5,6 +5,7 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"text/tabwriter"
 
@@ -13,6 +14,7 @@ import (
 	"sigs.k8s.io/yaml"
 
 	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+	servertypes "github.com/kubeopencode/kubeopencode/internal/server/types"
 )
 
 func init() {
@@ -79,32 +81,54 @@ Examples:
   kubeoc get agents -o json
   kubeoc get agents -o yaml`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cfg, err := getKubeConfig()
-			if err != nil {
-				return fmt.Errorf("cannot connect to cluster: %w", err)
-			}
+			var summaries []agentSummary
 
-			k8sClient, err := client.New(cfg, client.Options{Scheme: scheme})
+			sc, err := newServerClient()
 			if err != nil {
-				return fmt.Errorf("failed to create kubernetes client: %w", err)
+				return err
 			}
+			if sc != nil {
+				agents, err := sc.listAgents(cmd.Context(), namespace)
+				if err != nil {
+					return fmt.Errorf("failed to list agents: %w", err)
+				}
+				if handled, err := outputFormat(output, servertypes.AgentListResponse{Agents: agents, Total: len(agents)}); handled {
+					return err
+				}
+				for i := range agents {
+					summaries = append(summaries, summarizeAgentResponse(&agents[i]))
+				}
+			} else {
+				cfg, err := getKubeConfig()
+				if err != nil {
+					return fmt.Errorf("cannot connect to cluster: %w", err)
+				}
 
-			var agents kubeopenv1alpha1.AgentList
-			listOpts := []client.ListOption{}
-			if namespace != "" {
-				listOpts = append(listOpts, client.InNamespace(namespace))
-			}
+				k8sClient, err := client.New(cfg, client.Options{Scheme: scheme})
+				if err != nil {
+					return fmt.Errorf("failed to create kubernetes client: %w", err)
+				}
 
-			if err := k8sClient.List(cmd.Context(), &agents, listOpts...); err != nil {
-				return fmt.Errorf("failed to list agents: %w", err)
-			}
+				var agents kubeopenv1alpha1.AgentList
+				listOpts := []client.ListOption{}
+				if namespace != "" {
+					listOpts = append(listOpts, client.InNamespace(namespace))
+				}
 
-			// Handle structured output formats
-			if handled, err := outputFormat(output, agents); handled {
-				return err
+				if err := k8sClient.List(cmd.Context(), &agents, listOpts...); err != nil {
+					return fmt.Errorf("failed to list agents: %w", err)
+				}
+
+				// Handle structured output formats
+				if handled, err := outputFormat(output, agents); handled {
+					return err
+				}
+				for i := range agents.Items {
+					summaries = append(summaries, summarizeAgent(&agents.Items[i]))
+				}
 			}
 
-			if len(agents.Items) == 0 {
+			if len(summaries) == 0 {
 				if namespace != "" {
 					fmt.Printf("No agents found in namespace %q\n", namespace)
 				} else {
@@ -113,44 +137,7 @@ Examples:
 				return nil
 			}
 
-			w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
-			if wide {
-				_, _ = fmt.Fprintln(w, "NAMESPACE\tNAME\tSTATUS\tPROFILE\tTEMPLATE")
-			} else {
-				_, _ = fmt.Fprintln(w, "NAMESPACE\tNAME\tSTATUS")
-			}
-
-			for _, agent := range agents.Items {
-				var status string
-				switch {
-				case agent.Status.Suspended:
-					status = "Suspended"
-				case agent.Status.Ready:
-					status = "Ready"
-				default:
-					status = "Not Ready"
-				}
-
-				if wide {
-					profile := agent.Spec.Profile
-					if len(profile) > 50 {
-						profile = profile[:47] + "..."
-					}
-
-					template := "-"
-					if agent.Spec.TemplateRef != nil {
-						template = agent.Spec.TemplateRef.Name
-					}
-
-					_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
-						agent.Namespace, agent.Name, status, profile, template)
-				} else {
-					_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n",
-						agent.Namespace, agent.Name, status)
-				}
-			}
-
-			_ = w.Flush()
+			printAgentTable(os.Stdout, summaries, wide)
 			return nil
 		},
 	}
@@ -161,3 +148,83 @@ Examples:
 
 	return cmd
 }
+
+// agentSummary holds the fields of an agent shown by the CLI, read either from
+// the Agent resource or from the API server.
+type agentSummary struct {
+	Namespace string
+	Name      string
+	Status    string
+	Profile   string
+	Template  string
+}
+
+// agentStatus returns the status column of an agent.
+func agentStatus(suspended, ready bool) string {
+	switch {
+	case suspended:
+		return "Suspended"
+	case ready:
+		return "Ready"
+	default:
+		return "Not Ready"
+	}
+}
+
+// summarizeAgent returns the summary of an Agent resource.
+func summarizeAgent(agent *kubeopenv1alpha1.Agent) agentSummary {
+	summary := agentSummary{
+		Namespace: agent.Namespace,
+		Name:      agent.Name,
+		Status:    agentStatus(agent.Status.Suspended, agent.Status.Ready),
+		Profile:   agent.Spec.Profile,
+	}
+	if agent.Spec.TemplateRef != nil {
+		summary.Template = agent.Spec.TemplateRef.Name
+	}
+	return summary
+}
+
+// summarizeAgentResponse returns the summary of an agent returned by the API server.
+func summarizeAgentResponse(agent *servertypes.AgentResponse) agentSummary {
+	summary := agentSummary{
+		Namespace: agent.Namespace,
+		Name:      agent.Name,
+		Status:    agentStatus(false, false),
+		Profile:   agent.Profile,
+	}
+	if agent.ServerStatus != nil {
+		summary.Status = agentStatus(agent.ServerStatus.Suspended, agent.ServerStatus.Ready)
+	}
+	if agent.TemplateRef != nil {
+		summary.Template = agent.TemplateRef.Name
+	}
+	return summary
+}
+
+// printAgentTable prints agents as a table.
+func printAgentTable(out io.Writer, agents []agentSummary, wide bool) {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	if wide {
+		_, _ = fmt.Fprintln(w, "NAMESPACE\tNAME\tSTATUS\tPROFILE\tTEMPLATE")
+	} else {
+		_, _ = fmt.Fprintln(w, "NAMESPACE\tNAME\tSTATUS")
+	}
+
+	for _, agent := range agents {
+		if wide {
+			profile := agent.Profile
+			if len(profile) > 50 {
+				profile = profile[:47] + "..."
+			}
+
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+				agent.Namespace, agent.Name, agent.Status, profile, valueOrDash(agent.Template))
+		} else {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n",
+				agent.Namespace, agent.Name, agent.Status)
+		}
+	}
+
+	_ = w.Flush()
+}
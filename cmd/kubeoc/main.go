@@ -6,6 +6,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -32,6 +33,16 @@ var (
 	contextFlag    string
 )
 
+// Persistent flags for talking to the KubeOpenCode API server instead of the Kubernetes API
+var (
+	serverFlag string
+	tokenFlag  string
+)
+
+// kubectlPluginName is the binary name under which kubectl runs kubeoc as
+// "kubectl kubeopencode".
+const kubectlPluginName = "kubectl-kubeopencode"
+
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(kubeopenv1alpha1.AddToScheme(scheme))
@@ -44,11 +55,16 @@ var rootCmd = &cobra.Command{
 
 Commands:
   get agents|tasks|crontasks|agenttemplates   List resources
-  agent attach|suspend|resume|share|unshare   Interact with agents
-  task stop|logs                               Manage tasks
+  agent list|attach|suspend|resume|share|unshare
+                                              Interact with agents
+  task create|run|get|list|stop|logs|attach   Manage tasks
+  template list                               List agent templates
   crontask trigger|suspend|resume             Manage CronTasks
   completion bash|zsh|fish|powershell         Generate shell completion
-  version                                      Print version information
+  version                                     Print version information
+
+Installed as kubectl-kubeopencode on the PATH, kubeoc also runs as the
+kubectl plugin "kubectl kubeopencode".
 
 Kubeconfig resolution (in priority order):
   1. --kubeconfig flag
@@ -57,11 +73,19 @@ Kubeconfig resolution (in priority order):
   4. KUBECONFIG environment variable
   5. Default ~/.kube/config
 
+With --server (or KUBEOPENCODE_SERVER), the task, agent list and template list
+commands use the KubeOpenCode API server instead of the Kubernetes API. The
+bearer token is taken from --token, KUBEOPENCODE_TOKEN or the kubeconfig.
+
 Examples:
   kubeoc get agents
   kubeoc get tasks -n production -o json
+  kubeoc task create --agent my-agent -n test -f task.md
+  kubeoc task run --agent my-agent -n test -f task.md --follow --wait
   kubeoc agent attach my-agent -n test
   kubeoc task logs my-task -n test -f
+  kubeoc task attach my-task -n test
+  kubeoc task list --server https://kubeopencode.example.com
   kubeoc crontask trigger daily-scan -n production`,
 	SilenceUsage: true,
 }
@@ -69,6 +93,8 @@ Examples:
 func init() {
 	rootCmd.PersistentFlags().StringVar(&kubeconfigFlag, "kubeconfig", "", "Path to kubeconfig file")
 	rootCmd.PersistentFlags().StringVar(&contextFlag, "context", "", "Kubernetes context to use")
+	rootCmd.PersistentFlags().StringVar(&serverFlag, "server", "", "KubeOpenCode API server URL (default: use the Kubernetes API)")
+	rootCmd.PersistentFlags().StringVar(&tokenFlag, "token", "", "Bearer token for the KubeOpenCode API server")
 
 	rootCmd.AddCommand(newVersionCmd())
 	rootCmd.AddCommand(newCompletionCmd())
@@ -170,6 +196,9 @@ func getKubeConfig() (*rest.Config, error) {
 }
 
 func main() {
+	if filepath.Base(os.Args[0]) == kubectlPluginName {
+		rootCmd.Annotations = map[string]string{cobra.CommandDisplayNameAnnotation: "kubectl kubeopencode"}
+	}
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
@@ -0,0 +1,317 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+	"github.com/kubeopencode/kubeopencode/internal/server/types"
+)
+
+// serverListPageSize is the page size used to list all resources from the API server.
+const serverListPageSize = 100
+
+// serverClient talks to the KubeOpenCode API server instead of the Kubernetes API.
+type serverClient struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// newServerClient returns a client for the API server selected by --server or
+// KUBEOPENCODE_SERVER, or nil if commands should use the Kubernetes API directly.
+// The bearer token is taken from --token, KUBEOPENCODE_TOKEN or the kubeconfig.
+func newServerClient() (*serverClient, error) {
+	server := serverFlag
+	if server == "" {
+		server = os.Getenv("KUBEOPENCODE_SERVER")
+	}
+	if server == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(server)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid server URL %q: must be http(s)://host[:port]", server)
+	}
+
+	token := tokenFlag
+	if token == "" {
+		token = os.Getenv("KUBEOPENCODE_TOKEN")
+	}
+	if token == "" {
+		if cfg, err := getKubeConfig(); err == nil {
+			token = cfg.BearerToken
+		}
+	}
+
+	return &serverClient{
+		baseURL:    strings.TrimRight(server, "/"),
+		token:      token,
+		httpClient: &http.Client{},
+	}, nil
+}
+
+// namespacedPath returns the API path of a resource collection in namespace,
+// or across all namespaces if namespace is empty.
+func namespacedPath(namespace, resource string) string {
+	if namespace == "" {
+		return "/api/v1/" + resource
+	}
+	return "/api/v1/namespaces/" + url.PathEscape(namespace) + "/" + resource
+}
+
+// newRequest builds a request to the API server.
+func (c *serverClient) newRequest(ctx context.Context, method, path string, query url.Values, body any) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	return req, nil
+}
+
+// serverError is an error response of the API server.
+type serverError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *serverError) Error() string {
+	return e.Message
+}
+
+// isServerNotFound reports whether err is a 404 response of the API server.
+func isServerNotFound(err error) bool {
+	var apiErr *serverError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// send sends a request and returns the response, or a *serverError for a non-2xx status.
+func (c *serverClient) send(req *http.Request) (*http.Response, error) {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request to KubeOpenCode server failed: %w", err)
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer func() { _ = resp.Body.Close() }()
+	return nil, responseError(resp)
+}
+
+// responseError returns the *serverError for a non-2xx response.
+func responseError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	message := fmt.Sprintf("server returned %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	var apiErr types.ErrorResponse
+	if json.Unmarshal(data, &apiErr) == nil && apiErr.Error != "" {
+		message = apiErr.Error
+		if apiErr.Message != "" {
+			message += ": " + apiErr.Message
+		}
+	}
+	return &serverError{StatusCode: resp.StatusCode, Message: message}
+}
+
+// do sends a JSON request and decodes the JSON response into out, if not nil.
+func (c *serverClient) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	req, err := c.newRequest(ctx, method, path, query, body)
+	if err != nil {
+		return err
+	}
+	resp, err := c.send(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode server response: %w", err)
+	}
+	return nil
+}
+
+// getObject gets an Agent or AgentTemplate resource from the API server.
+func (c *serverClient) getObject(ctx context.Context, namespace, name string, obj client.Object) error {
+	var resource string
+	switch obj.(type) {
+	case *kubeopenv1alpha1.Agent:
+		resource = "agents"
+	case *kubeopenv1alpha1.AgentTemplate:
+		resource = "agenttemplates"
+	default:
+		return fmt.Errorf("unsupported resource type %T", obj)
+	}
+
+	path := namespacedPath(namespace, resource) + "/" + url.PathEscape(name)
+	req, err := c.newRequest(ctx, http.MethodGet, path, url.Values{"output": {"yaml"}}, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.send(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read server response: %w", err)
+	}
+	if err := yaml.Unmarshal(data, obj); err != nil {
+		return fmt.Errorf("failed to decode server response: %w", err)
+	}
+	return nil
+}
+
+// listAll calls a list endpoint page by page until all items are returned.
+// page decodes one response and returns its pagination.
+func (c *serverClient) listAll(ctx context.Context, path string, page func(data []byte) (*types.Pagination, error)) error {
+	query := url.Values{"limit": {fmt.Sprint(serverListPageSize)}}
+	for {
+		var raw json.RawMessage
+		if err := c.do(ctx, http.MethodGet, path, query, nil, &raw); err != nil {
+			return err
+		}
+		pagination, err := page(raw)
+		if err != nil {
+			return fmt.Errorf("failed to decode server response: %w", err)
+		}
+		if pagination == nil || !pagination.HasMore || pagination.Continue == "" {
+			return nil
+		}
+		query.Set("continue", pagination.Continue)
+	}
+}
+
+// listTasks returns the tasks in namespace, or in all namespaces if it is empty.
+func (c *serverClient) listTasks(ctx context.Context, namespace string) ([]types.TaskResponse, error) {
+	var tasks []types.TaskResponse
+	err := c.listAll(ctx, namespacedPath(namespace, "tasks"), func(data []byte) (*types.Pagination, error) {
+		var list types.TaskListResponse
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, list.Tasks...)
+		return list.Pagination, nil
+	})
+	return tasks, err
+}
+
+// listAgents returns the agents in namespace, or in all namespaces if it is empty.
+func (c *serverClient) listAgents(ctx context.Context, namespace string) ([]types.AgentResponse, error) {
+	var agents []types.AgentResponse
+	err := c.listAll(ctx, namespacedPath(namespace, "agents"), func(data []byte) (*types.Pagination, error) {
+		var list types.AgentListResponse
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, err
+		}
+		agents = append(agents, list.Agents...)
+		return list.Pagination, nil
+	})
+	return agents, err
+}
+
+// listAgentTemplates returns the agent templates in namespace, or in all namespaces if it is empty.
+func (c *serverClient) listAgentTemplates(ctx context.Context, namespace string) ([]types.AgentTemplateResponse, error) {
+	var templates []types.AgentTemplateResponse
+	err := c.listAll(ctx, namespacedPath(namespace, "agenttemplates"), func(data []byte) (*types.Pagination, error) {
+		var list types.AgentTemplateListResponse
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, err
+		}
+		templates = append(templates, list.Templates...)
+		return list.Pagination, nil
+	})
+	return templates, err
+}
+
+// streamTaskLogs copies the agent logs of a task from the server's SSE log stream to out.
+// It returns the task phase reported when the stream completes, if any.
+func (c *serverClient) streamTaskLogs(ctx context.Context, namespace, name string, follow bool, out io.Writer) (string, error) {
+	path := namespacedPath(namespace, "tasks") + "/" + url.PathEscape(name) + "/logs"
+	req, err := c.newRequest(ctx, http.MethodGet, path, url.Values{"follow": {fmt.Sprint(follow)}}, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := c.send(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var phase string
+	err = readLogEvents(resp.Body, func(event types.LogEvent) error {
+		switch event.Type {
+		case "log":
+			if event.Content != nil {
+				_, err := io.WriteString(out, *event.Content)
+				return err
+			}
+		case "error":
+			return fmt.Errorf("log stream failed: %s", event.Message)
+		case "complete":
+			if event.Phase != nil {
+				phase = *event.Phase
+			}
+		}
+		return nil
+	})
+	return phase, err
+}
+
+// readLogEvents calls fn for each LogEvent in an SSE stream, skipping comments
+// such as heartbeats, until the stream ends or fn returns an error.
+func readLogEvents(r io.Reader, fn func(types.LogEvent) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		var event types.LogEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return fmt.Errorf("invalid log event: %w", err)
+		}
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,201 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+	"github.com/kubeopencode/kubeopencode/internal/server/types"
+)
+
+func TestServerClientListTasks(t *testing.T) {
+	var gotAuth []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = append(gotAuth, r.Header.Get("Authorization"))
+		if r.URL.Path != "/api/v1/namespaces/test/tasks" {
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(types.ErrorResponse{Error: "Not found", Message: r.URL.Path})
+			return
+		}
+		// Two pages: the first one points to the second with a continue cursor
+		resp := types.TaskListResponse{Tasks: []types.TaskResponse{{Name: "second", Namespace: "test"}}}
+		if r.URL.Query().Get("continue") == "" {
+			resp = types.TaskListResponse{
+				Tasks:      []types.TaskResponse{{Name: "first", Namespace: "test"}},
+				Pagination: &types.Pagination{HasMore: true, Continue: "page-2"},
+			}
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	c := &serverClient{baseURL: srv.URL, token: "secret", httpClient: srv.Client()}
+	tasks, err := c.listTasks(context.Background(), "test")
+	if err != nil {
+		t.Fatalf("listTasks() error: %v", err)
+	}
+	if len(tasks) != 2 || tasks[0].Name != "first" || tasks[1].Name != "second" {
+		t.Errorf("listTasks() = %+v, want both pages", tasks)
+	}
+	if len(gotAuth) != 2 || gotAuth[0] != "Bearer secret" {
+		t.Errorf("Authorization headers = %v, want the bearer token on every page", gotAuth)
+	}
+
+	_, err = c.listAgents(context.Background(), "test")
+	if err == nil || !strings.Contains(err.Error(), "Not found: /api/v1/namespaces/test/agents") {
+		t.Errorf("listAgents() error = %v, want the server's error response", err)
+	}
+}
+
+func TestServerClientStreamTaskLogs(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("follow") != "true" {
+			t.Errorf("follow = %q, want true", r.URL.Query().Get("follow"))
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(`data: {"type":"status","phase":"Running","podPhase":"Running"}
+
+id: 1
+data: {"type":"log","content":"hello\n"}
+
+: heartbeat
+
+id: 2
+data: {"type":"log","content":"world\n"}
+
+data: {"type":"complete","phase":"Completed"}
+
+`))
+	}))
+	defer srv.Close()
+
+	c := &serverClient{baseURL: srv.URL, httpClient: srv.Client()}
+	var out bytes.Buffer
+	phase, err := c.streamTaskLogs(context.Background(), "test", "my-task", true, &out)
+	if err != nil {
+		t.Fatalf("streamTaskLogs() error: %v", err)
+	}
+	if out.String() != "hello\nworld\n" || phase != "Completed" {
+		t.Errorf("streamTaskLogs() = %q, %q; want the log lines and phase Completed", out.String(), phase)
+	}
+}
+
+func TestReadLogEventsError(t *testing.T) {
+	err := readLogEvents(strings.NewReader("data: {\"type\":\"error\",\"message\":\"Pod not found\"}\n\n"), func(event types.LogEvent) error {
+		if event.Type == "error" {
+			return context.Canceled
+		}
+		return nil
+	})
+	if err != context.Canceled {
+		t.Errorf("readLogEvents() error = %v, want the callback's error", err)
+	}
+}
+
+func TestNewServerClient(t *testing.T) {
+	t.Setenv("KUBEOPENCODE_SERVER", "")
+	t.Setenv("KUBEOPENCODE_TOKEN", "env-token")
+	serverFlag, tokenFlag = "", ""
+	defer func() { serverFlag, tokenFlag = "", "" }()
+
+	if c, err := newServerClient(); c != nil || err != nil {
+		t.Errorf("newServerClient() = %v, %v; want nil without a server", c, err)
+	}
+
+	serverFlag = "https://kubeopencode.example.com/"
+	c, err := newServerClient()
+	if err != nil {
+		t.Fatalf("newServerClient() error: %v", err)
+	}
+	if c.baseURL != "https://kubeopencode.example.com" || c.token != "env-token" {
+		t.Errorf("newServerClient() = %+v", c)
+	}
+
+	serverFlag = "kubeopencode.example.com"
+	if _, err := newServerClient(); err == nil {
+		t.Error("expected an error for a server URL without scheme")
+	}
+}
+
+func TestReadDescription(t *testing.T) {
+	if got, err := readDescription("Fix the tests", "", nil); err != nil || got != "Fix the tests" {
+		t.Errorf("readDescription(inline) = %q, %v", got, err)
+	}
+	if got, err := readDescription("", "-", strings.NewReader("from stdin\n")); err != nil || got != "from stdin\n" {
+		t.Errorf("readDescription(stdin) = %q, %v", got, err)
+	}
+	if _, err := readDescription("inline", "-", strings.NewReader("stdin")); err == nil {
+		t.Error("expected an error for both --description and --file")
+	}
+	if _, err := readDescription(" \n", "", nil); err == nil {
+		t.Error("expected an error for an empty description")
+	}
+}
+
+func TestTaskCreateOptions(t *testing.T) {
+	opts := &taskCreateOptions{namespace: "test", template: "sre-agent", timeout: 30 * time.Minute}
+
+	task := opts.buildTask("Investigate")
+	if task.GenerateName != "task-" || task.Namespace != "test" || task.Spec.AgentRef != nil {
+		t.Errorf("unexpected task metadata: %+v", task.ObjectMeta)
+	}
+	if task.Spec.TemplateRef == nil || task.Spec.TemplateRef.Name != "sre-agent" {
+		t.Errorf("templateRef = %+v, want sre-agent", task.Spec.TemplateRef)
+	}
+	if *task.Spec.Description != "Investigate" || task.Spec.Timeout.Duration != 30*time.Minute {
+		t.Errorf("unexpected task spec: %+v", task.Spec)
+	}
+
+	opts = &taskCreateOptions{namespace: "test", name: "fix", agent: "my-agent"}
+	req := opts.buildRequest("Fix")
+	if req.Name != "fix" || req.AgentRef == nil || req.AgentRef.Name != "my-agent" || req.TemplateRef != nil || req.Timeout != "" {
+		t.Errorf("buildRequest() = %+v", req)
+	}
+}
+
+func TestSummarizeTask(t *testing.T) {
+	start := metav1.NewTime(time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC))
+	description := "Review the PR"
+	task := &kubeopenv1alpha1.Task{
+		ObjectMeta: metav1.ObjectMeta{Name: "review", Namespace: "test"},
+		Spec: kubeopenv1alpha1.TaskSpec{
+			Description: &description,
+			AgentRef:    &kubeopenv1alpha1.AgentReference{Name: "reviewer"},
+		},
+		Status: kubeopenv1alpha1.TaskExecutionStatus{
+			Phase:     kubeopenv1alpha1.TaskPhaseCompleted,
+			StartTime: &start,
+			Result: &kubeopenv1alpha1.TaskResult{
+				Status: kubeopenv1alpha1.TaskResultFailure,
+				Fields: &runtime.RawExtension{Raw: []byte(`{"findings":2}`)},
+			},
+		},
+	}
+
+	summary := summarizeTask(task)
+	if summary.Agent != "reviewer" || summary.Phase != "Completed" || summary.Description != description {
+		t.Errorf("summarizeTask() = %+v", summary)
+	}
+	if summary.Result == nil || summary.Result.Status != "Failure" || summary.Result.Fields["findings"] != float64(2) {
+		t.Errorf("result = %+v, want Failure with fields", summary.Result)
+	}
+
+	var out bytes.Buffer
+	printTaskDetails(&out, summary)
+	for _, want := range []string{"review", "reviewer", "Completed", "Failure", "  Review the PR"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("task details missing %q:\n%s", want, out.String())
+		}
+	}
+}
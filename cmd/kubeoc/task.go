@@ -4,20 +4,27 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
+	"net/url"
 	"os"
+	"strings"
 	"text/tabwriter"
 	"time"
 
 	"github.com/spf13/cobra"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+	"github.com/kubeopencode/kubeopencode/internal/controller"
+	servertypes "github.com/kubeopencode/kubeopencode/internal/server/types"
 )
 
 func init() {
@@ -29,8 +36,538 @@ func newTaskCmd() *cobra.Command {
 		Use:   "task",
 		Short: "Manage KubeOpenCode tasks",
 	}
+	cmd.AddCommand(newTaskCreateCmd())
+	cmd.AddCommand(newTaskRunCmd())
+	cmd.AddCommand(newTaskGetCmd())
+	cmd.AddCommand(listCommand(newGetTasksCmd(), "task"))
 	cmd.AddCommand(newTaskStopCmd())
 	cmd.AddCommand(newTaskLogsCmd())
+	cmd.AddCommand(newTaskAttachCmd())
+	return cmd
+}
+
+// listCommand turns a "get <resources>" command into the "<parent> list" command.
+func listCommand(get *cobra.Command, parent string) *cobra.Command {
+	get.Long = strings.ReplaceAll(get.Long, "kubeoc get "+get.Name(), "kubeoc "+parent+" list")
+	get.Use = "list"
+	get.Aliases = []string{"ls"}
+	return get
+}
+
+// taskSummary holds the fields of a task shown by the CLI, read either from the
+// Task resource or from the API server.
+type taskSummary struct {
+	Namespace      string
+	Name           string
+	Phase          string
+	Agent          string
+	Template       string
+	Pod            string
+	Description    string
+	Created        time.Time
+	StartTime      *time.Time
+	CompletionTime *time.Time
+	Conditions     []servertypes.Condition
+	Result         *servertypes.TaskResultResponse
+}
+
+// summarizeTask returns the summary of a Task resource.
+func summarizeTask(task *kubeopenv1alpha1.Task) taskSummary {
+	summary := taskSummary{
+		Namespace: task.Namespace,
+		Name:      task.Name,
+		Phase:     string(task.Status.Phase),
+		Pod:       task.Status.PodName,
+		Created:   task.CreationTimestamp.Time,
+	}
+	if task.Spec.AgentRef != nil {
+		summary.Agent = task.Spec.AgentRef.Name
+	}
+	if task.Spec.TemplateRef != nil {
+		summary.Template = task.Spec.TemplateRef.Name
+	}
+	if task.Spec.Description != nil {
+		summary.Description = *task.Spec.Description
+	}
+	if task.Status.StartTime != nil {
+		summary.StartTime = &task.Status.StartTime.Time
+	}
+	if task.Status.CompletionTime != nil {
+		summary.CompletionTime = &task.Status.CompletionTime.Time
+	}
+	for _, cond := range task.Status.Conditions {
+		summary.Conditions = append(summary.Conditions, servertypes.Condition{
+			Type:    cond.Type,
+			Status:  string(cond.Status),
+			Reason:  cond.Reason,
+			Message: cond.Message,
+		})
+	}
+	if result := task.Status.Result; result != nil {
+		summary.Result = &servertypes.TaskResultResponse{Status: string(result.Status), Summary: result.Summary}
+		if result.Fields != nil {
+			_ = json.Unmarshal(result.Fields.Raw, &summary.Result.Fields)
+		}
+	}
+	return summary
+}
+
+// summarizeTaskResponse returns the summary of a task returned by the API server.
+func summarizeTaskResponse(task *servertypes.TaskResponse) taskSummary {
+	summary := taskSummary{
+		Namespace:      task.Namespace,
+		Name:           task.Name,
+		Phase:          task.Phase,
+		Pod:            task.PodName,
+		Description:    task.Description,
+		Created:        task.CreatedAt,
+		StartTime:      task.StartTime,
+		CompletionTime: task.CompletionTime,
+		Conditions:     task.Conditions,
+		Result:         task.Result,
+	}
+	if task.AgentRef != nil {
+		summary.Agent = task.AgentRef.Name
+	}
+	if task.TemplateRef != nil {
+		summary.Template = task.TemplateRef.Name
+	}
+	return summary
+}
+
+// printTaskTable prints tasks as a table.
+func printTaskTable(out io.Writer, tasks []taskSummary, wide bool) {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	if wide {
+		_, _ = fmt.Fprintln(w, "NAMESPACE\tNAME\tAGENT\tPHASE\tAGE\tPOD")
+	} else {
+		_, _ = fmt.Fprintln(w, "NAMESPACE\tNAME\tAGENT\tPHASE\tAGE")
+	}
+
+	for _, task := range tasks {
+		agent := valueOrDash(task.Agent)
+		phase := task.Phase
+		if phase == "" {
+			phase = "Pending"
+		}
+		age := formatAge(task.Created)
+
+		if wide {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+				task.Namespace, task.Name, agent, phase, age, valueOrDash(task.Pod))
+		} else {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+				task.Namespace, task.Name, agent, phase, age)
+		}
+	}
+
+	_ = w.Flush()
+}
+
+// printTaskDetails prints a single task, including its conditions and result.
+func printTaskDetails(out io.Writer, task taskSummary) {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	phase := task.Phase
+	if phase == "" {
+		phase = "Pending"
+	}
+	_, _ = fmt.Fprintf(w, "Name:\t%s\n", task.Name)
+	_, _ = fmt.Fprintf(w, "Namespace:\t%s\n", task.Namespace)
+	_, _ = fmt.Fprintf(w, "Phase:\t%s\n", phase)
+	if task.Agent != "" {
+		_, _ = fmt.Fprintf(w, "Agent:\t%s\n", task.Agent)
+	}
+	if task.Template != "" {
+		_, _ = fmt.Fprintf(w, "Template:\t%s\n", task.Template)
+	}
+	_, _ = fmt.Fprintf(w, "Pod:\t%s\n", valueOrDash(task.Pod))
+	_, _ = fmt.Fprintf(w, "Created:\t%s (%s ago)\n", task.Created.Format(time.RFC3339), formatAge(task.Created))
+	if task.StartTime != nil {
+		_, _ = fmt.Fprintf(w, "Started:\t%s\n", task.StartTime.Format(time.RFC3339))
+	}
+	if task.CompletionTime != nil {
+		_, _ = fmt.Fprintf(w, "Completed:\t%s\n", task.CompletionTime.Format(time.RFC3339))
+		if task.StartTime != nil {
+			_, _ = fmt.Fprintf(w, "Duration:\t%s\n", task.CompletionTime.Sub(*task.StartTime).Round(time.Second))
+		}
+	}
+	if task.Result != nil {
+		_, _ = fmt.Fprintf(w, "Result:\t%s\n", task.Result.Status)
+		if task.Result.Summary != "" {
+			_, _ = fmt.Fprintf(w, "Summary:\t%s\n", task.Result.Summary)
+		}
+	}
+	_ = w.Flush()
+
+	if len(task.Conditions) > 0 {
+		_, _ = fmt.Fprintln(out, "Conditions:")
+		w = tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "  TYPE\tSTATUS\tREASON\tMESSAGE")
+		for _, cond := range task.Conditions {
+			_, _ = fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", cond.Type, cond.Status, valueOrDash(cond.Reason), cond.Message)
+		}
+		_ = w.Flush()
+	}
+
+	if task.Description != "" {
+		_, _ = fmt.Fprintf(out, "Description:\n%s\n", indent(strings.TrimRight(task.Description, "\n"), "  "))
+	}
+}
+
+// valueOrDash returns value, or "-" if it is empty.
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// indent prefixes each line of text with prefix.
+func indent(text, prefix string) string {
+	return prefix + strings.ReplaceAll(text, "\n", "\n"+prefix)
+}
+
+// readDescription returns the task description from --description or from the
+// file given by --file ("-" reads standard input).
+func readDescription(description, file string, stdin io.Reader) (string, error) {
+	if file != "" {
+		if description != "" {
+			return "", fmt.Errorf("--description and --file are mutually exclusive")
+		}
+		var data []byte
+		var err error
+		if file == "-" {
+			data, err = io.ReadAll(stdin)
+		} else {
+			data, err = os.ReadFile(file) //nolint:gosec // User-provided CLI flag, not untrusted input
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read description: %w", err)
+		}
+		description = string(data)
+	}
+	if strings.TrimSpace(description) == "" {
+		return "", fmt.Errorf("a task description is required (--description or --file)")
+	}
+	return description, nil
+}
+
+// taskCreateOptions holds the flags of "task create".
+type taskCreateOptions struct {
+	namespace   string
+	name        string
+	agent       string
+	template    string
+	description string
+	file        string
+	timeout     time.Duration
+	params      []string
+	output      string
+	dryRun      bool
+
+	// paramValues holds the parsed --param flags.
+	paramValues map[string]string
+}
+
+// addFlags registers the flags that define the task to create.
+func (o *taskCreateOptions) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.namespace, "namespace", "n", "default", "Task namespace")
+	cmd.Flags().StringVar(&o.name, "name", "", "Task name (default: generated)")
+	cmd.Flags().StringVar(&o.agent, "agent", "", "Agent to run the task on")
+	cmd.Flags().StringVar(&o.template, "template", "", "AgentTemplate to run the task from")
+	cmd.Flags().StringVarP(&o.description, "description", "d", "", "Task description")
+	cmd.Flags().StringVarP(&o.file, "file", "f", "", `File with the task description ("-" for standard input)`)
+	cmd.Flags().DurationVar(&o.timeout, "timeout", 0, "Maximum task duration, e.g. 30m (default: no timeout)")
+	cmd.Flags().StringArrayVarP(&o.params, "param", "p", nil, "Task param as key=value, referenced as ${params.key} (repeatable)")
+}
+
+// validate checks the flags that define the task, parses its params and returns
+// its description. Like the controller, it rejects a description that references
+// an undefined param.
+func (o *taskCreateOptions) validate(stdin io.Reader) (string, error) {
+	if (o.agent == "") == (o.template == "") {
+		return "", fmt.Errorf("exactly one of --agent or --template is required")
+	}
+	params, err := parseParams(o.params)
+	if err != nil {
+		return "", err
+	}
+	o.paramValues = params
+
+	description, err := readDescription(o.description, o.file, stdin)
+	if err != nil {
+		return "", err
+	}
+	if _, _, err := controller.RenderTaskText(o.buildTask(description), nil); err != nil {
+		return "", err
+	}
+	return description, nil
+}
+
+// parseParams parses key=value params. A later value for the same key wins.
+func parseParams(params []string) (map[string]string, error) {
+	if len(params) == 0 {
+		return nil, nil
+	}
+	values := make(map[string]string, len(params))
+	for _, param := range params {
+		key, value, ok := strings.Cut(param, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid param %q: must be key=value", param)
+		}
+		values[key] = value
+	}
+	return values, nil
+}
+
+// buildTask returns the Task to create from the flags of "task create".
+// Without a name, the API server generates one from the "task-" prefix.
+func (o *taskCreateOptions) buildTask(description string) *kubeopenv1alpha1.Task {
+	task := &kubeopenv1alpha1.Task{
+		ObjectMeta: metav1.ObjectMeta{Name: o.name, Namespace: o.namespace},
+		Spec:       kubeopenv1alpha1.TaskSpec{Description: &description},
+	}
+	if o.name == "" {
+		task.GenerateName = "task-"
+	}
+	if o.agent != "" {
+		task.Spec.AgentRef = &kubeopenv1alpha1.AgentReference{Name: o.agent}
+	}
+	if o.template != "" {
+		task.Spec.TemplateRef = &kubeopenv1alpha1.AgentTemplateReference{Name: o.template}
+	}
+	if o.timeout > 0 {
+		task.Spec.Timeout = &metav1.Duration{Duration: o.timeout}
+	}
+	task.Spec.Params = o.paramValues
+	return task
+}
+
+// buildRequest returns the API server request to create the task from the flags of "task create".
+func (o *taskCreateOptions) buildRequest(description string) servertypes.CreateTaskRequest {
+	req := servertypes.CreateTaskRequest{Name: o.name, Description: description, Params: o.paramValues}
+	if o.agent != "" {
+		req.AgentRef = &servertypes.AgentReference{Name: o.agent}
+	}
+	if o.template != "" {
+		req.TemplateRef = &servertypes.AgentTemplateReference{Name: o.template}
+	}
+	if o.timeout > 0 {
+		req.Timeout = o.timeout.String()
+	}
+	return req
+}
+
+// objectGetter gets a namespaced resource by name.
+type objectGetter func(ctx context.Context, namespace, name string, obj client.Object) error
+
+// taskPreview is the effective spec of a task before it is created.
+type taskPreview struct {
+	// Task is the task spec with the variables in its description and Text contexts rendered.
+	Task kubeopenv1alpha1.TaskSpec `json:"task"`
+	// Agent is the spec the task runs with: the Agent merged with its AgentTemplate,
+	// or the AgentTemplate of a templateRef task, with its Text contexts rendered.
+	Agent kubeopenv1alpha1.AgentSpec `json:"agent"`
+}
+
+// previewTask returns the effective spec of task, merged and rendered with the
+// same rules as the controller when the task starts.
+func previewTask(ctx context.Context, get objectGetter, task *kubeopenv1alpha1.Task) (*taskPreview, error) {
+	if task.Name == "" {
+		// The API server generates the name when the task is created
+		task = task.DeepCopy()
+		task.Name = task.GenerateName + "<generated>"
+	}
+
+	agent := &kubeopenv1alpha1.Agent{}
+	var templateName string
+	if ref := task.Spec.AgentRef; ref != nil {
+		if err := get(ctx, task.Namespace, ref.Name, agent); err != nil {
+			return nil, fmt.Errorf("failed to get agent %q: %w", ref.Name, err)
+		}
+		if agent.Spec.TemplateRef != nil {
+			templateName = agent.Spec.TemplateRef.Name
+		}
+	}
+	if ref := task.Spec.TemplateRef; ref != nil {
+		templateName = ref.Name
+	}
+	tmpl := &kubeopenv1alpha1.AgentTemplate{}
+	if templateName != "" {
+		if err := get(ctx, task.Namespace, templateName, tmpl); err != nil {
+			return nil, fmt.Errorf("failed to get agent template %q: %w", templateName, err)
+		}
+	}
+
+	taskSpec, agentSpec, err := controller.EffectiveTaskSpec(task, agent, tmpl)
+	if err != nil {
+		return nil, err
+	}
+	return &taskPreview{Task: taskSpec, Agent: agentSpec}, nil
+}
+
+// printTaskPreview prints the effective spec of task, as YAML unless output is json.
+func printTaskPreview(ctx context.Context, get objectGetter, task *kubeopenv1alpha1.Task, output string) error {
+	preview, err := previewTask(ctx, get, task)
+	if err != nil {
+		return err
+	}
+	if output == "" {
+		output = "yaml"
+	}
+	_, err = outputFormat(output, preview)
+	return err
+}
+
+func newTaskCreateCmd() *cobra.Command {
+	opts := &taskCreateOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a task",
+		Long: `Create a Task that runs on an Agent (--agent) or from an AgentTemplate (--template).
+
+The description is given inline with --description or read from a file with
+--file ("-" reads standard input). Without --name, a name is generated.
+
+Params (--param key=value) are set in spec.params and can be referenced from the
+description and Text contexts as ${params.key} or {{ .params.key }}. A description
+that references an undefined param is rejected.
+
+With --dry-run, the Task is not created. Instead, its effective spec is printed:
+the Agent merged with its AgentTemplate (or the AgentTemplate of a --template
+Task), and the description and Text contexts rendered with the params, using the
+same rules as the controller.
+
+Examples:
+  kubeoc task create --agent my-agent -n test -d "Fix the failing unit tests"
+  kubeoc task create --template sre-agent -n production -f incident.md --timeout 30m
+  git diff | kubeoc task create --agent reviewer -n test --name review-diff -f -
+  kubeoc task create --template pr-review -n ci -f review.md -p repo=org/x -p pr=123 --dry-run`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			description, err := opts.validate(cmd.InOrStdin())
+			if err != nil {
+				return err
+			}
+
+			sc, err := newServerClient()
+			if err != nil {
+				return err
+			}
+			if sc != nil {
+				if opts.dryRun {
+					return printTaskPreview(cmd.Context(), sc.getObject, opts.buildTask(description), opts.output)
+				}
+				var created servertypes.TaskResponse
+				if err := sc.do(cmd.Context(), http.MethodPost, namespacedPath(opts.namespace, "tasks"), nil, opts.buildRequest(description), &created); err != nil {
+					return fmt.Errorf("failed to create task: %w", err)
+				}
+				if handled, err := outputFormat(opts.output, created); handled {
+					return err
+				}
+				fmt.Printf("Task %s/%s created\n", created.Namespace, created.Name)
+				return nil
+			}
+
+			cfg, err := getKubeConfig()
+			if err != nil {
+				return fmt.Errorf("cannot connect to cluster: %w", err)
+			}
+
+			k8sClient, err := client.New(cfg, client.Options{Scheme: scheme})
+			if err != nil {
+				return fmt.Errorf("failed to create kubernetes client: %w", err)
+			}
+
+			task := opts.buildTask(description)
+			if opts.dryRun {
+				get := func(ctx context.Context, namespace, name string, obj client.Object) error {
+					return k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, obj)
+				}
+				return printTaskPreview(cmd.Context(), get, task, opts.output)
+			}
+			if err := k8sClient.Create(cmd.Context(), task); err != nil {
+				return fmt.Errorf("failed to create task: %w", err)
+			}
+			if handled, err := outputFormat(opts.output, task); handled {
+				return err
+			}
+			fmt.Printf("Task %s/%s created\n", task.Namespace, task.Name)
+			return nil
+		},
+	}
+
+	opts.addFlags(cmd)
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "", "Output format: json, yaml")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Print the effective task spec instead of creating the task")
+	return cmd
+}
+
+func newTaskGetCmd() *cobra.Command {
+	var (
+		namespace string
+		output    string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "get <task-name>",
+		Short: "Show a task",
+		Long: `Show the phase, Pod, timing, conditions, result and description of a Task.
+
+Use -o json or -o yaml to output in structured format.
+
+Examples:
+  kubeoc task get my-task -n test
+  kubeoc task get my-task -n test -o yaml`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			taskName := args[0]
+
+			sc, err := newServerClient()
+			if err != nil {
+				return err
+			}
+			if sc != nil {
+				var task servertypes.TaskResponse
+				path := namespacedPath(namespace, "tasks") + "/" + url.PathEscape(taskName)
+				if err := sc.do(cmd.Context(), http.MethodGet, path, nil, nil, &task); err != nil {
+					return fmt.Errorf("task %q not found in namespace %q: %w", taskName, namespace, err)
+				}
+				if handled, err := outputFormat(output, task); handled {
+					return err
+				}
+				printTaskDetails(os.Stdout, summarizeTaskResponse(&task))
+				return nil
+			}
+
+			cfg, err := getKubeConfig()
+			if err != nil {
+				return fmt.Errorf("cannot connect to cluster: %w", err)
+			}
+
+			k8sClient, err := client.New(cfg, client.Options{Scheme: scheme})
+			if err != nil {
+				return fmt.Errorf("failed to create kubernetes client: %w", err)
+			}
+
+			var task kubeopenv1alpha1.Task
+			if err := k8sClient.Get(cmd.Context(), types.NamespacedName{
+				Name:      taskName,
+				Namespace: namespace,
+			}, &task); err != nil {
+				return fmt.Errorf("task %q not found in namespace %q: %w", taskName, namespace, err)
+			}
+			if handled, err := outputFormat(output, task); handled {
+				return err
+			}
+			printTaskDetails(os.Stdout, summarizeTask(&task))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "Task namespace")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output format: json, yaml")
 	return cmd
 }
 
@@ -52,6 +589,19 @@ Examples:
 		RunE: func(cmd *cobra.Command, args []string) error {
 			taskName := args[0]
 
+			sc, err := newServerClient()
+			if err != nil {
+				return err
+			}
+			if sc != nil {
+				path := namespacedPath(namespace, "tasks") + "/" + url.PathEscape(taskName) + "/stop"
+				if err := sc.do(cmd.Context(), http.MethodPost, path, nil, nil, nil); err != nil {
+					return fmt.Errorf("failed to stop task %q: %w", taskName, err)
+				}
+				fmt.Printf("Task %s/%s stop requested\n", namespace, taskName)
+				return nil
+			}
+
 			cfg, err := getKubeConfig()
 			if err != nil {
 				return fmt.Errorf("cannot connect to cluster: %w", err)
@@ -113,6 +663,18 @@ Examples:
 		RunE: func(cmd *cobra.Command, args []string) error {
 			taskName := args[0]
 
+			sc, err := newServerClient()
+			if err != nil {
+				return err
+			}
+			if sc != nil {
+				_, err := sc.streamTaskLogs(cmd.Context(), namespace, taskName, follow, os.Stdout)
+				if err != nil && !errors.Is(err, context.Canceled) {
+					return fmt.Errorf("failed to stream logs for task %q: %w", taskName, err)
+				}
+				return nil
+			}
+
 			cfg, err := getKubeConfig()
 			if err != nil {
 				return fmt.Errorf("cannot connect to cluster: %w", err)
@@ -188,74 +750,63 @@ Examples:
   kubeoc get tasks --wide
   kubeoc get tasks -o json`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cfg, err := getKubeConfig()
-			if err != nil {
-				return fmt.Errorf("cannot connect to cluster: %w", err)
-			}
+			var summaries []taskSummary
 
-			k8sClient, err := client.New(cfg, client.Options{Scheme: scheme})
+			sc, err := newServerClient()
 			if err != nil {
-				return fmt.Errorf("failed to create kubernetes client: %w", err)
-			}
-
-			var tasks kubeopenv1alpha1.TaskList
-			listOpts := []client.ListOption{}
-			if namespace != "" {
-				listOpts = append(listOpts, client.InNamespace(namespace))
-			}
-
-			if err := k8sClient.List(cmd.Context(), &tasks, listOpts...); err != nil {
-				return fmt.Errorf("failed to list tasks: %w", err)
-			}
-
-			// Handle structured output formats
-			if handled, err := outputFormat(output, tasks); handled {
 				return err
 			}
-
-			if len(tasks.Items) == 0 {
-				if namespace != "" {
-					fmt.Printf("No tasks found in namespace %q\n", namespace)
-				} else {
-					fmt.Println("No tasks found")
+			if sc != nil {
+				tasks, err := sc.listTasks(cmd.Context(), namespace)
+				if err != nil {
+					return fmt.Errorf("failed to list tasks: %w", err)
+				}
+				if handled, err := outputFormat(output, servertypes.TaskListResponse{Tasks: tasks, Total: len(tasks)}); handled {
+					return err
+				}
+				for i := range tasks {
+					summaries = append(summaries, summarizeTaskResponse(&tasks[i]))
 				}
-				return nil
-			}
-
-			w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
-			if wide {
-				_, _ = fmt.Fprintln(w, "NAMESPACE\tNAME\tAGENT\tPHASE\tAGE\tPOD")
 			} else {
-				_, _ = fmt.Fprintln(w, "NAMESPACE\tNAME\tAGENT\tPHASE\tAGE")
-			}
+				cfg, err := getKubeConfig()
+				if err != nil {
+					return fmt.Errorf("cannot connect to cluster: %w", err)
+				}
 
-			for _, task := range tasks.Items {
-				agent := "-"
-				if task.Spec.AgentRef != nil {
-					agent = task.Spec.AgentRef.Name
+				k8sClient, err := client.New(cfg, client.Options{Scheme: scheme})
+				if err != nil {
+					return fmt.Errorf("failed to create kubernetes client: %w", err)
+				}
+
+				var tasks kubeopenv1alpha1.TaskList
+				listOpts := []client.ListOption{}
+				if namespace != "" {
+					listOpts = append(listOpts, client.InNamespace(namespace))
 				}
 
-				phase := string(task.Status.Phase)
-				if phase == "" {
-					phase = "Pending"
+				if err := k8sClient.List(cmd.Context(), &tasks, listOpts...); err != nil {
+					return fmt.Errorf("failed to list tasks: %w", err)
 				}
 
-				age := formatAge(task.CreationTimestamp.Time)
+				// Handle structured output formats
+				if handled, err := outputFormat(output, tasks); handled {
+					return err
+				}
+				for i := range tasks.Items {
+					summaries = append(summaries, summarizeTask(&tasks.Items[i]))
+				}
+			}
 
-				if wide {
-					pod := task.Status.PodName
-					if pod == "" {
-						pod = "-"
-					}
-					_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
-						task.Namespace, task.Name, agent, phase, age, pod)
+			if len(summaries) == 0 {
+				if namespace != "" {
+					fmt.Printf("No tasks found in namespace %q\n", namespace)
 				} else {
-					_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
-						task.Namespace, task.Name, agent, phase, age)
+					fmt.Println("No tasks found")
 				}
+				return nil
 			}
 
-			_ = w.Flush()
+			printTaskTable(os.Stdout, summaries, wide)
 			return nil
 		},
 	}
@@ -0,0 +1,322 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+)
+
+// taskAttachShell is the shell "task attach" starts, the same as the web terminal's.
+var taskAttachShell = []string{"/bin/sh", "-c", "command -v bash >/dev/null 2>&1 && exec bash || exec sh"}
+
+// terminalResizePollInterval is how often the local terminal size is checked for
+// changes. Polling works on all platforms, unlike SIGWINCH.
+const terminalResizePollInterval = 250 * time.Millisecond
+
+// localTerminal is the terminal "task attach" runs in.
+type localTerminal struct {
+	fd    int
+	isTTY bool
+}
+
+func newLocalTerminal() *localTerminal {
+	fd := int(os.Stdin.Fd()) //nolint:gosec // file descriptors fit in int
+	return &localTerminal{fd: fd, isTTY: term.IsTerminal(fd)}
+}
+
+// makeRaw puts the terminal in raw mode, so that keys such as Ctrl-C reach the
+// remote shell, and returns a function that restores it.
+func (t *localTerminal) makeRaw() (func(), error) {
+	if !t.isTTY {
+		return func() {}, nil
+	}
+	state, err := term.MakeRaw(t.fd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set terminal to raw mode: %w", err)
+	}
+	return func() { _ = term.Restore(t.fd, state) }, nil
+}
+
+// watchSize calls fn with the terminal size now and whenever it changes, until ctx is done.
+func (t *localTerminal) watchSize(ctx context.Context, fn func(width, height uint16)) {
+	if !t.isTTY {
+		return
+	}
+	ticker := time.NewTicker(terminalResizePollInterval)
+	defer ticker.Stop()
+
+	var lastWidth, lastHeight int
+	for {
+		if width, height, err := term.GetSize(t.fd); err == nil && (width != lastWidth || height != lastHeight) {
+			lastWidth, lastHeight = width, height
+			fn(uint16(width), uint16(height)) //nolint:gosec // terminal dimensions fit in uint16
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// terminalSizeQueue implements remotecommand.TerminalSizeQueue.
+type terminalSizeQueue struct {
+	ch chan *remotecommand.TerminalSize
+}
+
+func (q *terminalSizeQueue) Next() *remotecommand.TerminalSize {
+	size, ok := <-q.ch
+	if !ok {
+		return nil
+	}
+	return size
+}
+
+// attachTaskPod runs an interactive shell in a container of a Task Pod through the
+// Kubernetes exec API.
+func attachTaskPod(ctx context.Context, cfg *rest.Config, namespace, podName, container string, terminal *localTerminal, stdin io.Reader, stdout, stderr io.Writer) error {
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create clientset: %w", err)
+	}
+
+	req := clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(podName).
+		Namespace(namespace).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   taskAttachShell,
+			Stdin:     true,
+			Stdout:    true,
+			Stderr:    !terminal.isTTY,
+			TTY:       terminal.isTTY,
+		}, clientgoscheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(cfg, "POST", req.URL())
+	if err != nil {
+		return fmt.Errorf("failed to create executor: %w", err)
+	}
+
+	sessionCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	opts := remotecommand.StreamOptions{Stdin: stdin, Stdout: stdout, Tty: terminal.isTTY}
+	if terminal.isTTY {
+		sizes := &terminalSizeQueue{ch: make(chan *remotecommand.TerminalSize, 1)}
+		go func() {
+			defer close(sizes.ch)
+			terminal.watchSize(sessionCtx, func(width, height uint16) {
+				// Only the latest size matters
+				select {
+				case <-sizes.ch:
+				default:
+				}
+				sizes.ch <- &remotecommand.TerminalSize{Width: width, Height: height}
+			})
+		}()
+		opts.TerminalSizeQueue = sizes
+	} else {
+		opts.Stderr = stderr
+	}
+	return executor.StreamWithContext(sessionCtx, opts)
+}
+
+// terminalResizeMessage is the resize control message of the server's exec WebSocket.
+type terminalResizeMessage struct {
+	Type string `json:"type"`
+	Cols uint16 `json:"cols"`
+	Rows uint16 `json:"rows"`
+}
+
+// attachTask runs an interactive shell in a container of the task Pod through the
+// server's exec WebSocket. Binary messages carry the terminal input and output, and
+// text messages carry resize control messages.
+func (c *serverClient) attachTask(ctx context.Context, namespace, name, container string, terminal *localTerminal, stdin io.Reader, stdout io.Writer) error {
+	u, err := url.Parse(c.baseURL + namespacedPath(namespace, "tasks") + "/" + url.PathEscape(name) + "/exec")
+	if err != nil {
+		return err
+	}
+	u.Scheme = strings.Replace(u.Scheme, "http", "ws", 1)
+	u.RawQuery = url.Values{"container": {container}}.Encode()
+
+	header := http.Header{}
+	if c.token != "" {
+		header.Set("Authorization", "Bearer "+c.token)
+	}
+	dialer := websocket.Dialer{Proxy: http.ProxyFromEnvironment, HandshakeTimeout: 30 * time.Second}
+	ws, resp, err := dialer.DialContext(ctx, u.String(), header)
+	if err != nil {
+		if resp != nil {
+			defer func() { _ = resp.Body.Close() }()
+			return responseError(resp)
+		}
+		return fmt.Errorf("request to KubeOpenCode server failed: %w", err)
+	}
+	defer func() { _ = ws.Close() }()
+
+	// gorilla/websocket requires writes to be serialized
+	var writeMu sync.Mutex
+	write := func(messageType int, data []byte) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		return ws.WriteMessage(messageType, data)
+	}
+
+	sessionCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go terminal.watchSize(sessionCtx, func(width, height uint16) {
+		data, _ := json.Marshal(terminalResizeMessage{Type: "resize", Cols: width, Rows: height})
+		_ = write(websocket.TextMessage, data)
+	})
+	go func() {
+		buf := make([]byte, 32*1024)
+		for {
+			n, err := stdin.Read(buf)
+			if n > 0 {
+				if write(websocket.BinaryMessage, buf[:n]) != nil {
+					return
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		messageType, data, err := ws.ReadMessage()
+		if err != nil {
+			var closeErr *websocket.CloseError
+			switch {
+			case errors.As(err, &closeErr) && closeErr.Code == websocket.CloseNormalClosure:
+				return nil
+			case errors.As(err, &closeErr):
+				return fmt.Errorf("session closed: %s", closeErr.Text)
+			case ctx.Err() != nil:
+				return nil
+			}
+			return fmt.Errorf("session failed: %w", err)
+		}
+		if messageType == websocket.BinaryMessage {
+			if _, err := stdout.Write(data); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func newTaskAttachCmd() *cobra.Command {
+	var (
+		namespace string
+		container string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "attach <task-name>",
+		Short: "Open an interactive shell in a running task's pod",
+		Long: `Open an interactive shell in a container (default: agent) of the Pod of a
+running Task, e.g. to inspect its workspace or help the agent along.
+The shell is the same as the web terminal's: bash if available, otherwise sh.
+Exit the shell to detach; the Task keeps running.
+
+With --server, the session goes through the KubeOpenCode API server's
+WebSocket exec endpoint instead of the Kubernetes exec API. Either way, the
+pods/exec permission is required.
+
+Examples:
+  kubeoc task attach my-task -n test
+  kubeoc task attach my-task -n test --server https://kubeopencode.example.com`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			taskName := args[0]
+			terminal := newLocalTerminal()
+
+			sc, err := newServerClient()
+			if err != nil {
+				return err
+			}
+			if sc != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "Attaching to task %s/%s. Exit the shell to detach.\n", namespace, taskName)
+				restore, err := terminal.makeRaw()
+				if err != nil {
+					return err
+				}
+				defer restore()
+				if err := sc.attachTask(cmd.Context(), namespace, taskName, container, terminal, os.Stdin, os.Stdout); err != nil {
+					return fmt.Errorf("failed to attach to task %q: %w", taskName, err)
+				}
+				return nil
+			}
+
+			cfg, err := getKubeConfig()
+			if err != nil {
+				return fmt.Errorf("cannot connect to cluster: %w", err)
+			}
+
+			k8sClient, err := client.New(cfg, client.Options{Scheme: scheme})
+			if err != nil {
+				return fmt.Errorf("failed to create kubernetes client: %w", err)
+			}
+
+			var task kubeopenv1alpha1.Task
+			if err := k8sClient.Get(cmd.Context(), types.NamespacedName{
+				Name:      taskName,
+				Namespace: namespace,
+			}, &task); err != nil {
+				return fmt.Errorf("task %q not found in namespace %q: %w", taskName, namespace, err)
+			}
+			podName := task.Status.PodName
+			if podName == "" {
+				return fmt.Errorf("task %q has no associated pod (phase: %s)", taskName, task.Status.Phase)
+			}
+
+			var pod corev1.Pod
+			if err := k8sClient.Get(cmd.Context(), types.NamespacedName{Name: podName, Namespace: namespace}, &pod); err != nil {
+				return fmt.Errorf("failed to get pod %q: %w", podName, err)
+			}
+			if pod.Status.Phase != corev1.PodRunning {
+				return fmt.Errorf("pod %q of task %q is %s, not Running", podName, taskName, pod.Status.Phase)
+			}
+
+			_, _ = fmt.Fprintf(os.Stderr, "Attached to task %s/%s (pod %s). Exit the shell to detach.\n", namespace, taskName, podName)
+			restore, err := terminal.makeRaw()
+			if err != nil {
+				return err
+			}
+			defer restore()
+			if err := attachTaskPod(cmd.Context(), cfg, namespace, podName, container, terminal, os.Stdin, os.Stdout, os.Stderr); err != nil {
+				return fmt.Errorf("failed to attach to task %q: %w", taskName, err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "Task namespace")
+	cmd.Flags().StringVarP(&container, "container", "c", "agent", "Container to open the shell in")
+	return cmd
+}
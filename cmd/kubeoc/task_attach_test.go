@@ -0,0 +1,68 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/kubeopencode/kubeopencode/internal/server/types"
+)
+
+func TestServerClientAttachTask(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/namespaces/test/tasks/my-task/exec" {
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(types.ErrorResponse{Error: "Task not found"})
+			return
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("Authorization = %q", got)
+		}
+		if got := r.URL.Query().Get("container"); got != "agent" {
+			t.Errorf("container = %q, want agent", got)
+		}
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("upgrade failed: %v", err)
+			return
+		}
+		defer func() { _ = ws.Close() }()
+
+		// Echo one line of input, then end the session like an exited shell
+		_, data, err := ws.ReadMessage()
+		if err != nil {
+			t.Errorf("read failed: %v", err)
+			return
+		}
+		_ = ws.WriteMessage(websocket.BinaryMessage, append([]byte("$ "), data...))
+		_ = ws.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	}))
+	defer srv.Close()
+	c := &serverClient{baseURL: srv.URL, token: "secret", httpClient: srv.Client()}
+
+	stdin, stdinWriter := io.Pipe()
+	defer func() { _ = stdinWriter.Close() }()
+	go func() { _, _ = stdinWriter.Write([]byte("ls\n")) }()
+
+	var out bytes.Buffer
+	if err := c.attachTask(context.Background(), "test", "my-task", "agent", &localTerminal{}, stdin, &out); err != nil {
+		t.Fatalf("attachTask() error: %v", err)
+	}
+	if out.String() != "$ ls\n" {
+		t.Errorf("output = %q, want the echoed input", out.String())
+	}
+
+	err := c.attachTask(context.Background(), "test", "missing", "agent", &localTerminal{}, stdin, &out)
+	if !isServerNotFound(err) || err.Error() != "Task not found" {
+		t.Errorf("attachTask() error = %v, want the server's error response", err)
+	}
+}
@@ -0,0 +1,313 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+	servertypes "github.com/kubeopencode/kubeopencode/internal/server/types"
+)
+
+// taskRunPollInterval is how often "task run" checks the phase of the task it waits for.
+var taskRunPollInterval = 2 * time.Second
+
+// taskBackend is the API "task run" creates and watches a task through:
+// the Kubernetes API, or the KubeOpenCode API server with --server.
+type taskBackend interface {
+	create(ctx context.Context, opts *taskCreateOptions, description string) (taskSummary, error)
+	get(ctx context.Context, namespace, name string) (taskSummary, error)
+	// streamLogs copies the agent logs of the task to out until the agent exits.
+	// It returns false if the agent container has not started yet.
+	streamLogs(ctx context.Context, namespace, name string, out io.Writer) (bool, error)
+	// outputs returns the captured output files of the task, or nil if there are none.
+	outputs(ctx context.Context, namespace, name string) (map[string]string, error)
+}
+
+// kubeTaskBackend runs tasks through the Kubernetes API.
+type kubeTaskBackend struct {
+	client    client.Client
+	clientset kubernetes.Interface
+}
+
+func (b *kubeTaskBackend) create(ctx context.Context, opts *taskCreateOptions, description string) (taskSummary, error) {
+	task := opts.buildTask(description)
+	if err := b.client.Create(ctx, task); err != nil {
+		return taskSummary{}, err
+	}
+	return summarizeTask(task), nil
+}
+
+func (b *kubeTaskBackend) getTask(ctx context.Context, namespace, name string) (*kubeopenv1alpha1.Task, error) {
+	var task kubeopenv1alpha1.Task
+	if err := b.client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+func (b *kubeTaskBackend) get(ctx context.Context, namespace, name string) (taskSummary, error) {
+	task, err := b.getTask(ctx, namespace, name)
+	if err != nil {
+		return taskSummary{}, err
+	}
+	return summarizeTask(task), nil
+}
+
+func (b *kubeTaskBackend) streamLogs(ctx context.Context, namespace, name string, out io.Writer) (bool, error) {
+	task, err := b.getTask(ctx, namespace, name)
+	if err != nil {
+		return false, err
+	}
+	if task.Status.PodName == "" {
+		return false, nil
+	}
+
+	req := b.clientset.CoreV1().Pods(namespace).GetLogs(task.Status.PodName, &corev1.PodLogOptions{
+		Container: "agent",
+		Follow:    true,
+	})
+	stream, err := req.Stream(ctx)
+	if err != nil {
+		// The Pod may not exist yet, or still be running its init containers
+		if apierrors.IsNotFound(err) || strings.Contains(err.Error(), "PodInitializing") || strings.Contains(err.Error(), "is waiting to start") {
+			return false, nil
+		}
+		return false, err
+	}
+	defer func() { _ = stream.Close() }()
+
+	_, err = io.Copy(out, stream)
+	return true, err
+}
+
+func (b *kubeTaskBackend) outputs(ctx context.Context, namespace, name string) (map[string]string, error) {
+	task, err := b.getTask(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	if task.Status.Outputs == nil {
+		return nil, nil
+	}
+	var cm corev1.ConfigMap
+	if err := b.client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: task.Status.Outputs.ConfigMapName}, &cm); err != nil {
+		return nil, err
+	}
+	return cm.Data, nil
+}
+
+// serverTaskBackend runs tasks through the KubeOpenCode API server.
+type serverTaskBackend struct {
+	sc *serverClient
+}
+
+func (b *serverTaskBackend) create(ctx context.Context, opts *taskCreateOptions, description string) (taskSummary, error) {
+	var created servertypes.TaskResponse
+	if err := b.sc.do(ctx, http.MethodPost, namespacedPath(opts.namespace, "tasks"), nil, opts.buildRequest(description), &created); err != nil {
+		return taskSummary{}, err
+	}
+	return summarizeTaskResponse(&created), nil
+}
+
+func (b *serverTaskBackend) get(ctx context.Context, namespace, name string) (taskSummary, error) {
+	var task servertypes.TaskResponse
+	if err := b.sc.do(ctx, http.MethodGet, namespacedPath(namespace, "tasks")+"/"+url.PathEscape(name), nil, nil, &task); err != nil {
+		return taskSummary{}, err
+	}
+	return summarizeTaskResponse(&task), nil
+}
+
+func (b *serverTaskBackend) streamLogs(ctx context.Context, namespace, name string, out io.Writer) (bool, error) {
+	// The server ends the stream without a phase while the Pod is initializing
+	w := &writeTracker{w: out}
+	phase, err := b.sc.streamTaskLogs(ctx, namespace, name, true, w)
+	return phase != "" || w.written, err
+}
+
+func (b *serverTaskBackend) outputs(ctx context.Context, namespace, name string) (map[string]string, error) {
+	var outputs servertypes.TaskOutputsResponse
+	path := namespacedPath(namespace, "tasks") + "/" + url.PathEscape(name) + "/outputs"
+	if err := b.sc.do(ctx, http.MethodGet, path, nil, nil, &outputs); err != nil {
+		if isServerNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return outputs.Files, nil
+}
+
+// writeTracker records whether anything was written to w.
+type writeTracker struct {
+	w       io.Writer
+	written bool
+}
+
+func (t *writeTracker) Write(p []byte) (int, error) {
+	if len(p) > 0 {
+		t.written = true
+	}
+	return t.w.Write(p)
+}
+
+// taskRunOptions holds the flags of "task run".
+type taskRunOptions struct {
+	taskCreateOptions
+	follow bool
+	wait   bool
+}
+
+// taskFinished reports whether phase is a terminal task phase.
+func taskFinished(phase string) bool {
+	return phase == string(kubeopenv1alpha1.TaskPhaseCompleted) || phase == string(kubeopenv1alpha1.TaskPhaseFailed)
+}
+
+// runTask creates a task and, with --wait or --follow, waits for it to finish,
+// streaming its agent logs to out with --follow. It then prints the result and
+// captured outputs of the task to out, and returns an error if the task failed
+// or its agent reported a Failure result. Progress messages go to errOut.
+func runTask(ctx context.Context, backend taskBackend, opts *taskRunOptions, description string, out, errOut io.Writer) error {
+	task, err := backend.create(ctx, &opts.taskCreateOptions, description)
+	if err != nil {
+		return fmt.Errorf("failed to create task: %w", err)
+	}
+	_, _ = fmt.Fprintf(errOut, "Task %s/%s created\n", task.Namespace, task.Name)
+	if !opts.wait && !opts.follow {
+		return nil
+	}
+
+	streamed := !opts.follow
+	for {
+		if !streamed && task.Pod != "" {
+			if streamed, err = backend.streamLogs(ctx, task.Namespace, task.Name, out); err != nil {
+				return fmt.Errorf("failed to stream logs for task %q: %w", task.Name, err)
+			}
+		}
+		if taskFinished(task.Phase) {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(taskRunPollInterval):
+		}
+		current, err := backend.get(ctx, task.Namespace, task.Name)
+		if err != nil {
+			return fmt.Errorf("failed to get task %q: %w", task.Name, err)
+		}
+		task = current
+	}
+
+	_, _ = fmt.Fprintf(errOut, "Task %s/%s %s\n", task.Namespace, task.Name, task.Phase)
+	if task.Result != nil {
+		_, _ = fmt.Fprintf(out, "Result: %s\n", task.Result.Status)
+		if task.Result.Summary != "" {
+			_, _ = fmt.Fprintf(out, "Summary: %s\n", task.Result.Summary)
+		}
+	}
+	files, err := backend.outputs(ctx, task.Namespace, task.Name)
+	if err != nil {
+		return fmt.Errorf("failed to get outputs of task %q: %w", task.Name, err)
+	}
+	printTaskOutputs(out, files)
+
+	if task.Phase == string(kubeopenv1alpha1.TaskPhaseFailed) {
+		for _, cond := range task.Conditions {
+			if cond.Type == kubeopenv1alpha1.ConditionTypeReady && cond.Message != "" {
+				return fmt.Errorf("task %q failed: %s", task.Name, cond.Message)
+			}
+		}
+		return fmt.Errorf("task %q failed", task.Name)
+	}
+	if task.Result != nil && task.Result.Status == string(kubeopenv1alpha1.TaskResultFailure) {
+		return fmt.Errorf("task %q reported a %s result", task.Name, task.Result.Status)
+	}
+	return nil
+}
+
+// printTaskOutputs prints captured output files in name order, each after a header line.
+func printTaskOutputs(out io.Writer, files map[string]string) {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		content := files[name]
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		_, _ = fmt.Fprintf(out, "==> %s <==\n%s", name, content)
+	}
+}
+
+func newTaskRunCmd() *cobra.Command {
+	opts := &taskRunOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "run",
+		Short: "Create a task and wait for it to finish",
+		Long: `Create a Task like "task create" and, with --wait, wait until it completes or
+fails, then print its result and captured outputs (see spec.captureOutputs).
+With --follow, the agent logs are streamed while waiting.
+
+The command exits with a non-zero status if the Task fails or its agent
+reports a Failure result, so it can gate CI pipelines. Progress messages are
+written to standard error; logs, result and outputs to standard output.
+
+Examples:
+  kubeoc task run --agent my-agent -n test -f description.md --follow --wait
+  kubeoc task run --template pr-review -n ci -d "Review PR #123" --wait --timeout 30m`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			description, err := opts.validate(cmd.InOrStdin())
+			if err != nil {
+				return err
+			}
+
+			sc, err := newServerClient()
+			if err != nil {
+				return err
+			}
+			if sc != nil {
+				return runTask(cmd.Context(), &serverTaskBackend{sc: sc}, opts, description, os.Stdout, os.Stderr)
+			}
+
+			cfg, err := getKubeConfig()
+			if err != nil {
+				return fmt.Errorf("cannot connect to cluster: %w", err)
+			}
+
+			k8sClient, err := client.New(cfg, client.Options{Scheme: scheme})
+			if err != nil {
+				return fmt.Errorf("failed to create kubernetes client: %w", err)
+			}
+
+			clientset, err := kubernetes.NewForConfig(cfg)
+			if err != nil {
+				return fmt.Errorf("failed to create clientset: %w", err)
+			}
+
+			return runTask(cmd.Context(), &kubeTaskBackend{client: k8sClient, clientset: clientset}, opts, description, os.Stdout, os.Stderr)
+		},
+	}
+
+	opts.addFlags(cmd)
+	cmd.Flags().BoolVar(&opts.follow, "follow", false, "Stream the agent logs while waiting (implies --wait)")
+	cmd.Flags().BoolVar(&opts.wait, "wait", false, "Wait for the task to finish and exit non-zero if it fails")
+	return cmd
+}
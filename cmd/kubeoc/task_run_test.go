@@ -0,0 +1,195 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+	servertypes "github.com/kubeopencode/kubeopencode/internal/server/types"
+)
+
+// fakeTaskBackend returns the task states in order, one per get.
+type fakeTaskBackend struct {
+	states   []taskSummary
+	gets     int
+	logs     string
+	notReady int
+	streams  int
+	files    map[string]string
+}
+
+func (b *fakeTaskBackend) create(_ context.Context, opts *taskCreateOptions, _ string) (taskSummary, error) {
+	return taskSummary{Namespace: opts.namespace, Name: "task-abc", Phase: "Pending"}, nil
+}
+
+func (b *fakeTaskBackend) get(context.Context, string, string) (taskSummary, error) {
+	state := b.states[min(b.gets, len(b.states)-1)]
+	b.gets++
+	return state, nil
+}
+
+func (b *fakeTaskBackend) streamLogs(_ context.Context, _, _ string, out io.Writer) (bool, error) {
+	b.streams++
+	if b.streams <= b.notReady {
+		return false, nil
+	}
+	_, err := io.WriteString(out, b.logs)
+	return true, err
+}
+
+func (b *fakeTaskBackend) outputs(context.Context, string, string) (map[string]string, error) {
+	return b.files, nil
+}
+
+func TestRunTask(t *testing.T) {
+	defer func(interval time.Duration) { taskRunPollInterval = interval }(taskRunPollInterval)
+	taskRunPollInterval = 0
+	running := taskSummary{Namespace: "test", Name: "task-abc", Phase: "Running", Pod: "task-abc-pod"}
+
+	tests := []struct {
+		name        string
+		opts        taskRunOptions
+		backend     *fakeTaskBackend
+		wantErr     string
+		wantOut     string
+		wantStreams int
+	}{
+		{
+			name:    "create only",
+			backend: &fakeTaskBackend{},
+		},
+		{
+			name: "wait prints result and outputs",
+			opts: taskRunOptions{wait: true},
+			backend: &fakeTaskBackend{
+				states: []taskSummary{running, {Namespace: "test", Name: "task-abc", Phase: "Completed",
+					Result: &servertypes.TaskResultResponse{Status: "Success", Summary: "All good"}}},
+				logs:  "ignored\n",
+				files: map[string]string{"report.md": "# Report", "a.txt": "a\n"},
+			},
+			wantOut: "Result: Success\nSummary: All good\n==> a.txt <==\na\n==> report.md <==\n# Report\n",
+		},
+		{
+			name: "follow retries until the agent starts",
+			opts: taskRunOptions{follow: true},
+			backend: &fakeTaskBackend{
+				states:   []taskSummary{running, running, {Namespace: "test", Name: "task-abc", Phase: "Completed"}},
+				logs:     "working\n",
+				notReady: 1,
+			},
+			wantOut:     "working\n",
+			wantStreams: 2,
+		},
+		{
+			name: "failed task",
+			opts: taskRunOptions{wait: true},
+			backend: &fakeTaskBackend{
+				states: []taskSummary{{Namespace: "test", Name: "task-abc", Phase: "Failed", Conditions: []servertypes.Condition{
+					{Type: kubeopenv1alpha1.ConditionTypeReady, Status: "False", Reason: "AgentError", Message: "agent exited with code 1"},
+				}}},
+			},
+			wantErr: `task "task-abc" failed: agent exited with code 1`,
+		},
+		{
+			name: "failure result",
+			opts: taskRunOptions{wait: true},
+			backend: &fakeTaskBackend{
+				states: []taskSummary{{Namespace: "test", Name: "task-abc", Phase: "Completed",
+					Result: &servertypes.TaskResultResponse{Status: "Failure"}}},
+			},
+			wantErr: `task "task-abc" reported a Failure result`,
+			wantOut: "Result: Failure\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.namespace = "test"
+			var out, errOut bytes.Buffer
+			err := runTask(context.Background(), tt.backend, &tt.opts, "Do it", &out, &errOut)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("runTask() error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("runTask() error: %v", err)
+			}
+			if out.String() != tt.wantOut {
+				t.Errorf("output = %q, want %q", out.String(), tt.wantOut)
+			}
+			if tt.backend.streams != tt.wantStreams {
+				t.Errorf("log streams = %d, want %d", tt.backend.streams, tt.wantStreams)
+			}
+			if !strings.HasPrefix(errOut.String(), "Task test/task-abc created\n") {
+				t.Errorf("progress = %q", errOut.String())
+			}
+		})
+	}
+}
+
+func TestKubeTaskBackendOutputs(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = kubeopenv1alpha1.AddToScheme(s)
+	_ = corev1.AddToScheme(s)
+
+	withOutputs := &kubeopenv1alpha1.Task{
+		ObjectMeta: metav1.ObjectMeta{Name: "with-outputs", Namespace: "test"},
+		Status: kubeopenv1alpha1.TaskExecutionStatus{
+			Outputs: &kubeopenv1alpha1.TaskOutputsStatus{ConfigMapName: "with-outputs-outputs"},
+		},
+	}
+	without := &kubeopenv1alpha1.Task{ObjectMeta: metav1.ObjectMeta{Name: "without", Namespace: "test"}}
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "with-outputs-outputs", Namespace: "test"},
+		Data:       map[string]string{"report.md": "# Report"},
+	}
+	backend := &kubeTaskBackend{client: fake.NewClientBuilder().WithScheme(s).WithObjects(withOutputs, without, cm).Build()}
+
+	files, err := backend.outputs(context.Background(), "test", "with-outputs")
+	if err != nil || files["report.md"] != "# Report" {
+		t.Errorf("outputs() = %v, %v; want the ConfigMap data", files, err)
+	}
+	files, err = backend.outputs(context.Background(), "test", "without")
+	if err != nil || files != nil {
+		t.Errorf("outputs() = %v, %v; want none", files, err)
+	}
+}
+
+func TestServerTaskBackend(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/namespaces/test/tasks/my-task/logs":
+			// The agent container has not started yet
+			_, _ = w.Write([]byte("data: {\"type\":\"info\",\"message\":\"Pod is initializing\"}\n\n"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(servertypes.ErrorResponse{Error: "Outputs not found"})
+		}
+	}))
+	defer srv.Close()
+	backend := &serverTaskBackend{sc: &serverClient{baseURL: srv.URL, httpClient: srv.Client()}}
+
+	var out bytes.Buffer
+	started, err := backend.streamLogs(context.Background(), "test", "my-task", &out)
+	if err != nil || started {
+		t.Errorf("streamLogs() = %v, %v; want not started", started, err)
+	}
+	files, err := backend.outputs(context.Background(), "test", "my-task")
+	if err != nil || files != nil {
+		t.Errorf("outputs() = %v, %v; want none", files, err)
+	}
+}
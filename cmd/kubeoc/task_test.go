@@ -0,0 +1,154 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+)
+
+func TestParseParams(t *testing.T) {
+	params, err := parseParams([]string{"repo=org/x", "pr=123", "query=a=b", "pr=124", "empty="})
+	if err != nil {
+		t.Fatalf("parseParams() error: %v", err)
+	}
+	want := map[string]string{"repo": "org/x", "pr": "124", "query": "a=b", "empty": ""}
+	if len(params) != len(want) {
+		t.Fatalf("parseParams() = %v, want %v", params, want)
+	}
+	for k, v := range want {
+		if params[k] != v {
+			t.Errorf("params[%q] = %q, want %q", k, params[k], v)
+		}
+	}
+
+	for _, invalid := range []string{"repo", "=value"} {
+		if _, err := parseParams([]string{invalid}); err == nil {
+			t.Errorf("expected an error for param %q", invalid)
+		}
+	}
+}
+
+func TestTaskCreateOptionsValidateParams(t *testing.T) {
+	opts := &taskCreateOptions{
+		namespace:   "ci",
+		template:    "pr-review",
+		description: "Review ${params.repo}#${params.pr}",
+		params:      []string{"repo=org/x", "pr=123"},
+	}
+	description, err := opts.validate(nil)
+	if err != nil {
+		t.Fatalf("validate() error: %v", err)
+	}
+	if task := opts.buildTask(description); task.Spec.Params["pr"] != "123" {
+		t.Errorf("task params = %v", task.Spec.Params)
+	}
+	if req := opts.buildRequest(description); req.Params["repo"] != "org/x" {
+		t.Errorf("request params = %v", req.Params)
+	}
+
+	opts.params = []string{"repo=org/x"}
+	if _, err := opts.validate(nil); err == nil || !strings.Contains(err.Error(), "params.pr") {
+		t.Errorf("validate() error = %v, want undefined params.pr", err)
+	}
+}
+
+func TestPreviewTask(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = kubeopenv1alpha1.AddToScheme(s)
+
+	tmpl := &kubeopenv1alpha1.AgentTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "pr-review", Namespace: "ci"},
+		Spec: kubeopenv1alpha1.AgentTemplateSpec{
+			WorkspaceDir:       "/workspace",
+			ServiceAccountName: "reviewer",
+			Contexts: []kubeopenv1alpha1.ContextItem{
+				{Name: "guide", Type: kubeopenv1alpha1.ContextTypeText, Text: "Only review ${params.repo}"},
+			},
+		},
+	}
+	agent := &kubeopenv1alpha1.Agent{
+		ObjectMeta: metav1.ObjectMeta{Name: "reviewer", Namespace: "ci"},
+		Spec: kubeopenv1alpha1.AgentSpec{
+			TemplateRef:        &kubeopenv1alpha1.AgentTemplateReference{Name: "pr-review"},
+			ServiceAccountName: "agent-sa",
+		},
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(s).WithObjects(tmpl, agent).Build()
+	get := func(ctx context.Context, namespace, name string, obj client.Object) error {
+		return k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, obj)
+	}
+
+	opts := &taskCreateOptions{namespace: "ci", template: "pr-review", paramValues: map[string]string{"repo": "org/x", "pr": "123"}}
+	preview, err := previewTask(context.Background(), get, opts.buildTask("Review ${params.repo}#${params.pr} as {{ .task.name }}"))
+	if err != nil {
+		t.Fatalf("previewTask() error: %v", err)
+	}
+	if *preview.Task.Description != "Review org/x#123 as task-<generated>" {
+		t.Errorf("description = %q", *preview.Task.Description)
+	}
+	if preview.Agent.WorkspaceDir != "/workspace" || preview.Agent.ServiceAccountName != "reviewer" {
+		t.Errorf("agent spec = %+v, want the template's", preview.Agent)
+	}
+	if len(preview.Agent.Contexts) != 1 || preview.Agent.Contexts[0].Text != "Only review org/x" {
+		t.Errorf("agent contexts = %+v, want the rendered template context", preview.Agent.Contexts)
+	}
+	if tmpl.Spec.Contexts[0].Text != "Only review ${params.repo}" {
+		t.Errorf("template contexts were modified: %q", tmpl.Spec.Contexts[0].Text)
+	}
+
+	// Agent fields take precedence over its template's
+	opts = &taskCreateOptions{namespace: "ci", name: "review", agent: "reviewer", paramValues: map[string]string{"repo": "org/x"}}
+	preview, err = previewTask(context.Background(), get, opts.buildTask("Review"))
+	if err != nil {
+		t.Fatalf("previewTask() error: %v", err)
+	}
+	if preview.Agent.ServiceAccountName != "agent-sa" || preview.Agent.WorkspaceDir != "/workspace" {
+		t.Errorf("agent spec = %+v, want the agent merged with its template", preview.Agent)
+	}
+
+	// Template contexts may reference params the task does not set
+	opts.paramValues = nil
+	if _, err := previewTask(context.Background(), get, opts.buildTask("Review")); err == nil || !strings.Contains(err.Error(), "params.repo") {
+		t.Errorf("previewTask() error = %v, want undefined params.repo", err)
+	}
+
+	opts.agent = "missing"
+	if _, err := previewTask(context.Background(), get, opts.buildTask("Review")); err == nil || !strings.Contains(err.Error(), `agent "missing"`) {
+		t.Errorf("previewTask() error = %v, want a missing agent", err)
+	}
+}
+
+func TestServerClientGetObject(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/namespaces/ci/agenttemplates/pr-review" || r.URL.Query().Get("output") != "yaml" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("apiVersion: kubeopencode.io/v1alpha1\nkind: AgentTemplate\nmetadata:\n  name: pr-review\nspec:\n  workspaceDir: /workspace\n"))
+	}))
+	defer srv.Close()
+	c := &serverClient{baseURL: srv.URL, httpClient: srv.Client()}
+
+	var tmpl kubeopenv1alpha1.AgentTemplate
+	if err := c.getObject(context.Background(), "ci", "pr-review", &tmpl); err != nil {
+		t.Fatalf("getObject() error: %v", err)
+	}
+	if tmpl.Spec.WorkspaceDir != "/workspace" {
+		t.Errorf("workspaceDir = %q", tmpl.Spec.WorkspaceDir)
+	}
+	if err := c.getObject(context.Background(), "ci", "pr-review", &kubeopenv1alpha1.Agent{}); !isServerNotFound(err) {
+		t.Errorf("getObject() error = %v, want not found", err)
+	}
+}
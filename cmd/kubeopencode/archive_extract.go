@@ -0,0 +1,317 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Archive formats accepted in URL_EXTRACT (values of the Archive context's format field)
+const (
+	archiveFormatAuto  = "Auto"
+	archiveFormatTarGz = "TarGz"
+	archiveFormatTar   = "Tar"
+	archiveFormatZip   = "Zip"
+)
+
+// Limits on what a single archive may extract, so a small compressed download
+// cannot expand to fill the workspace volume (a decompression bomb).
+const (
+	defaultMaxExtractedBytes = 1 << 30 // 1 GiB; overridable with URL_MAX_EXTRACTED_BYTES
+	maxArchiveEntries        = 100000
+)
+
+// archiveBudget tracks how many bytes and entries an archive may still extract.
+type archiveBudget struct {
+	maxBytes int64
+	bytes    int64
+	entries  int
+}
+
+// takeEntry accounts for one more archive entry.
+func (b *archiveBudget) takeEntry() error {
+	b.entries--
+	if b.entries < 0 {
+		return fmt.Errorf("archive has more than %d entries", maxArchiveEntries)
+	}
+	return nil
+}
+
+// detectArchiveFormat detects the format of an archive file from its magic bytes.
+func detectArchiveFormat(file string) (string, error) {
+	f, err := os.Open(file) //nolint:gosec // path is the temp file created by url-fetch
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+
+	header := make([]byte, 262)
+	n, _ := io.ReadFull(f, header)
+	header = header[:n]
+	switch {
+	case bytes.HasPrefix(header, []byte{0x1f, 0x8b}):
+		return archiveFormatTarGz, nil
+	case bytes.HasPrefix(header, []byte("PK\x03\x04")), bytes.HasPrefix(header, []byte("PK\x05\x06")):
+		return archiveFormatZip, nil
+	case len(header) >= 262 && string(header[257:262]) == "ustar":
+		return archiveFormatTar, nil
+	}
+	return "", fmt.Errorf("unable to detect archive format; set format explicitly")
+}
+
+// extractArchive extracts an archive file into destDir, removing stripComponents
+// leading path components from each entry. Entries that would be written outside
+// destDir are rejected, and extraction fails once the entries' total size exceeds
+// maxBytes or the archive has more than maxArchiveEntries entries.
+func extractArchive(file, format, destDir string, stripComponents int, maxBytes int64) (int, error) {
+	if format == "" || format == archiveFormatAuto {
+		detected, err := detectArchiveFormat(file)
+		if err != nil {
+			return 0, err
+		}
+		format = detected
+	}
+	if err := os.MkdirAll(destDir, 0755); err != nil { //nolint:gosec // Needs group/others access for random UID environments
+		return 0, fmt.Errorf("failed to create directory %s: %w", destDir, err)
+	}
+
+	budget := &archiveBudget{maxBytes: maxBytes, bytes: maxBytes, entries: maxArchiveEntries}
+	switch format {
+	case archiveFormatTarGz, archiveFormatTar:
+		return extractTar(file, format == archiveFormatTarGz, destDir, stripComponents, budget)
+	case archiveFormatZip:
+		return extractZip(file, destDir, stripComponents, budget)
+	default:
+		return 0, fmt.Errorf("unsupported archive format %q", format)
+	}
+}
+
+// archiveEntryPath returns the destination path of an archive entry after stripping
+// leading components, or "" if the entry has no components left.
+func archiveEntryPath(destDir, name string, stripComponents int) (string, error) {
+	name = strings.ReplaceAll(name, "\\", "/")
+	for _, part := range strings.Split(name, "/") {
+		if part == ".." {
+			return "", fmt.Errorf("archive entry %q escapes the target directory", name)
+		}
+	}
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+	parts := strings.Split(name, "/")
+	if name == "" || len(parts) <= stripComponents {
+		return "", nil
+	}
+	return filepath.Join(destDir, filepath.FromSlash(strings.Join(parts[stripComponents:], "/"))), nil
+}
+
+// checkArchiveParents returns an error if a directory between destDir and target is
+// a symlink. Entries are never written through symlinks created by earlier entries,
+// since chained links (e.g. "b -> .." and "a -> b/..") can resolve outside destDir
+// even though each link alone stays inside it.
+func checkArchiveParents(destDir, target string) error {
+	rel, err := filepath.Rel(destDir, filepath.Dir(target))
+	if err != nil || rel == "." {
+		return err
+	}
+	dir := destDir
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		dir = filepath.Join(dir, part)
+		info, err := os.Lstat(dir)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("archive entry %s is inside symlink %s", target, dir)
+		}
+	}
+	return nil
+}
+
+// writeArchiveFile writes an archive entry's content to target, creating parent directories.
+// Only the permission bits of mode are kept, and files stay readable for the agent.
+// A symlink at target is replaced rather than written through. The written bytes are
+// charged to budget, and writing fails once the budget is exhausted.
+func writeArchiveFile(destDir, target string, r io.Reader, mode os.FileMode, budget *archiveBudget) error {
+	if err := checkArchiveParents(destDir, target); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil { //nolint:gosec // Needs group/others access for random UID environments
+		return err
+	}
+	if info, err := os.Lstat(target); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		if err := os.Remove(target); err != nil {
+			return err
+		}
+	}
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode.Perm()|0644) //nolint:gosec // target is validated by archiveEntryPath
+	if err != nil {
+		return err
+	}
+	// Read one byte past the budget to tell an entry that fits exactly from one that doesn't
+	n, err := io.Copy(f, io.LimitReader(r, budget.bytes+1))
+	budget.bytes -= n
+	if err == nil && budget.bytes < 0 {
+		err = fmt.Errorf("archive expands to more than %d bytes", budget.maxBytes)
+	}
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeArchiveSymlink creates a symlink from an archive entry. Absolute links,
+// links resolving outside destDir and links inside another symlink are rejected.
+func writeArchiveSymlink(destDir, target, linkname string) error {
+	if filepath.IsAbs(linkname) {
+		return fmt.Errorf("symlink %s has absolute target %q", target, linkname)
+	}
+	resolved := filepath.Join(filepath.Dir(target), linkname)
+	if rel, err := filepath.Rel(destDir, resolved); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("symlink %s points outside the target directory", target)
+	}
+	if err := checkArchiveParents(destDir, target); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil { //nolint:gosec // Needs group/others access for random UID environments
+		return err
+	}
+	_ = os.Remove(target)
+	return os.Symlink(linkname, target)
+}
+
+// writeArchiveDir creates a directory from an archive entry.
+func writeArchiveDir(destDir, target string) error {
+	if err := checkArchiveParents(destDir, target); err != nil {
+		return err
+	}
+	return os.MkdirAll(target, 0755) //nolint:gosec // Needs group/others access for random UID environments
+}
+
+func extractTar(file string, gzipped bool, destDir string, stripComponents int, budget *archiveBudget) (int, error) {
+	f, err := os.Open(file) //nolint:gosec // path is the temp file created by url-fetch
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = f.Close() }()
+
+	var r io.Reader = f
+	if gzipped {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return 0, fmt.Errorf("invalid gzip archive: %w", err)
+		}
+		defer func() { _ = gz.Close() }()
+		r = gz
+	}
+
+	count := 0
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return count, nil
+		}
+		if err != nil {
+			return count, fmt.Errorf("invalid tar archive: %w", err)
+		}
+		if err := budget.takeEntry(); err != nil {
+			return count, err
+		}
+		target, err := archiveEntryPath(destDir, hdr.Name, stripComponents)
+		if err != nil {
+			return count, err
+		}
+		if target == "" {
+			continue
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := writeArchiveDir(destDir, target); err != nil {
+				return count, err
+			}
+		case tar.TypeReg:
+			if err := writeArchiveFile(destDir, target, tr, os.FileMode(hdr.Mode), budget); err != nil { //nolint:gosec // mode is masked to permission bits
+				return count, fmt.Errorf("failed to extract %s: %w", hdr.Name, err)
+			}
+			count++
+		case tar.TypeSymlink:
+			if err := writeArchiveSymlink(destDir, target, hdr.Linkname); err != nil {
+				return count, err
+			}
+		default:
+			// Hard links, devices and FIFOs are not useful as context and are skipped
+			fmt.Printf("url-fetch: Skipping unsupported entry %s\n", hdr.Name)
+		}
+	}
+}
+
+func extractZip(file, destDir string, stripComponents int, budget *archiveBudget) (int, error) {
+	zr, err := zip.OpenReader(file)
+	if err != nil {
+		return 0, fmt.Errorf("invalid zip archive: %w", err)
+	}
+	defer func() { _ = zr.Close() }()
+
+	count := 0
+	for _, zf := range zr.File {
+		if err := budget.takeEntry(); err != nil {
+			return count, err
+		}
+		target, err := archiveEntryPath(destDir, zf.Name, stripComponents)
+		if err != nil {
+			return count, err
+		}
+		if target == "" {
+			continue
+		}
+
+		mode := zf.Mode()
+		switch {
+		case mode.IsDir():
+			if err := writeArchiveDir(destDir, target); err != nil {
+				return count, err
+			}
+		case mode&os.ModeSymlink != 0:
+			rc, err := zf.Open()
+			if err != nil {
+				return count, err
+			}
+			// Link targets are short; a larger entry is not a real symlink
+			linkname, err := io.ReadAll(io.LimitReader(rc, 4096))
+			_ = rc.Close()
+			if err != nil {
+				return count, err
+			}
+			if err := writeArchiveSymlink(destDir, target, string(linkname)); err != nil {
+				return count, err
+			}
+		case mode.IsRegular():
+			rc, err := zf.Open()
+			if err != nil {
+				return count, fmt.Errorf("failed to extract %s: %w", zf.Name, err)
+			}
+			err = writeArchiveFile(destDir, target, rc, mode, budget)
+			_ = rc.Close()
+			if err != nil {
+				return count, fmt.Errorf("failed to extract %s: %w", zf.Name, err)
+			}
+			count++
+		default:
+			fmt.Printf("url-fetch: Skipping unsupported entry %s\n", zf.Name)
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,323 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type archiveEntry struct {
+	name    string
+	content string
+	dir     bool
+	link    string
+}
+
+func buildTarGz(t *testing.T, entries []archiveEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Mode: 0644, Size: int64(len(e.content)), Typeflag: tar.TypeReg}
+		switch {
+		case e.dir:
+			hdr = &tar.Header{Name: e.name, Mode: 0755, Typeflag: tar.TypeDir}
+		case e.link != "":
+			hdr = &tar.Header{Name: e.name, Linkname: e.link, Typeflag: tar.TypeSymlink}
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Typeflag == tar.TypeReg {
+			if _, err := tw.Write([]byte(e.content)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func buildZip(t *testing.T, entries []archiveEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, e := range entries {
+		w, err := zw.Create(e.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(e.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestExtractArchive(t *testing.T) {
+	release := []archiveEntry{
+		{name: "release-1.2.0/", dir: true},
+		{name: "release-1.2.0/README.md", content: "readme"},
+		{name: "release-1.2.0/docs/guide.md", content: "guide"},
+	}
+
+	tests := []struct {
+		name      string
+		data      []byte
+		format    string
+		strip     int
+		wantFiles map[string]string
+		wantErr   bool
+	}{
+		{
+			name:      "tar.gz detected from content",
+			data:      buildTarGz(t, release),
+			format:    archiveFormatAuto,
+			wantFiles: map[string]string{"release-1.2.0/README.md": "readme", "release-1.2.0/docs/guide.md": "guide"},
+		},
+		{
+			name:      "tar.gz with strip components",
+			data:      buildTarGz(t, release),
+			format:    archiveFormatTarGz,
+			strip:     1,
+			wantFiles: map[string]string{"README.md": "readme", "docs/guide.md": "guide"},
+		},
+		{
+			name:      "zip with strip components",
+			data:      buildZip(t, release[1:]),
+			format:    archiveFormatAuto,
+			strip:     1,
+			wantFiles: map[string]string{"README.md": "readme", "docs/guide.md": "guide"},
+		},
+		{
+			name:      "relative symlink inside target",
+			data:      buildTarGz(t, []archiveEntry{{name: "a.md", content: "a"}, {name: "b.md", link: "a.md"}}),
+			format:    archiveFormatTarGz,
+			wantFiles: map[string]string{"a.md": "a", "b.md": "a"},
+		},
+		{
+			name:    "path traversal",
+			data:    buildTarGz(t, []archiveEntry{{name: "../evil.sh", content: "x"}}),
+			format:  archiveFormatTarGz,
+			wantErr: true,
+		},
+		{
+			name:    "zip path traversal",
+			data:    buildZip(t, []archiveEntry{{name: "docs/../../evil.sh", content: "x"}}),
+			format:  archiveFormatZip,
+			wantErr: true,
+		},
+		{
+			name:    "symlink escaping target",
+			data:    buildTarGz(t, []archiveEntry{{name: "link", link: "../../etc/passwd"}}),
+			format:  archiveFormatTarGz,
+			wantErr: true,
+		},
+		{
+			name: "chained symlinks escaping target",
+			data: buildTarGz(t, []archiveEntry{
+				{name: "sub/", dir: true},
+				{name: "sub/b", link: ".."},
+				{name: "sub/a", link: "b/.."},
+				{name: "sub/a/evil.sh", content: "x"},
+			}),
+			format:  archiveFormatTarGz,
+			wantErr: true,
+		},
+		{
+			name: "file replacing a symlink",
+			data: buildTarGz(t, []archiveEntry{
+				{name: "a.md", content: "a"},
+				{name: "b.md", link: "a.md"},
+				{name: "b.md", content: "b"},
+			}),
+			format:    archiveFormatTarGz,
+			wantFiles: map[string]string{"a.md": "a", "b.md": "b"},
+		},
+		{
+			name:    "undetectable format",
+			data:    []byte("not an archive"),
+			format:  archiveFormatAuto,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmp := t.TempDir()
+			file := filepath.Join(tmp, "archive")
+			if err := os.WriteFile(file, tt.data, 0600); err != nil {
+				t.Fatal(err)
+			}
+			dest := filepath.Join(tmp, "out")
+
+			_, err := extractArchive(file, tt.format, dest, tt.strip, defaultMaxExtractedBytes)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("extractArchive() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if _, statErr := os.Stat(filepath.Join(tmp, "evil.sh")); statErr == nil {
+				t.Fatal("file written outside the target directory")
+			}
+			for name, want := range tt.wantFiles {
+				got, err := os.ReadFile(filepath.Join(dest, name))
+				if err != nil {
+					t.Errorf("reading %s: %v", name, err)
+					continue
+				}
+				if string(got) != want {
+					t.Errorf("%s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractArchive_Limits(t *testing.T) {
+	// 64 MiB of zeros compresses to well under 1 MiB
+	zeros := strings.Repeat("\x00", 64<<20)
+	const limit = 1 << 20
+
+	tests := []struct {
+		name    string
+		data    []byte
+		maxSize int64
+		wantErr string
+	}{
+		{
+			name:    "gzip bomb",
+			data:    buildTarGz(t, []archiveEntry{{name: "zeros", content: zeros}}),
+			maxSize: limit,
+			wantErr: "archive expands to more than",
+		},
+		{
+			name:    "zip bomb",
+			data:    buildZip(t, []archiveEntry{{name: "zeros", content: zeros}}),
+			maxSize: limit,
+			wantErr: "archive expands to more than",
+		},
+		{
+			name: "budget spread across entries",
+			data: buildZip(t, []archiveEntry{
+				{name: "a", content: zeros[:limit/2]},
+				{name: "b", content: zeros[:limit/2]},
+				{name: "c", content: "x"},
+			}),
+			maxSize: limit,
+			wantErr: "archive expands to more than",
+		},
+		{
+			name:    "entry exactly at the limit",
+			data:    buildTarGz(t, []archiveEntry{{name: "zeros", content: zeros[:limit]}}),
+			maxSize: limit,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if len(tt.data) >= limit {
+				t.Fatalf("compressed archive is %d bytes, want a small archive", len(tt.data))
+			}
+			tmp := t.TempDir()
+			file := filepath.Join(tmp, "archive")
+			if err := os.WriteFile(file, tt.data, 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := extractArchive(file, archiveFormatAuto, filepath.Join(tmp, "out"), 0, tt.maxSize)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("extractArchive() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("extractArchive() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExtractTar_EntryLimit(t *testing.T) {
+	tmp := t.TempDir()
+	file := filepath.Join(tmp, "archive.tgz")
+	data := buildTarGz(t, []archiveEntry{{name: "a"}, {name: "b"}, {name: "c"}})
+	if err := os.WriteFile(file, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	budget := &archiveBudget{maxBytes: defaultMaxExtractedBytes, bytes: defaultMaxExtractedBytes, entries: 2}
+	count, err := extractTar(file, true, filepath.Join(tmp, "out"), 0, budget)
+	if err == nil || !strings.Contains(err.Error(), "more than") {
+		t.Fatalf("extractTar() error = %v, want entry limit error", err)
+	}
+	if count != 2 {
+		t.Errorf("extracted %d files before the limit, want 2", count)
+	}
+}
+
+func TestRunURLFetch_Extract(t *testing.T) {
+	data := buildTarGz(t, []archiveEntry{{name: "bundle/notes.md", content: "notes"}})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+
+	target := filepath.Join(t.TempDir(), "release")
+	t.Setenv(envURLSource, server.URL+"/bundle.tgz")
+	t.Setenv(envURLTarget, target)
+	t.Setenv(envURLExtract, archiveFormatAuto)
+	t.Setenv(envURLStripComponents, "1")
+
+	if err := runURLFetch(nil, nil); err != nil {
+		t.Fatalf("runURLFetch() error = %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(target, "notes.md"))
+	if err != nil || string(content) != "notes" {
+		t.Errorf("notes.md = %q, %v", content, err)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(target))
+	if len(entries) != 1 {
+		t.Errorf("temporary files left behind: %v", entries)
+	}
+}
+
+func TestRunURLFetch_ExtractLimit(t *testing.T) {
+	data := buildTarGz(t, []archiveEntry{{name: "big", content: strings.Repeat("a", 1024)}})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+
+	t.Setenv(envURLSource, server.URL+"/bundle.tgz")
+	t.Setenv(envURLTarget, filepath.Join(t.TempDir(), "release"))
+	t.Setenv(envURLExtract, archiveFormatTarGz)
+	t.Setenv(envURLMaxExtracted, "512")
+
+	err := runURLFetch(nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "more than 512 bytes") {
+		t.Fatalf("runURLFetch() error = %v, want extracted size error", err)
+	}
+
+	t.Setenv(envURLMaxExtracted, "0")
+	if err := runURLFetch(nil, nil); err == nil {
+		t.Fatal("runURLFetch() accepted URL_MAX_EXTRACTED_BYTES=0")
+	}
+}
@@ -30,8 +30,10 @@ type FileMapping struct {
 
 // DirMapping represents a mapping from source directory to target directory
 type DirMapping struct {
-	SourcePath string `json:"sourcePath"`
-	TargetPath string `json:"targetPath"`
+	SourcePath string           `json:"sourcePath"`
+	TargetPath string           `json:"targetPath"`
+	FileMode   *int32           `json:"fileMode,omitempty"`  // Optional default mode for all copied files
+	FileModes  map[string]int32 `json:"fileModes,omitempty"` // Optional per-file modes keyed by relative path
 }
 
 func init() {
@@ -108,12 +110,20 @@ func runContextInit(cmd *cobra.Command, args []string) error {
 
 		fmt.Printf("  Directory mappings: %d\n", len(dirMappings))
 		for _, dm := range dirMappings {
-			if err := copyDir(dm.SourcePath, dm.TargetPath); err != nil {
+			if err := copyDir(dm.SourcePath, dm.TargetPath, dm.FileMode); err != nil {
 				// Log warning but continue - some directories might be optional
 				fmt.Printf("context-init: Warning: failed to copy directory %s to %s: %v\n", dm.SourcePath, dm.TargetPath, err)
 			} else {
 				fmt.Printf("context-init: Copied directory %s -> %s\n", dm.SourcePath, dm.TargetPath)
 			}
+			for relPath, mode := range dm.FileModes {
+				target := filepath.Join(dm.TargetPath, relPath)
+				if err := os.Chmod(target, os.FileMode(uint32(mode))); err != nil { //nolint:gosec // mode is validated by Kubernetes API
+					fmt.Printf("context-init: Warning: could not chmod %s: %v\n", target, err)
+				} else {
+					fmt.Printf("context-init: Set mode %04o on %s\n", mode, target)
+				}
+			}
 		}
 	}
 
@@ -127,12 +137,8 @@ func runContextInit(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-// copyFile copies a file from src to dst, creating parent directories as needed
-func copyFile(src, dst string) error {
-	return copyFileWithMode(src, dst, nil)
-}
-
-// copyFileWithMode copies a file from src to dst with optional file mode
+// copyFileWithMode copies a file from src to dst with optional file mode,
+// creating parent directories as needed
 func copyFileWithMode(src, dst string, fileMode *int32) error {
 	// Check if source file exists
 	srcInfo, err := os.Stat(src)
@@ -182,8 +188,9 @@ func copyFileWithMode(src, dst string, fileMode *int32) error {
 	return nil
 }
 
-// copyDir recursively copies a directory from src to dst
-func copyDir(src, dst string) error {
+// copyDir recursively copies a directory from src to dst.
+// Files are created with fileMode if provided, otherwise 0644.
+func copyDir(src, dst string, fileMode *int32) error {
 	// Check if source directory exists
 	srcInfo, err := os.Stat(src)
 	if err != nil {
@@ -224,11 +231,11 @@ func copyDir(src, dst string) error {
 		}
 
 		if info.IsDir() {
-			if err := copyDir(srcPath, dstPath); err != nil {
+			if err := copyDir(srcPath, dstPath, fileMode); err != nil {
 				return err
 			}
 		} else {
-			if err := copyFile(srcPath, dstPath); err != nil {
+			if err := copyFileWithMode(srcPath, dstPath, fileMode); err != nil {
 				return err
 			}
 		}
@@ -3,12 +3,15 @@
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -19,6 +22,8 @@ import (
 
 	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
 	"github.com/kubeopencode/kubeopencode/internal/controller"
+	"github.com/kubeopencode/kubeopencode/internal/tracing"
+	kubeopenwebhook "github.com/kubeopencode/kubeopencode/internal/webhook"
 )
 
 var (
@@ -53,6 +58,16 @@ var (
 	enableLeaderElection bool
 	secureMetrics        bool
 	enableHTTP2          bool
+	enableWebhooks       bool
+	webhookCertDir       string
+	shardIndex           int
+	shardCount           int
+	shardSelector        string
+	taskConcurrency      int
+	agentConcurrency     int
+	kubeAPIQPS           float32
+	kubeAPIBurst         int
+	orphanInterval       time.Duration
 )
 
 func init() {
@@ -67,6 +82,29 @@ func init() {
 		"If set the metrics endpoint is served securely")
 	controllerCmd.Flags().BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	controllerCmd.Flags().BoolVar(&enableWebhooks, "enable-webhooks", false,
+		"Serve the admission webhooks for KubeOpenCode resources")
+	controllerCmd.Flags().StringVar(&webhookCertDir, "webhook-cert-dir", "",
+		"Directory with the webhook serving certificate (tls.crt and tls.key). "+
+			"Defaults to <temp-dir>/k8s-webhook-server/serving-certs.")
+	controllerCmd.Flags().IntVar(&shardCount, "shard-count", 1,
+		"Number of controller shards. Namespaces are assigned to shards by hash; "+
+			"run one controller per shard index.")
+	controllerCmd.Flags().IntVar(&shardIndex, "shard-index", 0,
+		"Index of the shard reconciled by this controller, in [0, shard-count).")
+	controllerCmd.Flags().StringVar(&shardSelector, "shard-namespace-selector", "",
+		"Label selector of the namespaces reconciled by this controller, as an alternative to "+
+			"hash-based sharding. Selectors of different controllers must not overlap.")
+	controllerCmd.Flags().IntVar(&taskConcurrency, "task-concurrency", 1,
+		"Number of Tasks reconciled in parallel.")
+	controllerCmd.Flags().IntVar(&agentConcurrency, "agent-concurrency", 1,
+		"Number of Agents reconciled in parallel.")
+	controllerCmd.Flags().Float32Var(&kubeAPIQPS, "kube-api-qps", 20,
+		"Maximum sustained queries per second to the Kubernetes API server.")
+	controllerCmd.Flags().IntVar(&kubeAPIBurst, "kube-api-burst", 30,
+		"Maximum burst of queries to the Kubernetes API server.")
+	controllerCmd.Flags().DurationVar(&orphanInterval, "orphan-collection-interval", controller.DefaultOrphanCollectionInterval,
+		"Interval between sweeps deleting task Pods and ConfigMaps whose Task no longer exists. 0 disables the sweeps.")
 }
 
 func runController(cmd *cobra.Command, args []string) error {
@@ -75,6 +113,18 @@ func runController(cmd *cobra.Command, args []string) error {
 	}
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	// Tracing is configured by the standard OTEL_EXPORTER_OTLP_* environment variables
+	shutdownTracing, err := tracing.Setup(context.Background(), "kubeopencode-controller")
+	if err != nil {
+		setupLog.Error(err, "unable to set up tracing")
+		os.Exit(1)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			setupLog.Error(err, "unable to flush traces")
+		}
+	}()
+
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancelation and
@@ -91,11 +141,28 @@ func runController(cmd *cobra.Command, args []string) error {
 		tlsOpts = append(tlsOpts, disableHTTP2)
 	}
 
+	shard, err := controller.NewShard(shardIndex, shardCount, shardSelector)
+	if err != nil {
+		setupLog.Error(err, "invalid shard configuration")
+		os.Exit(1)
+	}
+	// Every shard elects its own leader, so the shards run side by side
+	leaderElectionID := "kubeopencode.io"
+	if shard != nil {
+		leaderElectionID = "kubeopencode.io-shard-" + shard.ID()
+		setupLog.Info("reconciling a shard of the namespaces", "shard", shard.ID())
+	}
+
 	webhookServer := webhook.NewServer(webhook.Options{
+		CertDir: webhookCertDir,
 		TLSOpts: tlsOpts,
 	})
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+	restConfig := ctrl.GetConfigOrDie()
+	restConfig.QPS = kubeAPIQPS
+	restConfig.Burst = kubeAPIBurst
+
+	mgr, err := ctrl.NewManager(restConfig, ctrl.Options{
 		Scheme: scheme,
 		Metrics: metricsserver.Options{
 			BindAddress:   metricsAddr,
@@ -105,7 +172,7 @@ func runController(cmd *cobra.Command, args []string) error {
 		WebhookServer:          webhookServer,
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
-		LeaderElectionID:       "kubeopencode.io",
+		LeaderElectionID:       leaderElectionID,
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
 		// when the Manager ends. This requires the binary to immediately end when the
 		// Manager is stopped, otherwise, this setting is unsafe. Setting this significantly
@@ -123,19 +190,35 @@ func runController(cmd *cobra.Command, args []string) error {
 		os.Exit(1)
 	}
 
-	if err = controller.NewTaskReconciler(
+	if shard != nil {
+		shard.Reader = mgr.GetClient()
+	}
+
+	taskReconciler := controller.NewTaskReconciler(
 		mgr.GetClient(),
 		mgr.GetScheme(),
 		mgr.GetEventRecorder("task-controller"),
-	).SetupWithManager(mgr); err != nil {
+	)
+	taskReconciler.Shard = shard
+	taskReconciler.MaxConcurrentReconciles = taskConcurrency
+	taskReconciler.APIReader = mgr.GetAPIReader()
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		setupLog.Error(err, "unable to create clientset")
+		os.Exit(1)
+	}
+	taskReconciler.PodLogs = controller.NewPodLogsFunc(clientset)
+	if err = taskReconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Task")
 		os.Exit(1)
 	}
 
 	if err = (&controller.AgentReconciler{
-		Client:   mgr.GetClient(),
-		Scheme:   mgr.GetScheme(),
-		Recorder: mgr.GetEventRecorder("agent-controller"),
+		Client:                  mgr.GetClient(),
+		Scheme:                  mgr.GetScheme(),
+		Recorder:                mgr.GetEventRecorder("agent-controller"),
+		Shard:                   shard,
+		MaxConcurrentReconciles: agentConcurrency,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Agent")
 		os.Exit(1)
@@ -144,6 +227,7 @@ func runController(cmd *cobra.Command, args []string) error {
 	if err = (&controller.AgentTemplateReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
+		Shard:  shard,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "AgentTemplate")
 		os.Exit(1)
@@ -153,6 +237,7 @@ func runController(cmd *cobra.Command, args []string) error {
 		Client:   mgr.GetClient(),
 		Scheme:   mgr.GetScheme(),
 		Recorder: mgr.GetEventRecorder("crontask-controller"),
+		Shard:    shard,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "CronTask")
 		os.Exit(1)
@@ -161,11 +246,31 @@ func runController(cmd *cobra.Command, args []string) error {
 	if err = (&controller.RegistryReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
+		Shard:  shard,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Registry")
 		os.Exit(1)
 	}
 
+	if orphanInterval > 0 {
+		if err = mgr.Add(&controller.OrphanCollector{
+			Client:    mgr.GetClient(),
+			APIReader: mgr.GetAPIReader(),
+			Interval:  orphanInterval,
+			Shard:     shard,
+		}); err != nil {
+			setupLog.Error(err, "unable to create orphan collector")
+			os.Exit(1)
+		}
+	}
+
+	if enableWebhooks {
+		if err = kubeopenwebhook.SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhooks")
+			os.Exit(1)
+		}
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -0,0 +1,136 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// envGitCacheDir points git-init at the shared context cache. When empty, caching is disabled.
+const envGitCacheDir = "GIT_CACHE_DIR"
+
+// commitSHAPattern matches a full Git commit SHA (SHA-1 or SHA-256).
+var commitSHAPattern = regexp.MustCompile(`^([0-9a-f]{40}|[0-9a-f]{64})$`)
+
+// gitCacheKey returns the cache key for a clone: a hash of everything that
+// determines the cloned content except the commit itself.
+func gitCacheKey(repo, ref string, depth int, recurseSubmodules bool, sparsePath string) string {
+	sum := sha256.Sum256(fmt.Appendf(nil, "%s\x00%s\x00%d\x00%t\x00%s", repo, ref, depth, recurseSubmodules, sparsePath))
+	return hex.EncodeToString(sum[:16])
+}
+
+// resolveRemoteCommit resolves ref to a commit SHA on the remote without cloning.
+func resolveRemoteCommit(repo, ref string) (string, error) {
+	if commitSHAPattern.MatchString(ref) {
+		return ref, nil
+	}
+	out, err := exec.Command("git", "ls-remote", repo, ref).Output() //nolint:gosec // args are constructed from controlled inputs
+	if err != nil {
+		return "", fmt.Errorf("git ls-remote failed: %w", err)
+	}
+	commit := selectRemoteCommit(string(out), ref)
+	if commit == "" {
+		return "", fmt.Errorf("ref %q not found on remote", ref)
+	}
+	return commit, nil
+}
+
+// selectRemoteCommit picks the commit for ref from "git ls-remote" output.
+// ls-remote matches refs by suffix, so exact branch and tag names are preferred,
+// and peeled annotated tags ("^{}") resolve to the tagged commit.
+func selectRemoteCommit(lsRemoteOutput, ref string) string {
+	refs := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(lsRemoteOutput), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 {
+			refs[fields[1]] = fields[0]
+		}
+	}
+	for _, name := range []string{
+		ref,
+		"refs/heads/" + ref,
+		"refs/tags/" + ref + "^{}",
+		"refs/tags/" + ref,
+	} {
+		if commit, ok := refs[name]; ok {
+			return commit
+		}
+	}
+	return ""
+}
+
+// restoreFromCache copies a cached clone into targetDir.
+// Returns false if the cache entry does not exist or cannot be copied.
+func restoreFromCache(entry, targetDir string) bool {
+	if _, err := os.Stat(filepath.Join(entry, ".git")); err != nil {
+		return false
+	}
+	if err := copyTree(entry, targetDir); err != nil {
+		fmt.Printf("git-init: Warning: failed to restore from cache: %v\n", err)
+		_ = os.RemoveAll(targetDir)
+		return false
+	}
+	return true
+}
+
+// populateCache stores a fresh clone of commit under keyDir and removes cache
+// entries for older commits of the same key. Entries are written to a temporary
+// directory and renamed into place, so concurrent Pods never see partial entries.
+func populateCache(targetDir, keyDir, commit string) error {
+	if err := os.MkdirAll(keyDir, 0755); err != nil { //nolint:gosec // Shared cache is accessed by random UIDs
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	entry := filepath.Join(keyDir, commit)
+	if _, err := os.Stat(entry); err == nil {
+		return nil
+	}
+
+	tmp, err := os.MkdirTemp(keyDir, ".tmp-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary cache entry: %w", err)
+	}
+	if err := copyTree(targetDir, tmp); err != nil {
+		_ = os.RemoveAll(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, entry); err != nil {
+		// Another Pod may have populated the same entry concurrently
+		_ = os.RemoveAll(tmp)
+		if _, statErr := os.Stat(entry); statErr == nil {
+			return nil
+		}
+		return fmt.Errorf("failed to store cache entry: %w", err)
+	}
+
+	// Keep only the latest commit per key so the cache does not grow unbounded
+	entries, err := os.ReadDir(keyDir)
+	if err != nil {
+		return nil
+	}
+	for _, e := range entries {
+		if e.Name() != commit && !strings.HasPrefix(e.Name(), ".tmp-") {
+			_ = os.RemoveAll(filepath.Join(keyDir, e.Name()))
+		}
+	}
+	return nil
+}
+
+// copyTree copies the contents of src into dst, preserving modes and symlinks.
+func copyTree(src, dst string) error {
+	if err := os.MkdirAll(dst, 0755); err != nil { //nolint:gosec // Needs group/others access for random UID environments
+		return fmt.Errorf("failed to create %s: %w", dst, err)
+	}
+	cpCmd := exec.Command("cp", "-a", src+"/.", dst+"/") //nolint:gosec // paths are from controlled env vars
+	cpCmd.Stderr = os.Stderr
+	if err := cpCmd.Run(); err != nil {
+		return fmt.Errorf("failed to copy %s to %s: %w", src, dst, err)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGitCacheKey(t *testing.T) {
+	base := gitCacheKey("https://github.com/org/repo.git", "main", 1, false, "")
+	if base != gitCacheKey("https://github.com/org/repo.git", "main", 1, false, "") {
+		t.Error("gitCacheKey() should be deterministic")
+	}
+	for name, key := range map[string]string{
+		"ref":        gitCacheKey("https://github.com/org/repo.git", "develop", 1, false, ""),
+		"depth":      gitCacheKey("https://github.com/org/repo.git", "main", 0, false, ""),
+		"submodules": gitCacheKey("https://github.com/org/repo.git", "main", 1, true, ""),
+		"sparse":     gitCacheKey("https://github.com/org/repo.git", "main", 1, false, "/docs"),
+	} {
+		if key == base {
+			t.Errorf("gitCacheKey() should differ when %s differs", name)
+		}
+	}
+}
+
+func TestSelectRemoteCommit(t *testing.T) {
+	output := `1111111111111111111111111111111111111111	HEAD
+2222222222222222222222222222222222222222	refs/heads/feature/main
+3333333333333333333333333333333333333333	refs/heads/main
+4444444444444444444444444444444444444444	refs/tags/v1.0
+5555555555555555555555555555555555555555	refs/tags/v1.0^{}
+`
+	tests := map[string]string{
+		"HEAD":    "1111111111111111111111111111111111111111",
+		"main":    "3333333333333333333333333333333333333333",
+		"v1.0":    "5555555555555555555555555555555555555555",
+		"missing": "",
+	}
+	for ref, want := range tests {
+		if got := selectRemoteCommit(output, ref); got != want {
+			t.Errorf("selectRemoteCommit(%q) = %q, want %q", ref, got, want)
+		}
+	}
+}
+
+func TestResolveRemoteCommit_CommitSHA(t *testing.T) {
+	sha := "0123456789abcdef0123456789abcdef01234567"
+	got, err := resolveRemoteCommit("https://invalid.example.com/repo.git", sha)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != sha {
+		t.Errorf("resolveRemoteCommit() = %q, want %q", got, sha)
+	}
+}
+
+func TestPopulateAndRestoreCache(t *testing.T) {
+	clone := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(clone, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(clone, "README.md"), []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	keyDir := filepath.Join(t.TempDir(), "key")
+	if err := populateCache(clone, keyDir, "old"); err != nil {
+		t.Fatalf("populateCache(old) error = %v", err)
+	}
+	if err := populateCache(clone, keyDir, "new"); err != nil {
+		t.Fatalf("populateCache(new) error = %v", err)
+	}
+
+	// Older commits of the same key are evicted
+	if _, err := os.Stat(filepath.Join(keyDir, "old")); !os.IsNotExist(err) {
+		t.Errorf("expected old cache entry to be evicted, stat err = %v", err)
+	}
+
+	target := filepath.Join(t.TempDir(), "repo")
+	if !restoreFromCache(filepath.Join(keyDir, "new"), target) {
+		t.Fatal("expected cache hit")
+	}
+	content, err := os.ReadFile(filepath.Join(target, "README.md"))
+	if err != nil || string(content) != "hello" {
+		t.Errorf("restored README.md = %q, %v", content, err)
+	}
+
+	if restoreFromCache(filepath.Join(keyDir, "missing"), filepath.Join(t.TempDir(), "repo")) {
+		t.Error("expected cache miss for unknown commit")
+	}
+}
@@ -69,6 +69,8 @@ Environment variables:
   GIT_SSH_KEY             SSH private key (content or file path)
   GIT_SSH_KNOWN_HOSTS     Known hosts content for SSH verification
   GIT_RECURSE_SUBMODULES  If "true", recursively clone submodules
+  GIT_REPO_SUBPATH        Path within the repository; enables sparse checkout of only that path
+  GIT_CACHE_DIR           Shared context cache directory; reuses clones of the same ref and commit
   GIT_CLONE_RETRIES        Number of retry attempts for git clone, default: 3
   GIT_CLONE_RETRY_DELAY    Delay between retry attempts (Go duration), default: 5s`,
 	RunE: runGitInit,
@@ -145,55 +147,23 @@ func runGitInit(cmd *cobra.Command, args []string) error {
 		// Check if submodule cloning is enabled
 		recurseSubmodules := os.Getenv(envGitRecurseSubmodules) == "true"
 
-		// Build git clone command
-		cloneArgs := []string{"clone", "--depth", strconv.Itoa(depth), "--single-branch"}
+		// Sparse checkout only the requested subpath. Submodules may live outside
+		// the subpath, so a full checkout is kept when they are requested.
+		sparsePath := ""
+		if !recurseSubmodules {
+			sparsePath = sparseCheckoutPattern(os.Getenv(envGitRepoSubpath))
+		}
 
+		cloneArgs := buildCloneArgs(repo, targetDir, ref, depth, recurseSubmodules, sparsePath != "")
 		if recurseSubmodules {
-			cloneArgs = append(cloneArgs, "--recurse-submodules")
 			fmt.Println("  Submodules: recursive")
 		}
-
-		// Add branch flag if not HEAD
-		if ref != "HEAD" {
-			cloneArgs = append(cloneArgs, "--branch", ref)
-		}
-
-		cloneArgs = append(cloneArgs, repo, targetDir)
-
-		// Retry clone on transient failures (network/TLS errors)
-		retries := getEnvIntOrDefault(envGitCloneRetries, defaultRetries)
-		retryDelay := getEnvDurationOrDefault(envGitCloneRetryDelay, defaultRetryDelay)
-
-		var lastErr error
-		for attempt := 1; attempt <= retries; attempt++ {
-			if attempt > 1 {
-				fmt.Printf("git-init: Retry attempt %d/%d (waiting %v)...\n", attempt, retries, retryDelay)
-				time.Sleep(retryDelay)
-
-				// Clean up partial clone directory before retrying
-				if err := os.RemoveAll(targetDir); err != nil {
-					fmt.Printf("git-init: Failed to clean up %s before retry: %v\n", targetDir, err)
-				}
-			}
-
-			cloneCmd := exec.Command("git", cloneArgs...) //nolint:gosec // args are constructed from controlled inputs
-			cloneCmd.Stdout = os.Stdout
-			cloneCmd.Stderr = os.Stderr
-
-			lastErr = cloneCmd.Run()
-			if lastErr == nil {
-				break
-			}
-			fmt.Printf("git-init: Clone attempt %d/%d failed: %v\n", attempt, retries, lastErr)
-		}
-
-		if lastErr != nil {
-			return fmt.Errorf("git clone failed after %d attempts: %w", retries, lastErr)
+		if sparsePath != "" {
+			fmt.Printf("  Sparse checkout: %s\n", sparsePath)
 		}
 
-		// Verify clone was successful
-		if _, err := os.Stat(gitDir); os.IsNotExist(err) {
-			return fmt.Errorf("clone verification failed: .git directory not found")
+		if err := cloneOrRestore(repo, ref, targetDir, depth, recurseSubmodules, sparsePath, cloneArgs); err != nil {
+			return err
 		}
 	}
 
@@ -255,6 +225,133 @@ func runGitInit(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// cloneOrRestore populates targetDir with the repository, either from the shared
+// context cache (when GIT_CACHE_DIR is set and the remote ref still resolves to
+// the cached commit) or by cloning from the remote.
+func cloneOrRestore(repo, ref, targetDir string, depth int, recurseSubmodules bool, sparsePath string, cloneArgs []string) error {
+	gitDir := filepath.Join(targetDir, ".git")
+
+	keyDir := ""
+	if cacheDir := os.Getenv(envGitCacheDir); cacheDir != "" {
+		keyDir = filepath.Join(cacheDir, gitCacheKey(repo, ref, depth, recurseSubmodules, sparsePath))
+		commit, err := resolveRemoteCommit(repo, ref)
+		switch {
+		case err != nil:
+			fmt.Printf("git-init: Warning: cache lookup skipped: %v\n", err)
+		case restoreFromCache(filepath.Join(keyDir, commit), targetDir):
+			fmt.Printf("git-init: Restored %s from context cache\n", commit)
+			return nil
+		default:
+			fmt.Printf("git-init: Context cache miss for %s\n", commit)
+		}
+	}
+
+	// Retry clone on transient failures (network/TLS errors)
+	retries := getEnvIntOrDefault(envGitCloneRetries, defaultRetries)
+	retryDelay := getEnvDurationOrDefault(envGitCloneRetryDelay, defaultRetryDelay)
+
+	var lastErr error
+	for attempt := 1; attempt <= retries; attempt++ {
+		if attempt > 1 {
+			fmt.Printf("git-init: Retry attempt %d/%d (waiting %v)...\n", attempt, retries, retryDelay)
+			time.Sleep(retryDelay)
+
+			// Clean up partial clone directory before retrying
+			if err := os.RemoveAll(targetDir); err != nil {
+				fmt.Printf("git-init: Failed to clean up %s before retry: %v\n", targetDir, err)
+			}
+		}
+
+		cloneCmd := exec.Command("git", cloneArgs...) //nolint:gosec // args are constructed from controlled inputs
+		cloneCmd.Stdout = os.Stdout
+		cloneCmd.Stderr = os.Stderr
+
+		lastErr = cloneCmd.Run()
+		if lastErr == nil {
+			break
+		}
+		fmt.Printf("git-init: Clone attempt %d/%d failed: %v\n", attempt, retries, lastErr)
+	}
+
+	if lastErr != nil {
+		return fmt.Errorf("git clone failed after %d attempts: %w", retries, lastErr)
+	}
+
+	// Verify clone was successful
+	if _, err := os.Stat(gitDir); os.IsNotExist(err) {
+		return fmt.Errorf("clone verification failed: .git directory not found")
+	}
+
+	if sparsePath != "" {
+		if err := sparseCheckout(targetDir, sparsePath); err != nil {
+			return err
+		}
+	}
+
+	if keyDir != "" {
+		commitOutput, err := exec.Command("git", "-C", targetDir, "rev-parse", "HEAD").Output() //nolint:gosec // targetDir is constructed from controlled inputs
+		if err == nil {
+			if err := populateCache(targetDir, keyDir, strings.TrimSpace(string(commitOutput))); err != nil {
+				fmt.Printf("git-init: Warning: failed to populate context cache: %v\n", err)
+			} else {
+				fmt.Println("git-init: Stored clone in context cache")
+			}
+		}
+	}
+
+	return nil
+}
+
+// buildCloneArgs builds the git clone arguments.
+// When sparse is true, the clone is blobless and skips checkout so that
+// sparseCheckout can materialize only the requested path.
+func buildCloneArgs(repo, targetDir, ref string, depth int, recurseSubmodules, sparse bool) []string {
+	cloneArgs := []string{"clone", "--depth", strconv.Itoa(depth), "--single-branch"}
+
+	if recurseSubmodules {
+		cloneArgs = append(cloneArgs, "--recurse-submodules")
+	}
+
+	if sparse {
+		cloneArgs = append(cloneArgs, "--filter=blob:none", "--no-checkout")
+	}
+
+	// Add branch flag if not HEAD
+	if ref != "HEAD" {
+		cloneArgs = append(cloneArgs, "--branch", ref)
+	}
+
+	return append(cloneArgs, repo, targetDir)
+}
+
+// sparseCheckoutPattern converts a repository subpath (file or directory) into
+// a non-cone sparse-checkout pattern anchored at the repository root.
+// Returns "" if the subpath selects the whole repository.
+func sparseCheckoutPattern(subpath string) string {
+	subpath = strings.Trim(filepath.ToSlash(filepath.Clean("/"+subpath)), "/")
+	if subpath == "" {
+		return ""
+	}
+	return "/" + subpath
+}
+
+// sparseCheckout restricts the working tree of a --no-checkout clone to pattern
+// and checks out the cloned ref.
+func sparseCheckout(targetDir, pattern string) error {
+	for _, args := range [][]string{
+		{"-C", targetDir, "sparse-checkout", "set", "--no-cone", pattern},
+		{"-C", targetDir, "checkout"},
+	} {
+		cmd := exec.Command("git", args...) //nolint:gosec // args are constructed from controlled inputs
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("git %s failed: %w", args[2], err)
+		}
+	}
+	return nil
+}
+
 func cleanupCredentials() {
 	username := os.Getenv(envUsername)
 	password := os.Getenv(envPassword)
@@ -0,0 +1,109 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestBuildCloneArgs(t *testing.T) {
+	tests := []struct {
+		name              string
+		ref               string
+		recurseSubmodules bool
+		sparse            bool
+		want              []string
+	}{
+		{
+			name: "default HEAD",
+			ref:  "HEAD",
+			want: []string{"clone", "--depth", "1", "--single-branch", "repo", "/git/repo"},
+		},
+		{
+			name:              "branch with submodules",
+			ref:               "main",
+			recurseSubmodules: true,
+			want:              []string{"clone", "--depth", "1", "--single-branch", "--recurse-submodules", "--branch", "main", "repo", "/git/repo"},
+		},
+		{
+			name:   "sparse",
+			ref:    "main",
+			sparse: true,
+			want:   []string{"clone", "--depth", "1", "--single-branch", "--filter=blob:none", "--no-checkout", "--branch", "main", "repo", "/git/repo"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := buildCloneArgs("repo", "/git/repo", tt.ref, 1, tt.recurseSubmodules, tt.sparse)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("buildCloneArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSparseCheckoutPattern(t *testing.T) {
+	tests := map[string]string{
+		"":                   "",
+		"/":                  "",
+		".":                  "",
+		"docs/architecture/": "/docs/architecture",
+		"/docs/architecture": "/docs/architecture",
+		"docs/guide.md":      "/docs/guide.md",
+		"docs/../skills/":    "/skills",
+		"../outside":         "/outside",
+	}
+	for in, want := range tests {
+		if got := sparseCheckoutPattern(in); got != want {
+			t.Errorf("sparseCheckoutPattern(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSparseCheckout(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	// Build a small source repository with two top-level directories
+	src := t.TempDir()
+	for _, f := range []string{"docs/architecture/overview.md", "src/main.go"} {
+		path := filepath.Join(src, f)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("content"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git := func(dir string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_CONFIG_GLOBAL=/dev/null", "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@t", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@t")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git(src, "init", "-q")
+	git(src, "add", ".")
+	git(src, "commit", "-q", "-m", "init")
+
+	target := filepath.Join(t.TempDir(), "repo")
+	git(src, "clone", "-q", "--no-checkout", "file://"+src, target)
+
+	if err := sparseCheckout(target, "/docs/architecture"); err != nil {
+		t.Fatalf("sparseCheckout() error = %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(target, "docs/architecture/overview.md")); err != nil {
+		t.Errorf("expected sparse path to be checked out: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(target, "src")); !os.IsNotExist(err) {
+		t.Errorf("expected src/ to be excluded from sparse checkout, stat err = %v", err)
+	}
+}
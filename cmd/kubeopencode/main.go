@@ -7,7 +7,9 @@
 //   - controller:    Start the Kubernetes controller
 //   - git-init:      Clone Git repositories for Git Context
 //   - context-init:  Copy ConfigMap content to workspace
-//   - url-fetch:     Fetch content from remote URLs for URL Context
+//   - url-fetch:     Fetch content from remote URLs for URL and Archive Context
+//   - resource-snapshot: Write live cluster resources to the workspace for Runtime Context
+//   - vault-fetch:   Write secrets from Vault KV paths to the workspace for Vault Context
 package main
 
 import (
@@ -48,7 +50,9 @@ This unified binary provides:
   git-init       Clone Git repositories for Git Context
   git-sync       Periodically sync a Git repository (sidecar mode)
   context-init   Copy ConfigMap content to workspace
-  url-fetch      Fetch content from remote URLs for URL Context
+  url-fetch      Fetch content from remote URLs for URL and Archive Context
+  resource-snapshot  Write live cluster resources to the workspace for Runtime Context
+  vault-fetch    Write secrets from Vault KV paths to the workspace for Vault Context
 
 Examples:
   # Start the controller
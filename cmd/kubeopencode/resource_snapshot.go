@@ -0,0 +1,162 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"sigs.k8s.io/yaml"
+)
+
+// envResourceSnapshots is the JSON array of resources to snapshot
+const envResourceSnapshots = "RESOURCE_SNAPSHOTS"
+
+// resourceSnapshotTimeout bounds the total time spent collecting snapshots
+const resourceSnapshotTimeout = 60 * time.Second
+
+// ResourceSnapshot describes a set of cluster resources to write to a file
+type ResourceSnapshot struct {
+	TargetPath    string `json:"targetPath"`
+	APIVersion    string `json:"apiVersion"`
+	Kind          string `json:"kind"`
+	Namespace     string `json:"namespace"`
+	ResourceName  string `json:"resourceName,omitempty"`
+	LabelSelector string `json:"labelSelector,omitempty"`
+	FieldSelector string `json:"fieldSelector,omitempty"`
+}
+
+func init() {
+	rootCmd.AddCommand(resourceSnapshotCmd)
+}
+
+var resourceSnapshotCmd = &cobra.Command{
+	Use:   "resource-snapshot",
+	Short: "Write live cluster resources to the workspace for Runtime context",
+	Long: `resource-snapshot reads cluster resources with the Pod's ServiceAccount and
+writes them as YAML files into the workspace.
+
+A resource that cannot be read (e.g., missing RBAC permissions) does not fail
+the Task: the error is written into its file so the agent can see why.
+
+Environment variables:
+  RESOURCE_SNAPSHOTS  JSON array of resources to snapshot:
+                      [{"targetPath":"/workspace/.kubeopencode/cluster-state/web.yaml",
+                        "apiVersion":"apps/v1","kind":"Deployment","namespace":"prod",
+                        "labelSelector":"app=web"}]`,
+	RunE: runResourceSnapshot,
+}
+
+func runResourceSnapshot(cmd *cobra.Command, args []string) error {
+	var snapshots []ResourceSnapshot
+	if err := json.Unmarshal([]byte(os.Getenv(envResourceSnapshots)), &snapshots); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", envResourceSnapshots, err)
+	}
+
+	fmt.Printf("resource-snapshot: Collecting %d resource snapshot(s)...\n", len(snapshots))
+
+	cfg, err := rest.InClusterConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load in-cluster config: %w", err)
+	}
+	dc, err := discovery.NewDiscoveryClientForConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create discovery client: %w", err)
+	}
+	client, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(dc))
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), resourceSnapshotTimeout)
+	defer cancel()
+
+	for _, s := range snapshots {
+		content, err := collectResourceSnapshot(ctx, client, mapper, s)
+		if err != nil {
+			fmt.Printf("resource-snapshot: Warning: %s %s: %v\n", s.Kind, s.TargetPath, err)
+			content = []byte(fmt.Sprintf("# resource snapshot failed: %v\n", err))
+		}
+		if err := writeResourceSnapshot(s.TargetPath, content); err != nil {
+			return err
+		}
+		fmt.Printf("resource-snapshot: Wrote %s\n", s.TargetPath)
+	}
+
+	fmt.Println("resource-snapshot: Done!")
+	return nil
+}
+
+// collectResourceSnapshot reads the resources selected by s and renders them as YAML.
+func collectResourceSnapshot(ctx context.Context, client dynamic.Interface, mapper meta.RESTMapper, s ResourceSnapshot) ([]byte, error) {
+	gv, err := schema.ParseGroupVersion(s.APIVersion)
+	if err != nil {
+		return nil, fmt.Errorf("invalid apiVersion %q: %w", s.APIVersion, err)
+	}
+	mapping, err := mapper.RESTMapping(gv.WithKind(s.Kind).GroupKind(), gv.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	var resource dynamic.ResourceInterface = client.Resource(mapping.Resource)
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		resource = client.Resource(mapping.Resource).Namespace(s.Namespace)
+	}
+
+	if s.ResourceName != "" {
+		obj, err := resource.Get(ctx, s.ResourceName, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return marshalResourceSnapshot(obj)
+	}
+
+	list, err := resource.List(ctx, metav1.ListOptions{
+		LabelSelector: s.LabelSelector,
+		FieldSelector: s.FieldSelector,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range list.Items {
+		stripManagedFields(&list.Items[i])
+	}
+	return yaml.Marshal(list.UnstructuredContent())
+}
+
+// marshalResourceSnapshot renders a single object as YAML without managed fields.
+func marshalResourceSnapshot(obj *unstructured.Unstructured) ([]byte, error) {
+	stripManagedFields(obj)
+	return yaml.Marshal(obj.Object)
+}
+
+// stripManagedFields removes server-side apply bookkeeping, which is noise for agents.
+func stripManagedFields(obj *unstructured.Unstructured) {
+	obj.SetManagedFields(nil)
+}
+
+// writeResourceSnapshot writes content to path, creating parent directories as needed.
+func writeResourceSnapshot(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil { //nolint:gosec // Needs group/others access for random UID environments
+		return fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil { //nolint:gosec // Workspace files must be readable by the agent container
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
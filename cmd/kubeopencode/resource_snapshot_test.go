@@ -0,0 +1,79 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func newTestDeployment(name, namespace, app string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("apps/v1")
+	obj.SetKind("Deployment")
+	obj.SetName(name)
+	obj.SetNamespace(namespace)
+	obj.SetLabels(map[string]string{"app": app})
+	obj.SetManagedFields([]metav1.ManagedFieldsEntry{{Manager: "kubectl"}})
+	return obj
+}
+
+func TestCollectResourceSnapshot(t *testing.T) {
+	deployments := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{deployments: "DeploymentList"},
+		newTestDeployment("web", "prod", "web"),
+		newTestDeployment("api", "prod", "api"),
+		newTestDeployment("web", "staging", "web"),
+	)
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, meta.RESTScopeNamespace)
+
+	ctx := context.Background()
+
+	t.Run("single object by name", func(t *testing.T) {
+		content, err := collectResourceSnapshot(ctx, client, mapper, ResourceSnapshot{
+			APIVersion: "apps/v1", Kind: "Deployment", Namespace: "prod", ResourceName: "api",
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.Contains(string(content), "name: api") {
+			t.Errorf("expected api Deployment, got:\n%s", content)
+		}
+		if strings.Contains(string(content), "managedFields") {
+			t.Errorf("managedFields should be stripped, got:\n%s", content)
+		}
+	})
+
+	t.Run("list with label selector is namespace scoped", func(t *testing.T) {
+		content, err := collectResourceSnapshot(ctx, client, mapper, ResourceSnapshot{
+			APIVersion: "apps/v1", Kind: "Deployment", Namespace: "prod", LabelSelector: "app=web",
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got := string(content)
+		if strings.Count(got, "name: web") != 1 || strings.Contains(got, "name: api") || strings.Contains(got, "staging") {
+			t.Errorf("expected only prod/web, got:\n%s", got)
+		}
+	})
+
+	t.Run("unknown kind", func(t *testing.T) {
+		_, err := collectResourceSnapshot(ctx, client, mapper, ResourceSnapshot{
+			APIVersion: "apps/v1", Kind: "Unknown", Namespace: "prod",
+		})
+		if err == nil {
+			t.Error("expected error for unknown kind")
+		}
+	})
+}
@@ -14,6 +14,8 @@ import (
 
 	"github.com/kubeopencode/kubeopencode/internal/server"
 	"github.com/kubeopencode/kubeopencode/internal/server/handlers"
+	authmiddleware "github.com/kubeopencode/kubeopencode/internal/server/middleware"
+	"github.com/kubeopencode/kubeopencode/internal/tracing"
 )
 
 func init() {
@@ -43,6 +45,13 @@ var (
 	serverAuthAllowAnon  bool
 	serverCORSAllowedOri []string
 	serverAPIRateLimit   int
+	serverOIDC           authmiddleware.OIDCConfig
+	serverPreferencesNS  string
+	serverTLSCertFile    string
+	serverTLSKeyFile     string
+	serverAllowedNS      []string
+	serverDeniedNS       []string
+	serverNSSelector     string
 )
 
 func init() {
@@ -58,6 +67,32 @@ func init() {
 		"Comma-separated list of allowed CORS origins (e.g., 'http://localhost:3000,https://dashboard.example.com')")
 	serverCmd.Flags().IntVar(&serverAPIRateLimit, "api-rate-limit", 0,
 		"Maximum number of concurrent API requests (0 = unlimited)")
+	serverCmd.Flags().StringVar(&serverOIDC.IssuerURL, "oidc-issuer-url", "",
+		"OpenID Connect issuer URL (https). Enables OIDC ID token authentication when set.")
+	serverCmd.Flags().StringVar(&serverOIDC.ClientID, "oidc-client-id", "",
+		"OpenID Connect client ID")
+	serverCmd.Flags().StringVar(&serverOIDC.Audience, "oidc-audience", "",
+		"Accepted audience (aud claim) of ID tokens (defaults to --oidc-client-id)")
+	serverCmd.Flags().StringVar(&serverOIDC.UsernameClaim, "oidc-username-claim", "sub",
+		"ID token claim used as the Kubernetes username")
+	serverCmd.Flags().StringVar(&serverOIDC.UsernamePrefix, "oidc-username-prefix", "",
+		"Prefix prepended to OIDC usernames (e.g., 'oidc:'). Defaults to '<issuer-url>#' unless the username claim is 'email'; '-' disables prefixing.")
+	serverCmd.Flags().StringVar(&serverOIDC.GroupsClaim, "oidc-groups-claim", "",
+		"ID token claim holding the user's groups")
+	serverCmd.Flags().StringVar(&serverOIDC.GroupsPrefix, "oidc-groups-prefix", "",
+		"Prefix prepended to OIDC group names (e.g., 'oidc:')")
+	serverCmd.Flags().StringVar(&serverTLSCertFile, "tls-cert-file", "",
+		"PEM certificate file to serve HTTPS. The file is reloaded when it changes.")
+	serverCmd.Flags().StringVar(&serverTLSKeyFile, "tls-key-file", "",
+		"PEM private key file for --tls-cert-file")
+	serverCmd.Flags().StringVar(&serverPreferencesNS, "preferences-namespace", "",
+		"Namespace of the ConfigMap storing per-user UI preferences (empty disables the preferences API)")
+	serverCmd.Flags().StringSliceVar(&serverAllowedNS, "allowed-namespaces", nil,
+		"Comma-separated list of namespaces served by the API (empty = all namespaces)")
+	serverCmd.Flags().StringSliceVar(&serverDeniedNS, "denied-namespaces", nil,
+		"Comma-separated list of namespaces never served by the API")
+	serverCmd.Flags().StringVar(&serverNSSelector, "namespace-selector", "",
+		"Label selector of the namespaces served by the API (e.g., 'tenant=team-a')")
 }
 
 func runServer(cmd *cobra.Command, args []string) error {
@@ -72,14 +107,33 @@ func runServer(cmd *cobra.Command, args []string) error {
 
 	log.Info("Starting KubeOpenCode server", "address", serverAddress)
 
+	// Tracing is configured by the standard OTEL_EXPORTER_OTLP_* environment variables
+	shutdownTracing, err := tracing.Setup(context.Background(), "kubeopencode-server")
+	if err != nil {
+		log.Error(err, "Failed to set up tracing")
+		return err
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			log.Error(err, "Failed to flush traces")
+		}
+	}()
+
 	// Create server options
 	serverOpts := server.Options{
-		Address:            serverAddress,
-		BaseURL:            serverBaseURL,
-		AuthEnabled:        serverAuthEnabled,
-		AuthAllowAnonymous: serverAuthAllowAnon,
-		CORSAllowedOrigins: serverCORSAllowedOri,
-		APIRateLimit:       serverAPIRateLimit,
+		Address:              serverAddress,
+		BaseURL:              serverBaseURL,
+		AuthEnabled:          serverAuthEnabled,
+		AuthAllowAnonymous:   serverAuthAllowAnon,
+		CORSAllowedOrigins:   serverCORSAllowedOri,
+		APIRateLimit:         serverAPIRateLimit,
+		OIDC:                 serverOIDC,
+		PreferencesNamespace: serverPreferencesNS,
+		TLSCertFile:          serverTLSCertFile,
+		TLSKeyFile:           serverTLSKeyFile,
+		AllowedNamespaces:    serverAllowedNS,
+		DeniedNamespaces:     serverDeniedNS,
+		NamespaceSelector:    serverNSSelector,
 	}
 
 	// Create the server
@@ -3,8 +3,10 @@
 package main
 
 import (
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -12,6 +14,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -24,6 +27,11 @@ const (
 	envURLHeaders  = "URL_HEADERS"
 	envURLTimeout  = "URL_TIMEOUT"
 	envURLInsecure = "URL_INSECURE"
+	envURLSHA256   = "URL_SHA256"
+	// Archive extraction (Archive contexts)
+	envURLExtract         = "URL_EXTRACT"
+	envURLStripComponents = "URL_STRIP_COMPONENTS"
+	envURLMaxExtracted    = "URL_MAX_EXTRACTED_BYTES"
 	// Auth credentials from Secret (mounted as env vars)
 	envURLToken    = "URL_AUTH_TOKEN"    //nolint:gosec // This is an env var name, not a credential
 	envURLUsername = "URL_AUTH_USERNAME" //nolint:gosec // This is an env var name, not a credential
@@ -43,15 +51,20 @@ var urlFetchCmd = &cobra.Command{
 	Use:   "url-fetch",
 	Short: "Fetch content from a URL and write to a file",
 	Long: `url-fetch fetches content from a remote HTTP/HTTPS URL and writes it to a target file.
+If URL_EXTRACT is set, the content is an archive that is extracted into the target directory.
 
-This command is used as an init container to fetch URL contexts for Tasks.
+This command is used as an init container to fetch URL and Archive contexts for Tasks.
 
 Environment variables:
   URL_SOURCE        The URL to fetch content from (required)
-  URL_TARGET        Target file path to write content to (required)
+  URL_TARGET        Target file path to write content to, or the directory to extract into (required)
   URL_HEADERS       JSON object of HTTP headers to include, e.g., {"X-Custom": "value"}
   URL_TIMEOUT       Request timeout in seconds (default: 30)
   URL_INSECURE      Set to "true" to skip TLS certificate verification
+  URL_SHA256        Expected hex-encoded SHA-256 of the content; on mismatch nothing is written
+  URL_EXTRACT       Archive format to extract: TarGz, Tar, Zip, or Auto (detect from content)
+  URL_STRIP_COMPONENTS Number of leading path components to strip from archive entries
+  URL_MAX_EXTRACTED_BYTES Maximum total size of extracted archive entries (default: 1 GiB)
   URL_AUTH_TOKEN    Bearer token for Authorization header (from Secret)
   URL_AUTH_USERNAME Username for HTTP Basic auth (from Secret)
   URL_AUTH_PASSWORD Password for HTTP Basic auth (from Secret)
@@ -77,6 +90,8 @@ func runURLFetch(cmd *cobra.Command, args []string) error {
 	headersJSON := os.Getenv(envURLHeaders)
 	timeoutStr := os.Getenv(envURLTimeout)
 	insecureStr := os.Getenv(envURLInsecure)
+	expectedSHA256 := strings.ToLower(os.Getenv(envURLSHA256))
+	extractFormat := os.Getenv(envURLExtract)
 
 	// Auth credentials (from mounted Secret)
 	authToken := os.Getenv(envURLToken)
@@ -106,6 +121,29 @@ func runURLFetch(cmd *cobra.Command, args []string) error {
 	}
 	fmt.Printf("  Timeout: %ds\n", timeout)
 
+	// Parse strip components
+	stripComponents := 0
+	if s := os.Getenv(envURLStripComponents); s != "" {
+		parsed, err := strconv.Atoi(s)
+		if err != nil || parsed < 0 {
+			return fmt.Errorf("invalid URL_STRIP_COMPONENTS value: %q", s)
+		}
+		stripComponents = parsed
+	}
+
+	// Parse extracted size limit
+	maxExtracted := int64(defaultMaxExtractedBytes)
+	if s := os.Getenv(envURLMaxExtracted); s != "" {
+		parsed, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || parsed <= 0 {
+			return fmt.Errorf("invalid URL_MAX_EXTRACTED_BYTES value: %q", s)
+		}
+		maxExtracted = parsed
+	}
+	if extractFormat != "" {
+		fmt.Printf("  Extract: %s (strip components: %d)\n", extractFormat, stripComponents)
+	}
+
 	// Parse insecure flag
 	insecure := insecureStr == "true" || insecureStr == "1"
 	if insecure {
@@ -195,18 +233,50 @@ func runURLFetch(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Write content to target file
-	file, err := os.Create(target) //nolint:gosec // target is a controlled path from Task spec
+	// Write content to a temporary file first, so that content failing verification
+	// never reaches the target path
+	file, err := os.CreateTemp(targetDir, ".url-fetch-*")
 	if err != nil {
 		return fmt.Errorf("failed to create target file: %w", err)
 	}
-	defer func() { _ = file.Close() }()
+	tmpPath := file.Name()
+	defer func() { _ = os.Remove(tmpPath) }()
 
-	written, err := io.Copy(file, resp.Body)
+	hash := sha256.New()
+	written, err := io.Copy(io.MultiWriter(file, hash), resp.Body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
 	if err != nil {
 		return fmt.Errorf("failed to write content: %w", err)
 	}
 
+	if expectedSHA256 != "" {
+		actual := hex.EncodeToString(hash.Sum(nil))
+		if actual != expectedSHA256 {
+			return fmt.Errorf("checksum mismatch for %s: expected sha256 %s, got %s", source, expectedSHA256, actual)
+		}
+		fmt.Printf("url-fetch: Verified sha256 %s\n", actual)
+	}
+
+	if extractFormat != "" {
+		files, err := extractArchive(tmpPath, extractFormat, target, stripComponents, maxExtracted)
+		if err != nil {
+			return fmt.Errorf("failed to extract archive from %s: %w", source, err)
+		}
+		fmt.Printf("url-fetch: Extracted %d files (%d bytes downloaded) to %s\n", files, written, target)
+		fmt.Println("url-fetch: Done!")
+		return nil
+	}
+
+	// CreateTemp uses 0600; fetched content should be readable like other context files
+	if err := os.Chmod(tmpPath, 0644); err != nil { //nolint:gosec // Context files are readable by the agent container
+		return fmt.Errorf("failed to set permissions: %w", err)
+	}
+	if err := os.Rename(tmpPath, target); err != nil {
+		return fmt.Errorf("failed to write target file: %w", err)
+	}
+
 	fmt.Printf("url-fetch: Written %d bytes to %s\n", written, target)
 	fmt.Println("url-fetch: Done!")
 	return nil
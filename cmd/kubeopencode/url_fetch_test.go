@@ -0,0 +1,65 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunURLFetch_SHA256(t *testing.T) {
+	const body = "openapi: 3.0.0\n"
+	sum := sha256.Sum256([]byte(body))
+	digest := hex.EncodeToString(sum[:])
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		sha256  string
+		wantErr bool
+	}{
+		{name: "no checksum", sha256: ""},
+		{name: "matching checksum", sha256: digest},
+		{name: "matching checksum in upper case", sha256: strings.ToUpper(digest)},
+		{name: "mismatching checksum", sha256: strings.Repeat("0", 64), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := filepath.Join(t.TempDir(), "specs", "openapi.yaml")
+			t.Setenv(envURLSource, server.URL)
+			t.Setenv(envURLTarget, target)
+			t.Setenv(envURLSHA256, tt.sha256)
+
+			err := runURLFetch(nil, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("runURLFetch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			content, readErr := os.ReadFile(target)
+			if tt.wantErr {
+				if !os.IsNotExist(readErr) {
+					t.Errorf("target should not exist after checksum mismatch, got %q", content)
+				}
+				entries, _ := os.ReadDir(filepath.Dir(target))
+				if len(entries) != 0 {
+					t.Errorf("temporary files left behind: %v", entries)
+				}
+				return
+			}
+			if string(content) != body {
+				t.Errorf("content = %q, want %q", content, body)
+			}
+		})
+	}
+}
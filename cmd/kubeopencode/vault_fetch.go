@@ -0,0 +1,250 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Environment variable names for vault-fetch
+const (
+	envVaultContexts = "VAULT_CONTEXTS"
+	// envVaultTokenPath overrides the ServiceAccount token path (mainly for testing)
+	envVaultTokenPath = "VAULT_SA_TOKEN_PATH" //nolint:gosec // This is an env var name, not a credential
+)
+
+// Default values for vault-fetch
+const (
+	defaultVaultTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token" //nolint:gosec // This is a file path, not a credential
+	vaultRequestTimeout   = 30 * time.Second
+)
+
+// VaultSecret describes a Vault KV secret to write into the workspace
+type VaultSecret struct {
+	Name       string           `json:"name"`
+	TargetDir  string           `json:"targetDir"`
+	Address    string           `json:"address"`
+	Role       string           `json:"role"`
+	AuthMount  string           `json:"authMount"`
+	Namespace  string           `json:"namespace,omitempty"`
+	SecretPath string           `json:"secretPath"`
+	KVVersion  int32            `json:"kvVersion"`
+	FileMode   int32            `json:"fileMode"`
+	Keys       []VaultKeyToFile `json:"keys,omitempty"`
+}
+
+// VaultKeyToFile maps a secret key to a file relative to the target directory
+type VaultKeyToFile struct {
+	Key  string `json:"key"`
+	Path string `json:"path"`
+	Mode *int32 `json:"mode,omitempty"`
+}
+
+func init() {
+	rootCmd.AddCommand(vaultFetchCmd)
+}
+
+var vaultFetchCmd = &cobra.Command{
+	Use:   "vault-fetch",
+	Short: "Write secrets from Vault KV paths to the workspace for Vault Context",
+	Long: `vault-fetch logs in to HashiCorp Vault with the Pod's ServiceAccount token
+(Kubernetes auth method), reads KV secrets, and writes the selected keys as files.
+
+Secret values are never logged.
+
+Environment variables:
+  VAULT_CONTEXTS      JSON array of secrets to fetch:
+                      [{"name":"ci","targetDir":"/workspace/.secrets","address":"https://vault:8200",
+                        "role":"ci","authMount":"kubernetes","secretPath":"secret/data/ci",
+                        "kvVersion":2,"fileMode":384,"keys":[{"key":"token","path":"token"}]}]
+  VAULT_SA_TOKEN_PATH ServiceAccount token path, default: ` + defaultVaultTokenPath,
+	RunE: runVaultFetch,
+}
+
+func runVaultFetch(cmd *cobra.Command, args []string) error {
+	var secrets []VaultSecret
+	if err := json.Unmarshal([]byte(os.Getenv(envVaultContexts)), &secrets); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", envVaultContexts, err)
+	}
+
+	jwt, err := os.ReadFile(getEnvOrDefault(envVaultTokenPath, defaultVaultTokenPath))
+	if err != nil {
+		return fmt.Errorf("failed to read ServiceAccount token: %w", err)
+	}
+
+	client, err := newVaultHTTPClient()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("vault-fetch: Fetching %d Vault secret(s)...\n", len(secrets))
+	for _, s := range secrets {
+		fmt.Printf("vault-fetch: %s: reading %s from %s (role: %s)\n", s.Name, s.SecretPath, s.Address, s.Role)
+		if err := fetchVaultSecret(client, s, string(bytes.TrimSpace(jwt))); err != nil {
+			return fmt.Errorf("vault context %s: %w", s.Name, err)
+		}
+	}
+	fmt.Println("vault-fetch: Done!")
+	return nil
+}
+
+// newVaultHTTPClient creates an HTTP client that trusts the custom CA bundle if configured.
+func newVaultHTTPClient() (*http.Client, error) {
+	transport := &http.Transport{Proxy: http.ProxyFromEnvironment}
+	if caPath := os.Getenv("CUSTOM_CA_CERT_PATH"); caPath != "" {
+		rootCAs, err := x509.SystemCertPool()
+		if err != nil {
+			rootCAs = x509.NewCertPool()
+		}
+		caCert, err := os.ReadFile(caPath) //nolint:gosec // path is from trusted env var set by controller
+		if err != nil {
+			return nil, fmt.Errorf("reading custom CA certificate: %w", err)
+		}
+		if !rootCAs.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("failed to parse custom CA certificate from %s", caPath)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: rootCAs} //nolint:gosec // Only RootCAs is set, no insecure options
+	}
+	return &http.Client{Timeout: vaultRequestTimeout, Transport: transport}, nil
+}
+
+// fetchVaultSecret logs in to Vault, reads the secret, and writes the selected keys.
+func fetchVaultSecret(client *http.Client, s VaultSecret, jwt string) error {
+	var login struct {
+		Auth struct {
+			ClientToken string `json:"client_token"`
+		} `json:"auth"`
+	}
+	loginBody, _ := json.Marshal(map[string]string{"role": s.Role, "jwt": jwt})
+	if err := vaultRequest(client, http.MethodPost, s.Address+"/v1/auth/"+s.AuthMount+"/login", s.Namespace, "", loginBody, &login); err != nil {
+		return fmt.Errorf("login failed: %w", err)
+	}
+	if login.Auth.ClientToken == "" {
+		return fmt.Errorf("login failed: no client token returned")
+	}
+
+	var secret struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := vaultRequest(client, http.MethodGet, s.Address+"/v1/"+s.SecretPath, s.Namespace, login.Auth.ClientToken, nil, &secret); err != nil {
+		return fmt.Errorf("read failed: %w", err)
+	}
+	data, err := vaultSecretData(secret.Data, s.KVVersion)
+	if err != nil {
+		return err
+	}
+	return writeVaultSecretFiles(s, data)
+}
+
+// vaultRequest performs a Vault API request and decodes the JSON response into out.
+// Error responses are reported without their body to avoid leaking request details.
+func vaultRequest(client *http.Client, method, url, namespace, token string, body []byte, out any) error {
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if namespace != "" {
+		req.Header.Set("X-Vault-Namespace", namespace)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("invalid response: %w", err)
+	}
+	return nil
+}
+
+// vaultSecretData extracts the key/value pairs from a KV read response's "data" field.
+// KV v2 nests the values in data.data; KV v1 returns them in data directly.
+// Non-string values are written as JSON.
+func vaultSecretData(raw json.RawMessage, kvVersion int32) (map[string]string, error) {
+	if kvVersion != 1 {
+		var v2 struct {
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(raw, &v2); err != nil {
+			return nil, fmt.Errorf("invalid KV v2 response: %w", err)
+		}
+		raw = v2.Data
+	}
+
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &values); err != nil || values == nil {
+		return nil, fmt.Errorf("secret has no data")
+	}
+	data := make(map[string]string, len(values))
+	for k, v := range values {
+		var str string
+		if err := json.Unmarshal(v, &str); err == nil {
+			data[k] = str
+		} else {
+			data[k] = string(v)
+		}
+	}
+	return data, nil
+}
+
+// writeVaultSecretFiles writes the selected secret keys into the target directory.
+// If no keys are selected, every key is written to a file named after the key.
+func writeVaultSecretFiles(s VaultSecret, data map[string]string) error {
+	keys := s.Keys
+	if len(keys) == 0 {
+		names := make([]string, 0, len(data))
+		for k := range data {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		for _, k := range names {
+			keys = append(keys, VaultKeyToFile{Key: k, Path: k})
+		}
+	}
+
+	for _, k := range keys {
+		value, ok := data[k.Key]
+		if !ok {
+			return fmt.Errorf("key %s not found in secret", k.Key)
+		}
+		target := filepath.Join(s.TargetDir, k.Path)
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil { //nolint:gosec // Needs group/others access for random UID environments
+			return fmt.Errorf("failed to create directory for %s: %w", k.Path, err)
+		}
+		mode := s.FileMode
+		if k.Mode != nil {
+			mode = *k.Mode
+		}
+		if err := os.WriteFile(target, []byte(value), os.FileMode(uint32(mode))); err != nil { //nolint:gosec // mode is validated by Kubernetes API
+			return fmt.Errorf("failed to write %s: %w", k.Path, err)
+		}
+		// WriteFile does not change the mode of an existing file
+		if err := os.Chmod(target, os.FileMode(uint32(mode))); err != nil { //nolint:gosec // mode is validated by Kubernetes API
+			return fmt.Errorf("failed to set mode on %s: %w", k.Path, err)
+		}
+		fmt.Printf("vault-fetch: Wrote key %s -> %s (mode: %04o)\n", k.Key, target, mode)
+	}
+	return nil
+}
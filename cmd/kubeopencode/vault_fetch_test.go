@@ -0,0 +1,106 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newFakeVault serves Kubernetes auth login and a KV v2 secret.
+func newFakeVault(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/auth/kubernetes/login", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["role"] != "ci" || body["jwt"] != "sa-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`{"auth":{"client_token":"vault-token"}}`))
+	})
+	mux.HandleFunc("/v1/secret/data/team/ci", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "vault-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"data":{"api-token":"s3cr3t","port":8443},"metadata":{"version":3}}}`))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestFetchVaultSecret(t *testing.T) {
+	srv := newFakeVault(t)
+	mode := int32(0400)
+
+	t.Run("selected keys", func(t *testing.T) {
+		dir := t.TempDir()
+		s := VaultSecret{
+			Name: "ci", TargetDir: dir, Address: srv.URL, Role: "ci", AuthMount: "kubernetes",
+			SecretPath: "secret/data/team/ci", KVVersion: 2, FileMode: 0600,
+			Keys: []VaultKeyToFile{{Key: "api-token", Path: "tokens/api", Mode: &mode}},
+		}
+		if err := fetchVaultSecret(srv.Client(), s, "sa-token"); err != nil {
+			t.Fatalf("fetchVaultSecret() error = %v", err)
+		}
+		target := filepath.Join(dir, "tokens/api")
+		content, err := os.ReadFile(target)
+		if err != nil || string(content) != "s3cr3t" {
+			t.Errorf("content = %q, %v; want s3cr3t", content, err)
+		}
+		if info, _ := os.Stat(target); info.Mode().Perm() != 0400 {
+			t.Errorf("mode = %o, want 0400", info.Mode().Perm())
+		}
+		if _, err := os.Stat(filepath.Join(dir, "port")); !os.IsNotExist(err) {
+			t.Error("unselected key should not be written")
+		}
+	})
+
+	t.Run("all keys", func(t *testing.T) {
+		dir := t.TempDir()
+		s := VaultSecret{
+			Name: "ci", TargetDir: dir, Address: srv.URL, Role: "ci", AuthMount: "kubernetes",
+			SecretPath: "secret/data/team/ci", KVVersion: 2, FileMode: 0600,
+		}
+		if err := fetchVaultSecret(srv.Client(), s, "sa-token"); err != nil {
+			t.Fatalf("fetchVaultSecret() error = %v", err)
+		}
+		if content, _ := os.ReadFile(filepath.Join(dir, "port")); string(content) != "8443" {
+			t.Errorf("port = %q, want 8443", content)
+		}
+		if info, _ := os.Stat(filepath.Join(dir, "api-token")); info == nil || info.Mode().Perm() != 0600 {
+			t.Errorf("api-token should be written with mode 0600")
+		}
+	})
+
+	t.Run("login denied", func(t *testing.T) {
+		s := VaultSecret{Name: "ci", TargetDir: t.TempDir(), Address: srv.URL, Role: "other", AuthMount: "kubernetes", SecretPath: "secret/data/team/ci", KVVersion: 2}
+		if err := fetchVaultSecret(srv.Client(), s, "sa-token"); err == nil {
+			t.Error("expected login error")
+		}
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		s := VaultSecret{
+			Name: "ci", TargetDir: t.TempDir(), Address: srv.URL, Role: "ci", AuthMount: "kubernetes",
+			SecretPath: "secret/data/team/ci", KVVersion: 2, Keys: []VaultKeyToFile{{Key: "missing", Path: "missing"}},
+		}
+		if err := fetchVaultSecret(srv.Client(), s, "sa-token"); err == nil {
+			t.Error("expected missing key error")
+		}
+	})
+}
+
+func TestVaultSecretData_KVv1(t *testing.T) {
+	data, err := vaultSecretData(json.RawMessage(`{"password":"p"}`), 1)
+	if err != nil || data["password"] != "p" {
+		t.Errorf("vaultSecretData() = %v, %v", data, err)
+	}
+}
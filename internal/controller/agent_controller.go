@@ -17,6 +17,7 @@ import (
 	"k8s.io/client-go/tools/events"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	crcontroller "sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -76,6 +77,10 @@ type AgentReconciler struct {
 	GitLsRemoteFn GitLsRemoteFunc
 	// CountActiveTasksFn counts active tasks. Defaults to r.countActiveTasks.
 	CountActiveTasksFn CountActiveTasksFunc
+	// Shard limits reconciliation to the namespaces of this replica. Nil reconciles all.
+	Shard *Shard
+	// MaxConcurrentReconciles is the number of Agents reconciled in parallel. Defaults to 1.
+	MaxConcurrentReconciles int
 }
 
 // +kubebuilder:rbac:groups=kubeopencode.io,resources=agents,verbs=get;list;watch;update;patch
@@ -459,7 +464,7 @@ func (r *AgentReconciler) processAgentContexts(ctx context.Context, agent *kubeo
 	}
 
 	// Validate mount path conflicts
-	if err := validateMountPathConflicts(fileMounts, dirMounts, gitMounts); err != nil {
+	if err := validateMountPathConflicts(fileMounts, dirMounts, gitMounts, nil); err != nil {
 		return nil, nil, nil, nil, err
 	}
 
@@ -821,5 +826,7 @@ func (r *AgentReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Owns(&corev1.Secret{}).
 		Watches(&kubeopenv1alpha1.AgentTemplate{}, handler.EnqueueRequestsFromMapFunc(r.findAgentsForTemplate)).
 		Watches(&kubeopenv1alpha1.Task{}, handler.EnqueueRequestsFromMapFunc(r.findAgentForTask)).
+		WithEventFilter(r.Shard.Predicate()).
+		WithOptions(crcontroller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles}).
 		Complete(r)
 }
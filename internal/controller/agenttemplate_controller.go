@@ -23,6 +23,8 @@ import (
 type AgentTemplateReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+	// Shard limits reconciliation to the namespaces of this replica. Nil reconciles all.
+	Shard *Shard
 }
 
 // +kubebuilder:rbac:groups=kubeopencode.io,resources=agenttemplates,verbs=get;list;watch;update;patch
@@ -67,5 +69,6 @@ func (r *AgentTemplateReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 func (r *AgentTemplateReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&kubeopenv1alpha1.AgentTemplate{}).
+		WithEventFilter(r.Shard.Predicate()).
 		Complete(r)
 }
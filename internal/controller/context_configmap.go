@@ -0,0 +1,120 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package controller
+
+import (
+	"context"
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+)
+
+const (
+	// SharedContextConfigMapPrefix is the name prefix of content-addressed context ConfigMaps
+	// shared by Tasks with identical contexts (see KubeOpenCodeConfig shareContextConfigMaps).
+	SharedContextConfigMapPrefix = "kubeopencode-context-"
+
+	// ContextHashLabelKey labels shared context ConfigMaps with their content hash.
+	ContextHashLabelKey = "kubeopencode.io/context-hash"
+
+	// maxSharedContextConfigMapOwners bounds the number of Tasks referencing one shared
+	// ConfigMap, so that its ownerReferences stay small. Further Tasks use their own ConfigMap.
+	maxSharedContextConfigMapOwners = 256
+)
+
+// sharedContextConfigMap returns the content-addressed variant of a Task's context ConfigMap.
+// The Task is a non-controller owner, so the ConfigMap can be owned by every Task using it
+// and is garbage collected once all of them are deleted.
+func sharedContextConfigMap(task *kubeopenv1alpha1.Task, cm *corev1.ConfigMap) *corev1.ConfigMap {
+	hash := hashConfigMapData(cm.Data)
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      SharedContextConfigMapPrefix + hash,
+			Namespace: cm.Namespace,
+			Labels: map[string]string{
+				"app":               "kubeopencode",
+				ContextHashLabelKey: hash,
+			},
+			OwnerReferences: []metav1.OwnerReference{taskOwnerReference(task)},
+		},
+		Data: cm.Data,
+	}
+}
+
+// taskOwnerReference returns a non-controller, non-blocking owner reference to a Task.
+func taskOwnerReference(task *kubeopenv1alpha1.Task) metav1.OwnerReference {
+	gvk := kubeopenv1alpha1.SchemeGroupVersion.WithKind("Task")
+	return metav1.OwnerReference{
+		APIVersion:         gvk.GroupVersion().String(),
+		Kind:               gvk.Kind,
+		Name:               task.Name,
+		UID:                task.UID,
+		BlockOwnerDeletion: boolPtr(false),
+	}
+}
+
+// hasOwner reports whether obj already lists uid as an owner.
+func hasOwner(obj metav1.Object, uid types.UID) bool {
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.UID == uid {
+			return true
+		}
+	}
+	return false
+}
+
+// ensureSharedContextConfigMap creates the shared context ConfigMap for the Task's contexts,
+// or adds the Task as an owner of an existing one, and returns the ConfigMap to mount.
+// It falls back to the Task's own ConfigMap (perTask) when the shared one cannot be used:
+// it is being deleted, it already has too many owners, or its data differs (hash collision).
+func (r *TaskReconciler) ensureSharedContextConfigMap(ctx context.Context, task *kubeopenv1alpha1.Task, perTask *corev1.ConfigMap) (*corev1.ConfigMap, error) {
+	logger := log.FromContext(ctx)
+
+	shared := sharedContextConfigMap(task, perTask)
+	err := r.Create(ctx, shared)
+	if err == nil || !errors.IsAlreadyExists(err) {
+		return shared, err
+	}
+
+	usable := true
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		existing := &corev1.ConfigMap{}
+		if err := r.Get(ctx, types.NamespacedName{Name: shared.Name, Namespace: shared.Namespace}, existing); err != nil {
+			return err
+		}
+		if hasOwner(existing, task.UID) {
+			return nil
+		}
+		if existing.DeletionTimestamp != nil ||
+			len(existing.OwnerReferences) >= maxSharedContextConfigMapOwners ||
+			!reflect.DeepEqual(existing.Data, shared.Data) {
+			usable = false
+			return nil
+		}
+		existing.OwnerReferences = append(existing.OwnerReferences, taskOwnerReference(task))
+		return r.Update(ctx, existing)
+	})
+	if errors.IsNotFound(err) {
+		// Deleted by the garbage collector after its last owner was removed
+		usable, err = false, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if usable {
+		return shared, nil
+	}
+
+	logger.Info("Shared context ConfigMap cannot be used, creating a Task ConfigMap", "configMap", shared.Name)
+	if err := r.Create(ctx, perTask); err != nil && !errors.IsAlreadyExists(err) {
+		return nil, err
+	}
+	return perTask, nil
+}
@@ -0,0 +1,121 @@
+// Copyright Contributors to the KubeOpenCode project
+
+//go:build !integration
+
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+)
+
+func TestEnsureSharedContextConfigMap(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = corev1.AddToScheme(s)
+	_ = kubeopenv1alpha1.AddToScheme(s)
+
+	newTask := func(name string) *kubeopenv1alpha1.Task {
+		return &kubeopenv1alpha1.Task{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID(name + "-uid")}}
+	}
+	perTask := func(task *kubeopenv1alpha1.Task, data map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: task.Name + ContextConfigMapSuffix, Namespace: task.Namespace},
+			Data:       data,
+		}
+	}
+	ctx := context.Background()
+	data := map[string]string{"task.md": "Review the PR"}
+
+	t.Run("tasks with identical contexts share one ConfigMap", func(t *testing.T) {
+		r := &TaskReconciler{Client: fake.NewClientBuilder().WithScheme(s).Build()}
+		first, second := newTask("first"), newTask("second")
+
+		cm1, err := r.ensureSharedContextConfigMap(ctx, first, perTask(first, data))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		cm2, err := r.ensureSharedContextConfigMap(ctx, second, perTask(second, data))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// Reconciling the same Task again must not add a duplicate owner
+		if _, err := r.ensureSharedContextConfigMap(ctx, second, perTask(second, data)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if cm1.Name != cm2.Name || !strings.HasPrefix(cm1.Name, SharedContextConfigMapPrefix) {
+			t.Fatalf("ConfigMap names = %q, %q, want the same shared name", cm1.Name, cm2.Name)
+		}
+		got := &corev1.ConfigMap{}
+		if err := r.Get(ctx, types.NamespacedName{Name: cm1.Name, Namespace: "default"}, got); err != nil {
+			t.Fatalf("shared ConfigMap not found: %v", err)
+		}
+		if len(got.OwnerReferences) != 2 {
+			t.Fatalf("owners = %d, want 2", len(got.OwnerReferences))
+		}
+		for _, ref := range got.OwnerReferences {
+			if ref.Controller != nil && *ref.Controller {
+				t.Errorf("shared ConfigMap owner %s must not be a controller reference", ref.Name)
+			}
+		}
+		if got.Labels[ContextHashLabelKey] != hashConfigMapData(data) {
+			t.Errorf("context hash label = %q", got.Labels[ContextHashLabelKey])
+		}
+	})
+
+	t.Run("different contexts use different ConfigMaps", func(t *testing.T) {
+		r := &TaskReconciler{Client: fake.NewClientBuilder().WithScheme(s).Build()}
+		a, b := newTask("a"), newTask("b")
+		cmA, _ := r.ensureSharedContextConfigMap(ctx, a, perTask(a, data))
+		cmB, _ := r.ensureSharedContextConfigMap(ctx, b, perTask(b, map[string]string{"task.md": "Other"}))
+		if cmA.Name == cmB.Name {
+			t.Errorf("different contexts must not share a ConfigMap")
+		}
+	})
+
+	t.Run("falls back to a Task ConfigMap when the shared one has too many owners", func(t *testing.T) {
+		owners := make([]metav1.OwnerReference, maxSharedContextConfigMapOwners)
+		for i := range owners {
+			owners[i] = taskOwnerReference(newTask(strings.Repeat("x", i+1)))
+		}
+		existing := sharedContextConfigMap(newTask("x"), perTask(newTask("x"), data))
+		existing.OwnerReferences = owners
+		r := &TaskReconciler{Client: fake.NewClientBuilder().WithScheme(s).WithObjects(existing).Build()}
+
+		task := newTask("late")
+		cm, err := r.ensureSharedContextConfigMap(ctx, task, perTask(task, data))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cm.Name != "late"+ContextConfigMapSuffix {
+			t.Errorf("ConfigMap = %q, want the Task's own ConfigMap", cm.Name)
+		}
+		if err := r.Get(ctx, types.NamespacedName{Name: cm.Name, Namespace: "default"}, &corev1.ConfigMap{}); err != nil {
+			t.Errorf("Task ConfigMap not created: %v", err)
+		}
+	})
+
+	t.Run("falls back to a Task ConfigMap on hash collision", func(t *testing.T) {
+		task := newTask("collide")
+		existing := sharedContextConfigMap(newTask("other"), perTask(task, data))
+		existing.Data = map[string]string{"task.md": "different content"}
+		r := &TaskReconciler{Client: fake.NewClientBuilder().WithScheme(s).WithObjects(existing).Build()}
+
+		cm, err := r.ensureSharedContextConfigMap(ctx, task, perTask(task, data))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cm.Name != "collide"+ContextConfigMapSuffix {
+			t.Errorf("ConfigMap = %q, want the Task's own ConfigMap", cm.Name)
+		}
+	})
+}
@@ -6,7 +6,9 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"path"
 	"sort"
 	"strings"
 	"time"
@@ -64,16 +66,29 @@ func resolveContextItemFromReader(reader contextReader, ctx context.Context, ite
 	if item.Type == kubeopenv1alpha1.ContextTypeGit && item.MountPath == "" {
 		return nil, nil, nil, fmt.Errorf("git context requires mountPath to be specified")
 	}
+	// Validate: Vault context requires mountPath, so secrets are never aggregated into context.md
+	if item.Type == kubeopenv1alpha1.ContextTypeVault && item.MountPath == "" {
+		return nil, nil, nil, fmt.Errorf("vault context requires mountPath to be specified")
+	}
+	// Validate: Archive context requires mountPath as the extraction directory
+	if item.Type == kubeopenv1alpha1.ContextTypeArchive && item.MountPath == "" {
+		return nil, nil, nil, fmt.Errorf("archive context requires mountPath to be specified")
+	}
 
-	// Use a generated name for contexts
+	// Use a generated name for contexts; Git contexts keep their own name, which
+	// identifies the repository in logs, errors, and Git sync status
 	name := "context"
-	if item.Type == kubeopenv1alpha1.ContextTypeRuntime {
+	switch {
+	case item.Type == kubeopenv1alpha1.ContextTypeGit && item.Name != "":
+		name = item.Name
+	case item.Type == kubeopenv1alpha1.ContextTypeRuntime:
 		name = "runtime"
 	}
 
 	// Resolve mountPath: relative paths are prefixed with workspaceDir
 	resolvedPath := resolveMountPath(item.MountPath, workspaceDir)
-	if item.Type == kubeopenv1alpha1.ContextTypeRuntime {
+	if item.Type == kubeopenv1alpha1.ContextTypeRuntime || item.Type == kubeopenv1alpha1.ContextTypeVault ||
+		item.Type == kubeopenv1alpha1.ContextTypeURL || item.Type == kubeopenv1alpha1.ContextTypeArchive {
 		resolvedPath = "" // Force empty to ensure content is appended to context file
 	}
 
@@ -120,15 +135,37 @@ func resolveContextContentFromReader(reader contextReader, ctx context.Context,
 			return content, nil, nil, err
 		}
 
-		if mountPath != "" {
-			optional := false
-			if cm.Optional != nil {
-				optional = *cm.Optional
+		optional := cm.Optional != nil && *cm.Optional
+
+		if len(cm.Items) > 0 {
+			items, err := configMapItemsToKeyPaths(cm.Items)
+			if err != nil {
+				return "", nil, nil, fmt.Errorf("invalid items for ConfigMap %s: %w", cm.Name, err)
 			}
+			if mountPath == "" {
+				content, err := getConfigMapItemsFromReader(reader, ctx, namespace, cm.Name, items, optional)
+				return content, nil, nil, err
+			}
+			// Verify selected keys up front so a missing key surfaces as a ContextError
+			// instead of a Pod stuck in ContainerCreating on volume setup.
+			if _, err := getConfigMapItemsFromReader(reader, ctx, namespace, cm.Name, items, optional); err != nil {
+				return "", nil, nil, err
+			}
+			return "", &dirMount{
+				dirPath:       mountPath,
+				configMapName: cm.Name,
+				optional:      optional,
+				items:         items,
+				fileMode:      item.FileMode,
+			}, nil, nil
+		}
+
+		if mountPath != "" {
 			return "", &dirMount{
 				dirPath:       mountPath,
 				configMapName: cm.Name,
 				optional:      optional,
+				fileMode:      item.FileMode,
 			}, nil, nil
 		}
 
@@ -184,6 +221,55 @@ func resolveContextContentFromReader(reader contextReader, ctx context.Context,
 	case kubeopenv1alpha1.ContextTypeRuntime:
 		return RuntimeSystemPrompt, nil, nil, nil
 
+	case kubeopenv1alpha1.ContextTypeURL:
+		// Content is fetched (and verified) by a url-fetch init container; only a
+		// pointer to the fetched file is added to the context.
+		if item.URL == nil {
+			return "", nil, nil, nil
+		}
+		return describeURLContext(item, item.Name, workspaceDir), nil, nil, nil
+
+	case kubeopenv1alpha1.ContextTypeArchive:
+		// The archive is downloaded and extracted by a url-fetch init container; only a
+		// pointer to the extraction directory is added to the context.
+		if item.Archive == nil {
+			return "", nil, nil, nil
+		}
+		return describeArchiveContext(item, workspaceDir), nil, nil, nil
+
+	case kubeopenv1alpha1.ContextTypeVault:
+		// Secret values are fetched by the vault-fetch init container; only a
+		// description of the files is added to the context.
+		if item.Vault == nil {
+			return "", nil, nil, nil
+		}
+		if _, err := vaultKeySpecs(item.Vault.Keys); err != nil {
+			return "", nil, nil, fmt.Errorf("invalid keys for Vault path %s: %w", item.Vault.Path, err)
+		}
+		return describeVaultContext(item, workspaceDir), nil, nil, nil
+
+	case kubeopenv1alpha1.ContextTypeTaskOutput:
+		if item.TaskOutput == nil {
+			return "", nil, nil, nil
+		}
+		ref := item.TaskOutput
+		producer := &kubeopenv1alpha1.Task{}
+		if err := reader.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: namespace}, producer); err != nil {
+			if ref.Optional != nil && *ref.Optional {
+				return "", nil, nil, nil
+			}
+			return "", nil, nil, fmt.Errorf("failed to get Task %s: %w", ref.Name, err)
+		}
+		cm, err := taskOutputConfigMapContext(producer, ref)
+		if err != nil || cm == nil {
+			return "", nil, nil, err
+		}
+		// Captured outputs are stored in a ConfigMap, so resolve them as a ConfigMap context
+		cmItem := *item
+		cmItem.Type = kubeopenv1alpha1.ContextTypeConfigMap
+		cmItem.ConfigMap = cm
+		return resolveContextContentFromReader(reader, ctx, namespace, name, workspaceDir, &cmItem, mountPath)
+
 	default:
 		return "", nil, nil, fmt.Errorf("unknown context type: %s", item.Type)
 	}
@@ -234,6 +320,58 @@ func getConfigMapAllKeysFromReader(reader contextReader, ctx context.Context, na
 	return strings.Join(parts, "\n"), nil
 }
 
+// configMapItemsToKeyPaths converts ConfigMap context items into volume KeyToPath entries.
+// Path defaults to the key name. Paths must be relative, must not escape the mount
+// directory, and must be unique within the context.
+func configMapItemsToKeyPaths(items []kubeopenv1alpha1.ConfigMapKeyToPath) ([]corev1.KeyToPath, error) {
+	result := make([]corev1.KeyToPath, 0, len(items))
+	seen := make(map[string]string, len(items))
+	for _, item := range items {
+		if item.Key == "" {
+			return nil, fmt.Errorf("item key must not be empty")
+		}
+		p := defaultString(item.Path, item.Key)
+		if path.IsAbs(p) {
+			return nil, fmt.Errorf("path %q for key %s must be relative", p, item.Key)
+		}
+		p = path.Clean(p)
+		if p == "." || p == ".." || strings.HasPrefix(p, "../") {
+			return nil, fmt.Errorf("path %q for key %s must not escape the mount directory", item.Path, item.Key)
+		}
+		if existing, ok := seen[p]; ok {
+			return nil, fmt.Errorf("path %q is used by both key %s and key %s", p, existing, item.Key)
+		}
+		seen[p] = item.Key
+		result = append(result, corev1.KeyToPath{Key: item.Key, Path: p, Mode: item.Mode})
+	}
+	return result, nil
+}
+
+// getConfigMapItemsFromReader retrieves the selected keys from a ConfigMap and formats them
+// for aggregation, using each item's path as the file name. Items keep their declared order.
+func getConfigMapItemsFromReader(reader contextReader, ctx context.Context, namespace, name string, items []corev1.KeyToPath, optional bool) (string, error) {
+	cm := &corev1.ConfigMap{}
+	if err := reader.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, cm); err != nil {
+		if optional {
+			return "", nil
+		}
+		return "", err
+	}
+
+	var parts []string
+	for _, item := range items {
+		content, ok := cm.Data[item.Key]
+		if !ok {
+			if optional {
+				continue
+			}
+			return "", fmt.Errorf("key %s not found in ConfigMap %s", item.Key, name)
+		}
+		parts = append(parts, fmt.Sprintf("<file name=%q>\n%s\n</file>", item.Path, content))
+	}
+	return strings.Join(parts, "\n"), nil
+}
+
 // processContextItems resolves a slice of ContextItems into resolved contexts, dir mounts, and git mounts.
 // This is used by both Task and Agent context processing.
 func processContextItems(reader contextReader, ctx context.Context, items []kubeopenv1alpha1.ContextItem, namespace, workspaceDir string) ([]resolvedContext, []dirMount, []gitMount, error) {
@@ -242,9 +380,19 @@ func processContextItems(reader contextReader, ctx context.Context, items []kube
 	var gitMounts []gitMount
 
 	for i, item := range items {
+		// Unnamed Git contexts get a positional name so that each repository has a
+		// distinct, deterministic identity in logs, errors, and Git sync status.
+		if item.Type == kubeopenv1alpha1.ContextTypeGit {
+			item.Name = ContextName(&item, i)
+		}
+		// Unnamed URL and Archive contexts are named the same way as their url-fetch spec (see collectURLFetches).
+		// Task contexts are named by nameURLContexts beforehand, after the Agent's.
+		if item.Type == kubeopenv1alpha1.ContextTypeURL || item.Type == kubeopenv1alpha1.ContextTypeArchive {
+			item.Name = urlContextName(&item, i)
+		}
 		rc, dm, gm, err := resolveContextItemFromReader(reader, ctx, &item, namespace, workspaceDir)
 		if err != nil {
-			return nil, nil, nil, fmt.Errorf("failed to resolve context[%d]: %w", i, err)
+			return nil, nil, nil, &contextItemError{index: i, ctxType: item.Type, err: err}
 		}
 		switch {
 		case dm != nil:
@@ -252,6 +400,10 @@ func processContextItems(reader contextReader, ctx context.Context, items []kube
 		case gm != nil:
 			gitMounts = append(gitMounts, *gm)
 		case rc != nil:
+			// Snapshots are only taken for templateRef Tasks, so the caller decides whether to list them
+			if item.Type == kubeopenv1alpha1.ContextTypeRuntime {
+				rc.snapshots = describeResourceSnapshots(&item, workspaceDir)
+			}
 			resolved = append(resolved, *rc)
 		}
 	}
@@ -259,6 +411,30 @@ func processContextItems(reader contextReader, ctx context.Context, items []kube
 	return resolved, dirMounts, gitMounts, nil
 }
 
+// contextItemError is returned by processContextItems for a context that cannot be resolved.
+type contextItemError struct {
+	index   int
+	ctxType kubeopenv1alpha1.ContextType
+	err     error
+}
+
+func (e *contextItemError) Error() string {
+	return fmt.Sprintf("failed to resolve context[%d]: %v", e.index, e.err)
+}
+
+func (e *contextItemError) Unwrap() error {
+	return e.err
+}
+
+// recordContextFetchFailure counts a failure to process the contexts of a task in
+// namespace, by the type of the failing context, if err identifies one.
+func recordContextFetchFailure(namespace string, err error) {
+	var itemErr *contextItemError
+	if errors.As(err, &itemErr) {
+		ContextFetchFailuresTotal.WithLabelValues(namespace, string(itemErr.ctxType)).Inc()
+	}
+}
+
 // buildContextConfigMapData builds ConfigMap data from resolved contexts.
 // Returns the ConfigMap data map and file mounts for contexts that have explicit mount paths.
 // Contexts without mountPath are aggregated into .kubeopencode/context.md.
@@ -6,7 +6,10 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -21,18 +24,26 @@ import (
 // fakeReader implements contextReader for testing.
 type fakeReader struct {
 	configMaps map[types.NamespacedName]*corev1.ConfigMap
+	tasks      map[types.NamespacedName]*kubeopenv1alpha1.Task
 }
 
 func (f *fakeReader) Get(ctx context.Context, key types.NamespacedName, obj client.Object, opts ...client.GetOption) error {
-	cm, ok := obj.(*corev1.ConfigMap)
-	if !ok {
+	switch o := obj.(type) {
+	case *corev1.ConfigMap:
+		stored, found := f.configMaps[key]
+		if !found {
+			return fmt.Errorf("configmap %s/%s not found", key.Namespace, key.Name)
+		}
+		*o = *stored
+	case *kubeopenv1alpha1.Task:
+		stored, found := f.tasks[key]
+		if !found {
+			return fmt.Errorf("task %s/%s not found", key.Namespace, key.Name)
+		}
+		*o = *stored
+	default:
 		return fmt.Errorf("unexpected object type: %T", obj)
 	}
-	stored, found := f.configMaps[key]
-	if !found {
-		return fmt.Errorf("configmap %s/%s not found", key.Namespace, key.Name)
-	}
-	*cm = *stored
 	return nil
 }
 
@@ -286,6 +297,130 @@ func TestResolveContextContentFromReader_ConfigMap(t *testing.T) {
 	})
 }
 
+func TestResolveContextContentFromReader_ConfigMapItems(t *testing.T) {
+	reader := newFakeReader(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-cm", Namespace: "default"},
+		Data:       map[string]string{"run.sh": "#!/bin/sh", "notes.md": "notes", "unused": "x"},
+	})
+	ctx := context.Background()
+	execMode := int32(0755)
+	defaultMode := int32(0600)
+
+	t.Run("items with mountPath returns dirMount with selected keys", func(t *testing.T) {
+		item := &kubeopenv1alpha1.ContextItem{
+			Type:     kubeopenv1alpha1.ContextTypeConfigMap,
+			FileMode: &defaultMode,
+			ConfigMap: &kubeopenv1alpha1.ConfigMapContext{
+				Name: "my-cm",
+				Items: []kubeopenv1alpha1.ConfigMapKeyToPath{
+					{Key: "run.sh", Path: "bin/run", Mode: &execMode},
+					{Key: "notes.md"},
+				},
+			},
+		}
+		_, dm, gm, err := resolveContextContentFromReader(reader, ctx, "default", "context", "/workspace", item, "/workspace/tools")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if gm != nil || dm == nil {
+			t.Fatal("expected dirMount only")
+		}
+		if len(dm.items) != 2 {
+			t.Fatalf("dirMount.items length = %d, want 2", len(dm.items))
+		}
+		if dm.items[0].Key != "run.sh" || dm.items[0].Path != "bin/run" || dm.items[0].Mode == nil || *dm.items[0].Mode != execMode {
+			t.Errorf("dirMount.items[0] = %+v, want run.sh -> bin/run (0755)", dm.items[0])
+		}
+		if dm.items[1].Path != "notes.md" {
+			t.Errorf("dirMount.items[1].Path = %q, want path defaulted to key", dm.items[1].Path)
+		}
+		if dm.fileMode == nil || *dm.fileMode != defaultMode {
+			t.Errorf("dirMount.fileMode = %v, want %o", dm.fileMode, defaultMode)
+		}
+	})
+
+	t.Run("items without mountPath aggregates selected keys in order", func(t *testing.T) {
+		item := &kubeopenv1alpha1.ContextItem{
+			Type: kubeopenv1alpha1.ContextTypeConfigMap,
+			ConfigMap: &kubeopenv1alpha1.ConfigMapContext{
+				Name: "my-cm",
+				Items: []kubeopenv1alpha1.ConfigMapKeyToPath{
+					{Key: "run.sh", Path: "scripts/run.sh"},
+					{Key: "notes.md"},
+				},
+			},
+		}
+		content, dm, gm, err := resolveContextContentFromReader(reader, ctx, "default", "context", "/workspace", item, "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dm != nil || gm != nil {
+			t.Fatal("expected no dir/git mounts")
+		}
+		want := "<file name=\"scripts/run.sh\">\n#!/bin/sh\n</file>\n<file name=\"notes.md\">\nnotes\n</file>"
+		if content != want {
+			t.Errorf("content = %q, want %q", content, want)
+		}
+	})
+
+	t.Run("missing key returns error", func(t *testing.T) {
+		item := &kubeopenv1alpha1.ContextItem{
+			Type: kubeopenv1alpha1.ContextTypeConfigMap,
+			ConfigMap: &kubeopenv1alpha1.ConfigMapContext{
+				Name:  "my-cm",
+				Items: []kubeopenv1alpha1.ConfigMapKeyToPath{{Key: "nonexistent"}},
+			},
+		}
+		_, _, _, err := resolveContextContentFromReader(reader, ctx, "default", "context", "/workspace", item, "/workspace/tools")
+		if err == nil {
+			t.Fatal("expected error for missing key")
+		}
+	})
+
+	t.Run("missing key with optional=true is skipped", func(t *testing.T) {
+		optional := true
+		item := &kubeopenv1alpha1.ContextItem{
+			Type: kubeopenv1alpha1.ContextTypeConfigMap,
+			ConfigMap: &kubeopenv1alpha1.ConfigMapContext{
+				Name:     "my-cm",
+				Optional: &optional,
+				Items:    []kubeopenv1alpha1.ConfigMapKeyToPath{{Key: "nonexistent"}, {Key: "notes.md"}},
+			},
+		}
+		content, _, _, err := resolveContextContentFromReader(reader, ctx, "default", "context", "/workspace", item, "")
+		if err != nil {
+			t.Fatalf("unexpected error for optional: %v", err)
+		}
+		if content != "<file name=\"notes.md\">\nnotes\n</file>" {
+			t.Errorf("content = %q, want only notes.md", content)
+		}
+	})
+
+	invalidPaths := []struct {
+		name  string
+		items []kubeopenv1alpha1.ConfigMapKeyToPath
+	}{
+		{"absolute path", []kubeopenv1alpha1.ConfigMapKeyToPath{{Key: "run.sh", Path: "/etc/run.sh"}}},
+		{"path escapes mount", []kubeopenv1alpha1.ConfigMapKeyToPath{{Key: "run.sh", Path: "../run.sh"}}},
+		{"duplicate path", []kubeopenv1alpha1.ConfigMapKeyToPath{{Key: "run.sh", Path: "x"}, {Key: "notes.md", Path: "./x"}}},
+	}
+	for _, tt := range invalidPaths {
+		t.Run(tt.name, func(t *testing.T) {
+			item := &kubeopenv1alpha1.ContextItem{
+				Type: kubeopenv1alpha1.ContextTypeConfigMap,
+				ConfigMap: &kubeopenv1alpha1.ConfigMapContext{
+					Name:  "my-cm",
+					Items: tt.items,
+				},
+			}
+			_, _, _, err := resolveContextContentFromReader(reader, ctx, "default", "context", "/workspace", item, "/workspace/tools")
+			if err == nil {
+				t.Fatal("expected validation error")
+			}
+		})
+	}
+}
+
 func TestResolveContextContentFromReader_Git(t *testing.T) {
 	reader := newFakeReader()
 	ctx := context.Background()
@@ -467,6 +602,90 @@ func TestResolveContextContentFromReader_UnknownType(t *testing.T) {
 	}
 }
 
+func TestResolveContextContentFromReader_TaskOutput(t *testing.T) {
+	reader := newFakeReader(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "analyze-outputs", Namespace: "default"},
+		Data:       map[string]string{"report.md": "# Report", "summary.txt": "ok"},
+	})
+	reader.tasks = map[types.NamespacedName]*kubeopenv1alpha1.Task{
+		{Name: "analyze", Namespace: "default"}: {
+			ObjectMeta: metav1.ObjectMeta{Name: "analyze", Namespace: "default"},
+			Status: kubeopenv1alpha1.TaskExecutionStatus{
+				Phase:   kubeopenv1alpha1.TaskPhaseCompleted,
+				Outputs: &kubeopenv1alpha1.TaskOutputsStatus{ConfigMapName: "analyze-outputs", Files: []string{"report.md", "summary.txt"}},
+			},
+		},
+		{Name: "running", Namespace: "default"}: {
+			ObjectMeta: metav1.ObjectMeta{Name: "running", Namespace: "default"},
+			Status:     kubeopenv1alpha1.TaskExecutionStatus{Phase: kubeopenv1alpha1.TaskPhaseRunning},
+		},
+	}
+	ctx := context.Background()
+	optional := true
+
+	t.Run("without mountPath aggregates selected files", func(t *testing.T) {
+		item := &kubeopenv1alpha1.ContextItem{
+			Type:       kubeopenv1alpha1.ContextTypeTaskOutput,
+			TaskOutput: &kubeopenv1alpha1.TaskOutputContext{Name: "analyze", Files: []string{"report.md"}},
+		}
+		content, dm, gm, err := resolveContextContentFromReader(reader, ctx, "default", "context", "/workspace", item, "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dm != nil || gm != nil {
+			t.Fatal("expected content only")
+		}
+		if !contains(content, "# Report") || contains(content, "ok") {
+			t.Errorf("content = %q, want report.md only", content)
+		}
+	})
+
+	t.Run("with mountPath returns dirMount over outputs ConfigMap", func(t *testing.T) {
+		item := &kubeopenv1alpha1.ContextItem{
+			Type:       kubeopenv1alpha1.ContextTypeTaskOutput,
+			TaskOutput: &kubeopenv1alpha1.TaskOutputContext{Name: "analyze"},
+		}
+		_, dm, _, err := resolveContextContentFromReader(reader, ctx, "default", "context", "/workspace", item, "/workspace/previous")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dm == nil || dm.configMapName != "analyze-outputs" || dm.dirPath != "/workspace/previous" {
+			t.Errorf("dirMount = %+v, want analyze-outputs at /workspace/previous", dm)
+		}
+	})
+
+	t.Run("unfinished task returns error", func(t *testing.T) {
+		item := &kubeopenv1alpha1.ContextItem{
+			Type:       kubeopenv1alpha1.ContextTypeTaskOutput,
+			TaskOutput: &kubeopenv1alpha1.TaskOutputContext{Name: "running"},
+		}
+		if _, _, _, err := resolveContextContentFromReader(reader, ctx, "default", "context", "/workspace", item, ""); err == nil {
+			t.Error("expected error for unfinished task")
+		}
+	})
+
+	t.Run("missing task returns error", func(t *testing.T) {
+		item := &kubeopenv1alpha1.ContextItem{
+			Type:       kubeopenv1alpha1.ContextTypeTaskOutput,
+			TaskOutput: &kubeopenv1alpha1.TaskOutputContext{Name: "missing"},
+		}
+		if _, _, _, err := resolveContextContentFromReader(reader, ctx, "default", "context", "/workspace", item, ""); err == nil {
+			t.Error("expected error for missing task")
+		}
+	})
+
+	t.Run("optional unfinished task returns empty", func(t *testing.T) {
+		item := &kubeopenv1alpha1.ContextItem{
+			Type:       kubeopenv1alpha1.ContextTypeTaskOutput,
+			TaskOutput: &kubeopenv1alpha1.TaskOutputContext{Name: "running", Optional: &optional},
+		}
+		content, dm, gm, err := resolveContextContentFromReader(reader, ctx, "default", "context", "/workspace", item, "/workspace/previous")
+		if err != nil || content != "" || dm != nil || gm != nil {
+			t.Errorf("got (%q, %v, %v, %v), want empty result", content, dm, gm, err)
+		}
+	})
+}
+
 func TestResolveContextItemFromReader(t *testing.T) {
 	reader := newFakeReader()
 	ctx := context.Background()
@@ -522,6 +741,35 @@ func TestResolveContextItemFromReader(t *testing.T) {
 		}
 	})
 
+	t.Run("archive context without mountPath fails", func(t *testing.T) {
+		item := &kubeopenv1alpha1.ContextItem{
+			Type:    kubeopenv1alpha1.ContextTypeArchive,
+			Archive: &kubeopenv1alpha1.ArchiveContext{URLContext: kubeopenv1alpha1.URLContext{Source: "https://example.com/r.zip"}},
+		}
+		_, _, _, err := resolveContextItemFromReader(reader, ctx, item, "default", "/workspace")
+		if err == nil {
+			t.Fatal("expected error for archive context without mountPath")
+		}
+	})
+
+	t.Run("archive context describes extraction directory", func(t *testing.T) {
+		item := &kubeopenv1alpha1.ContextItem{
+			Type:      kubeopenv1alpha1.ContextTypeArchive,
+			MountPath: "release",
+			Archive:   &kubeopenv1alpha1.ArchiveContext{URLContext: kubeopenv1alpha1.URLContext{Source: "https://example.com/r.zip"}},
+		}
+		rc, _, _, err := resolveContextItemFromReader(reader, ctx, item, "default", "/workspace")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if rc.mountPath != "" {
+			t.Errorf("archive description should be appended to the context file, got mountPath %q", rc.mountPath)
+		}
+		if !strings.Contains(rc.content, "/workspace/release") {
+			t.Errorf("content = %q, want extraction directory", rc.content)
+		}
+	})
+
 	t.Run("text context with fileMode", func(t *testing.T) {
 		mode := int32(0755)
 		item := &kubeopenv1alpha1.ContextItem{
@@ -593,6 +841,50 @@ func TestProcessContextItems(t *testing.T) {
 		}
 	})
 
+	t.Run("multiple git contexts keep order and get distinct names", func(t *testing.T) {
+		items := []kubeopenv1alpha1.ContextItem{
+			{
+				Type: kubeopenv1alpha1.ContextTypeGit,
+				Git: &kubeopenv1alpha1.GitContext{
+					Repository: "https://github.com/example/frontend.git",
+					SecretRef:  &kubeopenv1alpha1.GitSecretReference{Name: "frontend-creds"},
+				},
+				MountPath: "frontend",
+			},
+			{
+				Type: kubeopenv1alpha1.ContextTypeText,
+				Text: "text content",
+			},
+			{
+				Name: "backend",
+				Type: kubeopenv1alpha1.ContextTypeGit,
+				Git: &kubeopenv1alpha1.GitContext{
+					Repository: "https://github.com/example/backend.git",
+					SecretRef:  &kubeopenv1alpha1.GitSecretReference{Name: "backend-creds"},
+				},
+				MountPath: "backend",
+			},
+		}
+		_, _, gitMounts, err := processContextItems(reader, ctx, items, "default", "/workspace")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(gitMounts) != 2 {
+			t.Fatalf("gitMounts count = %d, want 2", len(gitMounts))
+		}
+		want := []struct{ name, mountPath, secret string }{
+			{"git-0", "/workspace/frontend", "frontend-creds"},
+			{"backend", "/workspace/backend", "backend-creds"},
+		}
+		for i, w := range want {
+			gm := gitMounts[i]
+			if gm.contextName != w.name || gm.mountPath != w.mountPath || gm.secretName != w.secret {
+				t.Errorf("gitMounts[%d] = {%q, %q, %q}, want {%q, %q, %q}",
+					i, gm.contextName, gm.mountPath, gm.secretName, w.name, w.mountPath, w.secret)
+			}
+		}
+	})
+
 	t.Run("error propagation", func(t *testing.T) {
 		items := []kubeopenv1alpha1.ContextItem{
 			{
@@ -604,6 +896,24 @@ func TestProcessContextItems(t *testing.T) {
 			t.Fatal("expected error for invalid context type")
 		}
 	})
+
+	t.Run("error identifies the failing context type", func(t *testing.T) {
+		items := []kubeopenv1alpha1.ContextItem{
+			{Type: kubeopenv1alpha1.ContextTypeText, Text: "text content"},
+			{Type: kubeopenv1alpha1.ContextTypeConfigMap, ConfigMap: &kubeopenv1alpha1.ConfigMapContext{Name: "missing-cm"}},
+		}
+		_, _, _, err := processContextItems(reader, ctx, items, "default", "/workspace")
+		var itemErr *contextItemError
+		if !errors.As(fmt.Errorf("failed to resolve Task contexts: %w", err), &itemErr) {
+			t.Fatalf("expected a contextItemError, got %v", err)
+		}
+		if itemErr.index != 1 || itemErr.ctxType != kubeopenv1alpha1.ContextTypeConfigMap {
+			t.Errorf("contextItemError = {%d, %s}, want {1, ConfigMap}", itemErr.index, itemErr.ctxType)
+		}
+		if !strings.HasPrefix(err.Error(), "failed to resolve context[1]: ") {
+			t.Errorf("error = %q", err)
+		}
+	})
 }
 
 func TestBuildContextConfigMapData(t *testing.T) {
@@ -859,3 +1169,51 @@ func containsSubstr(s, substr string) bool {
 	}
 	return false
 }
+
+func TestCollectResourceSnapshots(t *testing.T) {
+	items := []kubeopenv1alpha1.ContextItem{
+		{Type: kubeopenv1alpha1.ContextTypeText, Text: "ignored"},
+		{Type: kubeopenv1alpha1.ContextTypeRuntime},
+		{
+			Type: kubeopenv1alpha1.ContextTypeRuntime,
+			Runtime: &kubeopenv1alpha1.RuntimeContext{
+				Resources: []kubeopenv1alpha1.ResourceSnapshot{
+					{Name: "web", APIVersion: "apps/v1", Kind: "Deployment", LabelSelector: "app=web"},
+					{Name: "events", APIVersion: "v1", Kind: "Event", Namespace: "prod", FieldSelector: "involvedObject.name=web-0"},
+				},
+			},
+		},
+		{
+			Type:      kubeopenv1alpha1.ContextTypeRuntime,
+			MountPath: "/tmp/state",
+			Runtime: &kubeopenv1alpha1.RuntimeContext{
+				Resources: []kubeopenv1alpha1.ResourceSnapshot{
+					{Name: "nodes", APIVersion: "v1", Kind: "Node"},
+				},
+			},
+		},
+	}
+
+	specs := collectResourceSnapshots(items, "default", "/workspace")
+	want := []resourceSnapshotSpec{
+		{TargetPath: "/workspace/" + DefaultResourceSnapshotDir + "/web.yaml", APIVersion: "apps/v1", Kind: "Deployment", Namespace: "default", LabelSelector: "app=web"},
+		{TargetPath: "/workspace/" + DefaultResourceSnapshotDir + "/events.yaml", APIVersion: "v1", Kind: "Event", Namespace: "prod", FieldSelector: "involvedObject.name=web-0"},
+		{TargetPath: "/tmp/state/nodes.yaml", APIVersion: "v1", Kind: "Node", Namespace: "default"},
+	}
+	if !reflect.DeepEqual(specs, want) {
+		t.Errorf("collectResourceSnapshots() =\n%+v\nwant\n%+v", specs, want)
+	}
+
+	t.Run("runtime prompt lists snapshot files", func(t *testing.T) {
+		content := describeResourceSnapshots(&items[2], "/workspace")
+		for _, want := range []string{
+			"/workspace/" + DefaultResourceSnapshotDir,
+			"web.yaml: Deployment matching app=web (apps/v1)",
+			"events.yaml: Event matching involvedObject.name=web-0 in namespace prod (v1)",
+		} {
+			if !contains(content, want) {
+				t.Errorf("runtime prompt missing %q", want)
+			}
+		}
+	})
+}
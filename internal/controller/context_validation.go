@@ -0,0 +1,147 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package controller
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+)
+
+// MaxContextConfigMapSize is the maximum total size of a context ConfigMap's data.
+// Kubernetes rejects ConfigMaps larger than 1MiB.
+const MaxContextConfigMapSize = corev1.MaxSecretSize
+
+// ValidateTaskContexts checks a Task's own contexts for problems that would otherwise
+// only surface while the Task is being started:
+//   - inline Text contexts and the description exceeding the ConfigMap size limit
+//   - relative mount paths escaping the workspace directory
+//   - two contexts mounting to the same path
+//   - two contexts with the same name
+//   - URL, Archive and Vault contexts on agentRef Tasks
+//
+// It does not need the Agent or AgentTemplate, so it can run before the Task is
+// created (e.g., in the UI server) as well as when the controller first sees it.
+// workspaceDir is the workspace directory of the Task's Agent or AgentTemplate, or ""
+// if it is not known yet (see ValidateContextMountPaths).
+// Conflicts with Agent/AgentTemplate contexts are detected later by validateMountPathConflicts.
+func ValidateTaskContexts(task *kubeopenv1alpha1.Task, workspaceDir string) error {
+	size := 0
+	if task.Spec.Description != nil {
+		size += len(*task.Spec.Description)
+	}
+
+	for i := range task.Spec.Contexts {
+		if task.Spec.Contexts[i].Type == kubeopenv1alpha1.ContextTypeText {
+			size += len(task.Spec.Contexts[i].Text)
+		}
+	}
+	if err := ValidateContextMountPaths(task.Spec.Contexts, workspaceDir); err != nil {
+		return err
+	}
+	if err := validateContextNames(task.Spec.Contexts); err != nil {
+		return err
+	}
+	if task.Spec.AgentRef != nil {
+		if err := validateAgentRefContexts(task.Spec.Contexts); err != nil {
+			return err
+		}
+	}
+
+	if size > MaxContextConfigMapSize {
+		return fmt.Errorf("description and inline Text contexts total %d bytes, exceeding the ConfigMap limit of %d bytes; move large content to a ConfigMap or Git context", size, MaxContextConfigMapSize)
+	}
+	return nil
+}
+
+// ValidateContextMountPaths checks that relative mount paths stay inside the workspace
+// directory and that no two contexts of the list mount to the same path.
+// Relative paths are resolved against workspaceDir, so "src" and "/workspace/src"
+// conflict in the workspace "/workspace". If workspaceDir is "", relative paths are
+// only compared with relative paths, and absolute paths with absolute paths.
+func ValidateContextMountPaths(contexts []kubeopenv1alpha1.ContextItem, workspaceDir string) error {
+	mountPaths := make(map[string]string) // cleaned path -> context description
+	for i := range contexts {
+		item := &contexts[i]
+		if item.MountPath == "" {
+			continue
+		}
+		desc := fmt.Sprintf("context[%d]", i)
+		if item.Name != "" {
+			desc = fmt.Sprintf("context[%d] (%s)", i, item.Name)
+		}
+		p := path.Clean(item.MountPath)
+		if !path.IsAbs(p) && (p == ".." || strings.HasPrefix(p, "../")) {
+			return fmt.Errorf("%s: mountPath %q escapes the workspace directory", desc, item.MountPath)
+		}
+		if workspaceDir != "" {
+			p = path.Clean(resolveMountPath(item.MountPath, workspaceDir))
+		}
+		if existing, ok := mountPaths[p]; ok {
+			return fmt.Errorf("mount path conflict: %q is used by both %s and %s", p, existing, desc)
+		}
+		mountPaths[p] = desc
+	}
+	return nil
+}
+
+// ContextName returns the name of the context at index in its list: its own name,
+// or for an unnamed Git context the positional name "git-<index>". It returns ""
+// for other unnamed contexts.
+func ContextName(item *kubeopenv1alpha1.ContextItem, index int) string {
+	if item.Name == "" && item.Type == kubeopenv1alpha1.ContextTypeGit {
+		return fmt.Sprintf("git-%d", index)
+	}
+	return item.Name
+}
+
+// validateContextNames checks that no two contexts of the list have the same name,
+// including the positional names of unnamed Git contexts.
+func validateContextNames(contexts []kubeopenv1alpha1.ContextItem) error {
+	names := make(map[string]int) // name -> index
+	for i := range contexts {
+		name := ContextName(&contexts[i], i)
+		if name == "" {
+			continue
+		}
+		if existing, ok := names[name]; ok {
+			return fmt.Errorf("context name conflict: %q is used by both context[%d] and context[%d]", name, existing, i)
+		}
+		names[name] = i
+	}
+	return nil
+}
+
+// validateAgentRefContexts rejects the context types that are fetched by init
+// containers of the Task Pod. An agentRef Task's Pod only attaches to the Agent's
+// server, which never sees files fetched into the Task Pod's workspace.
+func validateAgentRefContexts(contexts []kubeopenv1alpha1.ContextItem) error {
+	for i := range contexts {
+		switch contexts[i].Type {
+		case kubeopenv1alpha1.ContextTypeURL, kubeopenv1alpha1.ContextTypeArchive, kubeopenv1alpha1.ContextTypeVault:
+			desc := fmt.Sprintf("context[%d]", i)
+			if contexts[i].Name != "" {
+				desc = fmt.Sprintf("context[%d] (%s)", i, contexts[i].Name)
+			}
+			return fmt.Errorf("%s: %s contexts are only supported for templateRef Tasks", desc, contexts[i].Type)
+		}
+	}
+	return nil
+}
+
+// validateContextConfigMapSize checks that the rendered context ConfigMap data
+// (description, contexts, skills and config) fits in a ConfigMap.
+func validateContextConfigMapSize(data map[string]string) error {
+	size := 0
+	for k, v := range data {
+		size += len(k) + len(v)
+	}
+	if size > MaxContextConfigMapSize {
+		return fmt.Errorf("resolved contexts total %d bytes, exceeding the ConfigMap limit of %d bytes; mount large content with mountPath from a ConfigMap or Git context instead", size, MaxContextConfigMapSize)
+	}
+	return nil
+}
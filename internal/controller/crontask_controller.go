@@ -9,6 +9,8 @@ import (
 	"time"
 
 	"github.com/robfig/cron/v3"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -20,6 +22,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+	"github.com/kubeopencode/kubeopencode/internal/tracing"
 )
 
 const (
@@ -33,6 +36,8 @@ type CronTaskReconciler struct {
 	client.Client
 	Scheme   *runtime.Scheme
 	Recorder events.EventRecorder
+	// Shard limits reconciliation to the namespaces of this replica. Nil reconciles all.
+	Shard *Shard
 }
 
 // +kubebuilder:rbac:groups=kubeopencode.io,resources=crontasks,verbs=get;list;watch;create;update;patch;delete
@@ -135,6 +140,7 @@ func (r *CronTaskReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 			} else if created {
 				cronTask.Status.LastScheduleTime = &metav1.Time{Time: now}
 				cronTask.Status.TotalExecutions++
+				TriggeredTasksTotal.WithLabelValues(cronTask.Namespace, cronTask.Name, "manual").Inc()
 				r.Recorder.Eventf(cronTask, nil, corev1.EventTypeNormal, "Triggered", "CreateTask", "Manually triggered Task creation")
 			}
 		}
@@ -239,6 +245,7 @@ func (r *CronTaskReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 	cronTask.Status.LastScheduleTime = &metav1.Time{Time: *scheduledTime}
 	if created {
 		cronTask.Status.TotalExecutions++
+		TriggeredTasksTotal.WithLabelValues(cronTask.Namespace, cronTask.Name, "schedule").Inc()
 	}
 	r.setCondition(cronTask, ConditionReady, metav1.ConditionTrue, "Scheduled", "Task created successfully")
 
@@ -251,21 +258,26 @@ func (r *CronTaskReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 
 // parseSchedule parses the cron schedule with optional timezone.
 func (r *CronTaskReconciler) parseSchedule(cronTask *kubeopenv1alpha1.CronTask) (cron.Schedule, error) {
+	return ParseCronSchedule(cronTask.Spec.Schedule, cronTask.Spec.TimeZone)
+}
+
+// ParseCronSchedule parses a 5-field cron schedule, evaluated in timeZone when set.
+func ParseCronSchedule(schedule string, timeZone *string) (cron.Schedule, error) {
 	parser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
 
-	if cronTask.Spec.TimeZone != nil && *cronTask.Spec.TimeZone != "" {
-		loc, err := time.LoadLocation(*cronTask.Spec.TimeZone)
+	if timeZone != nil && *timeZone != "" {
+		loc, err := time.LoadLocation(*timeZone)
 		if err != nil {
-			return nil, fmt.Errorf("invalid timezone %q: %w", *cronTask.Spec.TimeZone, err)
+			return nil, fmt.Errorf("invalid timezone %q: %w", *timeZone, err)
 		}
-		sched, err := parser.Parse(cronTask.Spec.Schedule)
+		sched, err := parser.Parse(schedule)
 		if err != nil {
 			return nil, err
 		}
 		return &cronScheduleWithTZ{Schedule: sched, loc: loc}, nil
 	}
 
-	return parser.Parse(cronTask.Spec.Schedule)
+	return parser.Parse(schedule)
 }
 
 // cronScheduleWithTZ wraps a cron.Schedule to compute next times in a specific timezone.
@@ -342,10 +354,13 @@ func (r *CronTaskReconciler) createTask(ctx context.Context, cronTask *kubeopenv
 	}
 	labels[kubeopenv1alpha1.CronTaskLabelKey] = cronTask.Name
 
-	// Build annotations from template
+	// Build annotations from template. A traceparent in the template would join
+	// every run to one trace, so it is not copied.
 	annotations := make(map[string]string)
 	for k, v := range cronTask.Spec.TaskTemplate.Metadata.Annotations {
-		annotations[k] = v
+		if k != tracing.TraceParentAnnotation {
+			annotations[k] = v
+		}
 	}
 
 	task := &kubeopenv1alpha1.Task{
@@ -361,11 +376,18 @@ func (r *CronTaskReconciler) createTask(ctx context.Context, cronTask *kubeopenv
 		Spec: *cronTask.Spec.TaskTemplate.Spec.DeepCopy(),
 	}
 
+	// Each scheduled run starts a new trace
+	ctx, span := tracing.Tracer().Start(ctx, "CronTask create Task", trace.WithNewRoot(),
+		trace.WithAttributes(attribute.String("kubeopencode.crontask", cronTask.Name)))
+	defer span.End()
+	tracing.InjectAnnotation(ctx, task)
+
 	if err := r.Create(ctx, task); err != nil {
 		if errors.IsAlreadyExists(err) {
 			log.V(1).Info("Task already exists, skipping creation", "task", taskName)
 			return false, nil
 		}
+		tracing.RecordError(span, err)
 		r.Recorder.Eventf(cronTask, nil, corev1.EventTypeWarning, "TaskCreationFailed", "CreateTask",
 			"Failed to create Task %s: %v", taskName, err)
 		return false, fmt.Errorf("failed to create Task %s: %w", taskName, err)
@@ -425,5 +447,6 @@ func (r *CronTaskReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&kubeopenv1alpha1.CronTask{}).
 		Owns(&kubeopenv1alpha1.Task{}).
+		WithEventFilter(r.Shard.Predicate()).
 		Complete(r)
 }
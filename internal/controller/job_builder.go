@@ -0,0 +1,75 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package controller
+
+import (
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+)
+
+// usesJobBackend reports whether Tasks run in Jobs instead of bare Pods
+func (c agentConfig) usesJobBackend() bool {
+	return c.execution != nil && c.execution.Backend == kubeopenv1alpha1.ExecutionBackendJob
+}
+
+// taskJobName returns the name of the Job running task
+func taskJobName(task *kubeopenv1alpha1.Task) string {
+	return fmt.Sprintf("%s-job", task.Name)
+}
+
+// buildJob wraps the Task Pod built by buildPod in a Job. The Job is owned by the
+// Task and owns the Pods it creates, so the Pod template drops the Pod's name and
+// ownerReferences. Settings from cfg are passed through to the Job spec.
+func buildJob(task *kubeopenv1alpha1.Task, jobName string, pod *corev1.Pod, cfg *kubeopenv1alpha1.JobExecutionConfig) *batchv1.Job {
+	labels := make(map[string]string, len(pod.Labels))
+	for k, v := range pod.Labels {
+		labels[k] = v
+	}
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      jobName,
+			Namespace: task.Namespace,
+			Labels:    labels,
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(task, kubeopenv1alpha1.SchemeGroupVersion.WithKind("Task")),
+			},
+		},
+		Spec: batchv1.JobSpec{
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      pod.Labels,
+					Annotations: pod.Annotations,
+				},
+				Spec: pod.Spec,
+			},
+		},
+	}
+
+	if cfg != nil {
+		job.Spec.BackoffLimit = cfg.BackoffLimit
+		job.Spec.ActiveDeadlineSeconds = cfg.ActiveDeadlineSeconds
+		if cfg.PodFailurePolicy != nil {
+			job.Spec.PodFailurePolicy = cfg.PodFailurePolicy.DeepCopy()
+		}
+	}
+
+	return job
+}
+
+// jobFinishedCondition returns the Complete or Failed condition of a finished Job,
+// or nil while the Job is still running.
+func jobFinishedCondition(job *batchv1.Job) *batchv1.JobCondition {
+	for i := range job.Status.Conditions {
+		cond := &job.Status.Conditions[i]
+		if (cond.Type == batchv1.JobComplete || cond.Type == batchv1.JobFailed) && cond.Status == corev1.ConditionTrue {
+			return cond
+		}
+	}
+	return nil
+}
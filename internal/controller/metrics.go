@@ -44,6 +44,95 @@ var (
 		},
 		[]string{"agent", "namespace"},
 	)
+
+	// TaskQueueWaitSeconds is a histogram tracking the time queued tasks spent in the Queued phase.
+	TaskQueueWaitSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "kubeopencode_task_queue_wait_seconds",
+			Help:    "Time tasks spent in the Queued phase before starting, in seconds",
+			Buckets: prometheus.ExponentialBuckets(1, 2, 14), // 1s, 2s, 4s, ... ~2.3h
+		},
+		[]string{"namespace", "agent"},
+	)
+
+	// TaskStartupSeconds is a histogram tracking the time from task creation until its
+	// pod's containers were running, including queueing, scheduling and image pulls.
+	TaskStartupSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "kubeopencode_task_startup_seconds",
+			Help:    "Time from task creation until the task pod was running, in seconds",
+			Buckets: prometheus.ExponentialBuckets(1, 2, 14), // 1s, 2s, 4s, ... ~2.3h
+		},
+		[]string{"namespace", "agent"},
+	)
+
+	// TaskRetriesTotal is a counter tracking started tasks that retry another task,
+	// by the reason the retried task failed.
+	TaskRetriesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubeopencode_task_retries_total",
+			Help: "Number of started tasks that retry a previous task, by the retried task's failure reason",
+		},
+		[]string{"namespace", "agent", "reason"},
+	)
+
+	// TaskCleanupDeletionsTotal is a counter tracking tasks deleted by automatic cleanup.
+	TaskCleanupDeletionsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubeopencode_task_cleanup_deletions_total",
+			Help: "Number of finished tasks deleted by automatic cleanup, by policy (ttl or retention)",
+		},
+		[]string{"namespace", "policy"},
+	)
+
+	// OrphanedResourcesCollectedTotal is a counter tracking task pods and ConfigMaps
+	// deleted by the orphan collector because their task no longer exists.
+	OrphanedResourcesCollectedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubeopencode_orphaned_resources_collected_total",
+			Help: "Number of task resources deleted because their task no longer exists, by kind",
+		},
+		[]string{"namespace", "kind"},
+	)
+
+	// TaskPreemptionsTotal is a counter tracking running tasks preempted by higher-priority tasks.
+	TaskPreemptionsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubeopencode_task_preemptions_total",
+			Help: "Number of running tasks stopped and requeued for a higher-priority task",
+		},
+		[]string{"namespace", "agent"},
+	)
+
+	// TriggeredTasksTotal is a counter tracking tasks created by CronTasks, by how
+	// the CronTask was triggered. CronTasks are the only triggers of Tasks; there
+	// are no webhook triggers to count.
+	TriggeredTasksTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubeopencode_triggered_tasks_total",
+			Help: "Number of tasks created by CronTasks, by trigger (schedule or manual)",
+		},
+		[]string{"namespace", "crontask", "trigger"},
+	)
+
+	// ContextFetchFailuresTotal is a counter tracking contexts that could not be
+	// resolved by the controller or fetched by an init container.
+	ContextFetchFailuresTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubeopencode_context_fetch_failures_total",
+			Help: "Number of task contexts that failed to resolve or fetch, by context type",
+		},
+		[]string{"namespace", "type"},
+	)
+
+	// TaskReschedulesTotal is a counter tracking Task Pods recreated after a disruption.
+	TaskReschedulesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubeopencode_task_reschedules_total",
+			Help: "Number of Task Pods recreated after being lost to eviction or node failure",
+		},
+		[]string{"namespace", "agent"},
+	)
 )
 
 func init() {
@@ -52,5 +141,14 @@ func init() {
 		TaskDurationSeconds,
 		AgentCapacity,
 		AgentQueueLength,
+		TaskQueueWaitSeconds,
+		TaskStartupSeconds,
+		TaskRetriesTotal,
+		TaskCleanupDeletionsTotal,
+		OrphanedResourcesCollectedTotal,
+		TaskPreemptionsTotal,
+		TaskReschedulesTotal,
+		TriggeredTasksTotal,
+		ContextFetchFailuresTotal,
 	)
 }
@@ -0,0 +1,64 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package controller
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+)
+
+// NetworkPolicySuffix is appended to the Task name for the NetworkPolicy of its Pod
+const NetworkPolicySuffix = "-network-policy"
+
+// buildTaskNetworkPolicy returns the NetworkPolicy isolating the Pod of task, or nil
+// if isolation is not enabled. It selects the Task Pod by TaskLabelKey (also with
+// the Job backend), denies all ingress and allows egress to DNS, to the server Pods
+// of agentName (empty for templateRef Tasks) and to the configured egress rules.
+func buildTaskNetworkPolicy(task *kubeopenv1alpha1.Task, cfg *kubeopenv1alpha1.TaskNetworkPolicyConfig, agentName string) *networkingv1.NetworkPolicy {
+	if cfg == nil || !cfg.Enabled {
+		return nil
+	}
+
+	udp, tcp := corev1.ProtocolUDP, corev1.ProtocolTCP
+	dnsPort := intstr.FromInt32(53)
+	egress := []networkingv1.NetworkPolicyEgressRule{{
+		Ports: []networkingv1.NetworkPolicyPort{
+			{Protocol: &udp, Port: &dnsPort},
+			{Protocol: &tcp, Port: &dnsPort},
+		},
+	}}
+	if agentName != "" {
+		// agentRef Task Pods attach to the Agent's OpenCode server
+		egress = append(egress, networkingv1.NetworkPolicyEgressRule{
+			To: []networkingv1.NetworkPolicyPeer{{
+				PodSelector: &metav1.LabelSelector{MatchLabels: getServerLabels(agentName)},
+			}},
+		})
+	}
+	for i := range cfg.Egress {
+		egress = append(egress, *cfg.Egress[i].DeepCopy())
+	}
+
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      task.Name + NetworkPolicySuffix,
+			Namespace: task.Namespace,
+			Labels: map[string]string{
+				"app":        "kubeopencode",
+				TaskLabelKey: task.Name,
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(task, kubeopenv1alpha1.SchemeGroupVersion.WithKind("Task")),
+			},
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{TaskLabelKey: task.Name}},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+			Egress:      egress,
+		},
+	}
+}
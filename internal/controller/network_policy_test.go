@@ -0,0 +1,69 @@
+// Copyright Contributors to the KubeOpenCode project
+
+//go:build !integration
+
+package controller
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+)
+
+func TestBuildTaskNetworkPolicy(t *testing.T) {
+	task := &kubeopenv1alpha1.Task{ObjectMeta: metav1.ObjectMeta{Name: "fix", Namespace: "default", UID: "fix-uid"}}
+	https := intstr.FromInt32(443)
+	cfg := &kubeopenv1alpha1.TaskNetworkPolicyConfig{
+		Enabled: true,
+		Egress:  []networkingv1.NetworkPolicyEgressRule{{Ports: []networkingv1.NetworkPolicyPort{{Port: &https}}}},
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		if np := buildTaskNetworkPolicy(task, nil, "agent"); np != nil {
+			t.Error("expected no NetworkPolicy without config")
+		}
+		if np := buildTaskNetworkPolicy(task, &kubeopenv1alpha1.TaskNetworkPolicyConfig{Egress: cfg.Egress}, "agent"); np != nil {
+			t.Error("expected no NetworkPolicy when not enabled")
+		}
+	})
+
+	t.Run("agentRef task", func(t *testing.T) {
+		np := buildTaskNetworkPolicy(task, cfg, "agent")
+		if np == nil {
+			t.Fatal("expected a NetworkPolicy")
+		}
+		if np.Name != "fix"+NetworkPolicySuffix || !metav1.IsControlledBy(np, task) {
+			t.Errorf("unexpected metadata: %+v", np.ObjectMeta)
+		}
+		if np.Spec.PodSelector.MatchLabels[TaskLabelKey] != "fix" {
+			t.Errorf("podSelector = %v, want the task pod", np.Spec.PodSelector.MatchLabels)
+		}
+		if len(np.Spec.PolicyTypes) != 2 || len(np.Spec.Ingress) != 0 {
+			t.Errorf("expected ingress to be denied, got policyTypes %v and ingress %v", np.Spec.PolicyTypes, np.Spec.Ingress)
+		}
+		if len(np.Spec.Egress) != 3 {
+			t.Fatalf("expected DNS, agent server and configured egress rules, got %d", len(np.Spec.Egress))
+		}
+		if p := np.Spec.Egress[0].Ports[0]; *p.Protocol != corev1.ProtocolUDP || p.Port.IntValue() != 53 {
+			t.Errorf("first egress rule should allow DNS, got %+v", p)
+		}
+		if sel := np.Spec.Egress[1].To[0].PodSelector; sel == nil || sel.MatchLabels[AgentLabelKey] != "agent" {
+			t.Errorf("second egress rule should allow the agent server, got %+v", np.Spec.Egress[1])
+		}
+		if np.Spec.Egress[2].Ports[0].Port.IntValue() != 443 {
+			t.Errorf("configured egress rule missing, got %+v", np.Spec.Egress[2])
+		}
+	})
+
+	t.Run("templateRef task", func(t *testing.T) {
+		np := buildTaskNetworkPolicy(task, cfg, "")
+		if len(np.Spec.Egress) != 2 {
+			t.Errorf("expected DNS and configured egress rules only, got %d", len(np.Spec.Egress))
+		}
+	})
+}
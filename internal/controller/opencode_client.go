@@ -3,6 +3,7 @@
 package controller
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -185,6 +186,38 @@ func (c *OpenCodeClient) GetSessionMessages(ctx context.Context, serverURL, sess
 	return messages, nil
 }
 
+// SendPrompt queues a text prompt on an existing session without waiting for the reply.
+// If the session is busy, OpenCode processes the prompt after the current one.
+func (c *OpenCodeClient) SendPrompt(ctx context.Context, serverURL, sessionID, text string) error {
+	reqURL := fmt.Sprintf("%s/session/%s/prompt_async", serverURL, url.PathEscape(sessionID))
+
+	body, err := json.Marshal(map[string]any{
+		"parts": []map[string]string{{"type": "text", "text": text}},
+	})
+	if err != nil {
+		return fmt.Errorf("encoding prompt: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, reqURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending prompt: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	return nil
+}
+
 // AggregateMessageStats fetches messages for a session and aggregates
 // token usage, cost, and message count from assistant messages.
 func (c *OpenCodeClient) AggregateMessageStats(ctx context.Context, serverURL, sessionID string) (*AssistantMessageData, int32, error) {
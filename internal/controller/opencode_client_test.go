@@ -134,3 +134,42 @@ func TestFindSessionByTitle_EmptyResponse(t *testing.T) {
 		t.Errorf("expected nil session for empty response, got %+v", session)
 	}
 }
+
+func TestSendPrompt(t *testing.T) {
+	var gotPath string
+	var gotBody struct {
+		Parts []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"parts"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.Method + " " + r.URL.Path
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	client := NewOpenCodeClient()
+	if err := client.SendPrompt(context.Background(), srv.URL, "ses_1", "also update the README"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotPath != "POST /session/ses_1/prompt_async" {
+		t.Errorf("expected POST /session/ses_1/prompt_async, got %s", gotPath)
+	}
+	if len(gotBody.Parts) != 1 || gotBody.Parts[0].Type != "text" || gotBody.Parts[0].Text != "also update the README" {
+		t.Errorf("unexpected prompt body: %+v", gotBody)
+	}
+}
+
+func TestSendPrompt_ErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "session not found", http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	client := NewOpenCodeClient()
+	if err := client.SendPrompt(context.Background(), srv.URL, "ses_missing", "hello"); err == nil {
+		t.Fatal("expected error for 404 response")
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+)
+
+const (
+	// DefaultOrphanCollectionInterval is the default interval between orphaned resource sweeps
+	DefaultOrphanCollectionInterval = 10 * time.Minute
+
+	// orphanMinAge is the minimum age of a resource before it can be collected, so that
+	// resources of a Task that was just created are not mistaken for orphans
+	orphanMinAge = 5 * time.Minute
+)
+
+// OrphanCollector periodically deletes Pods and ConfigMaps labeled with a Task
+// (TaskLabelKey) whose Task no longer exists. OwnerReferences normally let the
+// garbage collector remove them with their Task; the sweep catches resources left
+// behind otherwise, e.g. when ownerReferences were removed or a Task was recreated
+// under the same name.
+type OrphanCollector struct {
+	client.Client
+	// APIReader confirms that a Task is gone without relying on the cache
+	APIReader client.Reader
+	// Interval between sweeps. Defaults to DefaultOrphanCollectionInterval.
+	Interval time.Duration
+	// Shard limits the sweep to the namespaces of this replica. Nil sweeps all.
+	Shard *Shard
+}
+
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;delete
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;delete
+
+// Start runs the sweeps until ctx is cancelled. It implements manager.Runnable.
+func (c *OrphanCollector) Start(ctx context.Context) error {
+	interval := c.Interval
+	if interval <= 0 {
+		interval = DefaultOrphanCollectionInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			c.Collect(ctx)
+		}
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable; only the leader sweeps.
+func (c *OrphanCollector) NeedLeaderElection() bool {
+	return true
+}
+
+// Collect runs one sweep. Errors are logged; the next sweep retries.
+func (c *OrphanCollector) Collect(ctx context.Context) {
+	logger := log.FromContext(ctx).WithName("orphan-collector")
+
+	pods := &corev1.PodList{}
+	if err := c.List(ctx, pods, client.HasLabels{TaskLabelKey}); err != nil {
+		logger.Error(err, "unable to list task pods")
+	} else {
+		for i := range pods.Items {
+			c.collect(ctx, &pods.Items[i], "Pod")
+		}
+	}
+
+	configMaps := &corev1.ConfigMapList{}
+	if err := c.List(ctx, configMaps, client.HasLabels{TaskLabelKey}); err != nil {
+		logger.Error(err, "unable to list task ConfigMaps")
+	} else {
+		for i := range configMaps.Items {
+			c.collect(ctx, &configMaps.Items[i], "ConfigMap")
+		}
+	}
+}
+
+// collect deletes obj if it is an orphan
+func (c *OrphanCollector) collect(ctx context.Context, obj client.Object, kind string) {
+	logger := log.FromContext(ctx).WithName("orphan-collector")
+
+	if obj.GetDeletionTimestamp() != nil || time.Since(obj.GetCreationTimestamp().Time) < orphanMinAge {
+		return
+	}
+	if !c.Shard.Owns(ctx, obj.GetNamespace()) {
+		return
+	}
+
+	orphaned, err := c.isOrphaned(ctx, obj)
+	if err != nil {
+		logger.Error(err, "unable to check Task of resource", "kind", kind, "namespace", obj.GetNamespace(), "name", obj.GetName())
+		return
+	}
+	if !orphaned {
+		return
+	}
+
+	logger.Info("deleting orphaned resource", "kind", kind, "namespace", obj.GetNamespace(), "name", obj.GetName(),
+		"task", obj.GetLabels()[TaskLabelKey])
+	// The UID precondition guards against deleting a replacement created meanwhile
+	uid := obj.GetUID()
+	if err := c.Delete(ctx, obj, client.Preconditions{UID: &uid}); err != nil {
+		if !errors.IsNotFound(err) && !errors.IsConflict(err) {
+			logger.Error(err, "unable to delete orphaned resource", "kind", kind, "namespace", obj.GetNamespace(), "name", obj.GetName())
+		}
+		return
+	}
+	OrphanedResourcesCollectedTotal.WithLabelValues(obj.GetNamespace(), kind).Inc()
+}
+
+// isOrphaned reports whether the Task of obj is gone. A Task with the labeled name
+// that is not the owner of obj (recreated under the same name) counts as gone.
+func (c *OrphanCollector) isOrphaned(ctx context.Context, obj client.Object) (bool, error) {
+	task := &kubeopenv1alpha1.Task{}
+	key := types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetLabels()[TaskLabelKey]}
+	if err := c.APIReader.Get(ctx, key, task); err != nil {
+		if errors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}
+	owner := metav1.GetControllerOf(obj)
+	return owner != nil && owner.Kind == "Task" && owner.UID != task.UID, nil
+}
@@ -0,0 +1,84 @@
+// Copyright Contributors to the KubeOpenCode project
+
+//go:build !integration
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+)
+
+func TestOrphanCollectorCollect(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = kubeopenv1alpha1.AddToScheme(scheme)
+
+	old := metav1.NewTime(time.Now().Add(-time.Hour))
+	task := &kubeopenv1alpha1.Task{ObjectMeta: metav1.ObjectMeta{Name: "live", Namespace: "ns", UID: "live-uid"}}
+	taskRef := func(name string, uid types.UID) []metav1.OwnerReference {
+		return []metav1.OwnerReference{{
+			APIVersion: kubeopenv1alpha1.SchemeGroupVersion.String(),
+			Kind:       "Task",
+			Name:       name,
+			UID:        uid,
+			Controller: boolPtr(true),
+		}}
+	}
+	meta := func(name, taskName string, created metav1.Time, owners []metav1.OwnerReference) metav1.ObjectMeta {
+		return metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "ns",
+			UID:               types.UID(name + "-uid"),
+			CreationTimestamp: created,
+			Labels:            map[string]string{TaskLabelKey: taskName},
+			OwnerReferences:   owners,
+		}
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		task,
+		&corev1.Pod{ObjectMeta: meta("live-pod", "live", old, taskRef("live", "live-uid"))},
+		&corev1.Pod{ObjectMeta: meta("gone-pod", "gone", old, nil)},
+		&corev1.Pod{ObjectMeta: meta("stale-pod", "live", old, taskRef("live", "previous-uid"))},
+		&corev1.Pod{ObjectMeta: meta("new-pod", "gone", metav1.Now(), nil)},
+		&corev1.ConfigMap{ObjectMeta: meta("live-context", "live", old, taskRef("live", "live-uid"))},
+		&corev1.ConfigMap{ObjectMeta: meta("gone-context", "gone", old, nil)},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "unlabeled", Namespace: "ns", CreationTimestamp: old}},
+	).Build()
+
+	collector := &OrphanCollector{Client: c, APIReader: c}
+	collector.Collect(context.Background())
+
+	tests := []struct {
+		obj  client.Object
+		name string
+		kept bool
+	}{
+		{obj: &corev1.Pod{}, name: "live-pod", kept: true},
+		{obj: &corev1.Pod{}, name: "gone-pod", kept: false},
+		{obj: &corev1.Pod{}, name: "stale-pod", kept: false},
+		{obj: &corev1.Pod{}, name: "new-pod", kept: true},
+		{obj: &corev1.ConfigMap{}, name: "live-context", kept: true},
+		{obj: &corev1.ConfigMap{}, name: "gone-context", kept: false},
+		{obj: &corev1.ConfigMap{}, name: "unlabeled", kept: true},
+	}
+	for _, tt := range tests {
+		err := c.Get(context.Background(), types.NamespacedName{Namespace: "ns", Name: tt.name}, tt.obj)
+		if kept := !errors.IsNotFound(err); kept != tt.kept {
+			t.Errorf("%s kept = %v, want %v (err: %v)", tt.name, kept, tt.kept, err)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -36,6 +37,9 @@ type agentConfig struct {
 	serviceAccountName string
 	maxConcurrentTasks *int32
 	quota              *kubeopenv1alpha1.QuotaConfig
+	preemptionPolicy   kubeopenv1alpha1.PreemptionPolicy
+	execution          *kubeopenv1alpha1.ExecutionConfig          // Execution backend for Task Pods (nil = Pod)
+	networkPolicy      *kubeopenv1alpha1.TaskNetworkPolicyConfig  // NetworkPolicy for Task Pods (nil = cluster default)
 	caBundle           *kubeopenv1alpha1.CABundleConfig           // Custom CA bundle configuration (nil = no custom CA)
 	proxy              *kubeopenv1alpha1.ProxyConfig              // HTTP/HTTPS proxy configuration (nil = no proxy)
 	imagePullSecrets   []corev1.LocalObjectReference              // Image pull secrets for private registries
@@ -46,6 +50,10 @@ type agentConfig struct {
 	serverReady        bool                                       // Whether Agent server is ready (from status)
 	extraEnv           []corev1.EnvVar                            // Extra env vars injected into ALL containers
 	systemContainers   *kubeopenv1alpha1.SystemContainerOverrides // Per-container-type env/mount overrides
+	failureSnapshot    *kubeopenv1alpha1.WorkspaceSnapshotConfig  // Workspace snapshot on failure (templateRef only)
+	resourceSnapshots  []resourceSnapshotSpec                     // Cluster resource snapshots from Runtime contexts (templateRef only)
+	vaultContexts      []vaultFetchSpec                           // Vault secrets from Vault contexts (templateRef only)
+	urlFetches         []urlFetchSpec                             // URL contexts to fetch (templateRef only)
 }
 
 // ResolveAgentConfig extracts configuration from the Agent spec.
@@ -65,6 +73,9 @@ func ResolveAgentConfig(agent *kubeopenv1alpha1.Agent) agentConfig {
 		serviceAccountName: agent.Spec.ServiceAccountName,
 		maxConcurrentTasks: agent.Spec.MaxConcurrentTasks,
 		quota:              agent.Spec.Quota,
+		preemptionPolicy:   agent.Spec.PreemptionPolicy,
+		execution:          agent.Spec.Execution,
+		networkPolicy:      agent.Spec.NetworkPolicy,
 		caBundle:           agent.Spec.CABundle,
 		proxy:              agent.Spec.Proxy,
 		imagePullSecrets:   agent.Spec.ImagePullSecrets,
@@ -104,6 +115,8 @@ func ResolveTemplateToConfig(tmpl *kubeopenv1alpha1.AgentTemplate) agentConfig {
 		proxy:              tmpl.Spec.Proxy,
 		imagePullSecrets:   tmpl.Spec.ImagePullSecrets,
 		extraPorts:         tmpl.Spec.ExtraPorts,
+		execution:          tmpl.Spec.Execution,
+		failureSnapshot:    tmpl.Spec.FailureSnapshot,
 	}
 	if tmpl.Spec.PodSpec != nil {
 		cfg.extraEnv = tmpl.Spec.PodSpec.ExtraEnv
@@ -130,6 +143,21 @@ type systemConfig struct {
 	// observability is the cluster-wide observability configuration from KubeOpenCodeConfig.
 	// When set and enabled, OTel env vars are injected into agent Pods.
 	observability *kubeopenv1alpha1.ObservabilitySpec
+	// contextCache is the cluster-wide Git context cache from KubeOpenCodeConfig.
+	// When set, git-init containers mount the cache PVC and reuse cached clones.
+	contextCache *kubeopenv1alpha1.ContextCacheConfig
+	// shareContextConfigMaps makes Tasks with identical contexts share one context ConfigMap.
+	shareContextConfigMaps bool
+	// podSecurityDefaults is the cluster-wide container hardening from KubeOpenCodeConfig.
+	// Agent-level security settings take precedence over it.
+	podSecurityDefaults *kubeopenv1alpha1.PodSecurityDefaults
+	// imageRewrites rewrite the image references of generated Pods (e.g. to a mirror).
+	imageRewrites []kubeopenv1alpha1.ImageRewrite
+	// networkPolicy isolates Task Pods with a NetworkPolicy.
+	// Agent-level networkPolicy takes precedence over it.
+	networkPolicy *kubeopenv1alpha1.TaskNetworkPolicyConfig
+	// outputs selects how Task Pods report captured outputs.
+	outputs *kubeopenv1alpha1.TaskOutputsConfig
 }
 
 // applySystemDefaults merges cluster-level configuration from KubeOpenCodeConfig
@@ -139,6 +167,9 @@ func (c *agentConfig) applySystemDefaults(sys systemConfig) {
 	if c.proxy == nil && sys.proxy != nil {
 		c.proxy = sys.proxy
 	}
+	if c.networkPolicy == nil && sys.networkPolicy != nil {
+		c.networkPolicy = sys.networkPolicy
+	}
 }
 
 // fileMount represents a file to be mounted at a specific path
@@ -152,6 +183,8 @@ type dirMount struct {
 	dirPath       string
 	configMapName string
 	optional      bool
+	items         []corev1.KeyToPath // Selected keys (empty = all keys)
+	fileMode      *int32             // Default file permission mode for copied files
 }
 
 // gitMount represents a Git repository to be cloned and mounted
@@ -184,6 +217,7 @@ type resolvedContext struct {
 	content   string // Resolved content
 	mountPath string // Mount path (empty = append to task.md)
 	fileMode  *int32 // Optional file permission mode (e.g., 0755 for executable)
+	snapshots string // Resource snapshot files of a Runtime context (listed for templateRef Tasks only)
 }
 
 // sanitizeConfigMapKey converts a file path to a valid ConfigMap key.
@@ -256,6 +290,10 @@ const (
 	// Used when Tasks connect to a persistent OpenCode server via --attach flag.
 	DefaultAttachImage = "ghcr.io/kubeopencode/kubeopencode-agent-attach:latest"
 
+	// RescheduledTaskPrompt is sent to the session of a rescheduled agentRef Task,
+	// whose previous Pod was lost to eviction or node failure mid-run.
+	RescheduledTaskPrompt = "The previous run of this task was interrupted because its Pod was lost. Continue the task from where it stopped."
+
 	// DefaultKubeOpenCodeImage is the default kubeopencode container image.
 	// This unified image provides: controller, git-init (Git clone), etc.
 	DefaultKubeOpenCodeImage = "ghcr.io/kubeopencode/kubeopencode:latest"
@@ -285,6 +323,12 @@ const (
 	// PluginsVolumeName is the name of the emptyDir volume for installed plugins.
 	PluginsVolumeName = "plugins-volume"
 
+	// ContextCacheVolumeName is the volume name for the shared context cache PVC
+	ContextCacheVolumeName = "context-cache"
+
+	// ContextCacheMountPath is where the context cache PVC is mounted in git-init containers
+	ContextCacheMountPath = "/kubeopencode-cache"
+
 	// OpenCodeConfigPath is the path where OpenCode config (server) is written
 	OpenCodeConfigPath = "/tools/opencode.json"
 
@@ -458,6 +502,14 @@ func buildGitInitContainer(gm gitMount, volumeName string, index int, sysCfg sys
 		})
 	}
 
+	// A repository subpath enables sparse checkout in git-init, so only that
+	// path is fetched and checked out instead of the whole tree.
+	if gm.repoPath != "" {
+		envVars = append(envVars, corev1.EnvVar{
+			Name: "GIT_REPO_SUBPATH", Value: gm.repoPath,
+		})
+	}
+
 	volumeMounts := []corev1.VolumeMount{
 		{Name: volumeName, MountPath: DefaultGitRoot},
 	}
@@ -466,6 +518,16 @@ func buildGitInitContainer(gm gitMount, volumeName string, index int, sysCfg sys
 		envVars = append(envVars, buildGitCredentialEnvVars(gm.secretName)...)
 	}
 
+	// Reuse cached clones when a context cache is configured
+	if sysCfg.contextCache != nil {
+		envVars = append(envVars, corev1.EnvVar{
+			Name: "GIT_CACHE_DIR", Value: ContextCacheMountPath + "/git",
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name: ContextCacheVolumeName, MountPath: ContextCacheMountPath,
+		})
+	}
+
 	return corev1.Container{
 		Name:            fmt.Sprintf("git-init-%d", index),
 		Image:           sysCfg.systemImage,
@@ -476,6 +538,18 @@ func buildGitInitContainer(gm gitMount, volumeName string, index int, sysCfg sys
 	}
 }
 
+// buildContextCacheVolume returns the PVC volume backing the shared context cache.
+func buildContextCacheVolume(cache *kubeopenv1alpha1.ContextCacheConfig) corev1.Volume {
+	return corev1.Volume{
+		Name: ContextCacheVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: cache.ClaimName,
+			},
+		},
+	}
+}
+
 // buildGitCredentialEnvVars returns env vars that reference a Secret for Git authentication.
 // The Secret can contain HTTPS credentials (username + password/PAT),
 // SSH credentials (ssh-privatekey + optional ssh-known-hosts), or both.
@@ -576,8 +650,10 @@ type contextInitFileMapping struct {
 // contextInitDirMapping represents a mapping from source directory to target directory.
 // This mirrors the DirMapping struct in cmd/kubeopencode/context_init.go.
 type contextInitDirMapping struct {
-	SourcePath string `json:"sourcePath"`
-	TargetPath string `json:"targetPath"`
+	SourcePath string           `json:"sourcePath"`
+	TargetPath string           `json:"targetPath"`
+	FileMode   *int32           `json:"fileMode,omitempty"`  // Default mode for all copied files
+	FileModes  map[string]int32 `json:"fileModes,omitempty"` // Per-file modes keyed by relative path
 }
 
 // buildContextInitContainer creates an init container that copies ConfigMap content to the writable workspace.
@@ -614,10 +690,20 @@ func buildContextInitContainer(workspaceDir string, fileMounts []fileMount, dirM
 	if len(dirMounts) > 0 {
 		mappings := make([]contextInitDirMapping, 0, len(dirMounts))
 		for i, dm := range dirMounts {
-			mappings = append(mappings, contextInitDirMapping{
+			mapping := contextInitDirMapping{
 				SourcePath: fmt.Sprintf("/configmap-dir-%d", i),
 				TargetPath: dm.dirPath,
-			})
+				FileMode:   dm.fileMode,
+			}
+			for _, item := range dm.items {
+				if item.Mode != nil {
+					if mapping.FileModes == nil {
+						mapping.FileModes = make(map[string]int32)
+					}
+					mapping.FileModes[item.Path] = *item.Mode
+				}
+			}
+			mappings = append(mappings, mapping)
 		}
 		mappingsJSON, _ := json.Marshal(mappings)
 		envVars = append(envVars, corev1.EnvVar{
@@ -1184,6 +1270,117 @@ func defaultSecurityContext() *corev1.SecurityContext {
 	}
 }
 
+// applyPodSecurityDefaults applies the cluster-wide pod security defaults to every
+// container of podSpec. Settings the Agent specifies on the Pod or container, and
+// defaults the Agent opts out of, are left untouched.
+func applyPodSecurityDefaults(podSpec *corev1.PodSpec, defaults *kubeopenv1alpha1.PodSecurityDefaults, agentPodSpec *kubeopenv1alpha1.AgentPodSpec) {
+	if defaults == nil {
+		return
+	}
+	var optOut []kubeopenv1alpha1.PodSecurityDefault
+	if agentPodSpec != nil {
+		optOut = agentPodSpec.PodSecurityDefaultsOptOut
+	}
+	if slices.Contains(optOut, kubeopenv1alpha1.PodSecurityDefaultAll) {
+		return
+	}
+	applies := func(d kubeopenv1alpha1.PodSecurityDefault) bool {
+		return !slices.Contains(optOut, d)
+	}
+
+	podSC := podSpec.SecurityContext
+	if podSC == nil {
+		podSC = &corev1.PodSecurityContext{}
+	}
+
+	harden := func(c *corev1.Container) {
+		// Copy, as the security context may be shared with the Agent spec
+		sc := c.SecurityContext.DeepCopy()
+		if sc == nil {
+			sc = &corev1.SecurityContext{}
+		}
+		if defaults.RunAsNonRoot != nil && applies(kubeopenv1alpha1.PodSecurityDefaultRunAsNonRoot) &&
+			sc.RunAsNonRoot == nil && podSC.RunAsNonRoot == nil {
+			sc.RunAsNonRoot = boolPtr(*defaults.RunAsNonRoot)
+		}
+		if defaults.SeccompProfile != nil && applies(kubeopenv1alpha1.PodSecurityDefaultSeccompProfile) &&
+			sc.SeccompProfile == nil && podSC.SeccompProfile == nil {
+			sc.SeccompProfile = defaults.SeccompProfile.DeepCopy()
+		}
+		if defaults.ReadOnlyRootFilesystem != nil && applies(kubeopenv1alpha1.PodSecurityDefaultReadOnlyRootFilesystem) &&
+			sc.ReadOnlyRootFilesystem == nil {
+			sc.ReadOnlyRootFilesystem = boolPtr(*defaults.ReadOnlyRootFilesystem)
+		}
+		if len(defaults.DropCapabilities) > 0 && applies(kubeopenv1alpha1.PodSecurityDefaultDropCapabilities) {
+			if sc.Capabilities == nil {
+				sc.Capabilities = &corev1.Capabilities{}
+			}
+			for _, capability := range defaults.DropCapabilities {
+				if !slices.Contains(sc.Capabilities.Drop, capability) {
+					sc.Capabilities.Drop = append(sc.Capabilities.Drop, capability)
+				}
+			}
+		}
+		c.SecurityContext = sc
+	}
+	for i := range podSpec.InitContainers {
+		harden(&podSpec.InitContainers[i])
+	}
+	for i := range podSpec.Containers {
+		harden(&podSpec.Containers[i])
+	}
+}
+
+// applyImageRewrites rewrites the images of all containers of podSpec.
+func applyImageRewrites(podSpec *corev1.PodSpec, rewrites []kubeopenv1alpha1.ImageRewrite) {
+	if len(rewrites) == 0 {
+		return
+	}
+	for i := range podSpec.InitContainers {
+		podSpec.InitContainers[i].Image = rewriteImage(podSpec.InitContainers[i].Image, rewrites)
+	}
+	for i := range podSpec.Containers {
+		podSpec.Containers[i].Image = rewriteImage(podSpec.Containers[i].Image, rewrites)
+	}
+}
+
+// rewriteImage replaces the prefix of image matched by the rewrite with the longest
+// prefix. Images without a registry are also matched in their fully qualified form.
+func rewriteImage(image string, rewrites []kubeopenv1alpha1.ImageRewrite) string {
+	refs := []string{image}
+	if qualified := qualifyImage(image); qualified != image {
+		refs = append(refs, qualified)
+	}
+
+	var match *kubeopenv1alpha1.ImageRewrite
+	var matchedRef string
+	for _, ref := range refs {
+		for i := range rewrites {
+			rewrite := &rewrites[i]
+			if strings.HasPrefix(ref, rewrite.Prefix) && (match == nil || len(rewrite.Prefix) > len(match.Prefix)) {
+				match, matchedRef = rewrite, ref
+			}
+		}
+	}
+	if match == nil {
+		return image
+	}
+	return match.Replacement + strings.TrimPrefix(matchedRef, match.Prefix)
+}
+
+// qualifyImage makes the implicit Docker Hub registry and library namespace of
+// image explicit, e.g. "alpine:3" becomes "docker.io/library/alpine:3".
+func qualifyImage(image string) string {
+	first, _, found := strings.Cut(image, "/")
+	if !found {
+		return "docker.io/library/" + image
+	}
+	if strings.ContainsAny(first, ".:") || first == "localhost" {
+		return image
+	}
+	return "docker.io/" + image
+}
+
 // applyInitContainerOverrides appends extra env vars and volume mounts from
 // InitContainerOverrides to a container. It is a no-op when overrides is nil.
 // Overrides are appended after controller-managed values so users can override
@@ -1384,6 +1581,7 @@ func buildPod(task *kubeopenv1alpha1.Task, podName string, cfg agentConfig, cont
 					LocalObjectReference: corev1.LocalObjectReference{
 						Name: dm.configMapName,
 					},
+					Items:    dm.items,
 					Optional: &dm.optional,
 				},
 			},
@@ -1489,11 +1687,6 @@ func buildPod(task *kubeopenv1alpha1.Task, podName string, cfg agentConfig, cont
 			gitInitContainer.Env = append(gitInitContainer.Env,
 				corev1.EnvVar{Name: "GIT_WORKSPACE_DIR", Value: cfg.workspaceDir},
 			)
-			if gm.repoPath != "" {
-				gitInitContainer.Env = append(gitInitContainer.Env,
-					corev1.EnvVar{Name: "GIT_REPO_SUBPATH", Value: gm.repoPath},
-				)
-			}
 			gitInitContainer.VolumeMounts = append(gitInitContainer.VolumeMounts,
 				corev1.VolumeMount{
 					Name:      WorkspaceVolumeName,
@@ -1534,6 +1727,30 @@ func buildPod(task *kubeopenv1alpha1.Task, podName string, cfg agentConfig, cont
 		}
 	}
 
+	// Add the shared context cache volume used by git-init containers
+	if len(gitMounts) > 0 && sysCfg.contextCache != nil {
+		volumes = append(volumes, buildContextCacheVolume(sysCfg.contextCache))
+	}
+
+	// Snapshot cluster resources into the workspace with the Pod's ServiceAccount.
+	// Only for templateRef tasks: agentRef Pods do not own the agent's workspace.
+	if len(cfg.resourceSnapshots) > 0 && serverURL == "" {
+		initContainers = append(initContainers, buildResourceSnapshotContainer(cfg.resourceSnapshots, cfg.workspaceDir, sysCfg))
+	}
+
+	// Fetch Vault secrets into the workspace with the Pod's ServiceAccount.
+	// Runs after context-init so that secret file modes are not relaxed.
+	if len(cfg.vaultContexts) > 0 && serverURL == "" {
+		initContainers = append(initContainers, buildVaultFetchContainer(cfg.vaultContexts, cfg.workspaceDir, sysCfg))
+	}
+
+	// Fetch URL contexts into the workspace, verifying checksums where declared
+	if serverURL == "" {
+		for i, spec := range cfg.urlFetches {
+			initContainers = append(initContainers, buildURLFetchContainer(i, spec, cfg.workspaceDir, sysCfg))
+		}
+	}
+
 	// Add plugin-init container and plugins volume if plugins are configured.
 	// The plugin-init container runs `npm install` in the shared /plugins volume,
 	// so the executor container can load plugins from file:// paths without npm.
@@ -1649,6 +1866,14 @@ func buildPod(task *kubeopenv1alpha1.Task, podName string, cfg agentConfig, cont
 				"sh", "-c",
 				fmt.Sprintf(`%s; /tools/opencode run --attach %s --title %s "$(cat %s/task.md)"`, OpenCodeSymlinkCmd, serverURL, shellEscape(sessionTitle), cfg.workspaceDir),
 			}
+			if task.Status.Reschedules > 0 && task.Status.Session != nil && task.Status.Session.ID != "" {
+				// Rescheduled after a disruption: continue the session of the lost Pod,
+				// which lives on in the Agent's server
+				agentCommand = []string{
+					"sh", "-c",
+					fmt.Sprintf(`%s; /tools/opencode run --attach %s --session %s %s`, OpenCodeSymlinkCmd, serverURL, shellEscape(task.Status.Session.ID), shellEscape(RescheduledTaskPrompt)),
+				}
+			}
 		} else {
 			// templateRef path: run standalone OpenCode instance.
 			// Pre-warm the models cache to avoid ProviderModelNotFoundError on cold starts
@@ -1659,6 +1884,24 @@ func buildPod(task *kubeopenv1alpha1.Task, podName string, cfg agentConfig, cont
 			}
 		}
 	}
+	// Archive the workspace to the snapshot PVC if the agent command fails.
+	// Only for templateRef tasks: agentRef Pods have no workspace of their own.
+	if cfg.failureSnapshot != nil && serverURL == "" {
+		agentCommand = wrapCommandWithFailureSnapshot(agentCommand, cfg.failureSnapshot)
+		snapshotVolume, snapshotMount := buildFailureSnapshotVolume(cfg.failureSnapshot)
+		volumes = append(volumes, snapshotVolume)
+		volumeMounts = append(volumeMounts, snapshotMount)
+	}
+	// Report the outputs directory through the termination message for TaskOutput contexts.
+	// Applied last so that it wraps the failure snapshot wrapper.
+	if task.Spec.CaptureOutputs && serverURL == "" {
+		var outputsServerURL string
+		if sysCfg.outputs != nil && sysCfg.outputs.Delivery == kubeopenv1alpha1.TaskOutputsDeliveryServer {
+			outputsServerURL = sysCfg.outputs.ServerURL
+		}
+		agentCommand = wrapCommandWithOutputCapture(agentCommand, outputsServerURL)
+	}
+
 	// Determine executor image: use lightweight attach image only for agentRef tasks
 	// that use the default --attach command. When a custom command is provided,
 	// keep the executor image since the custom command may need tools not available
@@ -1761,6 +2004,9 @@ func buildPod(task *kubeopenv1alpha1.Task, podName string, cfg agentConfig, cont
 		}
 	}
 
+	applyPodSecurityDefaults(&podSpec, sysCfg.podSecurityDefaults, cfg.podSpec)
+	applyImageRewrites(&podSpec, sysCfg.imageRewrites)
+
 	pod := &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      podName,
@@ -6,6 +6,7 @@ package controller
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 	"testing"
 
@@ -907,6 +908,74 @@ func TestBuildPod_WithDirMounts(t *testing.T) {
 	}
 }
 
+func TestBuildPod_WithDirMountItems(t *testing.T) {
+	task := &kubeopenv1alpha1.Task{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-task",
+			Namespace: "default",
+			UID:       types.UID("test-uid"),
+		},
+	}
+	task.APIVersion = "kubeopencode.io/v1alpha1"
+	task.Kind = "Task"
+
+	cfg := agentConfig{
+		agentImage:         "test-opencode:v1.0.0",
+		executorImage:      "test-executor:v1.0.0",
+		workspaceDir:       "/workspace",
+		serviceAccountName: "test-sa",
+	}
+
+	execMode := int32(0755)
+	defaultMode := int32(0600)
+	dirMounts := []dirMount{
+		{
+			dirPath:       "/workspace/tools",
+			configMapName: "scripts",
+			items: []corev1.KeyToPath{
+				{Key: "run.sh", Path: "bin/run", Mode: &execMode},
+				{Key: "notes.md", Path: "notes.md"},
+			},
+			fileMode: &defaultMode,
+		},
+	}
+
+	pod := buildPod(task, "test-task-pod", cfg, nil, nil, dirMounts, nil, defaultSystemConfig(), "")
+
+	var volume *corev1.Volume
+	for i := range pod.Spec.Volumes {
+		if pod.Spec.Volumes[i].Name == "dir-mount-0" {
+			volume = &pod.Spec.Volumes[i]
+		}
+	}
+	if volume == nil || volume.ConfigMap == nil {
+		t.Fatal("dir-mount-0 ConfigMap volume not found")
+	}
+	if len(volume.ConfigMap.Items) != 2 || volume.ConfigMap.Items[0].Path != "bin/run" {
+		t.Errorf("dir-mount-0 volume Items = %+v, want selected keys", volume.ConfigMap.Items)
+	}
+
+	var contextInit *corev1.Container
+	for i := range pod.Spec.InitContainers {
+		if pod.Spec.InitContainers[i].Name == "context-init" {
+			contextInit = &pod.Spec.InitContainers[i]
+		}
+	}
+	if contextInit == nil {
+		t.Fatal("context-init init container not found")
+	}
+	var dirMappings string
+	for _, env := range contextInit.Env {
+		if env.Name == "DIR_MAPPINGS" {
+			dirMappings = env.Value
+		}
+	}
+	want := `[{"sourcePath":"/configmap-dir-0","targetPath":"/workspace/tools","fileMode":384,"fileModes":{"bin/run":493}}]`
+	if dirMappings != want {
+		t.Errorf("DIR_MAPPINGS = %s, want %s", dirMappings, want)
+	}
+}
+
 func TestBuildPod_WithGitMounts(t *testing.T) {
 	task := &kubeopenv1alpha1.Task{
 		ObjectMeta: metav1.ObjectMeta{
@@ -1139,6 +1208,9 @@ func TestBuildGitInitContainer(t *testing.T) {
 	if envMap["GIT_DEPTH"] != "5" {
 		t.Errorf("GIT_DEPTH = %q, want %q", envMap["GIT_DEPTH"], "5")
 	}
+	if envMap["GIT_REPO_SUBPATH"] != "docs/" {
+		t.Errorf("GIT_REPO_SUBPATH = %q, want %q", envMap["GIT_REPO_SUBPATH"], "docs/")
+	}
 
 	// Verify volume mount
 	if len(container.VolumeMounts) != 1 {
@@ -1973,6 +2045,44 @@ func TestBuildPod_AgentRef_WithAttachCommand(t *testing.T) {
 	}
 }
 
+func TestBuildPod_AgentRef_RescheduledContinuesSession(t *testing.T) {
+	task := &kubeopenv1alpha1.Task{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-task",
+			Namespace: "default",
+			UID:       types.UID("test-uid"),
+		},
+		Status: kubeopenv1alpha1.TaskExecutionStatus{
+			Reschedules: 1,
+			Session:     &kubeopenv1alpha1.SessionInfo{Title: "kubeopencode/default/test-task/test-uid", ID: "ses_123"},
+		},
+	}
+
+	cfg := agentConfig{
+		executorImage:      "test-executor:v1.0.0",
+		workspaceDir:       "/workspace",
+		serviceAccountName: "test-sa",
+	}
+
+	serverURL := "http://test-agent.default.svc.cluster.local:4096"
+	pod := buildPod(task, "test-task-pod-1", cfg, nil, nil, nil, nil, defaultSystemConfig(), serverURL)
+
+	command := pod.Spec.Containers[0].Command[2]
+	if !strings.Contains(command, "--attach "+serverURL+" --session 'ses_123'") {
+		t.Errorf("Command should continue the session, got: %s", command)
+	}
+	if strings.Contains(command, "task.md") {
+		t.Errorf("Command should not resend the task, got: %s", command)
+	}
+
+	// Without a resolved session, the rescheduled Task starts over
+	task.Status.Session.ID = ""
+	pod = buildPod(task, "test-task-pod-1", cfg, nil, nil, nil, nil, defaultSystemConfig(), serverURL)
+	if command := pod.Spec.Containers[0].Command[2]; !strings.Contains(command, "$(cat /workspace/task.md)") {
+		t.Errorf("Command should run the task from scratch, got: %s", command)
+	}
+}
+
 func TestBuildPod_AgentRef_WithCustomCommand_KeepsExecutorImage(t *testing.T) {
 	task := &kubeopenv1alpha1.Task{
 		ObjectMeta: metav1.ObjectMeta{
@@ -3348,6 +3458,141 @@ func TestBuildPodSecurityContext(t *testing.T) {
 	})
 }
 
+func TestBuildPodSecurityDefaults(t *testing.T) {
+	task := &kubeopenv1alpha1.Task{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-task", Namespace: "default", UID: "test-uid"},
+	}
+	task.APIVersion = "kubeopencode.io/v1alpha1"
+	task.Kind = "Task"
+
+	sysCfg := defaultSystemConfig()
+	sysCfg.podSecurityDefaults = &kubeopenv1alpha1.PodSecurityDefaults{
+		RunAsNonRoot:           boolPtr(true),
+		ReadOnlyRootFilesystem: boolPtr(true),
+		SeccompProfile:         &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+		DropCapabilities:       []corev1.Capability{"ALL", "NET_RAW"},
+	}
+	newConfig := func(podSpec *kubeopenv1alpha1.AgentPodSpec) agentConfig {
+		return agentConfig{
+			agentImage:    "test-opencode:v1.0.0",
+			executorImage: "test-executor:v1.0.0",
+			workspaceDir:  "/workspace",
+			podSpec:       podSpec,
+		}
+	}
+
+	t.Run("defaults apply to all containers", func(t *testing.T) {
+		pod := buildPod(task, "test-task-pod", newConfig(nil), nil, nil, nil, nil, sysCfg, "")
+
+		for _, c := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
+			sc := c.SecurityContext
+			if sc.RunAsNonRoot == nil || !*sc.RunAsNonRoot {
+				t.Errorf("container %s: RunAsNonRoot = %v, want true", c.Name, sc.RunAsNonRoot)
+			}
+			if sc.ReadOnlyRootFilesystem == nil || !*sc.ReadOnlyRootFilesystem {
+				t.Errorf("container %s: ReadOnlyRootFilesystem = %v, want true", c.Name, sc.ReadOnlyRootFilesystem)
+			}
+			if !slices.Equal(sc.Capabilities.Drop, []corev1.Capability{"ALL", "NET_RAW"}) {
+				t.Errorf("container %s: Capabilities.Drop = %v, want [ALL NET_RAW]", c.Name, sc.Capabilities.Drop)
+			}
+		}
+	})
+
+	t.Run("agent settings take precedence", func(t *testing.T) {
+		agentSC := &corev1.SecurityContext{ReadOnlyRootFilesystem: boolPtr(false)}
+		podSpec := &kubeopenv1alpha1.AgentPodSpec{
+			SecurityContext: agentSC,
+			PodSecurityContext: &corev1.PodSecurityContext{
+				SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeUnconfined},
+			},
+		}
+		pod := buildPod(task, "test-task-pod", newConfig(podSpec), nil, nil, nil, nil, sysCfg, "")
+
+		sc := pod.Spec.Containers[0].SecurityContext
+		if *sc.ReadOnlyRootFilesystem {
+			t.Error("ReadOnlyRootFilesystem should keep the Agent's false")
+		}
+		if sc.SeccompProfile != nil {
+			t.Errorf("SeccompProfile = %v, want unset so the Pod-level profile applies", sc.SeccompProfile)
+		}
+		if sc.RunAsNonRoot == nil || !*sc.RunAsNonRoot {
+			t.Errorf("RunAsNonRoot = %v, want true", sc.RunAsNonRoot)
+		}
+		if agentSC.RunAsNonRoot != nil || agentSC.Capabilities != nil {
+			t.Error("the Agent's security context must not be modified")
+		}
+	})
+
+	t.Run("opt-outs", func(t *testing.T) {
+		podSpec := &kubeopenv1alpha1.AgentPodSpec{
+			PodSecurityDefaultsOptOut: []kubeopenv1alpha1.PodSecurityDefault{kubeopenv1alpha1.PodSecurityDefaultReadOnlyRootFilesystem},
+		}
+		pod := buildPod(task, "test-task-pod", newConfig(podSpec), nil, nil, nil, nil, sysCfg, "")
+		sc := pod.Spec.Containers[0].SecurityContext
+		if sc.ReadOnlyRootFilesystem != nil {
+			t.Errorf("ReadOnlyRootFilesystem = %v, want unset", *sc.ReadOnlyRootFilesystem)
+		}
+		if sc.RunAsNonRoot == nil || !*sc.RunAsNonRoot {
+			t.Errorf("RunAsNonRoot = %v, want true", sc.RunAsNonRoot)
+		}
+
+		podSpec.PodSecurityDefaultsOptOut = []kubeopenv1alpha1.PodSecurityDefault{kubeopenv1alpha1.PodSecurityDefaultAll}
+		pod = buildPod(task, "test-task-pod", newConfig(podSpec), nil, nil, nil, nil, sysCfg, "")
+		sc = pod.Spec.Containers[0].SecurityContext
+		if sc.RunAsNonRoot != nil || sc.ReadOnlyRootFilesystem != nil {
+			t.Errorf("no defaults should apply with All, got %+v", sc)
+		}
+	})
+}
+
+func TestRewriteImage(t *testing.T) {
+	rewrites := []kubeopenv1alpha1.ImageRewrite{
+		{Prefix: "ghcr.io/", Replacement: "mirror.example.com/ghcr/"},
+		{Prefix: "ghcr.io/kubeopencode/", Replacement: "mirror.example.com/kubeopencode/"},
+		{Prefix: "docker.io/", Replacement: "mirror.example.com/dockerhub/"},
+	}
+	tests := []struct {
+		image string
+		want  string
+	}{
+		{image: "ghcr.io/other/tool:v1", want: "mirror.example.com/ghcr/other/tool:v1"},
+		{image: DefaultKubeOpenCodeImage, want: "mirror.example.com/kubeopencode/kubeopencode:latest"},
+		{image: "alpine:3", want: "mirror.example.com/dockerhub/library/alpine:3"},
+		{image: "bitnami/kubectl:1.30", want: "mirror.example.com/dockerhub/bitnami/kubectl:1.30"},
+		{image: "docker.io/library/busybox", want: "mirror.example.com/dockerhub/library/busybox"},
+		{image: "quay.io/prometheus/busybox", want: "quay.io/prometheus/busybox"},
+		{image: "localhost:5000/dev/app", want: "localhost:5000/dev/app"},
+	}
+	for _, tt := range tests {
+		if got := rewriteImage(tt.image, rewrites); got != tt.want {
+			t.Errorf("rewriteImage(%q) = %q, want %q", tt.image, got, tt.want)
+		}
+	}
+}
+
+func TestBuildPodImageRewrites(t *testing.T) {
+	task := &kubeopenv1alpha1.Task{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-task", Namespace: "default", UID: "test-uid"},
+	}
+	task.APIVersion = "kubeopencode.io/v1alpha1"
+	task.Kind = "Task"
+	cfg := agentConfig{
+		agentImage:    DefaultAgentImage,
+		executorImage: DefaultExecutorImage,
+		workspaceDir:  "/workspace",
+	}
+	sysCfg := defaultSystemConfig()
+	sysCfg.imageRewrites = []kubeopenv1alpha1.ImageRewrite{{Prefix: "ghcr.io/", Replacement: "mirror.example.com/ghcr/"}}
+
+	pod := buildPod(task, "test-task-pod", cfg, nil, nil, nil, nil, sysCfg, "")
+
+	for _, c := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
+		if !strings.HasPrefix(c.Image, "mirror.example.com/ghcr/") {
+			t.Errorf("container %s image = %q, want it rewritten to the mirror", c.Name, c.Image)
+		}
+	}
+}
+
 func TestBuildPod_WithExtraVolumesAndMounts(t *testing.T) {
 	task := &kubeopenv1alpha1.Task{
 		ObjectMeta: metav1.ObjectMeta{
@@ -4758,3 +5003,259 @@ func TestBuildOTelEnvVars_DeterministicOutput(t *testing.T) {
 		t.Errorf("headers should be sorted, expected a-header first, got: %s", headersValue)
 	}
 }
+
+func TestBuildPod_WithFailureSnapshot(t *testing.T) {
+	task := &kubeopenv1alpha1.Task{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-task",
+			Namespace: "default",
+		},
+		Spec: kubeopenv1alpha1.TaskSpec{
+			Description: ptr.To("test"),
+			TemplateRef: &kubeopenv1alpha1.AgentTemplateReference{Name: "test-template"},
+		},
+	}
+	snapshot := &kubeopenv1alpha1.WorkspaceSnapshotConfig{
+		ClaimName: "debug-snapshots",
+		MaxSize:   "100Mi",
+		Retention: ptr.To(int32(3)),
+	}
+
+	cfg := agentConfig{
+		agentImage:         "test-agent:v1.0.0",
+		executorImage:      "test-executor:v1.0.0",
+		command:            []string{"sh", "-c", "echo hello"},
+		workspaceDir:       "/workspace",
+		serviceAccountName: "test-sa",
+		failureSnapshot:    snapshot,
+	}
+
+	t.Run("templateRef wraps command and mounts PVC", func(t *testing.T) {
+		pod := buildPod(task, "test-task-pod", cfg, nil, nil, nil, nil, defaultSystemConfig(), "")
+		container := pod.Spec.Containers[0]
+
+		if len(container.Command) != 7 || container.Command[0] != "sh" || container.Command[3] != "sh" {
+			t.Fatalf("Command = %v, want sh -c <script> sh <original...>", container.Command)
+		}
+		if got := container.Command[4:]; got[0] != "sh" || got[1] != "-c" || got[2] != "echo hello" {
+			t.Errorf("original command = %v, want [sh -c echo hello]", got)
+		}
+		script := container.Command[2]
+		for _, want := range []string{
+			WorkspaceSnapshotMountPath + "/${TASK_NAMESPACE}",
+			"-gt 102400 ",
+			"tail -n +4",
+			"exit $rc",
+		} {
+			if !strings.Contains(script, want) {
+				t.Errorf("snapshot script missing %q:\n%s", want, script)
+			}
+		}
+
+		foundVolume := false
+		for _, v := range pod.Spec.Volumes {
+			if v.Name == WorkspaceSnapshotVolumeName {
+				foundVolume = true
+				if v.PersistentVolumeClaim == nil || v.PersistentVolumeClaim.ClaimName != "debug-snapshots" {
+					t.Errorf("snapshot volume = %+v, want PVC debug-snapshots", v.VolumeSource)
+				}
+			}
+		}
+		if !foundVolume {
+			t.Error("expected workspace-snapshots volume")
+		}
+
+		foundMount := false
+		for _, m := range container.VolumeMounts {
+			if m.Name == WorkspaceSnapshotVolumeName && m.MountPath == WorkspaceSnapshotMountPath {
+				foundMount = true
+			}
+		}
+		if !foundMount {
+			t.Error("expected workspace-snapshots mount on agent container")
+		}
+	})
+
+	t.Run("agentRef skips snapshot", func(t *testing.T) {
+		pod := buildPod(task, "test-task-pod", cfg, nil, nil, nil, nil, defaultSystemConfig(), "http://server:4096")
+		for _, v := range pod.Spec.Volumes {
+			if v.Name == WorkspaceSnapshotVolumeName {
+				t.Error("agentRef Pod should not mount the snapshot volume")
+			}
+		}
+		if strings.Contains(strings.Join(pod.Spec.Containers[0].Command, " "), workspaceSnapshotMessagePrefix) {
+			t.Error("agentRef Pod command should not be wrapped")
+		}
+	})
+}
+
+func TestResolveFailureSnapshot(t *testing.T) {
+	templateSnapshot := &kubeopenv1alpha1.WorkspaceSnapshotConfig{ClaimName: "template-claim"}
+	taskSnapshot := &kubeopenv1alpha1.WorkspaceSnapshotConfig{ClaimName: "task-claim"}
+
+	tests := []struct {
+		name      string
+		task      *kubeopenv1alpha1.WorkspaceSnapshotConfig
+		template  *kubeopenv1alpha1.WorkspaceSnapshotConfig
+		wantClaim string
+		wantErr   bool
+	}{
+		{name: "none configured"},
+		{name: "template default", template: templateSnapshot, wantClaim: "template-claim"},
+		{name: "task overrides template", task: taskSnapshot, template: templateSnapshot, wantClaim: "task-claim"},
+		{name: "invalid maxSize", task: &kubeopenv1alpha1.WorkspaceSnapshotConfig{ClaimName: "c", MaxSize: "lots"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &kubeopenv1alpha1.Task{Spec: kubeopenv1alpha1.TaskSpec{FailureSnapshot: tt.task}}
+			got, err := resolveFailureSnapshot(task, agentConfig{failureSnapshot: tt.template})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveFailureSnapshot() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			gotClaim := ""
+			if got != nil {
+				gotClaim = got.ClaimName
+			}
+			if gotClaim != tt.wantClaim {
+				t.Errorf("claimName = %q, want %q", gotClaim, tt.wantClaim)
+			}
+		})
+	}
+}
+
+func TestBuildPod_WithContextCache(t *testing.T) {
+	task := &kubeopenv1alpha1.Task{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-task",
+			Namespace: "default",
+		},
+		Spec: kubeopenv1alpha1.TaskSpec{
+			Description: ptr.To("test"),
+		},
+	}
+	cfg := agentConfig{
+		agentImage:         "test-agent:v1.0.0",
+		executorImage:      "test-executor:v1.0.0",
+		workspaceDir:       "/workspace",
+		serviceAccountName: "test-sa",
+	}
+	gitMounts := []gitMount{
+		{contextName: "git-0", repository: "https://github.com/example/a.git", mountPath: "/workspace/a"},
+		{contextName: "git-1", repository: "https://github.com/example/b.git", mountPath: "/workspace/b"},
+	}
+	sysCfg := defaultSystemConfig()
+	sysCfg.contextCache = &kubeopenv1alpha1.ContextCacheConfig{ClaimName: "context-cache-pvc"}
+
+	pod := buildPod(task, "test-task-pod", cfg, nil, nil, nil, gitMounts, sysCfg, "")
+
+	cacheVolumes := 0
+	for _, v := range pod.Spec.Volumes {
+		if v.Name == ContextCacheVolumeName {
+			cacheVolumes++
+			if v.PersistentVolumeClaim == nil || v.PersistentVolumeClaim.ClaimName != "context-cache-pvc" {
+				t.Errorf("cache volume = %+v, want PVC context-cache-pvc", v.VolumeSource)
+			}
+		}
+	}
+	if cacheVolumes != 1 {
+		t.Errorf("cache volume count = %d, want 1", cacheVolumes)
+	}
+
+	gitInits := 0
+	for _, ic := range pod.Spec.InitContainers {
+		if !strings.HasPrefix(ic.Name, "git-init") {
+			continue
+		}
+		gitInits++
+		hasEnv, hasMount := false, false
+		for _, env := range ic.Env {
+			if env.Name == "GIT_CACHE_DIR" && env.Value == ContextCacheMountPath+"/git" {
+				hasEnv = true
+			}
+		}
+		for _, m := range ic.VolumeMounts {
+			if m.Name == ContextCacheVolumeName && m.MountPath == ContextCacheMountPath {
+				hasMount = true
+			}
+		}
+		if !hasEnv || !hasMount {
+			t.Errorf("%s: GIT_CACHE_DIR env = %v, cache mount = %v, want both", ic.Name, hasEnv, hasMount)
+		}
+	}
+	if gitInits != 2 {
+		t.Errorf("git-init container count = %d, want 2", gitInits)
+	}
+
+	// Without git mounts, the cache volume is not added
+	pod = buildPod(task, "test-task-pod", cfg, nil, nil, nil, nil, sysCfg, "")
+	for _, v := range pod.Spec.Volumes {
+		if v.Name == ContextCacheVolumeName {
+			t.Error("cache volume should not be added without git contexts")
+		}
+	}
+}
+
+func TestBuildPod_WithResourceSnapshots(t *testing.T) {
+	task := &kubeopenv1alpha1.Task{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-task",
+			Namespace: "default",
+		},
+		Spec: kubeopenv1alpha1.TaskSpec{
+			Description: ptr.To("test"),
+		},
+	}
+	cfg := agentConfig{
+		agentImage:         "test-agent:v1.0.0",
+		executorImage:      "test-executor:v1.0.0",
+		workspaceDir:       "/workspace",
+		serviceAccountName: "test-sa",
+		resourceSnapshots: []resourceSnapshotSpec{
+			{TargetPath: "/workspace/.kubeopencode/cluster-state/web.yaml", APIVersion: "apps/v1", Kind: "Deployment", Namespace: "default"},
+		},
+	}
+
+	t.Run("templateRef adds resource-snapshot init container", func(t *testing.T) {
+		pod := buildPod(task, "test-task-pod", cfg, nil, nil, nil, nil, defaultSystemConfig(), "")
+
+		var container *corev1.Container
+		for i := range pod.Spec.InitContainers {
+			if pod.Spec.InitContainers[i].Name == ResourceSnapshotContainerName {
+				container = &pod.Spec.InitContainers[i]
+			}
+		}
+		if container == nil {
+			t.Fatal("expected resource-snapshot init container")
+		}
+		if got := strings.Join(container.Command, " "); got != "/kubeopencode resource-snapshot" {
+			t.Errorf("Command = %q", got)
+		}
+		envMap := make(map[string]string)
+		for _, env := range container.Env {
+			envMap[env.Name] = env.Value
+		}
+		want := `[{"targetPath":"/workspace/.kubeopencode/cluster-state/web.yaml","apiVersion":"apps/v1","kind":"Deployment","namespace":"default"}]`
+		if envMap["RESOURCE_SNAPSHOTS"] != want {
+			t.Errorf("RESOURCE_SNAPSHOTS = %s, want %s", envMap["RESOURCE_SNAPSHOTS"], want)
+		}
+		foundWorkspace := false
+		for _, m := range container.VolumeMounts {
+			if m.Name == WorkspaceVolumeName && m.MountPath == "/workspace" {
+				foundWorkspace = true
+			}
+		}
+		if !foundWorkspace {
+			t.Error("expected workspace volume mount on resource-snapshot container")
+		}
+	})
+
+	t.Run("agentRef skips resource snapshots", func(t *testing.T) {
+		pod := buildPod(task, "test-task-pod", cfg, nil, nil, nil, nil, defaultSystemConfig(), "http://server:4096")
+		for _, ic := range pod.Spec.InitContainers {
+			if ic.Name == ResourceSnapshotContainerName {
+				t.Error("agentRef Pod should not collect resource snapshots")
+			}
+		}
+	})
+}
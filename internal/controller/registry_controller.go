@@ -43,6 +43,8 @@ type RegistryReconciler struct {
 	client.Client
 	Scheme     *runtime.Scheme
 	HTTPClient *http.Client
+	// Shard limits reconciliation to the namespaces of this replica. Nil reconciles all.
+	Shard *Shard
 }
 
 // +kubebuilder:rbac:groups=kubeopencode.io,resources=registries,verbs=get;list;watch;update;patch
@@ -473,5 +475,6 @@ func parseNpmSpec(spec string) (string, string) {
 func (r *RegistryReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&kubeopenv1alpha1.Registry{}).
+		WithEventFilter(r.Shard.Predicate()).
 		Complete(r)
 }
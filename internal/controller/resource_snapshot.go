@@ -0,0 +1,110 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+)
+
+const (
+	// DefaultResourceSnapshotDir is the directory (relative to workspaceDir) for resource
+	// snapshots of a Runtime context without mountPath.
+	DefaultResourceSnapshotDir = ".kubeopencode/cluster-state"
+
+	// ResourceSnapshotContainerName is the name of the init container that collects resource snapshots
+	ResourceSnapshotContainerName = "resource-snapshot"
+)
+
+// resourceSnapshotSpec is a single resource snapshot passed to the resource-snapshot
+// init container via the RESOURCE_SNAPSHOTS env var.
+type resourceSnapshotSpec struct {
+	TargetPath    string `json:"targetPath"`
+	APIVersion    string `json:"apiVersion"`
+	Kind          string `json:"kind"`
+	Namespace     string `json:"namespace"`
+	ResourceName  string `json:"resourceName,omitempty"`
+	LabelSelector string `json:"labelSelector,omitempty"`
+	FieldSelector string `json:"fieldSelector,omitempty"`
+}
+
+// resourceSnapshotDir returns the absolute directory for a Runtime context's resource snapshots.
+func resourceSnapshotDir(item *kubeopenv1alpha1.ContextItem, workspaceDir string) string {
+	return resolveMountPath(defaultString(item.MountPath, DefaultResourceSnapshotDir), workspaceDir)
+}
+
+// collectResourceSnapshots gathers resource snapshot specs from all Runtime contexts.
+// Namespaces default to the Task's namespace.
+func collectResourceSnapshots(items []kubeopenv1alpha1.ContextItem, namespace, workspaceDir string) []resourceSnapshotSpec {
+	var specs []resourceSnapshotSpec
+	for i := range items {
+		item := &items[i]
+		if item.Type != kubeopenv1alpha1.ContextTypeRuntime || item.Runtime == nil {
+			continue
+		}
+		dir := resourceSnapshotDir(item, workspaceDir)
+		for _, r := range item.Runtime.Resources {
+			specs = append(specs, resourceSnapshotSpec{
+				TargetPath:    dir + "/" + r.Name + ".yaml",
+				APIVersion:    r.APIVersion,
+				Kind:          r.Kind,
+				Namespace:     defaultString(r.Namespace, namespace),
+				ResourceName:  r.ResourceName,
+				LabelSelector: r.LabelSelector,
+				FieldSelector: r.FieldSelector,
+			})
+		}
+	}
+	return specs
+}
+
+// describeResourceSnapshots lists a Runtime context's snapshot files for the agent prompt.
+// Returns "" if the context declares no resources.
+func describeResourceSnapshots(item *kubeopenv1alpha1.ContextItem, workspaceDir string) string {
+	if item.Runtime == nil || len(item.Runtime.Resources) == 0 {
+		return ""
+	}
+	dir := resourceSnapshotDir(item, workspaceDir)
+
+	var b strings.Builder
+	b.WriteString("\n\n### Cluster State\n")
+	fmt.Fprintf(&b, "Snapshots of live cluster resources, collected when this Task started, are available in %s:\n", dir)
+	for _, r := range item.Runtime.Resources {
+		target := r.Kind
+		switch {
+		case r.ResourceName != "":
+			target += " " + r.ResourceName
+		case r.LabelSelector != "" || r.FieldSelector != "":
+			target += " matching " + strings.Trim(r.LabelSelector+","+r.FieldSelector, ",")
+		}
+		if r.Namespace != "" {
+			target += " in namespace " + r.Namespace
+		}
+		fmt.Fprintf(&b, "- %s.yaml: %s (%s)\n", r.Name, target, r.APIVersion)
+	}
+	return b.String()
+}
+
+// buildResourceSnapshotContainer creates an init container that reads the declared
+// resources with the Pod's ServiceAccount and writes them into the workspace.
+func buildResourceSnapshotContainer(specs []resourceSnapshotSpec, workspaceDir string, sysCfg systemConfig) corev1.Container {
+	specsJSON, _ := json.Marshal(specs)
+
+	return corev1.Container{
+		Name:            ResourceSnapshotContainerName,
+		Image:           sysCfg.systemImage,
+		ImagePullPolicy: sysCfg.systemImagePullPolicy,
+		Command:         []string{"/kubeopencode", "resource-snapshot"},
+		Env: []corev1.EnvVar{
+			{Name: "RESOURCE_SNAPSHOTS", Value: string(specsJSON)},
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: WorkspaceVolumeName, MountPath: workspaceDir},
+		},
+	}
+}
@@ -320,6 +320,7 @@ func BuildServerDeployment(agent *kubeopenv1alpha1.Agent, agentCfg agentConfig,
 					LocalObjectReference: corev1.LocalObjectReference{
 						Name: dm.configMapName,
 					},
+					Items:    dm.items,
 					Optional: &dm.optional,
 				},
 			},
@@ -397,11 +398,6 @@ func BuildServerDeployment(agent *kubeopenv1alpha1.Agent, agentCfg agentConfig,
 			gitInitContainer.Env = append(gitInitContainer.Env,
 				corev1.EnvVar{Name: "GIT_WORKSPACE_DIR", Value: agentCfg.workspaceDir},
 			)
-			if gm.repoPath != "" {
-				gitInitContainer.Env = append(gitInitContainer.Env,
-					corev1.EnvVar{Name: "GIT_REPO_SUBPATH", Value: gm.repoPath},
-				)
-			}
 			gitInitContainer.VolumeMounts = append(gitInitContainer.VolumeMounts,
 				corev1.VolumeMount{
 					Name:      WorkspaceVolumeName,
@@ -458,6 +454,11 @@ func BuildServerDeployment(agent *kubeopenv1alpha1.Agent, agentCfg agentConfig,
 		})
 	}
 
+	// Add the shared context cache volume used by git-init containers
+	if len(ctxGitMounts) > 0 && sysCfg.contextCache != nil {
+		volumes = append(volumes, buildContextCacheVolume(sysCfg.contextCache))
+	}
+
 	// Add GIT_CONFIG_GLOBAL if we have Git mounts
 	if len(ctxGitMounts) > 0 {
 		envVars = append(envVars, corev1.EnvVar{
@@ -727,6 +728,9 @@ func BuildServerDeployment(agent *kubeopenv1alpha1.Agent, agentCfg agentConfig,
 		podSpec.SecurityContext = agentCfg.podSpec.PodSecurityContext
 	}
 
+	applyPodSecurityDefaults(&podSpec, sysCfg.podSecurityDefaults, agentCfg.podSpec)
+	applyImageRewrites(&podSpec, sysCfg.imageRewrites)
+
 	// Single replica for now (simplicity)
 	replicas := int32(1)
 
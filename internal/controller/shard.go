@@ -0,0 +1,116 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+
+// Shard is the subset of namespaces reconciled by one controller replica, so that
+// large installs can spread reconciliation over several active controllers.
+//
+// Namespaces are assigned either by hash (Count replicas, each with its own Index)
+// or by a namespace label selector (one replica per selector, the selectors must not
+// overlap). Cluster-scoped objects belong to the hash shard with index 0 and to every
+// selector shard.
+//
+// Namespaces are not watched: when a namespace's labels move it to another selector
+// shard, that shard picks up its objects on their next change or the manager's
+// periodic resync, not right away.
+type Shard struct {
+	// Index of this shard in [0, Count) for hash-based assignment
+	Index int
+	// Count is the number of hash shards
+	Count int
+	// Selector selects the namespaces of this shard for label-based assignment
+	Selector labels.Selector
+	// Reader looks up namespace labels for label-based assignment. It should be
+	// cache-backed, as every watch event is filtered.
+	Reader client.Reader
+}
+
+// NewShard returns the shard configured by the controller flags, or nil if the
+// controller reconciles all namespaces.
+func NewShard(index, count int, selector string) (*Shard, error) {
+	if selector != "" {
+		if count > 1 {
+			return nil, fmt.Errorf("shard count and shard namespace selector are mutually exclusive")
+		}
+		parsed, err := labels.Parse(selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid shard namespace selector %q: %w", selector, err)
+		}
+		return &Shard{Selector: parsed}, nil
+	}
+	if count < 1 {
+		return nil, fmt.Errorf("shard count must be at least 1, got %d", count)
+	}
+	if index < 0 || index >= count {
+		return nil, fmt.Errorf("shard index %d is out of range for %d shards", index, count)
+	}
+	if count == 1 {
+		return nil, nil
+	}
+	return &Shard{Index: index, Count: count}, nil
+}
+
+// ID identifies the shard, e.g. in its leader election lock name
+func (s *Shard) ID() string {
+	if s.Selector != nil {
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(s.Selector.String()))
+		return fmt.Sprintf("selector-%08x", h.Sum32())
+	}
+	return fmt.Sprintf("%d-of-%d", s.Index, s.Count)
+}
+
+// Owns reports whether namespace is reconciled by this shard. A nil shard owns all
+// namespaces. Namespace lookup failures are treated as not owned.
+func (s *Shard) Owns(ctx context.Context, namespace string) bool {
+	if s == nil {
+		return true
+	}
+	if s.Selector != nil {
+		if namespace == "" {
+			return true
+		}
+		var ns corev1.Namespace
+		if err := s.Reader.Get(ctx, client.ObjectKey{Name: namespace}, &ns); err != nil {
+			log.FromContext(ctx).Error(err, "failed to get namespace for shard assignment", "namespace", namespace)
+			return false
+		}
+		return s.Selector.Matches(labels.Set(ns.Labels))
+	}
+	return shardIndex(namespace, s.Count) == s.Index
+}
+
+// Predicate filters events to objects in namespaces owned by the shard. The predicate
+// of a nil shard accepts all events. Namespace label changes do not trigger events.
+func (s *Shard) Predicate() predicate.Predicate {
+	if s == nil {
+		return predicate.Funcs{}
+	}
+	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return s.Owns(context.Background(), obj.GetNamespace())
+	})
+}
+
+// shardIndex returns the hash shard of namespace; cluster-scoped objects go to shard 0
+func shardIndex(namespace string, count int) int {
+	if namespace == "" {
+		return 0
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(namespace))
+	return int(h.Sum32() % uint32(count))
+}
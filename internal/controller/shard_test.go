@@ -0,0 +1,135 @@
+// Copyright Contributors to the KubeOpenCode project
+
+//go:build !integration
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestNewShard(t *testing.T) {
+	tests := []struct {
+		name     string
+		index    int
+		count    int
+		selector string
+		wantNil  bool
+		wantErr  bool
+	}{
+		{name: "unsharded", index: 0, count: 1, wantNil: true},
+		{name: "hash shard", index: 1, count: 3},
+		{name: "selector shard", count: 1, selector: "tier=batch"},
+		{name: "zero count", index: 0, count: 0, wantErr: true},
+		{name: "index out of range", index: 3, count: 3, wantErr: true},
+		{name: "negative index", index: -1, count: 3, wantErr: true},
+		{name: "count and selector", index: 0, count: 2, selector: "tier=batch", wantErr: true},
+		{name: "invalid selector", count: 1, selector: "tier in (", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shard, err := NewShard(tt.index, tt.count, tt.selector)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewShard() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if (shard == nil) != tt.wantNil {
+				t.Errorf("NewShard() = %v, wantNil %v", shard, tt.wantNil)
+			}
+		})
+	}
+}
+
+func TestShardOwnsHash(t *testing.T) {
+	const count = 4
+	shards := make([]*Shard, count)
+	for i := range shards {
+		shard, err := NewShard(i, count, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		shards[i] = shard
+	}
+
+	// Every namespace is owned by exactly one shard
+	for i := 0; i < 100; i++ {
+		namespace := fmt.Sprintf("team-%d", i)
+		owners := 0
+		for _, shard := range shards {
+			if shard.Owns(context.Background(), namespace) {
+				owners++
+			}
+		}
+		if owners != 1 {
+			t.Errorf("namespace %q is owned by %d shards, want 1", namespace, owners)
+		}
+	}
+
+	if !shards[0].Owns(context.Background(), "") {
+		t.Error("cluster-scoped objects should belong to shard 0")
+	}
+	if shards[1].Owns(context.Background(), "") {
+		t.Error("cluster-scoped objects should not belong to shard 1")
+	}
+
+	var unsharded *Shard
+	if !unsharded.Owns(context.Background(), "any") {
+		t.Error("nil shard should own every namespace")
+	}
+}
+
+func TestShardOwnsSelector(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "batch", Labels: map[string]string{"tier": "batch"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "interactive", Labels: map[string]string{"tier": "interactive"}}},
+	).Build()
+
+	shard, err := NewShard(0, 1, "tier=batch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	shard.Reader = reader
+
+	tests := []struct {
+		namespace string
+		want      bool
+	}{
+		{namespace: "batch", want: true},
+		{namespace: "interactive", want: false},
+		{namespace: "missing", want: false},
+		{namespace: "", want: true},
+	}
+	for _, tt := range tests {
+		if got := shard.Owns(context.Background(), tt.namespace); got != tt.want {
+			t.Errorf("Owns(%q) = %v, want %v", tt.namespace, got, tt.want)
+		}
+	}
+}
+
+func TestShardID(t *testing.T) {
+	shard, err := NewShard(2, 5, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := shard.ID(); got != "2-of-5" {
+		t.Errorf("ID() = %q, want %q", got, "2-of-5")
+	}
+
+	a, _ := NewShard(0, 1, "tier=batch")
+	b, _ := NewShard(0, 1, "tier=interactive")
+	if a.ID() == b.ID() {
+		t.Errorf("selector shards should have distinct IDs, both are %q", a.ID())
+	}
+}
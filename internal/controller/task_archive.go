@@ -0,0 +1,177 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+)
+
+const (
+	// archiveRequestTimeout bounds a single archive request
+	archiveRequestTimeout = 30 * time.Second
+
+	// archiveLogLimitBytes bounds the logs archived per container
+	archiveLogLimitBytes int64 = 1 << 20
+)
+
+// PodLogsFunc returns the logs of a container of a Pod, at most limitBytes.
+type PodLogsFunc func(ctx context.Context, namespace, podName, container string, limitBytes int64) (string, error)
+
+// NewPodLogsFunc returns a PodLogsFunc reading logs with clientset.
+func NewPodLogsFunc(clientset kubernetes.Interface) PodLogsFunc {
+	return func(ctx context.Context, namespace, podName, container string, limitBytes int64) (string, error) {
+		data, err := clientset.CoreV1().Pods(namespace).GetLogs(podName, &corev1.PodLogOptions{
+			Container:  container,
+			LimitBytes: &limitBytes,
+		}).DoRaw(ctx)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+}
+
+// TaskArchiveRecord is the JSON document sent to the archive sink for a Task.
+type TaskArchiveRecord struct {
+	// Task is the archived Task
+	Task *kubeopenv1alpha1.Task `json:"task"`
+	// Policy is the cleanup policy deleting the Task: "ttl" or "retention"
+	Policy string `json:"policy"`
+	// ArchivedAt is when the record was created
+	ArchivedAt metav1.Time `json:"archivedAt"`
+	// Logs holds the final logs of the Task Pod by container name, if requested
+	Logs map[string]string `json:"logs,omitempty"`
+}
+
+// archiveTaskBeforeCleanup sends task to the archive sink, if one is configured.
+// It returns an error only if the Task must not be deleted yet.
+func (r *TaskReconciler) archiveTaskBeforeCleanup(ctx context.Context, archive *kubeopenv1alpha1.CleanupArchiveConfig, task *kubeopenv1alpha1.Task, policy string) error {
+	if archive == nil {
+		return nil
+	}
+
+	err := r.archiveTask(ctx, archive, task, policy)
+	if err == nil {
+		return nil
+	}
+	if archive.FailurePolicy == kubeopenv1alpha1.ArchiveFailurePolicyIgnore {
+		log.FromContext(ctx).Error(err, "failed to archive Task, deleting it anyway", "task", task.Name)
+		return nil
+	}
+	r.Recorder.Eventf(task, nil, corev1.EventTypeWarning, "ArchiveFailed", "Archive",
+		"Failed to archive Task before cleanup: %v", err)
+	return fmt.Errorf("failed to archive Task %s before cleanup: %w", task.Name, err)
+}
+
+// archiveTask POSTs the archive record of task to the sink
+func (r *TaskReconciler) archiveTask(ctx context.Context, archive *kubeopenv1alpha1.CleanupArchiveConfig, task *kubeopenv1alpha1.Task, policy string) error {
+	record := TaskArchiveRecord{
+		Task:       task.DeepCopy(),
+		Policy:     policy,
+		ArchivedAt: metav1.Now(),
+	}
+	// Objects read through the typed client carry no TypeMeta
+	record.Task.APIVersion = kubeopenv1alpha1.SchemeGroupVersion.String()
+	record.Task.Kind = "Task"
+	record.Task.ManagedFields = nil
+	if archive.IncludeLogs {
+		record.Logs = r.collectArchiveLogs(ctx, task)
+	}
+	body, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode archive record: %w", err)
+	}
+
+	headers, err := r.archiveHeaders(ctx, archive)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, archiveRequestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, archive.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid archive URL: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	httpClient := r.ArchiveHTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("archive request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("archive sink returned %s", resp.Status)
+	}
+
+	log.FromContext(ctx).Info("archived Task before cleanup", "task", task.Name, "policy", policy)
+	return nil
+}
+
+// archiveHeaders returns the HTTP headers from the archive's headers Secret
+func (r *TaskReconciler) archiveHeaders(ctx context.Context, archive *kubeopenv1alpha1.CleanupArchiveConfig) (map[string]string, error) {
+	ref := archive.HeadersSecretRef
+	if ref == nil {
+		return nil, nil
+	}
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, secret); err != nil {
+		return nil, fmt.Errorf("failed to get archive headers Secret %s/%s: %w", ref.Namespace, ref.Name, err)
+	}
+	headers := make(map[string]string, len(secret.Data))
+	for name, value := range secret.Data {
+		headers[name] = string(value)
+	}
+	return headers, nil
+}
+
+// collectArchiveLogs returns the logs of the Task Pod's containers. This is
+// best-effort: a missing Pod or unreadable logs leave the logs out of the record.
+func (r *TaskReconciler) collectArchiveLogs(ctx context.Context, task *kubeopenv1alpha1.Task) map[string]string {
+	logger := log.FromContext(ctx)
+	if r.PodLogs == nil || task.Status.PodName == "" {
+		return nil
+	}
+	pod := &corev1.Pod{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: task.Namespace, Name: task.Status.PodName}, pod); err != nil {
+		if !errors.IsNotFound(err) {
+			logger.Error(err, "unable to get Task Pod for archive logs", "pod", task.Status.PodName)
+		}
+		return nil
+	}
+
+	logs := map[string]string{}
+	containers := append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+	for _, container := range containers {
+		content, err := r.PodLogs(ctx, pod.Namespace, pod.Name, container.Name, archiveLogLimitBytes)
+		if err != nil {
+			logger.Error(err, "unable to get logs for archive", "pod", pod.Name, "container", container.Name)
+			continue
+		}
+		logs[container.Name] = content
+	}
+	return logs
+}
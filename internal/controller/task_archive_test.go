@@ -0,0 +1,136 @@
+// Copyright Contributors to the KubeOpenCode project
+
+//go:build !integration
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/events"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+)
+
+func TestArchiveBeforeCleanup(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(s)
+	_ = kubeopenv1alpha1.AddToScheme(s)
+	ctx := context.Background()
+
+	newTask := func() *kubeopenv1alpha1.Task {
+		return &kubeopenv1alpha1.Task{
+			ObjectMeta: metav1.ObjectMeta{Name: "done", Namespace: "default", UID: "done-uid"},
+			Status: kubeopenv1alpha1.TaskExecutionStatus{
+				Phase:          kubeopenv1alpha1.TaskPhaseCompleted,
+				CompletionTime: &metav1.Time{Time: time.Now().Add(-time.Hour)},
+				PodName:        "done-pod",
+			},
+		}
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "done-pod", Namespace: "default"},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "agent"}}},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "archive-auth", Namespace: "kubeopencode-system"},
+		Data:       map[string][]byte{"Authorization": []byte("Bearer token")},
+	}
+	exists := func(r *TaskReconciler) bool {
+		err := r.Get(ctx, types.NamespacedName{Namespace: "default", Name: "done"}, &kubeopenv1alpha1.Task{})
+		return !errors.IsNotFound(err)
+	}
+	newReconciler := func(task *kubeopenv1alpha1.Task) *TaskReconciler {
+		return &TaskReconciler{
+			Client:   fake.NewClientBuilder().WithScheme(s).WithObjects(task, pod, secret).Build(),
+			Recorder: events.NewFakeRecorder(10),
+			PodLogs: func(_ context.Context, _, podName, container string, _ int64) (string, error) {
+				return podName + "/" + container + " output", nil
+			},
+		}
+	}
+
+	t.Run("archives the Task and its logs before deleting it", func(t *testing.T) {
+		var record TaskArchiveRecord
+		var authorization string
+		sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			authorization = req.Header.Get("Authorization")
+			if err := json.NewDecoder(req.Body).Decode(&record); err != nil {
+				t.Errorf("failed to decode archive record: %v", err)
+			}
+			w.WriteHeader(http.StatusCreated)
+		}))
+		defer sink.Close()
+
+		task := newTask()
+		r := newReconciler(task)
+		archive := &kubeopenv1alpha1.CleanupArchiveConfig{
+			URL:              sink.URL,
+			HeadersSecretRef: &corev1.SecretReference{Name: "archive-auth", Namespace: "kubeopencode-system"},
+			IncludeLogs:      true,
+		}
+		if _, _, err := r.checkTTLCleanup(ctx, task, 60, archive); err != nil {
+			t.Fatal(err)
+		}
+
+		if exists(r) {
+			t.Error("archived task was not deleted")
+		}
+		if authorization != "Bearer token" {
+			t.Errorf("Authorization header = %q, want %q", authorization, "Bearer token")
+		}
+		if record.Policy != "ttl" {
+			t.Errorf("policy = %q, want %q", record.Policy, "ttl")
+		}
+		if record.Task == nil || record.Task.UID != "done-uid" || record.Task.Kind != "Task" {
+			t.Errorf("unexpected archived task: %+v", record.Task)
+		}
+		if got := record.Logs["agent"]; got != "done-pod/agent output" {
+			t.Errorf("logs[agent] = %q, want %q", got, "done-pod/agent output")
+		}
+	})
+
+	failingSink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failingSink.Close()
+
+	t.Run("keeps the Task when archiving fails with Retry", func(t *testing.T) {
+		task := newTask()
+		r := newReconciler(task)
+		archive := &kubeopenv1alpha1.CleanupArchiveConfig{URL: failingSink.URL}
+		if err := r.checkRetentionCleanup(ctx, "default", 0, archive); err == nil {
+			t.Error("expected an error when the sink fails")
+		}
+		if !exists(r) {
+			t.Error("task was deleted although archiving failed")
+		}
+	})
+
+	t.Run("deletes the Task when archiving fails with Ignore", func(t *testing.T) {
+		task := newTask()
+		r := newReconciler(task)
+		archive := &kubeopenv1alpha1.CleanupArchiveConfig{
+			URL:           failingSink.URL,
+			FailurePolicy: kubeopenv1alpha1.ArchiveFailurePolicyIgnore,
+		}
+		if err := r.checkRetentionCleanup(ctx, "default", 0, archive); err != nil {
+			t.Fatal(err)
+		}
+		if exists(r) {
+			t.Error("task was not deleted with failurePolicy Ignore")
+		}
+	})
+}
@@ -0,0 +1,107 @@
+// Copyright Contributors to the KubeOpenCode project
+
+//go:build !integration
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+)
+
+func TestIsTaskKept(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        bool
+	}{
+		{name: "nil annotations", annotations: nil, want: false},
+		{name: "keep true", annotations: map[string]string{AnnotationKeep: "true"}, want: true},
+		{name: "keep false", annotations: map[string]string{AnnotationKeep: "false"}, want: false},
+		{name: "other annotation", annotations: map[string]string{"other": "true"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &kubeopenv1alpha1.Task{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+			if got := isTaskKept(task); got != tt.want {
+				t.Errorf("isTaskKept() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCleanupSkipsKeptTasks(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = kubeopenv1alpha1.AddToScheme(s)
+	ctx := context.Background()
+
+	finishedTask := func(name string, age time.Duration, kept bool) *kubeopenv1alpha1.Task {
+		task := &kubeopenv1alpha1.Task{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Status: kubeopenv1alpha1.TaskExecutionStatus{
+				Phase:          kubeopenv1alpha1.TaskPhaseCompleted,
+				CompletionTime: &metav1.Time{Time: time.Now().Add(-age)},
+			},
+		}
+		if kept {
+			task.Annotations = map[string]string{AnnotationKeep: "true"}
+		}
+		return task
+	}
+	exists := func(r *TaskReconciler, name string) bool {
+		err := r.Get(ctx, types.NamespacedName{Namespace: "default", Name: name}, &kubeopenv1alpha1.Task{})
+		return !errors.IsNotFound(err)
+	}
+
+	t.Run("retention neither deletes nor counts kept tasks", func(t *testing.T) {
+		r := &TaskReconciler{Client: fake.NewClientBuilder().WithScheme(s).WithObjects(
+			finishedTask("kept-oldest", 4*time.Hour, true),
+			finishedTask("old", 3*time.Hour, false),
+			finishedTask("recent", 2*time.Hour, false),
+			finishedTask("newest", time.Hour, false),
+		).Build()}
+
+		if err := r.checkRetentionCleanup(ctx, "default", 2, nil); err != nil {
+			t.Fatal(err)
+		}
+		for name, want := range map[string]bool{"kept-oldest": true, "old": false, "recent": true, "newest": true} {
+			if got := exists(r, name); got != want {
+				t.Errorf("task %q exists = %v, want %v", name, got, want)
+			}
+		}
+	})
+
+	t.Run("TTL does not delete kept tasks", func(t *testing.T) {
+		ttl := int32(60)
+		config := &kubeopenv1alpha1.KubeOpenCodeConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: KubeOpenCodeConfigName},
+			Spec: kubeopenv1alpha1.KubeOpenCodeConfigSpec{
+				Cleanup: &kubeopenv1alpha1.CleanupConfig{TTLSecondsAfterFinished: &ttl},
+			},
+		}
+		kept := finishedTask("kept", time.Hour, true)
+		expired := finishedTask("expired", time.Hour, false)
+		r := &TaskReconciler{Client: fake.NewClientBuilder().WithScheme(s).WithObjects(config, kept, expired).Build()}
+
+		for _, task := range []*kubeopenv1alpha1.Task{kept, expired} {
+			if _, err := r.handleTaskCleanup(ctx, task); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if !exists(r, "kept") {
+			t.Error("kept task was deleted")
+		}
+		if exists(r, "expired") {
+			t.Error("expired task was not deleted")
+		}
+	})
+}
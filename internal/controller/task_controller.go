@@ -7,10 +7,15 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"path"
+	"slices"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -20,9 +25,11 @@ import (
 	"k8s.io/client-go/tools/events"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	crcontroller "sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+	"github.com/kubeopencode/kubeopencode/internal/tracing"
 )
 
 const (
@@ -41,6 +48,10 @@ const (
 	// DefaultQueuedRequeueDelay is the default delay for requeuing queued Tasks
 	DefaultQueuedRequeueDelay = 10 * time.Second
 
+	// podTerminationRequeueDelay is the delay for requeuing Tasks waiting for the Pod
+	// of a previous run to terminate
+	podTerminationRequeueDelay = 2 * time.Second
+
 	// DefaultQuotaRequeueDelay is the minimum delay for requeuing quota-blocked Tasks
 	DefaultQuotaRequeueDelay = 30 * time.Second
 
@@ -51,6 +62,9 @@ const (
 	// AnnotationStop is the annotation key for user-initiated task stop
 	AnnotationStop = "kubeopencode.io/stop"
 
+	// AnnotationKeep is the annotation key that exempts a Task from automatic cleanup
+	AnnotationKeep = "kubeopencode.io/keep"
+
 	// KubeOpenCodeConfigName is the singleton name for the cluster-scoped KubeOpenCodeConfig.
 	// Following OpenShift convention, cluster-wide config resources are named "cluster".
 	KubeOpenCodeConfigName = "cluster"
@@ -87,6 +101,20 @@ To get Task status:
 - Task: Single AI task execution (what you're running now)
 - Agent: Configuration for how tasks are executed (image, credentials, etc.)
 `
+
+	// TaskMessagesDir is the directory, relative to the workspace, where the UI server
+	// writes follow-up messages for templateRef Tasks. Each message is a separate
+	// Markdown file named after the time it was received, so the files sort in
+	// delivery order. agentRef Tasks receive messages as prompts in their session.
+	TaskMessagesDir = ".kubeopencode/messages"
+
+	// taskMessagesPrompt tells the agent of a templateRef Task where follow-up messages
+	// arrive. It is added to the context file; %s is the messages directory.
+	taskMessagesPrompt = `## Follow-up Messages
+
+Users can send you follow-up instructions while you work. Each message is written
+to a new Markdown file in %s, named after the time it was received.
+Check this directory between steps, and follow new messages in order.`
 )
 
 // isTaskFinished returns true if the task phase is terminal (Completed or Failed).
@@ -99,6 +127,11 @@ func isTaskStoppedByUser(task *kubeopenv1alpha1.Task) bool {
 	return task.Annotations != nil && task.Annotations[AnnotationStop] == "true"
 }
 
+// isTaskKept returns true if the task is exempt from automatic cleanup via annotation.
+func isTaskKept(task *kubeopenv1alpha1.Task) bool {
+	return task.Annotations != nil && task.Annotations[AnnotationKeep] == "true"
+}
+
 // isTaskTimedOut returns true if the task's execution has exceeded its timeout.
 // Returns false if no timeout is configured or if startTime is not set.
 // Queue time (before startTime) is excluded from the calculation.
@@ -116,6 +149,23 @@ type TaskReconciler struct {
 	Scheme   *runtime.Scheme
 	Recorder events.EventRecorder
 	ocClient *OpenCodeClient
+
+	// Shard limits reconciliation to the namespaces of this replica. Nil reconciles all.
+	Shard *Shard
+	// MaxConcurrentReconciles is the number of Tasks reconciled in parallel. Defaults to 1.
+	MaxConcurrentReconciles int
+	// PodLogs reads Task Pod logs for the cleanup archive. Nil archives no logs.
+	PodLogs PodLogsFunc
+	// ArchiveHTTPClient sends records to the cleanup archive sink. Defaults to a
+	// client with no timeout; each request is bounded by archiveRequestTimeout.
+	ArchiveHTTPClient *http.Client
+	// APIReader confirms that a Task Pod is gone without relying on the cache.
+	// Defaults to the Client.
+	APIReader client.Reader
+
+	// admissionLocks serializes the start of Tasks per Agent, so that parallel
+	// reconciles do not overrun the Agent's capacity and quota
+	admissionLocks sync.Map
 }
 
 // NewTaskReconciler creates a new TaskReconciler with all dependencies.
@@ -138,10 +188,13 @@ func NewTaskReconciler(c client.Client, scheme *runtime.Scheme, recorder events.
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups="",resources=pods/log,verbs=get
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;create
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop
-func (r *TaskReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+func (r *TaskReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
 	log := log.FromContext(ctx)
 
 	// Get Task CR
@@ -155,17 +208,27 @@ func (r *TaskReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 		return ctrl.Result{}, err
 	}
 
+	ctx, span := startReconcileSpan(ctx, task)
+	defer func() {
+		if reterr != nil {
+			tracing.RecordError(span, reterr)
+		}
+		span.End()
+	}()
+
 	// If new, initialize status and create Pod
 	// Also handle incomplete Running state (Running but no Pod created yet)
 	// This can happen if context processing failed after Phase was set to Running
 	// and the status update to Failed encountered a conflict
 	if task.Status.Phase == "" ||
-		(task.Status.Phase == kubeopenv1alpha1.TaskPhaseRunning && task.Status.PodName == "") {
+		(task.Status.Phase == kubeopenv1alpha1.TaskPhaseRunning && task.Status.PodName == "" && task.Status.JobName == "") {
+		defer r.lockAdmission(task)()
 		return r.initializeTask(ctx, task)
 	}
 
 	// If queued, check if capacity is available
 	if task.Status.Phase == kubeopenv1alpha1.TaskPhaseQueued {
+		defer r.lockAdmission(task)()
 		return r.handleQueuedTask(ctx, task)
 	}
 
@@ -192,6 +255,11 @@ func (r *TaskReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 		return ctrl.Result{}, err
 	}
 
+	// A rescheduled Task needs a new Pod
+	if task.Status.Phase == kubeopenv1alpha1.TaskPhaseRunning && task.Status.PodName == "" && task.Status.JobName == "" {
+		return ctrl.Result{Requeue: true}, nil
+	}
+
 	// Schedule requeue at timeout expiry for Running tasks with timeout
 	if task.Status.Phase == kubeopenv1alpha1.TaskPhaseRunning &&
 		task.Spec.Timeout != nil && task.Status.StartTime != nil {
@@ -211,6 +279,13 @@ func (r *TaskReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 func (r *TaskReconciler) initializeTask(ctx context.Context, task *kubeopenv1alpha1.Task) (ctrl.Result, error) {
 	log := log.FromContext(ctx)
 
+	// Reject invalid Task contexts before resolving the Agent/Template or taking a capacity slot
+	// The workspace directory is not resolved yet; processAllContexts checks the resolved mount paths
+	if err := ValidateTaskContexts(task, ""); err != nil {
+		log.Error(err, "invalid Task contexts")
+		return r.updateTaskFailed(ctx, task, kubeopenv1alpha1.ReasonContextError, err)
+	}
+
 	// Determine which path: agentRef or templateRef
 	isTemplateRef := task.Spec.TemplateRef != nil
 
@@ -226,6 +301,12 @@ func (r *TaskReconciler) initializeTask(ctx context.Context, task *kubeopenv1alp
 			log.Error(err, "unable to get AgentTemplate")
 			return r.updateTaskFailed(ctx, task, kubeopenv1alpha1.ReasonAgentError, err)
 		}
+		// Task-level failure snapshot overrides the template default
+		cfg.failureSnapshot, err = resolveFailureSnapshot(task, cfg)
+		if err != nil {
+			log.Error(err, "invalid failure snapshot configuration")
+			return r.updateTaskFailed(ctx, task, kubeopenv1alpha1.ReasonAgentError, err)
+		}
 		// No serverURL for template-based tasks (standalone Pod)
 	} else {
 		// agentRef path: resolve config from Agent
@@ -235,6 +316,10 @@ func (r *TaskReconciler) initializeTask(ctx context.Context, task *kubeopenv1alp
 			log.Error(err, "unable to get Agent")
 			return r.updateTaskFailed(ctx, task, kubeopenv1alpha1.ReasonAgentError, err)
 		}
+		if err := validateAgentRefContexts(cfg.contexts); err != nil {
+			log.Error(err, "invalid Agent contexts")
+			return r.updateTaskFailed(ctx, task, kubeopenv1alpha1.ReasonContextError, fmt.Errorf("agent %s: %w", refName, err))
+		}
 
 		// Agent always has a server — compute the URL for --attach
 		port := cfg.port
@@ -319,14 +404,25 @@ func (r *TaskReconciler) initializeTask(ctx context.Context, task *kubeopenv1alp
 			return ctrl.Result{RequeueAfter: DefaultQueuedRequeueDelay}, nil
 		}
 
-		// Check agent capacity if MaxConcurrentTasks is set
-		if cfg.maxConcurrentTasks != nil && *cfg.maxConcurrentTasks > 0 {
+		// Check agent capacity if MaxConcurrentTasks is set. A Running Task, e.g. one
+		// being rescheduled, already holds its capacity slot.
+		if cfg.maxConcurrentTasks != nil && *cfg.maxConcurrentTasks > 0 && task.Status.Phase != kubeopenv1alpha1.TaskPhaseRunning {
 			hasCapacity, err := r.checkAgentCapacity(ctx, task.Namespace, refName, *cfg.maxConcurrentTasks)
 			if err != nil {
 				log.Error(err, "unable to check agent capacity")
 				return ctrl.Result{}, err
 			}
 
+			if hasCapacity {
+				// Leave free capacity to queued Tasks with a higher priority
+				blocked, err := r.higherPriorityTaskQueued(ctx, task, refName)
+				if err != nil {
+					log.Error(err, "unable to check queued task priorities")
+					return ctrl.Result{}, err
+				}
+				hasCapacity = !blocked
+			}
+
 			if !hasCapacity {
 				log.Info("agent at capacity, queueing task", "agent", refName, "maxConcurrent", *cfg.maxConcurrentTasks)
 				r.Recorder.Eventf(task, nil, corev1.EventTypeNormal, "Queued", "Queued", "Agent %q at capacity (max: %d), task queued", refName, *cfg.maxConcurrentTasks)
@@ -349,6 +445,11 @@ func (r *TaskReconciler) initializeTask(ctx context.Context, task *kubeopenv1alp
 					return ctrl.Result{}, err
 				}
 
+				if cfg.preemptionPolicy == kubeopenv1alpha1.PreemptionPolicyLowerPriority {
+					// Preemption happens once the Task is queued, so that the preempted
+					// Task cannot take the freed capacity back before this one
+					return ctrl.Result{Requeue: true}, nil
+				}
 				return ctrl.Result{RequeueAfter: DefaultQueuedRequeueDelay}, nil
 			}
 		}
@@ -368,32 +469,7 @@ func (r *TaskReconciler) initializeTask(ctx context.Context, task *kubeopenv1alp
 			}
 
 			if !hasQuota {
-				log.Info("agent quota exceeded, queueing task",
-					"agent", refName,
-					"maxTaskStarts", cfg.quota.MaxTaskStarts,
-					"windowSeconds", cfg.quota.WindowSeconds)
-				r.Recorder.Eventf(task, nil, corev1.EventTypeNormal, "QuotaExceeded", "Queued", "Agent %q quota exceeded (max: %d per %ds), task queued", refName, cfg.quota.MaxTaskStarts, cfg.quota.WindowSeconds)
-
-				task.Status.ObservedGeneration = task.Generation
-				task.Status.Phase = kubeopenv1alpha1.TaskPhaseQueued
-				task.Status.AgentRef = &kubeopenv1alpha1.AgentReference{
-					Name: refName,
-				}
-
-				meta.SetStatusCondition(&task.Status.Conditions, metav1.Condition{
-					Type:   kubeopenv1alpha1.ConditionTypeQueued,
-					Status: metav1.ConditionTrue,
-					Reason: kubeopenv1alpha1.ReasonQuotaExceeded,
-					Message: fmt.Sprintf("Waiting for agent %q quota (max: %d per %ds)",
-						refName, cfg.quota.MaxTaskStarts, cfg.quota.WindowSeconds),
-				})
-
-				if err := r.Status().Update(ctx, task); err != nil {
-					log.Error(err, "unable to update Task status")
-					return ctrl.Result{}, err
-				}
-
-				return ctrl.Result{RequeueAfter: requeueDelay}, nil
+				return r.queueTaskForQuota(ctx, task, refName, cfg.quota, requeueDelay)
 			}
 		}
 	}
@@ -412,7 +488,8 @@ func (r *TaskReconciler) initializeTask(ctx context.Context, task *kubeopenv1alp
 
 		// Store session title for agentRef Tasks (used to look up session later).
 		// The same title is passed to `opencode run --title` in the Pod command.
-		if serverURL != "" {
+		// A rescheduled Task that was queued meanwhile keeps the session it continues.
+		if serverURL != "" && (task.Status.Session == nil || task.Status.Session.Title != sessionTitle(task)) {
 			task.Status.Session = &kubeopenv1alpha1.SessionInfo{
 				Title: sessionTitle(task),
 			}
@@ -427,6 +504,10 @@ func (r *TaskReconciler) initializeTask(ctx context.Context, task *kubeopenv1alp
 			return ctrl.Result{}, err
 		}
 
+		if retryOf := task.Labels[kubeopenv1alpha1.TaskRetryOfLabelKey]; retryOf != "" {
+			TaskRetriesTotal.WithLabelValues(task.Namespace, metricsAgentName(task), r.retriedTaskReason(ctx, task.Namespace, retryOf)).Inc()
+		}
+
 		// Refresh task to get updated version
 		if err := r.Get(ctx, types.NamespacedName{Name: task.Name, Namespace: task.Namespace}, task); err != nil {
 			log.Error(err, "unable to refresh task after pre-occupying slot")
@@ -441,12 +522,23 @@ func (r *TaskReconciler) initializeTask(ctx context.Context, task *kubeopenv1alp
 	}
 
 	// Generate Pod name
-	podName := fmt.Sprintf("%s-pod", task.Name)
+	podName := taskPodName(task)
+
+	if cfg.usesJobBackend() {
+		if result, found, err := r.adoptExistingJob(ctx, task); found || err != nil {
+			return result, err
+		}
+	}
 
 	// Check if Pod already exists
 	existingPod := &corev1.Pod{}
 	podKey := types.NamespacedName{Name: podName, Namespace: task.Namespace}
-	if err := r.Get(ctx, podKey, existingPod); err == nil {
+	if err := r.Get(ctx, podKey, existingPod); err == nil && !cfg.usesJobBackend() {
+		if existingPod.DeletionTimestamp != nil {
+			// The Pod of a previous run (e.g., of a preempted Task) is still terminating
+			log.V(1).Info("previous Pod still terminating, waiting", "pod", podName)
+			return ctrl.Result{RequeueAfter: podTerminationRequeueDelay}, nil
+		}
 		// Pod already exists, update status with Pod info
 		task.Status.PodName = podName
 		if updateErr := r.Status().Update(ctx, task); updateErr != nil {
@@ -459,6 +551,9 @@ func (r *TaskReconciler) initializeTask(ctx context.Context, task *kubeopenv1alp
 		return ctrl.Result{}, nil
 	}
 
+	// Collect URL contexts to fetch; unnamed ones are numbered across both context lists
+	cfg.urlFetches = collectURLFetches(slices.Concat(cfg.contexts, task.Spec.Contexts), cfg.workspaceDir)
+
 	// Process all contexts using priority-based resolution
 	// Priority (lowest to highest):
 	//   1. Agent/Template contexts (defaults)
@@ -467,6 +562,7 @@ func (r *TaskReconciler) initializeTask(ctx context.Context, task *kubeopenv1alp
 	contextConfigMap, fileMounts, dirMounts, gitMounts, err := r.processAllContexts(ctx, task, cfg)
 	if err != nil {
 		log.Error(err, "unable to process contexts")
+		recordContextFetchFailure(task.Namespace, err)
 
 		// Refresh task to get latest version before updating status
 		if refreshErr := r.Get(ctx, types.NamespacedName{Name: task.Name, Namespace: task.Namespace}, task); refreshErr != nil {
@@ -477,29 +573,58 @@ func (r *TaskReconciler) initializeTask(ctx context.Context, task *kubeopenv1alp
 		return r.updateTaskFailed(ctx, task, kubeopenv1alpha1.ReasonContextError, err)
 	}
 
+	// Get system configuration (image, pull policies, proxy) from cluster-scoped KubeOpenCodeConfig
+	sysCfg := r.getSystemConfig(ctx)
+
 	// Create ConfigMap in Task's namespace (where Pod runs)
 	if contextConfigMap != nil {
-		if err := r.Create(ctx, contextConfigMap); err != nil {
-			if !errors.IsAlreadyExists(err) {
-				log.Error(err, "unable to create context ConfigMap")
-
-				// Refresh task to get latest version before updating status
-				if refreshErr := r.Get(ctx, types.NamespacedName{Name: task.Name, Namespace: task.Namespace}, task); refreshErr != nil {
-					log.Error(refreshErr, "unable to refresh task for ConfigMap error status update")
-					return ctrl.Result{}, refreshErr
-				}
+		var err error
+		if sysCfg.shareContextConfigMaps {
+			contextConfigMap, err = r.ensureSharedContextConfigMap(ctx, task, contextConfigMap)
+		} else if err = r.Create(ctx, contextConfigMap); errors.IsAlreadyExists(err) {
+			err = nil
+		}
+		if err != nil {
+			log.Error(err, "unable to create context ConfigMap")
 
-				return r.updateTaskFailed(ctx, task, kubeopenv1alpha1.ReasonConfigMapCreationError, err)
+			// Refresh task to get latest version before updating status
+			if refreshErr := r.Get(ctx, types.NamespacedName{Name: task.Name, Namespace: task.Namespace}, task); refreshErr != nil {
+				log.Error(refreshErr, "unable to refresh task for ConfigMap error status update")
+				return ctrl.Result{}, refreshErr
 			}
+
+			return r.updateTaskFailed(ctx, task, kubeopenv1alpha1.ReasonConfigMapCreationError, err)
 		}
 	}
 
-	// Get system configuration (image, pull policies, proxy) from cluster-scoped KubeOpenCodeConfig
-	sysCfg := r.getSystemConfig(ctx)
-
 	// Apply cluster-level defaults where Agent/Template doesn't specify its own
 	cfg.applySystemDefaults(sysCfg)
 
+	// Isolate the Pod before it starts, so that it is never reachable unprotected
+	networkPolicyAgent := refName
+	if isTemplateRef {
+		networkPolicyAgent = ""
+	}
+	if networkPolicy := buildTaskNetworkPolicy(task, cfg.networkPolicy, networkPolicyAgent); networkPolicy != nil {
+		if err := r.Create(ctx, networkPolicy); err != nil && !errors.IsAlreadyExists(err) {
+			log.Error(err, "unable to create NetworkPolicy")
+
+			// Refresh task to get latest version before updating status
+			if refreshErr := r.Get(ctx, types.NamespacedName{Name: task.Name, Namespace: task.Namespace}, task); refreshErr != nil {
+				log.Error(refreshErr, "unable to refresh task for NetworkPolicy error status update")
+				return ctrl.Result{}, refreshErr
+			}
+
+			return r.updateTaskFailed(ctx, task, kubeopenv1alpha1.ReasonNetworkPolicyError, err)
+		}
+	}
+
+	// Collect cluster resource snapshots declared by Runtime contexts
+	cfg.resourceSnapshots = collectResourceSnapshots(slices.Concat(cfg.contexts, task.Spec.Contexts), task.Namespace, cfg.workspaceDir)
+
+	// Collect Vault secrets declared by Vault contexts
+	cfg.vaultContexts = collectVaultContexts(slices.Concat(cfg.contexts, task.Spec.Contexts), cfg.workspaceDir)
+
 	// Create Pod with configuration and context mounts
 	// For agentRef, serverURL is passed to generate --attach command
 	pod := buildPod(task, podName, cfg, contextConfigMap, fileMounts, dirMounts, gitMounts, sysCfg, serverURL)
@@ -521,7 +646,16 @@ func (r *TaskReconciler) initializeTask(ctx context.Context, task *kubeopenv1alp
 			return r.updateTaskFailed(ctx, task, kubeopenv1alpha1.ReasonAgentError, fmt.Errorf("failed to get Agent for quota: %v", err))
 		}
 
-		if err := r.recordTaskStart(ctx, quotaAgent, task); err != nil {
+		recorded, requeueDelay, err := r.recordTaskStart(ctx, quotaAgent, task)
+		if err == nil && !recorded {
+			// Another start filled the persisted window since the quota check
+			if refreshErr := r.Get(ctx, types.NamespacedName{Name: task.Name, Namespace: task.Namespace}, task); refreshErr != nil {
+				log.Error(refreshErr, "unable to refresh task for quota requeue")
+				return ctrl.Result{}, refreshErr
+			}
+			return r.queueTaskForQuota(ctx, task, refName, cfg.quota, requeueDelay)
+		}
+		if err != nil {
 			log.Error(err, "failed to record task start for quota")
 
 			// Refresh task to get latest version before updating status
@@ -535,9 +669,21 @@ func (r *TaskReconciler) initializeTask(ctx context.Context, task *kubeopenv1alp
 		log.V(1).Info("recorded task start for quota", "task", task.Name, "agent", refName)
 	}
 
-	if err := r.Create(ctx, pod); err != nil {
-		log.Error(err, "unable to create Pod", "pod", podName, "namespace", task.Namespace)
-		r.Recorder.Eventf(task, nil, corev1.EventTypeWarning, "PodCreationFailed", "CreatePod", "Failed to create pod: %v", err)
+	// With the Job backend, the Job creates the Pod from the built Pod's template
+	var workload client.Object = pod
+	var jobName string
+	if cfg.usesJobBackend() {
+		jobName = taskJobName(task)
+		workload = buildJob(task, jobName, pod, cfg.execution.Job)
+	}
+
+	if err := r.Create(ctx, workload); err != nil {
+		kind := "pod"
+		if jobName != "" {
+			kind = "job"
+		}
+		log.Error(err, "unable to create "+kind, "pod", podName, "job", jobName, "namespace", task.Namespace)
+		r.Recorder.Eventf(task, nil, corev1.EventTypeWarning, "PodCreationFailed", "CreatePod", "Failed to create %s: %v", kind, err)
 
 		// Rollback quota record if it was recorded
 		if quotaAgent != nil {
@@ -563,8 +709,13 @@ func (r *TaskReconciler) initializeTask(ctx context.Context, task *kubeopenv1alp
 		return ctrl.Result{}, err
 	}
 
-	// Update status with Pod info (Task is already Running from pre-occupation)
-	task.Status.PodName = podName
+	// Update status with Pod or Job info (Task is already Running from pre-occupation).
+	// The Pod name of a Job is set once the Job created its Pod.
+	if jobName != "" {
+		task.Status.JobName = jobName
+	} else {
+		task.Status.PodName = podName
+	}
 
 	if err := r.Status().Update(ctx, task); err != nil {
 		if errors.IsConflict(err) {
@@ -575,6 +726,11 @@ func (r *TaskReconciler) initializeTask(ctx context.Context, task *kubeopenv1alp
 		return ctrl.Result{}, err
 	}
 
+	if jobName != "" {
+		log.Info("initialized Task", "job", jobName, "image", cfg.agentImage)
+		r.Recorder.Eventf(task, nil, corev1.EventTypeNormal, "JobCreated", "CreateJob", "Created job %s", jobName)
+		return ctrl.Result{}, nil
+	}
 	log.Info("initialized Task", "pod", podName, "image", cfg.agentImage)
 	r.Recorder.Eventf(task, nil, corev1.EventTypeNormal, "PodCreated", "CreatePod", "Created pod %s", podName)
 	return ctrl.Result{}, nil
@@ -610,8 +766,9 @@ func (r *TaskReconciler) updateTaskFailed(ctx context.Context, task *kubeopenv1a
 
 // updateTaskStatusFromPod syncs task status from Pod status
 func (r *TaskReconciler) updateTaskStatusFromPod(ctx context.Context, task *kubeopenv1alpha1.Task) error {
-	log := log.FromContext(ctx)
-
+	if task.Status.JobName != "" {
+		return r.updateTaskStatusFromJob(ctx, task)
+	}
 	if task.Status.PodName == "" {
 		return nil
 	}
@@ -621,8 +778,7 @@ func (r *TaskReconciler) updateTaskStatusFromPod(ctx context.Context, task *kube
 	podKey := types.NamespacedName{Name: task.Status.PodName, Namespace: task.Namespace}
 	if err := r.Get(ctx, podKey, pod); err != nil {
 		if errors.IsNotFound(err) {
-			log.Error(err, "Pod not found", "pod", task.Status.PodName)
-			return nil
+			return r.handleLostPod(ctx, task)
 		}
 		return err
 	}
@@ -630,40 +786,200 @@ func (r *TaskReconciler) updateTaskStatusFromPod(ctx context.Context, task *kube
 	// Check Pod phase
 	switch pod.Status.Phase {
 	case corev1.PodSucceeded:
-		task.Status.ObservedGeneration = task.Generation
-		task.Status.Phase = kubeopenv1alpha1.TaskPhaseCompleted
-		now := metav1.Now()
-		task.Status.CompletionTime = &now
-		log.Info("task completed", "pod", task.Status.PodName)
-		r.Recorder.Eventf(task, nil, corev1.EventTypeNormal, "Completed", "Completed", "Task completed successfully")
-		r.recordTaskDuration(task)
-		// Resolve session info from Agent's OpenCode server (best-effort)
-		r.resolveSessionInfo(ctx, task)
-		return r.Status().Update(ctx, task)
+		return r.markTaskCompleted(ctx, task, pod)
 	case corev1.PodFailed:
-		task.Status.ObservedGeneration = task.Generation
-		task.Status.Phase = kubeopenv1alpha1.TaskPhaseFailed
-		now := metav1.Now()
-		task.Status.CompletionTime = &now
-
-		// Extract container failure details for better diagnostics
-		failureDetail := getPodFailureDetail(pod)
-		if failureDetail != "" {
-			log.Info("task failed", "pod", task.Status.PodName, "detail", failureDetail)
-			r.Recorder.Eventf(task, nil, corev1.EventTypeWarning, "Failed", "Failed", "Task failed: %s", failureDetail)
-		} else {
-			log.Info("task failed", "pod", task.Status.PodName)
-			r.Recorder.Eventf(task, nil, corev1.EventTypeWarning, "Failed", "Failed", "Task failed")
+		if message := podDisruptionMessage(pod); message != "" {
+			return r.handlePodDisruption(ctx, task, pod, message)
 		}
+		return r.markTaskFailed(ctx, task, pod)
+	}
+
+	return nil
+}
+
+// markTaskCompleted records the successful end of task, run by pod.
+// pod may be nil if the Pod of a Job is gone.
+func (r *TaskReconciler) markTaskCompleted(ctx context.Context, task *kubeopenv1alpha1.Task, pod *corev1.Pod) error {
+	log := log.FromContext(ctx)
+
+	task.Status.ObservedGeneration = task.Generation
+	task.Status.Phase = kubeopenv1alpha1.TaskPhaseCompleted
+	now := metav1.Now()
+	task.Status.CompletionTime = &now
+	log.Info("task completed", "pod", task.Status.PodName)
+	r.Recorder.Eventf(task, nil, corev1.EventTypeNormal, "Completed", "Completed", "Task completed successfully")
+	r.recordTaskDuration(task)
+	if pod != nil {
+		r.captureTaskOutputs(ctx, task, pod)
+		recordTaskStartup(task, pod)
+	}
+	// Resolve session info from Agent's OpenCode server (best-effort)
+	r.resolveSessionInfo(ctx, task)
+	if err := r.Status().Update(ctx, task); err != nil {
+		return err
+	}
+	// Spans are recorded after the update, so that a conflict does not record them twice
+	if pod != nil {
+		recordTaskLifecycleSpans(ctx, task, pod)
+	}
+	return nil
+}
+
+// retriedTaskReason returns the reason the retried Task name ended with: the
+// reason of its Ready condition, AgentError for a failure without one, or its
+// phase if it did not fail. It returns "Unknown" if the Task no longer exists.
+func (r *TaskReconciler) retriedTaskReason(ctx context.Context, namespace, name string) string {
+	retried := &kubeopenv1alpha1.Task{}
+	if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, retried); err != nil {
+		return "Unknown"
+	}
+	if cond := meta.FindStatusCondition(retried.Status.Conditions, kubeopenv1alpha1.ConditionTypeReady); cond != nil && cond.Status == metav1.ConditionFalse && cond.Reason != "" {
+		return cond.Reason
+	}
+	if retried.Status.Phase == kubeopenv1alpha1.TaskPhaseFailed {
+		return kubeopenv1alpha1.ReasonAgentError
+	}
+	return string(retried.Status.Phase)
+}
+
+// markTaskFailed records the failure of task, run by pod.
+// pod may be nil if the Pod of a Job is gone.
+func (r *TaskReconciler) markTaskFailed(ctx context.Context, task *kubeopenv1alpha1.Task, pod *corev1.Pod) error {
+	log := log.FromContext(ctx)
+
+	task.Status.ObservedGeneration = task.Generation
+	task.Status.Phase = kubeopenv1alpha1.TaskPhaseFailed
+	now := metav1.Now()
+	task.Status.CompletionTime = &now
+
+	if pod == nil {
+		log.Info("task failed", "job", task.Status.JobName)
+		r.Recorder.Eventf(task, nil, corev1.EventTypeWarning, "Failed", "Failed", "Task failed")
 		r.recordTaskDuration(task)
-		// Resolve session info from Agent's OpenCode server (best-effort)
 		r.resolveSessionInfo(ctx, task)
 		return r.Status().Update(ctx, task)
 	}
 
+	// Extract container failure details for better diagnostics
+	failureDetail := getPodFailureDetail(pod)
+	if name := getFailedContextInitContainer(pod); name != "" {
+		ContextFetchFailuresTotal.WithLabelValues(task.Namespace, string(contextInitContainerType(name))).Inc()
+		meta.SetStatusCondition(&task.Status.Conditions, metav1.Condition{
+			Type:    kubeopenv1alpha1.ConditionTypeReady,
+			Status:  metav1.ConditionFalse,
+			Reason:  kubeopenv1alpha1.ReasonContextError,
+			Message: fmt.Sprintf("failed to prepare context: %s", failureDetail),
+		})
+	}
+	if failureDetail != "" {
+		log.Info("task failed", "pod", task.Status.PodName, "detail", failureDetail)
+		r.Recorder.Eventf(task, nil, corev1.EventTypeWarning, "Failed", "Failed", "Task failed: %s", failureDetail)
+	} else {
+		log.Info("task failed", "pod", task.Status.PodName)
+		r.Recorder.Eventf(task, nil, corev1.EventTypeWarning, "Failed", "Failed", "Task failed")
+	}
+
+	// Link the workspace snapshot (if one was taken) for post-mortem debugging
+	if snapshot := getWorkspaceSnapshotStatus(pod); snapshot != nil {
+		task.Status.WorkspaceSnapshot = snapshot
+		log.Info("workspace snapshot saved", "claim", snapshot.ClaimName, "path", snapshot.Path)
+		r.Recorder.Eventf(task, nil, corev1.EventTypeNormal, "WorkspaceSnapshotSaved", "Failed", "Workspace snapshot saved to PVC %s at %s", snapshot.ClaimName, snapshot.Path)
+	}
+	r.captureTaskOutputs(ctx, task, pod)
+	r.recordTaskDuration(task)
+	recordTaskStartup(task, pod)
+	// Resolve session info from Agent's OpenCode server (best-effort)
+	r.resolveSessionInfo(ctx, task)
+	if err := r.Status().Update(ctx, task); err != nil {
+		return err
+	}
+	// Spans are recorded after the update, so that a conflict does not record them twice
+	recordTaskLifecycleSpans(ctx, task, pod)
 	return nil
 }
 
+// captureTaskOutputs stores the outputs reported by a finished Pod in the Task's
+// outputs ConfigMap and records it in task.Status.Outputs.
+// This is best-effort: failures are logged and reported as events but do not
+// change the Task's phase.
+func (r *TaskReconciler) captureTaskOutputs(ctx context.Context, task *kubeopenv1alpha1.Task, pod *corev1.Pod) {
+	log := log.FromContext(ctx)
+
+	if !task.Spec.CaptureOutputs {
+		return
+	}
+	files, err := getTaskOutputs(pod)
+	uploaded := false
+	if err == nil && files == nil {
+		// With server delivery, the Pod uploaded its outputs into the ConfigMap itself
+		files, err = r.getUploadedTaskOutputs(ctx, task)
+		if err == nil && files == nil {
+			return
+		}
+		uploaded = true
+	}
+	if content, ok := files[TaskResultFile]; ok {
+		r.captureTaskResult(ctx, task, content)
+	}
+	if err == nil && !uploaded {
+		err = r.Create(ctx, BuildTaskOutputsConfigMap(task, files))
+		if errors.IsAlreadyExists(err) {
+			err = nil
+		}
+	}
+	if err != nil {
+		log.Error(err, "unable to capture task outputs")
+		r.Recorder.Eventf(task, nil, corev1.EventTypeWarning, "OutputsCaptureFailed", "CaptureOutputs", "Failed to capture outputs: %v", err)
+		return
+	}
+
+	task.Status.Outputs = &kubeopenv1alpha1.TaskOutputsStatus{
+		ConfigMapName: TaskOutputsConfigMapName(task.Name),
+		Files:         taskOutputFileNames(files),
+	}
+	log.Info("task outputs captured", "configMap", task.Status.Outputs.ConfigMapName, "files", len(files))
+}
+
+// getUploadedTaskOutputs returns the outputs the server stored for task, or nil if
+// none were uploaded. The ConfigMap is read without the cache, because the upload
+// happens just before the Pod exits, and only if it is controlled by the Task.
+func (r *TaskReconciler) getUploadedTaskOutputs(ctx context.Context, task *kubeopenv1alpha1.Task) (map[string]string, error) {
+	reader := r.APIReader
+	if reader == nil {
+		reader = r.Client
+	}
+	cm := &corev1.ConfigMap{}
+	if err := reader.Get(ctx, types.NamespacedName{Name: TaskOutputsConfigMapName(task.Name), Namespace: task.Namespace}, cm); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !metav1.IsControlledBy(cm, task) {
+		return nil, nil
+	}
+	if cm.Data == nil {
+		return map[string]string{}, nil
+	}
+	return cm.Data, nil
+}
+
+// captureTaskResult records the result file reported by the agent in
+// task.Status.Result. An invalid result file is reported as an event and
+// leaves the result unset; it does not change the Task's phase.
+func (r *TaskReconciler) captureTaskResult(ctx context.Context, task *kubeopenv1alpha1.Task, content string) {
+	log := log.FromContext(ctx)
+
+	result, err := parseTaskResult(content)
+	if err != nil {
+		log.Error(err, "unable to capture task result")
+		r.Recorder.Eventf(task, nil, corev1.EventTypeWarning, "ResultInvalid", "CaptureOutputs", "Failed to capture result: %v", err)
+		return
+	}
+	task.Status.Result = result
+	log.Info("task result captured", "status", result.Status)
+}
+
 // getPodFailureDetail extracts a human-readable failure reason from a failed Pod.
 // It inspects init container and container termination states to find the first
 // non-zero exit code or OOM/Signal reason.
@@ -685,6 +1001,18 @@ func getPodFailureDetail(pod *corev1.Pod) string {
 	return ""
 }
 
+// getFailedContextInitContainer returns the name of the context init container
+// (git-init, url-fetch, vault-fetch) that failed, or "" if none failed.
+func getFailedContextInitContainer(pod *corev1.Pod) string {
+	for i := range pod.Status.InitContainerStatuses {
+		status := &pod.Status.InitContainerStatuses[i]
+		if status.State.Terminated != nil && status.State.Terminated.ExitCode != 0 && isContextInitContainer(status.Name) {
+			return status.Name
+		}
+	}
+	return ""
+}
+
 // formatTerminationDetail creates a human-readable string from a container termination state.
 func formatTerminationDetail(containerName string, term *corev1.ContainerStateTerminated) string {
 	switch {
@@ -781,14 +1109,34 @@ func (r *TaskReconciler) resolveSessionInfo(ctx context.Context, task *kubeopenv
 }
 
 // SetupWithManager sets up the controller with the Manager.
-// Pods have OwnerReferences to Tasks (same namespace), so we use Owns for automatic mapping.
+// Pods and Jobs have OwnerReferences to Tasks (same namespace), so we use Owns for automatic mapping.
 func (r *TaskReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&kubeopenv1alpha1.Task{}).
 		Owns(&corev1.Pod{}).
+		Owns(&batchv1.Job{}).
+		WithEventFilter(r.Shard.Predicate()).
+		WithOptions(crcontroller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles}).
 		Complete(r)
 }
 
+// lockAdmission locks the start of Tasks on the Agent or AgentTemplate of task and
+// returns the unlock function. Capacity and quota checks count the Tasks already
+// started, so two Tasks of one Agent must not be admitted at the same time.
+func (r *TaskReconciler) lockAdmission(task *kubeopenv1alpha1.Task) func() {
+	key := task.Namespace + "/"
+	switch {
+	case task.Spec.AgentRef != nil:
+		key += "agent/" + task.Spec.AgentRef.Name
+	case task.Spec.TemplateRef != nil:
+		key += "template/" + task.Spec.TemplateRef.Name
+	}
+	lock, _ := r.admissionLocks.LoadOrStore(key, &sync.Mutex{})
+	mu := lock.(*sync.Mutex)
+	mu.Lock()
+	return mu.Unlock
+}
+
 // getAgentConfigWithName retrieves the agent configuration and returns the agent name.
 // Agent must be in the same namespace as the Task.
 // Returns: agentConfig, agentName, error
@@ -903,7 +1251,8 @@ func (r *TaskReconciler) processAllContexts(ctx context.Context, task *kubeopenv
 	gitMounts = append(gitMounts, agentGitMounts...)
 
 	// 2. Resolve Task.contexts (appears last in task.md)
-	taskResolved, taskDirMounts, taskGitMounts, err := processContextItems(r.Client, ctx, task.Spec.Contexts, task.Namespace, cfg.workspaceDir)
+	taskContexts := nameURLContexts(task.Spec.Contexts, len(cfg.contexts))
+	taskResolved, taskDirMounts, taskGitMounts, err := processContextItems(r.Client, ctx, taskContexts, task.Namespace, cfg.workspaceDir)
 	if err != nil {
 		return nil, nil, nil, nil, fmt.Errorf("failed to resolve Task contexts: %w", err)
 	}
@@ -917,6 +1266,20 @@ func (r *TaskReconciler) processAllContexts(ctx context.Context, task *kubeopenv
 		taskDescription = *task.Spec.Description
 	}
 
+	// Render task/params variables in the description and Text contexts
+	vars := taskTextVariables(task)
+	if taskDescription, err = renderTextVariables(taskDescription, vars); err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("failed to render description: %w", err)
+	}
+	for i := range resolved {
+		if resolved[i].ctxType != string(kubeopenv1alpha1.ContextTypeText) {
+			continue
+		}
+		if resolved[i].content, err = renderTextVariables(resolved[i].content, vars); err != nil {
+			return nil, nil, nil, nil, fmt.Errorf("failed to render Text context %q: %w", resolved[i].name, err)
+		}
+	}
+
 	// Build the final content
 	// - Separate contexts with mountPath (independent files)
 	// - Contexts without mountPath are written to .kubeopencode/context.md with XML tags
@@ -944,12 +1307,22 @@ func (r *TaskReconciler) processAllContexts(ctx context.Context, task *kubeopenv
 		} else {
 			// No mountPath - append to .kubeopencode/context.md with XML tags
 			// OpenCode loads this via OPENCODE_CONFIG_CONTENT instructions injection
+			content := rc.content
+			if task.Spec.TemplateRef != nil {
+				// Only templateRef Pods run the resource-snapshot init container
+				content += rc.snapshots
+			}
 			xmlTag := fmt.Sprintf("<context name=%q namespace=%q type=%q>\n%s\n</context>",
-				rc.name, rc.namespace, rc.ctxType, rc.content)
+				rc.name, rc.namespace, rc.ctxType, content)
 			contextParts = append(contextParts, xmlTag)
 		}
 	}
 
+	// Pod-mode Tasks have no session to prompt, so messages are delivered as files
+	if task.Spec.TemplateRef != nil {
+		contextParts = append(contextParts, fmt.Sprintf(taskMessagesPrompt, cfg.workspaceDir+"/"+TaskMessagesDir))
+	}
+
 	// Create task.md if there's any content (description only)
 	// Mount at the configured workspace directory
 	taskMdPath := cfg.workspaceDir + "/task.md"
@@ -984,6 +1357,11 @@ func (r *TaskReconciler) processAllContexts(ctx context.Context, task *kubeopenv
 	}
 	gitMounts = append(gitMounts, skillGitMounts...)
 
+	// Fail with a clear error instead of a ConfigMap creation error from the API server
+	if err := validateContextConfigMapSize(configMapData); err != nil {
+		return nil, nil, nil, nil, err
+	}
+
 	// Create ConfigMap if there's any content
 	// ConfigMap is created in Agent's namespace (where Pod runs)
 	var configMap *corev1.ConfigMap
@@ -1022,7 +1400,7 @@ func (r *TaskReconciler) processAllContexts(ctx context.Context, task *kubeopenv
 	// Validate mount path conflicts
 	// Multiple contexts mounting to the same path would silently overwrite each other,
 	// so we detect and report conflicts explicitly.
-	if err := validateMountPathConflicts(fileMounts, dirMounts, gitMounts); err != nil {
+	if err := validateMountPathConflicts(fileMounts, dirMounts, gitMounts, cfg.urlFetches); err != nil {
 		return nil, nil, nil, nil, err
 	}
 
@@ -1030,29 +1408,41 @@ func (r *TaskReconciler) processAllContexts(ctx context.Context, task *kubeopenv
 }
 
 // validateMountPathConflicts checks for duplicate mount paths across all mount types.
+// Paths are compared after cleaning, so "/workspace/src" and "/workspace/src/" conflict.
 // Returns an error if any two mounts target the same path.
-func validateMountPathConflicts(fileMounts []fileMount, dirMounts []dirMount, gitMounts []gitMount) error {
-	mountPaths := make(map[string]string) // path -> source description
+func validateMountPathConflicts(fileMounts []fileMount, dirMounts []dirMount, gitMounts []gitMount, urlFetches []urlFetchSpec) error {
+	mountPaths := make(map[string]string) // cleaned path -> source description
 
 	for _, fm := range fileMounts {
-		if existing, ok := mountPaths[fm.filePath]; ok {
-			return fmt.Errorf("mount path conflict: %q is used by both %s and a file mount", fm.filePath, existing)
+		p := path.Clean(fm.filePath)
+		if existing, ok := mountPaths[p]; ok {
+			return fmt.Errorf("mount path conflict: %q is used by both %s and a file mount", p, existing)
 		}
-		mountPaths[fm.filePath] = "file mount"
+		mountPaths[p] = "file mount"
 	}
 
 	for _, dm := range dirMounts {
-		if existing, ok := mountPaths[dm.dirPath]; ok {
-			return fmt.Errorf("mount path conflict: %q is used by both %s and directory mount (ConfigMap: %s)", dm.dirPath, existing, dm.configMapName)
+		p := path.Clean(dm.dirPath)
+		if existing, ok := mountPaths[p]; ok {
+			return fmt.Errorf("mount path conflict: %q is used by both %s and directory mount (ConfigMap: %s)", p, existing, dm.configMapName)
 		}
-		mountPaths[dm.dirPath] = fmt.Sprintf("directory mount (ConfigMap: %s)", dm.configMapName)
+		mountPaths[p] = fmt.Sprintf("directory mount (ConfigMap: %s)", dm.configMapName)
 	}
 
 	for _, gm := range gitMounts {
-		if existing, ok := mountPaths[gm.mountPath]; ok {
-			return fmt.Errorf("mount path conflict: %q is used by both %s and git mount (%s)", gm.mountPath, existing, gm.contextName)
+		p := path.Clean(gm.mountPath)
+		if existing, ok := mountPaths[p]; ok {
+			return fmt.Errorf("mount path conflict: %q is used by both %s and git mount (%s)", p, existing, gm.contextName)
 		}
-		mountPaths[gm.mountPath] = fmt.Sprintf("git mount (%s)", gm.contextName)
+		mountPaths[p] = fmt.Sprintf("git mount (%s)", gm.contextName)
+	}
+
+	for _, spec := range urlFetches {
+		p := path.Clean(spec.targetPath)
+		if existing, ok := mountPaths[p]; ok {
+			return fmt.Errorf("mount path conflict: %q is used by both %s and url fetch (%s)", p, existing, spec.name)
+		}
+		mountPaths[p] = fmt.Sprintf("url fetch (%s)", spec.name)
 	}
 
 	return nil
@@ -1206,10 +1596,32 @@ func (r *TaskReconciler) handleQueuedTask(ctx context.Context, task *kubeopenv1a
 		}
 
 		if !hasCapacity {
+			if agentCfg.preemptionPolicy == kubeopenv1alpha1.PreemptionPolicyLowerPriority {
+				preempted, err := r.preemptLowerPriorityTask(ctx, task, agentName)
+				if err != nil {
+					log.Error(err, "unable to preempt lower-priority task")
+					return ctrl.Result{}, err
+				}
+				if preempted {
+					// A capacity slot was freed, retry admission
+					return ctrl.Result{Requeue: true}, nil
+				}
+			}
 			// Still at capacity, requeue
 			log.V(1).Info("agent still at capacity, remaining queued", "agent", agentName)
 			return ctrl.Result{RequeueAfter: DefaultQueuedRequeueDelay}, nil
 		}
+
+		// Leave free capacity to queued Tasks with a higher priority
+		blocked, err := r.higherPriorityTaskQueued(ctx, task, agentName)
+		if err != nil {
+			log.Error(err, "unable to check queued task priorities")
+			return ctrl.Result{}, err
+		}
+		if blocked {
+			log.V(1).Info("higher-priority task queued, remaining queued", "agent", agentName)
+			return ctrl.Result{RequeueAfter: DefaultQueuedRequeueDelay}, nil
+		}
 	}
 
 	// Check agent quota if configured
@@ -1259,6 +1671,10 @@ func (r *TaskReconciler) handleQueuedTask(ctx context.Context, task *kubeopenv1a
 
 	// Capacity available, transition to empty phase to trigger initializeTask
 	log.Info("agent capacity available, transitioning to initialize", "agent", agentName)
+	var queuedSince time.Time
+	if queued := meta.FindStatusCondition(task.Status.Conditions, kubeopenv1alpha1.ConditionTypeQueued); queued != nil && queued.Status == metav1.ConditionTrue {
+		queuedSince = queued.LastTransitionTime.Time
+	}
 	task.Status.Phase = ""
 	meta.SetStatusCondition(&task.Status.Conditions, metav1.Condition{
 		Type:    kubeopenv1alpha1.ConditionTypeQueued,
@@ -1271,6 +1687,9 @@ func (r *TaskReconciler) handleQueuedTask(ctx context.Context, task *kubeopenv1a
 		log.Error(err, "unable to update queued task status")
 		return ctrl.Result{}, err
 	}
+	if !queuedSince.IsZero() {
+		TaskQueueWaitSeconds.WithLabelValues(task.Namespace, metricsAgentName(task)).Observe(time.Since(queuedSince).Seconds())
+	}
 
 	// Requeue immediately to trigger initializeTask
 	return ctrl.Result{Requeue: true}, nil
@@ -1283,6 +1702,15 @@ func (r *TaskReconciler) handleStop(ctx context.Context, task *kubeopenv1alpha1.
 	log := log.FromContext(ctx)
 	log.Info("user-initiated stop detected", "task", task.Name)
 
+	// Delete the Job first (if any), so that it does not replace the deleted Pod
+	if task.Status.JobName != "" {
+		if err := r.deleteTaskJob(ctx, task.Namespace, task.Status.JobName); err != nil {
+			log.Error(err, "failed to delete job")
+			return ctrl.Result{}, err
+		}
+		log.Info("deleted job for stopped task", "job", task.Status.JobName)
+	}
+
 	// Delete the Pod if it exists
 	if task.Status.PodName != "" {
 		pod := &corev1.Pod{}
@@ -1334,6 +1762,15 @@ func (r *TaskReconciler) handleTimeout(ctx context.Context, task *kubeopenv1alph
 	timeoutDuration := task.Spec.Timeout.Duration
 	log.Info("task timeout exceeded", "task", task.Name, "timeout", timeoutDuration)
 
+	// Delete the Job first (if any), so that it does not replace the deleted Pod
+	if task.Status.JobName != "" {
+		if err := r.deleteTaskJob(ctx, task.Namespace, task.Status.JobName); err != nil {
+			log.Error(err, "failed to delete job")
+			return ctrl.Result{}, err
+		}
+		log.Info("deleted job for timed out task", "job", task.Status.JobName)
+	}
+
 	// Delete the Pod if it exists
 	if task.Status.PodName != "" {
 		pod := &corev1.Pod{}
@@ -1418,6 +1855,18 @@ func resolveSystemConfig(ctx context.Context, reader client.Reader) systemConfig
 
 	cfg.observability = config.Spec.Observability
 
+	cfg.contextCache = config.Spec.ContextCache
+
+	cfg.shareContextConfigMaps = config.Spec.ShareContextConfigMaps
+
+	cfg.podSecurityDefaults = config.Spec.PodSecurityDefaults
+
+	cfg.imageRewrites = config.Spec.ImageRewrites
+
+	cfg.networkPolicy = config.Spec.NetworkPolicy
+
+	cfg.outputs = config.Spec.Outputs
+
 	return cfg
 }
 
@@ -1434,9 +1883,9 @@ func (r *TaskReconciler) handleTaskCleanup(ctx context.Context, task *kubeopenv1
 		return ctrl.Result{}, nil
 	}
 
-	// Check TTL-based cleanup
-	if cleanupConfig.TTLSecondsAfterFinished != nil {
-		result, deleted, err := r.checkTTLCleanup(ctx, task, *cleanupConfig.TTLSecondsAfterFinished)
+	// Check TTL-based cleanup; kept Tasks are never deleted
+	if cleanupConfig.TTLSecondsAfterFinished != nil && !isTaskKept(task) {
+		result, deleted, err := r.checkTTLCleanup(ctx, task, *cleanupConfig.TTLSecondsAfterFinished, cleanupConfig.Archive)
 		if err != nil {
 			log.Error(err, "failed to check TTL cleanup")
 			return ctrl.Result{}, err
@@ -1451,7 +1900,7 @@ func (r *TaskReconciler) handleTaskCleanup(ctx context.Context, task *kubeopenv1
 			// Schedule requeue for TTL expiration
 			// Also check retention cleanup before returning
 			if cleanupConfig.MaxRetainedTasks != nil {
-				if err := r.checkRetentionCleanup(ctx, task.Namespace, *cleanupConfig.MaxRetainedTasks); err != nil {
+				if err := r.checkRetentionCleanup(ctx, task.Namespace, *cleanupConfig.MaxRetainedTasks, cleanupConfig.Archive); err != nil {
 					log.Error(err, "failed to check retention cleanup")
 					return ctrl.Result{}, err
 				}
@@ -1462,7 +1911,7 @@ func (r *TaskReconciler) handleTaskCleanup(ctx context.Context, task *kubeopenv1
 
 	// Check retention-based cleanup
 	if cleanupConfig.MaxRetainedTasks != nil {
-		if err := r.checkRetentionCleanup(ctx, task.Namespace, *cleanupConfig.MaxRetainedTasks); err != nil {
+		if err := r.checkRetentionCleanup(ctx, task.Namespace, *cleanupConfig.MaxRetainedTasks, cleanupConfig.Archive); err != nil {
 			log.Error(err, "failed to check retention cleanup")
 			return ctrl.Result{}, err
 		}
@@ -1493,7 +1942,8 @@ func (r *TaskReconciler) getCleanupConfig(ctx context.Context) *kubeopenv1alpha1
 
 // checkTTLCleanup checks if the Task should be deleted based on TTL.
 // Returns (result, deleted, error) where deleted is true if the Task was deleted.
-func (r *TaskReconciler) checkTTLCleanup(ctx context.Context, task *kubeopenv1alpha1.Task, ttlSeconds int32) (ctrl.Result, bool, error) {
+// If archive is set, the Task is archived before it is deleted.
+func (r *TaskReconciler) checkTTLCleanup(ctx context.Context, task *kubeopenv1alpha1.Task, ttlSeconds int32, archive *kubeopenv1alpha1.CleanupArchiveConfig) (ctrl.Result, bool, error) {
 	log := log.FromContext(ctx)
 
 	// CompletionTime must be set for TTL calculation
@@ -1513,6 +1963,9 @@ func (r *TaskReconciler) checkTTLCleanup(ctx context.Context, task *kubeopenv1al
 			"ttlSeconds", ttlSeconds,
 			"elapsedSeconds", int(elapsed.Seconds()))
 
+		if err := r.archiveTaskBeforeCleanup(ctx, archive, task, "ttl"); err != nil {
+			return ctrl.Result{}, false, err
+		}
 		if err := r.Delete(ctx, task); err != nil {
 			if errors.IsNotFound(err) {
 				// Already deleted, that's fine
@@ -1520,6 +1973,7 @@ func (r *TaskReconciler) checkTTLCleanup(ctx context.Context, task *kubeopenv1al
 			}
 			return ctrl.Result{}, false, err
 		}
+		TaskCleanupDeletionsTotal.WithLabelValues(task.Namespace, "ttl").Inc()
 		return ctrl.Result{}, true, nil
 	}
 
@@ -1534,7 +1988,8 @@ func (r *TaskReconciler) checkTTLCleanup(ctx context.Context, task *kubeopenv1al
 
 // checkRetentionCleanup checks if any Tasks should be deleted based on retention count.
 // Deletes the oldest completed/failed Tasks (by CompletionTime) if count exceeds limit.
-func (r *TaskReconciler) checkRetentionCleanup(ctx context.Context, namespace string, maxRetained int32) error {
+// If archive is set, each Task is archived before it is deleted.
+func (r *TaskReconciler) checkRetentionCleanup(ctx context.Context, namespace string, maxRetained int32, archive *kubeopenv1alpha1.CleanupArchiveConfig) error {
 	log := log.FromContext(ctx)
 
 	// List all Tasks in the namespace
@@ -1543,11 +1998,13 @@ func (r *TaskReconciler) checkRetentionCleanup(ctx context.Context, namespace st
 		return err
 	}
 
-	// Filter completed/failed Tasks with CompletionTime set
+	// Filter completed/failed Tasks with CompletionTime set.
+	// Kept Tasks are neither deleted nor counted against the limit.
 	var completedTasks []kubeopenv1alpha1.Task
 	for _, task := range taskList.Items {
 		if isTaskFinished(task.Status.Phase) &&
-			task.Status.CompletionTime != nil {
+			task.Status.CompletionTime != nil &&
+			!isTaskKept(&task) {
 			completedTasks = append(completedTasks, task)
 		}
 	}
@@ -1582,6 +2039,9 @@ func (r *TaskReconciler) checkRetentionCleanup(ctx context.Context, namespace st
 			"task", taskToDelete.Name,
 			"completionTime", taskToDelete.Status.CompletionTime.Time)
 
+		if err := r.archiveTaskBeforeCleanup(ctx, archive, taskToDelete, "retention"); err != nil {
+			return err
+		}
 		if err := r.Delete(ctx, taskToDelete); err != nil {
 			if errors.IsNotFound(err) {
 				// Already deleted, continue
@@ -1589,6 +2049,7 @@ func (r *TaskReconciler) checkRetentionCleanup(ctx context.Context, namespace st
 			}
 			return err
 		}
+		TaskCleanupDeletionsTotal.WithLabelValues(namespace, "retention").Inc()
 	}
 
 	return nil
@@ -1669,32 +2130,74 @@ func (r *TaskReconciler) checkAgentQuota(ctx context.Context, agent *kubeopenv1a
 	return true, 0, nil
 }
 
+// queueTaskForQuota queues task until the quota window of its Agent frees a slot.
+func (r *TaskReconciler) queueTaskForQuota(ctx context.Context, task *kubeopenv1alpha1.Task, agentName string, quota *kubeopenv1alpha1.QuotaConfig, requeueDelay time.Duration) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	log.Info("agent quota exceeded, queueing task",
+		"agent", agentName,
+		"maxTaskStarts", quota.MaxTaskStarts,
+		"windowSeconds", quota.WindowSeconds)
+	r.Recorder.Eventf(task, nil, corev1.EventTypeNormal, "QuotaExceeded", "Queued", "Agent %q quota exceeded (max: %d per %ds), task queued", agentName, quota.MaxTaskStarts, quota.WindowSeconds)
+
+	task.Status.ObservedGeneration = task.Generation
+	task.Status.Phase = kubeopenv1alpha1.TaskPhaseQueued
+	task.Status.AgentRef = &kubeopenv1alpha1.AgentReference{
+		Name: agentName,
+	}
+	task.Status.StartTime = nil
+	task.Status.Session = nil
+
+	meta.SetStatusCondition(&task.Status.Conditions, metav1.Condition{
+		Type:   kubeopenv1alpha1.ConditionTypeQueued,
+		Status: metav1.ConditionTrue,
+		Reason: kubeopenv1alpha1.ReasonQuotaExceeded,
+		Message: fmt.Sprintf("Waiting for agent %q quota (max: %d per %ds)",
+			agentName, quota.MaxTaskStarts, quota.WindowSeconds),
+	})
+
+	if err := r.Status().Update(ctx, task); err != nil {
+		log.Error(err, "unable to update Task status")
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{RequeueAfter: requeueDelay}, nil
+}
+
 // recordTaskStart adds a TaskStartRecord to the Agent's status.
 // Uses retry logic for optimistic concurrency conflicts in HA mode.
-func (r *TaskReconciler) recordTaskStart(ctx context.Context, agent *kubeopenv1alpha1.Agent, task *kubeopenv1alpha1.Task) error {
+//
+// The window is checked again against the Agent read for the update, so the
+// persisted history stays the source of truth even when the quota check ran on
+// stale data (e.g. right after a controller restart or leader failover).
+// Returns (recorded, requeueAfter, error); recorded=false means the window is full.
+func (r *TaskReconciler) recordTaskStart(ctx context.Context, agent *kubeopenv1alpha1.Agent, task *kubeopenv1alpha1.Task) (bool, time.Duration, error) {
 	log := log.FromContext(ctx)
 
 	if agent.Spec.Quota == nil {
-		return nil
+		return true, 0, nil
 	}
 
 	for i := 0; i < quotaStatusUpdateRetries; i++ {
 		// Fetch fresh Agent
 		freshAgent := &kubeopenv1alpha1.Agent{}
 		if err := r.Get(ctx, types.NamespacedName{Name: agent.Name, Namespace: agent.Namespace}, freshAgent); err != nil {
-			return err
+			return false, 0, err
 		}
 
 		// Check if quota is still configured (could be removed between retries)
 		if freshAgent.Spec.Quota == nil {
-			return nil
+			return true, 0, nil
 		}
 
-		// Prune old records and add new one
+		// Prune old records and add new one, unless the window is already full
 		freshAgent.Status.TaskStartHistory = pruneTaskStartHistory(
 			freshAgent.Status.TaskStartHistory,
 			freshAgent.Spec.Quota.WindowSeconds,
 		)
+		if int32(len(freshAgent.Status.TaskStartHistory)) >= freshAgent.Spec.Quota.MaxTaskStarts { //nolint:gosec // len() is always non-negative and bounded by slice capacity
+			return false, calculateQuotaRequeueDelay(freshAgent.Status.TaskStartHistory, freshAgent.Spec.Quota.WindowSeconds), nil
+		}
 		freshAgent.Status.TaskStartHistory = append(freshAgent.Status.TaskStartHistory, kubeopenv1alpha1.TaskStartRecord{
 			TaskName:      task.Name,
 			TaskNamespace: task.Namespace,
@@ -1707,17 +2210,17 @@ func (r *TaskReconciler) recordTaskStart(ctx context.Context, agent *kubeopenv1a
 				log.V(1).Info("conflict updating agent status, retrying", "retry", i+1)
 				continue
 			}
-			return err
+			return false, 0, err
 		}
 
 		log.V(1).Info("recorded task start for quota",
 			"agent", agent.Name,
 			"task", task.Name,
 			"historySize", len(freshAgent.Status.TaskStartHistory))
-		return nil
+		return true, 0, nil
 	}
 
-	return fmt.Errorf("failed to record task start after %d retries", quotaStatusUpdateRetries)
+	return false, 0, fmt.Errorf("failed to record task start after %d retries", quotaStatusUpdateRetries)
 }
 
 // removeTaskStart removes a task start record from Agent status.
@@ -1770,9 +2273,25 @@ func (r *TaskReconciler) recordTaskDuration(task *kubeopenv1alpha1.Task) {
 		return
 	}
 	duration := task.Status.CompletionTime.Time.Sub(task.Status.StartTime.Time).Seconds()
-	agentName := ""
+	TaskDurationSeconds.WithLabelValues(task.Namespace, metricsAgentName(task)).Observe(duration)
+}
+
+// recordTaskStartup records the time from task creation until the containers of its
+// finished Pod were running. The time is only known once the Pod has finished.
+func recordTaskStartup(task *kubeopenv1alpha1.Task, pod *corev1.Pod) {
+	running := podContainersStarted(pod)
+	if running.IsZero() || running.Before(task.CreationTimestamp.Time) {
+		return
+	}
+	startup := running.Sub(task.CreationTimestamp.Time).Seconds()
+	TaskStartupSeconds.WithLabelValues(task.Namespace, metricsAgentName(task)).Observe(startup)
+}
+
+// metricsAgentName returns the agent label of task metrics, which is empty for
+// tasks without a resolved Agent.
+func metricsAgentName(task *kubeopenv1alpha1.Task) string {
 	if task.Status.AgentRef != nil {
-		agentName = task.Status.AgentRef.Name
+		return task.Status.AgentRef.Name
 	}
-	TaskDurationSeconds.WithLabelValues(task.Namespace, agentName).Observe(duration)
+	return ""
 }
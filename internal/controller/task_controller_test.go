@@ -2848,12 +2848,76 @@ var _ = Describe("TaskController", func() {
 		})
 	})
 
-	// NOTE: URL Context type is defined in the API (ContextTypeURL) but not yet implemented
-	// in the controller. This test is marked as Pending until the feature is implemented.
 	Context("URL Context", func() {
-		PIt("Should create url-fetch init container for URL context (TODO: implement URL context type)", func() {
-			// This test verifies URL context type creates a url-fetch init container.
-			// Currently returns "unknown context type: URL" error.
+		It("Should create url-fetch init container for URL context", func() {
+			templateName := "test-url-template"
+			taskName := "test-task-url-context"
+			description := "Test URL context"
+			digest := "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+
+			By("Creating an AgentTemplate")
+			tmpl := &kubeopenv1alpha1.AgentTemplate{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      templateName,
+					Namespace: taskNamespace,
+				},
+				Spec: kubeopenv1alpha1.AgentTemplateSpec{
+					ExecutorImage:      "test-executor:latest",
+					WorkspaceDir:       "/workspace",
+					ServiceAccountName: "default",
+				},
+			}
+			Expect(k8sClient.Create(ctx, tmpl)).Should(Succeed())
+
+			By("Creating Task with URL context")
+			task := &kubeopenv1alpha1.Task{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      taskName,
+					Namespace: taskNamespace,
+				},
+				Spec: kubeopenv1alpha1.TaskSpec{
+					TemplateRef: &kubeopenv1alpha1.AgentTemplateReference{Name: templateName},
+					Description: &description,
+					Contexts: []kubeopenv1alpha1.ContextItem{
+						{
+							Name: "openapi-spec",
+							Type: kubeopenv1alpha1.ContextTypeURL,
+							URL: &kubeopenv1alpha1.URLContext{
+								Source: "https://api.example.com/openapi.yaml",
+								SHA256: digest,
+							},
+							MountPath: "specs/openapi.yaml",
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, task)).Should(Succeed())
+
+			By("Checking Pod has url-fetch-0 container")
+			podLookupKey := types.NamespacedName{Name: fmt.Sprintf("%s-pod", taskName), Namespace: taskNamespace}
+			createdPod := &corev1.Pod{}
+			Eventually(func() bool {
+				return k8sClient.Get(ctx, podLookupKey, createdPod) == nil
+			}, timeout, interval).Should(BeTrue())
+
+			var urlFetch *corev1.Container
+			for i := range createdPod.Spec.InitContainers {
+				if createdPod.Spec.InitContainers[i].Name == "url-fetch-0" {
+					urlFetch = &createdPod.Spec.InitContainers[i]
+				}
+			}
+			Expect(urlFetch).ShouldNot(BeNil(), "Expected url-fetch-0 container")
+			envMap := make(map[string]string)
+			for _, env := range urlFetch.Env {
+				envMap[env.Name] = env.Value
+			}
+			Expect(envMap).Should(HaveKeyWithValue("URL_SOURCE", "https://api.example.com/openapi.yaml"))
+			Expect(envMap).Should(HaveKeyWithValue("URL_TARGET", "/workspace/specs/openapi.yaml"))
+			Expect(envMap).Should(HaveKeyWithValue("URL_SHA256", digest))
+
+			By("Cleaning up")
+			Expect(k8sClient.Delete(ctx, task)).Should(Succeed())
+			Expect(k8sClient.Delete(ctx, tmpl)).Should(Succeed())
 		})
 	})
 
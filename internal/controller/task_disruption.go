@@ -0,0 +1,148 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+)
+
+// DefaultMaxReschedules is the number of times a disrupted Task is rescheduled
+// when its disruption policy does not set maxReschedules
+const DefaultMaxReschedules int32 = 3
+
+// taskPodName returns the name of the Pod running task. Rescheduled runs get a
+// new name, so that the new Pod never waits for, or adopts, the lost one.
+func taskPodName(task *kubeopenv1alpha1.Task) string {
+	if task.Status.Reschedules > 0 {
+		return fmt.Sprintf("%s-pod-%d", task.Name, task.Status.Reschedules)
+	}
+	return fmt.Sprintf("%s-pod", task.Name)
+}
+
+// podDisruptionMessage describes why a failed pod was lost to its node rather
+// than to the agent exiting, or returns "" if it was not.
+func podDisruptionMessage(pod *corev1.Pod) string {
+	if pod.Status.Phase != corev1.PodFailed {
+		return ""
+	}
+	if pod.Status.Reason == "Evicted" {
+		return fmt.Sprintf("Pod %s was evicted: %s", pod.Name, pod.Status.Message)
+	}
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.DisruptionTarget && cond.Status == corev1.ConditionTrue {
+			return fmt.Sprintf("Pod %s was disrupted (%s): %s", pod.Name, cond.Reason, cond.Message)
+		}
+	}
+	return ""
+}
+
+// handleLostPod handles the Pod of a running task that is not found in the
+// cache. The Pod is looked up again without the cache, because a Pod created
+// moments ago may not be cached yet; only a Pod that is really gone counts as
+// disrupted.
+func (r *TaskReconciler) handleLostPod(ctx context.Context, task *kubeopenv1alpha1.Task) error {
+	reader := r.APIReader
+	if reader == nil {
+		reader = r.Client
+	}
+	pod := &corev1.Pod{}
+	if err := reader.Get(ctx, types.NamespacedName{Name: task.Status.PodName, Namespace: task.Namespace}, pod); err == nil {
+		return nil
+	} else if !errors.IsNotFound(err) {
+		return err
+	}
+	return r.handlePodDisruption(ctx, task, nil, fmt.Sprintf("Pod %s was deleted", task.Status.PodName))
+}
+
+// handlePodDisruption applies the disruption policy of task after its Pod was
+// lost to its node, or its Job was deleted. pod is nil if the Pod is gone. Within
+// the policy's maxReschedules the Task stays Running without a Pod or Job, so that
+// the next reconcile creates a new one; otherwise it fails with reason InfrastructureError.
+func (r *TaskReconciler) handlePodDisruption(ctx context.Context, task *kubeopenv1alpha1.Task, pod *corev1.Pod, message string) error {
+	log := log.FromContext(ctx)
+
+	policy := r.getDisruptionPolicy(ctx, task)
+	maxReschedules := DefaultMaxReschedules
+	if policy != nil && policy.MaxReschedules != nil {
+		maxReschedules = *policy.MaxReschedules
+	}
+	if policy == nil || policy.Action != kubeopenv1alpha1.DisruptionActionReschedule || task.Status.Reschedules >= maxReschedules {
+		meta.SetStatusCondition(&task.Status.Conditions, metav1.Condition{
+			Type:    kubeopenv1alpha1.ConditionTypeReady,
+			Status:  metav1.ConditionFalse,
+			Reason:  kubeopenv1alpha1.ReasonInfrastructureError,
+			Message: message,
+		})
+		return r.markTaskFailed(ctx, task, pod)
+	}
+
+	log.Info("rescheduling disrupted task", "pod", task.Status.PodName, "reschedules", task.Status.Reschedules+1, "reason", message)
+
+	// Find the session of the lost run, so that the new Pod continues it
+	r.resolveSessionInfo(ctx, task)
+
+	podName := task.Status.PodName
+	task.Status.ObservedGeneration = task.Generation
+	task.Status.Reschedules++
+	task.Status.PodName = ""
+	task.Status.JobName = ""
+	meta.SetStatusCondition(&task.Status.Conditions, metav1.Condition{
+		Type:    kubeopenv1alpha1.ConditionTypeReady,
+		Status:  metav1.ConditionFalse,
+		Reason:  kubeopenv1alpha1.ReasonInfrastructureError,
+		Message: fmt.Sprintf("%s; rescheduling (%d/%d)", message, task.Status.Reschedules, maxReschedules),
+	})
+	if err := r.Status().Update(ctx, task); err != nil {
+		return err
+	}
+
+	if pod != nil {
+		if err := r.Delete(ctx, pod); err != nil && !errors.IsNotFound(err) {
+			log.Error(err, "unable to delete disrupted pod", "pod", podName)
+		}
+	}
+
+	// The new Pod records a new start, so the lost run does not count against the quota
+	if task.Status.AgentRef != nil {
+		if agent, err := r.getAgentForQuota(ctx, task.Status.AgentRef.Name, task.Namespace); err == nil {
+			if err := r.removeTaskStart(ctx, agent, task); err != nil {
+				log.Error(err, "unable to remove quota record of disrupted task")
+			}
+		}
+	}
+
+	r.Recorder.Eventf(task, nil, corev1.EventTypeWarning, "Rescheduled", "Reschedule",
+		"%s, rescheduling task (%d/%d)", message, task.Status.Reschedules, maxReschedules)
+	TaskReschedulesTotal.WithLabelValues(task.Namespace, metricsAgentName(task)).Inc()
+	return nil
+}
+
+// getDisruptionPolicy returns the disruption policy of the Agent or AgentTemplate
+// of task, or nil if none is configured or it cannot be resolved.
+func (r *TaskReconciler) getDisruptionPolicy(ctx context.Context, task *kubeopenv1alpha1.Task) *kubeopenv1alpha1.DisruptionPolicy {
+	var cfg agentConfig
+	var err error
+	if task.Spec.TemplateRef != nil {
+		cfg, _, err = r.resolveTemplateConfig(ctx, task)
+	} else {
+		cfg, _, err = r.getAgentConfigWithName(ctx, task)
+	}
+	if err != nil {
+		log.FromContext(ctx).Error(err, "unable to resolve disruption policy, failing task")
+		return nil
+	}
+	if cfg.execution == nil {
+		return nil
+	}
+	return cfg.execution.OnDisruption
+}
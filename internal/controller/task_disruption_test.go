@@ -0,0 +1,171 @@
+// Copyright Contributors to the KubeOpenCode project
+
+//go:build !integration
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/events"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+)
+
+func TestPodDisruptionMessage(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    corev1.PodStatus
+		disrupted bool
+	}{
+		{"running", corev1.PodStatus{Phase: corev1.PodRunning}, false},
+		{"agent failed", corev1.PodStatus{Phase: corev1.PodFailed}, false},
+		{"evicted", corev1.PodStatus{Phase: corev1.PodFailed, Reason: "Evicted", Message: "The node was low on resource: memory."}, true},
+		{"disruption target", corev1.PodStatus{Phase: corev1.PodFailed, Conditions: []corev1.PodCondition{{
+			Type: corev1.DisruptionTarget, Status: corev1.ConditionTrue, Reason: "DeletionByTaintManager",
+		}}}, true},
+		{"disruption target while running", corev1.PodStatus{Phase: corev1.PodRunning, Conditions: []corev1.PodCondition{{
+			Type: corev1.DisruptionTarget, Status: corev1.ConditionTrue,
+		}}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "fix-pod"}, Status: tt.status}
+			if got := podDisruptionMessage(pod); (got != "") != tt.disrupted {
+				t.Errorf("podDisruptionMessage() = %q, want disrupted=%v", got, tt.disrupted)
+			}
+		})
+	}
+}
+
+func TestHandlePodDisruption(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(s)
+	_ = kubeopenv1alpha1.AddToScheme(s)
+	ctx := context.Background()
+
+	evicted := func(name string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Status:     corev1.PodStatus{Phase: corev1.PodFailed, Reason: "Evicted", Message: "node drained"},
+		}
+	}
+	newReconciler := func(policy *kubeopenv1alpha1.DisruptionPolicy, reschedules int32, pod *corev1.Pod) *TaskReconciler {
+		tmpl := &kubeopenv1alpha1.AgentTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: "tmpl", Namespace: "default"},
+			Spec: kubeopenv1alpha1.AgentTemplateSpec{
+				WorkspaceDir:       "/workspace",
+				ServiceAccountName: "sa",
+				Execution:          &kubeopenv1alpha1.ExecutionConfig{OnDisruption: policy},
+			},
+		}
+		task := &kubeopenv1alpha1.Task{
+			ObjectMeta: metav1.ObjectMeta{Name: "fix", Namespace: "default", UID: "fix-uid"},
+			Spec:       kubeopenv1alpha1.TaskSpec{TemplateRef: &kubeopenv1alpha1.AgentTemplateReference{Name: "tmpl"}},
+			Status: kubeopenv1alpha1.TaskExecutionStatus{
+				Phase:       kubeopenv1alpha1.TaskPhaseRunning,
+				PodName:     taskPodName(&kubeopenv1alpha1.Task{ObjectMeta: metav1.ObjectMeta{Name: "fix"}, Status: kubeopenv1alpha1.TaskExecutionStatus{Reschedules: reschedules}}),
+				Reschedules: reschedules,
+				StartTime:   &metav1.Time{Time: time.Now().Add(-time.Hour)},
+			},
+		}
+		builder := fake.NewClientBuilder().WithScheme(s).
+			WithObjects(tmpl, task).
+			WithStatusSubresource(&kubeopenv1alpha1.Task{})
+		if pod != nil {
+			builder = builder.WithObjects(pod)
+		}
+		return &TaskReconciler{Client: builder.Build(), Recorder: events.NewFakeRecorder(10)}
+	}
+	getTask := func(r *TaskReconciler) *kubeopenv1alpha1.Task {
+		got := &kubeopenv1alpha1.Task{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: "default", Name: "fix"}, got); err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+	reschedule := &kubeopenv1alpha1.DisruptionPolicy{Action: kubeopenv1alpha1.DisruptionActionReschedule}
+
+	t.Run("evicted pod fails the task by default", func(t *testing.T) {
+		r := newReconciler(nil, 0, evicted("fix-pod"))
+		if err := r.updateTaskStatusFromPod(ctx, getTask(r)); err != nil {
+			t.Fatal(err)
+		}
+		got := getTask(r)
+		if got.Status.Phase != kubeopenv1alpha1.TaskPhaseFailed {
+			t.Errorf("phase = %s, want Failed", got.Status.Phase)
+		}
+		cond := meta.FindStatusCondition(got.Status.Conditions, kubeopenv1alpha1.ConditionTypeReady)
+		if cond == nil || cond.Reason != kubeopenv1alpha1.ReasonInfrastructureError {
+			t.Errorf("Ready condition = %+v, want reason %s", cond, kubeopenv1alpha1.ReasonInfrastructureError)
+		}
+	})
+
+	t.Run("evicted pod is rescheduled", func(t *testing.T) {
+		r := newReconciler(reschedule, 0, evicted("fix-pod"))
+		if err := r.updateTaskStatusFromPod(ctx, getTask(r)); err != nil {
+			t.Fatal(err)
+		}
+		got := getTask(r)
+		if got.Status.Phase != kubeopenv1alpha1.TaskPhaseRunning || got.Status.PodName != "" || got.Status.Reschedules != 1 {
+			t.Errorf("status = %+v, want Running without pod after 1 reschedule", got.Status)
+		}
+		if got.Status.StartTime == nil {
+			t.Error("rescheduled task should keep its start time")
+		}
+		if taskPodName(got) != "fix-pod-1" {
+			t.Errorf("next pod name = %q, want %q", taskPodName(got), "fix-pod-1")
+		}
+		err := r.Get(ctx, types.NamespacedName{Namespace: "default", Name: "fix-pod"}, &corev1.Pod{})
+		if !errors.IsNotFound(err) {
+			t.Errorf("evicted pod should be deleted, got err %v", err)
+		}
+	})
+
+	t.Run("deleted pod is rescheduled", func(t *testing.T) {
+		r := newReconciler(reschedule, 0, nil)
+		if err := r.updateTaskStatusFromPod(ctx, getTask(r)); err != nil {
+			t.Fatal(err)
+		}
+		if got := getTask(r); got.Status.Reschedules != 1 {
+			t.Errorf("reschedules = %d, want 1", got.Status.Reschedules)
+		}
+	})
+
+	t.Run("task fails after maxReschedules", func(t *testing.T) {
+		maxReschedules := int32(1)
+		r := newReconciler(&kubeopenv1alpha1.DisruptionPolicy{
+			Action:         kubeopenv1alpha1.DisruptionActionReschedule,
+			MaxReschedules: &maxReschedules,
+		}, 1, evicted("fix-pod-1"))
+		if err := r.updateTaskStatusFromPod(ctx, getTask(r)); err != nil {
+			t.Fatal(err)
+		}
+		if got := getTask(r); got.Status.Phase != kubeopenv1alpha1.TaskPhaseFailed {
+			t.Errorf("phase = %s, want Failed", got.Status.Phase)
+		}
+	})
+
+	t.Run("pod failed by the agent is not rescheduled", func(t *testing.T) {
+		pod := evicted("fix-pod")
+		pod.Status.Reason = ""
+		r := newReconciler(reschedule, 0, pod)
+		if err := r.updateTaskStatusFromPod(ctx, getTask(r)); err != nil {
+			t.Fatal(err)
+		}
+		got := getTask(r)
+		if got.Status.Phase != kubeopenv1alpha1.TaskPhaseFailed || got.Status.Reschedules != 0 {
+			t.Errorf("status = %+v, want Failed without reschedule", got.Status)
+		}
+	})
+}
@@ -0,0 +1,149 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+)
+
+// adoptExistingJob records an existing Job of task in its status, e.g. when the
+// status update after creating the Job failed. It returns whether a Job was found.
+func (r *TaskReconciler) adoptExistingJob(ctx context.Context, task *kubeopenv1alpha1.Task) (ctrl.Result, bool, error) {
+	log := log.FromContext(ctx)
+
+	job := &batchv1.Job{}
+	if err := r.Get(ctx, types.NamespacedName{Name: taskJobName(task), Namespace: task.Namespace}, job); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, false, nil
+		}
+		return ctrl.Result{}, false, err
+	}
+	if job.DeletionTimestamp != nil {
+		// The Job of a previous run (e.g., of a preempted Task) is still terminating
+		log.V(1).Info("previous Job still terminating, waiting", "job", job.Name)
+		return ctrl.Result{RequeueAfter: podTerminationRequeueDelay}, true, nil
+	}
+
+	task.Status.JobName = job.Name
+	if err := r.Status().Update(ctx, task); err != nil {
+		if errors.IsConflict(err) {
+			log.V(1).Info("conflict updating existing Job status, requeuing")
+			return ctrl.Result{Requeue: true}, true, nil
+		}
+		return ctrl.Result{}, true, err
+	}
+	return ctrl.Result{}, true, nil
+}
+
+// updateTaskStatusFromJob syncs task status from the status of its Job.
+// While the Job runs, task.Status.PodName follows the Job's most recent Pod, so
+// that logs and attach reach the Pod of a retry.
+func (r *TaskReconciler) updateTaskStatusFromJob(ctx context.Context, task *kubeopenv1alpha1.Task) error {
+	log := log.FromContext(ctx)
+
+	job := &batchv1.Job{}
+	if err := r.Get(ctx, types.NamespacedName{Name: task.Status.JobName, Namespace: task.Namespace}, job); err != nil {
+		if errors.IsNotFound(err) {
+			return r.handleLostJob(ctx, task)
+		}
+		return err
+	}
+
+	pod, err := r.latestJobPod(ctx, job)
+	if err != nil {
+		return err
+	}
+
+	cond := jobFinishedCondition(job)
+	if cond == nil {
+		if pod == nil || pod.Name == task.Status.PodName {
+			return nil
+		}
+		if task.Status.PodName != "" {
+			log.Info("job replaced task pod", "job", job.Name, "pod", pod.Name, "previousPod", task.Status.PodName)
+			r.Recorder.Eventf(task, nil, corev1.EventTypeWarning, "PodReplaced", "Retry",
+				"Job %s replaced pod %s with %s", job.Name, task.Status.PodName, pod.Name)
+		}
+		task.Status.PodName = pod.Name
+		return r.Status().Update(ctx, task)
+	}
+
+	if pod != nil {
+		task.Status.PodName = pod.Name
+	}
+	if cond.Type == batchv1.JobComplete {
+		return r.markTaskCompleted(ctx, task, pod)
+	}
+
+	reason := cond.Reason
+	if reason == "" {
+		reason = "JobFailed"
+	}
+	meta.SetStatusCondition(&task.Status.Conditions, metav1.Condition{
+		Type:    kubeopenv1alpha1.ConditionTypeReady,
+		Status:  metav1.ConditionFalse,
+		Reason:  reason,
+		Message: cond.Message,
+	})
+	return r.markTaskFailed(ctx, task, pod)
+}
+
+// handleLostJob handles the Job of a running task that is not found in the cache,
+// like handleLostPod: a Job that is really gone, e.g. deleted by hand, counts as
+// disrupted.
+func (r *TaskReconciler) handleLostJob(ctx context.Context, task *kubeopenv1alpha1.Task) error {
+	reader := r.APIReader
+	if reader == nil {
+		reader = r.Client
+	}
+	job := &batchv1.Job{}
+	if err := reader.Get(ctx, types.NamespacedName{Name: task.Status.JobName, Namespace: task.Namespace}, job); err == nil {
+		return nil
+	} else if !errors.IsNotFound(err) {
+		return err
+	}
+	return r.handlePodDisruption(ctx, task, nil, fmt.Sprintf("Job %s was deleted", task.Status.JobName))
+}
+
+// latestJobPod returns the most recently created Pod of job, or nil if it has none.
+func (r *TaskReconciler) latestJobPod(ctx context.Context, job *batchv1.Job) (*corev1.Pod, error) {
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(job.Namespace), client.MatchingLabels{batchv1.JobNameLabel: job.Name}); err != nil {
+		return nil, err
+	}
+	var latest *corev1.Pod
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if !metav1.IsControlledBy(pod, job) {
+			continue
+		}
+		if latest == nil || latest.CreationTimestamp.Before(&pod.CreationTimestamp) {
+			latest = pod
+		}
+	}
+	return latest, nil
+}
+
+// deleteTaskJob deletes the Job of a Task together with its Pods. Foreground
+// deletion keeps the Job visible until its Pods are gone, so a new run of the Task
+// waits for them like it waits for a terminating Pod.
+func (r *TaskReconciler) deleteTaskJob(ctx context.Context, namespace, name string) error {
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	if err := r.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationForeground)); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
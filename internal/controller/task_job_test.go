@@ -0,0 +1,194 @@
+// Copyright Contributors to the KubeOpenCode project
+
+//go:build !integration
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/events"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+)
+
+func TestBuildJob(t *testing.T) {
+	task := &kubeopenv1alpha1.Task{ObjectMeta: metav1.ObjectMeta{Name: "fix", Namespace: "default", UID: "fix-uid"}}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "fix-pod",
+			Namespace:       "default",
+			Labels:          map[string]string{TaskLabelKey: "fix"},
+			OwnerReferences: []metav1.OwnerReference{{Name: "fix"}},
+		},
+		Spec: corev1.PodSpec{RestartPolicy: corev1.RestartPolicyNever, Containers: []corev1.Container{{Name: "agent"}}},
+	}
+	backoffLimit := int32(2)
+	deadline := int64(3600)
+	cfg := &kubeopenv1alpha1.JobExecutionConfig{
+		BackoffLimit:          &backoffLimit,
+		ActiveDeadlineSeconds: &deadline,
+		PodFailurePolicy: &batchv1.PodFailurePolicy{Rules: []batchv1.PodFailurePolicyRule{{
+			Action:          batchv1.PodFailurePolicyActionIgnore,
+			OnPodConditions: []batchv1.PodFailurePolicyOnPodConditionsPattern{{Type: corev1.DisruptionTarget, Status: corev1.ConditionTrue}},
+		}}},
+	}
+
+	job := buildJob(task, "fix-job", pod, cfg)
+
+	if job.Name != "fix-job" || job.Namespace != "default" {
+		t.Errorf("job = %s/%s, want default/fix-job", job.Namespace, job.Name)
+	}
+	if !metav1.IsControlledBy(job, task) {
+		t.Error("job is not controlled by the task")
+	}
+	if job.Spec.Template.Name != "" || len(job.Spec.Template.OwnerReferences) != 0 {
+		t.Error("pod template must not carry the Pod's name or ownerReferences")
+	}
+	if job.Spec.Template.Labels[TaskLabelKey] != "fix" || job.Labels[TaskLabelKey] != "fix" {
+		t.Error("task label missing on job or pod template")
+	}
+	if job.Spec.Template.Spec.Containers[0].Name != "agent" {
+		t.Error("pod spec not used as template")
+	}
+	if *job.Spec.BackoffLimit != 2 || *job.Spec.ActiveDeadlineSeconds != 3600 {
+		t.Errorf("backoffLimit/activeDeadlineSeconds not passed through: %v/%v", *job.Spec.BackoffLimit, *job.Spec.ActiveDeadlineSeconds)
+	}
+	if job.Spec.PodFailurePolicy == nil || len(job.Spec.PodFailurePolicy.Rules) != 1 {
+		t.Error("podFailurePolicy not passed through")
+	}
+}
+
+func TestUpdateTaskStatusFromJob(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(s)
+	_ = kubeopenv1alpha1.AddToScheme(s)
+	ctx := context.Background()
+
+	newObjects := func(conditions ...batchv1.JobCondition) (*kubeopenv1alpha1.Task, *batchv1.Job, []*corev1.Pod) {
+		task := &kubeopenv1alpha1.Task{
+			ObjectMeta: metav1.ObjectMeta{Name: "fix", Namespace: "default", UID: "fix-uid"},
+			Status: kubeopenv1alpha1.TaskExecutionStatus{
+				Phase:     kubeopenv1alpha1.TaskPhaseRunning,
+				JobName:   "fix-job",
+				PodName:   "fix-job-first",
+				StartTime: &metav1.Time{Time: time.Now().Add(-time.Hour)},
+			},
+		}
+		job := &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "fix-job", Namespace: "default", UID: "job-uid"},
+			Status:     batchv1.JobStatus{Conditions: conditions},
+		}
+		jobPod := func(name string, age time.Duration, phase corev1.PodPhase) *corev1.Pod {
+			return &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              name,
+					Namespace:         "default",
+					Labels:            map[string]string{batchv1.JobNameLabel: "fix-job"},
+					CreationTimestamp: metav1.NewTime(time.Now().Add(-age)),
+					OwnerReferences: []metav1.OwnerReference{
+						*metav1.NewControllerRef(job, batchv1.SchemeGroupVersion.WithKind("Job")),
+					},
+				},
+				Status: corev1.PodStatus{Phase: phase},
+			}
+		}
+		return task, job, []*corev1.Pod{
+			jobPod("fix-job-first", time.Hour, corev1.PodFailed),
+			jobPod("fix-job-retry", time.Minute, corev1.PodRunning),
+		}
+	}
+	newReconciler := func(task *kubeopenv1alpha1.Task, job *batchv1.Job, pods []*corev1.Pod) *TaskReconciler {
+		builder := fake.NewClientBuilder().WithScheme(s).
+			WithObjects(task, job).
+			WithStatusSubresource(&kubeopenv1alpha1.Task{})
+		for _, pod := range pods {
+			builder = builder.WithObjects(pod)
+		}
+		return &TaskReconciler{Client: builder.Build(), Recorder: events.NewFakeRecorder(10)}
+	}
+	getTask := func(r *TaskReconciler) *kubeopenv1alpha1.Task {
+		got := &kubeopenv1alpha1.Task{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: "default", Name: "fix"}, got); err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+
+	t.Run("running job follows the retried pod", func(t *testing.T) {
+		task, job, pods := newObjects()
+		r := newReconciler(task, job, pods)
+		if err := r.updateTaskStatusFromJob(ctx, task); err != nil {
+			t.Fatal(err)
+		}
+		got := getTask(r)
+		if got.Status.Phase != kubeopenv1alpha1.TaskPhaseRunning {
+			t.Errorf("phase = %s, want Running while the job retries", got.Status.Phase)
+		}
+		if got.Status.PodName != "fix-job-retry" {
+			t.Errorf("podName = %q, want the latest pod %q", got.Status.PodName, "fix-job-retry")
+		}
+	})
+
+	t.Run("complete job completes the task", func(t *testing.T) {
+		task, job, pods := newObjects(batchv1.JobCondition{Type: batchv1.JobComplete, Status: corev1.ConditionTrue})
+		r := newReconciler(task, job, pods)
+		if err := r.updateTaskStatusFromJob(ctx, task); err != nil {
+			t.Fatal(err)
+		}
+		if got := getTask(r); got.Status.Phase != kubeopenv1alpha1.TaskPhaseCompleted {
+			t.Errorf("phase = %s, want Completed", got.Status.Phase)
+		}
+	})
+
+	t.Run("failed job fails the task with the job's reason", func(t *testing.T) {
+		task, job, pods := newObjects(batchv1.JobCondition{
+			Type:    batchv1.JobFailed,
+			Status:  corev1.ConditionTrue,
+			Reason:  "BackoffLimitExceeded",
+			Message: "Job has reached the specified backoff limit",
+		})
+		r := newReconciler(task, job, pods)
+		if err := r.updateTaskStatusFromJob(ctx, task); err != nil {
+			t.Fatal(err)
+		}
+		got := getTask(r)
+		if got.Status.Phase != kubeopenv1alpha1.TaskPhaseFailed {
+			t.Errorf("phase = %s, want Failed", got.Status.Phase)
+		}
+		cond := meta.FindStatusCondition(got.Status.Conditions, kubeopenv1alpha1.ConditionTypeReady)
+		if cond == nil || cond.Reason != "BackoffLimitExceeded" {
+			t.Errorf("Ready condition = %+v, want reason BackoffLimitExceeded", cond)
+		}
+	})
+
+	t.Run("deleted job is handled as a disruption", func(t *testing.T) {
+		task, _, _ := newObjects()
+		c := fake.NewClientBuilder().WithScheme(s).
+			WithObjects(task).
+			WithStatusSubresource(&kubeopenv1alpha1.Task{}).
+			Build()
+		r := &TaskReconciler{Client: c, APIReader: c, Recorder: events.NewFakeRecorder(10)}
+		if err := r.updateTaskStatusFromJob(ctx, task); err != nil {
+			t.Fatal(err)
+		}
+		got := getTask(r)
+		if got.Status.Phase != kubeopenv1alpha1.TaskPhaseFailed {
+			t.Errorf("phase = %s, want Failed without a disruption policy", got.Status.Phase)
+		}
+		cond := meta.FindStatusCondition(got.Status.Conditions, kubeopenv1alpha1.ConditionTypeReady)
+		if cond == nil || cond.Reason != kubeopenv1alpha1.ReasonInfrastructureError {
+			t.Errorf("Ready condition = %+v, want reason %s", cond, kubeopenv1alpha1.ReasonInfrastructureError)
+		}
+	})
+}
@@ -0,0 +1,284 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package controller
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+)
+
+const (
+	// TaskOutputsDir is the directory (relative to workspaceDir) whose top-level
+	// files are captured as Task outputs.
+	TaskOutputsDir = ".kubeopencode/outputs"
+
+	// TaskOutputsConfigMapSuffix is the suffix for the ConfigMap holding a Task's captured outputs
+	TaskOutputsConfigMapSuffix = "-outputs"
+
+	// maxTaskOutputsEncodedSize bounds the encoded outputs written to the termination
+	// message. Kubernetes truncates termination messages to 4096 bytes, and the
+	// failure snapshot line may share the same message.
+	maxTaskOutputsEncodedSize = 3584
+
+	// taskOutputsMessagePrefix prefixes the encoded outputs written to the
+	// agent container's termination message, where the controller picks them up.
+	taskOutputsMessagePrefix = "task-outputs: "
+
+	// maxTaskOutputsDecodedSize bounds the decompressed outputs read by the controller.
+	maxTaskOutputsDecodedSize = 512 * 1024
+
+	// MaxTaskOutputsUploadSize bounds the compressed outputs a Task Pod uploads to the server.
+	MaxTaskOutputsUploadSize = 1024 * 1024
+
+	// serviceAccountTokenPath is where Kubernetes mounts the Pod's ServiceAccount token.
+	serviceAccountTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+	// TaskResultFile is the output file that is parsed into the Task's status.result.
+	TaskResultFile = "result.json"
+
+	// maxTaskResultSummaryLength bounds the summary of a Task result, as in the CRD schema.
+	maxTaskResultSummaryLength = 1024
+)
+
+// taskOutputsScript runs the original command (passed as "$@") and appends the
+// top-level files of the outputs directory to the termination message as a
+// base64-encoded tar.gz. The exit code of the original command is always preserved.
+//
+// Placeholders: %[1]s outputs directory (relative to WORKSPACE_DIR), %[2]d max encoded size,
+// %[3]s message prefix, %[4]s upload script (empty without server delivery).
+const taskOutputsScript = `"$@"
+rc=$?
+dir="${WORKSPACE_DIR}/%[1]s"
+%[4]sif [ -d "$dir" ]; then
+  data=$(cd "$dir" && find . -maxdepth 1 -type f | tar -czf - -T - 2>/dev/null | base64 | tr -d '\n')
+  if [ ${#data} -gt %[2]d ]; then
+    echo "task outputs skipped: encoded size ${#data} exceeds limit of %[2]d bytes" >&2
+  elif [ -n "$data" ]; then
+    echo "%[3]s$data" >> /dev/termination-log
+  fi
+fi
+exit $rc`
+
+// taskOutputsUploadScript uploads the top-level files of the outputs directory as a
+// tar.gz to the server, authenticated with the Pod's ServiceAccount token. On
+// success it exits with the original command's exit code; otherwise
+// taskOutputsScript goes on with the termination message.
+//
+// Placeholders: %[1]s quoted server URL, %[2]s token path.
+const taskOutputsUploadScript = `if [ -d "$dir" ]; then
+  archive="${WORKSPACE_DIR}/.kubeopencode/outputs.tar.gz"
+  url=%[1]s"/api/v1/namespaces/${TASK_NAMESPACE}/tasks/${TASK_NAME}/outputs"
+  auth="Authorization: Bearer $(cat %[2]s 2>/dev/null)"
+  (cd "$dir" && find . -maxdepth 1 -type f | tar -czf - -T -) > "$archive" 2>/dev/null
+  if command -v curl >/dev/null 2>&1; then
+    curl -fsS -o /dev/null -H "$auth" -H "Content-Type: application/gzip" --data-binary "@$archive" "$url" && uploaded=1
+  elif command -v wget >/dev/null 2>&1; then
+    wget -q -O /dev/null --header "$auth" --header "Content-Type: application/gzip" --post-file "$archive" "$url" && uploaded=1
+  fi
+  rm -f "$archive"
+  [ -n "$uploaded" ] && exit $rc
+  echo "task outputs upload failed, using the termination message" >&2
+fi
+`
+
+// TaskOutputsConfigMapName returns the ConfigMap name for a Task's captured outputs.
+func TaskOutputsConfigMapName(taskName string) string {
+	return taskName + TaskOutputsConfigMapSuffix
+}
+
+// wrapCommandWithOutputCapture wraps the agent command so that the outputs directory
+// is reported through the termination message when the command exits, or uploaded
+// to the server at serverURL if it is not empty.
+// It must wrap any failure snapshot wrapper, because that wrapper overwrites the
+// termination message while this one appends to it.
+func wrapCommandWithOutputCapture(command []string, serverURL string) []string {
+	var upload string
+	if serverURL != "" {
+		upload = fmt.Sprintf(taskOutputsUploadScript, shellEscape(strings.TrimRight(serverURL, "/")), serviceAccountTokenPath)
+	}
+	script := fmt.Sprintf(taskOutputsScript, TaskOutputsDir, maxTaskOutputsEncodedSize, taskOutputsMessagePrefix, upload)
+
+	// "sh -c script sh cmd..." sets $0 to "sh" and "$@" to the original command.
+	wrapped := []string{"sh", "-c", script, "sh"}
+	return append(wrapped, command...)
+}
+
+// getTaskOutputs extracts the captured output files from a finished Pod.
+// Returns nil if no container reported outputs. Files whose names are not valid
+// ConfigMap keys are skipped.
+func getTaskOutputs(pod *corev1.Pod) (map[string]string, error) {
+	for i := range pod.Status.ContainerStatuses {
+		term := pod.Status.ContainerStatuses[i].State.Terminated
+		if term == nil {
+			continue
+		}
+		for _, line := range strings.Split(term.Message, "\n") {
+			if data, ok := strings.CutPrefix(strings.TrimSpace(line), taskOutputsMessagePrefix); ok && data != "" {
+				return decodeTaskOutputs(data)
+			}
+		}
+	}
+	return nil, nil
+}
+
+// decodeTaskOutputs decodes a base64-encoded tar.gz archive into a file name -> content map.
+func decodeTaskOutputs(data string) (map[string]string, error) {
+	raw, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid outputs encoding: %w", err)
+	}
+	return DecodeTaskOutputsArchive(raw)
+}
+
+// DecodeTaskOutputsArchive decodes a tar.gz archive of output files into a file
+// name -> content map. Files whose names are not valid ConfigMap keys are skipped.
+func DecodeTaskOutputsArchive(raw []byte) (map[string]string, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("invalid outputs archive: %w", err)
+	}
+	defer func() { _ = gz.Close() }()
+
+	files := make(map[string]string)
+	remaining := int64(maxTaskOutputsDecodedSize)
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid outputs archive: %w", err)
+		}
+		name := path.Clean(hdr.Name)
+		if hdr.Typeflag != tar.TypeReg || strings.Contains(name, "/") || len(validation.IsConfigMapKey(name)) > 0 {
+			continue
+		}
+		if hdr.Size > remaining {
+			return nil, fmt.Errorf("outputs exceed %d bytes", maxTaskOutputsDecodedSize)
+		}
+		content, err := io.ReadAll(io.LimitReader(tr, hdr.Size))
+		if err != nil {
+			return nil, fmt.Errorf("invalid outputs archive: %w", err)
+		}
+		remaining -= hdr.Size
+		files[name] = string(content)
+	}
+	return files, nil
+}
+
+// parseTaskResult parses and validates the content of a result file:
+//
+//	{"status": "Success", "summary": "...", "fields": {...}}
+//
+// status is required; unknown keys are rejected, so that typos do not go unnoticed.
+func parseTaskResult(content string) (*kubeopenv1alpha1.TaskResult, error) {
+	var file struct {
+		Status  kubeopenv1alpha1.TaskResultStatus `json:"status"`
+		Summary string                            `json:"summary"`
+		Fields  json.RawMessage                   `json:"fields"`
+	}
+	dec := json.NewDecoder(strings.NewReader(content))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&file); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", TaskResultFile, err)
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("invalid %s: unexpected data after the result object", TaskResultFile)
+	}
+
+	switch file.Status {
+	case kubeopenv1alpha1.TaskResultSuccess, kubeopenv1alpha1.TaskResultFailure, kubeopenv1alpha1.TaskResultNeutral:
+	case "":
+		return nil, fmt.Errorf("invalid %s: status is required", TaskResultFile)
+	default:
+		return nil, fmt.Errorf("invalid %s: status %q must be one of Success, Failure, Neutral", TaskResultFile, file.Status)
+	}
+	if len(file.Summary) > maxTaskResultSummaryLength {
+		return nil, fmt.Errorf("invalid %s: summary exceeds %d characters", TaskResultFile, maxTaskResultSummaryLength)
+	}
+
+	result := &kubeopenv1alpha1.TaskResult{Status: file.Status, Summary: file.Summary}
+	if fields := bytes.TrimSpace(file.Fields); len(fields) > 0 && !bytes.Equal(fields, []byte("null")) {
+		if fields[0] != '{' {
+			return nil, fmt.Errorf("invalid %s: fields must be a JSON object", TaskResultFile)
+		}
+		result.Fields = &runtime.RawExtension{Raw: fields}
+	}
+	return result, nil
+}
+
+// BuildTaskOutputsConfigMap creates the ConfigMap holding a Task's captured outputs.
+// The ConfigMap is owned by the Task, so it is deleted together with the Task.
+func BuildTaskOutputsConfigMap(task *kubeopenv1alpha1.Task, files map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      TaskOutputsConfigMapName(task.Name),
+			Namespace: task.Namespace,
+			Labels: map[string]string{
+				"app":        "kubeopencode",
+				TaskLabelKey: task.Name,
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(task, kubeopenv1alpha1.SchemeGroupVersion.WithKind("Task")),
+			},
+		},
+		Data: files,
+	}
+}
+
+// taskOutputFileNames returns the sorted file names of captured outputs.
+func taskOutputFileNames(files map[string]string) []string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// taskOutputConfigMapContext resolves a TaskOutput context to the ConfigMap context
+// over the referenced Task's captured outputs.
+// Returns nil if the context is optional and the outputs are not available.
+func taskOutputConfigMapContext(task *kubeopenv1alpha1.Task, ref *kubeopenv1alpha1.TaskOutputContext) (*kubeopenv1alpha1.ConfigMapContext, error) {
+	optional := ref.Optional != nil && *ref.Optional
+
+	var err error
+	switch {
+	case task.Status.Phase != kubeopenv1alpha1.TaskPhaseCompleted:
+		err = fmt.Errorf("task %s has not completed (phase: %q)", ref.Name, task.Status.Phase)
+	case task.Status.Outputs == nil:
+		err = fmt.Errorf("task %s has no captured outputs", ref.Name)
+	}
+	if err != nil {
+		if optional {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	cm := &kubeopenv1alpha1.ConfigMapContext{
+		Name:     task.Status.Outputs.ConfigMapName,
+		Optional: ref.Optional,
+	}
+	for _, f := range ref.Files {
+		cm.Items = append(cm.Items, kubeopenv1alpha1.ConfigMapKeyToPath{Key: f})
+	}
+	return cm, nil
+}
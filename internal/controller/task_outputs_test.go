@@ -0,0 +1,202 @@
+// Copyright Contributors to the KubeOpenCode project
+
+//go:build !integration
+
+package controller
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/events"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+)
+
+// encodeTaskOutputs builds a termination message payload the way the capture script does.
+func encodeTaskOutputs(t *testing.T, files map[string]string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+func TestWrapCommandWithOutputCapture(t *testing.T) {
+	got := wrapCommandWithOutputCapture([]string{"sh", "-c", "echo hi"}, "")
+	if len(got) != 7 || got[0] != "sh" || got[1] != "-c" || got[3] != "sh" {
+		t.Fatalf("unexpected wrapper: %v", got)
+	}
+	if !reflect.DeepEqual(got[4:], []string{"sh", "-c", "echo hi"}) {
+		t.Errorf("original command not preserved: %v", got[4:])
+	}
+	if !strings.Contains(got[2], TaskOutputsDir) || !strings.Contains(got[2], ">> /dev/termination-log") {
+		t.Errorf("script does not append outputs to termination log: %s", got[2])
+	}
+	if strings.Contains(got[2], "curl") {
+		t.Errorf("script uploads outputs without a server URL: %s", got[2])
+	}
+
+	got = wrapCommandWithOutputCapture([]string{"sh", "-c", "echo hi"}, "http://kubeopencode-server.kubeopencode-system.svc:2746/")
+	url := `'http://kubeopencode-server.kubeopencode-system.svc:2746'"/api/v1/namespaces/${TASK_NAMESPACE}/tasks/${TASK_NAME}/outputs"`
+	if !strings.Contains(got[2], url) || !strings.Contains(got[2], serviceAccountTokenPath) {
+		t.Errorf("script does not upload outputs to the server: %s", got[2])
+	}
+	if strings.Index(got[2], "curl") > strings.Index(got[2], ">> /dev/termination-log") {
+		t.Errorf("script should only fall back to the termination log after the upload: %s", got[2])
+	}
+}
+
+func TestGetTaskOutputs(t *testing.T) {
+	terminated := func(message string) *corev1.Pod {
+		return &corev1.Pod{Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+			State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Message: message}},
+		}}}}
+	}
+
+	t.Run("decodes files and skips invalid names", func(t *testing.T) {
+		data := encodeTaskOutputs(t, map[string]string{
+			"./report.md":  "# Report",
+			"./bad name!":  "skipped",
+			"./notes.json": "{}",
+		})
+		pod := terminated("workspace-snapshot: default/x.tar.gz\n" + taskOutputsMessagePrefix + data + "\n")
+		files, err := getTaskOutputs(pod)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := map[string]string{"report.md": "# Report", "notes.json": "{}"}
+		if !reflect.DeepEqual(files, want) {
+			t.Errorf("getTaskOutputs() = %v, want %v", files, want)
+		}
+		if names := taskOutputFileNames(files); !reflect.DeepEqual(names, []string{"notes.json", "report.md"}) {
+			t.Errorf("taskOutputFileNames() = %v", names)
+		}
+	})
+
+	t.Run("no outputs reported", func(t *testing.T) {
+		files, err := getTaskOutputs(terminated("workspace-snapshot: default/x.tar.gz"))
+		if err != nil || files != nil {
+			t.Errorf("getTaskOutputs() = (%v, %v), want (nil, nil)", files, err)
+		}
+	})
+
+	t.Run("invalid payload", func(t *testing.T) {
+		if _, err := getTaskOutputs(terminated(taskOutputsMessagePrefix + "not-base64!")); err == nil {
+			t.Error("expected error for invalid payload")
+		}
+	})
+}
+
+func TestParseTaskResult(t *testing.T) {
+	t.Run("valid result", func(t *testing.T) {
+		result, err := parseTaskResult(`{"status": "Failure", "summary": "2 blocking findings", "fields": {"approved": false, "findings": 2}}`)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Status != kubeopenv1alpha1.TaskResultFailure || result.Summary != "2 blocking findings" {
+			t.Errorf("parseTaskResult() = %+v", result)
+		}
+		if result.Fields == nil || string(result.Fields.Raw) != `{"approved": false, "findings": 2}` {
+			t.Errorf("fields = %v, want the JSON object", result.Fields)
+		}
+	})
+
+	t.Run("status only", func(t *testing.T) {
+		result, err := parseTaskResult(`{"status": "Neutral", "fields": null}`)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Fields != nil {
+			t.Errorf("fields = %s, want nil", result.Fields.Raw)
+		}
+	})
+
+	invalid := map[string]string{
+		"not JSON":        `status: Success`,
+		"missing status":  `{"summary": "done"}`,
+		"unknown status":  `{"status": "success"}`,
+		"unknown key":     `{"status": "Success", "verdict": "ok"}`,
+		"fields array":    `{"status": "Success", "fields": [1, 2]}`,
+		"trailing data":   `{"status": "Success"} {"status": "Failure"}`,
+		"summary too big": `{"status": "Success", "summary": "` + strings.Repeat("x", maxTaskResultSummaryLength+1) + `"}`,
+	}
+	for name, content := range invalid {
+		t.Run(name, func(t *testing.T) {
+			if _, err := parseTaskResult(content); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestCaptureTaskOutputs_Uploaded(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(s)
+	_ = kubeopenv1alpha1.AddToScheme(s)
+	ctx := context.Background()
+
+	task := &kubeopenv1alpha1.Task{
+		ObjectMeta: metav1.ObjectMeta{Name: "review", Namespace: "default", UID: "review-uid"},
+		Spec:       kubeopenv1alpha1.TaskSpec{CaptureOutputs: true},
+	}
+	pod := &corev1.Pod{Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+		Name:  "agent",
+		State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0}},
+	}}}}
+
+	t.Run("uploaded configmap", func(t *testing.T) {
+		cm := BuildTaskOutputsConfigMap(task, map[string]string{"summary.md": "LGTM", TaskResultFile: `{"status": "Success"}`})
+		r := &TaskReconciler{
+			Client:   fake.NewClientBuilder().WithScheme(s).WithObjects(cm).Build(),
+			Recorder: events.NewFakeRecorder(10),
+		}
+		got := task.DeepCopy()
+		r.captureTaskOutputs(ctx, got, pod)
+		if got.Status.Outputs == nil || !reflect.DeepEqual(got.Status.Outputs.Files, []string{TaskResultFile, "summary.md"}) {
+			t.Errorf("outputs = %+v, want the uploaded files", got.Status.Outputs)
+		}
+		if got.Status.Result == nil || got.Status.Result.Status != kubeopenv1alpha1.TaskResultSuccess {
+			t.Errorf("result = %+v, want Success", got.Status.Result)
+		}
+	})
+
+	t.Run("configmap not controlled by the task", func(t *testing.T) {
+		cm := BuildTaskOutputsConfigMap(task, map[string]string{"summary.md": "LGTM"})
+		cm.OwnerReferences = nil
+		r := &TaskReconciler{
+			Client:   fake.NewClientBuilder().WithScheme(s).WithObjects(cm).Build(),
+			Recorder: events.NewFakeRecorder(10),
+		}
+		got := task.DeepCopy()
+		r.captureTaskOutputs(ctx, got, pod)
+		if got.Status.Outputs != nil {
+			t.Errorf("outputs = %+v, want none", got.Status.Outputs)
+		}
+	})
+}
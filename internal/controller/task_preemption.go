@@ -0,0 +1,144 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+)
+
+// listAgentTasks returns the Tasks of an Agent
+func (r *TaskReconciler) listAgentTasks(ctx context.Context, namespace, agentName string) ([]kubeopenv1alpha1.Task, error) {
+	taskList := &kubeopenv1alpha1.TaskList{}
+	if err := r.List(ctx, taskList, client.InNamespace(namespace), client.MatchingLabels{AgentLabelKey: agentName}); err != nil {
+		return nil, err
+	}
+	return taskList.Items, nil
+}
+
+// higherPriorityTaskQueued reports whether another Task of the Agent with a higher
+// priority than task is queued, in which case task must leave the free capacity to it.
+func (r *TaskReconciler) higherPriorityTaskQueued(ctx context.Context, task *kubeopenv1alpha1.Task, agentName string) (bool, error) {
+	tasks, err := r.listAgentTasks(ctx, task.Namespace, agentName)
+	if err != nil {
+		return false, err
+	}
+	for i := range tasks {
+		other := &tasks[i]
+		if other.UID != task.UID &&
+			other.Status.Phase == kubeopenv1alpha1.TaskPhaseQueued &&
+			other.Spec.Priority > task.Spec.Priority &&
+			!isTaskStoppedByUser(other) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// selectPreemptionVictim returns the running Task with the lowest priority below
+// priority, or nil if there is none. Among Tasks with the same priority, the one
+// started last is selected, as it loses the least work.
+func selectPreemptionVictim(tasks []kubeopenv1alpha1.Task, priority int32) *kubeopenv1alpha1.Task {
+	var victim *kubeopenv1alpha1.Task
+	for i := range tasks {
+		candidate := &tasks[i]
+		if candidate.Status.Phase != kubeopenv1alpha1.TaskPhaseRunning || candidate.Spec.Priority >= priority {
+			continue
+		}
+		if victim == nil || candidate.Spec.Priority < victim.Spec.Priority ||
+			(candidate.Spec.Priority == victim.Spec.Priority && startedAfter(candidate, victim)) {
+			victim = candidate
+		}
+	}
+	return victim
+}
+
+// startedAfter reports whether a was started after b. Tasks not started yet count as latest.
+func startedAfter(a, b *kubeopenv1alpha1.Task) bool {
+	if a.Status.StartTime == nil {
+		return b.Status.StartTime != nil
+	}
+	return b.Status.StartTime != nil && a.Status.StartTime.After(b.Status.StartTime.Time)
+}
+
+// preemptLowerPriorityTask stops the running Task of the Agent selected by
+// selectPreemptionVictim and queues it again, freeing a capacity slot for task.
+// It returns whether a Task was preempted.
+func (r *TaskReconciler) preemptLowerPriorityTask(ctx context.Context, task *kubeopenv1alpha1.Task, agentName string) (bool, error) {
+	log := log.FromContext(ctx)
+
+	tasks, err := r.listAgentTasks(ctx, task.Namespace, agentName)
+	if err != nil {
+		return false, err
+	}
+	victim := selectPreemptionVictim(tasks, task.Spec.Priority)
+	if victim == nil {
+		return false, nil
+	}
+
+	log.Info("preempting lower-priority task", "victim", victim.Name, "victimPriority", victim.Spec.Priority,
+		"priority", task.Spec.Priority, "agent", agentName)
+
+	// Queue the victim before deleting its Pod, so that its own reconcile does not
+	// mistake the deleted Pod for a failure
+	podName, jobName := victim.Status.PodName, victim.Status.JobName
+	victim.Status.ObservedGeneration = victim.Generation
+	victim.Status.Phase = kubeopenv1alpha1.TaskPhaseQueued
+	victim.Status.PodName = ""
+	victim.Status.JobName = ""
+	victim.Status.StartTime = nil
+	victim.Status.Session = nil
+	meta.SetStatusCondition(&victim.Status.Conditions, metav1.Condition{
+		Type:    kubeopenv1alpha1.ConditionTypeQueued,
+		Status:  metav1.ConditionTrue,
+		Reason:  kubeopenv1alpha1.ReasonPreempted,
+		Message: fmt.Sprintf("Preempted by Task %q with higher priority %d", task.Name, task.Spec.Priority),
+	})
+	if err := r.Status().Update(ctx, victim); err != nil {
+		if errors.IsConflict(err) || errors.IsNotFound(err) {
+			// The victim changed meanwhile; the caller retries
+			return false, nil
+		}
+		return false, err
+	}
+
+	if jobName != "" {
+		if err := r.deleteTaskJob(ctx, victim.Namespace, jobName); err != nil {
+			return false, fmt.Errorf("failed to delete Job of preempted Task %s: %w", victim.Name, err)
+		}
+	}
+	if podName != "" {
+		pod := &corev1.Pod{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: victim.Namespace, Name: podName}, pod); err == nil {
+			if err := r.Delete(ctx, pod); err != nil && !errors.IsNotFound(err) {
+				return false, fmt.Errorf("failed to delete Pod of preempted Task %s: %w", victim.Name, err)
+			}
+		} else if !errors.IsNotFound(err) {
+			return false, err
+		}
+	}
+
+	// The preempted run does not count against the Agent's quota
+	if agent, err := r.getAgentForQuota(ctx, agentName, task.Namespace); err == nil {
+		if err := r.removeTaskStart(ctx, agent, victim); err != nil {
+			log.Error(err, "unable to remove quota record of preempted task", "victim", victim.Name)
+		}
+	}
+
+	r.Recorder.Eventf(victim, nil, corev1.EventTypeWarning, "Preempted", "Preempt",
+		"Preempted by Task %q with higher priority %d, task queued", task.Name, task.Spec.Priority)
+	r.Recorder.Eventf(task, nil, corev1.EventTypeNormal, "Preempting", "Preempt",
+		"Preempted Task %q with lower priority %d", victim.Name, victim.Spec.Priority)
+	TaskPreemptionsTotal.WithLabelValues(task.Namespace, agentName).Inc()
+	return true, nil
+}
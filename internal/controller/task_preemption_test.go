@@ -0,0 +1,133 @@
+// Copyright Contributors to the KubeOpenCode project
+
+//go:build !integration
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/events"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+)
+
+func priorityTask(name string, phase kubeopenv1alpha1.TaskPhase, priority int32, started time.Duration) kubeopenv1alpha1.Task {
+	task := kubeopenv1alpha1.Task{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			UID:       types.UID(name + "-uid"),
+			Labels:    map[string]string{AgentLabelKey: "agent"},
+		},
+		Spec:   kubeopenv1alpha1.TaskSpec{Priority: priority},
+		Status: kubeopenv1alpha1.TaskExecutionStatus{Phase: phase},
+	}
+	if phase == kubeopenv1alpha1.TaskPhaseRunning {
+		task.Status.StartTime = &metav1.Time{Time: time.Now().Add(-started)}
+		task.Status.PodName = name + "-pod"
+	}
+	return task
+}
+
+func TestSelectPreemptionVictim(t *testing.T) {
+	running := kubeopenv1alpha1.TaskPhaseRunning
+	tasks := []kubeopenv1alpha1.Task{
+		priorityTask("batch-old", running, 0, 2*time.Hour),
+		priorityTask("batch-new", running, 0, time.Hour),
+		priorityTask("normal", running, 5, time.Hour),
+		priorityTask("queued-low", kubeopenv1alpha1.TaskPhaseQueued, -10, 0),
+		priorityTask("done-low", kubeopenv1alpha1.TaskPhaseCompleted, -10, 0),
+	}
+
+	tests := []struct {
+		name     string
+		priority int32
+		want     string
+	}{
+		{name: "lowest priority, latest started", priority: 10, want: "batch-new"},
+		{name: "only lower priorities are preempted", priority: 5, want: "batch-new"},
+		{name: "no lower priority", priority: 0, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			victim := selectPreemptionVictim(tasks, tt.priority)
+			got := ""
+			if victim != nil {
+				got = victim.Name
+			}
+			if got != tt.want {
+				t.Errorf("selectPreemptionVictim(%d) = %q, want %q", tt.priority, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPreemptLowerPriorityTask(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(s)
+	_ = kubeopenv1alpha1.AddToScheme(s)
+	ctx := context.Background()
+
+	victim := priorityTask("batch", kubeopenv1alpha1.TaskPhaseRunning, 0, time.Hour)
+	urgent := priorityTask("urgent", kubeopenv1alpha1.TaskPhaseQueued, 100, 0)
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "batch-pod", Namespace: "default"}}
+	r := &TaskReconciler{
+		Client: fake.NewClientBuilder().WithScheme(s).
+			WithObjects(&victim, &urgent, pod).
+			WithStatusSubresource(&kubeopenv1alpha1.Task{}).
+			Build(),
+		Recorder: events.NewFakeRecorder(10),
+	}
+
+	preempted, err := r.preemptLowerPriorityTask(ctx, &urgent, "agent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !preempted {
+		t.Fatal("expected a task to be preempted")
+	}
+
+	got := &kubeopenv1alpha1.Task{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: "default", Name: "batch"}, got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Status.Phase != kubeopenv1alpha1.TaskPhaseQueued || got.Status.PodName != "" || got.Status.StartTime != nil {
+		t.Errorf("preempted task status = %+v, want queued without pod and start time", got.Status)
+	}
+	if cond := meta.FindStatusCondition(got.Status.Conditions, kubeopenv1alpha1.ConditionTypeQueued); cond == nil || cond.Reason != kubeopenv1alpha1.ReasonPreempted {
+		t.Errorf("Queued condition = %+v, want reason %s", cond, kubeopenv1alpha1.ReasonPreempted)
+	}
+	err = r.Get(ctx, types.NamespacedName{Namespace: "default", Name: "batch-pod"}, &corev1.Pod{})
+	if !errors.IsNotFound(err) {
+		t.Errorf("pod of preempted task should be deleted, got err %v", err)
+	}
+
+	// The preempted task must not take the freed capacity back from the urgent one
+	blocked, err := r.higherPriorityTaskQueued(ctx, got, "agent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !blocked {
+		t.Error("preempted task should wait for the queued higher-priority task")
+	}
+
+	// Nothing left to preempt
+	preempted, err = r.preemptLowerPriorityTask(ctx, &urgent, "agent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if preempted {
+		t.Error("no running task is left to preempt")
+	}
+}
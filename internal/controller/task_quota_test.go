@@ -0,0 +1,80 @@
+// Copyright Contributors to the KubeOpenCode project
+
+//go:build !integration
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+)
+
+func TestRecordTaskStartChecksPersistedWindow(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = kubeopenv1alpha1.AddToScheme(s)
+	ctx := context.Background()
+
+	agent := &kubeopenv1alpha1.Agent{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "default"},
+		Spec: kubeopenv1alpha1.AgentSpec{
+			Quota: &kubeopenv1alpha1.QuotaConfig{MaxTaskStarts: 2, WindowSeconds: 3600},
+		},
+		Status: kubeopenv1alpha1.AgentStatus{
+			TaskStartHistory: []kubeopenv1alpha1.TaskStartRecord{
+				{TaskName: "expired", TaskNamespace: "default", StartTime: metav1.NewTime(time.Now().Add(-2 * time.Hour))},
+				{TaskName: "earlier", TaskNamespace: "default", StartTime: metav1.NewTime(time.Now().Add(-time.Minute))},
+			},
+		},
+	}
+	r := &TaskReconciler{Client: fake.NewClientBuilder().WithScheme(s).
+		WithObjects(agent).
+		WithStatusSubresource(&kubeopenv1alpha1.Agent{}).
+		Build()}
+	task := func(name string) *kubeopenv1alpha1.Task {
+		return &kubeopenv1alpha1.Task{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+	}
+
+	// A stale copy of the Agent, e.g. read before a controller restart, must not
+	// let more Tasks start than the persisted history allows
+	stale := agent.DeepCopy()
+	stale.Status.TaskStartHistory = nil
+
+	recorded, _, err := r.recordTaskStart(ctx, stale, task("first"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !recorded {
+		t.Fatal("expected the start to be recorded while the window has a free slot")
+	}
+
+	recorded, requeueAfter, err := r.recordTaskStart(ctx, stale, task("second"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recorded {
+		t.Error("start recorded although the persisted window is full")
+	}
+	if requeueAfter < 50*time.Minute {
+		t.Errorf("requeueAfter = %v, want about one hour until the oldest start expires", requeueAfter)
+	}
+
+	got := &kubeopenv1alpha1.Agent{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: "default", Name: "agent"}, got); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, record := range got.Status.TaskStartHistory {
+		names = append(names, record.TaskName)
+	}
+	if len(names) != 2 || names[0] != "earlier" || names[1] != "first" {
+		t.Errorf("TaskStartHistory = %v, want [earlier first]", names)
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	corev1 "k8s.io/api/core/v1"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+	"github.com/kubeopencode/kubeopencode/internal/tracing"
+)
+
+// taskSpanAttributes returns the attributes identifying task on its spans
+func taskSpanAttributes(task *kubeopenv1alpha1.Task) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		attribute.String("kubeopencode.task.namespace", task.Namespace),
+		attribute.String("kubeopencode.task.name", task.Name),
+	}
+	switch {
+	case task.Spec.AgentRef != nil:
+		attrs = append(attrs, attribute.String("kubeopencode.agent.name", task.Spec.AgentRef.Name))
+	case task.Spec.TemplateRef != nil:
+		attrs = append(attrs, attribute.String("kubeopencode.agenttemplate.name", task.Spec.TemplateRef.Name))
+	}
+	return attrs
+}
+
+// startReconcileSpan starts the span of one Task reconcile in the trace recorded on
+// the Task, if any.
+func startReconcileSpan(ctx context.Context, task *kubeopenv1alpha1.Task) (context.Context, trace.Span) {
+	ctx = tracing.ContextFromAnnotation(ctx, task)
+	return tracing.Tracer().Start(ctx, "Reconcile Task",
+		trace.WithAttributes(taskSpanAttributes(task)...),
+		trace.WithAttributes(attribute.String("kubeopencode.task.phase", string(task.Status.Phase))),
+	)
+}
+
+// recordTaskLifecycleSpans records the phases of a finished Task as spans with the
+// timestamps from its status and Pod:
+//   - "Task queued": creation until the controller started the Task
+//   - "Task pod startup": start until the Pod's containers were running, which covers
+//     scheduling, image pulls and init containers (context and git initialization)
+//   - "Task run": containers running until completion
+func recordTaskLifecycleSpans(ctx context.Context, task *kubeopenv1alpha1.Task, pod *corev1.Pod) {
+	if task.Status.StartTime == nil || task.Status.CompletionTime == nil {
+		return
+	}
+	ctx = tracing.ContextFromAnnotation(ctx, task)
+	created := task.CreationTimestamp.Time
+	started := task.Status.StartTime.Time
+	completed := task.Status.CompletionTime.Time
+	attrs := append(taskSpanAttributes(task), attribute.String("kubeopencode.task.phase", string(task.Status.Phase)))
+
+	ctx, span := tracing.Tracer().Start(ctx, "Task", trace.WithTimestamp(created), trace.WithAttributes(attrs...))
+	defer span.End(trace.WithTimestamp(completed))
+
+	recordSpan(ctx, "Task queued", created, started)
+	running := podContainersStarted(pod)
+	if running.IsZero() || running.Before(started) || running.After(completed) {
+		recordSpan(ctx, "Task run", started, completed)
+		return
+	}
+	recordSpan(ctx, "Task pod startup", started, running)
+	recordSpan(ctx, "Task run", running, completed)
+}
+
+// recordSpan records a finished span from start to end
+func recordSpan(ctx context.Context, name string, start, end time.Time) {
+	_, span := tracing.Tracer().Start(ctx, name, trace.WithTimestamp(start))
+	span.End(trace.WithTimestamp(end))
+}
+
+// podContainersStarted returns when the last of the Pod's (non-init) containers
+// started, or the zero time if unknown.
+func podContainersStarted(pod *corev1.Pod) time.Time {
+	var started time.Time
+	if pod == nil {
+		return started
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		var t time.Time
+		switch {
+		case status.State.Running != nil:
+			t = status.State.Running.StartedAt.Time
+		case status.State.Terminated != nil:
+			t = status.State.Terminated.StartedAt.Time
+		}
+		if t.After(started) {
+			started = t
+		}
+	}
+	return started
+}
@@ -5,11 +5,16 @@
 package controller
 
 import (
+	"context"
+	"strings"
 	"testing"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
 )
@@ -341,3 +346,404 @@ func TestGetPodFailureDetail(t *testing.T) {
 		}
 	})
 }
+
+func TestGetWorkspaceSnapshotStatus(t *testing.T) {
+	snapshotVolume := corev1.Volume{
+		Name: WorkspaceSnapshotVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "debug-snapshots"},
+		},
+	}
+	failedWithMessage := func(msg string) []corev1.ContainerStatus {
+		return []corev1.ContainerStatus{{
+			Name: "agent",
+			State: corev1.ContainerState{
+				Terminated: &corev1.ContainerStateTerminated{ExitCode: 1, Message: msg},
+			},
+		}}
+	}
+
+	t.Run("returns snapshot location", func(t *testing.T) {
+		pod := &corev1.Pod{
+			Spec:   corev1.PodSpec{Volumes: []corev1.Volume{snapshotVolume}},
+			Status: corev1.PodStatus{ContainerStatuses: failedWithMessage("workspace-snapshot: default/my-task-20260101000000.tar.gz\n")},
+		}
+		got := getWorkspaceSnapshotStatus(pod)
+		if got == nil {
+			t.Fatal("expected snapshot status, got nil")
+		}
+		if got.ClaimName != "debug-snapshots" || got.Path != "default/my-task-20260101000000.tar.gz" {
+			t.Errorf("got %+v", got)
+		}
+	})
+
+	t.Run("nil without snapshot volume", func(t *testing.T) {
+		pod := &corev1.Pod{
+			Status: corev1.PodStatus{ContainerStatuses: failedWithMessage("workspace-snapshot: default/x.tar.gz")},
+		}
+		if got := getWorkspaceSnapshotStatus(pod); got != nil {
+			t.Errorf("expected nil, got %+v", got)
+		}
+	})
+
+	t.Run("nil when no snapshot was reported", func(t *testing.T) {
+		pod := &corev1.Pod{
+			Spec:   corev1.PodSpec{Volumes: []corev1.Volume{snapshotVolume}},
+			Status: corev1.PodStatus{ContainerStatuses: failedWithMessage("out of memory")},
+		}
+		if got := getWorkspaceSnapshotStatus(pod); got != nil {
+			t.Errorf("expected nil, got %+v", got)
+		}
+	})
+}
+
+func TestValidateTaskContexts(t *testing.T) {
+	text := func(name, mountPath, content string) kubeopenv1alpha1.ContextItem {
+		return kubeopenv1alpha1.ContextItem{Name: name, Type: kubeopenv1alpha1.ContextTypeText, Text: content, MountPath: mountPath}
+	}
+	large := strings.Repeat("x", MaxContextConfigMapSize/2+1)
+
+	tests := []struct {
+		name        string
+		description string
+		agentRef    bool
+		workspace   string
+		contexts    []kubeopenv1alpha1.ContextItem
+		wantErr     string
+	}{
+		{
+			name:     "valid contexts",
+			contexts: []kubeopenv1alpha1.ContextItem{text("a", "docs/a.md", "a"), text("b", "/etc/b.md", "b"), text("c", "", "c"), text("d", "", "d")},
+		},
+		{
+			name:     "workspace root is allowed",
+			contexts: []kubeopenv1alpha1.ContextItem{{Name: "repo", Type: kubeopenv1alpha1.ContextTypeGit, MountPath: "."}},
+		},
+		{
+			name:     "relative path escaping workspace",
+			contexts: []kubeopenv1alpha1.ContextItem{text("a", "docs/../../etc/passwd", "a")},
+			wantErr:  "escapes the workspace directory",
+		},
+		{
+			name:     "duplicate mount paths after cleaning",
+			contexts: []kubeopenv1alpha1.ContextItem{text("a", "docs/a.md", "a"), text("b", "docs//a.md", "b")},
+			wantErr:  "mount path conflict",
+		},
+		{
+			name:      "relative and absolute path to the same workspace file",
+			workspace: "/workspace",
+			contexts:  []kubeopenv1alpha1.ContextItem{text("a", "src/a.md", "a"), text("b", "/workspace/src/a.md", "b")},
+			wantErr:   `mount path conflict: "/workspace/src/a.md"`,
+		},
+		{
+			name:     "relative and absolute paths with unknown workspace",
+			contexts: []kubeopenv1alpha1.ContextItem{text("a", "src/a.md", "a"), text("b", "/workspace/src/a.md", "b")},
+		},
+		{
+			name:     "duplicate context names",
+			contexts: []kubeopenv1alpha1.ContextItem{text("a", "", "a"), text("a", "", "b")},
+			wantErr:  `context name conflict: "a" is used by both context[0] and context[1]`,
+		},
+		{
+			name: "name colliding with an unnamed Git context",
+			contexts: []kubeopenv1alpha1.ContextItem{
+				{Type: kubeopenv1alpha1.ContextTypeGit, MountPath: "repo"},
+				text("git-0", "", "a"),
+			},
+			wantErr: `context name conflict: "git-0"`,
+		},
+		{
+			name:        "inline content exceeds ConfigMap limit",
+			description: large,
+			contexts:    []kubeopenv1alpha1.ContextItem{text("a", "", large)},
+			wantErr:     "exceeding the ConfigMap limit",
+		},
+		{
+			name:     "URL context on templateRef Task",
+			contexts: []kubeopenv1alpha1.ContextItem{{Name: "spec", Type: kubeopenv1alpha1.ContextTypeURL}},
+		},
+		{
+			name:     "URL context on agentRef Task",
+			agentRef: true,
+			contexts: []kubeopenv1alpha1.ContextItem{{Name: "spec", Type: kubeopenv1alpha1.ContextTypeURL}},
+			wantErr:  "only supported for templateRef Tasks",
+		},
+		{
+			name:     "Vault context on agentRef Task",
+			agentRef: true,
+			contexts: []kubeopenv1alpha1.ContextItem{text("a", "", "a"), {Type: kubeopenv1alpha1.ContextTypeVault}},
+			wantErr:  "context[1]: Vault contexts are only supported for templateRef Tasks",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &kubeopenv1alpha1.Task{Spec: kubeopenv1alpha1.TaskSpec{Contexts: tt.contexts}}
+			if tt.agentRef {
+				task.Spec.AgentRef = &kubeopenv1alpha1.AgentReference{Name: "agent"}
+			}
+			if tt.description != "" {
+				task.Spec.Description = &tt.description
+			}
+			err := ValidateTaskContexts(task, tt.workspace)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateContextConfigMapSize(t *testing.T) {
+	if err := validateContextConfigMapSize(map[string]string{"task.md": "hello"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := validateContextConfigMapSize(map[string]string{"big": strings.Repeat("x", MaxContextConfigMapSize)}); err == nil {
+		t.Error("expected error for oversized data")
+	}
+}
+
+func TestValidateMountPathConflicts(t *testing.T) {
+	tests := []struct {
+		name       string
+		fileMounts []fileMount
+		dirMounts  []dirMount
+		gitMounts  []gitMount
+		urlFetches []urlFetchSpec
+		wantErr    bool
+	}{
+		{
+			name: "distinct git mounts",
+			gitMounts: []gitMount{
+				{contextName: "git-0", mountPath: "/workspace/frontend"},
+				{contextName: "git-1", mountPath: "/workspace/backend"},
+			},
+		},
+		{
+			name: "git mounts on the same path",
+			gitMounts: []gitMount{
+				{contextName: "git-0", mountPath: "/workspace/src"},
+				{contextName: "git-1", mountPath: "/workspace/src"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "trailing slash is normalized",
+			gitMounts: []gitMount{
+				{contextName: "git-0", mountPath: "/workspace/src"},
+				{contextName: "git-1", mountPath: "/workspace/src/"},
+			},
+			wantErr: true,
+		},
+		{
+			name:       "file and git mount on the same path",
+			fileMounts: []fileMount{{filePath: "/workspace/./docs"}},
+			gitMounts:  []gitMount{{contextName: "docs", mountPath: "/workspace/docs"}},
+			wantErr:    true,
+		},
+		{
+			name:      "directory and git mount on different paths",
+			dirMounts: []dirMount{{dirPath: "/workspace/configs", configMapName: "cm"}},
+			gitMounts: []gitMount{{contextName: "git-0", mountPath: "/workspace/repo"}},
+		},
+		{
+			name:       "url fetch and git mount on the same path",
+			gitMounts:  []gitMount{{contextName: "git-0", mountPath: "/workspace/release"}},
+			urlFetches: []urlFetchSpec{{name: "release", targetPath: "/workspace/release"}},
+			wantErr:    true,
+		},
+		{
+			name: "url fetches with the same target",
+			urlFetches: []urlFetchSpec{
+				{name: "spec", targetPath: "/workspace/.kubeopencode/urls/spec"},
+				{name: "spec", targetPath: "/workspace/.kubeopencode/urls/spec"},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateMountPathConflicts(tt.fileMounts, tt.dirMounts, tt.gitMounts, tt.urlFetches)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateMountPathConflicts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLockAdmission(t *testing.T) {
+	r := &TaskReconciler{}
+	newTask := func(namespace, agent string) *kubeopenv1alpha1.Task {
+		return &kubeopenv1alpha1.Task{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace},
+			Spec:       kubeopenv1alpha1.TaskSpec{AgentRef: &kubeopenv1alpha1.AgentReference{Name: agent}},
+		}
+	}
+
+	unlock := r.lockAdmission(newTask("ns", "agent-a"))
+
+	// Tasks of other Agents are admitted in parallel
+	done := make(chan struct{})
+	go func() {
+		r.lockAdmission(newTask("ns", "agent-b"))()
+		r.lockAdmission(newTask("other", "agent-a"))()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("admission of other Agents was blocked")
+	}
+
+	// Tasks of the same Agent wait for the lock
+	acquired := make(chan struct{})
+	go func() {
+		r.lockAdmission(newTask("ns", "agent-a"))()
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("admission of the same Agent was not serialized")
+	case <-time.After(50 * time.Millisecond):
+	}
+	unlock()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("admission was not released")
+	}
+}
+
+func TestRetriedTaskReason(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(s)
+	_ = kubeopenv1alpha1.AddToScheme(s)
+
+	task := func(name string, phase kubeopenv1alpha1.TaskPhase, conditions ...metav1.Condition) *kubeopenv1alpha1.Task {
+		return &kubeopenv1alpha1.Task{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Status:     kubeopenv1alpha1.TaskExecutionStatus{Phase: phase, Conditions: conditions},
+		}
+	}
+	r := &TaskReconciler{Client: fake.NewClientBuilder().WithScheme(s).WithObjects(
+		task("timed-out", kubeopenv1alpha1.TaskPhaseFailed, metav1.Condition{
+			Type: kubeopenv1alpha1.ConditionTypeReady, Status: metav1.ConditionFalse, Reason: kubeopenv1alpha1.ReasonTimeout,
+		}),
+		task("agent-failed", kubeopenv1alpha1.TaskPhaseFailed, metav1.Condition{
+			Type: kubeopenv1alpha1.ConditionTypeReady, Status: metav1.ConditionTrue, Reason: kubeopenv1alpha1.ReasonCapacityAvailable,
+		}),
+		task("completed", kubeopenv1alpha1.TaskPhaseCompleted),
+	).Build()}
+
+	tests := map[string]string{
+		"timed-out":    kubeopenv1alpha1.ReasonTimeout,
+		"agent-failed": kubeopenv1alpha1.ReasonAgentError,
+		"completed":    string(kubeopenv1alpha1.TaskPhaseCompleted),
+		"deleted":      "Unknown",
+	}
+	for name, want := range tests {
+		if got := r.retriedTaskReason(context.Background(), "default", name); got != want {
+			t.Errorf("retriedTaskReason(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestProcessAllContextsTaskMessages(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(s)
+	_ = kubeopenv1alpha1.AddToScheme(s)
+	r := &TaskReconciler{Client: fake.NewClientBuilder().WithScheme(s).Build()}
+
+	tests := []struct {
+		name string
+		spec kubeopenv1alpha1.TaskSpec
+		want bool
+	}{
+		{
+			name: "templateRef task is told about the messages directory",
+			spec: kubeopenv1alpha1.TaskSpec{TemplateRef: &kubeopenv1alpha1.AgentTemplateReference{Name: "tmpl"}},
+			want: true,
+		},
+		{
+			name: "agentRef task receives messages in its session",
+			spec: kubeopenv1alpha1.TaskSpec{AgentRef: &kubeopenv1alpha1.AgentReference{Name: "agent"}},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &kubeopenv1alpha1.Task{
+				ObjectMeta: metav1.ObjectMeta{Name: "task", Namespace: "default"},
+				Spec:       tt.spec,
+			}
+			configMap, _, _, _, err := r.processAllContexts(context.Background(), task, agentConfig{workspaceDir: "/workspace"})
+			if err != nil {
+				t.Fatalf("processAllContexts() error = %v", err)
+			}
+			var contextFile string
+			if configMap != nil {
+				contextFile = configMap.Data[sanitizeConfigMapKey("/workspace/"+ContextFileRelPath)]
+			}
+			if got := strings.Contains(contextFile, "/workspace/"+TaskMessagesDir); got != tt.want {
+				t.Errorf("context file mentions messages directory = %v, want %v; context file:\n%s", got, tt.want, contextFile)
+			}
+		})
+	}
+}
+
+func TestProcessAllContextsResourceSnapshots(t *testing.T) {
+	s := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(s)
+	_ = kubeopenv1alpha1.AddToScheme(s)
+	r := &TaskReconciler{Client: fake.NewClientBuilder().WithScheme(s).Build()}
+
+	runtimeContext := kubeopenv1alpha1.ContextItem{
+		Name: "cluster",
+		Type: kubeopenv1alpha1.ContextTypeRuntime,
+		Runtime: &kubeopenv1alpha1.RuntimeContext{
+			Resources: []kubeopenv1alpha1.ResourceSnapshot{{Name: "web", APIVersion: "apps/v1", Kind: "Deployment"}},
+		},
+	}
+	tests := []struct {
+		name string
+		spec kubeopenv1alpha1.TaskSpec
+		want bool
+	}{
+		{
+			name: "templateRef task lists snapshot files",
+			spec: kubeopenv1alpha1.TaskSpec{TemplateRef: &kubeopenv1alpha1.AgentTemplateReference{Name: "tmpl"}},
+			want: true,
+		},
+		{
+			name: "agentRef task takes no snapshots",
+			spec: kubeopenv1alpha1.TaskSpec{AgentRef: &kubeopenv1alpha1.AgentReference{Name: "agent"}},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &kubeopenv1alpha1.Task{
+				ObjectMeta: metav1.ObjectMeta{Name: "task", Namespace: "default"},
+				Spec:       tt.spec,
+			}
+			task.Spec.Contexts = []kubeopenv1alpha1.ContextItem{runtimeContext}
+			configMap, _, _, _, err := r.processAllContexts(context.Background(), task, agentConfig{workspaceDir: "/workspace"})
+			if err != nil {
+				t.Fatalf("processAllContexts() error = %v", err)
+			}
+			contextFile := configMap.Data[sanitizeConfigMapKey("/workspace/"+ContextFileRelPath)]
+			if !strings.Contains(contextFile, RuntimeSystemPrompt) {
+				t.Errorf("context file is missing the Runtime prompt:\n%s", contextFile)
+			}
+			if got := strings.Contains(contextFile, "web.yaml"); got != tt.want {
+				t.Errorf("context file lists snapshot files = %v, want %v; context file:\n%s", got, tt.want, contextFile)
+			}
+		})
+	}
+}
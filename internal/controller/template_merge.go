@@ -5,6 +5,7 @@ package controller
 import (
 	"context"
 	"fmt"
+	"slices"
 
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -64,6 +65,8 @@ func MergeAgentWithTemplate(agent *kubeopenv1alpha1.Agent, tmpl *kubeopenv1alpha
 
 		maxConcurrentTasks: firstNonNilPtr(agent.Spec.MaxConcurrentTasks, tmpl.Spec.MaxConcurrentTasks),
 		quota:              firstNonNilPtr(agent.Spec.Quota, tmpl.Spec.Quota),
+		execution:          firstNonNilPtr(agent.Spec.Execution, tmpl.Spec.Execution),
+		networkPolicy:      agent.Spec.NetworkPolicy,
 
 		command:          firstNonEmptyStringSlice(agent.Spec.Command, tmpl.Spec.Command),
 		contexts:         firstNonNilSlice(agent.Spec.Contexts, tmpl.Spec.Contexts),
@@ -91,6 +94,56 @@ func MergeAgentWithTemplate(agent *kubeopenv1alpha1.Agent, tmpl *kubeopenv1alpha
 	return merged
 }
 
+// EffectiveAgentSpec returns the Agent spec that results from merging the Agent with
+// the AgentTemplate, using the same rules as MergeAgentWithTemplate. Fields that are
+// not inherited from templates are kept from the Agent. It is used to preview merges.
+func EffectiveAgentSpec(agent *kubeopenv1alpha1.Agent, tmpl *kubeopenv1alpha1.AgentTemplate) kubeopenv1alpha1.AgentSpec {
+	cfg := MergeAgentWithTemplate(agent, tmpl)
+	spec := *agent.Spec.DeepCopy()
+	spec.AgentImage = cfg.agentImage
+	spec.ExecutorImage = cfg.executorImage
+	spec.AttachImage = cfg.attachImage
+	spec.WorkspaceDir = cfg.workspaceDir
+	spec.ServiceAccountName = cfg.serviceAccountName
+	spec.MaxConcurrentTasks = cfg.maxConcurrentTasks
+	spec.Quota = cfg.quota
+	spec.Execution = cfg.execution
+	spec.Command = cfg.command
+	spec.Contexts = cfg.contexts
+	spec.Skills = cfg.skills
+	spec.Plugins = cfg.plugins
+	spec.Config = cfg.config
+	spec.Credentials = cfg.credentials
+	spec.PodSpec = cfg.podSpec
+	spec.CABundle = cfg.caBundle
+	spec.Proxy = cfg.proxy
+	spec.ImagePullSecrets = cfg.imagePullSecrets
+	spec.ExtraPorts = cfg.extraPorts
+	return *spec.DeepCopy()
+}
+
+// EffectiveTaskSpec returns the spec of task with its description and Text contexts
+// rendered with its params, and the spec of the Agent it runs with (see
+// EffectiveAgentSpec) with its Text contexts rendered, using the same rules as the
+// controller when the Task starts. For a templateRef Task, agent is an empty Agent.
+// It is used to preview Tasks before they are created.
+func EffectiveTaskSpec(task *kubeopenv1alpha1.Task, agent *kubeopenv1alpha1.Agent, tmpl *kubeopenv1alpha1.AgentTemplate) (kubeopenv1alpha1.TaskSpec, kubeopenv1alpha1.AgentSpec, error) {
+	agentSpec := EffectiveAgentSpec(agent, tmpl)
+	// Agent contexts come before Task contexts, as in the Task's context files
+	description, contexts, err := RenderTaskText(task, slices.Concat(agentSpec.Contexts, task.Spec.Contexts))
+	if err != nil {
+		return kubeopenv1alpha1.TaskSpec{}, kubeopenv1alpha1.AgentSpec{}, err
+	}
+
+	taskSpec := *task.Spec.DeepCopy()
+	taskSpec.Description = &description
+	agentSpec.Contexts = contexts[:len(agentSpec.Contexts)]
+	if len(task.Spec.Contexts) > 0 {
+		taskSpec.Contexts = contexts[len(agentSpec.Contexts):]
+	}
+	return taskSpec, agentSpec, nil
+}
+
 // Merge helpers: return agent value if non-nil/non-empty, else template value.
 
 func firstNonEmptyStringSlice(a, b []string) []string {
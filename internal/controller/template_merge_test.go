@@ -858,3 +858,98 @@ func TestMergeAgentWithTemplate_SystemContainers_AgentWins(t *testing.T) {
 		t.Error("expected FROM_AGENT_SC in merged systemContainers.GitInit.ExtraEnv")
 	}
 }
+
+func TestEffectiveAgentSpec(t *testing.T) {
+	maxTasks := int32(3)
+	agent := &kubeopenv1alpha1.Agent{
+		Spec: kubeopenv1alpha1.AgentSpec{
+			TemplateRef:  &kubeopenv1alpha1.AgentTemplateReference{Name: "base"},
+			Profile:      "Reviews pull requests",
+			WorkspaceDir: "/agent-workspace",
+			Port:         8080,
+		},
+	}
+	tmpl := &kubeopenv1alpha1.AgentTemplate{
+		Spec: kubeopenv1alpha1.AgentTemplateSpec{
+			AgentImage:         "custom-agent:v1",
+			WorkspaceDir:       "/tmpl-workspace",
+			ServiceAccountName: "tmpl-sa",
+			MaxConcurrentTasks: &maxTasks,
+			Contexts: []kubeopenv1alpha1.ContextItem{
+				{Type: kubeopenv1alpha1.ContextTypeText, Text: "from template"},
+			},
+		},
+	}
+
+	spec := EffectiveAgentSpec(agent, tmpl)
+
+	if spec.WorkspaceDir != "/agent-workspace" {
+		t.Errorf("expected Agent workspaceDir to win, got %q", spec.WorkspaceDir)
+	}
+	if spec.ServiceAccountName != "tmpl-sa" || spec.AgentImage != "custom-agent:v1" {
+		t.Errorf("expected template values to be inherited, got serviceAccountName=%q agentImage=%q", spec.ServiceAccountName, spec.AgentImage)
+	}
+	if spec.ExecutorImage != DefaultExecutorImage {
+		t.Errorf("expected default executorImage, got %q", spec.ExecutorImage)
+	}
+	if spec.MaxConcurrentTasks == nil || *spec.MaxConcurrentTasks != 3 || len(spec.Contexts) != 1 {
+		t.Errorf("expected maxConcurrentTasks and contexts from template, got %v %v", spec.MaxConcurrentTasks, spec.Contexts)
+	}
+	if spec.Profile != "Reviews pull requests" || spec.Port != 8080 || spec.TemplateRef == nil {
+		t.Errorf("expected Agent-only fields to be kept, got %+v", spec)
+	}
+
+	// The preview must not alias the template
+	*spec.MaxConcurrentTasks = 10
+	if *tmpl.Spec.MaxConcurrentTasks != 3 {
+		t.Error("expected effective spec to be a copy")
+	}
+}
+
+func TestEffectiveTaskSpec(t *testing.T) {
+	description := "Review PR ${params.pr}"
+	task := &kubeopenv1alpha1.Task{
+		ObjectMeta: metav1.ObjectMeta{Name: "review", Namespace: "default"},
+		Spec: kubeopenv1alpha1.TaskSpec{
+			TemplateRef: &kubeopenv1alpha1.AgentTemplateReference{Name: "base"},
+			Description: &description,
+			Params:      map[string]string{"pr": "123", "repo": "org/app"},
+			Contexts: []kubeopenv1alpha1.ContextItem{
+				{Type: kubeopenv1alpha1.ContextTypeText, Text: "Task {{ .task.name }}"},
+			},
+		},
+	}
+	tmpl := &kubeopenv1alpha1.AgentTemplate{
+		Spec: kubeopenv1alpha1.AgentTemplateSpec{
+			WorkspaceDir: "/workspace",
+			Contexts: []kubeopenv1alpha1.ContextItem{
+				{Type: kubeopenv1alpha1.ContextTypeText, Text: "Repository ${params.repo}"},
+			},
+		},
+	}
+
+	taskSpec, agentSpec, err := EffectiveTaskSpec(task, &kubeopenv1alpha1.Agent{}, tmpl)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *taskSpec.Description != "Review PR 123" {
+		t.Errorf("expected rendered description, got %q", *taskSpec.Description)
+	}
+	if len(taskSpec.Contexts) != 1 || taskSpec.Contexts[0].Text != "Task review" {
+		t.Errorf("expected rendered Task context, got %v", taskSpec.Contexts)
+	}
+	if len(agentSpec.Contexts) != 1 || agentSpec.Contexts[0].Text != "Repository org/app" {
+		t.Errorf("expected rendered template context, got %v", agentSpec.Contexts)
+	}
+	if agentSpec.WorkspaceDir != "/workspace" {
+		t.Errorf("expected workspaceDir from template, got %q", agentSpec.WorkspaceDir)
+	}
+	if *task.Spec.Description != description || tmpl.Spec.Contexts[0].Text != "Repository ${params.repo}" {
+		t.Error("expected inputs to be left unchanged")
+	}
+
+	delete(task.Spec.Params, "pr")
+	if _, _, err := EffectiveTaskSpec(task, &kubeopenv1alpha1.Agent{}, tmpl); err == nil {
+		t.Error("expected error for undefined param")
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package controller
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+)
+
+// textVariablePattern matches variable references in Text contexts and Task descriptions:
+//
+//	{{ .task.name }}  {{ .params.service }}  ${task.namespace}  ${params.service}
+//
+// Only the "task" and "params" roots are recognized, so unrelated template or shell
+// syntax (e.g. Helm's {{ .Values.x }} or ${HOME}) is left untouched.
+var textVariablePattern = regexp.MustCompile(`\{\{\s*\.((?:task|params)\.[A-Za-z0-9_./-]+)\s*\}\}|\$\{((?:task|params)\.[A-Za-z0-9_./-]+)\}`)
+
+// taskTextVariables returns the variables available for rendering, keyed by
+// their reference path (e.g. "task.name", "params.service").
+func taskTextVariables(task *kubeopenv1alpha1.Task) map[string]string {
+	vars := map[string]string{
+		"task.name":      task.Name,
+		"task.namespace": task.Namespace,
+	}
+	for k, v := range task.Labels {
+		vars["task.labels."+k] = v
+	}
+	for k, v := range task.Annotations {
+		vars["task.annotations."+k] = v
+	}
+	for k, v := range task.Spec.Params {
+		vars["params."+k] = v
+	}
+	return vars
+}
+
+// renderTextVariables substitutes variable references in text.
+// Returns an error listing every referenced variable that is not defined,
+// so a missing param fails the Task instead of reaching the agent verbatim.
+func renderTextVariables(text string, vars map[string]string) (string, error) {
+	missing := make(map[string]struct{})
+	rendered := textVariablePattern.ReplaceAllStringFunc(text, func(match string) string {
+		sub := textVariablePattern.FindStringSubmatch(match)
+		key := sub[1]
+		if key == "" {
+			key = sub[2]
+		}
+		if v, ok := vars[key]; ok {
+			return v
+		}
+		missing[key] = struct{}{}
+		return match
+	})
+	if len(missing) > 0 {
+		keys := make([]string, 0, len(missing))
+		for k := range missing {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		return "", fmt.Errorf("undefined variables: %s", strings.Join(keys, ", "))
+	}
+	return rendered, nil
+}
+
+// RenderTaskText renders the variables in the description of task and in the
+// Text items of contexts, as the controller does when the Task starts.
+// It returns rendered copies and is used to preview Tasks before they are created.
+func RenderTaskText(task *kubeopenv1alpha1.Task, contexts []kubeopenv1alpha1.ContextItem) (string, []kubeopenv1alpha1.ContextItem, error) {
+	vars := taskTextVariables(task)
+
+	var description string
+	if task.Spec.Description != nil {
+		description = *task.Spec.Description
+	}
+	description, err := renderTextVariables(description, vars)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to render description: %w", err)
+	}
+
+	rendered := make([]kubeopenv1alpha1.ContextItem, len(contexts))
+	for i := range contexts {
+		contexts[i].DeepCopyInto(&rendered[i])
+		if rendered[i].Type != kubeopenv1alpha1.ContextTypeText {
+			continue
+		}
+		if rendered[i].Text, err = renderTextVariables(rendered[i].Text, vars); err != nil {
+			return "", nil, fmt.Errorf("failed to render Text context %d (%q): %w", i, rendered[i].Name, err)
+		}
+	}
+	return description, rendered, nil
+}
@@ -0,0 +1,108 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+)
+
+func TestRenderTextVariables(t *testing.T) {
+	task := &kubeopenv1alpha1.Task{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "fix-checkout",
+			Namespace: "team-a",
+			Labels:    map[string]string{"app.kubernetes.io/name": "checkout"},
+		},
+		Spec: kubeopenv1alpha1.TaskSpec{
+			Params: map[string]string{"service": "checkout", "env": "staging"},
+		},
+	}
+	vars := taskTextVariables(task)
+
+	tests := []struct {
+		name    string
+		text    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "template style",
+			text: "Task {{ .task.name }} in {{.task.namespace}} fixes {{ .params.service }}",
+			want: "Task fix-checkout in team-a fixes checkout",
+		},
+		{
+			name: "env style",
+			text: "Deploy ${params.service} to ${params.env}",
+			want: "Deploy checkout to staging",
+		},
+		{
+			name: "labels",
+			text: "App: {{ .task.labels.app.kubernetes.io/name }}",
+			want: "App: checkout",
+		},
+		{
+			name: "unrelated syntax is untouched",
+			text: "image: {{ .Values.image }} home: ${HOME} {{ range .items }}",
+			want: "image: {{ .Values.image }} home: ${HOME} {{ range .items }}",
+		},
+		{
+			name:    "undefined param",
+			text:    "Region {{ .params.region }} and ${params.zone}",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := renderTextVariables(tt.text, vars)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("renderTextVariables() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("renderTextVariables() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderTaskText(t *testing.T) {
+	description := "Review ${params.repo}#{{ .params.pr }}"
+	task := &kubeopenv1alpha1.Task{
+		ObjectMeta: metav1.ObjectMeta{Name: "review", Namespace: "ci"},
+		Spec: kubeopenv1alpha1.TaskSpec{
+			Description: &description,
+			Params:      map[string]string{"repo": "org/x", "pr": "123"},
+		},
+	}
+	contexts := []kubeopenv1alpha1.ContextItem{
+		{Name: "guide", Type: kubeopenv1alpha1.ContextTypeText, Text: "Task {{ .task.name }} in ${task.namespace}"},
+		{Name: "cfg", Type: kubeopenv1alpha1.ContextTypeConfigMap, Text: "${params.repo}"},
+	}
+
+	got, rendered, err := RenderTaskText(task, contexts)
+	if err != nil {
+		t.Fatalf("RenderTaskText() error: %v", err)
+	}
+	if got != "Review org/x#123" {
+		t.Errorf("description = %q", got)
+	}
+	if rendered[0].Text != "Task review in ci" {
+		t.Errorf("Text context = %q", rendered[0].Text)
+	}
+	if rendered[1].Text != "${params.repo}" {
+		t.Errorf("non-Text context was rendered: %q", rendered[1].Text)
+	}
+	if contexts[0].Text != "Task {{ .task.name }} in ${task.namespace}" {
+		t.Errorf("input contexts were modified: %q", contexts[0].Text)
+	}
+
+	contexts[0].Text = "${params.missing}"
+	if _, _, err := RenderTaskText(task, contexts); err == nil || !strings.Contains(err.Error(), `"guide"`) || !strings.Contains(err.Error(), "params.missing") {
+		t.Errorf("RenderTaskText() error = %v, want undefined params.missing in guide", err)
+	}
+}
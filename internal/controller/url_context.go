@@ -0,0 +1,200 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+)
+
+const (
+	// URLFetchContainerPrefix is the name prefix of the init containers that fetch URL contexts
+	URLFetchContainerPrefix = "url-fetch"
+
+	// DefaultURLContextDir is the directory (relative to workspaceDir) for URL contexts without mountPath
+	DefaultURLContextDir = ".kubeopencode/urls"
+
+	// archiveFormatAuto tells url-fetch to detect the archive format from the content
+	archiveFormatAuto = "Auto"
+)
+
+// urlFetchSpec is a URL or Archive context to be fetched by a url-fetch init container.
+// For Archive contexts, targetPath is the directory the archive is extracted into.
+type urlFetchSpec struct {
+	name            string
+	source          string
+	targetPath      string
+	headers         map[string]string
+	secretName      string
+	insecure        bool
+	timeout         *int32
+	sha256          string
+	archiveFormat   string // empty for URL contexts
+	stripComponents int32
+}
+
+// urlContextName returns the name of a URL or Archive context, defaulting to its position.
+func urlContextName(item *kubeopenv1alpha1.ContextItem, index int) string {
+	prefix := "url"
+	if item.Type == kubeopenv1alpha1.ContextTypeArchive {
+		prefix = "archive"
+	}
+	return defaultString(item.Name, fmt.Sprintf("%s-%d", prefix, index))
+}
+
+// urlContextTarget returns the absolute file path a URL context is fetched to.
+// Without mountPath, the content is written under DefaultURLContextDir.
+func urlContextTarget(item *kubeopenv1alpha1.ContextItem, name, workspaceDir string) string {
+	return resolveMountPath(defaultString(item.MountPath, DefaultURLContextDir+"/"+name), workspaceDir)
+}
+
+// nameURLContexts returns items with unnamed URL and Archive contexts named after their
+// position, counted from offset. The Task's contexts are numbered after the Agent's or
+// AgentTemplate's, so that default names and fetch targets are unique across both lists.
+func nameURLContexts(items []kubeopenv1alpha1.ContextItem, offset int) []kubeopenv1alpha1.ContextItem {
+	named := slices.Clone(items)
+	for i := range named {
+		item := &named[i]
+		if item.Type == kubeopenv1alpha1.ContextTypeURL || item.Type == kubeopenv1alpha1.ContextTypeArchive {
+			item.Name = urlContextName(item, offset+i)
+		}
+	}
+	return named
+}
+
+// collectURLFetches builds url-fetch specs from the URL and Archive contexts of the
+// Agent/AgentTemplate and Task context lists combined (in that order).
+// Names follow the same positional defaults as context resolution.
+func collectURLFetches(items []kubeopenv1alpha1.ContextItem, workspaceDir string) []urlFetchSpec {
+	var specs []urlFetchSpec
+	for i := range items {
+		item := &items[i]
+		name := urlContextName(item, i)
+
+		var spec urlFetchSpec
+		switch {
+		case item.Type == kubeopenv1alpha1.ContextTypeURL && item.URL != nil:
+			spec = newURLFetchSpec(item.URL)
+			spec.targetPath = urlContextTarget(item, name, workspaceDir)
+		case item.Type == kubeopenv1alpha1.ContextTypeArchive && item.Archive != nil && item.MountPath != "":
+			spec = newURLFetchSpec(&item.Archive.URLContext)
+			spec.targetPath = resolveMountPath(item.MountPath, workspaceDir)
+			spec.archiveFormat = defaultString(string(item.Archive.Format), archiveFormatAuto)
+			spec.stripComponents = item.Archive.StripComponents
+		default:
+			continue
+		}
+		spec.name = name
+		specs = append(specs, spec)
+	}
+	return specs
+}
+
+// newURLFetchSpec copies the download settings of a URL context into a url-fetch spec.
+func newURLFetchSpec(u *kubeopenv1alpha1.URLContext) urlFetchSpec {
+	spec := urlFetchSpec{
+		source:   u.Source,
+		headers:  u.Headers,
+		insecure: u.InsecureSkipTLSVerify,
+		timeout:  u.Timeout,
+		sha256:   strings.ToLower(u.SHA256),
+	}
+	if u.SecretRef != nil {
+		spec.secretName = u.SecretRef.Name
+	}
+	return spec
+}
+
+// describeURLContext tells the agent where a URL context's content is available.
+func describeURLContext(item *kubeopenv1alpha1.ContextItem, name, workspaceDir string) string {
+	return fmt.Sprintf("Content fetched from %s is available at %s", item.URL.Source, urlContextTarget(item, name, workspaceDir))
+}
+
+// describeArchiveContext tells the agent where an Archive context has been extracted.
+func describeArchiveContext(item *kubeopenv1alpha1.ContextItem, workspaceDir string) string {
+	return fmt.Sprintf("The archive %s has been extracted into %s", item.Archive.Source, resolveMountPath(item.MountPath, workspaceDir))
+}
+
+// buildURLFetchContainer creates an init container that fetches a URL context into the workspace,
+// or downloads and extracts an Archive context. When an expected SHA-256 is set, the content is
+// verified before it is written or extracted.
+// FallbackToLogsOnError surfaces fetch and verification errors in the Task's failure detail.
+func buildURLFetchContainer(index int, spec urlFetchSpec, workspaceDir string, sysCfg systemConfig) corev1.Container {
+	envVars := []corev1.EnvVar{
+		{Name: "URL_SOURCE", Value: spec.source},
+		{Name: "URL_TARGET", Value: spec.targetPath},
+	}
+	if len(spec.headers) > 0 {
+		headersJSON, _ := json.Marshal(spec.headers)
+		envVars = append(envVars, corev1.EnvVar{Name: "URL_HEADERS", Value: string(headersJSON)})
+	}
+	if spec.timeout != nil {
+		envVars = append(envVars, corev1.EnvVar{Name: "URL_TIMEOUT", Value: strconv.Itoa(int(*spec.timeout))})
+	}
+	if spec.insecure {
+		envVars = append(envVars, corev1.EnvVar{Name: "URL_INSECURE", Value: "true"})
+	}
+	if spec.sha256 != "" {
+		envVars = append(envVars, corev1.EnvVar{Name: "URL_SHA256", Value: spec.sha256})
+	}
+	if spec.archiveFormat != "" {
+		envVars = append(envVars, corev1.EnvVar{Name: "URL_EXTRACT", Value: spec.archiveFormat})
+		if spec.stripComponents > 0 {
+			envVars = append(envVars, corev1.EnvVar{Name: "URL_STRIP_COMPONENTS", Value: strconv.Itoa(int(spec.stripComponents))})
+		}
+	}
+	if spec.secretName != "" {
+		for _, kv := range [][2]string{{"URL_AUTH_TOKEN", "token"}, {"URL_AUTH_USERNAME", "username"}, {"URL_AUTH_PASSWORD", "password"}} {
+			envVars = append(envVars, corev1.EnvVar{
+				Name: kv[0],
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: spec.secretName},
+						Key:                  kv[1],
+						Optional:             boolPtr(true),
+					},
+				},
+			})
+		}
+	}
+
+	return corev1.Container{
+		Name:                     fmt.Sprintf("%s-%d", URLFetchContainerPrefix, index),
+		Image:                    sysCfg.systemImage,
+		ImagePullPolicy:          sysCfg.systemImagePullPolicy,
+		Command:                  []string{"/kubeopencode", "url-fetch"},
+		Env:                      envVars,
+		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: WorkspaceVolumeName, MountPath: workspaceDir},
+		},
+	}
+}
+
+// isContextInitContainer reports whether an init container fetches context content,
+// so that its failure is reported as a ContextError.
+func isContextInitContainer(name string) bool {
+	return contextInitContainerType(name) != ""
+}
+
+// contextInitContainerType returns the type of context an init container fetches,
+// or "" if it does not fetch context content. url-fetch containers also fetch
+// Archive contexts and are reported as URL.
+func contextInitContainerType(name string) kubeopenv1alpha1.ContextType {
+	switch {
+	case strings.HasPrefix(name, URLFetchContainerPrefix+"-"):
+		return kubeopenv1alpha1.ContextTypeURL
+	case strings.HasPrefix(name, "git-init"):
+		return kubeopenv1alpha1.ContextTypeGit
+	case name == VaultFetchContainerName:
+		return kubeopenv1alpha1.ContextTypeVault
+	}
+	return ""
+}
@@ -0,0 +1,179 @@
+// Copyright Contributors to the KubeOpenCode project
+
+//go:build !integration
+
+package controller
+
+import (
+	"slices"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+)
+
+func TestCollectURLFetches(t *testing.T) {
+	items := []kubeopenv1alpha1.ContextItem{
+		{Type: kubeopenv1alpha1.ContextTypeText, Text: "ignored"},
+		{
+			Type: kubeopenv1alpha1.ContextTypeURL,
+			URL:  &kubeopenv1alpha1.URLContext{Source: "https://example.com/guide.md"},
+		},
+		{
+			Name:      "openapi",
+			Type:      kubeopenv1alpha1.ContextTypeURL,
+			MountPath: "specs/openapi.yaml",
+			URL: &kubeopenv1alpha1.URLContext{
+				Source:    "https://api.example.com/openapi.yaml",
+				SecretRef: &kubeopenv1alpha1.URLSecretReference{Name: "api-creds"},
+				SHA256:    "ABCDEF0123456789ABCDEF0123456789ABCDEF0123456789ABCDEF0123456789",
+			},
+		},
+		{
+			Type:      kubeopenv1alpha1.ContextTypeArchive,
+			MountPath: "release",
+			Archive: &kubeopenv1alpha1.ArchiveContext{
+				URLContext:      kubeopenv1alpha1.URLContext{Source: "https://example.com/release.tar.gz"},
+				StripComponents: 1,
+			},
+		},
+	}
+
+	specs := collectURLFetches(items, "/workspace")
+	if len(specs) != 3 {
+		t.Fatalf("got %d specs, want 3", len(specs))
+	}
+	if specs[0].name != "url-1" || specs[0].targetPath != "/workspace/.kubeopencode/urls/url-1" {
+		t.Errorf("unnamed URL context: name=%q target=%q", specs[0].name, specs[0].targetPath)
+	}
+	if specs[1].targetPath != "/workspace/specs/openapi.yaml" || specs[1].secretName != "api-creds" {
+		t.Errorf("unexpected spec: %+v", specs[1])
+	}
+	if specs[1].sha256 != "abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789" {
+		t.Errorf("sha256 should be normalized to lower case, got %q", specs[1].sha256)
+	}
+	if a := specs[2]; a.name != "archive-3" || a.targetPath != "/workspace/release" || a.archiveFormat != "Auto" || a.stripComponents != 1 {
+		t.Errorf("unexpected archive spec: %+v", a)
+	}
+}
+
+func TestNameURLContexts(t *testing.T) {
+	agentContexts := []kubeopenv1alpha1.ContextItem{
+		{Type: kubeopenv1alpha1.ContextTypeURL, URL: &kubeopenv1alpha1.URLContext{Source: "https://example.com/agent.md"}},
+	}
+	taskContexts := []kubeopenv1alpha1.ContextItem{
+		{Type: kubeopenv1alpha1.ContextTypeURL, URL: &kubeopenv1alpha1.URLContext{Source: "https://example.com/task.md"}},
+		{Type: kubeopenv1alpha1.ContextTypeText, Text: "ignored"},
+	}
+
+	named := nameURLContexts(taskContexts, len(agentContexts))
+	if named[0].Name != "url-1" || named[1].Name != "" {
+		t.Errorf("names = %q, %q, want url-1 and no name", named[0].Name, named[1].Name)
+	}
+	if taskContexts[0].Name != "" {
+		t.Error("nameURLContexts modified its input")
+	}
+
+	// The fetch specs of both lists combined use the same names
+	specs := collectURLFetches(slices.Concat(agentContexts, taskContexts), "/workspace")
+	if len(specs) != 2 || specs[0].name != "url-0" || specs[1].name != named[0].Name {
+		t.Errorf("unexpected specs: %+v", specs)
+	}
+}
+
+func TestBuildURLFetchContainer(t *testing.T) {
+	spec := urlFetchSpec{
+		name:       "openapi",
+		source:     "https://api.example.com/openapi.yaml",
+		targetPath: "/workspace/specs/openapi.yaml",
+		headers:    map[string]string{"X-API-Key": "k"},
+		secretName: "api-creds",
+		timeout:    ptr.To(int32(60)),
+		sha256:     "abc",
+	}
+	c := buildURLFetchContainer(2, spec, "/workspace", defaultSystemConfig())
+
+	if c.Name != "url-fetch-2" {
+		t.Errorf("Name = %q, want url-fetch-2", c.Name)
+	}
+	if c.TerminationMessagePolicy != corev1.TerminationMessageFallbackToLogsOnError {
+		t.Errorf("TerminationMessagePolicy = %q", c.TerminationMessagePolicy)
+	}
+	envMap := make(map[string]corev1.EnvVar)
+	for _, env := range c.Env {
+		envMap[env.Name] = env
+	}
+	for name, want := range map[string]string{
+		"URL_SOURCE":  spec.source,
+		"URL_TARGET":  spec.targetPath,
+		"URL_HEADERS": `{"X-API-Key":"k"}`,
+		"URL_TIMEOUT": "60",
+		"URL_SHA256":  "abc",
+	} {
+		if envMap[name].Value != want {
+			t.Errorf("%s = %q, want %q", name, envMap[name].Value, want)
+		}
+	}
+	if ref := envMap["URL_AUTH_TOKEN"].ValueFrom; ref == nil || ref.SecretKeyRef.Name != "api-creds" || ref.SecretKeyRef.Key != "token" {
+		t.Errorf("URL_AUTH_TOKEN should reference the token key of api-creds")
+	}
+	if _, ok := envMap["URL_EXTRACT"]; ok {
+		t.Errorf("URL_EXTRACT should not be set for URL contexts")
+	}
+
+	spec.archiveFormat = "Zip"
+	spec.stripComponents = 2
+	c = buildURLFetchContainer(0, spec, "/workspace", defaultSystemConfig())
+	envMap = make(map[string]corev1.EnvVar)
+	for _, env := range c.Env {
+		envMap[env.Name] = env
+	}
+	if envMap["URL_EXTRACT"].Value != "Zip" || envMap["URL_STRIP_COMPONENTS"].Value != "2" {
+		t.Errorf("archive env = %q/%q, want Zip/2", envMap["URL_EXTRACT"].Value, envMap["URL_STRIP_COMPONENTS"].Value)
+	}
+}
+
+func TestGetFailedContextInitContainer(t *testing.T) {
+	status := func(name string, exitCode int32) corev1.ContainerStatus {
+		return corev1.ContainerStatus{
+			Name:  name,
+			State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: exitCode}},
+		}
+	}
+	tests := []struct {
+		name     string
+		statuses []corev1.ContainerStatus
+		want     string
+	}{
+		{name: "url-fetch failed", statuses: []corev1.ContainerStatus{status("opencode-init", 0), status("url-fetch-0", 1)}, want: "url-fetch-0"},
+		{name: "git-init failed", statuses: []corev1.ContainerStatus{status("git-init-1", 128)}, want: "git-init-1"},
+		{name: "vault-fetch failed", statuses: []corev1.ContainerStatus{status(VaultFetchContainerName, 1)}, want: VaultFetchContainerName},
+		{name: "other init container failed", statuses: []corev1.ContainerStatus{status("plugin-init", 1)}, want: ""},
+		{name: "all succeeded", statuses: []corev1.ContainerStatus{status("url-fetch-0", 0)}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{Status: corev1.PodStatus{InitContainerStatuses: tt.statuses}}
+			if got := getFailedContextInitContainer(pod); got != tt.want {
+				t.Errorf("getFailedContextInitContainer() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContextInitContainerType(t *testing.T) {
+	tests := map[string]kubeopenv1alpha1.ContextType{
+		"url-fetch-0":           kubeopenv1alpha1.ContextTypeURL,
+		"git-init-1":            kubeopenv1alpha1.ContextTypeGit,
+		VaultFetchContainerName: kubeopenv1alpha1.ContextTypeVault,
+		"opencode-init":         "",
+		"url-fetcher":           "",
+	}
+	for name, want := range tests {
+		if got := contextInitContainerType(name); got != want {
+			t.Errorf("contextInitContainerType(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+)
+
+const (
+	// VaultFetchContainerName is the name of the init container that fetches Vault contexts
+	VaultFetchContainerName = "vault-fetch"
+
+	// DefaultVaultAuthMount is the default mount path of the Vault Kubernetes auth method
+	DefaultVaultAuthMount = "kubernetes"
+
+	// DefaultVaultEngine is the default mount path of the Vault KV secrets engine
+	DefaultVaultEngine = "secret"
+
+	// DefaultVaultKVVersion is the default Vault KV secrets engine version
+	DefaultVaultKVVersion int32 = 2
+
+	// DefaultVaultFileMode is the default permission mode for files written from Vault secrets
+	DefaultVaultFileMode int32 = 0600
+)
+
+// vaultFetchSpec is a single Vault secret passed to the vault-fetch init container
+// via the VAULT_CONTEXTS env var. It contains no secret values.
+type vaultFetchSpec struct {
+	Name       string         `json:"name"`
+	TargetDir  string         `json:"targetDir"`
+	Address    string         `json:"address"`
+	Role       string         `json:"role"`
+	AuthMount  string         `json:"authMount"`
+	Namespace  string         `json:"namespace,omitempty"`
+	SecretPath string         `json:"secretPath"`
+	KVVersion  int32          `json:"kvVersion"`
+	FileMode   int32          `json:"fileMode"`
+	Keys       []vaultKeySpec `json:"keys,omitempty"`
+}
+
+// vaultKeySpec maps a Vault secret key to a file relative to the target directory.
+type vaultKeySpec struct {
+	Key  string `json:"key"`
+	Path string `json:"path"`
+	Mode *int32 `json:"mode,omitempty"`
+}
+
+// vaultSecretAPIPath returns the Vault HTTP API path (without the /v1/ prefix) for
+// reading a KV secret. KV v2 stores secret data under the "data/" sub-path.
+func vaultSecretAPIPath(engine, secretPath string, kvVersion int32) string {
+	engine = strings.Trim(engine, "/")
+	secretPath = strings.Trim(secretPath, "/")
+	if kvVersion == 1 {
+		return engine + "/" + secretPath
+	}
+	return engine + "/data/" + secretPath
+}
+
+// vaultKeySpecs converts Vault key mappings into vault-fetch key specs.
+// Path defaults to the key name and must not escape the mount directory.
+func vaultKeySpecs(keys []kubeopenv1alpha1.VaultKeyToPath) ([]vaultKeySpec, error) {
+	specs := make([]vaultKeySpec, 0, len(keys))
+	for _, k := range keys {
+		if k.Key == "" {
+			return nil, fmt.Errorf("key must not be empty")
+		}
+		p := defaultString(k.Path, k.Key)
+		if path.IsAbs(p) {
+			return nil, fmt.Errorf("path %q for key %s must be relative", p, k.Key)
+		}
+		p = path.Clean(p)
+		if p == "." || p == ".." || strings.HasPrefix(p, "../") {
+			return nil, fmt.Errorf("path %q for key %s must not escape the mount directory", k.Path, k.Key)
+		}
+		specs = append(specs, vaultKeySpec{Key: k.Key, Path: p, Mode: k.Mode})
+	}
+	return specs, nil
+}
+
+// collectVaultContexts builds vault-fetch specs from all Vault contexts.
+// Contexts must have been validated by context resolution.
+func collectVaultContexts(items []kubeopenv1alpha1.ContextItem, workspaceDir string) []vaultFetchSpec {
+	var specs []vaultFetchSpec
+	for i := range items {
+		item := &items[i]
+		if item.Type != kubeopenv1alpha1.ContextTypeVault || item.Vault == nil || item.MountPath == "" {
+			continue
+		}
+		v := item.Vault
+
+		kvVersion := DefaultVaultKVVersion
+		if v.KVVersion != nil {
+			kvVersion = *v.KVVersion
+		}
+		fileMode := DefaultVaultFileMode
+		if item.FileMode != nil {
+			fileMode = *item.FileMode
+		}
+		keys, _ := vaultKeySpecs(v.Keys) // validated during context resolution
+
+		specs = append(specs, vaultFetchSpec{
+			Name:       defaultString(item.Name, fmt.Sprintf("vault-%d", i)),
+			TargetDir:  resolveMountPath(item.MountPath, workspaceDir),
+			Address:    strings.TrimRight(v.Address, "/"),
+			Role:       v.Role,
+			AuthMount:  strings.Trim(defaultString(v.AuthMount, DefaultVaultAuthMount), "/"),
+			Namespace:  v.Namespace,
+			SecretPath: vaultSecretAPIPath(defaultString(v.Engine, DefaultVaultEngine), v.Path, kvVersion),
+			KVVersion:  kvVersion,
+			FileMode:   fileMode,
+			Keys:       keys,
+		})
+	}
+	return specs
+}
+
+// describeVaultContext tells the agent where a Vault context's files are written.
+// Secret values are never included.
+func describeVaultContext(item *kubeopenv1alpha1.ContextItem, workspaceDir string) string {
+	if item.Vault == nil {
+		return ""
+	}
+	dir := resolveMountPath(item.MountPath, workspaceDir)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Secret values from Vault path %q are available as files in %s", item.Vault.Path, dir)
+	if len(item.Vault.Keys) == 0 {
+		b.WriteString(" (one file per secret key).")
+	} else {
+		b.WriteString(":\n")
+		for _, k := range item.Vault.Keys {
+			fmt.Fprintf(&b, "- %s\n", defaultString(k.Path, k.Key))
+		}
+	}
+	b.WriteString("\nDo not print these values or commit them to a repository.")
+	return b.String()
+}
+
+// buildVaultFetchContainer creates an init container that logs in to Vault with the
+// Pod's ServiceAccount token and writes the selected secret values into the workspace.
+func buildVaultFetchContainer(specs []vaultFetchSpec, workspaceDir string, sysCfg systemConfig) corev1.Container {
+	specsJSON, _ := json.Marshal(specs)
+
+	return corev1.Container{
+		Name:            VaultFetchContainerName,
+		Image:           sysCfg.systemImage,
+		ImagePullPolicy: sysCfg.systemImagePullPolicy,
+		Command:         []string{"/kubeopencode", "vault-fetch"},
+		Env: []corev1.EnvVar{
+			{Name: "VAULT_CONTEXTS", Value: string(specsJSON)},
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: WorkspaceVolumeName, MountPath: workspaceDir},
+		},
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright Contributors to the KubeOpenCode project
+
+//go:build !integration
+
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+)
+
+func TestVaultSecretAPIPath(t *testing.T) {
+	if got := vaultSecretAPIPath("secret/", "/team/ci", 2); got != "secret/data/team/ci" {
+		t.Errorf("KV v2 path = %q", got)
+	}
+	if got := vaultSecretAPIPath("kv", "team/ci", 1); got != "kv/team/ci" {
+		t.Errorf("KV v1 path = %q", got)
+	}
+}
+
+func TestCollectVaultContexts(t *testing.T) {
+	v1 := int32(1)
+	items := []kubeopenv1alpha1.ContextItem{
+		{Type: kubeopenv1alpha1.ContextTypeText, Text: "ignored"},
+		{
+			Name:      "ci-secrets",
+			Type:      kubeopenv1alpha1.ContextTypeVault,
+			MountPath: ".secrets",
+			Vault: &kubeopenv1alpha1.VaultContext{
+				Address: "https://vault.example.com:8200/",
+				Role:    "ci",
+				Path:    "team/ci",
+				Keys:    []kubeopenv1alpha1.VaultKeyToPath{{Key: "token", Path: "./ci/token"}},
+			},
+		},
+		{
+			Type:      kubeopenv1alpha1.ContextTypeVault,
+			MountPath: "/etc/legacy",
+			Vault: &kubeopenv1alpha1.VaultContext{
+				Address:   "https://vault.example.com",
+				Role:      "legacy",
+				AuthMount: "k8s-prod",
+				Engine:    "kv",
+				Path:      "legacy",
+				KVVersion: &v1,
+			},
+		},
+	}
+
+	specs := collectVaultContexts(items, "/workspace")
+	if len(specs) != 2 {
+		t.Fatalf("got %d specs, want 2", len(specs))
+	}
+	first := specs[0]
+	if first.Name != "ci-secrets" || first.TargetDir != "/workspace/.secrets" || first.Address != "https://vault.example.com:8200" {
+		t.Errorf("unexpected first spec: %+v", first)
+	}
+	if first.AuthMount != DefaultVaultAuthMount || first.SecretPath != "secret/data/team/ci" || first.FileMode != DefaultVaultFileMode {
+		t.Errorf("unexpected defaults: %+v", first)
+	}
+	if len(first.Keys) != 1 || first.Keys[0].Path != "ci/token" {
+		t.Errorf("unexpected keys: %+v", first.Keys)
+	}
+	second := specs[1]
+	if second.Name != "vault-2" || second.TargetDir != "/etc/legacy" || second.SecretPath != "kv/legacy" || second.AuthMount != "k8s-prod" {
+		t.Errorf("unexpected second spec: %+v", second)
+	}
+}
+
+func TestVaultKeySpecs(t *testing.T) {
+	for _, p := range []string{"/etc/token", "../token", "."} {
+		if _, err := vaultKeySpecs([]kubeopenv1alpha1.VaultKeyToPath{{Key: "token", Path: p}}); err == nil {
+			t.Errorf("expected error for path %q", p)
+		}
+	}
+}
+
+func TestDescribeVaultContext(t *testing.T) {
+	item := &kubeopenv1alpha1.ContextItem{
+		Type:      kubeopenv1alpha1.ContextTypeVault,
+		MountPath: ".secrets",
+		Vault: &kubeopenv1alpha1.VaultContext{
+			Path: "team/ci",
+			Keys: []kubeopenv1alpha1.VaultKeyToPath{{Key: "token"}},
+		},
+	}
+	got := describeVaultContext(item, "/workspace")
+	if !strings.Contains(got, "/workspace/.secrets") || !strings.Contains(got, "- token") {
+		t.Errorf("describeVaultContext() = %q", got)
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+)
+
+const (
+	// WorkspaceSnapshotVolumeName is the volume name for the failure snapshot PVC
+	WorkspaceSnapshotVolumeName = "workspace-snapshots"
+
+	// WorkspaceSnapshotMountPath is where the failure snapshot PVC is mounted in the agent container
+	WorkspaceSnapshotMountPath = "/kubeopencode-snapshots"
+
+	// DefaultWorkspaceSnapshotMaxSize is the default maximum workspace size to archive
+	DefaultWorkspaceSnapshotMaxSize = "1Gi"
+
+	// DefaultWorkspaceSnapshotRetention is the default number of snapshots kept per namespace
+	DefaultWorkspaceSnapshotRetention int32 = 10
+
+	// workspaceSnapshotMessagePrefix prefixes the snapshot path written to the
+	// agent container's termination message, where the controller picks it up.
+	workspaceSnapshotMessagePrefix = "workspace-snapshot: "
+)
+
+// workspaceSnapshotScript runs the original command (passed as "$@") and archives
+// the workspace into the snapshot volume only when the command fails.
+// The exit code of the original command is always preserved.
+//
+// Placeholders: %[1]s mount path, %[2]d max size in KiB, %[3]d retention, %[4]s message prefix.
+const workspaceSnapshotScript = `"$@"
+rc=$?
+if [ "$rc" -ne 0 ]; then
+  dir="%[1]s/${TASK_NAMESPACE}"
+  name="${TASK_NAME}-$(date -u +%%Y%%m%%d%%H%%M%%S).tar.gz"
+  size=$(du -sk "${WORKSPACE_DIR}" 2>/dev/null | cut -f1)
+  if [ "${size:-0}" -gt %[2]d ]; then
+    echo "workspace snapshot skipped: ${size}KiB exceeds limit of %[2]dKiB" >&2
+  elif mkdir -p "$dir" && tar -czf "$dir/$name" -C "${WORKSPACE_DIR}" . 2>/dev/null; then
+    echo "%[4]s${TASK_NAMESPACE}/$name" > /dev/termination-log
+    echo "workspace snapshot saved to ${TASK_NAMESPACE}/$name" >&2
+    ls -1t "$dir"/*.tar.gz 2>/dev/null | tail -n +%[3]d | xargs -r rm -f
+  else
+    echo "workspace snapshot failed" >&2
+  fi
+fi
+exit $rc`
+
+// resolveFailureSnapshot returns the effective failure snapshot configuration for a
+// templateRef Task: Task-level configuration overrides the template-level default.
+// Returns an error if the configuration is invalid.
+func resolveFailureSnapshot(task *kubeopenv1alpha1.Task, cfg agentConfig) (*kubeopenv1alpha1.WorkspaceSnapshotConfig, error) {
+	snapshot := firstNonNilPtr(task.Spec.FailureSnapshot, cfg.failureSnapshot)
+	if snapshot == nil {
+		return nil, nil
+	}
+	if snapshot.ClaimName == "" {
+		return nil, fmt.Errorf("failureSnapshot.claimName is required")
+	}
+	if _, err := resource.ParseQuantity(defaultString(snapshot.MaxSize, DefaultWorkspaceSnapshotMaxSize)); err != nil {
+		return nil, fmt.Errorf("invalid failureSnapshot.maxSize %q: %w", snapshot.MaxSize, err)
+	}
+	return snapshot, nil
+}
+
+// wrapCommandWithFailureSnapshot wraps the agent command so that the workspace is
+// archived into the snapshot volume when the command exits non-zero.
+// The original command is passed through as positional arguments, so any
+// command vector (default or user-provided) is preserved verbatim.
+// The snapshot configuration must have been validated by resolveFailureSnapshot.
+func wrapCommandWithFailureSnapshot(command []string, snapshot *kubeopenv1alpha1.WorkspaceSnapshotConfig) []string {
+	maxSize := resource.MustParse(defaultString(snapshot.MaxSize, DefaultWorkspaceSnapshotMaxSize))
+	retention := DefaultWorkspaceSnapshotRetention
+	if snapshot.Retention != nil && *snapshot.Retention > 0 {
+		retention = *snapshot.Retention
+	}
+
+	script := fmt.Sprintf(workspaceSnapshotScript,
+		WorkspaceSnapshotMountPath, maxSize.Value()/1024, retention+1, workspaceSnapshotMessagePrefix)
+
+	// "sh -c script sh cmd..." sets $0 to "sh" and "$@" to the original command.
+	wrapped := []string{"sh", "-c", script, "sh"}
+	return append(wrapped, command...)
+}
+
+// buildFailureSnapshotVolume returns the PVC volume and agent container mount for failure snapshots.
+func buildFailureSnapshotVolume(snapshot *kubeopenv1alpha1.WorkspaceSnapshotConfig) (corev1.Volume, corev1.VolumeMount) {
+	volume := corev1.Volume{
+		Name: WorkspaceSnapshotVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: snapshot.ClaimName,
+			},
+		},
+	}
+	mount := corev1.VolumeMount{
+		Name:      WorkspaceSnapshotVolumeName,
+		MountPath: WorkspaceSnapshotMountPath,
+	}
+	return volume, mount
+}
+
+// getWorkspaceSnapshotStatus extracts the failure snapshot location from a failed Pod.
+// Returns nil if the Pod has no snapshot volume or the agent container did not report one.
+func getWorkspaceSnapshotStatus(pod *corev1.Pod) *kubeopenv1alpha1.WorkspaceSnapshotStatus {
+	claimName := ""
+	for _, vol := range pod.Spec.Volumes {
+		if vol.Name == WorkspaceSnapshotVolumeName && vol.PersistentVolumeClaim != nil {
+			claimName = vol.PersistentVolumeClaim.ClaimName
+			break
+		}
+	}
+	if claimName == "" {
+		return nil
+	}
+
+	for i := range pod.Status.ContainerStatuses {
+		term := pod.Status.ContainerStatuses[i].State.Terminated
+		if term == nil {
+			continue
+		}
+		for _, line := range strings.Split(term.Message, "\n") {
+			if path, ok := strings.CutPrefix(strings.TrimSpace(line), workspaceSnapshotMessagePrefix); ok && path != "" {
+				return &kubeopenv1alpha1.WorkspaceSnapshotStatus{
+					ClaimName: claimName,
+					Path:      path,
+				}
+			}
+		}
+	}
+	return nil
+}
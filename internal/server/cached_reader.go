@@ -0,0 +1,110 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package server
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+	authmiddleware "github.com/kubeopencode/kubeopencode/internal/server/middleware"
+)
+
+const (
+	// accessReviewTTL is how long a SubjectAccessReview decision is reused
+	accessReviewTTL = 30 * time.Second
+
+	// maxAccessReviewDecisions bounds the number of remembered decisions
+	maxAccessReviewDecisions = 4096
+)
+
+// cachedReader implements handlers.CachedReader for one request.
+// Requests without a user (auth disabled or anonymous) act with the server's
+// identity and always read from the cache; users need list permission,
+// which is checked with a SubjectAccessReview.
+type cachedReader struct {
+	cache     client.Reader
+	clientset kubernetes.Interface
+	user      *authmiddleware.UserInfo
+	reviews   *accessReviewCache
+}
+
+// ReaderFor returns the cache if the request's user may list resource in namespace.
+func (r *cachedReader) ReaderFor(ctx context.Context, resource, namespace string) client.Reader {
+	if r.user == nil || r.reviews.allowed(ctx, r.clientset, r.user, resource, namespace) {
+		return r.cache
+	}
+	return nil
+}
+
+// accessReviewCache remembers SubjectAccessReview decisions for list permissions,
+// so that dashboards polling the API do not trigger a review per request.
+type accessReviewCache struct {
+	mu        sync.Mutex
+	decisions map[string]accessDecision
+}
+
+type accessDecision struct {
+	allowed bool
+	expires time.Time
+}
+
+func newAccessReviewCache() *accessReviewCache {
+	return &accessReviewCache{decisions: map[string]accessDecision{}}
+}
+
+// allowed reports whether user may list resource in namespace ("" for all namespaces).
+// Review failures deny cache access; the request then uses the user's live client,
+// which enforces RBAC itself.
+func (c *accessReviewCache) allowed(ctx context.Context, clientset kubernetes.Interface, user *authmiddleware.UserInfo, resource, namespace string) bool {
+	key := strings.Join([]string{user.Username, user.UID, strings.Join(user.Groups, ","), resource, namespace}, "\x00")
+	now := time.Now()
+
+	c.mu.Lock()
+	decision, ok := c.decisions[key]
+	c.mu.Unlock()
+	if ok && now.Before(decision.expires) {
+		return decision.allowed
+	}
+
+	review := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   user.Username,
+			UID:    user.UID,
+			Groups: user.Groups,
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: namespace,
+				Verb:      "list",
+				Group:     kubeopenv1alpha1.GroupName,
+				Resource:  resource,
+			},
+		},
+	}
+	result, err := clientset.AuthorizationV1().SubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		log.Error(err, "SubjectAccessReview failed, listing with the user's client", "user", user.Username, "resource", resource)
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.decisions) >= maxAccessReviewDecisions {
+		for k, d := range c.decisions {
+			if now.After(d.expires) {
+				delete(c.decisions, k)
+			}
+		}
+		if len(c.decisions) >= maxAccessReviewDecisions {
+			c.decisions = map[string]accessDecision{}
+		}
+	}
+	c.decisions[key] = accessDecision{allowed: result.Status.Allowed, expires: now.Add(accessReviewTTL)}
+	return result.Status.Allowed
+}
@@ -0,0 +1,69 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package server
+
+import (
+	"context"
+	"testing"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	kubetesting "k8s.io/client-go/testing"
+	clientfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	authmiddleware "github.com/kubeopencode/kubeopencode/internal/server/middleware"
+)
+
+func TestCachedReader_ReaderFor(t *testing.T) {
+	var reviews []*authorizationv1.SubjectAccessReview
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("create", "subjectaccessreviews", func(action kubetesting.Action) (bool, runtime.Object, error) {
+		review := action.(kubetesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		reviews = append(reviews, review)
+		// Only team-a may be listed
+		review.Status.Allowed = review.Spec.ResourceAttributes.Namespace == "team-a"
+		return true, review, nil
+	})
+	cache := clientfake.NewClientBuilder().Build()
+	accessReviews := newAccessReviewCache()
+	ctx := context.Background()
+
+	t.Run("server identity always uses the cache", func(t *testing.T) {
+		r := &cachedReader{cache: cache, clientset: clientset, reviews: accessReviews}
+		if r.ReaderFor(ctx, "tasks", "") == nil {
+			t.Error("expected cache for requests without user")
+		}
+		if len(reviews) != 0 {
+			t.Errorf("expected no SubjectAccessReview, got %d", len(reviews))
+		}
+	})
+
+	user := &authmiddleware.UserInfo{Username: "jane", Groups: []string{"dev"}}
+	r := &cachedReader{cache: cache, clientset: clientset, user: user, reviews: accessReviews}
+
+	t.Run("allowed user uses the cache", func(t *testing.T) {
+		if r.ReaderFor(ctx, "tasks", "team-a") == nil {
+			t.Error("expected cache for allowed namespace")
+		}
+		attrs := reviews[len(reviews)-1].Spec
+		if attrs.User != "jane" || attrs.ResourceAttributes.Verb != "list" || attrs.ResourceAttributes.Resource != "tasks" {
+			t.Errorf("unexpected SubjectAccessReview %+v", attrs)
+		}
+	})
+
+	t.Run("denied user lists with the live client", func(t *testing.T) {
+		if r.ReaderFor(ctx, "tasks", "team-b") != nil {
+			t.Error("expected no cache for denied namespace")
+		}
+	})
+
+	t.Run("decisions are reused", func(t *testing.T) {
+		before := len(reviews)
+		r.ReaderFor(ctx, "tasks", "team-a")
+		r.ReaderFor(ctx, "tasks", "team-b")
+		if len(reviews) != before {
+			t.Errorf("expected cached decisions, got %d new reviews", len(reviews)-before)
+		}
+	})
+}
@@ -8,7 +8,6 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"sort"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -61,7 +60,8 @@ func (h *AgentHandler) ListAll(w http.ResponseWriter, r *http.Request) {
 	var agentList kubeopenv1alpha1.AgentList
 	listOpts := BuildListOptions("", filterOpts) // empty namespace = all namespaces
 
-	if err := k8sClient.List(ctx, &agentList, listOpts...); err != nil {
+	reader, _ := listReader(ctx, k8sClient, "agents", "")
+	if err := reader.List(ctx, &agentList, listOpts...); err != nil {
 		writeError(w, http.StatusInternalServerError, "Failed to list agents", err.Error())
 		return
 	}
@@ -69,37 +69,26 @@ func (h *AgentHandler) ListAll(w http.ResponseWriter, r *http.Request) {
 	// Filter by name (in-memory)
 	var filteredItems []kubeopenv1alpha1.Agent
 	for _, agent := range agentList.Items {
-		if MatchesNameFilter(agent.Name, filterOpts.Name) {
+		if MatchesNameFilter(agent.Name, filterOpts.Name) && namespaceAllowed(ctx, agent.Namespace) {
 			filteredItems = append(filteredItems, agent)
 		}
 	}
 
-	// Sort by CreationTimestamp
-	sort.Slice(filteredItems, func(i, j int) bool {
-		if filterOpts.SortOrder == "asc" {
-			return filteredItems[i].CreationTimestamp.Before(&filteredItems[j].CreationTimestamp)
-		}
-		return filteredItems[j].CreationTimestamp.Before(&filteredItems[i].CreationTimestamp)
-	})
-
-	totalCount := len(filteredItems)
-
-	// Apply pagination bounds
-	start := min(filterOpts.Offset, totalCount)
-	end := min(start+filterOpts.Limit, totalCount)
+	// Sort by CreationTimestamp and paginate
+	paginatedItems, pagination, err := paginate(filteredItems, filterOpts)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid filter parameters", err.Error())
+		return
+	}
 
-	paginatedItems := filteredItems[start:end]
-	hasMore := end < totalCount
+	if notModified(w, r, listETag(filteredItems)) {
+		return
+	}
 
 	response := types.AgentListResponse{
-		Agents: make([]types.AgentResponse, 0, len(paginatedItems)),
-		Total:  totalCount,
-		Pagination: &types.Pagination{
-			Limit:      filterOpts.Limit,
-			Offset:     filterOpts.Offset,
-			TotalCount: totalCount,
-			HasMore:    hasMore,
-		},
+		Agents:     make([]types.AgentResponse, 0, len(paginatedItems)),
+		Total:      pagination.TotalCount,
+		Pagination: pagination,
 	}
 
 	for _, agent := range paginatedItems {
@@ -124,7 +113,8 @@ func (h *AgentHandler) List(w http.ResponseWriter, r *http.Request) {
 	var agentList kubeopenv1alpha1.AgentList
 	listOpts := BuildListOptions(namespace, filterOpts)
 
-	if err := k8sClient.List(ctx, &agentList, listOpts...); err != nil {
+	reader, _ := listReader(ctx, k8sClient, "agents", namespace)
+	if err := reader.List(ctx, &agentList, listOpts...); err != nil {
 		writeError(w, http.StatusInternalServerError, "Failed to list agents", err.Error())
 		return
 	}
@@ -132,37 +122,26 @@ func (h *AgentHandler) List(w http.ResponseWriter, r *http.Request) {
 	// Filter by name (in-memory)
 	var filteredItems []kubeopenv1alpha1.Agent
 	for _, agent := range agentList.Items {
-		if MatchesNameFilter(agent.Name, filterOpts.Name) {
+		if MatchesNameFilter(agent.Name, filterOpts.Name) && namespaceAllowed(ctx, agent.Namespace) {
 			filteredItems = append(filteredItems, agent)
 		}
 	}
 
-	// Sort by CreationTimestamp
-	sort.Slice(filteredItems, func(i, j int) bool {
-		if filterOpts.SortOrder == "asc" {
-			return filteredItems[i].CreationTimestamp.Before(&filteredItems[j].CreationTimestamp)
-		}
-		return filteredItems[j].CreationTimestamp.Before(&filteredItems[i].CreationTimestamp)
-	})
-
-	totalCount := len(filteredItems)
-
-	// Apply pagination bounds
-	start := min(filterOpts.Offset, totalCount)
-	end := min(start+filterOpts.Limit, totalCount)
+	// Sort by CreationTimestamp and paginate
+	paginatedItems, pagination, err := paginate(filteredItems, filterOpts)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid filter parameters", err.Error())
+		return
+	}
 
-	paginatedItems := filteredItems[start:end]
-	hasMore := end < totalCount
+	if notModified(w, r, listETag(filteredItems)) {
+		return
+	}
 
 	response := types.AgentListResponse{
-		Agents: make([]types.AgentResponse, 0, len(paginatedItems)),
-		Total:  totalCount,
-		Pagination: &types.Pagination{
-			Limit:      filterOpts.Limit,
-			Offset:     filterOpts.Offset,
-			TotalCount: totalCount,
-			HasMore:    hasMore,
-		},
+		Agents:     make([]types.AgentResponse, 0, len(paginatedItems)),
+		Total:      pagination.TotalCount,
+		Pagination: pagination,
 	}
 
 	for _, agent := range paginatedItems {
@@ -330,18 +309,6 @@ func (h *AgentHandler) Create(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "Name is required", "")
 		return
 	}
-	// workspaceDir and serviceAccountName are required only when no template is referenced,
-	// since they can be inherited from the template.
-	if req.TemplateRef == nil {
-		if req.WorkspaceDir == "" {
-			writeError(w, http.StatusBadRequest, "WorkspaceDir is required when no template is specified", "")
-			return
-		}
-		if req.ServiceAccountName == "" {
-			writeError(w, http.StatusBadRequest, "ServiceAccountName is required when no template is specified", "")
-			return
-		}
-	}
 
 	agent := &kubeopenv1alpha1.Agent{
 		ObjectMeta: metav1.ObjectMeta{
@@ -416,12 +383,17 @@ func (h *AgentHandler) Create(w http.ResponseWriter, r *http.Request) {
 		agent.Spec.Plugins = req.Plugins
 	}
 
+	if msg := validateAgentSpec(&agent.Spec); msg != "" {
+		writeError(w, http.StatusBadRequest, msg, "")
+		return
+	}
+
 	if err := k8sClient.Create(ctx, agent); err != nil {
 		if apierrors.IsAlreadyExists(err) {
 			writeError(w, http.StatusConflict, "Agent already exists", err.Error())
 			return
 		}
-		writeError(w, http.StatusInternalServerError, "Failed to create agent", err.Error())
+		writeAgentWriteError(w, "Failed to create agent", err)
 		return
 	}
 
@@ -446,14 +418,20 @@ func (h *AgentHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := k8sClient.Delete(ctx, &agent); err != nil {
-		writeError(w, http.StatusInternalServerError, "Failed to delete agent", err.Error())
+		if apierrors.IsNotFound(err) {
+			writeError(w, http.StatusNotFound, "Agent not found", err.Error())
+			return
+		}
+		writeAgentWriteError(w, "Failed to delete agent", err)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
-// Update replaces the Agent spec from a YAML body.
+// Update replaces the Agent spec from a YAML (or JSON) body.
+// If the body carries metadata.resourceVersion, the update only succeeds
+// when the Agent has not changed since that version.
 func (h *AgentHandler) Update(w http.ResponseWriter, r *http.Request) {
 	namespace := chi.URLParam(r, "namespace")
 	name := chi.URLParam(r, "name")
@@ -473,6 +451,19 @@ func (h *AgentHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if submitted.Name != "" && submitted.Name != name {
+		writeError(w, http.StatusBadRequest, "Agent name cannot be changed", fmt.Sprintf("metadata.name %q does not match %q", submitted.Name, name))
+		return
+	}
+	if submitted.Namespace != "" && submitted.Namespace != namespace {
+		writeError(w, http.StatusBadRequest, "Agent namespace cannot be changed", fmt.Sprintf("metadata.namespace %q does not match %q", submitted.Namespace, namespace))
+		return
+	}
+	if msg := validateAgentSpec(&submitted.Spec); msg != "" {
+		writeError(w, http.StatusBadRequest, msg, "")
+		return
+	}
+
 	var existing kubeopenv1alpha1.Agent
 	if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, &existing); err != nil {
 		if apierrors.IsNotFound(err) {
@@ -483,15 +474,52 @@ func (h *AgentHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if submitted.ResourceVersion != "" {
+		existing.ResourceVersion = submitted.ResourceVersion
+	}
 	existing.Spec = submitted.Spec
 	if err := k8sClient.Update(ctx, &existing); err != nil {
-		writeError(w, http.StatusInternalServerError, "Failed to update agent", err.Error())
+		writeAgentWriteError(w, "Failed to update agent", err)
 		return
 	}
 
 	writeResourceOutput(w, r, http.StatusOK, &existing, agentToResponse(&existing))
 }
 
+// validateAgentSpec checks the fields the API server cannot validate on its own
+// and returns a message describing the first problem, or "" if the spec is valid.
+func validateAgentSpec(spec *kubeopenv1alpha1.AgentSpec) string {
+	// workspaceDir and serviceAccountName are required only when no template is referenced,
+	// since they can be inherited from the template.
+	if spec.TemplateRef == nil {
+		if spec.WorkspaceDir == "" {
+			return "WorkspaceDir is required when no template is specified"
+		}
+		if spec.ServiceAccountName == "" {
+			return "ServiceAccountName is required when no template is specified"
+		}
+	} else if spec.TemplateRef.Name == "" {
+		return "TemplateRef name is required"
+	}
+	return ""
+}
+
+// writeAgentWriteError maps errors from Agent create, update and delete calls
+// to HTTP responses, so that RBAC denials of the impersonated user and schema
+// validation failures are reported as such instead of as server errors.
+func writeAgentWriteError(w http.ResponseWriter, msg string, err error) {
+	switch {
+	case apierrors.IsForbidden(err):
+		writeError(w, http.StatusForbidden, msg, err.Error())
+	case apierrors.IsInvalid(err):
+		writeError(w, http.StatusUnprocessableEntity, "Invalid Agent", err.Error())
+	case apierrors.IsConflict(err):
+		writeError(w, http.StatusConflict, "Agent was modified, reload and try again", err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, msg, err.Error())
+	}
+}
+
 // Suspend scales the server deployment to 0 replicas.
 func (h *AgentHandler) Suspend(w http.ResponseWriter, r *http.Request) {
 	h.setSuspendState(w, r, true)
@@ -12,10 +12,12 @@ import (
 	"testing"
 
 	"github.com/go-chi/chi/v5"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 
 	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
 	"github.com/kubeopencode/kubeopencode/internal/server/types"
@@ -246,6 +248,157 @@ func TestAgentHandler_Create(t *testing.T) {
 	}
 }
 
+func TestAgentHandler_Update(t *testing.T) {
+	existing := func() *kubeopenv1alpha1.Agent {
+		return &kubeopenv1alpha1.Agent{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-agent", Namespace: "default"},
+			Spec: kubeopenv1alpha1.AgentSpec{
+				WorkspaceDir:       "/workspace",
+				ServiceAccountName: "sa",
+			},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		agentName  string
+		body       string
+		wantStatus int
+	}{
+		{
+			name:       "updates agent spec",
+			agentName:  "my-agent",
+			body:       "metadata:\n  name: my-agent\nspec:\n  workspaceDir: /work\n  serviceAccountName: sa\n",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "accepts JSON body",
+			agentName:  "my-agent",
+			body:       `{"spec":{"workspaceDir":"/work","serviceAccountName":"sa"}}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "rejects name change",
+			agentName:  "my-agent",
+			body:       "metadata:\n  name: other\nspec:\n  workspaceDir: /work\n  serviceAccountName: sa\n",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "validates workspaceDir required without template",
+			agentName:  "my-agent",
+			body:       "spec:\n  serviceAccountName: sa\n",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "rejects stale resourceVersion",
+			agentName:  "my-agent",
+			body:       "metadata:\n  resourceVersion: \"1\"\nspec:\n  workspaceDir: /work\n  serviceAccountName: sa\n",
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "handles not found",
+			agentName:  "missing",
+			body:       "spec:\n  workspaceDir: /work\n  serviceAccountName: sa\n",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k8sClient := fake.NewClientBuilder().
+				WithScheme(newTestScheme()).
+				WithObjects(existing()).
+				Build()
+			handler := NewAgentHandler(k8sClient)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPut, "/", bytes.NewBufferString(tt.body))
+			r.URL = &url.URL{Path: "/"}
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("namespace", "default")
+			rctx.URLParams.Add("name", tt.agentName)
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+			handler.Update(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+
+			var agent kubeopenv1alpha1.Agent
+			if err := k8sClient.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "my-agent"}, &agent); err != nil {
+				t.Fatalf("failed to get agent: %v", err)
+			}
+			wantDir := "/workspace"
+			if tt.wantStatus == http.StatusOK {
+				wantDir = "/work"
+			}
+			if agent.Spec.WorkspaceDir != wantDir {
+				t.Errorf("expected workspaceDir %q, got %q", wantDir, agent.Spec.WorkspaceDir)
+			}
+		})
+	}
+}
+
+func TestAgentHandler_Delete(t *testing.T) {
+	tests := []struct {
+		name       string
+		agentName  string
+		funcs      interceptor.Funcs
+		wantStatus int
+	}{
+		{
+			name:       "deletes agent",
+			agentName:  "my-agent",
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "handles not found",
+			agentName:  "missing",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:      "reports RBAC denial as forbidden",
+			agentName: "my-agent",
+			funcs: interceptor.Funcs{
+				Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+					return apierrors.NewForbidden(kubeopenv1alpha1.SchemeGroupVersion.WithResource("agents").GroupResource(), obj.GetName(), nil)
+				},
+			},
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k8sClient := fake.NewClientBuilder().
+				WithScheme(newTestScheme()).
+				WithObjects(&kubeopenv1alpha1.Agent{
+					ObjectMeta: metav1.ObjectMeta{Name: "my-agent", Namespace: "default"},
+				}).
+				WithInterceptorFuncs(tt.funcs).
+				Build()
+			handler := NewAgentHandler(k8sClient)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodDelete, "/", nil)
+			r.URL = &url.URL{Path: "/"}
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("namespace", "default")
+			rctx.URLParams.Add("name", tt.agentName)
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+			handler.Delete(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
 func TestAgentHandler_Suspend(t *testing.T) {
 	tests := []struct {
 		name       string
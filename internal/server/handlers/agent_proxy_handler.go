@@ -3,7 +3,6 @@
 package handlers
 
 import (
-	"context"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
@@ -34,10 +33,9 @@ func (h *AgentProxyHandler) ServeProxy(w http.ResponseWriter, r *http.Request) {
 	namespace := chi.URLParam(r, "namespace")
 	agentName := chi.URLParam(r, "name")
 
-	// Detach from chi's timeout context (60s) to support long-lived SSE streams.
-	// context.WithoutCancel preserves values but does not inherit cancellation.
-	// The proxy will still terminate when the client disconnects (write errors).
-	ctx := context.WithoutCancel(r.Context())
+	// The proxy route is exempt from the request timeout to support long-lived SSE
+	// streams; the proxied request is cancelled when the client disconnects.
+	ctx := r.Context()
 
 	k8sClient := clientFromContext(ctx, h.defaultClient)
 
@@ -55,12 +53,24 @@ func (h *AgentProxyHandler) ServeProxy(w http.ResponseWriter, r *http.Request) {
 	}
 
 	proxyPath := normalizeProxyPath(r)
+	proxy := newAgentReverseProxy(target, proxyPath, func(w http.ResponseWriter, r *http.Request, err error) {
+		proxyLog.Error(err, "Proxy error", "namespace", namespace, "agent", agentName, "path", proxyPath)
+		writeError(w, http.StatusBadGateway, "Proxy error", err.Error())
+	})
 
-	proxy := &httputil.ReverseProxy{
+	proxyLog.V(1).Info("Proxying request", "namespace", namespace, "agent", agentName, "path", proxyPath, "method", r.Method)
+	proxy.ServeHTTP(w, r)
+}
+
+// newAgentReverseProxy returns a reverse proxy that sends every request to proxyPath on target,
+// keeping the query string. Connection upgrades (WebSocket) are passed through by httputil.ReverseProxy.
+func newAgentReverseProxy(target *url.URL, proxyPath string, errorHandler func(http.ResponseWriter, *http.Request, error)) *httputil.ReverseProxy {
+	return &httputil.ReverseProxy{
 		Director: func(req *http.Request) {
 			req.URL.Scheme = target.Scheme
 			req.URL.Host = target.Host
 			req.URL.Path = proxyPath
+			req.URL.RawPath = ""
 			req.Host = target.Host
 			// Remove Authorization header — internal traffic does not need external auth
 			req.Header.Del("Authorization")
@@ -68,12 +78,6 @@ func (h *AgentProxyHandler) ServeProxy(w http.ResponseWriter, r *http.Request) {
 		// FlushInterval -1 means flush immediately after each write,
 		// which is critical for SSE streaming.
 		FlushInterval: -1,
-		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
-			proxyLog.Error(err, "Proxy error", "namespace", namespace, "agent", agentName, "path", proxyPath)
-			writeError(w, http.StatusBadGateway, "Proxy error", err.Error())
-		},
+		ErrorHandler:  errorHandler,
 	}
-
-	proxyLog.V(1).Info("Proxying request", "namespace", namespace, "agent", agentName, "path", proxyPath, "method", r.Method)
-	proxy.ServeHTTP(w, r.WithContext(ctx))
 }
@@ -3,8 +3,14 @@
 package handlers
 
 import (
+	"bufio"
 	"context"
+	"io"
+	"net"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/go-chi/chi/v5"
@@ -55,6 +61,74 @@ func TestNormalizeProxyPath(t *testing.T) {
 	}
 }
 
+func TestNewAgentReverseProxy(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") == "websocket" {
+			conn, buf, err := http.NewResponseController(w).Hijack()
+			if err != nil {
+				t.Errorf("hijack failed: %v", err)
+				return
+			}
+			defer func() { _ = conn.Close() }()
+			_, _ = buf.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: websocket\r\n\r\n")
+			_ = buf.Flush()
+			// Echo one line back over the upgraded connection
+			line, _ := buf.ReadString('\n')
+			_, _ = conn.Write([]byte("echo:" + line))
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, "path="+r.URL.Path+" query="+r.URL.RawQuery+" auth="+r.Header.Get("Authorization"))
+	}))
+	defer backend.Close()
+
+	target, _ := url.Parse(backend.URL)
+	proxy := httptest.NewServer(newAgentReverseProxy(target, "/event", func(w http.ResponseWriter, _ *http.Request, err error) {
+		t.Errorf("unexpected proxy error: %v", err)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer proxy.Close()
+
+	t.Run("forwards path and query without Authorization", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, proxy.URL+"/api/v1/namespaces/default/agents/a/proxy/event?directory=%2Fworkspace", nil)
+		req.Header.Set("Authorization", "Bearer user-token")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		body, _ := io.ReadAll(resp.Body)
+		if got, want := string(body), "path=/event query=directory=%2Fworkspace auth="; got != want {
+			t.Errorf("body = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("passes WebSocket upgrades through", func(t *testing.T) {
+		conn, err := net.Dial("tcp", strings.TrimPrefix(proxy.URL, "http://"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = conn.Close() }()
+		_, _ = io.WriteString(conn, "GET /pty HTTP/1.1\r\nHost: proxy\r\nConnection: Upgrade\r\nUpgrade: websocket\r\n\r\n")
+		reader := bufio.NewReader(conn)
+		resp, err := http.ReadResponse(reader, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != http.StatusSwitchingProtocols {
+			t.Fatalf("status = %d, want 101", resp.StatusCode)
+		}
+		_, _ = io.WriteString(conn, "hello\n")
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if line != "echo:hello\n" {
+			t.Errorf("got %q, want %q", line, "echo:hello\n")
+		}
+	})
+}
+
 // newProxyTestRequest creates a request with a chi route context containing the wildcard parameter.
 func newProxyTestRequest(wildcard string) *http.Request {
 	r, _ := http.NewRequest(http.MethodGet, "/", nil)
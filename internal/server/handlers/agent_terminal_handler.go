@@ -140,10 +140,9 @@ func (h *AgentTerminalHandler) ServeTerminal(w http.ResponseWriter, r *http.Requ
 	// Mutex to serialize all WebSocket writes (gorilla/websocket requires this)
 	var wsMu sync.Mutex
 
-	// Detach from chi's 60s timeout for long-lived connection.
 	// Use a separate cancellable context so we can stop the exec stream and heartbeat
-	// when the WebSocket disconnects.
-	sessionCtx, sessionCancel := context.WithCancel(context.WithoutCancel(r.Context()))
+	// when the WebSocket disconnects. The route is exempt from the request timeout.
+	sessionCtx, sessionCancel := context.WithCancel(r.Context())
 	defer sessionCancel()
 
 	// Start connection heartbeat to prevent standby auto-suspend while terminal is active.
@@ -152,6 +151,17 @@ func (h *AgentTerminalHandler) ServeTerminal(w http.ResponseWriter, r *http.Requ
 		termLog.Error(err, "heartbeat: failed to patch annotation", "agent", agentName)
 	})
 
+	attachURL := fmt.Sprintf("http://localhost:%d", port)
+	runTerminalExec(sessionCtx, sessionCancel, ws, &wsMu, execConfig, namespace, podName, containerName,
+		[]string{"/tools/opencode", "attach", attachURL}, "agent", agentName)
+}
+
+// runTerminalExec bridges an upgraded WebSocket to an interactive TTY exec session
+// running command in the given container. Binary messages are stdin; text messages
+// are resize control messages. Transient exec failures are retried.
+// execConfig should impersonate the user so that the API server checks their pods/exec
+// permission. targetKind and targetName identify the session in logs.
+func runTerminalExec(sessionCtx context.Context, sessionCancel context.CancelFunc, ws *websocket.Conn, wsMu *sync.Mutex, execConfig *rest.Config, namespace, podName, containerName string, command []string, targetKind, targetName string) {
 	// Build the exec clientset using impersonated config
 	execClientset, err := kubernetes.NewForConfig(execConfig)
 	if err != nil {
@@ -205,8 +215,7 @@ func (h *AgentTerminalHandler) ServeTerminal(w http.ResponseWriter, r *http.Requ
 		}
 	}()
 
-	wsWriter := &wsStdoutWriter{ws: ws, mu: &wsMu}
-	attachURL := fmt.Sprintf("http://localhost:%d", port)
+	wsWriter := &wsStdoutWriter{ws: ws, mu: wsMu}
 
 	// Retry loop for transient exec failures (e.g., exit code 137 after agent resume)
 	var lastErr error
@@ -222,7 +231,7 @@ func (h *AgentTerminalHandler) ServeTerminal(w http.ResponseWriter, r *http.Requ
 			SubResource("exec").
 			VersionedParams(&corev1.PodExecOptions{
 				Container: containerName,
-				Command:   []string{"/tools/opencode", "attach", attachURL},
+				Command:   command,
 				Stdin:     true,
 				Stdout:    true,
 				TTY:       true,
@@ -286,7 +295,7 @@ func (h *AgentTerminalHandler) ServeTerminal(w http.ResponseWriter, r *http.Requ
 		}
 
 		termLog.Info("transient exec failure, retrying",
-			"attempt", attempt, "error", lastErr, "agent", agentName)
+			"attempt", attempt, "error", lastErr, targetKind, targetName)
 		wsMu.Lock()
 		retryMsg := fmt.Sprintf("\r\n\x1b[33mConnection interrupted, retrying (%d/%d)...\x1b[0m\r\n",
 			attempt, maxExecRetries)
@@ -300,7 +309,7 @@ func (h *AgentTerminalHandler) ServeTerminal(w http.ResponseWriter, r *http.Requ
 	}
 
 	if lastErr != nil {
-		termLog.Info("exec session ended", "error", lastErr, "agent", agentName)
+		termLog.Info("exec session ended", "error", lastErr, targetKind, targetName)
 		errMsg := fmt.Sprintf("\r\n\x1b[31mError: %s\x1b[0m\r\n", lastErr.Error())
 		wsMu.Lock()
 		_ = ws.WriteMessage(websocket.BinaryMessage, []byte(errMsg))
@@ -55,7 +55,7 @@ func (h *AgentTemplateHandler) ListAll(w http.ResponseWriter, r *http.Request) {
 	// Filter by name (in-memory)
 	var filteredItems []kubeopenv1alpha1.AgentTemplate
 	for _, tmpl := range tmplList.Items {
-		if MatchesNameFilter(tmpl.Name, filterOpts.Name) {
+		if MatchesNameFilter(tmpl.Name, filterOpts.Name) && namespaceAllowed(ctx, tmpl.Namespace) {
 			filteredItems = append(filteredItems, tmpl)
 		}
 	}
@@ -80,6 +80,10 @@ func (h *AgentTemplateHandler) ListAll(w http.ResponseWriter, r *http.Request) {
 	// Count referencing agents for each template
 	agentCounts := h.countReferencingAgents(ctx, k8sClient, paginatedItems)
 
+	if notModified(w, r, listETag(filteredItems)) {
+		return
+	}
+
 	response := types.AgentTemplateListResponse{
 		Templates: make([]types.AgentTemplateResponse, 0, len(paginatedItems)),
 		Total:     totalCount,
@@ -123,7 +127,7 @@ func (h *AgentTemplateHandler) List(w http.ResponseWriter, r *http.Request) {
 	// Filter by name (in-memory)
 	var filteredItems []kubeopenv1alpha1.AgentTemplate
 	for _, tmpl := range tmplList.Items {
-		if MatchesNameFilter(tmpl.Name, filterOpts.Name) {
+		if MatchesNameFilter(tmpl.Name, filterOpts.Name) && namespaceAllowed(ctx, tmpl.Namespace) {
 			filteredItems = append(filteredItems, tmpl)
 		}
 	}
@@ -148,6 +152,10 @@ func (h *AgentTemplateHandler) List(w http.ResponseWriter, r *http.Request) {
 	// Count referencing agents for each template
 	agentCounts := h.countReferencingAgents(ctx, k8sClient, paginatedItems)
 
+	if notModified(w, r, listETag(filteredItems)) {
+		return
+	}
+
 	response := types.AgentTemplateListResponse{
 		Templates: make([]types.AgentTemplateResponse, 0, len(paginatedItems)),
 		Total:     totalCount,
@@ -326,6 +334,72 @@ func templateToResponse(tmpl *kubeopenv1alpha1.AgentTemplate) types.AgentTemplat
 	}
 }
 
+// Render previews the effective spec of a Task referencing this template without
+// creating it: the description and Text contexts rendered with the params, and the
+// template's spec the Task runs with. The optional body is a Task manifest (YAML or
+// JSON) with the description, params and contexts; its agentRef and templateRef are ignored.
+func (h *AgentTemplateHandler) Render(w http.ResponseWriter, r *http.Request) {
+	namespace := chi.URLParam(r, "namespace")
+	name := chi.URLParam(r, "name")
+	ctx := r.Context()
+	k8sClient := h.getClient(ctx)
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20) // 1 MiB limit
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Failed to read request body", err.Error())
+		return
+	}
+
+	var task kubeopenv1alpha1.Task
+	if err := yaml.Unmarshal(body, &task); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid YAML", err.Error())
+		return
+	}
+
+	var tmpl kubeopenv1alpha1.AgentTemplate
+	if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, &tmpl); err != nil {
+		if apierrors.IsNotFound(err) {
+			writeError(w, http.StatusNotFound, "AgentTemplate not found", err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to get agent template", err.Error())
+		return
+	}
+
+	task.Namespace = namespace
+	if task.Name == "" {
+		// The API server generates the name when the Task is created
+		task.Name = task.GenerateName + "<generated>"
+	}
+	task.Spec.AgentRef = nil
+	task.Spec.TemplateRef = &kubeopenv1alpha1.AgentTemplateReference{Name: name}
+	taskSpec, agentSpec, err := controller.EffectiveTaskSpec(&task, &kubeopenv1alpha1.Agent{}, &tmpl)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Failed to render task", err.Error())
+		return
+	}
+
+	// Same checks as the Task controller before it starts the Task
+	var warnings []string
+	if agentSpec.WorkspaceDir == "" {
+		warnings = append(warnings, "workspaceDir is empty in the template")
+	}
+	if agentSpec.ServiceAccountName == "" {
+		warnings = append(warnings, "serviceAccountName is empty in the template")
+	}
+	if err := controller.ValidateTaskContexts(&task, agentSpec.WorkspaceDir); err != nil {
+		warnings = append(warnings, err.Error())
+	}
+
+	writeJSON(w, http.StatusOK, types.RenderAgentTemplateResponse{
+		Template: name,
+		Task:     taskSpec,
+		Agent:    agentSpec,
+		Warnings: warnings,
+	})
+}
+
 // countReferencingAgents counts agents referencing each template using a single
 // list call per unique namespace (instead of one call per template).
 func (h *AgentTemplateHandler) countReferencingAgents(ctx context.Context, k8sClient client.Client, templates []kubeopenv1alpha1.AgentTemplate) []int {
@@ -288,3 +288,133 @@ func TestAgentTemplateHandler_Delete(t *testing.T) {
 		})
 	}
 }
+
+func TestAgentTemplateHandler_Render(t *testing.T) {
+	tmpl := &kubeopenv1alpha1.AgentTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "team-config", Namespace: "default"},
+		Spec: kubeopenv1alpha1.AgentTemplateSpec{
+			AgentImage:         "custom-agent:v1",
+			WorkspaceDir:       "/workspace",
+			ServiceAccountName: "team-sa",
+			Contexts: []kubeopenv1alpha1.ContextItem{
+				{Name: "standards", Type: kubeopenv1alpha1.ContextTypeText, Text: "Review ${params.repo}"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name            string
+		tmplName        string
+		body            string
+		wantStatus      int
+		wantDescription string
+		wantContexts    int
+		wantWarnings    int
+	}{
+		{
+			name:     "renders task with params",
+			tmplName: "team-config",
+			body: `spec:
+  description: "Review PR ${params.pr} of {{ .params.repo }}"
+  params:
+    repo: org/app
+    pr: "123"
+  contexts:
+    - name: notes
+      type: Text
+      text: "Task ${task.name}"
+`,
+			wantStatus:      http.StatusOK,
+			wantDescription: "Review PR 123 of org/app",
+			wantContexts:    1,
+		},
+		{
+			name:         "context problems are reported as warnings",
+			tmplName:     "team-config",
+			body:         "spec:\n  params:\n    repo: org/app\n  contexts:\n    - type: Text\n      text: a\n      mountPath: ../a.md\n",
+			wantStatus:   http.StatusOK,
+			wantContexts: 1,
+			wantWarnings: 1,
+		},
+		{
+			name:       "undefined param",
+			tmplName:   "team-config",
+			body:       "spec:\n  description: Review ${params.pr}\n",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid body",
+			tmplName:   "team-config",
+			body:       "spec: [",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "template not found",
+			tmplName:   "missing",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k8sClient := fake.NewClientBuilder().
+				WithScheme(newTestScheme()).
+				WithObjects(tmpl.DeepCopy()).
+				Build()
+			handler := NewAgentTemplateHandler(k8sClient)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(tt.body))
+			r.URL = &url.URL{Path: "/"}
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("namespace", "default")
+			rctx.URLParams.Add("name", tt.tmplName)
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+			handler.Render(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var resp types.RenderAgentTemplateResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.Task.TemplateRef == nil || resp.Task.TemplateRef.Name != "team-config" {
+				t.Errorf("expected templateRef team-config, got %v", resp.Task.TemplateRef)
+			}
+			if resp.Task.Description == nil || *resp.Task.Description != tt.wantDescription {
+				t.Errorf("expected description %q, got %v", tt.wantDescription, resp.Task.Description)
+			}
+			if len(resp.Task.Contexts) != tt.wantContexts {
+				t.Errorf("expected %d task contexts, got %d", tt.wantContexts, len(resp.Task.Contexts))
+			}
+			if tt.wantContexts > 0 && tt.wantWarnings == 0 && resp.Task.Contexts[0].Text != "Task <generated>" {
+				t.Errorf("expected rendered task context, got %q", resp.Task.Contexts[0].Text)
+			}
+			if resp.Agent.AgentImage != "custom-agent:v1" {
+				t.Errorf("expected agentImage from template, got %q", resp.Agent.AgentImage)
+			}
+			if len(resp.Agent.Contexts) != 1 || resp.Agent.Contexts[0].Text != "Review org/app" {
+				t.Errorf("expected rendered template context, got %v", resp.Agent.Contexts)
+			}
+			if len(resp.Warnings) != tt.wantWarnings {
+				t.Errorf("expected %d warnings, got %v", tt.wantWarnings, resp.Warnings)
+			}
+
+			// Nothing is created
+			var tasks kubeopenv1alpha1.TaskList
+			if err := k8sClient.List(context.Background(), &tasks); err != nil {
+				t.Fatal(err)
+			}
+			if len(tasks.Items) != 0 {
+				t.Errorf("expected no Tasks to be created, got %d", len(tasks.Items))
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+	"github.com/kubeopencode/kubeopencode/internal/server/types"
+)
+
+const (
+	// ApplyFieldManager is the field manager of objects applied through the API
+	ApplyFieldManager = "kubeopencode-server"
+
+	// maxApplyBodySize bounds the size of applied manifests
+	maxApplyBodySize = 2 << 20
+)
+
+// applyKinds are the namespaced KubeOpenCode kinds accepted by the apply endpoint.
+// KubeOpenCodeConfig is edited through /config.
+var applyKinds = map[string]bool{
+	"Task":          true,
+	"CronTask":      true,
+	"Agent":         true,
+	"AgentTemplate": true,
+	"Registry":      true,
+}
+
+// ApplyHandler applies KubeOpenCode manifests with server-side apply
+type ApplyHandler struct {
+	defaultClient client.Client
+}
+
+// NewApplyHandler creates a new ApplyHandler
+func NewApplyHandler(c client.Client) *ApplyHandler {
+	return &ApplyHandler{defaultClient: c}
+}
+
+func (h *ApplyHandler) getClient(ctx context.Context) client.Client {
+	return clientFromContext(ctx, h.defaultClient)
+}
+
+// Apply server-side applies the YAML or JSON manifests in the body (multiple YAML
+// documents and kind List are supported) with the impersonated client.
+// Objects without a namespace go to the namespace query parameter (default "default").
+// dryRun=true validates without persisting, force=true takes over conflicting fields.
+// Manifests are checked before anything is applied; the response holds one result
+// per object and has status 207 if some objects failed to apply.
+func (h *ApplyHandler) Apply(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	k8sClient := h.getClient(ctx)
+
+	query := r.URL.Query()
+	namespace := query.Get("namespace")
+	if namespace == "" {
+		namespace = "default"
+	}
+	var opts []client.ApplyOption
+	opts = append(opts, client.FieldOwner(ApplyFieldManager))
+	for param, opt := range map[string]client.ApplyOption{"dryRun": client.DryRunAll, "force": client.ForceOwnership} {
+		if v := query.Get(param); v != "" {
+			enabled, err := strconv.ParseBool(v)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "Invalid "+param, err.Error())
+				return
+			}
+			if enabled {
+				opts = append(opts, opt)
+			}
+		}
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxApplyBodySize)
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Failed to read request body", err.Error())
+		return
+	}
+	objects, err := decodeManifests(body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid manifests", err.Error())
+		return
+	}
+	if len(objects) == 0 {
+		writeError(w, http.StatusBadRequest, "No objects to apply", "")
+		return
+	}
+
+	resp := types.ApplyResponse{Results: make([]types.ApplyResult, len(objects))}
+	valid := true
+	for i, obj := range objects {
+		if obj.GetNamespace() == "" {
+			obj.SetNamespace(namespace)
+		}
+		resp.Results[i] = types.ApplyResult{
+			APIVersion: obj.GetAPIVersion(),
+			Kind:       obj.GetKind(),
+			Namespace:  obj.GetNamespace(),
+			Name:       obj.GetName(),
+		}
+		if err := validateApplyObject(obj); err != nil {
+			resp.Results[i].Error = err.Error()
+			valid = false
+		} else if !namespaceAllowed(ctx, obj.GetNamespace()) {
+			resp.Results[i].Error = fmt.Sprintf("namespace %q is outside the namespaces served by this server", obj.GetNamespace())
+			valid = false
+		}
+	}
+	if !valid {
+		writeJSON(w, http.StatusBadRequest, resp)
+		return
+	}
+
+	status := http.StatusOK
+	for i, obj := range objects {
+		if err := k8sClient.Apply(ctx, client.ApplyConfigurationFromUnstructured(obj), opts...); err != nil {
+			resp.Results[i].Error = err.Error()
+			resp.Results[i].Conflict = apierrors.IsConflict(err)
+			resp.Failed++
+			status = http.StatusMultiStatus
+			continue
+		}
+		resp.Results[i].Applied = true
+	}
+	writeJSON(w, status, resp)
+}
+
+// decodeManifests decodes YAML documents or JSON objects, expanding kind List.
+// Server-populated metadata and status are dropped, so that objects copied from
+// kubectl get -o yaml can be applied as they are.
+func decodeManifests(body []byte) ([]*unstructured.Unstructured, error) {
+	var objects []*unstructured.Unstructured
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(body), 4096)
+	for {
+		var doc map[string]interface{}
+		if err := decoder.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				return objects, nil
+			}
+			return nil, err
+		}
+		if len(doc) == 0 {
+			continue
+		}
+		obj := &unstructured.Unstructured{Object: doc}
+		if !obj.IsList() {
+			objects = append(objects, obj)
+			continue
+		}
+		list, err := obj.ToList()
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			objects = append(objects, &list.Items[i])
+		}
+	}
+}
+
+// validateApplyObject checks that obj is a supported KubeOpenCode object and
+// removes the fields the API server populates.
+func validateApplyObject(obj *unstructured.Unstructured) error {
+	if obj.GetAPIVersion() != kubeopenv1alpha1.GroupVersion.String() || !applyKinds[obj.GetKind()] {
+		return fmt.Errorf("unsupported object %s %s; only %s Task, CronTask, Agent, AgentTemplate and Registry can be applied",
+			obj.GetAPIVersion(), obj.GetKind(), kubeopenv1alpha1.GroupVersion.String())
+	}
+	if obj.GetName() == "" {
+		return fmt.Errorf("metadata.name is required")
+	}
+	for _, field := range []string{"resourceVersion", "uid", "creationTimestamp", "generation", "managedFields"} {
+		unstructured.RemoveNestedField(obj.Object, "metadata", field)
+	}
+	unstructured.RemoveNestedField(obj.Object, "status")
+	return nil
+}
@@ -0,0 +1,160 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+	"github.com/kubeopencode/kubeopencode/internal/server/types"
+)
+
+const applyManifests = `apiVersion: kubeopencode.io/v1alpha1
+kind: Agent
+metadata:
+  name: coder
+  resourceVersion: "42"
+spec:
+  workspaceDir: /workspace
+  serviceAccountName: sa
+status:
+  ready: true
+---
+apiVersion: kubeopencode.io/v1alpha1
+kind: Task
+metadata:
+  name: fix-bug
+  namespace: team-b
+spec:
+  description: Fix the bug
+  agentRef:
+    name: coder
+`
+
+func TestApplyHandler_Apply(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		body        string
+		wantStatus  int
+		wantResults int
+		wantStored  bool
+		funcs       interceptor.Funcs
+	}{
+		{
+			name:        "applies multiple documents",
+			query:       "namespace=team-a",
+			body:        applyManifests,
+			wantStatus:  http.StatusOK,
+			wantResults: 2,
+			wantStored:  true,
+		},
+		{
+			name:        "applies a JSON List",
+			query:       "namespace=team-a",
+			body:        `{"apiVersion":"v1","kind":"List","items":[{"apiVersion":"kubeopencode.io/v1alpha1","kind":"Agent","metadata":{"name":"coder"},"spec":{"workspaceDir":"/workspace","serviceAccountName":"sa"}}]}`,
+			wantStatus:  http.StatusOK,
+			wantResults: 1,
+			wantStored:  true,
+		},
+		{
+			name:        "dry run does not persist",
+			query:       "namespace=team-a&dryRun=true",
+			body:        applyManifests,
+			wantStatus:  http.StatusOK,
+			wantResults: 2,
+			// The fake client ignores dry run, so only check that it is requested
+			funcs: interceptor.Funcs{
+				Apply: func(ctx context.Context, c client.WithWatch, obj runtime.ApplyConfiguration, opts ...client.ApplyOption) error {
+					applyOpts := &client.ApplyOptions{}
+					applyOpts.ApplyOptions(opts)
+					if len(applyOpts.DryRun) == 0 || applyOpts.FieldManager != ApplyFieldManager {
+						return c.Apply(ctx, obj, opts...)
+					}
+					return nil
+				},
+			},
+		},
+		{
+			name:        "reports conflicts per object",
+			query:       "namespace=team-a",
+			body:        applyManifests,
+			wantStatus:  http.StatusMultiStatus,
+			wantResults: 2,
+			funcs: interceptor.Funcs{
+				Apply: func(ctx context.Context, c client.WithWatch, obj runtime.ApplyConfiguration, opts ...client.ApplyOption) error {
+					return apierrors.NewConflict(kubeopenv1alpha1.GroupVersion.WithResource("agents").GroupResource(), "coder", nil)
+				},
+			},
+		},
+		{
+			name:        "rejects unsupported kinds before applying",
+			query:       "namespace=team-a",
+			body:        applyManifests + "---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm\n",
+			wantStatus:  http.StatusBadRequest,
+			wantResults: 3,
+		},
+		{
+			name:       "rejects invalid YAML",
+			body:       "kind: [",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "rejects empty body",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k8sClient := fake.NewClientBuilder().WithScheme(newTestScheme()).WithInterceptorFuncs(tt.funcs).Build()
+			handler := NewApplyHandler(k8sClient)
+
+			w := httptest.NewRecorder()
+			handler.Apply(w, httptest.NewRequest(http.MethodPost, "/?"+tt.query, strings.NewReader(tt.body)))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantResults > 0 {
+				var resp types.ApplyResponse
+				if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if len(resp.Results) != tt.wantResults {
+					t.Fatalf("expected %d results, got %+v", tt.wantResults, resp.Results)
+				}
+				if resp.Results[0].Namespace != "team-a" {
+					t.Errorf("expected default namespace team-a, got %q", resp.Results[0].Namespace)
+				}
+				if tt.wantStatus == http.StatusMultiStatus && (resp.Failed != tt.wantResults || !resp.Results[0].Conflict) {
+					t.Errorf("expected all objects to fail with a conflict, got %+v", resp)
+				}
+			}
+
+			var agent kubeopenv1alpha1.Agent
+			err := k8sClient.Get(context.Background(), client.ObjectKey{Namespace: "team-a", Name: "coder"}, &agent)
+			if tt.wantStored {
+				if err != nil {
+					t.Fatalf("expected Agent to be applied: %v", err)
+				}
+				if agent.Spec.WorkspaceDir != "/workspace" {
+					t.Errorf("expected workspaceDir /workspace, got %q", agent.Spec.WorkspaceDir)
+				}
+			} else if err == nil {
+				t.Error("expected nothing to be applied")
+			}
+		})
+	}
+}
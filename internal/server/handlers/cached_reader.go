@@ -0,0 +1,33 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package handlers
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CachedReaderContextKey is the context key for the request's CachedReader.
+type CachedReaderContextKey struct{}
+
+// CachedReader gives handlers access to the server's informer cache on behalf of the
+// requesting user. The cache is read with the server's permissions, so it is only
+// handed out after checking that the user may list the resource.
+type CachedReader interface {
+	// ReaderFor returns the cache-backed reader if the user may list resource
+	// (e.g. "tasks") in namespace ("" for all namespaces), or nil otherwise.
+	ReaderFor(ctx context.Context, resource, namespace string) client.Reader
+}
+
+// listReader returns the reader to list resource in namespace: the informer cache when
+// the request's user may list it, or the request's (impersonated) live client.
+// The second result reports whether the cache is used.
+func listReader(ctx context.Context, liveClient client.Client, resource, namespace string) (client.Reader, bool) {
+	if cr, ok := ctx.Value(CachedReaderContextKey{}).(CachedReader); ok && cr != nil {
+		if reader := cr.ReaderFor(ctx, resource, namespace); reader != nil {
+			return reader, true
+		}
+	}
+	return liveClient, false
+}
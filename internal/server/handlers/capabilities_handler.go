@@ -0,0 +1,112 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/go-chi/chi/v5"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+	"github.com/kubeopencode/kubeopencode/internal/server/types"
+)
+
+// capabilityChecks lists the verbs checked per resource, covering the actions of the UI.
+// Resources without a group are core resources; subresources are written as "pods/log".
+var capabilityChecks = []struct {
+	group    string
+	resource string
+	verbs    []string
+}{
+	{kubeopenv1alpha1.GroupName, "tasks", []string{"list", "create", "update", "patch", "delete"}},
+	{kubeopenv1alpha1.GroupName, "crontasks", []string{"list", "create", "update", "delete"}},
+	{kubeopenv1alpha1.GroupName, "agents", []string{"list", "create", "update", "delete"}},
+	{kubeopenv1alpha1.GroupName, "agenttemplates", []string{"list", "create", "update", "delete"}},
+	{kubeopenv1alpha1.GroupName, "registries", []string{"list", "create", "update", "delete"}},
+	{"", "pods/log", []string{"get"}},
+	{"", "pods/exec", []string{"create"}},
+	{"", "configmaps", []string{"get"}},
+}
+
+// CapabilitiesHandler reports which actions the current user may perform
+type CapabilitiesHandler struct {
+	defaultClientset kubernetes.Interface
+}
+
+// NewCapabilitiesHandler creates a new CapabilitiesHandler
+func NewCapabilitiesHandler(clientset kubernetes.Interface) *CapabilitiesHandler {
+	return &CapabilitiesHandler{defaultClientset: clientset}
+}
+
+// Get runs a SelfSubjectAccessReview per resource and verb with the impersonated
+// clientset, so the reviews are evaluated for the requesting user, and returns
+// the decisions keyed by resource and verb.
+func (h *CapabilitiesHandler) Get(w http.ResponseWriter, r *http.Request) {
+	namespace := chi.URLParam(r, "namespace")
+	ctx := r.Context()
+	clientset := clientsetFromContext(ctx, h.defaultClientset)
+
+	resp := types.CapabilitiesResponse{
+		Namespace: namespace,
+		Resources: map[string]map[string]bool{},
+	}
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+	)
+	for _, check := range capabilityChecks {
+		resp.Resources[check.resource] = map[string]bool{}
+		for _, verb := range check.verbs {
+			wg.Add(1)
+			go func(group, resource, verb string) {
+				defer wg.Done()
+				allowed, err := selfAccessAllowed(ctx, clientset, namespace, group, resource, verb)
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					if firstErr == nil {
+						firstErr = err
+					}
+					return
+				}
+				resp.Resources[resource][verb] = allowed
+			}(check.group, check.resource, verb)
+		}
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to check permissions", firstErr.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// selfAccessAllowed reports whether the clientset's user may perform verb on resource
+// (optionally "resource/subresource") in namespace.
+func selfAccessAllowed(ctx context.Context, clientset kubernetes.Interface, namespace, group, resource, verb string) (bool, error) {
+	resource, subresource, _ := strings.Cut(resource, "/")
+	review := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace:   namespace,
+				Verb:        verb,
+				Group:       group,
+				Resource:    resource,
+				Subresource: subresource,
+			},
+		},
+	}
+	result, err := clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		return false, err
+	}
+	return result.Status.Allowed, nil
+}
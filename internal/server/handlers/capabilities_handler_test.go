@@ -0,0 +1,84 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	kubetesting "k8s.io/client-go/testing"
+
+	"github.com/kubeopencode/kubeopencode/internal/server/types"
+)
+
+func TestCapabilitiesHandler_Get(t *testing.T) {
+	// The user may only read Tasks and their logs in team-a
+	readOnly := func(action kubetesting.Action) (bool, runtime.Object, error) {
+		review := action.(kubetesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		attrs := review.Spec.ResourceAttributes
+		review.Status.Allowed = attrs.Namespace == "team-a" &&
+			((attrs.Resource == "tasks" && attrs.Verb == "list") ||
+				(attrs.Resource == "pods" && attrs.Subresource == "log" && attrs.Verb == "get"))
+		return true, review, nil
+	}
+	failing := func(action kubetesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("authorization unavailable")
+	}
+
+	tests := []struct {
+		name       string
+		reactor    kubetesting.ReactionFunc
+		wantStatus int
+	}{
+		{name: "reports allowed and denied verbs", reactor: readOnly, wantStatus: http.StatusOK},
+		{name: "review failure", reactor: failing, wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientset := k8sfake.NewSimpleClientset()
+			clientset.PrependReactor("create", "selfsubjectaccessreviews", tt.reactor)
+			handler := NewCapabilitiesHandler(clientset)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("namespace", "team-a")
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+			handler.Get(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var resp types.CapabilitiesResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.Namespace != "team-a" {
+				t.Errorf("expected namespace team-a, got %q", resp.Namespace)
+			}
+			if !resp.Resources["tasks"]["list"] || !resp.Resources["pods/log"]["get"] {
+				t.Errorf("expected task list and log access, got %v", resp.Resources)
+			}
+			if allowed, ok := resp.Resources["tasks"]["create"]; !ok || allowed {
+				t.Errorf("expected tasks create to be reported as denied, got %v", resp.Resources["tasks"])
+			}
+			if allowed, ok := resp.Resources["agents"]["delete"]; !ok || allowed {
+				t.Errorf("expected agents delete to be reported as denied, got %v", resp.Resources["agents"])
+			}
+		})
+	}
+}
@@ -109,8 +109,13 @@ func normalizeProxyPath(r *http.Request) string {
 	return path
 }
 
-// writeResourceOutput writes a Kubernetes resource as JSON or YAML depending on the output query parameter
+// writeResourceOutput writes a Kubernetes resource as JSON or YAML depending on the output query parameter.
+// The response carries an ETag from the resourceVersion, and GET requests with a matching
+// If-None-Match get a 304 instead.
 func writeResourceOutput(w http.ResponseWriter, r *http.Request, statusCode int, obj client.Object, jsonResponse interface{}) {
+	if notModified(w, r, resourceETag(obj)) {
+		return
+	}
 	output := r.URL.Query().Get("output")
 	if output == "yaml" {
 		data, err := json.Marshal(obj)
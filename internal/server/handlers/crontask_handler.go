@@ -66,7 +66,7 @@ func (h *CronTaskHandler) listCronTasks(w http.ResponseWriter, r *http.Request,
 	// Filter by name
 	var filteredItems []kubeopenv1alpha1.CronTask
 	for _, ct := range cronTaskList.Items {
-		if !MatchesNameFilter(ct.Name, filterOpts.Name) {
+		if !MatchesNameFilter(ct.Name, filterOpts.Name) || !namespaceAllowed(ctx, ct.Namespace) {
 			continue
 		}
 		filteredItems = append(filteredItems, ct)
@@ -85,6 +85,10 @@ func (h *CronTaskHandler) listCronTasks(w http.ResponseWriter, r *http.Request,
 	end := min(start+filterOpts.Limit, totalCount)
 	paginatedItems := filteredItems[start:end]
 
+	if notModified(w, r, listETag(filteredItems)) {
+		return
+	}
+
 	response := types.CronTaskListResponse{
 		CronTasks: make([]types.CronTaskResponse, 0, len(paginatedItems)),
 		Total:     totalCount,
@@ -408,6 +412,10 @@ func (h *CronTaskHandler) History(w http.ResponseWriter, r *http.Request) {
 	end := min(start+filterOpts.Limit, totalCount)
 	paginatedItems := items[start:end]
 
+	if notModified(w, r, listETag(items)) {
+		return
+	}
+
 	response := types.TaskListResponse{
 		Tasks: make([]types.TaskResponse, 0, len(paginatedItems)),
 		Total: totalCount,
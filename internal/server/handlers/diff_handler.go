@@ -0,0 +1,229 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+	"github.com/kubeopencode/kubeopencode/internal/server/types"
+)
+
+// DiffHandler compares two Tasks, e.g. a Task and its retry
+type DiffHandler struct {
+	defaultClient client.Client
+}
+
+// NewDiffHandler creates a new DiffHandler
+func NewDiffHandler(c client.Client) *DiffHandler {
+	return &DiffHandler{defaultClient: c}
+}
+
+func (h *DiffHandler) getClient(ctx context.Context) client.Client {
+	return clientFromContext(ctx, h.defaultClient)
+}
+
+// Tasks returns a structured diff of the specs, captured outputs and durations
+// of the Tasks given as a and b query parameters, each as "namespace/name".
+func (h *DiffHandler) Tasks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	k8sClient := h.getClient(ctx)
+
+	var keys [2]client.ObjectKey
+	for i, param := range []string{"a", "b"} {
+		key, err := parseTaskKey(r.URL.Query().Get(param))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid parameter %q", param), err.Error())
+			return
+		}
+		if !namespaceAllowed(ctx, key.Namespace) {
+			writeError(w, http.StatusForbidden, "Namespace not served", fmt.Sprintf("namespace %q is outside the namespaces served by this server", key.Namespace))
+			return
+		}
+		keys[i] = key
+	}
+
+	var tasks [2]kubeopenv1alpha1.Task
+	var outputs [2]map[string]string
+	for i, key := range keys {
+		if err := k8sClient.Get(ctx, key, &tasks[i]); err != nil {
+			if apierrors.IsNotFound(err) {
+				writeError(w, http.StatusNotFound, "Task not found", err.Error())
+			} else {
+				writeError(w, http.StatusInternalServerError, "Failed to get task", err.Error())
+			}
+			return
+		}
+		files, err := taskOutputFiles(ctx, k8sClient, &tasks[i])
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "Failed to get outputs", err.Error())
+			return
+		}
+		outputs[i] = files
+	}
+
+	resp, err := diffTasks(&tasks[0], &tasks[1], outputs[0], outputs[1])
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to compare tasks", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// parseTaskKey parses a "namespace/name" Task reference
+func parseTaskKey(value string) (client.ObjectKey, error) {
+	namespace, name, ok := strings.Cut(value, "/")
+	if !ok || namespace == "" || name == "" || strings.Contains(name, "/") {
+		return client.ObjectKey{}, fmt.Errorf("expected namespace/name, got %q", value)
+	}
+	return client.ObjectKey{Namespace: namespace, Name: name}, nil
+}
+
+// taskOutputFiles returns the captured output files of a task, or nil if it has none
+// or the outputs ConfigMap was already deleted.
+func taskOutputFiles(ctx context.Context, k8sClient client.Client, task *kubeopenv1alpha1.Task) (map[string]string, error) {
+	if task.Status.Outputs == nil {
+		return nil, nil
+	}
+	var cm corev1.ConfigMap
+	if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: task.Namespace, Name: task.Status.Outputs.ConfigMapName}, &cm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return cm.Data, nil
+}
+
+// diffTasks compares two Tasks and their output files
+func diffTasks(a, b *kubeopenv1alpha1.Task, outputsA, outputsB map[string]string) (*types.TaskDiffResponse, error) {
+	spec, err := diffSpecs(a.Spec, b.Spec)
+	if err != nil {
+		return nil, err
+	}
+	resp := &types.TaskDiffResponse{
+		A:       taskDiffSide(a),
+		B:       taskDiffSide(b),
+		Spec:    spec,
+		Outputs: diffOutputFiles(outputsA, outputsB),
+	}
+	if resp.A.DurationSeconds != nil && resp.B.DurationSeconds != nil {
+		delta := *resp.B.DurationSeconds - *resp.A.DurationSeconds
+		resp.DurationDeltaSeconds = &delta
+	}
+	return resp, nil
+}
+
+// taskDiffSide summarizes a Task; the duration is only set once it has finished
+func taskDiffSide(task *kubeopenv1alpha1.Task) types.TaskDiffSide {
+	side := types.TaskDiffSide{
+		Namespace: task.Namespace,
+		Name:      task.Name,
+		Phase:     string(task.Status.Phase),
+	}
+	if task.Status.StartTime != nil && task.Status.CompletionTime != nil {
+		seconds := task.Status.CompletionTime.Sub(task.Status.StartTime.Time).Seconds()
+		side.DurationSeconds = &seconds
+	}
+	return side
+}
+
+// diffSpecs flattens both specs to field paths and returns the fields that differ,
+// sorted by path
+func diffSpecs(a, b kubeopenv1alpha1.TaskSpec) ([]types.SpecFieldDiff, error) {
+	fieldsA, err := flattenSpec(a)
+	if err != nil {
+		return nil, err
+	}
+	fieldsB, err := flattenSpec(b)
+	if err != nil {
+		return nil, err
+	}
+
+	diffs := []types.SpecFieldDiff{}
+	for path, valueA := range fieldsA {
+		if valueB, ok := fieldsB[path]; !ok || !reflect.DeepEqual(valueA, valueB) {
+			diffs = append(diffs, types.SpecFieldDiff{Path: path, A: valueA, B: fieldsB[path]})
+		}
+	}
+	for path, valueB := range fieldsB {
+		if _, ok := fieldsA[path]; !ok {
+			diffs = append(diffs, types.SpecFieldDiff{Path: path, B: valueB})
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Path < diffs[j].Path })
+	return diffs, nil
+}
+
+// flattenSpec returns the leaf values of a spec's JSON form keyed by field path
+func flattenSpec(spec kubeopenv1alpha1.TaskSpec) (map[string]any, error) {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	fields := map[string]any{}
+	flattenValue("", value, fields)
+	return fields, nil
+}
+
+func flattenValue(path string, value any, fields map[string]any) {
+	switch v := value.(type) {
+	case map[string]any:
+		if len(v) == 0 && path != "" {
+			fields[path] = v
+		}
+		for key, child := range v {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			flattenValue(childPath, child, fields)
+		}
+	case []any:
+		if len(v) == 0 {
+			fields[path] = v
+		}
+		for i, child := range v {
+			flattenValue(fmt.Sprintf("%s[%d]", path, i), child, fields)
+		}
+	default:
+		fields[path] = v
+	}
+}
+
+// diffOutputFiles compares output files by name, sorted by file name
+func diffOutputFiles(a, b map[string]string) []types.OutputFileDiff {
+	diffs := []types.OutputFileDiff{}
+	for file, contentA := range a {
+		contentB, ok := b[file]
+		switch {
+		case !ok:
+			diffs = append(diffs, types.OutputFileDiff{File: file, Status: "removed", A: contentA})
+		case contentA != contentB:
+			diffs = append(diffs, types.OutputFileDiff{File: file, Status: "changed", A: contentA, B: contentB})
+		default:
+			diffs = append(diffs, types.OutputFileDiff{File: file, Status: "unchanged"})
+		}
+	}
+	for file, contentB := range b {
+		if _, ok := a[file]; !ok {
+			diffs = append(diffs, types.OutputFileDiff{File: file, Status: "added", B: contentB})
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].File < diffs[j].File })
+	return diffs
+}
@@ -0,0 +1,121 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+	"github.com/kubeopencode/kubeopencode/internal/server/types"
+)
+
+func TestDiffHandler_Tasks(t *testing.T) {
+	start := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	task := func(name, description string, duration time.Duration, outputs string) *kubeopenv1alpha1.Task {
+		task := &kubeopenv1alpha1.Task{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec: kubeopenv1alpha1.TaskSpec{
+				Description: &description,
+				AgentRef:    &kubeopenv1alpha1.AgentReference{Name: "coder"},
+			},
+			Status: kubeopenv1alpha1.TaskExecutionStatus{
+				Phase:          kubeopenv1alpha1.TaskPhaseCompleted,
+				StartTime:      &metav1.Time{Time: start},
+				CompletionTime: &metav1.Time{Time: start.Add(duration)},
+			},
+		}
+		if outputs != "" {
+			task.Status.Outputs = &kubeopenv1alpha1.TaskOutputsStatus{ConfigMapName: outputs}
+		}
+		return task
+	}
+	original := task("original", "Fix the bug", 2*time.Minute, "original-outputs")
+	retry := task("retry", "Fix the bug and add a test", 3*time.Minute, "retry-outputs")
+	retry.Spec.AgentRef = nil
+	retry.Spec.TemplateRef = &kubeopenv1alpha1.AgentTemplateReference{Name: "coder-template"}
+
+	objects := []client.Object{
+		original, retry,
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "original-outputs"},
+			Data:       map[string]string{"summary.md": "v1", "notes.txt": "same", "old.txt": "gone"},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "retry-outputs"},
+			Data:       map[string]string{"summary.md": "v2", "notes.txt": "same", "new.txt": "new"},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+	}{
+		{name: "compares two tasks", query: "a=default/original&b=default/retry", wantStatus: http.StatusOK},
+		{name: "missing parameter", query: "a=default/original", wantStatus: http.StatusBadRequest},
+		{name: "invalid reference", query: "a=original&b=default/retry", wantStatus: http.StatusBadRequest},
+		{name: "task not found", query: "a=default/original&b=default/missing", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k8sClient := fake.NewClientBuilder().WithScheme(newTestScheme()).WithObjects(objects...).Build()
+			handler := NewDiffHandler(k8sClient)
+
+			w := httptest.NewRecorder()
+			handler.Tasks(w, httptest.NewRequest(http.MethodGet, "/?"+tt.query, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var resp types.TaskDiffResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+
+			spec := map[string]types.SpecFieldDiff{}
+			for _, d := range resp.Spec {
+				spec[d.Path] = d
+			}
+			if len(spec) != 3 {
+				t.Errorf("expected 3 differing fields, got %v", resp.Spec)
+			}
+			if d := spec["description"]; d.A != "Fix the bug" || d.B != "Fix the bug and add a test" {
+				t.Errorf("unexpected description diff %+v", d)
+			}
+			if d := spec["agentRef.name"]; d.A != "coder" || d.B != nil {
+				t.Errorf("unexpected agentRef diff %+v", d)
+			}
+			if d := spec["templateRef.name"]; d.A != nil || d.B != "coder-template" {
+				t.Errorf("unexpected templateRef diff %+v", d)
+			}
+
+			wantOutputs := map[string]string{"new.txt": "added", "notes.txt": "unchanged", "old.txt": "removed", "summary.md": "changed"}
+			if len(resp.Outputs) != len(wantOutputs) {
+				t.Fatalf("expected %d output diffs, got %v", len(wantOutputs), resp.Outputs)
+			}
+			for _, d := range resp.Outputs {
+				if wantOutputs[d.File] != d.Status {
+					t.Errorf("expected %s to be %s, got %s", d.File, wantOutputs[d.File], d.Status)
+				}
+			}
+
+			if resp.DurationDeltaSeconds == nil || *resp.DurationDeltaSeconds != 60 {
+				t.Errorf("expected duration delta of 60s, got %v", resp.DurationDeltaSeconds)
+			}
+		})
+	}
+}
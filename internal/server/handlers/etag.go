@@ -0,0 +1,71 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package handlers
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// resourceETag returns a weak ETag for a single resource, derived from its
+// resourceVersion. It returns "" if the resource has no resourceVersion.
+func resourceETag(obj metav1.Object) string {
+	if obj.GetResourceVersion() == "" {
+		return ""
+	}
+	return fmt.Sprintf(`W/"%s"`, obj.GetResourceVersion())
+}
+
+// listETag returns a weak ETag for a list response, derived from the identity and
+// resourceVersion of every item that matched the request (before pagination), so the
+// same query over unchanged items yields the same ETag. The list's own resourceVersion
+// is left out, since it changes with every write in the cluster; a client that gets a
+// 304 can still start a watch from the resourceVersion of its cached list.
+func listETag[T any, PT interface {
+	*T
+	metav1.Object
+}](items []T) string {
+	h := sha256.New()
+	for i := range items {
+		obj := PT(&items[i])
+		_, _ = fmt.Fprintf(h, "%s/%s/%s\n", obj.GetNamespace(), obj.GetName(), obj.GetResourceVersion())
+	}
+	return fmt.Sprintf(`W/"%x"`, h.Sum(nil)[:16])
+}
+
+// notModified sets the ETag response header and reports whether the request's
+// If-None-Match header matches it, in which case a 304 response has been written.
+// Conditional requests are only honored for GET and HEAD.
+func notModified(w http.ResponseWriter, r *http.Request, etag string) bool {
+	if etag == "" {
+		return false
+	}
+	w.Header().Set("ETag", etag)
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	if !etagMatches(r.Header.Get("If-None-Match"), etag) {
+		return false
+	}
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// etagMatches reports whether an If-None-Match header value matches etag,
+// using the weak comparison required for If-None-Match (RFC 9110).
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
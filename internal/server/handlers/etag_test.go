@@ -0,0 +1,105 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+)
+
+func TestListETag(t *testing.T) {
+	task := func(name, rv string) kubeopenv1alpha1.Task {
+		return kubeopenv1alpha1.Task{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, ResourceVersion: rv}}
+	}
+
+	base := listETag([]kubeopenv1alpha1.Task{task("a", "1"), task("b", "2")})
+	if base != listETag([]kubeopenv1alpha1.Task{task("a", "1"), task("b", "2")}) {
+		t.Error("expected the same ETag for unchanged items")
+	}
+	if base == listETag([]kubeopenv1alpha1.Task{task("a", "1"), task("b", "3")}) {
+		t.Error("expected a different ETag when an item changes")
+	}
+	if base == listETag([]kubeopenv1alpha1.Task{task("a", "1")}) {
+		t.Error("expected a different ETag when an item is removed")
+	}
+	if listETag([]kubeopenv1alpha1.Task{}) == "" {
+		t.Error("expected an ETag for an empty list")
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		ifNoneMatch string
+		want        bool
+	}{
+		{name: "no If-None-Match", method: http.MethodGet},
+		{name: "matching weak ETag", method: http.MethodGet, ifNoneMatch: `W/"42"`, want: true},
+		{name: "matching strong ETag", method: http.MethodGet, ifNoneMatch: `"42"`, want: true},
+		{name: "one of several", method: http.MethodGet, ifNoneMatch: `W/"41", W/"42"`, want: true},
+		{name: "wildcard", method: http.MethodGet, ifNoneMatch: "*", want: true},
+		{name: "different ETag", method: http.MethodGet, ifNoneMatch: `W/"41"`},
+		{name: "ignored for PUT", method: http.MethodPut, ifNoneMatch: `W/"42"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.method, "/", nil)
+			if tt.ifNoneMatch != "" {
+				r.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+
+			got := notModified(w, r, `W/"42"`)
+			if got != tt.want {
+				t.Fatalf("notModified() = %v, want %v", got, tt.want)
+			}
+			if etag := w.Header().Get("ETag"); etag != `W/"42"` {
+				t.Errorf("expected ETag header W/\"42\", got %q", etag)
+			}
+			if tt.want && w.Code != http.StatusNotModified {
+				t.Errorf("expected status 304, got %d", w.Code)
+			}
+		})
+	}
+}
+
+func TestTaskHandler_GetNotModified(t *testing.T) {
+	k8sClient := fake.NewClientBuilder().
+		WithScheme(newTestScheme()).
+		WithObjects(&kubeopenv1alpha1.Task{ObjectMeta: metav1.ObjectMeta{Name: "my-task", Namespace: "default"}}).
+		Build()
+	handler := NewTaskHandler(k8sClient, nil, nil)
+
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if ifNoneMatch != "" {
+			r.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("namespace", "default")
+		rctx.URLParams.Add("name", "my-task")
+		r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+		handler.Get(w, r)
+		return w
+	}
+
+	first := get("")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" {
+		t.Fatalf("expected 200 with ETag, got %d and %q", first.Code, etag)
+	}
+	if second := get(etag); second.Code != http.StatusNotModified || second.Body.Len() != 0 {
+		t.Errorf("expected empty 304, got %d: %s", second.Code, second.Body.String())
+	}
+}
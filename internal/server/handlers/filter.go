@@ -15,9 +15,12 @@ import (
 type FilterOptions struct {
 	Name          string
 	Phase         string
+	Agent         string
+	Query         string
 	LabelSelector labels.Selector
 	Limit         int
 	Offset        int
+	Continue      string
 	SortOrder     string
 }
 
@@ -35,6 +38,12 @@ func ParseFilterOptions(r *http.Request) (*FilterOptions, error) {
 	// Parse phase filter
 	opts.Phase = r.URL.Query().Get("phase")
 
+	// Parse agent filter (Tasks referencing the Agent)
+	opts.Agent = r.URL.Query().Get("agent")
+
+	// Parse free-text search query
+	opts.Query = r.URL.Query().Get("q")
+
 	// Parse label selector (format: key1=value1,key2=value2)
 	if labelStr := r.URL.Query().Get("labelSelector"); labelStr != "" {
 		selector, err := labels.Parse(labelStr)
@@ -57,6 +66,8 @@ func ParseFilterOptions(r *http.Request) (*FilterOptions, error) {
 		}
 	}
 
+	opts.Continue = r.URL.Query().Get("continue")
+
 	if so := r.URL.Query().Get("sortOrder"); so == "asc" || so == "desc" {
 		opts.SortOrder = so
 	}
@@ -101,3 +112,26 @@ func MatchesPhaseFilter(phase, filter string) bool {
 	}
 	return false
 }
+
+// SearchTerms splits a free-text search query into lower-cased terms
+func SearchTerms(query string) []string {
+	return strings.Fields(strings.ToLower(query))
+}
+
+// UnmatchedSearchTerms returns the terms not contained in any of the texts (case-insensitive)
+func UnmatchedSearchTerms(terms []string, texts ...string) []string {
+	var unmatched []string
+	for _, term := range terms {
+		found := false
+		for _, text := range texts {
+			if strings.Contains(strings.ToLower(text), term) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			unmatched = append(unmatched, term)
+		}
+	}
+	return unmatched
+}
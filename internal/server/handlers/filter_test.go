@@ -217,3 +217,27 @@ func TestMatchesPhaseFilter(t *testing.T) {
 		})
 	}
 }
+
+func TestUnmatchedSearchTerms(t *testing.T) {
+	tests := []struct {
+		query string
+		texts []string
+		want  int
+	}{
+		{"", []string{"anything"}, 0},                              // empty query matches all
+		{"login", []string{"Fix the LOGIN bug"}, 0},                // case insensitive
+		{"login bug", []string{"fix login", "bug"}, 0},             // terms may match different texts
+		{"login crash", []string{"fix login"}, 1},                  // all terms must match
+		{"team=platform", []string{"team=platform", "app=web"}, 0}, // label match
+		{"missing", nil, 1},                                        // no texts
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got := UnmatchedSearchTerms(SearchTerms(tt.query), tt.texts...)
+			if len(got) != tt.want {
+				t.Errorf("UnmatchedSearchTerms(%q, %v) = %v, want %d unmatched", tt.query, tt.texts, got, tt.want)
+			}
+		})
+	}
+}
@@ -50,7 +50,9 @@ func (h *InfoHandler) ListNamespaces(w http.ResponseWriter, r *http.Request) {
 
 	namespaces := make([]string, 0, len(nsList.Items))
 	for _, ns := range nsList.Items {
-		namespaces = append(namespaces, ns.Name)
+		if namespaceAllowed(ctx, ns.Name) {
+			namespaces = append(namespaces, ns.Name)
+		}
 	}
 
 	// Sort namespaces alphabetically
@@ -3,10 +3,12 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"slices"
 	"testing"
 
 	corev1 "k8s.io/api/core/v1"
@@ -51,6 +53,7 @@ func TestInfoHandler_ListNamespaces(t *testing.T) {
 	tests := []struct {
 		name       string
 		objects    []runtime.Object
+		scope      NamespaceScope
 		wantCount  int
 		wantNames  []string
 		wantStatus int
@@ -83,6 +86,17 @@ func TestInfoHandler_ListNamespaces(t *testing.T) {
 			wantNames:  []string{"default"},
 			wantStatus: http.StatusOK,
 		},
+		{
+			name: "hides namespaces outside the scope",
+			objects: []runtime.Object{
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a"}},
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b"}},
+			},
+			scope:      staticNamespaceScope{"team-b"},
+			wantCount:  1,
+			wantNames:  []string{"team-b"},
+			wantStatus: http.StatusOK,
+		},
 	}
 
 	for _, tt := range tests {
@@ -97,6 +111,9 @@ func TestInfoHandler_ListNamespaces(t *testing.T) {
 			w := httptest.NewRecorder()
 			r := httptest.NewRequest(http.MethodGet, "/api/v1/namespaces", nil)
 			r.URL = &url.URL{Path: "/api/v1/namespaces"}
+			if tt.scope != nil {
+				r = r.WithContext(context.WithValue(r.Context(), NamespaceScopeContextKey{}, tt.scope))
+			}
 
 			handler.ListNamespaces(w, r)
 
@@ -121,3 +138,10 @@ func TestInfoHandler_ListNamespaces(t *testing.T) {
 		})
 	}
 }
+
+// staticNamespaceScope serves a fixed list of namespaces
+type staticNamespaceScope []string
+
+func (s staticNamespaceScope) Allows(_ context.Context, namespace string) bool {
+	return slices.Contains(s, namespace)
+}
@@ -0,0 +1,25 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package handlers
+
+import (
+	"context"
+)
+
+// NamespaceScopeContextKey is the context key for the server's NamespaceScope.
+type NamespaceScopeContextKey struct{}
+
+// NamespaceScope restricts the namespaces the API serves, so that a server deployed
+// for one tenant does not expose other namespaces its ServiceAccount can read.
+// It applies on top of RBAC and never grants access.
+type NamespaceScope interface {
+	// Allows reports whether namespace is served
+	Allows(ctx context.Context, namespace string) bool
+}
+
+// namespaceAllowed reports whether the request's NamespaceScope serves namespace.
+// Without a scope every namespace is served.
+func namespaceAllowed(ctx context.Context, namespace string) bool {
+	scope, ok := ctx.Value(NamespaceScopeContextKey{}).(NamespaceScope)
+	return !ok || scope == nil || scope.Allows(ctx, namespace)
+}
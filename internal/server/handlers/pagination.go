@@ -0,0 +1,102 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeopencode/kubeopencode/internal/server/types"
+)
+
+// listCursor is the sort key of the last item of a page, encoded in continue tokens
+type listCursor struct {
+	CreatedAt int64  `json:"t"`
+	Namespace string `json:"ns,omitempty"`
+	Name      string `json:"n"`
+}
+
+func cursorOf(obj metav1.Object) listCursor {
+	return listCursor{
+		CreatedAt: obj.GetCreationTimestamp().Unix(),
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+	}
+}
+
+// before orders cursors by creation time, then namespace and name, so that
+// items created in the same second still have a stable order.
+func (c listCursor) before(o listCursor) bool {
+	if c.CreatedAt != o.CreatedAt {
+		return c.CreatedAt < o.CreatedAt
+	}
+	if c.Namespace != o.Namespace {
+		return c.Namespace < o.Namespace
+	}
+	return c.Name < o.Name
+}
+
+func (c listCursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeListCursor(token string) (listCursor, error) {
+	var c listCursor
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err == nil {
+		err = json.Unmarshal(data, &c)
+	}
+	if err != nil || c.Name == "" {
+		return c, errors.New("invalid continue token")
+	}
+	return c, nil
+}
+
+// paginate sorts items by creation time in the requested order and returns the
+// requested page. The page starts after the continue cursor if one is given,
+// otherwise at the offset. A continue token for the next page is returned
+// when more items follow.
+func paginate[T any, PT interface {
+	*T
+	metav1.Object
+}](items []T, opts *FilterOptions) ([]T, *types.Pagination, error) {
+	// after reports whether a comes after b in the requested order
+	after := func(a, b listCursor) bool {
+		if opts.SortOrder == "asc" {
+			return b.before(a)
+		}
+		return a.before(b)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return after(cursorOf(PT(&items[j])), cursorOf(PT(&items[i])))
+	})
+
+	total := len(items)
+	start := min(opts.Offset, total)
+	if opts.Continue != "" {
+		cursor, err := decodeListCursor(opts.Continue)
+		if err != nil {
+			return nil, nil, err
+		}
+		start = sort.Search(total, func(i int) bool {
+			return after(cursorOf(PT(&items[i])), cursor)
+		})
+	}
+	end := min(start+opts.Limit, total)
+
+	pagination := &types.Pagination{
+		Limit:      opts.Limit,
+		Offset:     start,
+		TotalCount: total,
+		HasMore:    end < total,
+	}
+	if pagination.HasMore && end > start {
+		pagination.Continue = cursorOf(PT(&items[end-1])).encode()
+	}
+	return items[start:end], pagination, nil
+}
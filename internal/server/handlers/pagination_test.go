@@ -0,0 +1,96 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+)
+
+func TestPaginate(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	task := func(name string, age int) kubeopenv1alpha1.Task {
+		return kubeopenv1alpha1.Task{ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "default",
+			CreationTimestamp: metav1.NewTime(base.Add(time.Duration(-age) * time.Minute)),
+		}}
+	}
+	names := func(items []kubeopenv1alpha1.Task) []string {
+		var out []string
+		for _, item := range items {
+			out = append(out, item.Name)
+		}
+		return out
+	}
+	// t2a and t2b were created in the same second and are ordered by name
+	items := []kubeopenv1alpha1.Task{task("t3", 3), task("t1", 1), task("t2b", 2), task("t4", 4), task("t2a", 2)}
+
+	t.Run("offset pagination", func(t *testing.T) {
+		page, p, err := paginate(items, &FilterOptions{Limit: 2, Offset: 2, SortOrder: "desc"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := names(page); len(got) != 2 || got[0] != "t2a" || got[1] != "t3" {
+			t.Errorf("unexpected page %v", got)
+		}
+		if !p.HasMore || p.TotalCount != 5 || p.Offset != 2 {
+			t.Errorf("unexpected pagination %+v", p)
+		}
+	})
+
+	t.Run("continue tokens walk all items", func(t *testing.T) {
+		opts := &FilterOptions{Limit: 2, SortOrder: "desc"}
+		page, p, err := paginate(items, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := names(page)
+
+		// A Task created between pages must not shift the following pages
+		withNew := append([]kubeopenv1alpha1.Task{task("t0", 0)}, items...)
+		for p.Continue != "" {
+			opts.Continue = p.Continue
+			if page, p, err = paginate(withNew, opts); err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, names(page)...)
+		}
+		want := []string{"t1", "t2b", "t2a", "t3", "t4"}
+		if len(got) != len(want) {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("expected %v, got %v", want, got)
+			}
+		}
+		if p.HasMore {
+			t.Errorf("expected last page, got %+v", p)
+		}
+	})
+
+	t.Run("ascending order", func(t *testing.T) {
+		page, p, err := paginate(items, &FilterOptions{Limit: 3, SortOrder: "asc"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		page, _, err = paginate(items, &FilterOptions{Limit: 3, SortOrder: "asc", Continue: p.Continue})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := names(page); len(got) != 2 || got[0] != "t2b" || got[1] != "t1" {
+			t.Errorf("unexpected second page %v", got)
+		}
+	})
+
+	t.Run("invalid continue token", func(t *testing.T) {
+		if _, _, err := paginate(items, &FilterOptions{Limit: 2, Continue: "not-a-token"}); err == nil {
+			t.Error("expected error for invalid continue token")
+		}
+	})
+}
@@ -0,0 +1,208 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	authmiddleware "github.com/kubeopencode/kubeopencode/internal/server/middleware"
+	"github.com/kubeopencode/kubeopencode/internal/server/types"
+)
+
+const (
+	// PreferencesConfigMapName is the ConfigMap holding the preferences of all users,
+	// one key per user
+	PreferencesConfigMapName = "kubeopencode-user-preferences"
+
+	// maxFavoriteNamespaces bounds the size of a user's preferences
+	maxFavoriteNamespaces = 50
+
+	// maxUserPreferencesSize is the maximum encoded size of one user's preferences.
+	// Validated preferences stay below it; it guards against fields added later.
+	maxUserPreferencesSize = 4 << 10 // 4 KiB
+
+	// maxPreferencesConfigMapSize keeps the shared ConfigMap safely below the
+	// 1 MiB object size limit; new preferences are refused beyond it
+	maxPreferencesConfigMapSize = 900 << 10 // 900 KiB
+)
+
+// errPreferencesStorageFull is returned when the shared ConfigMap has no room left
+var errPreferencesStorageFull = errors.New("the preferences ConfigMap is full")
+
+// PreferencesHandler stores UI preferences per authenticated user.
+// Preferences are read and written with the server's own client, so users
+// need no access to the ConfigMap; each user can only reach their own key.
+type PreferencesHandler struct {
+	client    client.Client
+	namespace string
+}
+
+// NewPreferencesHandler creates a new PreferencesHandler storing preferences in
+// namespace. An empty namespace disables preferences.
+func NewPreferencesHandler(c client.Client, namespace string) *PreferencesHandler {
+	return &PreferencesHandler{client: c, namespace: namespace}
+}
+
+// Get returns the current user's preferences, or empty preferences if none are stored.
+func (h *PreferencesHandler) Get(w http.ResponseWriter, r *http.Request) {
+	key, ok := h.userKey(w, r)
+	if !ok {
+		return
+	}
+
+	var cm corev1.ConfigMap
+	err := h.client.Get(r.Context(), client.ObjectKey{Namespace: h.namespace, Name: PreferencesConfigMapName}, &cm)
+	if err != nil && !apierrors.IsNotFound(err) {
+		writeError(w, http.StatusInternalServerError, "Failed to get preferences", err.Error())
+		return
+	}
+
+	var prefs types.UserPreferences
+	if data, ok := cm.Data[key]; ok {
+		if err := json.Unmarshal([]byte(data), &prefs); err != nil {
+			writeError(w, http.StatusInternalServerError, "Failed to read preferences", err.Error())
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, prefs)
+}
+
+// Update replaces the current user's preferences.
+func (h *PreferencesHandler) Update(w http.ResponseWriter, r *http.Request) {
+	key, ok := h.userKey(w, r)
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 64<<10) // 64 KiB limit
+	var prefs types.UserPreferences
+	if err := json.NewDecoder(r.Body).Decode(&prefs); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+	if prefs.PageSize < 0 || prefs.PageSize > 100 {
+		writeError(w, http.StatusBadRequest, "Invalid pageSize", "pageSize must be between 1 and 100, or 0 for the default")
+		return
+	}
+	if len(prefs.FavoriteNamespaces) > maxFavoriteNamespaces {
+		writeError(w, http.StatusBadRequest, "Too many favorite namespaces", fmt.Sprintf("at most %d are allowed", maxFavoriteNamespaces))
+		return
+	}
+	for _, ns := range prefs.FavoriteNamespaces {
+		if errs := validation.IsDNS1123Label(ns); len(errs) > 0 {
+			writeError(w, http.StatusBadRequest, "Invalid favorite namespace", fmt.Sprintf("%q: %s", ns, strings.Join(errs, "; ")))
+			return
+		}
+	}
+	if prefs.DefaultAgent != "" {
+		if err := validateAgentRef(prefs.DefaultAgent); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid defaultAgent", err.Error())
+			return
+		}
+	}
+	data, err := json.Marshal(prefs)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to encode preferences", err.Error())
+		return
+	}
+	if len(data) > maxUserPreferencesSize {
+		writeError(w, http.StatusRequestEntityTooLarge, "Preferences too large", fmt.Sprintf("encoded preferences must not exceed %d bytes", maxUserPreferencesSize))
+		return
+	}
+
+	if err := h.store(r.Context(), key, string(data)); err != nil {
+		if errors.Is(err, errPreferencesStorageFull) {
+			writeError(w, http.StatusInsufficientStorage, "Preferences storage is full", "ask an administrator to clean up the "+PreferencesConfigMapName+" ConfigMap")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to save preferences", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, prefs)
+}
+
+// store sets key in the preferences ConfigMap, creating it if needed.
+func (h *PreferencesHandler) store(ctx context.Context, key, value string) error {
+	return retry.OnError(retry.DefaultRetry, func(err error) bool {
+		return apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err)
+	}, func() error {
+		var cm corev1.ConfigMap
+		err := h.client.Get(ctx, client.ObjectKey{Namespace: h.namespace, Name: PreferencesConfigMapName}, &cm)
+		if apierrors.IsNotFound(err) {
+			cm = corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      PreferencesConfigMapName,
+					Namespace: h.namespace,
+					Labels:    map[string]string{"app": "kubeopencode"},
+				},
+				Data: map[string]string{key: value},
+			}
+			return h.client.Create(ctx, &cm)
+		}
+		if err != nil {
+			return err
+		}
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		cm.Data[key] = value
+		if preferencesSize(cm.Data) > maxPreferencesConfigMapSize {
+			return errPreferencesStorageFull
+		}
+		return h.client.Update(ctx, &cm)
+	})
+}
+
+// preferencesSize returns the size of the stored preferences of all users.
+func preferencesSize(data map[string]string) int {
+	size := 0
+	for k, v := range data {
+		size += len(k) + len(v)
+	}
+	return size
+}
+
+// validateAgentRef checks that ref has the form "namespace/name".
+func validateAgentRef(ref string) error {
+	namespace, name, ok := strings.Cut(ref, "/")
+	if !ok {
+		return fmt.Errorf("%q must have the form namespace/name", ref)
+	}
+	if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+		return fmt.Errorf("invalid namespace %q: %s", namespace, strings.Join(errs, "; "))
+	}
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return fmt.Errorf("invalid name %q: %s", name, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// userKey returns the ConfigMap key of the request's user. Usernames may contain
+// characters that are not valid in keys, so the key is derived from a hash.
+func (h *PreferencesHandler) userKey(w http.ResponseWriter, r *http.Request) (string, bool) {
+	if h.namespace == "" {
+		writeError(w, http.StatusNotFound, "Preferences are not enabled", "start the server with --preferences-namespace")
+		return "", false
+	}
+	userInfo := authmiddleware.GetUserInfo(r.Context())
+	if userInfo == nil || userInfo.Username == "" {
+		writeError(w, http.StatusNotFound, "Preferences require an authenticated user", "")
+		return "", false
+	}
+	sum := sha256.Sum256([]byte(userInfo.Username))
+	return "user-" + hex.EncodeToString(sum[:16]), true
+}
@@ -0,0 +1,123 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	authmiddleware "github.com/kubeopencode/kubeopencode/internal/server/middleware"
+	"github.com/kubeopencode/kubeopencode/internal/server/types"
+)
+
+func TestPreferencesHandler(t *testing.T) {
+	k8sClient := fake.NewClientBuilder().WithScheme(newTestScheme()).Build()
+	handler := NewPreferencesHandler(k8sClient, "kubeopencode-system")
+
+	request := func(h *PreferencesHandler, method, username, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/", bytes.NewBufferString(body))
+		if username != "" {
+			r = r.WithContext(context.WithValue(r.Context(), authmiddleware.UserInfoKey, &authmiddleware.UserInfo{Username: username}))
+		}
+		w := httptest.NewRecorder()
+		if method == http.MethodPut {
+			h.Update(w, r)
+		} else {
+			h.Get(w, r)
+		}
+		return w
+	}
+	decode := func(t *testing.T, w *httptest.ResponseRecorder) types.UserPreferences {
+		t.Helper()
+		var prefs types.UserPreferences
+		if err := json.NewDecoder(w.Body).Decode(&prefs); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return prefs
+	}
+
+	t.Run("empty preferences before the first update", func(t *testing.T) {
+		w := request(handler, http.MethodGet, "alice@example.com", "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if prefs := decode(t, w); prefs.PageSize != 0 || len(prefs.FavoriteNamespaces) != 0 {
+			t.Errorf("expected empty preferences, got %+v", prefs)
+		}
+	})
+
+	t.Run("preferences are stored per user", func(t *testing.T) {
+		if w := request(handler, http.MethodPut, "alice@example.com", `{"favoriteNamespaces":["team-a"],"defaultAgent":"team-a/coder","pageSize":50}`); w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if w := request(handler, http.MethodPut, "system:serviceaccount:ci:bot", `{"pageSize":10}`); w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		alice := decode(t, request(handler, http.MethodGet, "alice@example.com", ""))
+		if alice.DefaultAgent != "team-a/coder" || alice.PageSize != 50 || len(alice.FavoriteNamespaces) != 1 {
+			t.Errorf("unexpected preferences for alice: %+v", alice)
+		}
+		bot := decode(t, request(handler, http.MethodGet, "system:serviceaccount:ci:bot", ""))
+		if bot.PageSize != 10 || bot.DefaultAgent != "" {
+			t.Errorf("unexpected preferences for bot: %+v", bot)
+		}
+	})
+
+	t.Run("validates page size", func(t *testing.T) {
+		if w := request(handler, http.MethodPut, "alice@example.com", `{"pageSize":500}`); w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("validates namespaces and default agent", func(t *testing.T) {
+		for _, body := range []string{
+			`{"favoriteNamespaces":["Team_A"]}`,
+			`{"favoriteNamespaces":["` + strings.Repeat("a", 64) + `"]}`,
+			`{"defaultAgent":"coder"}`,
+			`{"defaultAgent":"team-a/"}`,
+			`{"defaultAgent":"team-a/coder/extra"}`,
+			`{"defaultAgent":"` + strings.Repeat("a", 300) + `/coder"}`,
+		} {
+			if w := request(handler, http.MethodPut, "alice@example.com", body); w.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected 400, got %d", body, w.Code)
+			}
+		}
+	})
+
+	t.Run("refuses updates when the ConfigMap is full", func(t *testing.T) {
+		full := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: PreferencesConfigMapName, Namespace: "kubeopencode-system"},
+			Data:       map[string]string{"filler": strings.Repeat("x", maxPreferencesConfigMapSize)},
+		}
+		fullHandler := NewPreferencesHandler(fake.NewClientBuilder().WithScheme(newTestScheme()).WithObjects(full).Build(), "kubeopencode-system")
+		w := request(fullHandler, http.MethodPut, "alice@example.com", `{"pageSize":10}`)
+		if w.Code != http.StatusInsufficientStorage {
+			t.Errorf("expected 507, got %d: %s", w.Code, w.Body.String())
+		}
+		if w := request(fullHandler, http.MethodGet, "alice@example.com", ""); w.Code != http.StatusOK {
+			t.Errorf("expected 200, got %d", w.Code)
+		}
+	})
+
+	t.Run("requires an authenticated user", func(t *testing.T) {
+		if w := request(handler, http.MethodGet, "", ""); w.Code != http.StatusNotFound {
+			t.Errorf("expected 404, got %d", w.Code)
+		}
+	})
+
+	t.Run("disabled without a namespace", func(t *testing.T) {
+		if w := request(NewPreferencesHandler(k8sClient, ""), http.MethodGet, "alice@example.com", ""); w.Code != http.StatusNotFound {
+			t.Errorf("expected 404, got %d", w.Code)
+		}
+	})
+}
@@ -54,7 +54,7 @@ func (h *RegistryHandler) ListAll(w http.ResponseWriter, r *http.Request) {
 	// Filter by name (in-memory)
 	var filteredItems []kubeopenv1alpha1.Registry
 	for _, reg := range registryList.Items {
-		if MatchesNameFilter(reg.Name, filterOpts.Name) {
+		if MatchesNameFilter(reg.Name, filterOpts.Name) && namespaceAllowed(ctx, reg.Namespace) {
 			filteredItems = append(filteredItems, reg)
 		}
 	}
@@ -76,6 +76,10 @@ func (h *RegistryHandler) ListAll(w http.ResponseWriter, r *http.Request) {
 	paginatedItems := filteredItems[start:end]
 	hasMore := end < totalCount
 
+	if notModified(w, r, listETag(filteredItems)) {
+		return
+	}
+
 	response := types.RegistryListResponse{
 		Registries: make([]types.RegistryResponse, 0, len(paginatedItems)),
 		Total:      totalCount,
@@ -117,7 +121,7 @@ func (h *RegistryHandler) List(w http.ResponseWriter, r *http.Request) {
 	// Filter by name (in-memory)
 	var filteredItems []kubeopenv1alpha1.Registry
 	for _, reg := range registryList.Items {
-		if MatchesNameFilter(reg.Name, filterOpts.Name) {
+		if MatchesNameFilter(reg.Name, filterOpts.Name) && namespaceAllowed(ctx, reg.Namespace) {
 			filteredItems = append(filteredItems, reg)
 		}
 	}
@@ -139,6 +143,10 @@ func (h *RegistryHandler) List(w http.ResponseWriter, r *http.Request) {
 	paginatedItems := filteredItems[start:end]
 	hasMore := end < totalCount
 
+	if notModified(w, r, listETag(filteredItems)) {
+		return
+	}
+
 	response := types.RegistryListResponse{
 		Registries: make([]types.RegistryResponse, 0, len(paginatedItems)),
 		Total:      totalCount,
@@ -0,0 +1,213 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+	"github.com/kubeopencode/kubeopencode/internal/server/types"
+)
+
+// defaultReportRange is the time range of a report when since is not given
+const defaultReportRange = 7 * 24 * time.Hour
+
+// taskReportCSVHeader is the header row of CSV Task reports
+var taskReportCSVHeader = []string{
+	"namespace", "name", "agent", "template", "cronTask", "phase", "reason",
+	"startTime", "completionTime", "durationSeconds", "cost",
+}
+
+// ReportHandler serves reports on finished Tasks
+type ReportHandler struct {
+	defaultClient client.Client
+}
+
+// NewReportHandler creates a new ReportHandler
+func NewReportHandler(c client.Client) *ReportHandler {
+	return &ReportHandler{defaultClient: c}
+}
+
+func (h *ReportHandler) getClient(ctx context.Context) client.Client {
+	return clientFromContext(ctx, h.defaultClient)
+}
+
+// taskReportOptions are the query parameters of a Task report
+type taskReportOptions struct {
+	Namespace string
+	Agent     string
+	Since     time.Time
+	Until     time.Time
+	Format    string
+}
+
+// Tasks returns one record per Task that finished (Completed or Failed) between since
+// and until (RFC 3339, default the last 7 days), optionally filtered by namespace and
+// Agent, as JSON or as CSV with format=csv.
+func (h *ReportHandler) Tasks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	k8sClient := h.getClient(ctx)
+
+	opts, err := parseTaskReportOptions(r.URL.Query(), time.Now())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid report parameters", err.Error())
+		return
+	}
+
+	var listOpts []client.ListOption
+	if opts.Namespace != "" {
+		listOpts = append(listOpts, client.InNamespace(opts.Namespace))
+	}
+	var taskList kubeopenv1alpha1.TaskList
+	reader, _ := listReader(ctx, k8sClient, "tasks", opts.Namespace)
+	if err := reader.List(ctx, &taskList, listOpts...); err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to list tasks", err.Error())
+		return
+	}
+
+	tasks := taskList.Items[:0]
+	for _, task := range taskList.Items {
+		if namespaceAllowed(ctx, task.Namespace) {
+			tasks = append(tasks, task)
+		}
+	}
+
+	records := buildTaskReport(tasks, opts)
+	if opts.Format == "csv" {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q",
+			fmt.Sprintf("tasks-%s-%s.csv", opts.Since.Format("20060102"), opts.Until.Format("20060102"))))
+		w.WriteHeader(http.StatusOK)
+		_ = writeTaskReportCSV(w, records)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, types.TaskReportResponse{
+		Since:   opts.Since,
+		Until:   opts.Until,
+		Records: records,
+	})
+}
+
+// parseTaskReportOptions parses and validates the report query parameters
+func parseTaskReportOptions(query url.Values, now time.Time) (*taskReportOptions, error) {
+	opts := &taskReportOptions{
+		Namespace: query.Get("namespace"),
+		Agent:     query.Get("agent"),
+		Until:     now,
+		Format:    query.Get("format"),
+	}
+	if opts.Format == "" {
+		opts.Format = "json"
+	}
+	if opts.Format != "json" && opts.Format != "csv" {
+		return nil, fmt.Errorf("format must be json or csv, got %q", opts.Format)
+	}
+	if v := query.Get("until"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, fmt.Errorf("until must be an RFC 3339 time, got %q", v)
+		}
+		opts.Until = t
+	}
+	opts.Since = opts.Until.Add(-defaultReportRange)
+	if v := query.Get("since"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, fmt.Errorf("since must be an RFC 3339 time, got %q", v)
+		}
+		opts.Since = t
+	}
+	if !opts.Since.Before(opts.Until) {
+		return nil, fmt.Errorf("since must be before until")
+	}
+	return opts, nil
+}
+
+// buildTaskReport returns records for the Tasks that finished in [since, until)
+// and match the Agent filter, ordered by completion time.
+func buildTaskReport(tasks []kubeopenv1alpha1.Task, opts *taskReportOptions) []types.TaskReportRecord {
+	records := []types.TaskReportRecord{}
+	for i := range tasks {
+		task := &tasks[i]
+		phase := task.Status.Phase
+		if phase != kubeopenv1alpha1.TaskPhaseCompleted && phase != kubeopenv1alpha1.TaskPhaseFailed {
+			continue
+		}
+		if task.Status.CompletionTime == nil {
+			continue
+		}
+		completed := task.Status.CompletionTime.Time
+		if completed.Before(opts.Since) || !completed.Before(opts.Until) {
+			continue
+		}
+		if opts.Agent != "" && (task.Spec.AgentRef == nil || task.Spec.AgentRef.Name != opts.Agent) {
+			continue
+		}
+
+		record := types.TaskReportRecord{
+			Namespace:      task.Namespace,
+			Name:           task.Name,
+			CronTask:       task.Labels[kubeopenv1alpha1.CronTaskLabelKey],
+			Phase:          string(phase),
+			CompletionTime: completed,
+		}
+		if task.Spec.AgentRef != nil {
+			record.Agent = task.Spec.AgentRef.Name
+		}
+		if task.Spec.TemplateRef != nil {
+			record.Template = task.Spec.TemplateRef.Name
+		}
+		if cond := meta.FindStatusCondition(task.Status.Conditions, kubeopenv1alpha1.ConditionTypeReady); cond != nil {
+			record.Reason = cond.Reason
+		}
+		if task.Status.StartTime != nil {
+			start := task.Status.StartTime.Time
+			record.StartTime = &start
+			record.DurationSeconds = completed.Sub(start).Seconds()
+		}
+		if task.Status.Session != nil && task.Status.Session.Summary != nil {
+			record.Cost = task.Status.Session.Summary.Cost
+		}
+		records = append(records, record)
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].CompletionTime.Before(records[j].CompletionTime)
+	})
+	return records
+}
+
+// writeTaskReportCSV writes report records as CSV with a header row
+func writeTaskReportCSV(w http.ResponseWriter, records []types.TaskReportRecord) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(taskReportCSVHeader); err != nil {
+		return err
+	}
+	for _, rec := range records {
+		var start string
+		if rec.StartTime != nil {
+			start = rec.StartTime.UTC().Format(time.RFC3339)
+		}
+		row := []string{
+			rec.Namespace, rec.Name, rec.Agent, rec.Template, rec.CronTask, rec.Phase, rec.Reason,
+			start, rec.CompletionTime.UTC().Format(time.RFC3339),
+			strconv.FormatFloat(rec.DurationSeconds, 'f', 0, 64), rec.Cost,
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,136 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+	"github.com/kubeopencode/kubeopencode/internal/server/types"
+)
+
+func TestParseTaskReportOptions(t *testing.T) {
+	now := time.Date(2026, 6, 8, 0, 0, 0, 0, time.UTC)
+
+	opts, err := parseTaskReportOptions(url.Values{}, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.Format != "json" || !opts.Until.Equal(now) || !opts.Since.Equal(now.Add(-7*24*time.Hour)) {
+		t.Errorf("unexpected defaults: %+v", opts)
+	}
+
+	for _, query := range []string{
+		"format=xml",
+		"since=yesterday",
+		"until=2026-06-01",
+		"since=2026-06-02T00:00:00Z&until=2026-06-01T00:00:00Z",
+	} {
+		values, _ := url.ParseQuery(query)
+		if _, err := parseTaskReportOptions(values, now); err == nil {
+			t.Errorf("expected error for %q", query)
+		}
+	}
+}
+
+func TestReportHandler_Tasks(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	task := func(ns, name, agent string, phase kubeopenv1alpha1.TaskPhase, finishedAgo time.Duration) *kubeopenv1alpha1.Task {
+		task := &kubeopenv1alpha1.Task{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name}}
+		if agent != "" {
+			task.Spec.AgentRef = &kubeopenv1alpha1.AgentReference{Name: agent}
+		}
+		task.Status.Phase = phase
+		if finishedAgo > 0 {
+			task.Status.StartTime = &metav1.Time{Time: now.Add(-finishedAgo - 90*time.Second)}
+			task.Status.CompletionTime = &metav1.Time{Time: now.Add(-finishedAgo)}
+		}
+		return task
+	}
+
+	failed := task("team-a", "failed", "coder", kubeopenv1alpha1.TaskPhaseFailed, time.Hour)
+	failed.Labels = map[string]string{kubeopenv1alpha1.CronTaskLabelKey: "nightly"}
+	failed.Status.Conditions = []metav1.Condition{{Type: kubeopenv1alpha1.ConditionTypeReady, Status: metav1.ConditionFalse, Reason: "TimedOut"}}
+
+	objects := []client.Object{
+		task("team-a", "done", "coder", kubeopenv1alpha1.TaskPhaseCompleted, 2*time.Hour),
+		failed,
+		task("team-a", "running", "coder", kubeopenv1alpha1.TaskPhaseRunning, 0),
+		task("team-b", "reviewed", "reviewer", kubeopenv1alpha1.TaskPhaseCompleted, 3*time.Hour),
+		// Finished before the default range
+		task("team-a", "old", "coder", kubeopenv1alpha1.TaskPhaseCompleted, 10*24*time.Hour),
+	}
+
+	tests := []struct {
+		name      string
+		query     string
+		wantNames []string
+	}{
+		{name: "all finished tasks in range", wantNames: []string{"reviewed", "done", "failed"}},
+		{name: "filter by namespace", query: "namespace=team-a", wantNames: []string{"done", "failed"}},
+		{name: "filter by agent", query: "agent=reviewer", wantNames: []string{"reviewed"}},
+		{name: "since", query: "since=" + url.QueryEscape(now.Add(-90*time.Minute).Format(time.RFC3339)), wantNames: []string{"failed"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k8sClient := fake.NewClientBuilder().WithScheme(newTestScheme()).WithObjects(objects...).Build()
+			handler := NewReportHandler(k8sClient)
+
+			w := httptest.NewRecorder()
+			handler.Tasks(w, httptest.NewRequest(http.MethodGet, "/?"+tt.query, nil))
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+			}
+			var resp types.TaskReportResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			var names []string
+			for _, rec := range resp.Records {
+				names = append(names, rec.Name)
+			}
+			if strings.Join(names, ",") != strings.Join(tt.wantNames, ",") {
+				t.Errorf("expected records %v, got %v", tt.wantNames, names)
+			}
+		})
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		k8sClient := fake.NewClientBuilder().WithScheme(newTestScheme()).WithObjects(objects...).Build()
+		handler := NewReportHandler(k8sClient)
+
+		w := httptest.NewRecorder()
+		handler.Tasks(w, httptest.NewRequest(http.MethodGet, "/?format=csv&namespace=team-a", nil))
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+			t.Errorf("expected text/csv, got %q", ct)
+		}
+		rows, err := csv.NewReader(w.Body).ReadAll()
+		if err != nil {
+			t.Fatalf("failed to parse CSV: %v", err)
+		}
+		if len(rows) != 3 || strings.Join(rows[0], ",") != strings.Join(taskReportCSVHeader, ",") {
+			t.Fatalf("unexpected rows %v", rows)
+		}
+		want := []string{"team-a", "failed", "coder", "", "nightly", "Failed", "TimedOut"}
+		if strings.Join(rows[2][:7], ",") != strings.Join(want, ",") || rows[2][9] != "90" {
+			t.Errorf("unexpected record %v", rows[2])
+		}
+	})
+}
@@ -242,8 +242,8 @@ func (h *ShareHandler) ServeShareTerminal(w http.ResponseWriter, r *http.Request
 
 	var wsMu sync.Mutex
 
-	// Detach from chi's timeout for long-lived connection
-	sessionCtx, sessionCancel := context.WithCancel(context.WithoutCancel(r.Context()))
+	// The route is exempt from the request timeout, so the session lives as long as the connection
+	sessionCtx, sessionCancel := context.WithCancel(r.Context())
 	defer sessionCancel()
 
 	// Start connection heartbeat (using server's own client)
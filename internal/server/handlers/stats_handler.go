@@ -0,0 +1,124 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+	"github.com/kubeopencode/kubeopencode/internal/server/types"
+)
+
+// defaultStatsWindow is the window of finished Tasks used for failure rate and duration
+const defaultStatsWindow = 24 * time.Hour
+
+// StatsHandler serves aggregate Task statistics for the dashboard
+type StatsHandler struct {
+	defaultClient client.Client
+}
+
+// NewStatsHandler creates a new StatsHandler
+func NewStatsHandler(c client.Client) *StatsHandler {
+	return &StatsHandler{defaultClient: c}
+}
+
+func (h *StatsHandler) getClient(ctx context.Context) client.Client {
+	return clientFromContext(ctx, h.defaultClient)
+}
+
+// Get returns Task counts by phase, namespace and Agent, queue depths, and the failure
+// rate and average duration of Tasks finished within the window (default 24h).
+// The optional namespace query parameter limits the statistics to one namespace.
+func (h *StatsHandler) Get(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	k8sClient := h.getClient(ctx)
+
+	namespace := r.URL.Query().Get("namespace")
+	window := defaultStatsWindow
+	if v := r.URL.Query().Get("window"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			writeError(w, http.StatusBadRequest, "Invalid window", fmt.Sprintf("expected a positive Go duration (e.g. 1h, 168h), got %q", v))
+			return
+		}
+		window = d
+	}
+
+	var opts []client.ListOption
+	if namespace != "" {
+		opts = append(opts, client.InNamespace(namespace))
+	}
+	var taskList kubeopenv1alpha1.TaskList
+	reader, _ := listReader(ctx, k8sClient, "tasks", namespace)
+	if err := reader.List(ctx, &taskList, opts...); err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to list tasks", err.Error())
+		return
+	}
+
+	tasks := taskList.Items[:0]
+	for _, task := range taskList.Items {
+		if namespaceAllowed(ctx, task.Namespace) {
+			tasks = append(tasks, task)
+		}
+	}
+
+	stats := computeTaskStats(tasks, time.Now(), window)
+	stats.Namespace = namespace
+	writeJSON(w, http.StatusOK, stats)
+}
+
+// computeTaskStats aggregates Tasks. Agents are keyed by "namespace/name";
+// templateRef Tasks are only counted by phase and namespace.
+func computeTaskStats(tasks []kubeopenv1alpha1.Task, now time.Time, window time.Duration) types.TaskStatsResponse {
+	stats := types.TaskStatsResponse{
+		Total:       len(tasks),
+		ByPhase:     map[string]int{},
+		ByNamespace: map[string]int{},
+		ByAgent:     map[string]int{},
+		QueueDepths: map[string]int{},
+		Window:      types.TaskStatsWindow{Duration: window.String()},
+	}
+
+	var totalDuration time.Duration
+	since := now.Add(-window)
+	for i := range tasks {
+		task := &tasks[i]
+		phase := task.Status.Phase
+		if phase == "" {
+			phase = kubeopenv1alpha1.TaskPhasePending
+		}
+		stats.ByPhase[string(phase)]++
+		stats.ByNamespace[task.Namespace]++
+
+		if ref := task.Spec.AgentRef; ref != nil {
+			key := task.Namespace + "/" + ref.Name
+			stats.ByAgent[key]++
+			if phase == kubeopenv1alpha1.TaskPhaseQueued {
+				stats.QueueDepths[key]++
+			}
+		}
+
+		finished := phase == kubeopenv1alpha1.TaskPhaseCompleted || phase == kubeopenv1alpha1.TaskPhaseFailed
+		if !finished || task.Status.CompletionTime == nil || task.Status.CompletionTime.Time.Before(since) {
+			continue
+		}
+		stats.Window.Finished++
+		if phase == kubeopenv1alpha1.TaskPhaseFailed {
+			stats.Window.Failed++
+		}
+		if task.Status.StartTime != nil {
+			totalDuration += task.Status.CompletionTime.Sub(task.Status.StartTime.Time)
+		}
+	}
+
+	if stats.Window.Finished > 0 {
+		stats.Window.FailureRate = float64(stats.Window.Failed) / float64(stats.Window.Finished)
+		stats.Window.AverageDurationSeconds = totalDuration.Seconds() / float64(stats.Window.Finished)
+	}
+	return stats
+}
@@ -0,0 +1,106 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+	"github.com/kubeopencode/kubeopencode/internal/server/types"
+)
+
+func TestComputeTaskStats(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	task := func(ns, agent string, phase kubeopenv1alpha1.TaskPhase, finishedAgo, duration time.Duration) kubeopenv1alpha1.Task {
+		task := kubeopenv1alpha1.Task{ObjectMeta: metav1.ObjectMeta{Namespace: ns}}
+		if agent != "" {
+			task.Spec.AgentRef = &kubeopenv1alpha1.AgentReference{Name: agent}
+		}
+		task.Status.Phase = phase
+		if finishedAgo > 0 {
+			task.Status.StartTime = &metav1.Time{Time: now.Add(-finishedAgo - duration)}
+			task.Status.CompletionTime = &metav1.Time{Time: now.Add(-finishedAgo)}
+		}
+		return task
+	}
+
+	stats := computeTaskStats([]kubeopenv1alpha1.Task{
+		task("team-a", "coder", kubeopenv1alpha1.TaskPhaseCompleted, time.Hour, 2*time.Minute),
+		task("team-a", "coder", kubeopenv1alpha1.TaskPhaseFailed, 2*time.Hour, 4*time.Minute),
+		task("team-a", "coder", kubeopenv1alpha1.TaskPhaseQueued, 0, 0),
+		task("team-b", "reviewer", kubeopenv1alpha1.TaskPhaseRunning, 0, 0),
+		task("team-b", "", "", 0, 0),
+		// Finished before the window
+		task("team-b", "reviewer", kubeopenv1alpha1.TaskPhaseFailed, 48*time.Hour, time.Minute),
+	}, now, 24*time.Hour)
+
+	if stats.Total != 6 {
+		t.Errorf("expected 6 tasks, got %d", stats.Total)
+	}
+	if stats.ByPhase["Failed"] != 2 || stats.ByPhase["Pending"] != 1 || stats.ByPhase["Queued"] != 1 {
+		t.Errorf("unexpected byPhase %v", stats.ByPhase)
+	}
+	if stats.ByNamespace["team-a"] != 3 || stats.ByNamespace["team-b"] != 3 {
+		t.Errorf("unexpected byNamespace %v", stats.ByNamespace)
+	}
+	if stats.ByAgent["team-a/coder"] != 3 || stats.ByAgent["team-b/reviewer"] != 2 || len(stats.ByAgent) != 2 {
+		t.Errorf("unexpected byAgent %v", stats.ByAgent)
+	}
+	if stats.QueueDepths["team-a/coder"] != 1 || len(stats.QueueDepths) != 1 {
+		t.Errorf("unexpected queueDepths %v", stats.QueueDepths)
+	}
+	want := types.TaskStatsWindow{Duration: "24h0m0s", Finished: 2, Failed: 1, FailureRate: 0.5, AverageDurationSeconds: 180}
+	if stats.Window != want {
+		t.Errorf("expected window %+v, got %+v", want, stats.Window)
+	}
+}
+
+func TestStatsHandler_Get(t *testing.T) {
+	objects := []client.Object{
+		&kubeopenv1alpha1.Task{ObjectMeta: metav1.ObjectMeta{Name: "t1", Namespace: "team-a"}},
+		&kubeopenv1alpha1.Task{ObjectMeta: metav1.ObjectMeta{Name: "t2", Namespace: "team-b"}},
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantTotal  int
+	}{
+		{name: "all namespaces", wantStatus: http.StatusOK, wantTotal: 2},
+		{name: "single namespace", query: "namespace=team-a&window=168h", wantStatus: http.StatusOK, wantTotal: 1},
+		{name: "invalid window", query: "window=-1h", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k8sClient := fake.NewClientBuilder().WithScheme(newTestScheme()).WithObjects(objects...).Build()
+			handler := NewStatsHandler(k8sClient)
+
+			w := httptest.NewRecorder()
+			handler.Get(w, httptest.NewRequest(http.MethodGet, "/?"+tt.query, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var resp types.TaskStatsResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.Total != tt.wantTotal {
+				t.Errorf("expected total %d, got %d", tt.wantTotal, resp.Total)
+			}
+		})
+	}
+}
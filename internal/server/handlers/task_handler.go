@@ -4,26 +4,40 @@ package handlers
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
-	"sort"
+	"net/url"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+	"github.com/kubeopencode/kubeopencode/internal/controller"
+	authmiddleware "github.com/kubeopencode/kubeopencode/internal/server/middleware"
 	"github.com/kubeopencode/kubeopencode/internal/server/types"
+	"github.com/kubeopencode/kubeopencode/internal/tracing"
 )
 
+// maxSearchedOutputs bounds the outputs ConfigMaps read by one Task search, so that
+// a search over many Tasks does not issue a GET per Task. Tasks beyond the limit
+// are matched on their name, description, labels and output file names only.
+const maxSearchedOutputs = 50
+
 // TaskHandler handles task-related HTTP requests
 type TaskHandler struct {
 	defaultClient    client.Client
@@ -70,49 +84,53 @@ func (h *TaskHandler) listTasks(w http.ResponseWriter, r *http.Request, namespac
 	var taskList kubeopenv1alpha1.TaskList
 	listOpts := BuildListOptions(namespace, filterOpts)
 
-	if err := k8sClient.List(ctx, &taskList, listOpts...); err != nil {
+	// List from the indexed cache when allowed, filtering by phase and agent server-side
+	reader, cached := listReader(ctx, k8sClient, "tasks", namespace)
+	if cached {
+		listOpts = append(listOpts, taskIndexListOptions(filterOpts)...)
+	}
+
+	if err := reader.List(ctx, &taskList, listOpts...); err != nil {
 		writeError(w, http.StatusInternalServerError, "Failed to list tasks", err.Error())
 		return
 	}
 
-	// Filter by name and phase (in-memory)
+	// Filter by name, phase, agent and search query (in-memory)
+	terms := SearchTerms(filterOpts.Query)
+	outputReads := maxSearchedOutputs
 	var filteredItems []kubeopenv1alpha1.Task
 	for _, task := range taskList.Items {
-		if !MatchesNameFilter(task.Name, filterOpts.Name) {
+		if !MatchesNameFilter(task.Name, filterOpts.Name) || !namespaceAllowed(ctx, task.Namespace) {
 			continue
 		}
 		if filterOpts.Phase != "" && !MatchesPhaseFilter(string(task.Status.Phase), filterOpts.Phase) {
 			continue
 		}
+		if filterOpts.Agent != "" && (task.Spec.AgentRef == nil || task.Spec.AgentRef.Name != filterOpts.Agent) {
+			continue
+		}
+		if len(terms) > 0 && !matchesTaskSearch(ctx, k8sClient, &task, terms, &outputReads) {
+			continue
+		}
 		filteredItems = append(filteredItems, task)
 	}
 
-	// Sort by CreationTimestamp
-	sort.Slice(filteredItems, func(i, j int) bool {
-		if filterOpts.SortOrder == "asc" {
-			return filteredItems[i].CreationTimestamp.Before(&filteredItems[j].CreationTimestamp)
-		}
-		return filteredItems[j].CreationTimestamp.Before(&filteredItems[i].CreationTimestamp)
-	})
-
-	totalCount := len(filteredItems)
-
-	// Apply pagination bounds
-	start := min(filterOpts.Offset, totalCount)
-	end := min(start+filterOpts.Limit, totalCount)
+	// Sort by CreationTimestamp and paginate
+	paginatedItems, pagination, err := paginate(filteredItems, filterOpts)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid filter parameters", err.Error())
+		return
+	}
 
-	paginatedItems := filteredItems[start:end]
-	hasMore := end < totalCount
+	if notModified(w, r, listETag(filteredItems)) {
+		return
+	}
 
 	response := types.TaskListResponse{
-		Tasks: make([]types.TaskResponse, 0, len(paginatedItems)),
-		Total: totalCount,
-		Pagination: &types.Pagination{
-			Limit:      filterOpts.Limit,
-			Offset:     filterOpts.Offset,
-			TotalCount: totalCount,
-			HasMore:    hasMore,
-		},
+		Tasks:           make([]types.TaskResponse, 0, len(paginatedItems)),
+		Total:           pagination.TotalCount,
+		Pagination:      pagination,
+		ResourceVersion: taskList.ResourceVersion,
 	}
 
 	for _, task := range paginatedItems {
@@ -122,6 +140,132 @@ func (h *TaskHandler) listTasks(w http.ResponseWriter, r *http.Request, namespac
 	writeJSON(w, http.StatusOK, response)
 }
 
+// matchesTaskSearch reports whether every search term occurs in the task's name,
+// description, labels or captured outputs. The outputs ConfigMap is only read
+// when the other fields do not match all terms, and while outputReads is positive;
+// each read decrements it.
+func matchesTaskSearch(ctx context.Context, k8sClient client.Client, task *kubeopenv1alpha1.Task, terms []string, outputReads *int) bool {
+	texts := []string{task.Name}
+	if task.Spec.Description != nil {
+		texts = append(texts, *task.Spec.Description)
+	}
+	for k, v := range task.Labels {
+		texts = append(texts, k+"="+v)
+	}
+	if task.Status.Outputs != nil {
+		texts = append(texts, task.Status.Outputs.Files...)
+	}
+	remaining := UnmatchedSearchTerms(terms, texts...)
+	if len(remaining) == 0 || task.Status.Outputs == nil || *outputReads <= 0 {
+		return len(remaining) == 0
+	}
+	*outputReads--
+
+	var cm corev1.ConfigMap
+	if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: task.Namespace, Name: task.Status.Outputs.ConfigMapName}, &cm); err != nil {
+		return false
+	}
+	outputs := make([]string, 0, len(cm.Data))
+	for _, content := range cm.Data {
+		outputs = append(outputs, content)
+	}
+	return len(UnmatchedSearchTerms(remaining, outputs...)) == 0
+}
+
+// WatchAll streams task changes across all namespaces via Server-Sent Events
+func (h *TaskHandler) WatchAll(w http.ResponseWriter, r *http.Request) {
+	h.watchTasks(w, r, "")
+}
+
+// Watch streams task changes in a namespace via Server-Sent Events
+func (h *TaskHandler) Watch(w http.ResponseWriter, r *http.Request) {
+	h.watchTasks(w, r, chi.URLParam(r, "namespace"))
+}
+
+// watchTasks streams ADDED/MODIFIED/DELETED events for Tasks matching the name and
+// labelSelector filters. The phase filter is not applied, so that clients see Tasks
+// leaving a phase. Clients resume after a disconnect by passing the last seen
+// resourceVersion; an "error" event (e.g., resourceVersion too old) means the client
+// should list again.
+func (h *TaskHandler) watchTasks(w http.ResponseWriter, r *http.Request, namespace string) {
+	ctx := r.Context()
+	watchClient, ok := h.getClient(ctx).(client.WithWatch)
+	if !ok {
+		writeError(w, http.StatusNotImplemented, "Watch not supported", "")
+		return
+	}
+
+	filterOpts, err := ParseFilterOptions(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid filter parameters", err.Error())
+		return
+	}
+	listOpts := BuildListOptions(namespace, filterOpts)
+	if rv := r.URL.Query().Get("resourceVersion"); rv != "" {
+		listOpts = append(listOpts, &client.ListOptions{Raw: &metav1.ListOptions{ResourceVersion: rv}})
+	}
+
+	watcher, err := watchClient.Watch(ctx, &kubeopenv1alpha1.TaskList{}, listOpts...)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to watch tasks", err.Error())
+		return
+	}
+	defer watcher.Stop()
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "Streaming not supported", "")
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(taskWatchHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat.C:
+			// SSE comment keeps idle connections open through proxies
+			_, _ = fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return
+			}
+			if event.Type == watch.Error {
+				writeTaskWatchEvent(w, flusher, types.TaskWatchEvent{Type: "error", Message: apierrors.FromObject(event.Object).Error()})
+				return
+			}
+			task, ok := event.Object.(*kubeopenv1alpha1.Task)
+			if !ok || event.Type == watch.Bookmark || !MatchesNameFilter(task.Name, filterOpts.Name) || !namespaceAllowed(ctx, task.Namespace) {
+				continue
+			}
+			resp := taskToResponse(task)
+			writeTaskWatchEvent(w, flusher, types.TaskWatchEvent{Type: string(event.Type), Task: &resp, ResourceVersion: task.ResourceVersion})
+		}
+	}
+}
+
+// taskWatchHeartbeatInterval is how often an idle task watch stream sends a heartbeat
+var taskWatchHeartbeatInterval = 30 * time.Second
+
+// writeTaskWatchEvent marshals a TaskWatchEvent to JSON and writes it as an SSE data event
+func writeTaskWatchEvent(w http.ResponseWriter, flusher http.Flusher, event types.TaskWatchEvent) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	_, _ = fmt.Fprintf(w, "data: %s\n\n", data)
+	flusher.Flush()
+}
+
 // Get returns a specific task
 func (h *TaskHandler) Get(w http.ResponseWriter, r *http.Request) {
 	namespace := chi.URLParam(r, "namespace")
@@ -138,6 +282,143 @@ func (h *TaskHandler) Get(w http.ResponseWriter, r *http.Request) {
 	writeResourceOutput(w, r, http.StatusOK, &task, taskToResponse(&task))
 }
 
+// GetOutputs returns the captured outputs of a task as JSON.
+func (h *TaskHandler) GetOutputs(w http.ResponseWriter, r *http.Request) {
+	task, cm, ok := h.getTaskOutputs(w, r)
+	if !ok {
+		return
+	}
+
+	files := cm.Data
+	if files == nil {
+		files = map[string]string{}
+	}
+	writeJSON(w, http.StatusOK, types.TaskOutputsResponse{
+		Name:          task.Name,
+		Namespace:     task.Namespace,
+		ConfigMapName: cm.Name,
+		Files:         files,
+	})
+}
+
+// GetOutputFile returns the raw content of a single captured output file.
+func (h *TaskHandler) GetOutputFile(w http.ResponseWriter, r *http.Request) {
+	file := chi.URLParam(r, "file")
+
+	_, cm, ok := h.getTaskOutputs(w, r)
+	if !ok {
+		return
+	}
+
+	content, found := cm.Data[file]
+	if !found {
+		writeError(w, http.StatusNotFound, "Output not found", fmt.Sprintf("Task has no output file %q", file))
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", file))
+	w.WriteHeader(http.StatusOK)
+	_, _ = io.WriteString(w, content)
+}
+
+// serviceAccountPodNameExtra is the TokenReview extra naming the Pod a bound
+// ServiceAccount token was issued to.
+const serviceAccountPodNameExtra = "authentication.kubernetes.io/pod-name"
+
+// UploadOutputs stores the outputs a running task uploads from its Pod, as a
+// tar.gz of the outputs directory. Only the token of the task's own Pod is
+// accepted; the ConfigMap is then written with the server's client, since the
+// Pod's ServiceAccount need not be allowed to write ConfigMaps.
+func (h *TaskHandler) UploadOutputs(w http.ResponseWriter, r *http.Request) {
+	namespace := chi.URLParam(r, "namespace")
+	name := chi.URLParam(r, "name")
+	ctx := r.Context()
+
+	userInfo := authmiddleware.GetUserInfo(ctx)
+	if userInfo == nil {
+		writeError(w, http.StatusUnauthorized, "Authentication required", "outputs must be uploaded with the Task Pod's ServiceAccount token")
+		return
+	}
+
+	var task kubeopenv1alpha1.Task
+	if err := h.defaultClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, &task); err != nil {
+		writeError(w, http.StatusNotFound, "Task not found", err.Error())
+		return
+	}
+	podNames := userInfo.Extra[serviceAccountPodNameExtra]
+	if !strings.HasPrefix(userInfo.Username, "system:serviceaccount:"+namespace+":") ||
+		len(podNames) != 1 || task.Status.PodName == "" || podNames[0] != task.Status.PodName {
+		writeError(w, http.StatusForbidden, "Forbidden", "outputs can only be uploaded by the Task's own Pod")
+		return
+	}
+	if !task.Spec.CaptureOutputs || task.Status.Phase != kubeopenv1alpha1.TaskPhaseRunning {
+		writeError(w, http.StatusConflict, "Outputs not accepted", fmt.Sprintf("Task does not capture outputs or is not running (phase: %s)", task.Status.Phase))
+		return
+	}
+
+	raw, err := io.ReadAll(http.MaxBytesReader(w, r.Body, controller.MaxTaskOutputsUploadSize))
+	if err != nil {
+		writeError(w, http.StatusRequestEntityTooLarge, "Outputs too large", err.Error())
+		return
+	}
+	files, err := controller.DecodeTaskOutputsArchive(raw)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid outputs archive", err.Error())
+		return
+	}
+
+	cm := controller.BuildTaskOutputsConfigMap(&task, files)
+	if err := h.defaultClient.Create(ctx, cm); apierrors.IsAlreadyExists(err) {
+		var existing corev1.ConfigMap
+		if err = h.defaultClient.Get(ctx, client.ObjectKeyFromObject(cm), &existing); err == nil {
+			if !metav1.IsControlledBy(&existing, &task) {
+				writeError(w, http.StatusConflict, "Outputs not accepted", fmt.Sprintf("ConfigMap %s is not owned by the Task", cm.Name))
+				return
+			}
+			existing.Data = cm.Data
+			err = h.defaultClient.Update(ctx, &existing)
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "Failed to store outputs", err.Error())
+			return
+		}
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to store outputs", err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getTaskOutputs loads a task and the ConfigMap holding its captured outputs.
+// It writes an error response and returns false if either is unavailable.
+func (h *TaskHandler) getTaskOutputs(w http.ResponseWriter, r *http.Request) (*kubeopenv1alpha1.Task, *corev1.ConfigMap, bool) {
+	namespace := chi.URLParam(r, "namespace")
+	name := chi.URLParam(r, "name")
+	ctx := r.Context()
+	k8sClient := h.getClient(ctx)
+
+	var task kubeopenv1alpha1.Task
+	if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, &task); err != nil {
+		writeError(w, http.StatusNotFound, "Task not found", err.Error())
+		return nil, nil, false
+	}
+	if task.Status.Outputs == nil {
+		writeError(w, http.StatusNotFound, "Outputs not found", fmt.Sprintf("Task has no captured outputs (phase: %s)", task.Status.Phase))
+		return nil, nil, false
+	}
+
+	var cm corev1.ConfigMap
+	if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: task.Status.Outputs.ConfigMapName}, &cm); err != nil {
+		if apierrors.IsNotFound(err) {
+			writeError(w, http.StatusNotFound, "Outputs not found", err.Error())
+		} else {
+			writeError(w, http.StatusInternalServerError, "Failed to get outputs", err.Error())
+		}
+		return nil, nil, false
+	}
+	return &task, &cm, true
+}
+
 // Create creates a new task
 func (h *TaskHandler) Create(w http.ResponseWriter, r *http.Request) {
 	namespace := chi.URLParam(r, "namespace")
@@ -207,6 +488,8 @@ func (h *TaskHandler) Create(w http.ResponseWriter, r *http.Request) {
 		task.Spec.Timeout = &metav1.Duration{Duration: d}
 	}
 
+	task.Spec.Params = req.Params
+
 	// Convert contexts
 	for _, c := range req.Contexts {
 		item := kubeopenv1alpha1.ContextItem{
@@ -221,6 +504,74 @@ func (h *TaskHandler) Create(w http.ResponseWriter, r *http.Request) {
 		task.Spec.Contexts = append(task.Spec.Contexts, item)
 	}
 
+	// Reject contexts that would fail the Task when it starts
+	if err := controller.ValidateTaskContexts(task, ""); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid contexts", err.Error())
+		return
+	}
+
+	// Continue the request's trace in the controller
+	tracing.InjectAnnotation(ctx, task)
+	if err := k8sClient.Create(ctx, task); err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to create task", err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, taskToResponse(task))
+}
+
+// Retry creates a new Task from a finished one with the same spec, optionally with an
+// edited description. The new Task is labeled with the name of the retried Task.
+func (h *TaskHandler) Retry(w http.ResponseWriter, r *http.Request) {
+	namespace := chi.URLParam(r, "namespace")
+	name := chi.URLParam(r, "name")
+	ctx := r.Context()
+	k8sClient := h.getClient(ctx)
+
+	var req types.RetryTaskRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+			writeError(w, http.StatusBadRequest, "Invalid request body", err.Error())
+			return
+		}
+	}
+
+	var original kubeopenv1alpha1.Task
+	if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, &original); err != nil {
+		writeError(w, http.StatusNotFound, "Task not found", err.Error())
+		return
+	}
+	if original.Status.Phase != kubeopenv1alpha1.TaskPhaseCompleted && original.Status.Phase != kubeopenv1alpha1.TaskPhaseFailed {
+		writeError(w, http.StatusBadRequest, "Task is not finished", fmt.Sprintf("Task phase is %s", original.Status.Phase))
+		return
+	}
+
+	task := &kubeopenv1alpha1.Task{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: retryGenerateName(&original),
+			Namespace:    namespace,
+			Labels:       map[string]string{},
+		},
+		Spec: *original.Spec.DeepCopy(),
+	}
+	// Keep user labels, but not the CronTask label: the retry is not owned by the CronTask
+	for k, v := range original.Labels {
+		if k != kubeopenv1alpha1.CronTaskLabelKey {
+			task.Labels[k] = v
+		}
+	}
+	task.Labels[kubeopenv1alpha1.TaskRetryOfLabelKey] = original.Name
+	// Annotations are not copied: the retry gets the trace of this request, not the
+	// trace of the retried Task
+	if req.Description != "" {
+		task.Spec.Description = &req.Description
+	}
+
+	if err := controller.ValidateTaskContexts(task, ""); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid contexts", err.Error())
+		return
+	}
+	tracing.InjectAnnotation(ctx, task)
 	if err := k8sClient.Create(ctx, task); err != nil {
 		writeError(w, http.StatusInternalServerError, "Failed to create task", err.Error())
 		return
@@ -229,6 +580,20 @@ func (h *TaskHandler) Create(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusCreated, taskToResponse(task))
 }
 
+// retryGenerateName returns the generateName prefix for a retry of task.
+// Retries of retries share the name of the first Task instead of growing a suffix chain.
+func retryGenerateName(task *kubeopenv1alpha1.Task) string {
+	base := task.Name
+	if original := task.Labels[kubeopenv1alpha1.TaskRetryOfLabelKey]; original != "" {
+		base = original
+	}
+	// Leave room for "-retry-" and the 5 random characters appended by the API server
+	if len(base) > 50 {
+		base = base[:50]
+	}
+	return base + "-retry-"
+}
+
 // Delete deletes a task
 func (h *TaskHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	namespace := chi.URLParam(r, "namespace")
@@ -283,7 +648,78 @@ func (h *TaskHandler) Stop(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, taskToResponse(&task))
 }
 
-// GetLogs streams task logs via Server-Sent Events
+// Patch updates Task labels and annotations with a JSON merge patch
+// (RFC 7386) of the form {"metadata": {"labels": {...}, "annotations": {...}}}.
+// A null value removes a key. Other fields, and keys in the kubeopencode.io
+// domain that are managed by the operator, cannot be patched.
+func (h *TaskHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	namespace := chi.URLParam(r, "namespace")
+	name := chi.URLParam(r, "name")
+	ctx := r.Context()
+	k8sClient := h.getClient(ctx)
+
+	r.Body = http.MaxBytesReader(w, r.Body, 256<<10) // 256 KiB limit, the annotation size limit
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Failed to read request body", err.Error())
+		return
+	}
+	if err := validateMetadataPatch(body); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid patch", err.Error())
+		return
+	}
+
+	task := &kubeopenv1alpha1.Task{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+	if err := k8sClient.Patch(ctx, task, client.RawPatch(k8stypes.MergePatchType, body)); err != nil {
+		switch {
+		case apierrors.IsNotFound(err):
+			writeError(w, http.StatusNotFound, "Task not found", err.Error())
+		case apierrors.IsInvalid(err):
+			writeError(w, http.StatusUnprocessableEntity, "Invalid labels or annotations", err.Error())
+		case apierrors.IsForbidden(err):
+			writeError(w, http.StatusForbidden, "Failed to patch task", err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "Failed to patch task", err.Error())
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, taskToResponse(task))
+}
+
+// userSettableAnnotations are annotations in the operator's domain that users
+// set themselves, rather than the controller.
+var userSettableAnnotations = map[string]bool{
+	controller.AnnotationKeep: true,
+}
+
+// validateMetadataPatch checks that a merge patch only sets metadata.labels and
+// metadata.annotations, outside the operator's domain except for
+// userSettableAnnotations.
+func validateMetadataPatch(body []byte) error {
+	var patch types.PatchTaskRequest
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&patch); err != nil {
+		return fmt.Errorf("only metadata.labels and metadata.annotations can be patched: %w", err)
+	}
+	for kind, values := range map[string]map[string]*string{"label": patch.Metadata.Labels, "annotation": patch.Metadata.Annotations} {
+		for key := range values {
+			if kind == "annotation" && userSettableAnnotations[key] {
+				continue
+			}
+			prefix, _, found := strings.Cut(key, "/")
+			if found && (prefix == kubeopenv1alpha1.GroupName || strings.HasSuffix(prefix, "."+kubeopenv1alpha1.GroupName)) {
+				return fmt.Errorf("%s %q is managed by KubeOpenCode", kind, key)
+			}
+		}
+	}
+	return nil
+}
+
+// GetLogs streams task logs via Server-Sent Events.
+// Each log line is sent with its line number as the event ID, so a client that
+// reconnects with a Last-Event-ID header resumes after the last line it received.
 func (h *TaskHandler) GetLogs(w http.ResponseWriter, r *http.Request) {
 	namespace := chi.URLParam(r, "namespace")
 	name := chi.URLParam(r, "name")
@@ -297,6 +733,11 @@ func (h *TaskHandler) GetLogs(w http.ResponseWriter, r *http.Request) {
 	if container == "" {
 		container = "agent"
 	}
+	skipLines, err := parseLastEventID(r.Header.Get("Last-Event-ID"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid Last-Event-ID", err.Error())
+		return
+	}
 
 	var task kubeopenv1alpha1.Task
 	if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, &task); err != nil {
@@ -338,11 +779,173 @@ func (h *TaskHandler) GetLogs(w http.ResponseWriter, r *http.Request) {
 
 	// Stream pod logs using impersonated clientset for RBAC enforcement
 	clientset := clientsetFromContext(ctx, h.defaultClientset)
-	h.streamPodLogs(ctx, w, flusher, clientset, podNamespace, task.Status.PodName, container, follow, namespace, name)
+	h.streamPodLogs(ctx, w, flusher, clientset, podNamespace, task.Status.PodName, container, follow, skipLines, namespace, name)
+}
+
+// parseLastEventID parses the Last-Event-ID of a reconnecting log stream,
+// which is the number of log lines the client has already received.
+func parseLastEventID(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("must be a non-negative line number, got %q", value)
+	}
+	return n, nil
+}
+
+// DownloadLogs writes the logs of a Task Pod container (default: agent) as plain text,
+// for archiving and scripts. Unlike GetLogs it does not follow the log and supports
+// the tailLines, sinceTime (RFC 3339), timestamps and previous options of the Pod log API.
+func (h *TaskHandler) DownloadLogs(w http.ResponseWriter, r *http.Request) {
+	namespace := chi.URLParam(r, "namespace")
+	name := chi.URLParam(r, "name")
+	ctx := r.Context()
+	k8sClient := h.getClient(ctx)
+
+	logOptions, err := parsePodLogOptions(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid log options", err.Error())
+		return
+	}
+
+	var task kubeopenv1alpha1.Task
+	if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, &task); err != nil {
+		writeError(w, http.StatusNotFound, "Task not found", err.Error())
+		return
+	}
+	if task.Status.PodName == "" {
+		writeError(w, http.StatusBadRequest, "Task has no pod", "Pod not yet created")
+		return
+	}
+
+	// Pod logs are read with the impersonated clientset for RBAC enforcement
+	clientset := clientsetFromContext(ctx, h.defaultClientset)
+	stream, err := clientset.CoreV1().Pods(namespace).GetLogs(task.Status.PodName, logOptions).Stream(ctx)
+	if err != nil {
+		switch {
+		case apierrors.IsNotFound(err):
+			writeError(w, http.StatusNotFound, "Pod not found", err.Error())
+		case apierrors.IsForbidden(err):
+			writeError(w, http.StatusForbidden, "Failed to get logs", err.Error())
+		case apierrors.IsBadRequest(err):
+			// e.g. previous=true without a restarted container, or the container is still waiting
+			writeError(w, http.StatusBadRequest, "Failed to get logs", err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "Failed to get logs", err.Error())
+		}
+		return
+	}
+	defer func() { _ = stream.Close() }()
+
+	filename := fmt.Sprintf("%s-%s.log", name, logOptions.Container)
+	if logOptions.Previous {
+		filename = fmt.Sprintf("%s-%s-previous.log", name, logOptions.Container)
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+	_, _ = io.Copy(w, stream)
+}
+
+// parsePodLogOptions parses the container, tailLines, sinceTime, timestamps and
+// previous query parameters of the log download endpoint.
+func parsePodLogOptions(query url.Values) (*corev1.PodLogOptions, error) {
+	opts := &corev1.PodLogOptions{Container: query.Get("container")}
+	if opts.Container == "" {
+		opts.Container = "agent"
+	}
+	if v := query.Get("tailLines"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("tailLines must be a non-negative integer, got %q", v)
+		}
+		opts.TailLines = &n
+	}
+	if v := query.Get("sinceTime"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, fmt.Errorf("sinceTime must be an RFC 3339 timestamp: %w", err)
+		}
+		since := metav1.NewTime(t)
+		opts.SinceTime = &since
+	}
+	for param, target := range map[string]*bool{"timestamps": &opts.Timestamps, "previous": &opts.Previous} {
+		if v := query.Get(param); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, fmt.Errorf("%s must be a boolean, got %q", param, v)
+			}
+			*target = b
+		}
+	}
+	return opts, nil
+}
+
+// Exec upgrades the HTTP connection to WebSocket and opens an interactive shell in a
+// container of the running Task Pod (default: agent), using the same protocol as the
+// Agent terminal. The user's pods/exec permission is checked through impersonation.
+func (h *TaskHandler) Exec(w http.ResponseWriter, r *http.Request) {
+	namespace := chi.URLParam(r, "namespace")
+	name := chi.URLParam(r, "name")
+	ctx := r.Context()
+	k8sClient := h.getClient(ctx)
+
+	container := r.URL.Query().Get("container")
+	if container == "" {
+		container = "agent"
+	}
+
+	var task kubeopenv1alpha1.Task
+	if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, &task); err != nil {
+		writeError(w, http.StatusNotFound, "Task not found", err.Error())
+		return
+	}
+	if task.Status.PodName == "" {
+		writeError(w, http.StatusBadRequest, "Task has no pod", "Pod not yet created")
+		return
+	}
+	var pod corev1.Pod
+	if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: task.Status.PodName}, &pod); err != nil {
+		writeError(w, http.StatusNotFound, "Pod not found", err.Error())
+		return
+	}
+	if pod.Status.Phase != corev1.PodRunning {
+		writeError(w, http.StatusConflict, "Pod is not running", fmt.Sprintf("pod %s is %s", pod.Name, pod.Status.Phase))
+		return
+	}
+
+	execConfig := rest.CopyConfig(h.restConfig)
+	if userInfo := authmiddleware.GetUserInfo(ctx); userInfo != nil {
+		execConfig.Impersonate = rest.ImpersonationConfig{
+			UserName: userInfo.Username,
+			UID:      userInfo.UID,
+			Groups:   userInfo.Groups,
+		}
+		termLog.Info("task exec session starting", "user", userInfo.Username, "task", name, "namespace", namespace, "pod", pod.Name)
+	}
+
+	ws, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		termLog.Error(err, "websocket upgrade failed")
+		return
+	}
+	defer func() { _ = ws.Close() }()
+	var wsMu sync.Mutex
+
+	// The route is exempt from the request timeout, so the session lives as long as the connection
+	sessionCtx, sessionCancel := context.WithCancel(ctx)
+	defer sessionCancel()
+
+	runTerminalExec(sessionCtx, sessionCancel, ws, &wsMu, execConfig, namespace, pod.Name, container,
+		[]string{"/bin/sh", "-c", "command -v bash >/dev/null 2>&1 && exec bash || exec sh"}, "task", name)
 }
 
 // streamPodLogs streams actual pod logs using the provided clientset (impersonated for RBAC).
-func (h *TaskHandler) streamPodLogs(ctx context.Context, w http.ResponseWriter, flusher http.Flusher, clientset kubernetes.Interface, podNamespace, podName, container string, follow bool, taskNamespace, taskName string) {
+// The first skipLines lines are not sent, and heartbeat comments keep the stream alive
+// while the container is not writing.
+func (h *TaskHandler) streamPodLogs(ctx context.Context, w http.ResponseWriter, flusher http.Flusher, clientset kubernetes.Interface, podNamespace, podName, container string, follow bool, skipLines int64, taskNamespace, taskName string) {
 	// Create pod log options
 	logOptions := &corev1.PodLogOptions{
 		Container: container,
@@ -369,37 +972,84 @@ func (h *TaskHandler) streamPodLogs(ctx context.Context, w http.ResponseWriter,
 	}
 	defer func() { _ = stream.Close() }()
 
-	// Read logs line by line and send as SSE events
-	reader := bufio.NewReader(stream)
+	// Read logs line by line in the background, so heartbeats can be sent while waiting
+	lines := make(chan []byte)
+	readErr := make(chan error, 1)
+	go func() {
+		defer close(lines)
+		reader := bufio.NewReader(stream)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(line) > 0 {
+				select {
+				case lines <- line:
+				case <-ctx.Done():
+					readErr <- ctx.Err()
+					return
+				}
+			}
+			if err != nil {
+				readErr <- err
+				return
+			}
+		}
+	}()
+
+	heartbeat := time.NewTicker(logStreamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	// Send each log line as an SSE event whose ID is its line number
+	var lineNumber int64
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		default:
-			line, err := reader.ReadBytes('\n')
-			if err != nil {
-				if err == io.EOF {
-					// Check if task is completed
-					k8sClient := h.getClient(ctx)
-					var task kubeopenv1alpha1.Task
-					phase := "Unknown"
-					if getErr := k8sClient.Get(ctx, client.ObjectKey{Namespace: taskNamespace, Name: taskName}, &task); getErr == nil {
-						phase = string(task.Status.Phase)
-					}
-					writeSSEEvent(w, flusher, types.LogEvent{Type: "complete", Phase: &phase})
+		case <-heartbeat.C:
+			_, _ = fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		case line, ok := <-lines:
+			if !ok {
+				err := <-readErr
+				if ctx.Err() != nil {
+					return
+				}
+				if err != io.EOF {
+					writeSSEEvent(w, flusher, types.LogEvent{Type: "error", Message: fmt.Sprintf("Read error: %s", err.Error())})
 					return
 				}
-				writeSSEEvent(w, flusher, types.LogEvent{Type: "error", Message: fmt.Sprintf("Read error: %s", err.Error())})
+				// Check if task is completed
+				k8sClient := h.getClient(ctx)
+				var task kubeopenv1alpha1.Task
+				phase := "Unknown"
+				if getErr := k8sClient.Get(ctx, client.ObjectKey{Namespace: taskNamespace, Name: taskName}, &task); getErr == nil {
+					phase = string(task.Status.Phase)
+				}
+				writeSSEEvent(w, flusher, types.LogEvent{Type: "complete", Phase: &phase})
 				return
 			}
-
-			// Send log line as SSE event
+			lineNumber++
+			if lineNumber <= skipLines {
+				continue
+			}
 			logContent := string(line)
-			writeSSEEvent(w, flusher, types.LogEvent{Type: "log", Content: &logContent})
+			writeSSELogEvent(w, flusher, lineNumber, types.LogEvent{Type: "log", Content: &logContent})
 		}
 	}
 }
 
+// logStreamHeartbeatInterval is how often an idle log stream sends a heartbeat
+var logStreamHeartbeatInterval = 15 * time.Second
+
+// writeSSELogEvent writes a LogEvent as an SSE data event with the given event ID
+func writeSSELogEvent(w http.ResponseWriter, flusher http.Flusher, id int64, event types.LogEvent) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	_, _ = fmt.Fprintf(w, "id: %d\ndata: %s\n\n", id, data)
+	flusher.Flush()
+}
+
 // writeSSEEvent marshals a LogEvent to JSON and writes it as an SSE data event
 func writeSSEEvent(w http.ResponseWriter, flusher http.Flusher, event types.LogEvent) {
 	data, err := json.Marshal(event)
@@ -425,6 +1075,7 @@ func taskToResponse(task *kubeopenv1alpha1.Task) types.TaskResponse {
 		PodName:     task.Status.PodName,
 		CreatedAt:   task.CreationTimestamp.Time,
 		Labels:      task.Labels,
+		Annotations: task.Annotations,
 	}
 
 	// Timeout
@@ -483,6 +1134,17 @@ func taskToResponse(task *kubeopenv1alpha1.Task) types.TaskResponse {
 		}
 	}
 
+	if result := task.Status.Result; result != nil {
+		resp.Result = &types.TaskResultResponse{
+			Status:  string(result.Status),
+			Summary: result.Summary,
+		}
+		if result.Fields != nil {
+			// Fields was validated as a JSON object when the result was captured
+			_ = json.Unmarshal(result.Fields.Raw, &resp.Result.Fields)
+		}
+	}
+
 	if task.Status.StartTime != nil {
 		t := task.Status.StartTime.Time
 		resp.StartTime = &t
@@ -3,23 +3,34 @@
 package handlers
 
 import (
+	"archive/tar"
+	"bufio"
 	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"sort"
+	"strings"
 	"testing"
 
 	"github.com/go-chi/chi/v5"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+	"github.com/kubeopencode/kubeopencode/internal/controller"
+	authmiddleware "github.com/kubeopencode/kubeopencode/internal/server/middleware"
 	"github.com/kubeopencode/kubeopencode/internal/server/types"
+	"github.com/kubeopencode/kubeopencode/internal/tracing"
 )
 
 func TestTaskHandler_List(t *testing.T) {
@@ -95,6 +106,50 @@ func TestTaskHandler_List(t *testing.T) {
 			wantTotal:  1,
 			wantStatus: http.StatusOK,
 		},
+		{
+			name:      "search description, labels and outputs",
+			namespace: "default",
+			query:     "q=LOGIN+regression",
+			objects: []runtime.Object{
+				&kubeopenv1alpha1.Task{
+					ObjectMeta: metav1.ObjectMeta{Name: "by-description", Namespace: "default"},
+					Spec: kubeopenv1alpha1.TaskSpec{
+						AgentRef:    &kubeopenv1alpha1.AgentReference{Name: "a"},
+						Description: ptr.To("Fix the login regression"),
+					},
+				},
+				&kubeopenv1alpha1.Task{
+					ObjectMeta: metav1.ObjectMeta{Name: "by-label", Namespace: "default", Labels: map[string]string{"area": "login"}},
+					Spec: kubeopenv1alpha1.TaskSpec{
+						AgentRef:    &kubeopenv1alpha1.AgentReference{Name: "a"},
+						Description: ptr.To("Investigate regression"),
+					},
+				},
+				&kubeopenv1alpha1.Task{
+					ObjectMeta: metav1.ObjectMeta{Name: "by-output", Namespace: "default"},
+					Spec: kubeopenv1alpha1.TaskSpec{
+						AgentRef:    &kubeopenv1alpha1.AgentReference{Name: "a"},
+						Description: ptr.To("Nightly triage"),
+					},
+					Status: kubeopenv1alpha1.TaskExecutionStatus{
+						Outputs: &kubeopenv1alpha1.TaskOutputsStatus{ConfigMapName: "by-output-outputs", Files: []string{"report.md"}},
+					},
+				},
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: "by-output-outputs", Namespace: "default"},
+					Data:       map[string]string{"report.md": "Found a login regression in v2"},
+				},
+				&kubeopenv1alpha1.Task{
+					ObjectMeta: metav1.ObjectMeta{Name: "no-match", Namespace: "default"},
+					Spec: kubeopenv1alpha1.TaskSpec{
+						AgentRef:    &kubeopenv1alpha1.AgentReference{Name: "a"},
+						Description: ptr.To("Fix the login page"),
+					},
+				},
+			},
+			wantTotal:  3,
+			wantStatus: http.StatusOK,
+		},
 		{
 			name:       "empty list",
 			namespace:  "default",
@@ -139,6 +194,34 @@ func TestTaskHandler_List(t *testing.T) {
 	}
 }
 
+func TestMatchesTaskSearchOutputReads(t *testing.T) {
+	task := &kubeopenv1alpha1.Task{
+		ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "default"},
+		Status: kubeopenv1alpha1.TaskExecutionStatus{
+			Outputs: &kubeopenv1alpha1.TaskOutputsStatus{ConfigMapName: "nightly-outputs", Files: []string{"report.md"}},
+		},
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(newTestScheme()).WithObjects(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "nightly-outputs", Namespace: "default"},
+		Data:       map[string]string{"report.md": "Found a login regression"},
+	}).Build()
+	terms := SearchTerms("regression")
+
+	reads := 1
+	if !matchesTaskSearch(context.Background(), k8sClient, task, terms, &reads) {
+		t.Error("expected the outputs to match")
+	}
+	if reads != 0 {
+		t.Errorf("expected the read to be counted, %d reads left", reads)
+	}
+	if matchesTaskSearch(context.Background(), k8sClient, task, terms, &reads) {
+		t.Error("expected the outputs not to be read once the limit is reached")
+	}
+	if !matchesTaskSearch(context.Background(), k8sClient, task, SearchTerms("nightly report.md"), &reads) {
+		t.Error("expected the name and output file names to match without reading the outputs")
+	}
+}
+
 func TestTaskHandler_Get(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -158,6 +241,12 @@ func TestTaskHandler_Get(t *testing.T) {
 						AgentRef:    &kubeopenv1alpha1.AgentReference{Name: "my-agent"},
 						Description: ptr.To("do something"),
 					},
+					Status: kubeopenv1alpha1.TaskExecutionStatus{
+						Result: &kubeopenv1alpha1.TaskResult{
+							Status: kubeopenv1alpha1.TaskResultFailure,
+							Fields: &runtime.RawExtension{Raw: []byte(`{"approved":false}`)},
+						},
+					},
 				},
 			},
 			wantStatus: http.StatusOK,
@@ -206,6 +295,9 @@ func TestTaskHandler_Get(t *testing.T) {
 				if resp.Description != "do something" {
 					t.Errorf("expected description %q, got %q", "do something", resp.Description)
 				}
+				if resp.Result == nil || resp.Result.Status != "Failure" || resp.Result.Fields["approved"] != false {
+					t.Errorf("expected the task result, got %+v", resp.Result)
+				}
 			}
 		})
 	}
@@ -236,6 +328,16 @@ func TestTaskHandler_Create(t *testing.T) {
 			},
 			wantStatus: http.StatusCreated,
 		},
+		{
+			name: "creates task with params",
+			body: types.CreateTaskRequest{
+				Name:        "review",
+				Description: "Review ${params.repo}#${params.pr}",
+				TemplateRef: &types.AgentTemplateReference{Name: "pr-review"},
+				Params:      map[string]string{"repo": "org/x", "pr": "123"},
+			},
+			wantStatus: http.StatusCreated,
+		},
 		{
 			name: "validates description required",
 			body: types.CreateTaskRequest{
@@ -262,6 +364,19 @@ func TestTaskHandler_Create(t *testing.T) {
 			},
 			wantStatus: http.StatusBadRequest,
 		},
+		{
+			name: "validates context mount path conflicts",
+			body: types.CreateTaskRequest{
+				Name:        "conflict",
+				Description: "conflicting contexts",
+				AgentRef:    &types.AgentReference{Name: "my-agent"},
+				Contexts: []types.ContextItem{
+					{Name: "a", Type: "Text", Text: "a", MountPath: "notes.md"},
+					{Name: "b", Type: "Text", Text: "b", MountPath: "./notes.md"},
+				},
+			},
+			wantStatus: http.StatusBadRequest,
+		},
 	}
 
 	for _, tt := range tests {
@@ -295,6 +410,15 @@ func TestTaskHandler_Create(t *testing.T) {
 				if resp.Namespace != "default" {
 					t.Errorf("expected namespace %q, got %q", "default", resp.Namespace)
 				}
+
+				req := tt.body.(types.CreateTaskRequest)
+				var task kubeopenv1alpha1.Task
+				if err := k8sClient.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: req.Name}, &task); err != nil {
+					t.Fatalf("failed to get created task: %v", err)
+				}
+				if !equality.Semantic.DeepEqual(task.Spec.Params, req.Params) {
+					t.Errorf("expected params %v, got %v", req.Params, task.Spec.Params)
+				}
 			}
 		})
 	}
@@ -364,6 +488,121 @@ func TestTaskHandler_Delete(t *testing.T) {
 	}
 }
 
+func TestTaskHandler_Patch(t *testing.T) {
+	tests := []struct {
+		name            string
+		taskName        string
+		body            string
+		wantStatus      int
+		wantLabels      map[string]string
+		wantAnnotations map[string]string
+	}{
+		{
+			name:            "adds labels and annotations",
+			taskName:        "my-task",
+			body:            `{"metadata":{"labels":{"owner":"alice"},"annotations":{"example.com/reviewed":"true"}}}`,
+			wantStatus:      http.StatusOK,
+			wantLabels:      map[string]string{"team": "a", "owner": "alice", kubeopenv1alpha1.CronTaskLabelKey: "nightly"},
+			wantAnnotations: map[string]string{"example.com/reviewed": "true"},
+		},
+		{
+			name:       "null removes a label",
+			taskName:   "my-task",
+			body:       `{"metadata":{"labels":{"team":null}}}`,
+			wantStatus: http.StatusOK,
+			wantLabels: map[string]string{kubeopenv1alpha1.CronTaskLabelKey: "nightly"},
+		},
+		{
+			name:       "rejects reserved keys",
+			taskName:   "my-task",
+			body:       `{"metadata":{"annotations":{"kubeopencode.io/stop":"true"}}}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:            "sets the keep annotation",
+			taskName:        "my-task",
+			body:            `{"metadata":{"annotations":{"kubeopencode.io/keep":"true"}}}`,
+			wantStatus:      http.StatusOK,
+			wantLabels:      map[string]string{"team": "a", kubeopenv1alpha1.CronTaskLabelKey: "nightly"},
+			wantAnnotations: map[string]string{controller.AnnotationKeep: "true"},
+		},
+		{
+			name:       "clears the keep annotation",
+			taskName:   "my-task",
+			body:       `{"metadata":{"annotations":{"kubeopencode.io/keep":null}}}`,
+			wantStatus: http.StatusOK,
+			wantLabels: map[string]string{"team": "a", kubeopenv1alpha1.CronTaskLabelKey: "nightly"},
+		},
+		{
+			name:       "keep is not allowed as a label",
+			taskName:   "my-task",
+			body:       `{"metadata":{"labels":{"kubeopencode.io/keep":"true"}}}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "rejects spec changes",
+			taskName:   "my-task",
+			body:       `{"spec":{"description":"changed"}}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "rejects non-string values",
+			taskName:   "my-task",
+			body:       `{"metadata":{"labels":{"owner":1}}}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "task not found",
+			taskName:   "missing",
+			body:       `{"metadata":{"labels":{"owner":"alice"}}}`,
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k8sClient := fake.NewClientBuilder().
+				WithScheme(newTestScheme()).
+				WithObjects(&kubeopenv1alpha1.Task{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "my-task",
+						Namespace: "default",
+						Labels:    map[string]string{"team": "a", kubeopenv1alpha1.CronTaskLabelKey: "nightly"},
+					},
+				}).
+				Build()
+			handler := NewTaskHandler(k8sClient, nil, nil)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(tt.body))
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("namespace", "default")
+			rctx.URLParams.Add("name", tt.taskName)
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+			handler.Patch(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var resp types.TaskResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if !equality.Semantic.DeepEqual(resp.Labels, tt.wantLabels) {
+				t.Errorf("expected labels %v, got %v", tt.wantLabels, resp.Labels)
+			}
+			if !equality.Semantic.DeepEqual(resp.Annotations, tt.wantAnnotations) {
+				t.Errorf("expected annotations %v, got %v", tt.wantAnnotations, resp.Annotations)
+			}
+		})
+	}
+}
+
 func TestTaskHandler_Stop(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -449,3 +688,644 @@ func TestTaskHandler_Stop(t *testing.T) {
 		})
 	}
 }
+
+// staticCachedReader hands out the same reader for every resource
+type staticCachedReader struct{ reader client.Reader }
+
+func (s staticCachedReader) ReaderFor(context.Context, string, string) client.Reader {
+	return s.reader
+}
+
+func TestTaskHandler_ListFromIndexedCache(t *testing.T) {
+	task := func(name, agent string, phase kubeopenv1alpha1.TaskPhase) *kubeopenv1alpha1.Task {
+		return &kubeopenv1alpha1.Task{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       kubeopenv1alpha1.TaskSpec{AgentRef: &kubeopenv1alpha1.AgentReference{Name: agent}},
+			Status:     kubeopenv1alpha1.TaskExecutionStatus{Phase: phase},
+		}
+	}
+	objects := []runtime.Object{
+		task("a-running", "agent-a", kubeopenv1alpha1.TaskPhaseRunning),
+		task("a-failed", "agent-a", kubeopenv1alpha1.TaskPhaseFailed),
+		task("b-running", "agent-b", kubeopenv1alpha1.TaskPhaseRunning),
+	}
+
+	// The live client has no indexes: listing with field selectors would fail
+	liveClient := fake.NewClientBuilder().WithScheme(newTestScheme()).WithRuntimeObjects(objects...).Build()
+	builder := fake.NewClientBuilder().WithScheme(newTestScheme()).WithRuntimeObjects(objects...)
+	for field, extract := range map[string]client.IndexerFunc{
+		TaskPhaseField: func(obj client.Object) []string {
+			return []string{string(obj.(*kubeopenv1alpha1.Task).Status.Phase)}
+		},
+		TaskAgentRefField: func(obj client.Object) []string {
+			return []string{obj.(*kubeopenv1alpha1.Task).Spec.AgentRef.Name}
+		},
+	} {
+		builder = builder.WithIndex(&kubeopenv1alpha1.Task{}, field, extract)
+	}
+	cachedReader := builder.Build()
+	handler := NewTaskHandler(liveClient, nil, nil)
+
+	tests := []struct {
+		query     string
+		wantNames []string
+	}{
+		{query: "phase=running", wantNames: []string{"a-running", "b-running"}},
+		{query: "agent=agent-a", wantNames: []string{"a-failed", "a-running"}},
+		{query: "agent=agent-a&phase=Running", wantNames: []string{"a-running"}},
+		{query: "phase=Running,Failed&agent=agent-a", wantNames: []string{"a-failed", "a-running"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.URL = &url.URL{Path: "/", RawQuery: tt.query}
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("namespace", "default")
+			ctx := context.WithValue(r.Context(), chi.RouteCtxKey, rctx)
+			ctx = context.WithValue(ctx, CachedReaderContextKey{}, CachedReader(staticCachedReader{cachedReader}))
+			r = r.WithContext(ctx)
+
+			handler.List(w, r)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+			}
+			var resp types.TaskListResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			var names []string
+			for _, task := range resp.Tasks {
+				names = append(names, task.Name)
+			}
+			sort.Strings(names)
+			if strings.Join(names, ",") != strings.Join(tt.wantNames, ",") {
+				t.Errorf("expected tasks %v, got %v", tt.wantNames, names)
+			}
+		})
+	}
+}
+
+func TestTaskHandler_Retry(t *testing.T) {
+	finished := func(name string, phase kubeopenv1alpha1.TaskPhase) *kubeopenv1alpha1.Task {
+		description := "original description"
+		return &kubeopenv1alpha1.Task{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Labels: map[string]string{
+					"team":                            "platform",
+					kubeopenv1alpha1.CronTaskLabelKey: "nightly",
+				},
+				Annotations: map[string]string{
+					tracing.TraceParentAnnotation: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+				},
+			},
+			Spec: kubeopenv1alpha1.TaskSpec{
+				AgentRef:    &kubeopenv1alpha1.AgentReference{Name: "a"},
+				Description: &description,
+			},
+			Status: kubeopenv1alpha1.TaskExecutionStatus{Phase: phase},
+		}
+	}
+
+	tests := []struct {
+		name            string
+		taskName        string
+		body            string
+		objects         []runtime.Object
+		wantStatus      int
+		wantDescription string
+	}{
+		{
+			name:            "retries failed task",
+			taskName:        "failed-task",
+			objects:         []runtime.Object{finished("failed-task", kubeopenv1alpha1.TaskPhaseFailed)},
+			wantStatus:      http.StatusCreated,
+			wantDescription: "original description",
+		},
+		{
+			name:            "retries completed task with edited description",
+			taskName:        "completed-task",
+			body:            `{"description":"try again"}`,
+			objects:         []runtime.Object{finished("completed-task", kubeopenv1alpha1.TaskPhaseCompleted)},
+			wantStatus:      http.StatusCreated,
+			wantDescription: "try again",
+		},
+		{
+			name:       "rejects running task",
+			taskName:   "running-task",
+			objects:    []runtime.Object{finished("running-task", kubeopenv1alpha1.TaskPhaseRunning)},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "handles not found",
+			taskName:   "missing",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k8sClient := fake.NewClientBuilder().
+				WithScheme(newTestScheme()).
+				WithRuntimeObjects(tt.objects...).
+				WithStatusSubresource(&kubeopenv1alpha1.Task{}).
+				Build()
+			handler := NewTaskHandler(k8sClient, nil, nil)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("namespace", "default")
+			rctx.URLParams.Add("name", tt.taskName)
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+			handler.Retry(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusCreated {
+				return
+			}
+
+			var resp types.TaskResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			var task kubeopenv1alpha1.Task
+			if err := k8sClient.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: resp.Name}, &task); err != nil {
+				t.Fatalf("failed to get retried task: %v", err)
+			}
+			if !strings.HasPrefix(task.Name, tt.taskName+"-retry-") {
+				t.Errorf("unexpected retried task name %q", task.Name)
+			}
+			if got := task.Labels[kubeopenv1alpha1.TaskRetryOfLabelKey]; got != tt.taskName {
+				t.Errorf("expected retry-of label %q, got %q", tt.taskName, got)
+			}
+			if task.Labels["team"] != "platform" {
+				t.Errorf("expected user labels to be copied, got %v", task.Labels)
+			}
+			if _, ok := task.Labels[kubeopenv1alpha1.CronTaskLabelKey]; ok {
+				t.Errorf("expected CronTask label to be dropped, got %v", task.Labels)
+			}
+			if _, ok := task.Annotations[tracing.TraceParentAnnotation]; ok {
+				t.Errorf("expected the retried task's trace not to be kept, got %v", task.Annotations)
+			}
+			if task.Spec.Description == nil || *task.Spec.Description != tt.wantDescription {
+				t.Errorf("expected description %q, got %v", tt.wantDescription, task.Spec.Description)
+			}
+		})
+	}
+}
+
+func TestTaskHandler_GetOutputs(t *testing.T) {
+	withOutputs := &kubeopenv1alpha1.Task{
+		ObjectMeta: metav1.ObjectMeta{Name: "done", Namespace: "default"},
+		Status: kubeopenv1alpha1.TaskExecutionStatus{
+			Phase: kubeopenv1alpha1.TaskPhaseCompleted,
+			Outputs: &kubeopenv1alpha1.TaskOutputsStatus{
+				ConfigMapName: "done-outputs",
+				Files:         []string{"result.json"},
+			},
+		},
+	}
+	noOutputs := &kubeopenv1alpha1.Task{
+		ObjectMeta: metav1.ObjectMeta{Name: "plain", Namespace: "default"},
+		Status:     kubeopenv1alpha1.TaskExecutionStatus{Phase: kubeopenv1alpha1.TaskPhaseCompleted},
+	}
+	outputsCM := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "done-outputs", Namespace: "default"},
+		Data:       map[string]string{"result.json": `{"ok":true}`},
+	}
+	k8sClient := fake.NewClientBuilder().
+		WithScheme(newTestScheme()).
+		WithRuntimeObjects(withOutputs, noOutputs, outputsCM).
+		WithStatusSubresource(&kubeopenv1alpha1.Task{}).
+		Build()
+	handler := NewTaskHandler(k8sClient, nil, nil)
+
+	serve := func(h http.HandlerFunc, taskName, file string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("namespace", "default")
+		rctx.URLParams.Add("name", taskName)
+		if file != "" {
+			rctx.URLParams.Add("file", file)
+		}
+		r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+		h(w, r)
+		return w
+	}
+
+	t.Run("returns outputs as JSON", func(t *testing.T) {
+		w := serve(handler.GetOutputs, "done", "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var resp types.TaskOutputsResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if resp.ConfigMapName != "done-outputs" || resp.Files["result.json"] != `{"ok":true}` {
+			t.Errorf("unexpected outputs response: %+v", resp)
+		}
+	})
+
+	t.Run("downloads a single file", func(t *testing.T) {
+		w := serve(handler.GetOutputFile, "done", "result.json")
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		if w.Body.String() != `{"ok":true}` {
+			t.Errorf("unexpected file content %q", w.Body.String())
+		}
+	})
+
+	tests := []struct {
+		name     string
+		handler  http.HandlerFunc
+		taskName string
+		file     string
+	}{
+		{name: "missing task", handler: handler.GetOutputs, taskName: "missing"},
+		{name: "task without outputs", handler: handler.GetOutputs, taskName: "plain"},
+		{name: "missing file", handler: handler.GetOutputFile, taskName: "done", file: "other.txt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := serve(tt.handler, tt.taskName, tt.file); w.Code != http.StatusNotFound {
+				t.Errorf("expected status %d, got %d: %s", http.StatusNotFound, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestTaskHandler_UploadOutputs(t *testing.T) {
+	var archive bytes.Buffer
+	gz := gzip.NewWriter(&archive)
+	tw := tar.NewWriter(gz)
+	content := `{"status":"Success"}`
+	if err := tw.WriteHeader(&tar.Header{Name: "./result.json", Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	podUser := &authmiddleware.UserInfo{
+		Username: "system:serviceaccount:default:kubeopencode-agent",
+		Extra:    map[string][]string{"authentication.kubernetes.io/pod-name": {"review-pod"}},
+	}
+	tests := []struct {
+		name       string
+		user       *authmiddleware.UserInfo
+		phase      kubeopenv1alpha1.TaskPhase
+		wantStatus int
+	}{
+		{name: "stores outputs uploaded by the task pod", user: podUser, phase: kubeopenv1alpha1.TaskPhaseRunning, wantStatus: http.StatusNoContent},
+		{name: "requires authentication", phase: kubeopenv1alpha1.TaskPhaseRunning, wantStatus: http.StatusUnauthorized},
+		{
+			name:       "rejects other pods",
+			user:       &authmiddleware.UserInfo{Username: podUser.Username, Extra: map[string][]string{"authentication.kubernetes.io/pod-name": {"other-pod"}}},
+			phase:      kubeopenv1alpha1.TaskPhaseRunning,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "rejects users",
+			user:       &authmiddleware.UserInfo{Username: "alice", Extra: podUser.Extra},
+			phase:      kubeopenv1alpha1.TaskPhaseRunning,
+			wantStatus: http.StatusForbidden,
+		},
+		{name: "rejects finished tasks", user: podUser, phase: kubeopenv1alpha1.TaskPhaseCompleted, wantStatus: http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &kubeopenv1alpha1.Task{
+				ObjectMeta: metav1.ObjectMeta{Name: "review", Namespace: "default", UID: "review-uid"},
+				Spec:       kubeopenv1alpha1.TaskSpec{CaptureOutputs: true},
+				Status:     kubeopenv1alpha1.TaskExecutionStatus{Phase: tt.phase, PodName: "review-pod"},
+			}
+			k8sClient := fake.NewClientBuilder().WithScheme(newTestScheme()).WithRuntimeObjects(task).Build()
+			handler := NewTaskHandler(k8sClient, nil, nil)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(archive.Bytes()))
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("namespace", "default")
+			rctx.URLParams.Add("name", "review")
+			ctx := context.WithValue(r.Context(), chi.RouteCtxKey, rctx)
+			if tt.user != nil {
+				ctx = context.WithValue(ctx, authmiddleware.UserInfoKey, tt.user)
+			}
+			handler.UploadOutputs(w, r.WithContext(ctx))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			var cm corev1.ConfigMap
+			err := k8sClient.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "review-outputs"}, &cm)
+			if tt.wantStatus != http.StatusNoContent {
+				if err == nil {
+					t.Error("outputs ConfigMap should not be created")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("outputs ConfigMap not created: %v", err)
+			}
+			if cm.Data["result.json"] != content || !metav1.IsControlledBy(&cm, task) {
+				t.Errorf("unexpected outputs ConfigMap: %+v", cm)
+			}
+		})
+	}
+}
+
+func TestTaskHandler_DownloadLogs(t *testing.T) {
+	tests := []struct {
+		name       string
+		taskName   string
+		query      string
+		wantStatus int
+		wantFile   string
+	}{
+		{
+			name:       "downloads agent logs",
+			taskName:   "running",
+			query:      "tailLines=100&sinceTime=2026-01-01T00:00:00Z&timestamps=true",
+			wantStatus: http.StatusOK,
+			wantFile:   "running-agent.log",
+		},
+		{
+			name:       "previous container logs",
+			taskName:   "running",
+			query:      "container=executor&previous=true",
+			wantStatus: http.StatusOK,
+			wantFile:   "running-executor-previous.log",
+		},
+		{
+			name:       "rejects invalid tailLines",
+			taskName:   "running",
+			query:      "tailLines=-1",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "rejects invalid sinceTime",
+			taskName:   "running",
+			query:      "sinceTime=yesterday",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "task without pod",
+			taskName:   "pending",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "task not found",
+			taskName:   "missing",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k8sClient := fake.NewClientBuilder().
+				WithScheme(newTestScheme()).
+				WithObjects(
+					&kubeopenv1alpha1.Task{
+						ObjectMeta: metav1.ObjectMeta{Name: "running", Namespace: "default"},
+						Status:     kubeopenv1alpha1.TaskExecutionStatus{PodName: "running-pod"},
+					},
+					&kubeopenv1alpha1.Task{
+						ObjectMeta: metav1.ObjectMeta{Name: "pending", Namespace: "default"},
+					},
+				).
+				Build()
+			clientset := k8sfake.NewSimpleClientset(&corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "running-pod", Namespace: "default"},
+			})
+			handler := NewTaskHandler(k8sClient, clientset, nil)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/?"+tt.query, nil)
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("namespace", "default")
+			rctx.URLParams.Add("name", tt.taskName)
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+			handler.DownloadLogs(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+				t.Errorf("expected text/plain, got %q", ct)
+			}
+			if cd := w.Header().Get("Content-Disposition"); !strings.Contains(cd, tt.wantFile) {
+				t.Errorf("expected filename %q, got %q", tt.wantFile, cd)
+			}
+			if w.Body.Len() == 0 {
+				t.Error("expected log content")
+			}
+		})
+	}
+}
+
+func TestTaskHandler_GetLogs(t *testing.T) {
+	tests := []struct {
+		name        string
+		lastEventID string
+		wantStatus  int
+		wantLog     bool
+	}{
+		{
+			name:       "streams lines with line number IDs",
+			wantStatus: http.StatusOK,
+			wantLog:    true,
+		},
+		{
+			name:        "resumes after Last-Event-ID",
+			lastEventID: "1",
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:        "rejects invalid Last-Event-ID",
+			lastEventID: "abc",
+			wantStatus:  http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "running-pod", Namespace: "default"}}
+			k8sClient := fake.NewClientBuilder().
+				WithScheme(newTestScheme()).
+				WithObjects(
+					&kubeopenv1alpha1.Task{
+						ObjectMeta: metav1.ObjectMeta{Name: "running", Namespace: "default"},
+						Status:     kubeopenv1alpha1.TaskExecutionStatus{Phase: kubeopenv1alpha1.TaskPhaseRunning, PodName: "running-pod"},
+					},
+					pod.DeepCopy(),
+				).
+				Build()
+			// The fake clientset returns the single line "fake logs"
+			handler := NewTaskHandler(k8sClient, k8sfake.NewSimpleClientset(pod), nil)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/?follow=false", nil)
+			if tt.lastEventID != "" {
+				r.Header.Set("Last-Event-ID", tt.lastEventID)
+			}
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("namespace", "default")
+			rctx.URLParams.Add("name", "running")
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+			handler.GetLogs(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			body := w.Body.String()
+			hasLog := strings.Contains(body, `id: 1`+"\n"+`data: {"type":"log","content":"fake logs"}`)
+			if hasLog != tt.wantLog {
+				t.Errorf("expected log line event %v, got body %s", tt.wantLog, body)
+			}
+			if !strings.Contains(body, `"type":"complete"`) {
+				t.Errorf("expected complete event, got body %s", body)
+			}
+		})
+	}
+}
+
+func TestTaskHandler_Watch(t *testing.T) {
+	k8sClient := fake.NewClientBuilder().WithScheme(newTestScheme()).WithStatusSubresource(&kubeopenv1alpha1.Task{}).Build()
+	handler := NewTaskHandler(k8sClient, nil, nil)
+
+	router := chi.NewRouter()
+	router.Get("/api/v1/namespaces/{namespace}/tasks/watch", handler.Watch)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/v1/namespaces/default/tasks/watch?name=keep", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("watch request failed: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("unexpected response: %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	// The watch is established once headers are sent
+	for _, name := range []string{"skipped-by-name", "keep-me"} {
+		task := &kubeopenv1alpha1.Task{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+		if err := k8sClient.Create(context.Background(), task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	task := &kubeopenv1alpha1.Task{}
+	_ = k8sClient.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "keep-me"}, task)
+	task.Status.Phase = kubeopenv1alpha1.TaskPhaseRunning
+	if err := k8sClient.Status().Update(context.Background(), task); err != nil {
+		t.Fatal(err)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	var events []types.TaskWatchEvent
+	for len(events) < 2 && scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		var event types.TaskWatchEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			t.Fatalf("invalid event %q: %v", data, err)
+		}
+		events = append(events, event)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2", len(events))
+	}
+	if events[0].Type != "ADDED" || events[0].Task.Name != "keep-me" || events[0].ResourceVersion == "" {
+		t.Errorf("first event = %+v, want ADDED keep-me with resourceVersion", events[0])
+	}
+	if events[1].Type != "MODIFIED" || events[1].Task.Phase != string(kubeopenv1alpha1.TaskPhaseRunning) {
+		t.Errorf("second event = %+v, want MODIFIED Running", events[1])
+	}
+}
+
+func TestTaskHandler_Exec(t *testing.T) {
+	tests := []struct {
+		name       string
+		objects    []runtime.Object
+		wantStatus int
+	}{
+		{
+			name:       "task not found",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name: "task without pod",
+			objects: []runtime.Object{
+				&kubeopenv1alpha1.Task{ObjectMeta: metav1.ObjectMeta{Name: "my-task", Namespace: "default"}},
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "pod not running",
+			objects: []runtime.Object{
+				&kubeopenv1alpha1.Task{
+					ObjectMeta: metav1.ObjectMeta{Name: "my-task", Namespace: "default"},
+					Status:     kubeopenv1alpha1.TaskExecutionStatus{PodName: "my-task-pod"},
+				},
+				&corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{Name: "my-task-pod", Namespace: "default"},
+					Status:     corev1.PodStatus{Phase: corev1.PodSucceeded},
+				},
+			},
+			wantStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k8sClient := fake.NewClientBuilder().WithScheme(newTestScheme()).WithRuntimeObjects(tt.objects...).Build()
+			handler := NewTaskHandler(k8sClient, nil, nil)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/namespaces/default/tasks/my-task/exec", nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("namespace", "default")
+			rctx.URLParams.Add("name", "my-task")
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+			handler.Exec(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package handlers
+
+import (
+	"context"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+)
+
+// Field indexes registered on the server's Task cache
+const (
+	// TaskPhaseField indexes Tasks by status.phase
+	TaskPhaseField = "status.phase"
+	// TaskAgentRefField indexes Tasks by spec.agentRef.name
+	TaskAgentRefField = "spec.agentRef.name"
+)
+
+// taskPhases are the phases that can be looked up in the phase index
+var taskPhases = []kubeopenv1alpha1.TaskPhase{
+	kubeopenv1alpha1.TaskPhasePending,
+	kubeopenv1alpha1.TaskPhaseQueued,
+	kubeopenv1alpha1.TaskPhaseRunning,
+	kubeopenv1alpha1.TaskPhaseCompleted,
+	kubeopenv1alpha1.TaskPhaseFailed,
+}
+
+// IndexTaskFields registers the Task field indexes used to filter lists in the cache.
+func IndexTaskFields(ctx context.Context, indexer client.FieldIndexer) error {
+	if err := indexer.IndexField(ctx, &kubeopenv1alpha1.Task{}, TaskPhaseField, func(obj client.Object) []string {
+		return []string{string(obj.(*kubeopenv1alpha1.Task).Status.Phase)}
+	}); err != nil {
+		return err
+	}
+	return indexer.IndexField(ctx, &kubeopenv1alpha1.Task{}, TaskAgentRefField, func(obj client.Object) []string {
+		if ref := obj.(*kubeopenv1alpha1.Task).Spec.AgentRef; ref != nil {
+			return []string{ref.Name}
+		}
+		return nil
+	})
+}
+
+// taskIndexListOptions returns the field selectors for filters that can be served by
+// the Task indexes. Filters that cannot (e.g. several phases) are applied in memory only.
+func taskIndexListOptions(opts *FilterOptions) []client.ListOption {
+	fields := client.MatchingFields{}
+	if opts.Phase != "" && !strings.Contains(opts.Phase, ",") {
+		for _, phase := range taskPhases {
+			if strings.EqualFold(string(phase), strings.TrimSpace(opts.Phase)) {
+				fields[TaskPhaseField] = string(phase)
+			}
+		}
+	}
+	if opts.Agent != "" {
+		fields[TaskAgentRefField] = opts.Agent
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return []client.ListOption{fields}
+}
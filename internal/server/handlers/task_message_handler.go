@@ -0,0 +1,188 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+	"github.com/kubeopencode/kubeopencode/internal/controller"
+	authmiddleware "github.com/kubeopencode/kubeopencode/internal/server/middleware"
+	"github.com/kubeopencode/kubeopencode/internal/server/types"
+)
+
+var messageLog = ctrl.Log.WithName("task-message")
+
+const (
+	// maxTaskMessageBytes limits the size of a follow-up message request body.
+	maxTaskMessageBytes = 64 << 10
+
+	// taskMessageDeliveryTimeout bounds delivery to the OpenCode server or the task Pod.
+	taskMessageDeliveryTimeout = 30 * time.Second
+)
+
+// TaskMessageHandler delivers follow-up instructions to running Tasks.
+// Tasks running on a server-mode Agent receive the message as a new prompt in their
+// OpenCode session. Pod-mode Tasks have no session to talk to, so the message is
+// written into the workspace of the task Pod instead, to controller.TaskMessagesDir,
+// which the agent's context file tells it to check.
+type TaskMessageHandler struct {
+	defaultClient client.Client
+	restConfig    *rest.Config
+	clusterDomain string
+	opencode      *controller.OpenCodeClient
+}
+
+// NewTaskMessageHandler creates a new TaskMessageHandler.
+func NewTaskMessageHandler(c client.Client, restConfig *rest.Config, clusterDomain string) *TaskMessageHandler {
+	return &TaskMessageHandler{
+		defaultClient: c,
+		restConfig:    restConfig,
+		clusterDomain: clusterDomain,
+		opencode:      controller.NewOpenCodeClient(),
+	}
+}
+
+// SendMessage delivers a follow-up message to a running Task.
+// Route: POST /api/v1/namespaces/{namespace}/tasks/{name}/messages
+func (h *TaskMessageHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
+	namespace := chi.URLParam(r, "namespace")
+	name := chi.URLParam(r, "name")
+	ctx := r.Context()
+	k8sClient := clientFromContext(ctx, h.defaultClient)
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxTaskMessageBytes)
+	var req types.SendTaskMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+	if strings.TrimSpace(req.Message) == "" {
+		writeError(w, http.StatusBadRequest, "Message is required", "")
+		return
+	}
+
+	var task kubeopenv1alpha1.Task
+	if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, &task); err != nil {
+		writeError(w, http.StatusNotFound, "Task not found", err.Error())
+		return
+	}
+	if task.Status.Phase != kubeopenv1alpha1.TaskPhaseRunning {
+		writeError(w, http.StatusConflict, "Task is not running", fmt.Sprintf("Task phase is %s", task.Status.Phase))
+		return
+	}
+
+	deliveryCtx, cancel := context.WithTimeout(ctx, taskMessageDeliveryTimeout)
+	defer cancel()
+
+	resp := types.TaskMessageResponse{Name: name, Namespace: namespace}
+
+	// Session info is only recorded for Tasks running on a server-mode Agent
+	if task.Status.Session != nil {
+		if task.Status.Session.ID == "" || task.Status.AgentRef == nil {
+			writeError(w, http.StatusConflict, "Task session is not available yet", "the OpenCode session has not been resolved; retry shortly")
+			return
+		}
+		serverURL, err := resolveAgentServerURL(deliveryCtx, k8sClient, namespace, task.Status.AgentRef.Name, h.clusterDomain)
+		if err != nil {
+			writeError(w, http.StatusBadGateway, "Cannot resolve agent server", err.Error())
+			return
+		}
+		if err := h.opencode.SendPrompt(deliveryCtx, serverURL, task.Status.Session.ID, req.Message); err != nil {
+			messageLog.Error(err, "Failed to send message to session", "namespace", namespace, "task", name)
+			writeError(w, http.StatusBadGateway, "Failed to send message", err.Error())
+			return
+		}
+		resp.Delivery = "session"
+	} else {
+		path, err := h.writeWorkspaceMessage(deliveryCtx, &task, req.Message)
+		if err != nil {
+			messageLog.Error(err, "Failed to write message to workspace", "namespace", namespace, "task", name)
+			writeError(w, http.StatusBadGateway, "Failed to write message to workspace", err.Error())
+			return
+		}
+		resp.Delivery = "workspace"
+		resp.Path = path
+	}
+
+	messageLog.Info("Delivered task message", "namespace", namespace, "task", name, "delivery", resp.Delivery)
+	writeJSON(w, http.StatusAccepted, resp)
+}
+
+// writeWorkspaceMessage writes message to a new file under controller.TaskMessagesDir in the
+// agent container of the task Pod, using the caller's identity for the exec.
+// It returns the absolute path of the file.
+func (h *TaskMessageHandler) writeWorkspaceMessage(ctx context.Context, task *kubeopenv1alpha1.Task, message string) (string, error) {
+	if task.Status.PodName == "" {
+		return "", fmt.Errorf("task %q has no pod yet", task.Name)
+	}
+	if h.restConfig == nil {
+		return "", fmt.Errorf("pod exec is not configured")
+	}
+
+	execConfig := rest.CopyConfig(h.restConfig)
+	if userInfo := authmiddleware.GetUserInfo(ctx); userInfo != nil {
+		execConfig.Impersonate = rest.ImpersonationConfig{
+			UserName: userInfo.Username,
+			UID:      userInfo.UID,
+			Groups:   userInfo.Groups,
+		}
+	}
+	execClientset, err := kubernetes.NewForConfig(execConfig)
+	if err != nil {
+		return "", fmt.Errorf("creating clientset: %w", err)
+	}
+
+	execReq := execClientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(task.Status.PodName).
+		Namespace(task.Namespace).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: "agent",
+			Command:   workspaceMessageCommand(time.Now()),
+			Stdin:     true,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(execConfig, "POST", execReq.URL())
+	if err != nil {
+		return "", fmt.Errorf("creating executor: %w", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if err := executor.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdin:  strings.NewReader(message),
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}); err != nil {
+		return "", fmt.Errorf("exec failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// workspaceMessageCommand returns the command that stores stdin as a message file
+// under controller.TaskMessagesDir and prints its path. The agent container's working
+// directory is the workspace, so the directory is resolved relative to it.
+func workspaceMessageCommand(now time.Time) []string {
+	file := fmt.Sprintf("%s/%s.md", controller.TaskMessagesDir, now.UTC().Format("20060102T150405.000000000Z"))
+	return []string{"/bin/sh", "-c",
+		fmt.Sprintf(`mkdir -p %s && cat > %s && echo "$PWD/%s"`, controller.TaskMessagesDir, file, file)}
+}
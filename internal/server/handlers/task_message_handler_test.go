@@ -0,0 +1,135 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+)
+
+func TestTaskMessageHandler_SendMessage(t *testing.T) {
+	runningTask := func(status kubeopenv1alpha1.TaskExecutionStatus) *kubeopenv1alpha1.Task {
+		status.Phase = kubeopenv1alpha1.TaskPhaseRunning
+		return &kubeopenv1alpha1.Task{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-task", Namespace: "default"},
+			Status:     status,
+		}
+	}
+
+	tests := []struct {
+		name       string
+		body       string
+		objects    []runtime.Object
+		wantStatus int
+		wantError  string
+	}{
+		{
+			name:       "rejects invalid body",
+			body:       `{"message":`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  "Invalid request body",
+		},
+		{
+			name:       "rejects empty message",
+			body:       `{"message":"  "}`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  "Message is required",
+		},
+		{
+			name:       "task not found",
+			body:       `{"message":"hi"}`,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name: "task not running",
+			body: `{"message":"hi"}`,
+			objects: []runtime.Object{&kubeopenv1alpha1.Task{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-task", Namespace: "default"},
+				Status:     kubeopenv1alpha1.TaskExecutionStatus{Phase: kubeopenv1alpha1.TaskPhaseCompleted},
+			}},
+			wantStatus: http.StatusConflict,
+			wantError:  "Task is not running",
+		},
+		{
+			name: "session not resolved yet",
+			body: `{"message":"hi"}`,
+			objects: []runtime.Object{runningTask(kubeopenv1alpha1.TaskExecutionStatus{
+				AgentRef: &kubeopenv1alpha1.AgentReference{Name: "my-agent"},
+				Session:  &kubeopenv1alpha1.SessionInfo{Title: "kubeopencode/default/my-task"},
+			})},
+			wantStatus: http.StatusConflict,
+			wantError:  "Task session is not available yet",
+		},
+		{
+			name: "suspended agent",
+			body: `{"message":"hi"}`,
+			objects: []runtime.Object{
+				runningTask(kubeopenv1alpha1.TaskExecutionStatus{
+					AgentRef: &kubeopenv1alpha1.AgentReference{Name: "my-agent"},
+					Session:  &kubeopenv1alpha1.SessionInfo{ID: "ses_1"},
+				}),
+				&kubeopenv1alpha1.Agent{
+					ObjectMeta: metav1.ObjectMeta{Name: "my-agent", Namespace: "default"},
+					Status: kubeopenv1alpha1.AgentStatus{
+						Suspended: true,
+						URL:       "http://my-agent.default.svc.cluster.local:4096",
+					},
+				},
+			},
+			wantStatus: http.StatusBadGateway,
+			wantError:  "Cannot resolve agent server",
+		},
+		{
+			name:       "pod-mode task without pod",
+			body:       `{"message":"hi"}`,
+			objects:    []runtime.Object{runningTask(kubeopenv1alpha1.TaskExecutionStatus{})},
+			wantStatus: http.StatusBadGateway,
+			wantError:  "Failed to write message to workspace",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k8sClient := fake.NewClientBuilder().
+				WithScheme(newTestScheme()).
+				WithRuntimeObjects(tt.objects...).
+				Build()
+			handler := NewTaskMessageHandler(k8sClient, nil, "cluster.local")
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("namespace", "default")
+			rctx.URLParams.Add("name", "my-task")
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+
+			handler.SendMessage(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantError != "" && !strings.Contains(w.Body.String(), tt.wantError) {
+				t.Errorf("expected error %q in body, got %s", tt.wantError, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestWorkspaceMessageCommand(t *testing.T) {
+	cmd := workspaceMessageCommand(time.Date(2026, 1, 2, 3, 4, 5, 6, time.UTC))
+	want := `mkdir -p .kubeopencode/messages && cat > .kubeopencode/messages/20260102T030405.000000006Z.md && echo "$PWD/.kubeopencode/messages/20260102T030405.000000006Z.md"`
+	if len(cmd) != 3 || cmd[2] != want {
+		t.Errorf("unexpected command: %q", cmd)
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr/funcr"
+)
+
+func TestAudit(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		user       *UserInfo
+		status     int
+		wantLogged bool
+		wantFields []string
+	}{
+		{
+			name:       "skips read-only requests",
+			method:     http.MethodGet,
+			path:       "/api/v1/namespaces/default/tasks/t1",
+			status:     http.StatusOK,
+			wantLogged: false,
+		},
+		{
+			name:       "records task creation",
+			method:     http.MethodPost,
+			path:       "/api/v1/namespaces/default/tasks",
+			user:       &UserInfo{Username: "jane", Groups: []string{"dev"}},
+			status:     http.StatusCreated,
+			wantLogged: true,
+			wantFields: []string{`"user"="jane"`, `"verb"="create"`, `"namespace"="default"`, `"resource"="tasks"`, `"status"=201`, `"result"="success"`},
+		},
+		{
+			name:       "records action as verb",
+			method:     http.MethodPost,
+			path:       "/api/v1/namespaces/default/tasks/t1/stop",
+			user:       &UserInfo{Username: "jane"},
+			status:     http.StatusBadRequest,
+			wantLogged: true,
+			wantFields: []string{`"verb"="stop"`, `"name"="t1"`, `"subresource"="stop"`, `"result"="failure"`},
+		},
+		{
+			name:       "records anonymous deletion",
+			method:     http.MethodDelete,
+			path:       "/api/v1/namespaces/team-a/crontasks/nightly",
+			status:     http.StatusNoContent,
+			wantLogged: true,
+			wantFields: []string{`"user"="anonymous"`, `"verb"="delete"`, `"namespace"="team-a"`, `"resource"="crontasks"`, `"name"="nightly"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logged []string
+			logger := funcr.New(func(prefix, args string) { logged = append(logged, args) }, funcr.Options{})
+
+			handler := Audit(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.user != nil {
+				req = req.WithContext(context.WithValue(req.Context(), UserInfoKey, tt.user))
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, rec.Code)
+			}
+			if !tt.wantLogged {
+				if len(logged) != 0 {
+					t.Errorf("expected no audit entry, got %v", logged)
+				}
+				return
+			}
+			if len(logged) != 1 {
+				t.Fatalf("expected one audit entry, got %v", logged)
+			}
+			for _, field := range tt.wantFields {
+				if !strings.Contains(logged[0], field) {
+					t.Errorf("expected audit entry to contain %s, got %s", field, logged[0])
+				}
+			}
+		})
+	}
+}
@@ -29,6 +29,9 @@ type UserInfo struct {
 	Username string
 	UID      string
 	Groups   []string
+	// Extra holds additional attributes from the TokenReview, such as the
+	// Pod a ServiceAccount token is bound to
+	Extra map[string][]string
 }
 
 // AuthConfig holds authentication configuration
@@ -37,6 +40,9 @@ type AuthConfig struct {
 	Enabled bool
 	// AllowAnonymous allows unauthenticated requests (for development)
 	AllowAnonymous bool
+	// OIDC validates ID tokens from an OpenID provider. Tokens from other issuers
+	// are validated with the TokenReview API. Nil disables OIDC.
+	OIDC *OIDCVerifier
 }
 
 // Auth creates an authentication middleware
@@ -68,6 +74,18 @@ func Auth(clientset kubernetes.Interface, config AuthConfig) func(http.Handler)
 
 			token := parts[1]
 
+			if config.OIDC != nil && config.OIDC.Accepts(token) {
+				userInfo, err := config.OIDC.Verify(r.Context(), token)
+				if err != nil {
+					log.V(1).Info("Rejected OIDC token", "reason", err.Error())
+					http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+					return
+				}
+				ctx := context.WithValue(r.Context(), UserInfoKey, userInfo)
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
 			// Validate token using TokenReview API
 			tokenReview := &authv1.TokenReview{
 				Spec: authv1.TokenReviewSpec{
@@ -97,6 +115,12 @@ func Auth(clientset kubernetes.Interface, config AuthConfig) func(http.Handler)
 				UID:      result.Status.User.UID,
 				Groups:   result.Status.User.Groups,
 			}
+			for key, values := range result.Status.User.Extra {
+				if userInfo.Extra == nil {
+					userInfo.Extra = map[string][]string{}
+				}
+				userInfo.Extra[key] = values
+			}
 
 			ctx := context.WithValue(r.Context(), UserInfoKey, &userInfo)
 			next.ServeHTTP(w, r.WithContext(ctx))
@@ -0,0 +1,477 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package middleware
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// oidcClockSkew is the tolerated clock difference when checking exp and nbf
+	oidcClockSkew = time.Minute
+
+	// oidcKeyRefreshInterval bounds how often the signing keys are refetched
+	// when a token references an unknown key ID or the last fetch failed
+	oidcKeyRefreshInterval = time.Minute
+
+	// oidcNoPrefix disables the default username prefix, as in kube-apiserver
+	oidcNoPrefix = "-"
+
+	// reservedIdentityPrefix is the prefix of Kubernetes system users and groups,
+	// which OIDC identities must never be impersonated as
+	reservedIdentityPrefix = "system:"
+)
+
+// OIDCConfig configures authentication with OpenID Connect ID tokens.
+// The fields mirror the kube-apiserver --oidc-* flags, including the default
+// username prefix. Usernames and groups starting with "system:" after prefixing
+// are rejected, so IdP users cannot be impersonated as Kubernetes system identities.
+type OIDCConfig struct {
+	// IssuerURL is the https URL of the OpenID provider; it must match the iss claim
+	IssuerURL string
+	// ClientID is the client ID tokens are issued for
+	ClientID string
+	// Audience is the accepted aud claim. Defaults to ClientID.
+	Audience string
+	// UsernameClaim is the claim used as the Kubernetes username. Defaults to "sub".
+	UsernameClaim string
+	// UsernamePrefix is prepended to usernames, e.g. "oidc:". Defaults to "<issuer>#"
+	// unless UsernameClaim is "email"; "-" disables prefixing.
+	UsernamePrefix string
+	// GroupsClaim is the claim holding the user's groups (string or list). Empty disables groups.
+	GroupsClaim string
+	// GroupsPrefix is prepended to group names, e.g. "oidc:"
+	GroupsPrefix string
+}
+
+// OIDCVerifier validates ID tokens issued by an OpenID provider.
+// The provider's signing keys are discovered on first use and refreshed on key rotation.
+type OIDCVerifier struct {
+	config OIDCConfig
+	client *http.Client
+
+	// fetches lets concurrent requests share a single key fetch
+	fetches singleflight.Group
+
+	mu   sync.Mutex
+	keys map[string]*oidcKey
+	// lastFetch is the time of the last fetch attempt, and fetchErr its error
+	lastFetch time.Time
+	fetchErr  error
+}
+
+// NewOIDCVerifier creates a verifier for the given configuration.
+func NewOIDCVerifier(config OIDCConfig) (*OIDCVerifier, error) {
+	if !strings.HasPrefix(config.IssuerURL, "https://") {
+		return nil, fmt.Errorf("OIDC issuer URL must use https: %q", config.IssuerURL)
+	}
+	if config.ClientID == "" {
+		return nil, errors.New("OIDC client ID is required")
+	}
+	if config.Audience == "" {
+		config.Audience = config.ClientID
+	}
+	if config.UsernameClaim == "" {
+		config.UsernameClaim = "sub"
+	}
+	switch {
+	case config.UsernamePrefix == oidcNoPrefix:
+		config.UsernamePrefix = ""
+	case config.UsernamePrefix == "" && config.UsernameClaim != "email":
+		// Claims like sub are only unique per issuer, so qualify them like kube-apiserver does
+		config.UsernamePrefix = config.IssuerURL + "#"
+	}
+	return &OIDCVerifier{
+		config: config,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Accepts reports whether token is a JWT issued by the configured issuer.
+// Other tokens (e.g. ServiceAccount tokens) are left to the TokenReview API.
+// The signature is not checked here.
+func (v *OIDCVerifier) Accepts(token string) bool {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return false
+	}
+	var claims struct {
+		Issuer string `json:"iss"`
+	}
+	if err := decodeJWTSegment(parts[1], &claims); err != nil {
+		return false
+	}
+	return claims.Issuer == v.config.IssuerURL
+}
+
+// Verify validates the token's signature and claims and returns the user it identifies.
+func (v *OIDCVerifier) Verify(ctx context.Context, token string) (*UserInfo, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeJWTSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("malformed token header: %w", err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed token signature: %w", err)
+	}
+	key, err := v.signingKey(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if key.alg != "" && key.alg != header.Alg {
+		return nil, fmt.Errorf("signing key %q is for algorithm %q, not %q", header.Kid, key.alg, header.Alg)
+	}
+	if err := verifyJWTSignature(header.Alg, key.key, []byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return nil, err
+	}
+
+	var claims map[string]any
+	if err := decodeJWTSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("malformed token claims: %w", err)
+	}
+	if err := v.verifyClaims(claims, time.Now()); err != nil {
+		return nil, err
+	}
+	return v.userInfo(claims)
+}
+
+// verifyClaims checks the issuer, audience and validity period of the token.
+func (v *OIDCVerifier) verifyClaims(claims map[string]any, now time.Time) error {
+	if iss, _ := claims["iss"].(string); iss != v.config.IssuerURL {
+		return fmt.Errorf("unexpected issuer %q", iss)
+	}
+
+	var audiences []string
+	switch aud := claims["aud"].(type) {
+	case string:
+		audiences = []string{aud}
+	case []any:
+		for _, a := range aud {
+			if s, ok := a.(string); ok {
+				audiences = append(audiences, s)
+			}
+		}
+	}
+	if !slices.Contains(audiences, v.config.Audience) {
+		return fmt.Errorf("token audience %v does not include %q", audiences, v.config.Audience)
+	}
+
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return errors.New("token has no expiry")
+	}
+	if now.Add(-oidcClockSkew).After(time.Unix(int64(exp), 0)) {
+		return errors.New("token is expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(oidcClockSkew).Before(time.Unix(int64(nbf), 0)) {
+		return errors.New("token is not valid yet")
+	}
+	return nil
+}
+
+// userInfo maps the username and groups claims to a UserInfo.
+func (v *OIDCVerifier) userInfo(claims map[string]any) (*UserInfo, error) {
+	username, _ := claims[v.config.UsernameClaim].(string)
+	if username == "" {
+		return nil, fmt.Errorf("token has no %q claim", v.config.UsernameClaim)
+	}
+	// Like kube-apiserver, an unverified email must not be used as identity
+	if v.config.UsernameClaim == "email" {
+		if verified, ok := claims["email_verified"]; ok && verified != true {
+			return nil, errors.New("token email is not verified")
+		}
+	}
+
+	info := &UserInfo{Username: v.config.UsernamePrefix + username}
+	if strings.HasPrefix(info.Username, reservedIdentityPrefix) {
+		return nil, fmt.Errorf("token maps to reserved username %q", info.Username)
+	}
+	if v.config.GroupsClaim != "" {
+		switch groups := claims[v.config.GroupsClaim].(type) {
+		case string:
+			info.Groups = []string{v.config.GroupsPrefix + groups}
+		case []any:
+			for _, g := range groups {
+				if s, ok := g.(string); ok {
+					info.Groups = append(info.Groups, v.config.GroupsPrefix+s)
+				}
+			}
+		}
+	}
+	for _, g := range info.Groups {
+		if strings.HasPrefix(g, reservedIdentityPrefix) {
+			return nil, fmt.Errorf("token maps to reserved group %q", g)
+		}
+	}
+	return info, nil
+}
+
+// signingKey returns the provider key with the given ID, fetching the key set
+// if it was not loaded yet or the key is unknown (key rotation). Fetches happen
+// at most once per oidcKeyRefreshInterval, also after a failure, so that tokens
+// with unknown key IDs or an unreachable provider cannot stall authentication.
+func (v *OIDCVerifier) signingKey(ctx context.Context, kid string) (*oidcKey, error) {
+	if key, fetched, err := v.cachedKey(kid); fetched {
+		return key, err
+	}
+
+	// The fetch is shared by concurrent requests, so it must not be canceled with ctx
+	fetchCtx := context.WithoutCancel(ctx)
+	_, _, _ = v.fetches.Do("keys", func() (any, error) {
+		// Another request may have completed a fetch in the meantime
+		if _, fetched, _ := v.cachedKey(kid); fetched {
+			return nil, nil
+		}
+		keys, err := v.fetchKeys(fetchCtx)
+		if err != nil {
+			log.Error(err, "Failed to fetch OIDC signing keys", "issuer", v.config.IssuerURL)
+		}
+
+		v.mu.Lock()
+		defer v.mu.Unlock()
+		v.lastFetch, v.fetchErr = time.Now(), err
+		if err == nil {
+			v.keys = keys
+		}
+		return nil, nil
+	})
+	key, _, err := v.cachedKey(kid)
+	return key, err
+}
+
+// cachedKey returns the key with the given ID from the loaded key set. It returns
+// false if the key is unknown and the key set may be fetched again.
+func (v *OIDCVerifier) cachedKey(kid string) (*oidcKey, bool, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if key := v.lookupKey(kid); key != nil {
+		return key, true, nil
+	}
+	if v.lastFetch.IsZero() || time.Since(v.lastFetch) >= oidcKeyRefreshInterval {
+		return nil, false, nil
+	}
+	if v.fetchErr != nil {
+		return nil, true, fmt.Errorf("failed to fetch OIDC signing keys: %w", v.fetchErr)
+	}
+	return nil, true, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// lookupKey returns the key with the given ID. A token without key ID
+// is accepted only if the provider has a single key.
+func (v *OIDCVerifier) lookupKey(kid string) *oidcKey {
+	if kid == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key
+		}
+	}
+	return v.keys[kid]
+}
+
+// fetchKeys discovers the provider's JWKS endpoint and loads its signing keys.
+func (v *OIDCVerifier) fetchKeys(ctx context.Context) (map[string]*oidcKey, error) {
+	var discovery struct {
+		Issuer  string `json:"issuer"`
+		JWKSURI string `json:"jwks_uri"`
+	}
+	wellKnown := strings.TrimSuffix(v.config.IssuerURL, "/") + "/.well-known/openid-configuration"
+	if err := v.getJSON(ctx, wellKnown, &discovery); err != nil {
+		return nil, err
+	}
+	if discovery.Issuer != v.config.IssuerURL {
+		return nil, fmt.Errorf("discovered issuer %q does not match %q", discovery.Issuer, v.config.IssuerURL)
+	}
+
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := v.getJSON(ctx, discovery.JWKSURI, &jwks); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]*oidcKey, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			log.Info("Skipping unsupported OIDC signing key", "kid", jwk.Kid, "reason", err.Error())
+			continue
+		}
+		keys[jwk.Kid] = &oidcKey{key: key, alg: jwk.Alg}
+	}
+	return keys, nil
+}
+
+func (v *OIDCVerifier) getJSON(ctx context.Context, url string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// oidcKey is a provider signing key. If the provider restricts the key to an
+// algorithm (the JWK "alg" parameter), alg is set and tokens must use it.
+type oidcKey struct {
+	key crypto.PublicKey
+	alg string
+}
+
+// jsonWebKey is a public key from a JWKS document (RFC 7517).
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+// verifyJWTSignature checks a JWS signature for the RS*, PS* and ES* algorithms.
+func verifyJWTSignature(alg string, key crypto.PublicKey, signed, signature []byte) error {
+	var hash crypto.Hash
+	switch alg[min(2, len(alg)):] {
+	case "256":
+		hash = crypto.SHA256
+	case "384":
+		hash = crypto.SHA384
+	case "512":
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported signing algorithm %q", alg)
+	}
+	h := hash.New()
+	h.Write(signed)
+	digest := h.Sum(nil)
+
+	invalid := errors.New("invalid token signature")
+	switch {
+	case strings.HasPrefix(alg, "RS"), strings.HasPrefix(alg, "PS"):
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return invalid
+		}
+		var err error
+		if alg[0] == 'P' {
+			err = rsa.VerifyPSS(pub, hash, digest, signature, nil)
+		} else {
+			err = rsa.VerifyPKCS1v15(pub, hash, digest, signature)
+		}
+		if err != nil {
+			return invalid
+		}
+		return nil
+	case strings.HasPrefix(alg, "ES"):
+		// Each ES algorithm is defined for a single curve (RFC 7518 section 3.4)
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok || pub.Curve != ecdsaCurve(hash) {
+			return invalid
+		}
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return invalid
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(pub, digest, r, s) {
+			return invalid
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported signing algorithm %q", alg)
+	}
+}
+
+// ecdsaCurve returns the curve of the ES algorithm using hash.
+func ecdsaCurve(hash crypto.Hash) elliptic.Curve {
+	switch hash {
+	case crypto.SHA256:
+		return elliptic.P256()
+	case crypto.SHA384:
+		return elliptic.P384()
+	default:
+		return elliptic.P521()
+	}
+}
+
+func decodeJWTSegment(segment string, out any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
@@ -0,0 +1,386 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package middleware
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testOIDCProvider is an OpenID provider serving discovery and JWKS documents.
+type testOIDCProvider struct {
+	server   *httptest.Server
+	rsaKey   *rsa.PrivateKey
+	ecKey    *ecdsa.PrivateKey
+	ec384Key *ecdsa.PrivateKey
+
+	// keyFetches counts JWKS requests; failKeys makes them fail
+	keyFetches atomic.Int32
+	failKeys   atomic.Bool
+}
+
+func newTestOIDCProvider(t *testing.T) *testOIDCProvider {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate EC key: %v", err)
+	}
+	ec384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate EC key: %v", err)
+	}
+	p := &testOIDCProvider{rsaKey: rsaKey, ecKey: ecKey, ec384Key: ec384Key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   p.server.URL,
+			"jwks_uri": p.server.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		p.keyFetches.Add(1)
+		if p.failKeys.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		enc := base64.RawURLEncoding
+		rsaJWK := func(kid, use, alg string) map[string]string {
+			return map[string]string{
+				"kty": "RSA", "kid": kid, "use": use, "alg": alg,
+				"n": enc.EncodeToString(rsaKey.N.Bytes()),
+				"e": enc.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()),
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{
+			rsaJWK("rsa", "sig", ""),
+			rsaJWK("rsa-ps256", "sig", "PS256"),
+			rsaJWK("rsa-enc", "enc", ""),
+			{
+				"kty": "EC", "kid": "ec", "crv": "P-256",
+				"x": enc.EncodeToString(ecKey.X.FillBytes(make([]byte, 32))),
+				"y": enc.EncodeToString(ecKey.Y.FillBytes(make([]byte, 32))),
+			},
+			{
+				"kty": "EC", "kid": "ec384", "crv": "P-384",
+				"x": enc.EncodeToString(ec384Key.X.FillBytes(make([]byte, 48))),
+				"y": enc.EncodeToString(ec384Key.Y.FillBytes(make([]byte, 48))),
+			},
+		}})
+	})
+	p.server = httptest.NewTLSServer(mux)
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+func (p *testOIDCProvider) verifier(t *testing.T, config OIDCConfig) *OIDCVerifier {
+	t.Helper()
+	config.IssuerURL = p.server.URL
+	v, err := NewOIDCVerifier(config)
+	if err != nil {
+		t.Fatalf("failed to create verifier: %v", err)
+	}
+	v.client = p.server.Client()
+	return v
+}
+
+// token signs claims with the provider's RSA (RS256) or EC (ES256) key.
+func (p *testOIDCProvider) token(t *testing.T, kid string, claims map[string]any) string {
+	t.Helper()
+	alg := map[string]string{"rsa": "RS256", "ec": "ES256"}[kid]
+	return p.signedToken(t, alg, kid, claims)
+}
+
+// signedToken signs claims with the key with ID kid, using SHA-256 and the
+// RSA PKCS #1 v1.5 or ECDSA scheme of the key, and alg as the header's algorithm.
+func (p *testOIDCProvider) signedToken(t *testing.T, alg, kid string, claims map[string]any) string {
+	t.Helper()
+	enc := base64.RawURLEncoding
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := enc.EncodeToString(header) + "." + enc.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+
+	var signature []byte
+	if strings.HasPrefix(kid, "rsa") {
+		var err error
+		if signature, err = rsa.SignPKCS1v15(rand.Reader, p.rsaKey, crypto.SHA256, digest[:]); err != nil {
+			t.Fatalf("failed to sign token: %v", err)
+		}
+	} else {
+		key := map[string]*ecdsa.PrivateKey{"ec": p.ecKey, "ec384": p.ec384Key}[kid]
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+		if err != nil {
+			t.Fatalf("failed to sign token: %v", err)
+		}
+		size := (key.Curve.Params().BitSize + 7) / 8
+		signature = append(r.FillBytes(make([]byte, size)), s.FillBytes(make([]byte, size))...)
+	}
+	return signed + "." + enc.EncodeToString(signature)
+}
+
+func (p *testOIDCProvider) claims(overrides map[string]any) map[string]any {
+	claims := map[string]any{
+		"iss":            p.server.URL,
+		"aud":            "kubeopencode",
+		"sub":            "1234",
+		"email":          "jane@example.com",
+		"email_verified": true,
+		"groups":         []string{"dev", "ops"},
+		"exp":            time.Now().Add(time.Hour).Unix(),
+	}
+	for k, v := range overrides {
+		claims[k] = v
+	}
+	return claims
+}
+
+func TestOIDCVerifier_Verify(t *testing.T) {
+	p := newTestOIDCProvider(t)
+	v := p.verifier(t, OIDCConfig{
+		ClientID:       "kubeopencode",
+		UsernameClaim:  "email",
+		UsernamePrefix: "oidc:",
+		GroupsClaim:    "groups",
+		GroupsPrefix:   "oidc:",
+	})
+
+	for _, kid := range []string{"rsa", "ec"} {
+		t.Run("valid "+kid+" token", func(t *testing.T) {
+			user, err := v.Verify(t.Context(), p.token(t, kid, p.claims(nil)))
+			if err != nil {
+				t.Fatalf("expected token to be valid: %v", err)
+			}
+			if user.Username != "oidc:jane@example.com" {
+				t.Errorf("unexpected username %q", user.Username)
+			}
+			if len(user.Groups) != 2 || user.Groups[0] != "oidc:dev" || user.Groups[1] != "oidc:ops" {
+				t.Errorf("unexpected groups %v", user.Groups)
+			}
+		})
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "wrong audience", token: p.token(t, "rsa", p.claims(map[string]any{"aud": "other"}))},
+		{name: "expired", token: p.token(t, "rsa", p.claims(map[string]any{"exp": time.Now().Add(-time.Hour).Unix()}))},
+		{name: "not valid yet", token: p.token(t, "rsa", p.claims(map[string]any{"nbf": time.Now().Add(time.Hour).Unix()}))},
+		{name: "unverified email", token: p.token(t, "rsa", p.claims(map[string]any{"email_verified": false}))},
+		{name: "tampered claims", token: func() string {
+			// Claims of one token with the signature of another
+			valid := p.token(t, "rsa", p.claims(nil))
+			forged := p.token(t, "rsa", p.claims(map[string]any{"email": "admin@example.com"}))
+			return forged[:strings.LastIndex(forged, ".")] + valid[strings.LastIndex(valid, "."):]
+		}()},
+		{name: "ES256 with a P-384 key", token: p.signedToken(t, "ES256", "ec384", p.claims(nil))},
+		{name: "algorithm not allowed for the key", token: p.signedToken(t, "RS256", "rsa-ps256", p.claims(nil))},
+		{name: "encryption key", token: p.signedToken(t, "RS256", "rsa-enc", p.claims(nil))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := v.Verify(t.Context(), tt.token); err == nil {
+				t.Error("expected token to be rejected")
+			}
+		})
+	}
+}
+
+func TestOIDCVerifier_IdentityPrefixes(t *testing.T) {
+	p := newTestOIDCProvider(t)
+
+	t.Run("sub is prefixed with the issuer by default", func(t *testing.T) {
+		v := p.verifier(t, OIDCConfig{ClientID: "kubeopencode", GroupsClaim: "groups"})
+		user, err := v.Verify(t.Context(), p.token(t, "rsa", p.claims(map[string]any{"sub": "system:admin"})))
+		if err != nil {
+			t.Fatalf("expected token to be valid: %v", err)
+		}
+		if want := p.server.URL + "#system:admin"; user.Username != want {
+			t.Errorf("username = %q, want %q", user.Username, want)
+		}
+	})
+
+	t.Run("email is not prefixed by default", func(t *testing.T) {
+		v := p.verifier(t, OIDCConfig{ClientID: "kubeopencode", UsernameClaim: "email"})
+		user, err := v.Verify(t.Context(), p.token(t, "rsa", p.claims(nil)))
+		if err != nil {
+			t.Fatalf("expected token to be valid: %v", err)
+		}
+		if user.Username != "jane@example.com" {
+			t.Errorf("username = %q, want jane@example.com", user.Username)
+		}
+	})
+
+	tests := []struct {
+		name   string
+		config OIDCConfig
+		claims map[string]any
+	}{
+		{
+			name:   "system username without prefix",
+			config: OIDCConfig{ClientID: "kubeopencode", UsernamePrefix: "-"},
+			claims: map[string]any{"sub": "system:admin"},
+		},
+		{
+			name:   "system username from email claim",
+			config: OIDCConfig{ClientID: "kubeopencode", UsernameClaim: "email"},
+			claims: map[string]any{"email": "system:admin"},
+		},
+		{
+			name:   "system group without prefix",
+			config: OIDCConfig{ClientID: "kubeopencode", GroupsClaim: "groups"},
+			claims: map[string]any{"groups": []string{"dev", "system:masters"}},
+		},
+		{
+			name:   "system group as a single string",
+			config: OIDCConfig{ClientID: "kubeopencode", GroupsClaim: "groups"},
+			claims: map[string]any{"groups": "system:masters"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := p.verifier(t, tt.config)
+			user, err := v.Verify(t.Context(), p.token(t, "rsa", p.claims(tt.claims)))
+			if err == nil || !strings.Contains(err.Error(), "reserved") {
+				t.Errorf("Verify() = %+v, %v, want reserved identity error", user, err)
+			}
+		})
+	}
+
+	t.Run("prefixed system group is allowed", func(t *testing.T) {
+		v := p.verifier(t, OIDCConfig{ClientID: "kubeopencode", GroupsClaim: "groups", GroupsPrefix: "oidc:"})
+		user, err := v.Verify(t.Context(), p.token(t, "rsa", p.claims(map[string]any{"groups": []string{"system:masters"}})))
+		if err != nil {
+			t.Fatalf("expected token to be valid: %v", err)
+		}
+		if len(user.Groups) != 1 || user.Groups[0] != "oidc:system:masters" {
+			t.Errorf("groups = %v, want [oidc:system:masters]", user.Groups)
+		}
+	})
+}
+
+func TestOIDCVerifier_KeyFetchRateLimit(t *testing.T) {
+	p := newTestOIDCProvider(t)
+	v := p.verifier(t, OIDCConfig{ClientID: "kubeopencode"})
+
+	t.Run("concurrent requests share one fetch", func(t *testing.T) {
+		token := p.token(t, "rsa", p.claims(nil))
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Go(func() {
+				if _, err := v.Verify(t.Context(), token); err != nil {
+					t.Errorf("expected token to be valid: %v", err)
+				}
+			})
+		}
+		wg.Wait()
+		if n := p.keyFetches.Load(); n != 1 {
+			t.Errorf("keys fetched %d times, want 1", n)
+		}
+	})
+
+	t.Run("unknown key is not refetched within the interval", func(t *testing.T) {
+		v.lastFetch = time.Now()
+		token := p.token(t, "rsa", p.claims(nil))
+		token = strings.Replace(token, strings.Split(token, ".")[0], base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","kid":"rotated"}`)), 1)
+		before := p.keyFetches.Load()
+		for range 3 {
+			if _, err := v.Verify(t.Context(), token); err == nil || !strings.Contains(err.Error(), "unknown signing key") {
+				t.Errorf("error = %v, want unknown signing key", err)
+			}
+		}
+		if n := p.keyFetches.Load() - before; n != 0 {
+			t.Errorf("keys fetched %d times, want 0", n)
+		}
+	})
+
+	t.Run("failed fetch is not retried within the interval", func(t *testing.T) {
+		p.failKeys.Store(true)
+		v := p.verifier(t, OIDCConfig{ClientID: "kubeopencode"})
+		token := p.token(t, "rsa", p.claims(nil))
+		before := p.keyFetches.Load()
+		for range 3 {
+			if _, err := v.Verify(t.Context(), token); err == nil || !strings.Contains(err.Error(), "failed to fetch OIDC signing keys") {
+				t.Errorf("error = %v, want fetch failure", err)
+			}
+		}
+		if n := p.keyFetches.Load() - before; n != 1 {
+			t.Errorf("keys fetched %d times, want 1", n)
+		}
+
+		// The next request after the interval fetches again
+		p.failKeys.Store(false)
+		v.lastFetch = time.Now().Add(-oidcKeyRefreshInterval)
+		if _, err := v.Verify(t.Context(), token); err != nil {
+			t.Errorf("expected token to be valid after refetch: %v", err)
+		}
+	})
+}
+
+func TestNewOIDCVerifier_RequiresHTTPS(t *testing.T) {
+	if _, err := NewOIDCVerifier(OIDCConfig{IssuerURL: "http://idp.example.com", ClientID: "c"}); err == nil {
+		t.Error("expected http issuer to be rejected")
+	}
+	if _, err := NewOIDCVerifier(OIDCConfig{IssuerURL: "https://idp.example.com"}); err == nil {
+		t.Error("expected missing client ID to be rejected")
+	}
+}
+
+func TestAuth_OIDC(t *testing.T) {
+	p := newTestOIDCProvider(t)
+	v := p.verifier(t, OIDCConfig{ClientID: "kubeopencode", GroupsClaim: "groups"})
+	// TokenReview handles tokens from other issuers
+	cs := fakeClientsetWithTokenReview(true, "system:serviceaccount:default:ci", "uid-1", nil)
+	middleware := Auth(cs, AuthConfig{Enabled: true, OIDC: v})
+
+	tests := []struct {
+		name         string
+		token        string
+		wantStatus   int
+		wantUsername string
+	}{
+		{name: "OIDC token", token: p.token(t, "rsa", p.claims(nil)), wantStatus: http.StatusOK, wantUsername: p.server.URL + "#1234"},
+		{name: "invalid OIDC token", token: p.token(t, "rsa", p.claims(map[string]any{"aud": "other"})), wantStatus: http.StatusUnauthorized},
+		{name: "other token uses TokenReview", token: "opaque-token", wantStatus: http.StatusOK, wantUsername: "system:serviceaccount:default:ci"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var username string
+			handler := middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				username = GetUserInfo(r.Context()).Username
+				w.WriteHeader(http.StatusOK)
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if username != tt.wantUsername {
+				t.Errorf("expected username %q, got %q", tt.wantUsername, username)
+			}
+		})
+	}
+}
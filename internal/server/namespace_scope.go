@@ -0,0 +1,146 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubeopencode/kubeopencode/internal/server/handlers"
+	servertypes "github.com/kubeopencode/kubeopencode/internal/server/types"
+)
+
+// namespaceLabelsTTL is how long the labels of a namespace are reused when matching
+// the namespace selector
+const namespaceLabelsTTL = 30 * time.Second
+
+// namespaceScope implements handlers.NamespaceScope from the server options.
+// A namespace is served if it is not denied, is in the allow list (when set) and
+// matches the label selector (when set). Namespace labels are read with the server's
+// identity; lookup failures deny the namespace.
+type namespaceScope struct {
+	allowed  []string
+	denied   []string
+	selector labels.Selector
+	reader   client.Reader
+
+	mu     sync.Mutex
+	labels map[string]namespaceLabels
+}
+
+type namespaceLabels struct {
+	labels  labels.Set
+	expires time.Time
+}
+
+// newNamespaceScope returns the scope configured by the options, or nil if the server
+// serves all namespaces.
+func newNamespaceScope(reader client.Reader, allowed, denied []string, selector string) (*namespaceScope, error) {
+	if len(allowed) == 0 && len(denied) == 0 && selector == "" {
+		return nil, nil
+	}
+	s := &namespaceScope{
+		allowed: allowed,
+		denied:  denied,
+		reader:  reader,
+		labels:  map[string]namespaceLabels{},
+	}
+	if selector != "" {
+		parsed, err := labels.Parse(selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid namespace selector %q: %w", selector, err)
+		}
+		s.selector = parsed
+	}
+	return s, nil
+}
+
+// Allows reports whether namespace is served
+func (s *namespaceScope) Allows(ctx context.Context, namespace string) bool {
+	if slices.Contains(s.denied, namespace) {
+		return false
+	}
+	if len(s.allowed) > 0 && !slices.Contains(s.allowed, namespace) {
+		return false
+	}
+	if s.selector == nil {
+		return true
+	}
+	set, err := s.namespaceLabels(ctx, namespace)
+	if err != nil {
+		log.Error(err, "failed to get namespace labels", "namespace", namespace)
+		return false
+	}
+	return s.selector.Matches(set)
+}
+
+// namespaceLabels returns the labels of namespace, cached for namespaceLabelsTTL
+func (s *namespaceScope) namespaceLabels(ctx context.Context, namespace string) (labels.Set, error) {
+	now := time.Now()
+	s.mu.Lock()
+	cached, ok := s.labels[namespace]
+	s.mu.Unlock()
+	if ok && now.Before(cached.expires) {
+		return cached.labels, nil
+	}
+
+	var ns corev1.Namespace
+	if err := s.reader.Get(ctx, client.ObjectKey{Name: namespace}, &ns); err != nil {
+		return nil, err
+	}
+	set := labels.Set(ns.Labels)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, entry := range s.labels {
+		if now.After(entry.expires) {
+			delete(s.labels, key)
+		}
+	}
+	s.labels[namespace] = namespaceLabels{labels: set, expires: now.Add(namespaceLabelsTTL)}
+	return set, nil
+}
+
+// namespaceScopeMiddleware hands the scope to handlers, which filter all-namespace
+// lists, and rejects requests for a namespace outside the scope, given either in
+// the path (/api/v1/namespaces/{namespace}/...) or as the namespace query parameter.
+func namespaceScopeMiddleware(scope handlers.NamespaceScope) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), handlers.NamespaceScopeContextKey{}, scope)
+			for _, namespace := range []string{pathNamespace(r.URL.Path), r.URL.Query().Get("namespace")} {
+				if namespace != "" && !scope.Allows(ctx, namespace) {
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(http.StatusForbidden)
+					_ = json.NewEncoder(w).Encode(servertypes.ErrorResponse{
+						Error:   "Namespace not served",
+						Message: fmt.Sprintf("namespace %q is outside the namespaces served by this server", namespace),
+						Code:    http.StatusForbidden,
+					})
+					return
+				}
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// pathNamespace returns the namespace of a /api/v1/namespaces/{namespace}/... path
+func pathNamespace(path string) string {
+	_, rest, ok := strings.Cut(path, "/api/v1/namespaces/")
+	if !ok {
+		return ""
+	}
+	namespace, _, _ := strings.Cut(rest, "/")
+	return namespace
+}
@@ -0,0 +1,112 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestNamespaceScope_Allows(t *testing.T) {
+	reader := clientfake.NewClientBuilder().WithObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{"tenant": "a"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a-dev", Labels: map[string]string{"tenant": "a"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b", Labels: map[string]string{"tenant": "b"}}},
+	).Build()
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		allowed  []string
+		denied   []string
+		selector string
+		want     map[string]bool
+	}{
+		{
+			name:    "allow list",
+			allowed: []string{"team-a"},
+			want:    map[string]bool{"team-a": true, "team-b": false},
+		},
+		{
+			name:   "deny list",
+			denied: []string{"kube-system"},
+			want:   map[string]bool{"team-a": true, "kube-system": false},
+		},
+		{
+			name:     "selector",
+			selector: "tenant=a",
+			want:     map[string]bool{"team-a": true, "team-a-dev": true, "team-b": false, "missing": false},
+		},
+		{
+			name:     "deny list wins over selector",
+			denied:   []string{"team-a-dev"},
+			selector: "tenant=a",
+			want:     map[string]bool{"team-a": true, "team-a-dev": false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scope, err := newNamespaceScope(reader, tt.allowed, tt.denied, tt.selector)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for namespace, want := range tt.want {
+				if got := scope.Allows(ctx, namespace); got != want {
+					t.Errorf("Allows(%q) = %v, want %v", namespace, got, want)
+				}
+			}
+		})
+	}
+
+	t.Run("unrestricted", func(t *testing.T) {
+		scope, err := newNamespaceScope(reader, nil, nil, "")
+		if err != nil || scope != nil {
+			t.Errorf("expected no scope, got %v, %v", scope, err)
+		}
+	})
+
+	t.Run("invalid selector", func(t *testing.T) {
+		if _, err := newNamespaceScope(reader, nil, nil, "tenant in"); err == nil {
+			t.Error("expected error for invalid selector")
+		}
+	})
+}
+
+func TestNamespaceScopeMiddleware(t *testing.T) {
+	scope, err := newNamespaceScope(nil, []string{"team-a"}, nil, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	handler := namespaceScopeMiddleware(scope)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		path       string
+		wantStatus int
+	}{
+		{path: "/api/v1/namespaces/team-a/tasks", wantStatus: http.StatusOK},
+		{path: "/api/v1/namespaces/team-b/tasks/my-task/logs", wantStatus: http.StatusForbidden},
+		{path: "/api/v1/namespaces/team-b/capabilities", wantStatus: http.StatusForbidden},
+		{path: "/api/v1/tasks", wantStatus: http.StatusOK},
+		{path: "/api/v1/stats?namespace=team-a", wantStatus: http.StatusOK},
+		{path: "/api/v1/stats?namespace=team-b", wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
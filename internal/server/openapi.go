@@ -0,0 +1,378 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/kubeopencode/kubeopencode/internal/server/handlers"
+	servertypes "github.com/kubeopencode/kubeopencode/internal/server/types"
+)
+
+const (
+	// openAPIPath is the route serving the generated OpenAPI document
+	openAPIPath = "/api/v1/openapi.json"
+
+	contentTypeJSON = "application/json"
+	contentTypeYAML = "application/x-yaml"
+	contentTypeSSE  = "text/event-stream"
+	contentTypeText = "text/plain"
+	contentTypeGzip = "application/gzip"
+)
+
+// openAPIOperation describes an API route in the OpenAPI document.
+// Request and Response are values of the JSON body types; nil means no (typed) body.
+type openAPIOperation struct {
+	ID      string
+	Summary string
+	Request any
+	// OptionalRequest marks the request body as optional
+	OptionalRequest bool
+	// RequestContentType overrides the JSON request body, e.g. for YAML manifests
+	RequestContentType string
+	Response           any
+	// ResponseContentType overrides the JSON response body, e.g. for streams
+	ResponseContentType string
+	// Status is the success status code (default 200)
+	Status int
+	Query  []string
+}
+
+// Query parameters understood by ParseFilterOptions
+var listQuery = []string{"name", "phase", "labelSelector", "limit", "offset", "sortOrder"}
+
+// Task and Agent lists additionally support continue tokens, and Task lists
+// agent and free-text search filters
+var (
+	agentListQuery = append([]string{"continue"}, listQuery...)
+	taskListQuery  = append([]string{"agent", "q", "continue"}, listQuery...)
+)
+
+// openAPIOperations documents every /api/v1 route, keyed by "METHOD path".
+// Routes are discovered from the router, so a route missing here fails the OpenAPI test.
+var openAPIOperations = map[string]openAPIOperation{
+	"GET " + openAPIPath: {ID: "getOpenAPI", Summary: "Get the OpenAPI document"},
+
+	"GET /api/v1/info":                                {ID: "getInfo", Summary: "Get server information", Response: servertypes.ServerInfo{}},
+	"GET /api/v1/namespaces":                          {ID: "listNamespaces", Summary: "List namespaces", Response: servertypes.NamespaceList{}},
+	"GET /api/v1/stats":                               {ID: "getStats", Summary: "Get aggregate Task statistics", Response: servertypes.TaskStatsResponse{}, Query: []string{"namespace", "window"}},
+	"GET /api/v1/reports/tasks":                       {ID: "getTaskReport", Summary: "Report Tasks finished within a time range (JSON or CSV)", Response: servertypes.TaskReportResponse{}, Query: []string{"namespace", "agent", "since", "until", "format"}},
+	"GET /api/v1/diff":                                {ID: "diffTasks", Summary: "Compare the specs, outputs and durations of two Tasks", Response: servertypes.TaskDiffResponse{}, Query: []string{"a", "b"}},
+	"POST /api/v1/apply":                              {ID: "apply", Summary: "Server-side apply KubeOpenCode manifests", RequestContentType: contentTypeYAML, Response: servertypes.ApplyResponse{}, Query: []string{"namespace", "dryRun", "force"}},
+	"GET /api/v1/preferences":                         {ID: "getPreferences", Summary: "Get the current user's UI preferences", Response: servertypes.UserPreferences{}},
+	"PUT /api/v1/preferences":                         {ID: "updatePreferences", Summary: "Replace the current user's UI preferences", Request: servertypes.UserPreferences{}, Response: servertypes.UserPreferences{}},
+	"GET /api/v1/namespaces/{namespace}/capabilities": {ID: "getCapabilities", Summary: "List the actions the current user may perform in a namespace", Response: servertypes.CapabilitiesResponse{}},
+
+	"GET /api/v1/tasks":       {ID: "listAllTasks", Summary: "List Tasks in all namespaces", Response: servertypes.TaskListResponse{}, Query: taskListQuery},
+	"GET /api/v1/tasks/watch": {ID: "watchAllTasks", Summary: "Watch Tasks in all namespaces (SSE)", Response: servertypes.TaskWatchEvent{}, ResponseContentType: contentTypeSSE, Query: []string{"name", "labelSelector", "resourceVersion"}},
+
+	"GET /api/v1/namespaces/{namespace}/tasks":                         {ID: "listTasks", Summary: "List Tasks", Response: servertypes.TaskListResponse{}, Query: taskListQuery},
+	"POST /api/v1/namespaces/{namespace}/tasks":                        {ID: "createTask", Summary: "Create a Task", Request: servertypes.CreateTaskRequest{}, Response: servertypes.TaskResponse{}, Status: http.StatusCreated},
+	"GET /api/v1/namespaces/{namespace}/tasks/watch":                   {ID: "watchTasks", Summary: "Watch Tasks (SSE)", Response: servertypes.TaskWatchEvent{}, ResponseContentType: contentTypeSSE, Query: []string{"name", "labelSelector", "resourceVersion"}},
+	"GET /api/v1/namespaces/{namespace}/tasks/{name}":                  {ID: "getTask", Summary: "Get a Task", Response: servertypes.TaskResponse{}, Query: []string{"output"}},
+	"DELETE /api/v1/namespaces/{namespace}/tasks/{name}":               {ID: "deleteTask", Summary: "Delete a Task", Status: http.StatusNoContent},
+	"PATCH /api/v1/namespaces/{namespace}/tasks/{name}":                {ID: "patchTask", Summary: "Update Task labels and annotations (JSON merge patch)", Request: servertypes.PatchTaskRequest{}, Response: servertypes.TaskResponse{}},
+	"POST /api/v1/namespaces/{namespace}/tasks/{name}/stop":            {ID: "stopTask", Summary: "Stop a running Task", Response: servertypes.TaskResponse{}},
+	"POST /api/v1/namespaces/{namespace}/tasks/{name}/retry":           {ID: "retryTask", Summary: "Create a new Task from a finished one", Request: servertypes.RetryTaskRequest{}, OptionalRequest: true, Response: servertypes.TaskResponse{}, Status: http.StatusCreated},
+	"GET /api/v1/namespaces/{namespace}/tasks/{name}/logs":             {ID: "getTaskLogs", Summary: "Stream Task logs (SSE)", Response: servertypes.LogEvent{}, ResponseContentType: contentTypeSSE, Query: []string{"follow", "container"}},
+	"GET /api/v1/namespaces/{namespace}/tasks/{name}/logs/download":    {ID: "downloadTaskLogs", Summary: "Download Task logs as plain text", ResponseContentType: contentTypeText, Query: []string{"container", "tailLines", "sinceTime", "timestamps", "previous"}},
+	"GET /api/v1/namespaces/{namespace}/tasks/{name}/outputs":          {ID: "getTaskOutputs", Summary: "Get captured Task outputs", Response: servertypes.TaskOutputsResponse{}},
+	"POST /api/v1/namespaces/{namespace}/tasks/{name}/outputs":         {ID: "uploadTaskOutputs", Summary: "Upload the outputs of a running Task from its Pod", RequestContentType: contentTypeGzip, Status: http.StatusNoContent},
+	"GET /api/v1/namespaces/{namespace}/tasks/{name}/outputs/{file}":   {ID: "getTaskOutputFile", Summary: "Download a captured Task output file", ResponseContentType: contentTypeText},
+	"GET /api/v1/namespaces/{namespace}/tasks/{name}/exec":             {ID: "execTask", Summary: "Open a shell in the Task Pod (WebSocket)", Query: []string{"container"}},
+	"POST /api/v1/namespaces/{namespace}/tasks/{name}/messages":        {ID: "sendTaskMessage", Summary: "Send a follow-up message to a running Task", Request: servertypes.SendTaskMessageRequest{}, Response: servertypes.TaskMessageResponse{}, Status: http.StatusAccepted},
+	"GET /api/v1/namespaces/{namespace}/tasks/{name}/session":          {ID: "getTaskSession", Summary: "Get the Task's OpenCode session"},
+	"GET /api/v1/namespaces/{namespace}/tasks/{name}/session/messages": {ID: "getTaskSessionMessages", Summary: "Get the Task's OpenCode session messages"},
+
+	"GET /api/v1/crontasks":                                        {ID: "listAllCronTasks", Summary: "List CronTasks in all namespaces", Response: servertypes.CronTaskListResponse{}, Query: listQuery},
+	"GET /api/v1/namespaces/{namespace}/crontasks":                 {ID: "listCronTasks", Summary: "List CronTasks", Response: servertypes.CronTaskListResponse{}, Query: listQuery},
+	"POST /api/v1/namespaces/{namespace}/crontasks":                {ID: "createCronTask", Summary: "Create a CronTask", Request: servertypes.CreateCronTaskRequest{}, Response: servertypes.CronTaskResponse{}, Status: http.StatusCreated},
+	"GET /api/v1/namespaces/{namespace}/crontasks/{name}":          {ID: "getCronTask", Summary: "Get a CronTask", Response: servertypes.CronTaskResponse{}, Query: []string{"output"}},
+	"PUT /api/v1/namespaces/{namespace}/crontasks/{name}":          {ID: "updateCronTask", Summary: "Update a CronTask", Request: servertypes.UpdateCronTaskRequest{}, Response: servertypes.CronTaskResponse{}},
+	"DELETE /api/v1/namespaces/{namespace}/crontasks/{name}":       {ID: "deleteCronTask", Summary: "Delete a CronTask", Status: http.StatusNoContent},
+	"POST /api/v1/namespaces/{namespace}/crontasks/{name}/suspend": {ID: "suspendCronTask", Summary: "Suspend a CronTask", Response: servertypes.CronTaskResponse{}},
+	"POST /api/v1/namespaces/{namespace}/crontasks/{name}/resume":  {ID: "resumeCronTask", Summary: "Resume a CronTask", Response: servertypes.CronTaskResponse{}},
+	"POST /api/v1/namespaces/{namespace}/crontasks/{name}/trigger": {ID: "triggerCronTask", Summary: "Trigger a CronTask run", Response: map[string]string{}},
+	"GET /api/v1/namespaces/{namespace}/crontasks/{name}/history":  {ID: "getCronTaskHistory", Summary: "List Tasks created by a CronTask", Response: servertypes.TaskListResponse{}, Query: listQuery},
+
+	"GET /api/v1/agenttemplates":                                       {ID: "listAllAgentTemplates", Summary: "List AgentTemplates in all namespaces", Response: servertypes.AgentTemplateListResponse{}, Query: listQuery},
+	"GET /api/v1/namespaces/{namespace}/agenttemplates":                {ID: "listAgentTemplates", Summary: "List AgentTemplates", Response: servertypes.AgentTemplateListResponse{}, Query: listQuery},
+	"POST /api/v1/namespaces/{namespace}/agenttemplates":               {ID: "createAgentTemplate", Summary: "Create an AgentTemplate", Request: servertypes.CreateAgentTemplateRequest{}, Response: servertypes.AgentTemplateResponse{}, Status: http.StatusCreated},
+	"GET /api/v1/namespaces/{namespace}/agenttemplates/{name}":         {ID: "getAgentTemplate", Summary: "Get an AgentTemplate", Response: servertypes.AgentTemplateResponse{}, Query: []string{"output"}},
+	"PUT /api/v1/namespaces/{namespace}/agenttemplates/{name}":         {ID: "updateAgentTemplate", Summary: "Replace an AgentTemplate spec from a YAML manifest", RequestContentType: contentTypeYAML, Response: servertypes.AgentTemplateResponse{}},
+	"DELETE /api/v1/namespaces/{namespace}/agenttemplates/{name}":      {ID: "deleteAgentTemplate", Summary: "Delete an AgentTemplate", Status: http.StatusNoContent},
+	"POST /api/v1/namespaces/{namespace}/agenttemplates/{name}/render": {ID: "renderAgentTemplate", Summary: "Preview the effective spec of a Task using an AgentTemplate", RequestContentType: contentTypeYAML, Response: servertypes.RenderAgentTemplateResponse{}},
+
+	"GET /api/v1/config": {ID: "getConfig", Summary: "Get the cluster KubeOpenCodeConfig", Response: servertypes.ConfigResponse{}, Query: []string{"output"}},
+	"PUT /api/v1/config": {ID: "updateConfig", Summary: "Replace the KubeOpenCodeConfig spec from a YAML manifest", RequestContentType: contentTypeYAML, Response: servertypes.ConfigResponse{}},
+
+	"GET /api/v1/registries":                                  {ID: "listAllRegistries", Summary: "List Registries in all namespaces", Response: servertypes.RegistryListResponse{}, Query: listQuery},
+	"GET /api/v1/namespaces/{namespace}/registries":           {ID: "listRegistries", Summary: "List Registries", Response: servertypes.RegistryListResponse{}, Query: listQuery},
+	"POST /api/v1/namespaces/{namespace}/registries":          {ID: "createRegistry", Summary: "Create a Registry", Request: servertypes.CreateRegistryRequest{}, Response: servertypes.RegistryResponse{}, Status: http.StatusCreated},
+	"GET /api/v1/namespaces/{namespace}/registries/{name}":    {ID: "getRegistry", Summary: "Get a Registry", Response: servertypes.RegistryResponse{}, Query: []string{"output"}},
+	"PUT /api/v1/namespaces/{namespace}/registries/{name}":    {ID: "updateRegistry", Summary: "Replace a Registry spec from a YAML manifest", RequestContentType: contentTypeYAML, Response: servertypes.RegistryResponse{}},
+	"DELETE /api/v1/namespaces/{namespace}/registries/{name}": {ID: "deleteRegistry", Summary: "Delete a Registry", Status: http.StatusNoContent},
+
+	"GET /api/v1/agents":                                        {ID: "listAllAgents", Summary: "List Agents in all namespaces", Response: servertypes.AgentListResponse{}, Query: agentListQuery},
+	"GET /api/v1/namespaces/{namespace}/agents":                 {ID: "listAgents", Summary: "List Agents", Response: servertypes.AgentListResponse{}, Query: agentListQuery},
+	"POST /api/v1/namespaces/{namespace}/agents":                {ID: "createAgent", Summary: "Create an Agent", Request: servertypes.CreateAgentRequest{}, Response: servertypes.AgentResponse{}, Status: http.StatusCreated},
+	"GET /api/v1/namespaces/{namespace}/agents/{name}":          {ID: "getAgent", Summary: "Get an Agent", Response: servertypes.AgentResponse{}, Query: []string{"output"}},
+	"PUT /api/v1/namespaces/{namespace}/agents/{name}":          {ID: "updateAgent", Summary: "Replace an Agent spec from a YAML manifest", RequestContentType: contentTypeYAML, Response: servertypes.AgentResponse{}},
+	"DELETE /api/v1/namespaces/{namespace}/agents/{name}":       {ID: "deleteAgent", Summary: "Delete an Agent", Status: http.StatusNoContent},
+	"POST /api/v1/namespaces/{namespace}/agents/{name}/suspend": {ID: "suspendAgent", Summary: "Suspend an Agent", Response: servertypes.AgentResponse{}},
+	"POST /api/v1/namespaces/{namespace}/agents/{name}/resume":  {ID: "resumeAgent", Summary: "Resume an Agent", Response: servertypes.AgentResponse{}},
+	"GET /api/v1/namespaces/{namespace}/agents/{name}/share":    {ID: "getAgentShare", Summary: "Get an Agent's share link", Response: servertypes.ShareTokenResponse{}},
+	"POST /api/v1/namespaces/{namespace}/agents/{name}/share":   {ID: "updateAgentShare", Summary: "Enable or update an Agent's share link", Request: servertypes.UpdateShareRequest{}, Response: servertypes.AgentResponse{}},
+	"DELETE /api/v1/namespaces/{namespace}/agents/{name}/share": {ID: "deleteAgentShare", Summary: "Disable an Agent's share link", Response: servertypes.AgentResponse{}},
+	"GET /api/v1/namespaces/{namespace}/agents/{name}/terminal": {ID: "openAgentTerminal", Summary: "Open a terminal in the Agent Pod (WebSocket)"},
+}
+
+// chiParamPattern matches chi path parameters, with an optional regexp ({name:[a-z]+})
+var chiParamPattern = regexp.MustCompile(`\{([^}:]+)(?::[^}]*)?\}`)
+
+// openAPIHandler serves the OpenAPI document for the routes of router.
+// The document is generated on the first request, once all routes are registered.
+func openAPIHandler(router chi.Routes) http.HandlerFunc {
+	var (
+		once sync.Once
+		data []byte
+		err  error
+	)
+	return func(w http.ResponseWriter, _ *http.Request) {
+		once.Do(func() {
+			var spec map[string]any
+			if spec, err = buildOpenAPISpec(router); err == nil {
+				data, err = json.Marshal(spec)
+			}
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", contentTypeJSON)
+		_, _ = w.Write(data)
+	}
+}
+
+// isOpenAPIRoute reports whether a route is described in the OpenAPI document.
+// The Agent proxy forwards arbitrary OpenCode requests and is not described.
+func isOpenAPIRoute(route string) bool {
+	return strings.HasPrefix(route, "/api/v1/") && !strings.Contains(route, "*") && !strings.Contains(route, "/proxy")
+}
+
+// buildOpenAPISpec generates an OpenAPI 3 document for the /api/v1 routes of router.
+func buildOpenAPISpec(router chi.Routes) (map[string]any, error) {
+	schemas := openAPISchemas{}
+	paths := map[string]map[string]any{}
+
+	err := chi.Walk(router, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		if !isOpenAPIRoute(route) {
+			return nil
+		}
+		path := strings.TrimSuffix(route, "/")
+		if paths[path] == nil {
+			paths[path] = map[string]any{}
+		}
+		paths[path][strings.ToLower(method)] = schemas.operation(path, openAPIOperations[method+" "+path])
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	schemas.schemaFor(reflect.TypeOf(servertypes.ErrorResponse{}))
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "KubeOpenCode API",
+			"version": handlers.Version,
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": schemas,
+			"securitySchemes": map[string]any{
+				"bearerAuth": map[string]any{"type": "http", "scheme": "bearer"},
+			},
+		},
+		"security": []map[string][]string{{"bearerAuth": {}}},
+	}, nil
+}
+
+// operation builds the OpenAPI operation object for a route.
+func (s openAPISchemas) operation(path string, op openAPIOperation) map[string]any {
+	var params []map[string]any
+	for _, m := range chiParamPattern.FindAllStringSubmatch(path, -1) {
+		params = append(params, map[string]any{
+			"name": m[1], "in": "path", "required": true, "schema": map[string]any{"type": "string"},
+		})
+	}
+	for _, q := range op.Query {
+		params = append(params, map[string]any{
+			"name": q, "in": "query", "schema": map[string]any{"type": "string"},
+		})
+	}
+
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := map[string]any{"description": http.StatusText(status)}
+	if status != http.StatusNoContent {
+		contentType := contentTypeJSON
+		if op.ResponseContentType != "" {
+			contentType = op.ResponseContentType
+		}
+		schema := map[string]any{}
+		switch {
+		case op.Response != nil:
+			schema = s.schemaFor(reflect.TypeOf(op.Response))
+		case contentType == contentTypeText:
+			schema = map[string]any{"type": "string"}
+		}
+		success["content"] = map[string]any{contentType: map[string]any{"schema": schema}}
+	}
+
+	result := map[string]any{
+		"operationId": op.ID,
+		"summary":     op.Summary,
+		"tags":        []string{openAPITag(path)},
+		"responses": map[string]any{
+			strconv.Itoa(status): success,
+			"default": map[string]any{
+				"description": "Error",
+				"content": map[string]any{contentTypeJSON: map[string]any{
+					"schema": s.schemaFor(reflect.TypeOf(servertypes.ErrorResponse{})),
+				}},
+			},
+		},
+	}
+	if params != nil {
+		result["parameters"] = params
+	}
+	switch {
+	case op.RequestContentType == contentTypeGzip:
+		result["requestBody"] = map[string]any{
+			"required": true,
+			"content": map[string]any{op.RequestContentType: map[string]any{
+				"schema": map[string]any{"type": "string", "format": "binary", "description": "tar.gz archive"},
+			}},
+		}
+	case op.RequestContentType != "":
+		result["requestBody"] = map[string]any{
+			"required": true,
+			"content": map[string]any{op.RequestContentType: map[string]any{
+				"schema": map[string]any{"type": "string", "description": "Kubernetes resource manifest"},
+			}},
+		}
+	case op.Request != nil:
+		result["requestBody"] = map[string]any{
+			"required": !op.OptionalRequest,
+			"content": map[string]any{contentTypeJSON: map[string]any{
+				"schema": s.schemaFor(reflect.TypeOf(op.Request)),
+			}},
+		}
+	}
+	return result
+}
+
+// openAPITag groups operations by resource: the first path segment after /api/v1
+// or after the namespace.
+func openAPITag(path string) string {
+	rest := strings.TrimPrefix(path, "/api/v1/")
+	if after, ok := strings.CutPrefix(rest, "namespaces/{namespace}/"); ok {
+		rest = after
+	}
+	tag, _, _ := strings.Cut(rest, "/")
+	return tag
+}
+
+// openAPISchemas collects the component schemas of the API types, keyed by type name.
+type openAPISchemas map[string]any
+
+// schemaFor returns the schema for a Go type. Named structs are added to the
+// component schemas and referenced.
+func (s openAPISchemas) schemaFor(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == reflect.TypeOf(time.Time{}) {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.objectSchema(t)
+		}
+		if _, ok := s[t.Name()]; !ok {
+			s[t.Name()] = map[string]any{} // placeholder for recursive types
+			s[t.Name()] = s.objectSchema(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + t.Name()}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "format": "byte"}
+		}
+		return map[string]any{"type": "array", "items": s.schemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": s.schemaFor(t.Elem())}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return map[string]any{"type": "integer", "format": "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]any{"type": "integer", "format": "int32"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	default:
+		return map[string]any{}
+	}
+}
+
+// objectSchema returns the schema of a struct from its json tags.
+// Fields without omitempty are required; embedded structs are flattened.
+func (s openAPISchemas) objectSchema(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	var required []string
+
+	var addFields func(t reflect.Type)
+	addFields = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			tag := field.Tag.Get("json")
+			if tag == "-" || !field.IsExported() {
+				continue
+			}
+			name, opts, _ := strings.Cut(tag, ",")
+			if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+				addFields(field.Type)
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			properties[name] = s.schemaFor(field.Type)
+			if !strings.Contains(opts, "omitempty") {
+				required = append(required, name)
+			}
+		}
+	}
+	addFields(t)
+
+	schema := map[string]any{"type": "object", "properties": properties}
+	if required != nil {
+		schema["required"] = required
+	}
+	return schema
+}
@@ -0,0 +1,92 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestOpenAPIOperationsCoverRoutes(t *testing.T) {
+	s := &Server{}
+	router := s.setupRoutes()
+
+	seen := map[string]bool{}
+	err := chi.Walk(router, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		if !isOpenAPIRoute(route) {
+			return nil
+		}
+		key := method + " " + strings.TrimSuffix(route, "/")
+		seen[key] = true
+		if _, ok := openAPIOperations[key]; !ok {
+			t.Errorf("route %q is not documented in openAPIOperations", key)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to walk routes: %v", err)
+	}
+	for key := range openAPIOperations {
+		if !seen[key] {
+			t.Errorf("openAPIOperations documents unknown route %q", key)
+		}
+	}
+}
+
+func TestOpenAPIHandler(t *testing.T) {
+	s := &Server{}
+	router := s.setupRoutes()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, openAPIPath, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var spec struct {
+		OpenAPI    string                               `json:"openapi"`
+		Paths      map[string]map[string]map[string]any `json:"paths"`
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]any `json:"properties"`
+				Required   []string       `json:"required"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&spec); err != nil {
+		t.Fatalf("failed to decode OpenAPI document: %v", err)
+	}
+	if spec.OpenAPI != "3.0.3" {
+		t.Errorf("unexpected openapi version %q", spec.OpenAPI)
+	}
+
+	create, ok := spec.Paths["/api/v1/namespaces/{namespace}/tasks"]["post"]
+	if !ok {
+		t.Fatalf("expected POST tasks operation, got paths %v", spec.Paths)
+	}
+	if create["operationId"] != "createTask" {
+		t.Errorf("unexpected operationId %v", create["operationId"])
+	}
+	if _, ok := create["responses"].(map[string]any)["201"]; !ok {
+		t.Errorf("expected 201 response, got %v", create["responses"])
+	}
+
+	task, ok := spec.Components.Schemas["TaskResponse"]
+	if !ok {
+		t.Fatalf("expected TaskResponse schema")
+	}
+	if _, ok := task.Properties["phase"]; !ok {
+		t.Errorf("expected phase property, got %v", task.Properties)
+	}
+	if !strings.Contains(strings.Join(task.Required, ","), "name") {
+		t.Errorf("expected name to be required, got %v", task.Required)
+	}
+	if _, ok := spec.Paths["/api/v1/namespaces/{namespace}/agents/{name}/proxy"]; ok {
+		t.Errorf("expected the agent proxy to be omitted")
+	}
+}
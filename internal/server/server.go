@@ -20,6 +20,7 @@ import (
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
@@ -53,6 +54,21 @@ type Options struct {
 	CORSAllowedOrigins []string
 	// APIRateLimit is the maximum number of concurrent API requests. 0 means no limit.
 	APIRateLimit int
+	// OIDC configures OpenID Connect authentication. An empty IssuerURL disables it.
+	OIDC authmiddleware.OIDCConfig
+	// TLSCertFile and TLSKeyFile enable HTTPS. The files are watched and reloaded
+	// when they change. Empty means plain HTTP.
+	TLSCertFile string
+	TLSKeyFile  string
+	// PreferencesNamespace is the namespace of the ConfigMap storing user preferences.
+	// Empty disables the preferences API.
+	PreferencesNamespace string
+	// AllowedNamespaces, DeniedNamespaces and NamespaceSelector (a label selector)
+	// restrict the namespaces served by the API, on top of RBAC. Empty serves all
+	// namespaces the user may access.
+	AllowedNamespaces []string
+	DeniedNamespaces  []string
+	NamespaceSelector string
 }
 
 // Server is the KubeOpenCode UI server
@@ -64,6 +80,13 @@ type Server struct {
 	restConfig    *rest.Config
 	startTime     time.Time
 	clusterDomain string
+	oidcVerifier  *authmiddleware.OIDCVerifier
+	// taskCache is the informer cache used by list endpoints, with Task field indexes
+	taskCache cache.Cache
+	// accessReviews remembers whether users may list from the cache
+	accessReviews *accessReviewCache
+	// namespaceScope restricts the served namespaces; nil serves all namespaces
+	namespaceScope *namespaceScope
 }
 
 // New creates a new Server instance
@@ -74,7 +97,8 @@ func New(opts Options) (*Server, error) {
 		return nil, fmt.Errorf("failed to get kubeconfig: %w", err)
 	}
 
-	k8sClient, err := client.New(cfg, client.Options{Scheme: scheme})
+	// WithWatch is needed for streaming task updates
+	k8sClient, err := client.NewWithWatch(cfg, client.Options{Scheme: scheme})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
 	}
@@ -85,6 +109,10 @@ func New(opts Options) (*Server, error) {
 		return nil, fmt.Errorf("failed to create kubernetes clientset: %w", err)
 	}
 
+	if (opts.TLSCertFile == "") != (opts.TLSKeyFile == "") {
+		return nil, fmt.Errorf("both a TLS certificate and key file are required to serve HTTPS")
+	}
+
 	s := &Server{
 		opts:          opts,
 		k8sClient:     k8sClient,
@@ -94,6 +122,28 @@ func New(opts Options) (*Server, error) {
 		clusterDomain: "cluster.local", // Default value
 	}
 
+	// Informer cache for list endpoints; indexes must be registered before it starts
+	taskCache, err := cache.New(cfg, cache.Options{Scheme: scheme})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create task cache: %w", err)
+	}
+	if err := handlers.IndexTaskFields(context.Background(), taskCache); err != nil {
+		return nil, fmt.Errorf("failed to index tasks: %w", err)
+	}
+	s.taskCache = taskCache
+	s.accessReviews = newAccessReviewCache()
+
+	s.namespaceScope, err = newNamespaceScope(k8sClient, opts.AllowedNamespaces, opts.DeniedNamespaces, opts.NamespaceSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.OIDC.IssuerURL != "" {
+		if s.oidcVerifier, err = authmiddleware.NewOIDCVerifier(opts.OIDC); err != nil {
+			return nil, fmt.Errorf("invalid OIDC configuration: %w", err)
+		}
+	}
+
 	// Try to get cluster-scoped KubeOpenCodeConfig to set clusterDomain
 	config := &kubeopenv1alpha1.KubeOpenCodeConfig{}
 	configKey := client.ObjectKey{Name: "cluster"}
@@ -121,15 +171,39 @@ func (s *Server) Run(ctx context.Context) error {
 		Handler:           router,
 		ReadTimeout:       30 * time.Second,
 		ReadHeaderTimeout: 10 * time.Second,
-		WriteTimeout:      0, // Disabled for SSE support; regular requests use chi middleware timeout
+		WriteTimeout:      writeTimeout, // Lifted per request on streaming routes
 		IdleTimeout:       120 * time.Second,
 	}
 
 	// Start server in a goroutine
-	errChan := make(chan error, 1)
+	errChan := make(chan error, 3)
 	go func() {
-		log.Info("Starting HTTP server", "address", s.opts.Address)
-		if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := s.taskCache.Start(ctx); err != nil {
+			errChan <- fmt.Errorf("task cache: %w", err)
+		}
+	}()
+	if s.opts.TLSCertFile != "" {
+		tlsConfig, certWatcher, err := newServingTLSConfig(s.opts.TLSCertFile, s.opts.TLSKeyFile)
+		if err != nil {
+			return err
+		}
+		s.httpServer.TLSConfig = tlsConfig
+		go func() {
+			if err := certWatcher.Start(ctx); err != nil {
+				errChan <- fmt.Errorf("certificate watcher: %w", err)
+			}
+		}()
+	}
+	go func() {
+		var err error
+		if s.httpServer.TLSConfig != nil {
+			log.Info("Starting HTTPS server", "address", s.opts.Address)
+			err = s.httpServer.ListenAndServeTLS("", "")
+		} else {
+			log.Info("Starting HTTP server", "address", s.opts.Address)
+			err = s.httpServer.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			errChan <- err
 		}
 	}()
@@ -155,7 +229,7 @@ func (s *Server) setupRoutes() *chi.Mux {
 	r.Use(chimiddleware.RealIP)
 	r.Use(structuredLogger)
 	r.Use(chimiddleware.Recoverer)
-	r.Use(chimiddleware.Timeout(60 * time.Second))
+	r.Use(withRequestTimeout(requestTimeout))
 
 	// CORS middleware
 	if len(s.opts.CORSAllowedOrigins) > 0 {
@@ -173,11 +247,17 @@ func (s *Server) setupRoutes() *chi.Mux {
 		r.Use(chimiddleware.Throttle(20)) // max 20 concurrent share requests
 		r.Get("/", ui.ShareHandler(s.opts.BaseURL))
 		r.Get("/info", shareHandler.ServeShareInfo)
-		r.Get("/terminal", shareHandler.ServeShareTerminal)
+		r.With(streaming).Get("/terminal", shareHandler.ServeShareTerminal)
 	})
 
+	// OpenAPI document (no auth required — describes the API, not cluster data)
+	r.Get(openAPIPath, openAPIHandler(r))
+
 	// API routes
 	r.Route("/api/v1", func(r chi.Router) {
+		// Trace API requests, including rejected ones
+		r.Use(tracingMiddleware)
+
 		// Add rate limiting if configured
 		if s.opts.APIRateLimit > 0 {
 			r.Use(chimiddleware.Throttle(s.opts.APIRateLimit))
@@ -187,9 +267,18 @@ func (s *Server) setupRoutes() *chi.Mux {
 		authConfig := authmiddleware.AuthConfig{
 			Enabled:        s.opts.AuthEnabled,
 			AllowAnonymous: s.opts.AuthAllowAnonymous,
+			OIDC:           s.oidcVerifier,
 		}
 		r.Use(authmiddleware.Auth(s.clientset, authConfig))
 
+		// Record mutating requests with the authenticated user
+		r.Use(authmiddleware.Audit(ctrl.Log.WithName("audit")))
+
+		// Restrict the served namespaces if configured
+		if s.namespaceScope != nil {
+			r.Use(namespaceScopeMiddleware(s.namespaceScope))
+		}
+
 		// Create handlers with impersonation support
 		taskHandler := handlers.NewTaskHandler(s.k8sClient, s.clientset, s.restConfig)
 		agentHandler := handlers.NewAgentHandler(s.k8sClient)
@@ -202,18 +291,54 @@ func (s *Server) setupRoutes() *chi.Mux {
 		r.Get("/info", infoHandler.GetInfo)
 		r.Get("/namespaces", infoHandler.ListNamespaces)
 
+		// Permissions of the current user, used by the UI to hide actions
+		capabilitiesHandler := handlers.NewCapabilitiesHandler(s.clientset)
+		r.Get("/namespaces/{namespace}/capabilities", capabilitiesHandler.Get)
+
+		// Server-side apply of pasted manifests
+		applyHandler := handlers.NewApplyHandler(s.k8sClient)
+		r.Post("/apply", applyHandler.Apply)
+
+		// UI preferences of the authenticated user
+		preferencesHandler := handlers.NewPreferencesHandler(s.k8sClient, s.opts.PreferencesNamespace)
+		r.Get("/preferences", preferencesHandler.Get)
+		r.Put("/preferences", preferencesHandler.Update)
+
+		// Dashboard statistics
+		statsHandler := handlers.NewStatsHandler(s.k8sClient)
+		r.Get("/stats", statsHandler.Get)
+
+		// Reports on finished Tasks
+		reportHandler := handlers.NewReportHandler(s.k8sClient)
+		r.Get("/reports/tasks", reportHandler.Tasks)
+
+		// Comparison of two Tasks, e.g. a retry and its original
+		diffHandler := handlers.NewDiffHandler(s.k8sClient)
+		r.Get("/diff", diffHandler.Tasks)
+
 		// Task endpoints (all-namespaces)
 		r.Get("/tasks", taskHandler.ListAll)
+		r.With(streaming).Get("/tasks/watch", taskHandler.WatchAll)
 
 		// Task endpoints (namespace-scoped)
 		taskSessionHandler := handlers.NewTaskSessionHandler(s.k8sClient, s.clusterDomain)
+		taskMessageHandler := handlers.NewTaskMessageHandler(s.k8sClient, s.restConfig, s.clusterDomain)
 		r.Route("/namespaces/{namespace}/tasks", func(r chi.Router) {
 			r.Get("/", taskHandler.List)
 			r.Post("/", taskHandler.Create)
+			r.With(streaming).Get("/watch", taskHandler.Watch)
 			r.Get("/{name}", taskHandler.Get)
 			r.Delete("/{name}", taskHandler.Delete)
+			r.Patch("/{name}", taskHandler.Patch)
 			r.Post("/{name}/stop", taskHandler.Stop)
-			r.Get("/{name}/logs", taskHandler.GetLogs)
+			r.Post("/{name}/retry", taskHandler.Retry)
+			r.With(streaming).Get("/{name}/logs", taskHandler.GetLogs)
+			r.Get("/{name}/logs/download", taskHandler.DownloadLogs)
+			r.Get("/{name}/outputs", taskHandler.GetOutputs)
+			r.Post("/{name}/outputs", taskHandler.UploadOutputs)
+			r.Get("/{name}/outputs/{file}", taskHandler.GetOutputFile)
+			r.With(streaming).Get("/{name}/exec", taskHandler.Exec)
+			r.Post("/{name}/messages", taskMessageHandler.SendMessage)
 
 			// Session proxy — forwards to Agent's OpenCode server
 			r.Get("/{name}/session", taskSessionHandler.GetSession)
@@ -244,6 +369,7 @@ func (s *Server) setupRoutes() *chi.Mux {
 			r.Get("/{name}", agentTemplateHandler.Get)
 			r.Put("/{name}", agentTemplateHandler.Update)
 			r.Delete("/{name}", agentTemplateHandler.Delete)
+			r.Post("/{name}/render", agentTemplateHandler.Render)
 		})
 
 		// Config endpoint (cluster-scoped singleton)
@@ -280,13 +406,14 @@ func (s *Server) setupRoutes() *chi.Mux {
 			// Supports HTTP REST and SSE streaming for opencode attach
 			agentProxyHandler := handlers.NewAgentProxyHandler(s.k8sClient, s.clusterDomain)
 			r.Route("/{name}/proxy", func(r chi.Router) {
+				r.Use(streaming)
 				r.HandleFunc("/*", agentProxyHandler.ServeProxy)
 				r.HandleFunc("/", agentProxyHandler.ServeProxy)
 			})
 
 			// Agent terminal - WebSocket terminal to agent server pods
 			agentTerminalHandler := handlers.NewAgentTerminalHandler(s.k8sClient, s.clientset, s.restConfig)
-			r.Get("/{name}/terminal", agentTerminalHandler.ServeTerminal)
+			r.With(streaming).Get("/{name}/terminal", agentTerminalHandler.ServeTerminal)
 		})
 
 	})
@@ -341,9 +468,20 @@ func (s *Server) impersonationMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		userInfo := authmiddleware.GetUserInfo(r.Context())
 
+		// List endpoints read from the informer cache when the user may list the resource
+		ctx := r.Context()
+		if s.taskCache != nil {
+			ctx = context.WithValue(ctx, handlers.CachedReaderContextKey{}, handlers.CachedReader(&cachedReader{
+				cache:     s.taskCache,
+				clientset: s.clientset,
+				user:      userInfo,
+				reviews:   s.accessReviews,
+			}))
+		}
+
 		// If no user info (auth disabled or anonymous allowed), use default clients
 		if userInfo == nil {
-			ctx := context.WithValue(r.Context(), handlers.ClientContextKey{}, s.k8sClient)
+			ctx = context.WithValue(ctx, handlers.ClientContextKey{}, s.k8sClient)
 			ctx = context.WithValue(ctx, handlers.ClientsetContextKey{}, s.clientset)
 			next.ServeHTTP(w, r.WithContext(ctx))
 			return
@@ -358,7 +496,7 @@ func (s *Server) impersonationMiddleware(next http.Handler) http.Handler {
 		}
 
 		// Create impersonated client
-		impersonatedClient, err := client.New(impersonatedConfig, client.Options{Scheme: scheme})
+		impersonatedClient, err := client.NewWithWatch(impersonatedConfig, client.Options{Scheme: scheme})
 		if err != nil {
 			log.Error(err, "Failed to create impersonated client", "user", userInfo.Username)
 			http.Error(w, "Failed to create client", http.StatusInternalServerError)
@@ -373,7 +511,7 @@ func (s *Server) impersonationMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
-		ctx := context.WithValue(r.Context(), handlers.ClientContextKey{}, impersonatedClient)
+		ctx = context.WithValue(ctx, handlers.ClientContextKey{}, impersonatedClient)
 		ctx = context.WithValue(ctx, handlers.ClientsetContextKey{}, impersonatedClientset)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
@@ -414,8 +552,9 @@ func corsMiddleware(allowedOrigins []string) func(http.Handler) http.Handler {
 			origin := r.Header.Get("Origin")
 			if origin != "" && (allowAll || originSet[origin]) {
 				w.Header().Set("Access-Control-Allow-Origin", origin)
-				w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-				w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+				w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+				w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, If-None-Match")
+				w.Header().Set("Access-Control-Expose-Headers", "ETag")
 				w.Header().Set("Access-Control-Max-Age", "300")
 				w.Header().Set("Vary", strings.Join([]string{w.Header().Get("Vary"), "Origin"}, ", "))
 			}
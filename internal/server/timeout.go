@@ -0,0 +1,71 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package server
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// requestTimeout bounds the handling of regular API requests.
+	requestTimeout = 60 * time.Second
+
+	// writeTimeout bounds writing a response on the connection. It is longer than
+	// requestTimeout so that a handler that times out can still write its error.
+	writeTimeout = requestTimeout + 30*time.Second
+)
+
+// requestDeadline is stored in the request context by withRequestTimeout
+type requestDeadline struct {
+	// untimed is the request context before the timeout was applied
+	untimed context.Context
+	// lifted is set by streaming when the timeout no longer applies
+	lifted atomic.Bool
+}
+
+type requestDeadlineKey struct{}
+
+// withRequestTimeout cancels the request context after timeout and responds with
+// 504 Gateway Timeout if the handler returns after the deadline, like chi's Timeout
+// middleware. Routes wrapped with streaming are exempt.
+func withRequestTimeout(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			deadline := &requestDeadline{untimed: r.Context()}
+			ctx, cancel := context.WithTimeout(context.WithValue(r.Context(), requestDeadlineKey{}, deadline), timeout)
+			defer func() {
+				cancel()
+				if ctx.Err() == context.DeadlineExceeded && !deadline.lifted.Load() {
+					w.WriteHeader(http.StatusGatewayTimeout)
+				}
+			}()
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// streaming lifts the request timeout and the server's write timeout for long-lived
+// responses such as SSE streams, WebSockets and proxied agent connections. The request
+// context keeps its values and is still cancelled when the client disconnects.
+func streaming(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Not all response writers support deadlines (e.g., test recorders)
+		_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+		deadline, ok := r.Context().Value(requestDeadlineKey{}).(*requestDeadline)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		deadline.lifted.Store(true)
+
+		ctx, cancel := context.WithCancel(context.WithoutCancel(r.Context()))
+		defer cancel()
+		stop := context.AfterFunc(deadline.untimed, cancel)
+		defer stop()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
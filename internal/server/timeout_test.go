@@ -0,0 +1,90 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package server
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestRequestTimeout(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(withRequestTimeout(50 * time.Millisecond))
+	// Both handlers outlive the timeout, then report whether their context was cancelled
+	slow := func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(200 * time.Millisecond):
+			_, _ = io.WriteString(w, "done")
+		}
+	}
+	r.Get("/regular", slow)
+	r.With(streaming).Get("/stream", slow)
+
+	t.Run("regular route times out", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/regular", nil))
+		if w.Code != http.StatusGatewayTimeout {
+			t.Errorf("expected status %d, got %d", http.StatusGatewayTimeout, w.Code)
+		}
+	})
+
+	t.Run("streaming route is exempt", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/stream", nil))
+		if w.Code != http.StatusOK || w.Body.String() != "done" {
+			t.Errorf("expected 200 done, got %d %q", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("streaming route is cancelled on disconnect", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/stream", nil).WithContext(ctx))
+		if w.Body.Len() != 0 {
+			t.Errorf("expected the handler to stop on disconnect, got %q", w.Body.String())
+		}
+	})
+}
+
+func TestStreamingLiftsWriteTimeout(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		// Write after the server's write timeout has passed
+		time.Sleep(150 * time.Millisecond)
+		_, _ = io.WriteString(w, "done")
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/regular", handler)
+	mux.Handle("/stream", streaming(http.HandlerFunc(handler)))
+
+	srv := httptest.NewUnstartedServer(mux)
+	srv.Config.WriteTimeout = 50 * time.Millisecond
+	srv.Start()
+	defer srv.Close()
+
+	if _, err := readBody(srv.URL + "/regular"); err == nil {
+		t.Error("expected the regular response to hit the write timeout")
+	}
+	body, err := readBody(srv.URL + "/stream")
+	if err != nil || body != "done" {
+		t.Errorf("expected streaming response, got %q, %v", body, err)
+	}
+}
+
+func readBody(url string) (string, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+	return string(body), err
+}
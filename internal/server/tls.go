@@ -0,0 +1,25 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package server
+
+import (
+	"crypto/tls"
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
+)
+
+// newServingTLSConfig loads the serving certificate and returns a TLS config that
+// always presents the current certificate of the returned watcher. Once the watcher
+// is started, rotated certificate files (e.g. renewed by cert-manager) are picked
+// up without a restart.
+func newServingTLSConfig(certFile, keyFile string) (*tls.Config, *certwatcher.CertWatcher, error) {
+	watcher, err := certwatcher.New(certFile, keyFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: watcher.GetCertificate,
+	}, watcher, nil
+}
@@ -46,6 +46,12 @@ type AgentTemplateReference struct {
 	Name string `json:"name"`
 }
 
+// RetryTaskRequest represents a request to retry a finished task.
+// An empty description reuses the original task's description.
+type RetryTaskRequest struct {
+	Description string `json:"description,omitempty"`
+}
+
 // CreateTaskRequest represents a request to create a task
 type CreateTaskRequest struct {
 	Name        string                  `json:"name,omitempty"`
@@ -54,6 +60,7 @@ type CreateTaskRequest struct {
 	TemplateRef *AgentTemplateReference `json:"templateRef,omitempty"`
 	Timeout     string                  `json:"timeout,omitempty"`
 	Contexts    []ContextItem           `json:"contexts,omitempty"`
+	Params      map[string]string       `json:"params,omitempty"`
 }
 
 // CreateAgentRequest represents a request to create an agent
@@ -94,6 +101,18 @@ type CreateVolumePersistence struct {
 	Size             string `json:"size,omitempty"`
 }
 
+// PatchTaskRequest is a JSON merge patch of Task labels and annotations.
+// A null value removes the key.
+type PatchTaskRequest struct {
+	Metadata PatchMetadata `json:"metadata"`
+}
+
+// PatchMetadata holds the labels and annotations of a PatchTaskRequest
+type PatchMetadata struct {
+	Labels      map[string]*string `json:"labels,omitempty"`
+	Annotations map[string]*string `json:"annotations,omitempty"`
+}
+
 // TaskResponse represents a task in API responses
 type TaskResponse struct {
 	Name           string                  `json:"name"`
@@ -105,12 +124,21 @@ type TaskResponse struct {
 	Timeout        string                  `json:"timeout,omitempty"`
 	PodName        string                  `json:"podName,omitempty"`
 	Session        *SessionInfoResponse    `json:"session,omitempty"`
+	Result         *TaskResultResponse     `json:"result,omitempty"`
 	StartTime      *time.Time              `json:"startTime,omitempty"`
 	CompletionTime *time.Time              `json:"completionTime,omitempty"`
 	Duration       string                  `json:"duration,omitempty"`
 	CreatedAt      time.Time               `json:"createdAt"`
 	Conditions     []Condition             `json:"conditions,omitempty"`
 	Labels         map[string]string       `json:"labels,omitempty"`
+	Annotations    map[string]string       `json:"annotations,omitempty"`
+}
+
+// TaskResultResponse represents the result an agent reported (see status.result)
+type TaskResultResponse struct {
+	Status  string         `json:"status"`
+	Summary string         `json:"summary,omitempty"`
+	Fields  map[string]any `json:"fields,omitempty"`
 }
 
 // SessionInfoResponse represents session information in API responses
@@ -144,6 +172,9 @@ type Pagination struct {
 	Offset     int  `json:"offset"`
 	TotalCount int  `json:"totalCount"`
 	HasMore    bool `json:"hasMore"`
+	// Continue is an opaque cursor for the next page (pass it as ?continue=).
+	// Unlike offsets, cursors are not shifted by items created or deleted between pages.
+	Continue string `json:"continue,omitempty"`
 }
 
 // TaskListResponse represents a list of tasks
@@ -151,6 +182,8 @@ type TaskListResponse struct {
 	Tasks      []TaskResponse `json:"tasks"`
 	Total      int            `json:"total"` // Keep for backward compat
 	Pagination *Pagination    `json:"pagination,omitempty"`
+	// ResourceVersion of the list, to start a task watch without missing changes
+	ResourceVersion string `json:"resourceVersion,omitempty"`
 }
 
 // Condition represents a status condition
@@ -255,6 +288,135 @@ type AgentTemplateListResponse struct {
 	Pagination *Pagination             `json:"pagination,omitempty"`
 }
 
+// RenderAgentTemplateResponse is the effective spec of a (hypothetical) Task
+// that references an AgentTemplate
+type RenderAgentTemplateResponse struct {
+	Template string `json:"template"`
+	// Task is the Task spec with its description and Text contexts rendered with its params
+	Task kubeopenv1alpha1.TaskSpec `json:"task"`
+	// Agent is the spec the Task runs with: the template, with its Text contexts rendered
+	Agent kubeopenv1alpha1.AgentSpec `json:"agent"`
+	// Warnings lists problems that would keep a Task with this spec from running
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// TaskStatsResponse represents aggregate Task statistics
+type TaskStatsResponse struct {
+	Namespace   string         `json:"namespace,omitempty"`
+	Total       int            `json:"total"`
+	ByPhase     map[string]int `json:"byPhase"`
+	ByNamespace map[string]int `json:"byNamespace"`
+	// ByAgent and QueueDepths (Queued Tasks) are keyed by "namespace/agent"
+	ByAgent     map[string]int  `json:"byAgent"`
+	QueueDepths map[string]int  `json:"queueDepths"`
+	Window      TaskStatsWindow `json:"window"`
+}
+
+// TaskStatsWindow represents statistics of Tasks finished within a time window
+type TaskStatsWindow struct {
+	Duration               string  `json:"duration"`
+	Finished               int     `json:"finished"`
+	Failed                 int     `json:"failed"`
+	FailureRate            float64 `json:"failureRate"`
+	AverageDurationSeconds float64 `json:"averageDurationSeconds"`
+}
+
+// TaskReportResponse represents the Tasks that finished within a time range
+type TaskReportResponse struct {
+	Since   time.Time          `json:"since"`
+	Until   time.Time          `json:"until"`
+	Records []TaskReportRecord `json:"records"`
+}
+
+// TaskReportRecord represents one finished Task in a report.
+// Agent is empty for templateRef Tasks; Reason is the reason of the Ready condition.
+type TaskReportRecord struct {
+	Namespace       string     `json:"namespace"`
+	Name            string     `json:"name"`
+	Agent           string     `json:"agent,omitempty"`
+	Template        string     `json:"template,omitempty"`
+	CronTask        string     `json:"cronTask,omitempty"`
+	Phase           string     `json:"phase"`
+	Reason          string     `json:"reason,omitempty"`
+	StartTime       *time.Time `json:"startTime,omitempty"`
+	CompletionTime  time.Time  `json:"completionTime"`
+	DurationSeconds float64    `json:"durationSeconds"`
+	Cost            string     `json:"cost,omitempty"`
+}
+
+// TaskDiffResponse represents the differences between two Tasks (A and B).
+// Spec lists the spec fields whose values differ; Outputs compares captured output files.
+type TaskDiffResponse struct {
+	A       TaskDiffSide     `json:"a"`
+	B       TaskDiffSide     `json:"b"`
+	Spec    []SpecFieldDiff  `json:"spec"`
+	Outputs []OutputFileDiff `json:"outputs"`
+	// DurationDeltaSeconds is B's duration minus A's, when both have finished
+	DurationDeltaSeconds *float64 `json:"durationDeltaSeconds,omitempty"`
+}
+
+// TaskDiffSide summarizes one of the compared Tasks
+type TaskDiffSide struct {
+	Namespace       string   `json:"namespace"`
+	Name            string   `json:"name"`
+	Phase           string   `json:"phase"`
+	DurationSeconds *float64 `json:"durationSeconds,omitempty"`
+}
+
+// SpecFieldDiff is a spec field that differs between two Tasks.
+// Path uses dots for fields and [i] for list items, e.g. "contexts[0].text".
+// A or B is omitted when the field is not set on that Task.
+type SpecFieldDiff struct {
+	Path string `json:"path"`
+	A    any    `json:"a,omitempty"`
+	B    any    `json:"b,omitempty"`
+}
+
+// OutputFileDiff compares a captured output file of two Tasks.
+// Status is "added" (only B), "removed" (only A), "changed" or "unchanged";
+// contents are omitted for unchanged files.
+type OutputFileDiff struct {
+	File   string `json:"file"`
+	Status string `json:"status"`
+	A      string `json:"a,omitempty"`
+	B      string `json:"b,omitempty"`
+}
+
+// CapabilitiesResponse lists the actions the current user may perform in a namespace.
+// Resources maps a resource (e.g. "tasks", "pods/log") to verbs and whether they are allowed.
+type CapabilitiesResponse struct {
+	Namespace string                     `json:"namespace"`
+	Resources map[string]map[string]bool `json:"resources"`
+}
+
+// UserPreferences represents the UI settings of a user
+type UserPreferences struct {
+	FavoriteNamespaces []string `json:"favoriteNamespaces,omitempty"`
+	// DefaultAgent is the Agent preselected when creating Tasks, as "namespace/name"
+	DefaultAgent string `json:"defaultAgent,omitempty"`
+	// PageSize is the number of items per list page (0 = server default)
+	PageSize int `json:"pageSize,omitempty"`
+}
+
+// ApplyResponse represents the results of applying manifests
+type ApplyResponse struct {
+	Results []ApplyResult `json:"results"`
+	// Failed is the number of objects that could not be applied
+	Failed int `json:"failed"`
+}
+
+// ApplyResult represents the result of applying one object
+type ApplyResult struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace"`
+	Name       string `json:"name"`
+	Applied    bool   `json:"applied"`
+	// Conflict is set when fields are managed by another field manager (retry with force=true)
+	Conflict bool   `json:"conflict,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
 // StandbyInfo represents standby configuration in API requests/responses
 type StandbyInfo struct {
 	IdleTimeout string `json:"idleTimeout"`
@@ -288,6 +450,40 @@ type LogEvent struct {
 	Message  string  `json:"message,omitempty"`
 }
 
+// TaskWatchEvent represents a Server-Sent Event for task list updates.
+// Type is "ADDED", "MODIFIED" or "DELETED" with the Task, or "error" with a message.
+type TaskWatchEvent struct {
+	Type string        `json:"type"`
+	Task *TaskResponse `json:"task,omitempty"`
+	// ResourceVersion of the Task, to resume the watch after a disconnect
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+	Message         string `json:"message,omitempty"`
+}
+
+// TaskOutputsResponse represents the captured outputs of a task (see spec.captureOutputs).
+// Files maps each output file name to its content.
+type TaskOutputsResponse struct {
+	Name          string            `json:"name"`
+	Namespace     string            `json:"namespace"`
+	ConfigMapName string            `json:"configMapName"`
+	Files         map[string]string `json:"files"`
+}
+
+// SendTaskMessageRequest is a follow-up instruction for a running task
+type SendTaskMessageRequest struct {
+	Message string `json:"message"`
+}
+
+// TaskMessageResponse describes how a follow-up message was delivered.
+// Delivery is "session" when the message was sent to the Agent's OpenCode session,
+// or "workspace" when it was written to Path in the task Pod's workspace.
+type TaskMessageResponse struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Delivery  string `json:"delivery"`
+	Path      string `json:"path,omitempty"`
+}
+
 // HealthResponse represents the health endpoint response
 type HealthResponse struct {
 	Status  string `json:"status"`
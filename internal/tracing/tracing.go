@@ -0,0 +1,118 @@
+// Copyright Contributors to the KubeOpenCode project
+
+// Package tracing sets up OpenTelemetry tracing for the controller and the server.
+//
+// Tracing is enabled by the standard OTLP environment variables
+// (OTEL_EXPORTER_OTLP_ENDPOINT or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT) and exports
+// spans over OTLP/HTTP. The trace of a Task is carried from the request that
+// created it to the controller in the kubeopencode.io/traceparent annotation.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// TraceParentAnnotation holds the W3C traceparent of the trace a Task belongs to
+	TraceParentAnnotation = "kubeopencode.io/traceparent"
+
+	// instrumentationName is the name of the tracer used for all KubeOpenCode spans
+	instrumentationName = "github.com/kubeopencode/kubeopencode"
+)
+
+// propagator is the W3C Trace Context propagator used for HTTP headers and annotations
+var propagator = propagation.TraceContext{}
+
+// Enabled reports whether an OTLP endpoint is configured in the environment.
+func Enabled() bool {
+	return os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+}
+
+// Setup installs the global tracer provider for serviceName when tracing is enabled,
+// and the W3C Trace Context propagator in any case. The returned function flushes
+// and stops the exporter.
+func Setup(ctx context.Context, serviceName string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagator)
+	if !Enabled() {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+	}
+	// Attributes from OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES take precedence
+	res, err := resource.New(ctx,
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(semconv.ServiceName(serviceName)),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Tracer returns the KubeOpenCode tracer of the global tracer provider.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// RecordError records err on span and marks the span as failed.
+func RecordError(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// InjectAnnotation records the span context of ctx in the traceparent annotation of
+// obj, unless ctx has no valid span or obj already carries a trace.
+func InjectAnnotation(ctx context.Context, obj metav1.Object) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return
+	}
+	annotations := obj.GetAnnotations()
+	if annotations[TraceParentAnnotation] != "" {
+		return
+	}
+	carrier := propagation.MapCarrier{}
+	propagator.Inject(ctx, carrier)
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[TraceParentAnnotation] = carrier.Get("traceparent")
+	obj.SetAnnotations(annotations)
+}
+
+// ContextFromAnnotation returns ctx with the remote span context recorded in the
+// traceparent annotation of obj, so that new spans join the object's trace.
+func ContextFromAnnotation(ctx context.Context, obj metav1.Object) context.Context {
+	traceparent := obj.GetAnnotations()[TraceParentAnnotation]
+	if traceparent == "" {
+		return ctx
+	}
+	return propagator.Extract(ctx, propagation.MapCarrier{"traceparent": traceparent})
+}
+
+// ContextFromHeaders returns ctx with the remote span context from W3C Trace Context
+// headers, so that callers can connect their own traces.
+func ContextFromHeaders(ctx context.Context, headers propagation.HeaderCarrier) context.Context {
+	return propagator.Extract(ctx, headers)
+}
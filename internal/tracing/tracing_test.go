@@ -0,0 +1,72 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testSpanContext(t *testing.T) trace.SpanContext {
+	t.Helper()
+	traceID, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	if err != nil {
+		t.Fatal(err)
+	}
+	spanID, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	})
+}
+
+func TestAnnotationRoundTrip(t *testing.T) {
+	sc := testSpanContext(t)
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+
+	obj := &metav1.ObjectMeta{}
+	InjectAnnotation(ctx, obj)
+	want := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	if got := obj.GetAnnotations()[TraceParentAnnotation]; got != want {
+		t.Fatalf("traceparent annotation = %q, want %q", got, want)
+	}
+
+	extracted := trace.SpanContextFromContext(ContextFromAnnotation(context.Background(), obj))
+	if extracted.TraceID() != sc.TraceID() || extracted.SpanID() != sc.SpanID() {
+		t.Errorf("extracted span context = %v, want %v", extracted, sc)
+	}
+	if !extracted.IsRemote() {
+		t.Error("extracted span context should be remote")
+	}
+}
+
+func TestInjectAnnotationKeepsExistingTrace(t *testing.T) {
+	existing := "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+	obj := &metav1.ObjectMeta{Annotations: map[string]string{TraceParentAnnotation: existing}}
+	InjectAnnotation(trace.ContextWithSpanContext(context.Background(), testSpanContext(t)), obj)
+	if got := obj.Annotations[TraceParentAnnotation]; got != existing {
+		t.Errorf("traceparent annotation = %q, want %q", got, existing)
+	}
+}
+
+func TestInjectAnnotationWithoutSpan(t *testing.T) {
+	obj := &metav1.ObjectMeta{}
+	InjectAnnotation(context.Background(), obj)
+	if obj.Annotations != nil {
+		t.Errorf("annotations = %v, want none", obj.Annotations)
+	}
+}
+
+func TestContextFromAnnotationWithoutAnnotation(t *testing.T) {
+	ctx := ContextFromAnnotation(context.Background(), &metav1.ObjectMeta{})
+	if trace.SpanContextFromContext(ctx).IsValid() {
+		t.Error("expected no span context without annotation")
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package webhook
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+	"github.com/kubeopencode/kubeopencode/internal/controller"
+)
+
+// +kubebuilder:webhook:path=/validate-kubeopencode-io-v1alpha1-agent,mutating=false,failurePolicy=fail,sideEffects=None,groups=kubeopencode.io,resources=agents,verbs=create;update,versions=v1alpha1,name=vagent.kubeopencode.io,admissionReviewVersions=v1
+
+// AgentValidator validates Agents on create and update.
+type AgentValidator struct{}
+
+var _ admission.Validator[*kubeopenv1alpha1.Agent] = &AgentValidator{}
+
+// ValidateCreate validates a new Agent.
+func (v *AgentValidator) ValidateCreate(_ context.Context, agent *kubeopenv1alpha1.Agent) (admission.Warnings, error) {
+	return nil, invalid("Agent", agent.Name, validateAgentSpec(field.NewPath("spec"), &agent.Spec))
+}
+
+// ValidateUpdate validates an updated Agent. Updates that keep the spec are always
+// allowed, so that the controller can keep managing Agents created before validation.
+func (v *AgentValidator) ValidateUpdate(_ context.Context, oldAgent, agent *kubeopenv1alpha1.Agent) (admission.Warnings, error) {
+	if equality.Semantic.DeepEqual(oldAgent.Spec, agent.Spec) {
+		return nil, nil
+	}
+	return nil, invalid("Agent", agent.Name, validateAgentSpec(field.NewPath("spec"), &agent.Spec))
+}
+
+// ValidateDelete allows all deletions.
+func (v *AgentValidator) ValidateDelete(_ context.Context, _ *kubeopenv1alpha1.Agent) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateAgentSpec validates the Agent's own fields; fields inherited from its
+// AgentTemplate are validated with the template.
+func validateAgentSpec(fldPath *field.Path, spec *kubeopenv1alpha1.AgentSpec) field.ErrorList {
+	var errs field.ErrorList
+	errs = append(errs, validateWorkspaceDir(fldPath.Child("workspaceDir"), spec.WorkspaceDir)...)
+	errs = append(errs, validateContexts(fldPath.Child("contexts"), spec.Contexts, spec.WorkspaceDir)...)
+	errs = append(errs, validateCredentials(fldPath.Child("credentials"), spec.Credentials)...)
+
+	port := spec.Port
+	if port == 0 {
+		port = controller.DefaultServerPort
+	}
+	errs = append(errs, validateExtraPorts(fldPath.Child("extraPorts"), port, spec.ExtraPorts)...)
+	return errs
+}
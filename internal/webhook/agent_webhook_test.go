@@ -0,0 +1,136 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package webhook
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+)
+
+func TestAgentValidator(t *testing.T) {
+	newAgent := func(mutate func(*kubeopenv1alpha1.AgentSpec)) *kubeopenv1alpha1.Agent {
+		agent := &kubeopenv1alpha1.Agent{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "coder"},
+			Spec: kubeopenv1alpha1.AgentSpec{
+				WorkspaceDir:       "/workspace",
+				ServiceAccountName: "agent",
+				ExtraPorts:         []kubeopenv1alpha1.ExtraPort{{Name: "webapp", Port: 3000}},
+				Credentials: []kubeopenv1alpha1.Credential{
+					{Name: "github", SecretRef: kubeopenv1alpha1.SecretReference{Name: "github", Key: ptr.To("token")}, Env: ptr.To("GITHUB_TOKEN")},
+					{Name: "ssh", SecretRef: kubeopenv1alpha1.SecretReference{Name: "ssh"}, MountPath: ptr.To("/home/agent/.ssh")},
+				},
+			},
+		}
+		if mutate != nil {
+			mutate(&agent.Spec)
+		}
+		return agent
+	}
+
+	tests := []struct {
+		name    string
+		mutate  func(*kubeopenv1alpha1.AgentSpec)
+		wantErr string
+	}{
+		{name: "valid"},
+		{
+			name:    "relative workspace dir",
+			mutate:  func(spec *kubeopenv1alpha1.AgentSpec) { spec.WorkspaceDir = "workspace" },
+			wantErr: "spec.workspaceDir",
+		},
+		{
+			name: "extra port clashes with the default server port",
+			mutate: func(spec *kubeopenv1alpha1.AgentSpec) {
+				spec.ExtraPorts = append(spec.ExtraPorts, kubeopenv1alpha1.ExtraPort{Name: "other", Port: 4096})
+			},
+			wantErr: "spec.extraPorts[1].port",
+		},
+		{
+			name: "extra port clashes with a custom server port",
+			mutate: func(spec *kubeopenv1alpha1.AgentSpec) {
+				spec.Port = 3000
+			},
+			wantErr: "spec.extraPorts[0].port",
+		},
+		{
+			name: "extra port named like the server port",
+			mutate: func(spec *kubeopenv1alpha1.AgentSpec) {
+				spec.ExtraPorts[0].Name = "http"
+			},
+			wantErr: "spec.extraPorts[0].name",
+		},
+		{
+			name: "same port with another protocol",
+			mutate: func(spec *kubeopenv1alpha1.AgentSpec) {
+				spec.ExtraPorts = append(spec.ExtraPorts, kubeopenv1alpha1.ExtraPort{Name: "dns", Port: 3000, Protocol: "UDP"})
+			},
+		},
+		{
+			name: "invalid env name",
+			mutate: func(spec *kubeopenv1alpha1.AgentSpec) {
+				spec.Credentials[0].Env = ptr.To("GITHUB-TOKEN=")
+			},
+			wantErr: "spec.credentials[0].env",
+		},
+		{
+			name: "key without env or mount path",
+			mutate: func(spec *kubeopenv1alpha1.AgentSpec) {
+				spec.Credentials[0].Env = nil
+			},
+			wantErr: "spec.credentials[0]",
+		},
+		{
+			name: "relative credential mount path",
+			mutate: func(spec *kubeopenv1alpha1.AgentSpec) {
+				spec.Credentials[1].MountPath = ptr.To(".ssh")
+			},
+			wantErr: "spec.credentials[1].mountPath",
+		},
+		{
+			name: "context mount paths resolving to the same path",
+			mutate: func(spec *kubeopenv1alpha1.AgentSpec) {
+				spec.Contexts = []kubeopenv1alpha1.ContextItem{
+					{Name: "a", Type: kubeopenv1alpha1.ContextTypeText, Text: "a", MountPath: "docs/guide.md"},
+					{Name: "b", Type: kubeopenv1alpha1.ContextTypeText, Text: "b", MountPath: "/workspace/docs/guide.md"},
+				}
+			},
+			wantErr: "mount path conflict",
+		},
+		{
+			name: "missing secret name",
+			mutate: func(spec *kubeopenv1alpha1.AgentSpec) {
+				spec.Credentials[1].SecretRef.Name = ""
+			},
+			wantErr: "spec.credentials[1].secretRef.name",
+		},
+		{
+			name: "duplicate credential names",
+			mutate: func(spec *kubeopenv1alpha1.AgentSpec) {
+				spec.Credentials[1].Name = "github"
+			},
+			wantErr: "spec.credentials[1].name",
+		},
+	}
+
+	v := &AgentValidator{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := v.ValidateCreate(context.Background(), newAgent(tt.mutate))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
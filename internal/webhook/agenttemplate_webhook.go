@@ -0,0 +1,50 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package webhook
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+)
+
+// +kubebuilder:webhook:path=/validate-kubeopencode-io-v1alpha1-agenttemplate,mutating=false,failurePolicy=fail,sideEffects=None,groups=kubeopencode.io,resources=agenttemplates,verbs=create;update,versions=v1alpha1,name=vagenttemplate.kubeopencode.io,admissionReviewVersions=v1
+
+// AgentTemplateValidator validates AgentTemplates on create and update.
+type AgentTemplateValidator struct{}
+
+var _ admission.Validator[*kubeopenv1alpha1.AgentTemplate] = &AgentTemplateValidator{}
+
+// ValidateCreate validates a new AgentTemplate.
+func (v *AgentTemplateValidator) ValidateCreate(_ context.Context, tmpl *kubeopenv1alpha1.AgentTemplate) (admission.Warnings, error) {
+	return nil, invalid("AgentTemplate", tmpl.Name, validateAgentTemplateSpec(field.NewPath("spec"), &tmpl.Spec))
+}
+
+// ValidateUpdate validates an updated AgentTemplate. Updates that keep the spec are
+// always allowed.
+func (v *AgentTemplateValidator) ValidateUpdate(_ context.Context, oldTmpl, tmpl *kubeopenv1alpha1.AgentTemplate) (admission.Warnings, error) {
+	if equality.Semantic.DeepEqual(oldTmpl.Spec, tmpl.Spec) {
+		return nil, nil
+	}
+	return nil, invalid("AgentTemplate", tmpl.Name, validateAgentTemplateSpec(field.NewPath("spec"), &tmpl.Spec))
+}
+
+// ValidateDelete allows all deletions.
+func (v *AgentTemplateValidator) ValidateDelete(_ context.Context, _ *kubeopenv1alpha1.AgentTemplate) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateAgentTemplateSpec validates an AgentTemplate. Agents may override the server
+// port, so extra ports are not checked against it here.
+func validateAgentTemplateSpec(fldPath *field.Path, spec *kubeopenv1alpha1.AgentTemplateSpec) field.ErrorList {
+	var errs field.ErrorList
+	errs = append(errs, validateWorkspaceDir(fldPath.Child("workspaceDir"), spec.WorkspaceDir)...)
+	errs = append(errs, validateContexts(fldPath.Child("contexts"), spec.Contexts, spec.WorkspaceDir)...)
+	errs = append(errs, validateCredentials(fldPath.Child("credentials"), spec.Credentials)...)
+	errs = append(errs, validateExtraPorts(fldPath.Child("extraPorts"), 0, spec.ExtraPorts)...)
+	return errs
+}
@@ -0,0 +1,51 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package webhook
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+	"github.com/kubeopencode/kubeopencode/internal/controller"
+)
+
+// +kubebuilder:webhook:path=/validate-kubeopencode-io-v1alpha1-crontask,mutating=false,failurePolicy=fail,sideEffects=None,groups=kubeopencode.io,resources=crontasks,verbs=create;update,versions=v1alpha1,name=vcrontask.kubeopencode.io,admissionReviewVersions=v1
+
+// CronTaskValidator validates CronTasks, including their Task template, on create
+// and update.
+type CronTaskValidator struct{}
+
+var _ admission.Validator[*kubeopenv1alpha1.CronTask] = &CronTaskValidator{}
+
+// ValidateCreate validates a new CronTask.
+func (v *CronTaskValidator) ValidateCreate(_ context.Context, cronTask *kubeopenv1alpha1.CronTask) (admission.Warnings, error) {
+	return nil, invalid("CronTask", cronTask.Name, validateCronTaskSpec(field.NewPath("spec"), &cronTask.Spec))
+}
+
+// ValidateUpdate validates an updated CronTask. Updates that keep the spec (e.g. the
+// trigger annotation) are always allowed.
+func (v *CronTaskValidator) ValidateUpdate(_ context.Context, oldCronTask, cronTask *kubeopenv1alpha1.CronTask) (admission.Warnings, error) {
+	if equality.Semantic.DeepEqual(oldCronTask.Spec, cronTask.Spec) {
+		return nil, nil
+	}
+	return nil, invalid("CronTask", cronTask.Name, validateCronTaskSpec(field.NewPath("spec"), &cronTask.Spec))
+}
+
+// ValidateDelete allows all deletions.
+func (v *CronTaskValidator) ValidateDelete(_ context.Context, _ *kubeopenv1alpha1.CronTask) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateCronTaskSpec validates the schedule and the Task template
+func validateCronTaskSpec(fldPath *field.Path, spec *kubeopenv1alpha1.CronTaskSpec) field.ErrorList {
+	var errs field.ErrorList
+	if _, err := controller.ParseCronSchedule(spec.Schedule, spec.TimeZone); err != nil {
+		errs = append(errs, field.Invalid(fldPath.Child("schedule"), spec.Schedule, err.Error()))
+	}
+	errs = append(errs, validateTaskSpec(fldPath.Child("taskTemplate", "spec"), "", &spec.TaskTemplate.Spec)...)
+	return errs
+}
@@ -0,0 +1,74 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package webhook
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+)
+
+func TestCronTaskValidator(t *testing.T) {
+	newCronTask := func(mutate func(*kubeopenv1alpha1.CronTaskSpec)) *kubeopenv1alpha1.CronTask {
+		cronTask := &kubeopenv1alpha1.CronTask{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "nightly"},
+			Spec: kubeopenv1alpha1.CronTaskSpec{
+				Schedule: "0 2 * * *",
+				TaskTemplate: kubeopenv1alpha1.TaskTemplateSpec{
+					Spec: kubeopenv1alpha1.TaskSpec{
+						Description: ptr.To("Update dependencies"),
+						AgentRef:    &kubeopenv1alpha1.AgentReference{Name: "coder"},
+					},
+				},
+			},
+		}
+		if mutate != nil {
+			mutate(&cronTask.Spec)
+		}
+		return cronTask
+	}
+
+	tests := []struct {
+		name    string
+		mutate  func(*kubeopenv1alpha1.CronTaskSpec)
+		wantErr string
+	}{
+		{name: "valid"},
+		{
+			name:    "invalid schedule",
+			mutate:  func(spec *kubeopenv1alpha1.CronTaskSpec) { spec.Schedule = "every night" },
+			wantErr: "spec.schedule",
+		},
+		{
+			name:    "invalid time zone",
+			mutate:  func(spec *kubeopenv1alpha1.CronTaskSpec) { spec.TimeZone = ptr.To("Mars/Olympus") },
+			wantErr: "invalid timezone",
+		},
+		{
+			name:    "invalid task template",
+			mutate:  func(spec *kubeopenv1alpha1.CronTaskSpec) { spec.TaskTemplate.Spec.Description = nil },
+			wantErr: "spec.taskTemplate.spec.description",
+		},
+	}
+
+	v := &CronTaskValidator{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := v.ValidateCreate(context.Background(), newCronTask(tt.mutate))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+	"github.com/kubeopencode/kubeopencode/internal/controller"
+)
+
+const (
+	// DefaultWorkspaceDir is the workspace directory of Agents that neither set one
+	// nor inherit one from an AgentTemplate
+	DefaultWorkspaceDir = "/workspace"
+
+	// DefaultAgentName is the Agent used by Tasks that reference neither an Agent
+	// nor an AgentTemplate, if it exists in the Task's namespace and neither the
+	// namespace nor KubeOpenCodeConfig names another default Agent
+	DefaultAgentName = "default"
+
+	// DefaultAgentAnnotation on a namespace names the default Agent of its Tasks,
+	// overriding KubeOpenCodeConfig spec.defaults.agentRef
+	DefaultAgentAnnotation = "kubeopencode.io/default-agent"
+)
+
+// +kubebuilder:webhook:path=/mutate-kubeopencode-io-v1alpha1-task,mutating=true,failurePolicy=fail,sideEffects=None,groups=kubeopencode.io,resources=tasks,verbs=create,versions=v1alpha1,name=mtask.kubeopencode.io,admissionReviewVersions=v1
+
+// TaskDefaulter fills in Task defaults on creation, so that they are visible on the
+// stored object:
+//   - agentRef to the default Agent when neither agentRef nor templateRef is set
+//     and the default Agent exists in the namespace. The default Agent is named by
+//     the namespace's kubeopencode.io/default-agent annotation, else by
+//     KubeOpenCodeConfig spec.defaults.agentRef, else "default".
+//   - the kubeopencode.io/agent or kubeopencode.io/agent-template label, which the
+//     controller would otherwise add on its first reconcile
+//
+// The controller does not resolve the default Agent, so it only applies while the
+// webhook is enabled; the CRD schema rejects Tasks without either reference otherwise.
+type TaskDefaulter struct {
+	// Reader looks up the default Agent, its namespace and KubeOpenCodeConfig
+	Reader client.Reader
+}
+
+var _ admission.Defaulter[*kubeopenv1alpha1.Task] = &TaskDefaulter{}
+
+// Default sets the Task defaults.
+func (d *TaskDefaulter) Default(ctx context.Context, task *kubeopenv1alpha1.Task) error {
+	if task.Spec.AgentRef == nil && task.Spec.TemplateRef == nil {
+		name, err := d.defaultAgentName(ctx, task.Namespace)
+		if err != nil {
+			return err
+		}
+		var agent kubeopenv1alpha1.Agent
+		err = d.Reader.Get(ctx, client.ObjectKey{Namespace: task.Namespace, Name: name}, &agent)
+		switch {
+		case err == nil:
+			task.Spec.AgentRef = &kubeopenv1alpha1.AgentReference{Name: name}
+		case !apierrors.IsNotFound(err):
+			return fmt.Errorf("failed to look up the default Agent: %w", err)
+		}
+	}
+
+	switch {
+	case task.Spec.AgentRef != nil:
+		setLabelDefault(task, controller.AgentLabelKey, task.Spec.AgentRef.Name)
+	case task.Spec.TemplateRef != nil:
+		setLabelDefault(task, controller.AgentTemplateLabelKey, task.Spec.TemplateRef.Name)
+	}
+	return nil
+}
+
+// defaultAgentName returns the name of the default Agent of namespace
+func (d *TaskDefaulter) defaultAgentName(ctx context.Context, namespace string) (string, error) {
+	var ns corev1.Namespace
+	err := d.Reader.Get(ctx, client.ObjectKey{Name: namespace}, &ns)
+	switch {
+	case err == nil:
+		if name := ns.Annotations[DefaultAgentAnnotation]; name != "" {
+			return name, nil
+		}
+	case !apierrors.IsNotFound(err):
+		return "", fmt.Errorf("failed to look up the namespace: %w", err)
+	}
+
+	var config kubeopenv1alpha1.KubeOpenCodeConfig
+	err = d.Reader.Get(ctx, client.ObjectKey{Name: controller.KubeOpenCodeConfigName}, &config)
+	switch {
+	case err == nil:
+		if defaults := config.Spec.Defaults; defaults != nil && defaults.AgentRef != nil && defaults.AgentRef.Name != "" {
+			return defaults.AgentRef.Name, nil
+		}
+	case !apierrors.IsNotFound(err):
+		return "", fmt.Errorf("failed to look up KubeOpenCodeConfig: %w", err)
+	}
+
+	return DefaultAgentName, nil
+}
+
+// +kubebuilder:webhook:path=/mutate-kubeopencode-io-v1alpha1-agent,mutating=true,failurePolicy=fail,sideEffects=None,groups=kubeopencode.io,resources=agents,verbs=create;update,versions=v1alpha1,name=magent.kubeopencode.io,admissionReviewVersions=v1
+
+// AgentDefaulter fills in Agent defaults on creation and update:
+//   - workspaceDir "/workspace" when it is not set and no AgentTemplate is referenced
+//   - port 4096 when it is not set
+type AgentDefaulter struct{}
+
+var _ admission.Defaulter[*kubeopenv1alpha1.Agent] = &AgentDefaulter{}
+
+// Default sets the Agent defaults.
+func (d *AgentDefaulter) Default(_ context.Context, agent *kubeopenv1alpha1.Agent) error {
+	if agent.Spec.WorkspaceDir == "" && agent.Spec.TemplateRef == nil {
+		agent.Spec.WorkspaceDir = DefaultWorkspaceDir
+	}
+	if agent.Spec.Port == 0 {
+		agent.Spec.Port = controller.DefaultServerPort
+	}
+	return nil
+}
+
+// setLabelDefault sets a label unless the object already has it
+func setLabelDefault(obj client.Object, key, value string) {
+	labels := obj.GetLabels()
+	if _, ok := labels[key]; ok {
+		return
+	}
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[key] = value
+	obj.SetLabels(labels)
+}
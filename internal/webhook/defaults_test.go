@@ -0,0 +1,186 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package webhook
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	clientfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+	"github.com/kubeopencode/kubeopencode/internal/controller"
+)
+
+func TestTaskDefaulter(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = kubeopenv1alpha1.AddToScheme(scheme)
+	reader := clientfake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&kubeopenv1alpha1.Agent{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: DefaultAgentName}},
+	).Build()
+	defaulter := &TaskDefaulter{Reader: reader}
+
+	tests := []struct {
+		name       string
+		namespace  string
+		spec       kubeopenv1alpha1.TaskSpec
+		labels     map[string]string
+		wantAgent  string
+		wantLabels map[string]string
+	}{
+		{
+			name:       "default agent",
+			namespace:  "team-a",
+			wantAgent:  DefaultAgentName,
+			wantLabels: map[string]string{controller.AgentLabelKey: DefaultAgentName},
+		},
+		{
+			name:       "no default agent in namespace",
+			namespace:  "team-b",
+			wantLabels: nil,
+		},
+		{
+			name:       "explicit agent",
+			namespace:  "team-a",
+			spec:       kubeopenv1alpha1.TaskSpec{AgentRef: &kubeopenv1alpha1.AgentReference{Name: "coder"}},
+			wantAgent:  "coder",
+			wantLabels: map[string]string{controller.AgentLabelKey: "coder"},
+		},
+		{
+			name:       "template",
+			namespace:  "team-a",
+			spec:       kubeopenv1alpha1.TaskSpec{TemplateRef: &kubeopenv1alpha1.AgentTemplateReference{Name: "base"}},
+			wantLabels: map[string]string{controller.AgentTemplateLabelKey: "base"},
+		},
+		{
+			name:       "existing label is kept",
+			namespace:  "team-a",
+			spec:       kubeopenv1alpha1.TaskSpec{AgentRef: &kubeopenv1alpha1.AgentReference{Name: "coder"}},
+			labels:     map[string]string{controller.AgentLabelKey: "other", "team": "a"},
+			wantAgent:  "coder",
+			wantLabels: map[string]string{controller.AgentLabelKey: "other", "team": "a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &kubeopenv1alpha1.Task{
+				ObjectMeta: metav1.ObjectMeta{Namespace: tt.namespace, Name: "task", Labels: tt.labels},
+				Spec:       tt.spec,
+			}
+			if err := defaulter.Default(context.Background(), task); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			gotAgent := ""
+			if task.Spec.AgentRef != nil {
+				gotAgent = task.Spec.AgentRef.Name
+			}
+			if gotAgent != tt.wantAgent {
+				t.Errorf("agentRef = %q, want %q", gotAgent, tt.wantAgent)
+			}
+			if len(task.Labels) != len(tt.wantLabels) {
+				t.Fatalf("labels = %v, want %v", task.Labels, tt.wantLabels)
+			}
+			for k, v := range tt.wantLabels {
+				if task.Labels[k] != v {
+					t.Errorf("label %s = %q, want %q", k, task.Labels[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestTaskDefaulterConfiguredDefaultAgent(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = kubeopenv1alpha1.AddToScheme(scheme)
+	reader := clientfake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&kubeopenv1alpha1.KubeOpenCodeConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: controller.KubeOpenCodeConfigName},
+			Spec: kubeopenv1alpha1.KubeOpenCodeConfigSpec{
+				Defaults: &kubeopenv1alpha1.DefaultsConfig{AgentRef: &kubeopenv1alpha1.AgentReference{Name: "shared"}},
+			},
+		},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:        "team-b",
+			Annotations: map[string]string{DefaultAgentAnnotation: "team-b-agent"},
+		}},
+		&kubeopenv1alpha1.Agent{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: DefaultAgentName}},
+		&kubeopenv1alpha1.Agent{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "shared"}},
+		&kubeopenv1alpha1.Agent{ObjectMeta: metav1.ObjectMeta{Namespace: "team-b", Name: "shared"}},
+		&kubeopenv1alpha1.Agent{ObjectMeta: metav1.ObjectMeta{Namespace: "team-b", Name: "team-b-agent"}},
+		&kubeopenv1alpha1.Agent{ObjectMeta: metav1.ObjectMeta{Namespace: "team-c", Name: DefaultAgentName}},
+	).Build()
+	defaulter := &TaskDefaulter{Reader: reader}
+
+	tests := []struct {
+		namespace string
+		wantAgent string
+	}{
+		{namespace: "team-a", wantAgent: "shared"},
+		{namespace: "team-b", wantAgent: "team-b-agent"},
+		{namespace: "team-c", wantAgent: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.namespace, func(t *testing.T) {
+			task := &kubeopenv1alpha1.Task{ObjectMeta: metav1.ObjectMeta{Namespace: tt.namespace, Name: "task"}}
+			if err := defaulter.Default(context.Background(), task); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			gotAgent := ""
+			if task.Spec.AgentRef != nil {
+				gotAgent = task.Spec.AgentRef.Name
+			}
+			if gotAgent != tt.wantAgent {
+				t.Errorf("agentRef = %q, want %q", gotAgent, tt.wantAgent)
+			}
+		})
+	}
+}
+
+func TestAgentDefaulter(t *testing.T) {
+	tests := []struct {
+		name          string
+		spec          kubeopenv1alpha1.AgentSpec
+		wantWorkspace string
+		wantPort      int32
+	}{
+		{
+			name:          "empty spec",
+			wantWorkspace: DefaultWorkspaceDir,
+			wantPort:      controller.DefaultServerPort,
+		},
+		{
+			name:          "explicit values are kept",
+			spec:          kubeopenv1alpha1.AgentSpec{WorkspaceDir: "/src", Port: 8080},
+			wantWorkspace: "/src",
+			wantPort:      8080,
+		},
+		{
+			name:          "workspaceDir is inherited from the template",
+			spec:          kubeopenv1alpha1.AgentSpec{TemplateRef: &kubeopenv1alpha1.AgentTemplateReference{Name: "base"}},
+			wantWorkspace: "",
+			wantPort:      controller.DefaultServerPort,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agent := &kubeopenv1alpha1.Agent{Spec: *tt.spec.DeepCopy()}
+			if err := (&AgentDefaulter{}).Default(context.Background(), agent); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if agent.Spec.WorkspaceDir != tt.wantWorkspace {
+				t.Errorf("workspaceDir = %q, want %q", agent.Spec.WorkspaceDir, tt.wantWorkspace)
+			}
+			if agent.Spec.Port != tt.wantPort {
+				t.Errorf("port = %d, want %d", agent.Spec.Port, tt.wantPort)
+			}
+		})
+	}
+}
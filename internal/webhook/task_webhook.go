@@ -0,0 +1,68 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package webhook
+
+import (
+	"context"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+	"github.com/kubeopencode/kubeopencode/internal/controller"
+)
+
+// +kubebuilder:webhook:path=/validate-kubeopencode-io-v1alpha1-task,mutating=false,failurePolicy=fail,sideEffects=None,groups=kubeopencode.io,resources=tasks,verbs=create;update,versions=v1alpha1,name=vtask.kubeopencode.io,admissionReviewVersions=v1
+
+// TaskValidator validates Tasks on create and update.
+type TaskValidator struct{}
+
+var _ admission.Validator[*kubeopenv1alpha1.Task] = &TaskValidator{}
+
+// ValidateCreate validates a new Task.
+func (v *TaskValidator) ValidateCreate(_ context.Context, task *kubeopenv1alpha1.Task) (admission.Warnings, error) {
+	return nil, invalid("Task", task.Name, validateTaskSpec(field.NewPath("spec"), task.Name, &task.Spec))
+}
+
+// ValidateUpdate validates an updated Task. Updates that keep the spec are always
+// allowed, so that Tasks created before validation can still be stopped and cleaned up.
+func (v *TaskValidator) ValidateUpdate(_ context.Context, oldTask, task *kubeopenv1alpha1.Task) (admission.Warnings, error) {
+	if equality.Semantic.DeepEqual(oldTask.Spec, task.Spec) {
+		return nil, nil
+	}
+	return nil, invalid("Task", task.Name, validateTaskSpec(field.NewPath("spec"), task.Name, &task.Spec))
+}
+
+// ValidateDelete allows all deletions.
+func (v *TaskValidator) ValidateDelete(_ context.Context, _ *kubeopenv1alpha1.Task) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateTaskSpec validates a TaskSpec of the Task name, which is empty for CronTask
+// templates. The agentRef/templateRef choice is enforced by the CRD schema.
+func validateTaskSpec(fldPath *field.Path, name string, spec *kubeopenv1alpha1.TaskSpec) field.ErrorList {
+	var errs field.ErrorList
+	if (spec.Description == nil || strings.TrimSpace(*spec.Description) == "") && len(spec.Contexts) == 0 {
+		errs = append(errs, field.Required(fldPath.Child("description"), "a description or at least one context is required"))
+	}
+	if spec.Timeout != nil && spec.Timeout.Duration <= 0 {
+		errs = append(errs, field.Invalid(fldPath.Child("timeout"), spec.Timeout.Duration.String(), "must be positive"))
+	}
+
+	contextsPath := fldPath.Child("contexts")
+	errs = append(errs, validateContexts(contextsPath, spec.Contexts, "")...)
+	for i := range spec.Contexts {
+		if out := spec.Contexts[i].TaskOutput; out != nil && name != "" && out.Name == name {
+			errs = append(errs, field.Invalid(contextsPath.Index(i).Child("taskOutput", "name"), out.Name, "a Task cannot use its own outputs"))
+		}
+	}
+	// Size limit of the description and inline Text contexts
+	if len(errs) == 0 {
+		if err := controller.ValidateTaskContexts(&kubeopenv1alpha1.Task{Spec: *spec}, ""); err != nil {
+			errs = append(errs, field.Invalid(contextsPath, "", err.Error()))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,128 @@
+// Copyright Contributors to the KubeOpenCode project
+
+package webhook
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+)
+
+func TestTaskValidator(t *testing.T) {
+	description := "Fix the flaky test"
+	newTask := func(mutate func(*kubeopenv1alpha1.Task)) *kubeopenv1alpha1.Task {
+		task := &kubeopenv1alpha1.Task{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "fix"},
+			Spec: kubeopenv1alpha1.TaskSpec{
+				Description: &description,
+				AgentRef:    &kubeopenv1alpha1.AgentReference{Name: "coder"},
+			},
+		}
+		if mutate != nil {
+			mutate(task)
+		}
+		return task
+	}
+	text := func(name, mountPath string) kubeopenv1alpha1.ContextItem {
+		return kubeopenv1alpha1.ContextItem{Name: name, Type: kubeopenv1alpha1.ContextTypeText, Text: "x", MountPath: mountPath}
+	}
+
+	tests := []struct {
+		name    string
+		task    *kubeopenv1alpha1.Task
+		wantErr string
+	}{
+		{name: "valid", task: newTask(nil)},
+		{
+			name: "contexts without description",
+			task: newTask(func(task *kubeopenv1alpha1.Task) {
+				task.Spec.Description = nil
+				task.Spec.Contexts = []kubeopenv1alpha1.ContextItem{text("guide", "")}
+			}),
+		},
+		{
+			name:    "no description or contexts",
+			task:    newTask(func(task *kubeopenv1alpha1.Task) { task.Spec.Description = nil }),
+			wantErr: "spec.description",
+		},
+		{
+			name: "duplicate context names",
+			task: newTask(func(task *kubeopenv1alpha1.Task) {
+				task.Spec.Contexts = []kubeopenv1alpha1.ContextItem{text("guide", ""), text("guide", "")}
+			}),
+			wantErr: "spec.contexts[1].name",
+		},
+		{
+			name: "context name colliding with an unnamed Git context",
+			task: newTask(func(task *kubeopenv1alpha1.Task) {
+				task.Spec.Contexts = []kubeopenv1alpha1.ContextItem{
+					{Type: kubeopenv1alpha1.ContextTypeGit, MountPath: "repo"},
+					text("git-0", ""),
+				}
+			}),
+			wantErr: `spec.contexts[1].name: Duplicate value: "git-0"`,
+		},
+		{
+			name: "mount path escapes the workspace",
+			task: newTask(func(task *kubeopenv1alpha1.Task) {
+				task.Spec.Contexts = []kubeopenv1alpha1.ContextItem{text("guide", "../etc")}
+			}),
+			wantErr: "escapes the workspace directory",
+		},
+		{
+			name: "own outputs",
+			task: newTask(func(task *kubeopenv1alpha1.Task) {
+				task.Spec.Contexts = []kubeopenv1alpha1.ContextItem{{
+					Type:       kubeopenv1alpha1.ContextTypeTaskOutput,
+					TaskOutput: &kubeopenv1alpha1.TaskOutputContext{Name: "fix"},
+				}}
+			}),
+			wantErr: "spec.contexts[0].taskOutput.name",
+		},
+		{
+			name: "nested output file",
+			task: newTask(func(task *kubeopenv1alpha1.Task) {
+				task.Spec.Contexts = []kubeopenv1alpha1.ContextItem{{
+					Type:       kubeopenv1alpha1.ContextTypeTaskOutput,
+					TaskOutput: &kubeopenv1alpha1.TaskOutputContext{Name: "analyze", Files: []string{"report.md", "sub/notes.md"}},
+				}}
+			}),
+			wantErr: "spec.contexts[0].taskOutput.files[1]",
+		},
+		{
+			name:    "non-positive timeout",
+			task:    newTask(func(task *kubeopenv1alpha1.Task) { task.Spec.Timeout = &metav1.Duration{Duration: -time.Minute} }),
+			wantErr: "spec.timeout",
+		},
+	}
+
+	v := &TaskValidator{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := v.ValidateCreate(context.Background(), tt.task)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	t.Run("update keeping an invalid spec is allowed", func(t *testing.T) {
+		old := newTask(func(task *kubeopenv1alpha1.Task) { task.Spec.Description = nil })
+		updated := old.DeepCopy()
+		updated.Annotations = map[string]string{"kubeopencode.io/stop": "true"}
+		if _, err := v.ValidateUpdate(context.Background(), old, updated); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
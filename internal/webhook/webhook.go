@@ -0,0 +1,196 @@
+// Copyright Contributors to the KubeOpenCode project
+
+// Package webhook implements the admission webhooks for KubeOpenCode resources.
+// Defaulting makes implicit defaults visible on the stored object, and validation
+// catches spec problems at apply time that the controller would otherwise only report
+// as a Failed Task or a not-ready Agent.
+package webhook
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	kubeopenv1alpha1 "github.com/kubeopencode/kubeopencode/api/v1alpha1"
+	"github.com/kubeopencode/kubeopencode/internal/controller"
+)
+
+// SetupWithManager registers the defaulting and validating webhooks with the manager's
+// webhook server.
+func SetupWithManager(mgr ctrl.Manager) error {
+	if err := ctrl.NewWebhookManagedBy(mgr, &kubeopenv1alpha1.Task{}).
+		WithDefaulter(&TaskDefaulter{Reader: mgr.GetAPIReader()}).
+		WithValidator(&TaskValidator{}).
+		Complete(); err != nil {
+		return fmt.Errorf("task webhook: %w", err)
+	}
+	if err := ctrl.NewWebhookManagedBy(mgr, &kubeopenv1alpha1.Agent{}).
+		WithDefaulter(&AgentDefaulter{}).
+		WithValidator(&AgentValidator{}).
+		Complete(); err != nil {
+		return fmt.Errorf("agent webhook: %w", err)
+	}
+	if err := ctrl.NewWebhookManagedBy(mgr, &kubeopenv1alpha1.AgentTemplate{}).
+		WithValidator(&AgentTemplateValidator{}).
+		Complete(); err != nil {
+		return fmt.Errorf("agenttemplate webhook: %w", err)
+	}
+	if err := ctrl.NewWebhookManagedBy(mgr, &kubeopenv1alpha1.CronTask{}).
+		WithValidator(&CronTaskValidator{}).
+		Complete(); err != nil {
+		return fmt.Errorf("crontask webhook: %w", err)
+	}
+	return nil
+}
+
+// invalid converts field errors to the API's Invalid error for kind, or returns nil.
+func invalid(kind, name string, errs field.ErrorList) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(kubeopenv1alpha1.GroupVersion.WithKind(kind).GroupKind(), name, errs)
+}
+
+// validateContexts checks that context names are unique and that mount paths stay
+// inside the workspace and do not collide. workspaceDir is "" if it is not known.
+func validateContexts(fldPath *field.Path, contexts []kubeopenv1alpha1.ContextItem, workspaceDir string) field.ErrorList {
+	var errs field.ErrorList
+	names := map[string]bool{}
+	for i := range contexts {
+		item := &contexts[i]
+		// Unnamed Git contexts are named by position, which an explicit name may collide with
+		if name := controller.ContextName(item, i); name != "" {
+			if names[name] {
+				errs = append(errs, field.Duplicate(fldPath.Index(i).Child("name"), name))
+			}
+			names[name] = true
+		}
+		if item.TaskOutput != nil {
+			errs = append(errs, validateOutputFiles(fldPath.Index(i).Child("taskOutput", "files"), item.TaskOutput.Files)...)
+		}
+	}
+	if err := controller.ValidateContextMountPaths(contexts, workspaceDir); err != nil {
+		errs = append(errs, field.Invalid(fldPath, "", err.Error()))
+	}
+	return errs
+}
+
+// validateOutputFiles checks that selected output files are unique top-level file names,
+// since only top-level files are captured.
+func validateOutputFiles(fldPath *field.Path, files []string) field.ErrorList {
+	var errs field.ErrorList
+	seen := map[string]bool{}
+	for i, file := range files {
+		switch {
+		case file == "" || file == "." || file == ".." || strings.Contains(file, "/"):
+			errs = append(errs, field.Invalid(fldPath.Index(i), file, "must be a top-level file name"))
+		case seen[file]:
+			errs = append(errs, field.Duplicate(fldPath.Index(i), file))
+		}
+		seen[file] = true
+	}
+	return errs
+}
+
+// validateCredentials checks credential names, Secret references, mount paths and
+// environment variable names.
+func validateCredentials(fldPath *field.Path, credentials []kubeopenv1alpha1.Credential) field.ErrorList {
+	var errs field.ErrorList
+	names := map[string]bool{}
+	mountPaths := map[string]bool{}
+	envs := map[string]bool{}
+	for i := range credentials {
+		cred := &credentials[i]
+		credPath := fldPath.Index(i)
+		if names[cred.Name] {
+			errs = append(errs, field.Duplicate(credPath.Child("name"), cred.Name))
+		}
+		names[cred.Name] = true
+
+		if cred.SecretRef.Name == "" {
+			errs = append(errs, field.Required(credPath.Child("secretRef", "name"), ""))
+		} else {
+			for _, msg := range validation.IsDNS1123Subdomain(cred.SecretRef.Name) {
+				errs = append(errs, field.Invalid(credPath.Child("secretRef", "name"), cred.SecretRef.Name, msg))
+			}
+		}
+		hasKey := cred.SecretRef.Key != nil && *cred.SecretRef.Key != ""
+
+		if cred.MountPath != nil && *cred.MountPath != "" {
+			p := path.Clean(*cred.MountPath)
+			switch {
+			case !path.IsAbs(p):
+				errs = append(errs, field.Invalid(credPath.Child("mountPath"), *cred.MountPath, "must be an absolute path"))
+			case mountPaths[p]:
+				errs = append(errs, field.Duplicate(credPath.Child("mountPath"), *cred.MountPath))
+			}
+			mountPaths[p] = true
+		}
+		if cred.Env != nil && *cred.Env != "" {
+			for _, msg := range validation.IsEnvVarName(*cred.Env) {
+				errs = append(errs, field.Invalid(credPath.Child("env"), *cred.Env, msg))
+			}
+			if envs[*cred.Env] {
+				errs = append(errs, field.Duplicate(credPath.Child("env"), *cred.Env))
+			}
+			envs[*cred.Env] = true
+		}
+		if hasKey && (cred.Env == nil || *cred.Env == "") && (cred.MountPath == nil || *cred.MountPath == "") {
+			errs = append(errs, field.Required(credPath, "env or mountPath is required when secretRef.key is set"))
+		}
+		if cred.FileMode != nil && (*cred.FileMode < 0 || *cred.FileMode > 0o777) {
+			errs = append(errs, field.Invalid(credPath.Child("fileMode"), *cred.FileMode, "must be between 0 and 0777 (511)"))
+		}
+	}
+	return errs
+}
+
+// validateExtraPorts checks that extra port names and numbers are unique and do not
+// clash with the OpenCode server port, which is exposed as "http". serverPort 0 skips
+// the server port check.
+func validateExtraPorts(fldPath *field.Path, serverPort int32, ports []kubeopenv1alpha1.ExtraPort) field.ErrorList {
+	var errs field.ErrorList
+	names := map[string]bool{"http": true}
+	numbers := map[string]bool{}
+	if serverPort != 0 {
+		numbers[fmt.Sprintf("%d/%s", serverPort, corev1.ProtocolTCP)] = true
+	}
+	for i, port := range ports {
+		portPath := fldPath.Index(i)
+		for _, msg := range validation.IsValidPortName(port.Name) {
+			errs = append(errs, field.Invalid(portPath.Child("name"), port.Name, msg))
+		}
+		if names[port.Name] {
+			errs = append(errs, field.Duplicate(portPath.Child("name"), port.Name))
+		}
+		names[port.Name] = true
+
+		for _, msg := range validation.IsValidPortNum(int(port.Port)) {
+			errs = append(errs, field.Invalid(portPath.Child("port"), port.Port, msg))
+		}
+		protocol := port.Protocol
+		if protocol == "" {
+			protocol = corev1.ProtocolTCP
+		}
+		key := fmt.Sprintf("%d/%s", port.Port, protocol)
+		if numbers[key] {
+			errs = append(errs, field.Duplicate(portPath.Child("port"), port.Port))
+		}
+		numbers[key] = true
+	}
+	return errs
+}
+
+// validateWorkspaceDir checks that a workspace directory, when set, is an absolute path.
+func validateWorkspaceDir(fldPath *field.Path, dir string) field.ErrorList {
+	if dir != "" && !path.IsAbs(dir) {
+		return field.ErrorList{field.Invalid(fldPath, dir, "must be an absolute path")}
+	}
+	return nil
+}
@@ -35,6 +35,12 @@ export interface SessionInfo {
   summary?: SessionSummary;
 }
 
+export interface TaskResult {
+  status: 'Success' | 'Failure' | 'Neutral';
+  summary?: string;
+  fields?: Record<string, unknown>;
+}
+
 export interface Task {
   name: string;
   namespace: string;
@@ -45,6 +51,7 @@ export interface Task {
   timeout?: string;
   podName?: string;
   session?: SessionInfo;
+  result?: TaskResult;
   startTime?: string;
   completionTime?: string;
   duration?: string;
@@ -84,6 +91,7 @@ export interface CreateTaskRequest {
   agentRef?: AgentReference;
   templateRef?: { name: string };
   timeout?: string;
+  params?: Record<string, string>;
 }
 
 export interface CreateVolumePersistence {
@@ -22,6 +22,7 @@ const sidebars: SidebarsConfig = {
         'features/crontask',
         'features/git-auto-sync',
         'features/task-stop',
+        'features/workspace-snapshot',
         'features/concurrency-quota',
         'features/persistence',
         'features/enterprise',